@@ -47,7 +47,26 @@ func main() {
 }
 
 func cmdCheck(args *skel.CmdArgs) error {
-	return nil
+	netConf, _, err := loadNetConf(args.StdinData)
+	if err != nil {
+		return err
+	}
+
+	client := request.NewCniDaemonClient(netConf.ServerSocket)
+	podName, err := parseValueFromArgs("K8S_POD_NAME", args.Args)
+	if err != nil {
+		return err
+	}
+	podNamespace, err := parseValueFromArgs("K8S_POD_NAMESPACE", args.Args)
+	if err != nil {
+		return err
+	}
+
+	return client.Check(request.PodRequest{
+		PodName:      podName,
+		PodNamespace: podNamespace,
+		ContainerID:  args.ContainerID,
+		NetNs:        args.Netns})
 }
 
 func cmdAdd(args *skel.CmdArgs) error {
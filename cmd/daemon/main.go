@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"runtime/debug"
 
 	"github.com/alibaba/hybridnet/pkg/constants"
 	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
@@ -29,12 +30,14 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 	daemonconfig "github.com/alibaba/hybridnet/pkg/daemon/config"
 	"github.com/alibaba/hybridnet/pkg/daemon/controller"
+	"github.com/alibaba/hybridnet/pkg/daemon/crash"
 	"github.com/alibaba/hybridnet/pkg/daemon/server"
 	"github.com/alibaba/hybridnet/pkg/feature"
 )
@@ -53,8 +56,21 @@ func main() {
 		entryLog.Error(err, "failed to parse config")
 		os.Exit(1)
 	}
+
+	// report all the misconfigurations at once instead of failing on the first one
+	if errs := daemonconfig.ValidateConfiguration(config); len(errs) > 0 {
+		for _, err := range errs {
+			entryLog.Error(err, "invalid config")
+		}
+		os.Exit(1)
+	}
 	entryLog.Info("generate daemon config", "config", *config)
 
+	// turn unexpected memory faults into panics, so that crash report can be written
+	debug.SetPanicOnFault(true)
+	crashReporter := crash.NewReporter(config.CrashDir, config.NodeName)
+	defer crashReporter.Recover()
+
 	if err := initSysctl(); err != nil {
 		entryLog.Error(err, "failed to init sysctl")
 		os.Exit(1)
@@ -63,6 +79,11 @@ func main() {
 	// setup manager
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		MetricsBindAddress: config.MetricsServerAddress,
+		// managed fields are useless for daemon but copied on every read of cache, especially
+		// IPInstances listed on CNI ADD
+		NewCache: cache.BuilderWithOptions(cache.Options{
+			DefaultTransform: daemonutils.StripManagedFields,
+		}),
 	})
 	if err != nil {
 		entryLog.Error(err, "unable to start daemon manager")
@@ -92,14 +113,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	crashReporter.RegisterState("ipam", ctl.IPAMStateSummary)
+	crashReporter.RegisterState("vtep", ctl.VtepInterfaceState)
+
 	go func() {
+		debug.SetPanicOnFault(true)
+		defer crashReporter.Recover()
+
 		if err = ctl.Run(ctx); err != nil {
 			entryLog.Error(err, "CtrlHub exit unusually")
 			os.Exit(1)
 		}
 	}()
 
-	server.RunServer(ctx, config, ctl, log.Log.WithName("cni-server"))
+	server.RunServer(ctx, config, ctl, crashReporter, log.Log.WithName("cni-server"))
 }
 
 func initSysctl() error {
@@ -0,0 +1,181 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/networking"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+// runIPAMCheck compares the IPs of IPInstances of a subnet in apiserver with the ones
+// allocated in the in-memory ipam state of the leader manager
+func runIPAMCheck(args []string) int {
+	flags := pflag.NewFlagSet("ipam-check", pflag.ContinueOnError)
+	var (
+		subnetName = flags.String("subnet", "", "The name of subnet to check")
+		endpoint   = flags.String("manager-endpoint", "http://127.0.0.1:9899", "The metrics endpoint of the leader hybridnet manager")
+		timeout    = flags.Duration("timeout", 30*time.Second, "The timeout of the whole check")
+	)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *subnetName == "" {
+		fmt.Fprintln(os.Stderr, "--subnet is required")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create kubernetes client: %v\n", err)
+		return 1
+	}
+
+	subnet, err := utils.GetSubnet(ctx, c, *subnetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to get subnet %s: %v\n", *subnetName, err)
+		return 1
+	}
+
+	ipList, err := utils.ListIPInstances(ctx, c, client.MatchingLabels{
+		constants.LabelSubnet: subnet.Name,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to list IPInstances of subnet %s: %v\n", subnet.Name, err)
+		return 1
+	}
+
+	// expected IPs are indexed to the IPInstances holding them
+	expected := make(map[string]string, len(ipList.Items))
+	for i := range ipList.Items {
+		ip := &ipList.Items[i]
+		expected[utils.ToIPFormat(ip.Name)] = ip.Namespace + "/" + ip.Name
+	}
+
+	state, err := fetchSubnetIPAMState(ctx, *endpoint, subnet.Spec.Network, subnet.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to fetch ipam state of subnet %s: %v\n", subnet.Name, err)
+		return 1
+	}
+
+	missing, unexpected := diffAllocatedIPs(expected, state.AllocatedIPs)
+	fmt.Printf("subnet %s of network %s: %d IPInstance(s) in apiserver, %d allocated IP(s) in ipam\n",
+		subnet.Name, subnet.Spec.Network, len(expected), len(state.AllocatedIPs))
+
+	if len(missing) == 0 && len(unexpected) == 0 {
+		fmt.Println("\nIPAM state is consistent with apiserver.")
+		return 0
+	}
+
+	fmt.Printf("\n%d discrepancy(s) found, '-' means not allocated in ipam, '+' means no IPInstance in apiserver:\n",
+		len(missing)+len(unexpected))
+	for _, ip := range missing {
+		fmt.Printf("- %s\t(IPInstance %s)\n", ip, expected[ip])
+	}
+	for _, ip := range unexpected {
+		fmt.Printf("+ %s\n", ip)
+	}
+	return 1
+}
+
+func newClient() (client.Client, error) {
+	restConfig, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	if err = networkingv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err = multiclusterv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
+
+func fetchSubnetIPAMState(ctx context.Context, endpoint, networkName, subnetName string) (*networking.SubnetIPAMState, error) {
+	query := url.Values{}
+	query.Set("network", networkName)
+	query.Set("subnet", subnetName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(endpoint, "/")+networking.IPAMDebugPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	state := &networking.SubnetIPAMState{}
+	if err = json.NewDecoder(resp.Body).Decode(state); err != nil {
+		return nil, fmt.Errorf("unable to decode response: %v", err)
+	}
+	return state, nil
+}
+
+// diffAllocatedIPs returns the sorted IPs which have IPInstances but are not allocated, and
+// the ones which are allocated but have no IPInstances
+func diffAllocatedIPs(expected map[string]string, allocated []string) (missing, unexpected []string) {
+	allocatedSet := make(map[string]struct{}, len(allocated))
+	for _, ip := range allocated {
+		allocatedSet[ip] = struct{}{}
+		if _, exist := expected[ip]; !exist {
+			unexpected = append(unexpected, ip)
+		}
+	}
+	for ip := range expected {
+		if _, exist := allocatedSet[ip]; !exist {
+			missing = append(missing, ip)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return
+}
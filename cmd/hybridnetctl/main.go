@@ -0,0 +1,124 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+
+	"github.com/alibaba/hybridnet/pkg/preflight"
+)
+
+const usage = `hybridnetctl is the command line tool of hybridnet.
+
+Usage:
+  hybridnetctl <command> [flags]
+
+Commands:
+  preflight   Check if the underlay network environment of node meets the requirements of hybridnet
+  ipam-check  Check if the in-memory ipam state of a subnet in hybridnet manager matches IPInstances in apiserver
+  export      Export RemoteVteps and RemoteSubnets of remote clusters as portable json, e.g., export vteps
+  import      Recreate RemoteVteps and RemoteSubnets from the output of export, e.g., import vteps
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "preflight":
+		os.Exit(runPreflight(os.Args[2:]))
+	case "ipam-check":
+		os.Exit(runIPAMCheck(os.Args[2:]))
+	case "export":
+		os.Exit(runExport(os.Args[2:]))
+	case "import":
+		os.Exit(runImport(os.Args[2:]))
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func runPreflight(args []string) int {
+	flags := pflag.NewFlagSet("preflight", pflag.ContinueOnError)
+	var (
+		nodeInterface = flags.String("node-interface", "", "The host interface which underlay vlan interfaces are created on")
+		gateway       = flags.String("gateway", "", "The ipv4 gateway of underlay subnet")
+		vlanID        = flags.Int("vlan-id", 0, "The vlan id of underlay network, 0 means untagged")
+		mtu           = flags.Int("mtu", 0, "The expected path mtu to gateway, 0 means the mtu of the interface which gateway is reached over")
+		timeout       = flags.Duration("timeout", preflight.DefaultTimeout, "The timeout of each arp and icmp probe")
+	)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	gatewayIP := net.ParseIP(*gateway)
+	switch {
+	case *nodeInterface == "":
+		fmt.Fprintln(os.Stderr, "--node-interface is required")
+		return 2
+	case gatewayIP == nil || gatewayIP.To4() == nil:
+		fmt.Fprintln(os.Stderr, "--gateway should be a valid ipv4 address")
+		return 2
+	case *vlanID < 0:
+		fmt.Fprintln(os.Stderr, "--vlan-id should not be negative")
+		return 2
+	}
+
+	results := preflight.Run(preflight.Options{
+		NodeInterface: *nodeInterface,
+		Gateway:       gatewayIP,
+		VlanID:        *vlanID,
+		MTU:           *mtu,
+		Timeout:       *timeout,
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tMESSAGE")
+	failedCount := 0
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+			failedCount++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, status, result.Message)
+	}
+	_ = w.Flush()
+
+	if failedCount == 0 {
+		fmt.Println("\nAll preflight checks passed.")
+		return 0
+	}
+
+	fmt.Printf("\n%d preflight check(s) failed, remediation hints:\n", failedCount)
+	for _, result := range results {
+		if !result.Passed && result.Hint != "" {
+			fmt.Printf("  - %s: %s\n", result.Name, result.Hint)
+		}
+	}
+	return 1
+}
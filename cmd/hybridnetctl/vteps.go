@@ -0,0 +1,142 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/alibaba/hybridnet/pkg/vtepbackup"
+)
+
+// runExport exports the VTEP configuration of remote clusters as portable json
+func runExport(args []string) int {
+	if len(args) < 1 || args[0] != "vteps" {
+		fmt.Fprintln(os.Stderr, "usage: hybridnetctl export vteps [--cluster <name>] [-o json]")
+		return 2
+	}
+
+	flags := pflag.NewFlagSet("export vteps", pflag.ContinueOnError)
+	var (
+		clusterName = flags.String("cluster", "", "The name of remote cluster to export, empty means all remote clusters")
+		output      = flags.StringP("output", "o", "json", "The output format, only json is supported")
+		timeout     = flags.Duration("timeout", 30*time.Second, "The timeout of the whole export")
+	)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *output != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q, only json is supported\n", *output)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create kubernetes client: %v\n", err)
+		return 1
+	}
+
+	backup, err := vtepbackup.Export(ctx, c, *clusterName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to export vteps: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(backup); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to encode backup: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runImport recreates the VTEP configuration of remote clusters from the output of export
+func runImport(args []string) int {
+	if len(args) < 1 || args[0] != "vteps" {
+		fmt.Fprintln(os.Stderr, "usage: hybridnetctl import vteps -f <file>")
+		return 2
+	}
+
+	flags := pflag.NewFlagSet("import vteps", pflag.ContinueOnError)
+	var (
+		file    = flags.StringP("filename", "f", "", "The file exported by export vteps, - means stdin")
+		timeout = flags.Duration("timeout", 30*time.Second, "The timeout of the whole import")
+	)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "--filename is required")
+		return 2
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to read %s: %v\n", *file, err)
+		return 1
+	}
+
+	backup := &vtepbackup.Backup{}
+	if err = json.Unmarshal(data, backup); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to decode backup: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create kubernetes client: %v\n", err)
+		return 1
+	}
+
+	result, err := vtepbackup.Import(ctx, c, backup)
+	if result != nil {
+		for _, name := range result.Applied {
+			fmt.Printf("%s applied\n", name)
+		}
+		for _, name := range result.Skipped {
+			fmt.Printf("%s unchanged\n", name)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to import vteps: %v\n", err)
+		return 1
+	}
+	return 0
+}
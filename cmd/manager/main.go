@@ -20,6 +20,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
@@ -32,9 +34,12 @@ import (
 
 	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
 	"github.com/alibaba/hybridnet/pkg/controllers/multicluster"
 	"github.com/alibaba/hybridnet/pkg/controllers/networking"
 	"github.com/alibaba/hybridnet/pkg/feature"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	"github.com/alibaba/hybridnet/pkg/managerapi"
 	zapinit "github.com/alibaba/hybridnet/pkg/zap"
 )
 
@@ -52,10 +57,41 @@ func init() {
 
 func main() {
 	var (
-		controllerConcurrency map[string]int
-		clientQPS             float32
-		clientBurst           int
-		metricsPort           int
+		controllerConcurrency    map[string]int
+		clientQPS                float32
+		clientBurst              int
+		metricsPort              int
+		ipamFitStrategy          string
+		enableSchemaMigration    bool
+		ipamCheckInterval        time.Duration
+		ipamAutoHeal             bool
+		enableEndpointSliceSync  bool
+		nodeDeleteIPWorkers      int
+		enablePprof              bool
+		pprofPort                int
+		pprofAllowedCIDRs        []string
+		enableAPI                bool
+		apiPort                  int
+		apiBindAddress           string
+		apiTLSCertFile           string
+		apiTLSKeyFile            string
+		apiBearerTokenFile       string
+		apiQPSLimit              float64
+		podControllerWorkers     int
+		subnetControllerWorkers  int
+		networkControllerWorkers int
+		clusterID                string
+		statefulIPCheckInterval  time.Duration
+		statefulIPGracePeriod    time.Duration
+		ipInstanceAgeInterval    time.Duration
+		ipInstanceStatusInterval time.Duration
+		observabilityInterval    time.Duration
+		dnsRegistrationZone      string
+		subnetRetryTimeout       time.Duration
+		allocationHistoryPeriod  time.Duration
+		shardCount               int
+		podEventDebounce         time.Duration
+		shardID                  int
 	)
 
 	// register flags
@@ -63,6 +99,37 @@ func main() {
 	pflag.Float32Var(&clientQPS, "kube-client-qps", 300, "The QPS limit of apiserver client.")
 	pflag.IntVar(&clientBurst, "kube-client-burst", 600, "The Burst limit of apiserver client.")
 	pflag.IntVar(&metricsPort, "metrics-port", 9899, "The port to listen on for prometheus metrics.")
+	pflag.StringVar(&ipamFitStrategy, "ipam-fit-strategy", string(ipamtypes.FirstFit), "The strategy to pick IP from subnet, first-fit or best-fit.")
+	pflag.BoolVar(&enableSchemaMigration, "enable-schema-migration", false, "Whether to migrate IPInstances to the latest schema version.")
+	pflag.DurationVar(&ipamCheckInterval, "ipam-consistency-check-interval", 5*time.Minute, "The interval of checking whether in-memory ipam state drifts from apiserver, zero disables it.")
+	pflag.BoolVar(&ipamAutoHeal, "ipam-auto-heal", false, "Whether to rebuild the in-memory ipam state of networks if drift is detected.")
+	pflag.BoolVar(&enableEndpointSliceSync, "enable-endpointslice-sync", false, "Whether to verify the endpoints of pods in EndpointSlices against allocated IPs.")
+	pflag.DurationVar(&subnetRetryTimeout, "subnet-retry-timeout", 10*time.Minute, "How long a subnet keeps failing with permanent errors before subnet controller stops retrying it until it is updated, zero means retrying forever.")
+	pflag.IntVar(&nodeDeleteIPWorkers, "node-delete-ip-workers", 10, "The number of workers deleting IPInstances of a deleted node in parallel, zero disables it.")
+	pflag.BoolVar(&enablePprof, "enable-pprof", false, "Whether to serve pprof handlers, which requires the binary built with tag pprof.")
+	pflag.IntVar(&pprofPort, "pprof-port", 6060, "The port to listen on for pprof handlers.")
+	pflag.StringSliceVar(&pprofAllowedCIDRs, "pprof-allowed-cidrs", []string{"127.0.0.1/32", "::1/128"}, "The CIDRs of clients allowed to access pprof handlers.")
+	pflag.BoolVar(&enableAPI, "enable-api", false, "Whether to serve the read-only http api of IPInstances.")
+	pflag.IntVar(&apiPort, "api-port", 9900, "The port to listen on for the http api.")
+	pflag.StringVar(&apiBindAddress, "api-bind-address", "127.0.0.1", "The address to listen on for the http api, which must be a loopback address unless tls is configured.")
+	pflag.StringVar(&apiTLSCertFile, "api-tls-cert-file", "", "The file containing the tls certificate of the http api.")
+	pflag.StringVar(&apiTLSKeyFile, "api-tls-key-file", "", "The file containing the tls private key of the http api.")
+	pflag.StringVar(&apiBearerTokenFile, "api-bearer-token-file", "", "The file containing bearer token which requests of the http api must carry.")
+	pflag.Float64Var(&apiQPSLimit, "api-qps-limit", 10, "The QPS limit of the http api.")
+	pflag.DurationVar(&podEventDebounce, "pod-event-debounce", 500*time.Millisecond, "The window of coalescing update events of a pod into one reconciliation of pod controller, zero disables it.")
+	pflag.IntVar(&podControllerWorkers, "pod-controller-workers", 0, "The number of workers of pod controller, zero means following controller-concurrency.")
+	pflag.IntVar(&subnetControllerWorkers, "subnet-controller-workers", 0, "The number of workers of subnet and subnet status controllers, zero means following controller-concurrency.")
+	pflag.IntVar(&networkControllerWorkers, "network-controller-workers", 0, "The number of workers of network status controller, zero means following controller-concurrency.")
+	pflag.StringVar(&clusterID, "cluster-id", "", "The unique ID of local cluster in multi-cluster mode, which must not be registered by peer clusters for other clusters.")
+	pflag.DurationVar(&statefulIPCheckInterval, "stateful-ip-staleness-check-interval", 10*time.Minute, "The interval of checking retained IPInstances of StatefulSets with indexes out of replicas, zero disables it.")
+	pflag.DurationVar(&statefulIPGracePeriod, "stateful-ip-staleness-grace-period", 0, "How long a stale retained IPInstance of StatefulSet is kept before deletion, zero means only emitting warning events.")
+	pflag.DurationVar(&ipInstanceAgeInterval, "ipinstance-age-check-interval", 10*time.Minute, "The interval of purging IPInstances older than the max age of NetworkingPolicies, zero disables it.")
+	pflag.DurationVar(&ipInstanceStatusInterval, "ipinstance-status-consistency-check-interval", time.Minute, "The interval of fixing the status of IPInstances which skews from their bindings, zero disables it.")
+	pflag.DurationVar(&observabilityInterval, "network-observability-report-interval", 5*time.Minute, "The interval of refreshing the cluster-wide NetworkObservabilityReport, zero disables it.")
+	pflag.DurationVar(&allocationHistoryPeriod, "subnet-allocation-history-interval", time.Hour, "The interval of snapshotting used IPs of subnets into allocation histories stored in ConfigMaps, zero disables it.")
+	pflag.IntVar(&shardCount, "shard-count", 1, "The number of manager shards which networks are distributed across by the hash of names, one disables sharding.")
+	pflag.IntVar(&shardID, "shard-id", 0, "The shard of this manager in range [0, shard-count), shard 0 also runs cluster-scoped controllers.")
+	pflag.StringVar(&dnsRegistrationZone, "dns-registration-zone", "", "The DNS zone which hostnames of pods with underlay IPs are registered under, required if UnderlayDNSRegistration feature is enabled.")
 
 	// parse flags
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -71,13 +138,73 @@ func main() {
 	ctrllog.SetLogger(zapinit.NewZapLogger())
 
 	var entryLog = ctrllog.Log.WithName("entry")
+
+	if err := overrideControllerConcurrency(controllerConcurrency, podControllerWorkers,
+		subnetControllerWorkers, networkControllerWorkers); err != nil {
+		entryLog.Error(err, "invalid flag")
+		os.Exit(1)
+	}
+	if controllerConcurrency[networking.ControllerPod] > concurrency.MaxPodControllerConcurrency {
+		entryLog.Info("concurrency of pod controller exceeds the parallelism of ipam lock, "+
+			"extra workers only wait for the lock", "concurrency", controllerConcurrency[networking.ControllerPod],
+			"recommended-max", concurrency.MaxPodControllerConcurrency)
+	}
+
 	entryLog.Info("starting hybridnet manager",
 		"known-features", feature.KnownFeatures(),
 		"commit-id", gitCommit,
-		"controller-concurrency", controllerConcurrency)
+		"controller-concurrency", controllerConcurrency,
+		"ipam-fit-strategy", ipamFitStrategy,
+		"enable-schema-migration", enableSchemaMigration,
+		"ipam-consistency-check-interval", ipamCheckInterval,
+		"ipam-auto-heal", ipamAutoHeal,
+		"enable-endpointslice-sync", enableEndpointSliceSync,
+		"node-delete-ip-workers", nodeDeleteIPWorkers,
+		"pod-event-debounce", podEventDebounce,
+		"enable-api", enableAPI,
+		"api-qps-limit", apiQPSLimit,
+		"cluster-id", clusterID,
+		"stateful-ip-staleness-check-interval", statefulIPCheckInterval,
+		"stateful-ip-staleness-grace-period", statefulIPGracePeriod,
+		"ipinstance-age-check-interval", ipInstanceAgeInterval,
+		"ipinstance-status-consistency-check-interval", ipInstanceStatusInterval,
+		"network-observability-report-interval", observabilityInterval,
+		"dns-registration-zone", dnsRegistrationZone,
+		"shard-count", shardCount,
+		"shard-id", shardID)
+
+	fitStrategy := ipamtypes.ParseFitStrategyFromString(ipamFitStrategy)
+	if !ipamtypes.IsValidFitStrategy(fitStrategy) {
+		entryLog.Error(fmt.Errorf("unsupported ipam fit strategy %s", ipamFitStrategy), "invalid flag")
+		os.Exit(1)
+	}
+
+	if feature.UnderlayDNSRegistrationEnabled() && len(dnsRegistrationZone) == 0 {
+		entryLog.Error(fmt.Errorf("--dns-registration-zone is required by UnderlayDNSRegistration feature"), "invalid flag")
+		os.Exit(1)
+	}
+
+	networkShard := networking.NetworkShard{Count: shardCount, ID: shardID}
+	if err := networkShard.Validate(); err != nil {
+		entryLog.Error(err, "invalid flag")
+		os.Exit(1)
+	}
+
+	// every shard elects its own leader
+	leaderElectionID := "hybridnet-manager-election"
+	if networkShard.Enabled() {
+		leaderElectionID = fmt.Sprintf("%s-shard-%d", leaderElectionID, networkShard.ID)
+	}
 
 	globalContext := ctrl.SetupSignalHandler()
 
+	if enablePprof {
+		if err := startPprofServer(globalContext, pprofPort, pprofAllowedCIDRs); err != nil {
+			entryLog.Error(err, "unable to start pprof server")
+			os.Exit(1)
+		}
+	}
+
 	clientConfig := ctrl.GetConfigOrDie()
 	clientConfig.QPS = clientQPS
 	clientConfig.Burst = clientBurst
@@ -87,7 +214,7 @@ func main() {
 		Logger:                  ctrl.Log.WithName("manager"),
 		MetricsBindAddress:      fmt.Sprintf(":%d", metricsPort),
 		LeaderElection:          true,
-		LeaderElectionID:        "hybridnet-manager-election",
+		LeaderElectionID:        leaderElectionID,
 		LeaderElectionNamespace: os.Getenv("NAMESPACE"),
 	})
 	if err != nil {
@@ -95,6 +222,45 @@ func main() {
 		os.Exit(1)
 	}
 
+	if enableAPI {
+		token, err := os.ReadFile(apiBearerTokenFile)
+		if err != nil {
+			entryLog.Error(err, "unable to read api bearer token")
+			os.Exit(1)
+		}
+		if len(strings.TrimSpace(string(token))) == 0 {
+			entryLog.Error(fmt.Errorf("empty api bearer token in %s", apiBearerTokenFile), "invalid flag")
+			os.Exit(1)
+		}
+
+		apiServer := &managerapi.Server{
+			Client:      mgr.GetClient(),
+			BindAddress: apiBindAddress,
+			Port:        apiPort,
+			TLSCertFile: apiTLSCertFile,
+			TLSKeyFile:  apiTLSKeyFile,
+			BearerToken: strings.TrimSpace(string(token)),
+			QPSLimit:    apiQPSLimit,
+			Logger:      ctrl.Log.WithName("api"),
+		}
+		if err = apiServer.Validate(); err != nil {
+			entryLog.Error(err, "invalid flag")
+			os.Exit(1)
+		}
+
+		if err = mgr.Add(apiServer); err != nil {
+			entryLog.Error(err, "unable to add api server")
+			os.Exit(1)
+		}
+	}
+
+	// extra handlers of metrics server need to be added before manager is running
+	ipamDebugHandler := &networking.IPAMDebugHandler{}
+	if err = mgr.AddMetricsExtraHandler(networking.IPAMDebugPath, ipamDebugHandler); err != nil {
+		entryLog.Error(err, "unable to add ipam debug handler")
+		os.Exit(1)
+	}
+
 	// indexers need to be injected be for informer is running
 	if err = networking.InitIndexers(mgr); err != nil {
 		entryLog.Error(err, "unable to init indexers")
@@ -118,16 +284,54 @@ func main() {
 		os.Exit(1)
 	}
 
+	// name of manager pod identifies the instance which allocates IPs
+	controllerInstance := os.Getenv("POD_NAME")
+	if len(controllerInstance) == 0 {
+		controllerInstance, _ = os.Hostname()
+	}
+
+	// histories of subnet allocation are stored with manager
+	historyNamespace := os.Getenv("NAMESPACE")
+	if len(historyNamespace) == 0 {
+		historyNamespace = "kube-system"
+	}
+
 	if err = networking.RegisterToManager(globalContext, mgr, networking.RegisterOptions{
-		ConcurrencyMap: controllerConcurrency,
+		ConcurrencyMap:        controllerConcurrency,
+		IPAMFitStrategy:       fitStrategy,
+		EnableSchemaMigration: enableSchemaMigration,
+
+		IPAMConsistencyCheckInterval: ipamCheckInterval,
+		IPAMAutoHeal:                 ipamAutoHeal,
+		EnableEndpointSliceSync:      enableEndpointSliceSync,
+		NodeDeleteIPWorkers:          nodeDeleteIPWorkers,
+		PodEventDebounce:             podEventDebounce,
+		SubnetRetryTimeout:           subnetRetryTimeout,
+
+		StatefulIPStalenessCheckInterval: statefulIPCheckInterval,
+		StatefulIPStalenessGracePeriod:   statefulIPGracePeriod,
+
+		IPInstanceAgeCheckInterval:               ipInstanceAgeInterval,
+		IPInstanceStatusConsistencyCheckInterval: ipInstanceStatusInterval,
+		NetworkObservabilityReportInterval:       observabilityInterval,
+
+		SubnetAllocationHistoryInterval:  allocationHistoryPeriod,
+		SubnetAllocationHistoryNamespace: historyNamespace,
+
+		DNSRegistrationZone: dnsRegistrationZone,
+		ControllerInstance:  controllerInstance,
+		NetworkShard:        networkShard,
+
+		IPAMDebugHandler: ipamDebugHandler,
 	}); err != nil {
 		entryLog.Error(err, "unable to register networking controllers")
 		os.Exit(1)
 	}
 
-	if feature.MultiClusterEnabled() {
+	if feature.MultiClusterEnabled() && networkShard.IsPrimary() {
 		if err = multicluster.RegisterToManager(globalContext, mgr, multicluster.RegisterOptions{
 			ConcurrencyMap: controllerConcurrency,
+			ClusterID:      clusterID,
 		}); err != nil {
 			entryLog.Error(err, "unable to register multi-cluster controllers")
 			os.Exit(1)
@@ -136,3 +340,29 @@ func main() {
 
 	<-globalContext.Done()
 }
+
+// overrideControllerConcurrency overrides the concurrency of controllers with the numbers of
+// workers specified by flags, zero means keeping the one of controller-concurrency
+func overrideControllerConcurrency(controllerConcurrency map[string]int, podWorkers, subnetWorkers, networkWorkers int) error {
+	for flagName, workers := range map[string]int{
+		"pod-controller-workers":     podWorkers,
+		"subnet-controller-workers":  subnetWorkers,
+		"network-controller-workers": networkWorkers,
+	} {
+		if workers < 0 {
+			return fmt.Errorf("%s must not be negative", flagName)
+		}
+	}
+
+	if podWorkers > 0 {
+		controllerConcurrency[networking.ControllerPod] = podWorkers
+	}
+	if subnetWorkers > 0 {
+		controllerConcurrency[networking.ControllerSubnet] = subnetWorkers
+		controllerConcurrency[networking.ControllerSubnetStatus] = subnetWorkers
+	}
+	if networkWorkers > 0 {
+		controllerConcurrency[networking.ControllerNetworkStatus] = networkWorkers
+	}
+	return nil
+}
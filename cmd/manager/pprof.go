@@ -0,0 +1,91 @@
+//go:build pprof
+
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// startPprofServer serves the standard pprof handlers on a separate port, only the
+// requests from allowed CIDRs are accepted
+func startPprofServer(ctx context.Context, port int, allowedCIDRs []string) error {
+	var allowedNets []*net.IPNet
+	for _, cidr := range allowedCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid pprof allowed cidr %s: %v", cidr, err)
+		}
+		allowedNets = append(allowedNets, ipNet)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           allowlistHandler(allowedNets, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	logger := ctrllog.Log.WithName("pprof")
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	go func() {
+		logger.Info("starting pprof server", "port", port, "allowed-cidrs", allowedCIDRs)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error(err, "pprof server exit unexpectedly")
+		}
+	}()
+
+	return nil
+}
+
+// allowlistHandler rejects the requests whose remote addresses are not in allowed networks,
+// all requests will be rejected if no allowed network is specified
+func allowlistHandler(allowedNets []*net.IPNet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		ip := net.ParseIP(host)
+		for _, ipNet := range allowedNets {
+			if ip != nil && ipNet.Contains(ip) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+}
@@ -0,0 +1,30 @@
+//go:build !pprof
+
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// startPprofServer always fails because pprof is excluded from the binary,
+// build with tag "pprof" to enable it
+func startPprofServer(_ context.Context, _ int, _ []string) error {
+	return fmt.Errorf("pprof is not compiled in, rebuild hybridnet manager with build tag \"pprof\"")
+}
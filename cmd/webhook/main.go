@@ -28,6 +28,7 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,15 +44,19 @@ import (
 )
 
 var (
-	gitCommit          string
-	scheme             = runtime.NewScheme()
-	port               int
-	metricsBindAddress string
+	gitCommit                string
+	scheme                   = runtime.NewScheme()
+	port                     int
+	metricsBindAddress       string
+	controllerServiceAccount string
+	ipv4SubnetMaskSizes      validating.MaskSizeRange
+	ipv6SubnetMaskSizes      validating.MaskSizeRange
 )
 
 func init() {
 	_ = corev1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
+	_ = authorizationv1.AddToScheme(scheme)
 	_ = networkingv1.AddToScheme(scheme)
 	_ = multiclusterv1.AddToScheme(scheme)
 	_ = admissionv1beta1.AddToScheme(scheme)
@@ -62,7 +67,13 @@ func init() {
 func main() {
 	// register flags
 	pflag.IntVar(&port, "port", 9898, "The port webhook listen on")
-	pflag.StringVar(&metricsBindAddress, "metrics-bind-address", "0", "The bind address for metrics, eg :8080")
+	pflag.StringVar(&metricsBindAddress, "metrics-bind-address", ":9897", "The bind address for metrics, eg :8080, 0 disables it")
+	pflag.StringVar(&controllerServiceAccount, "controller-service-account", "system:serviceaccount:kube-system:hybridnet",
+		"The user name of hybridnet components, whose requests are not reviewed for cluster-admin privileges")
+	pflag.IntVar(&ipv4SubnetMaskSizes.Min, "global-min-ipv4-subnet-mask", 0, "The minimum mask size of ipv4 subnets if network does not specify it, 0 means no restriction.")
+	pflag.IntVar(&ipv4SubnetMaskSizes.Max, "global-max-ipv4-subnet-mask", 0, "The maximum mask size of ipv4 subnets if network does not specify it, 0 means no restriction.")
+	pflag.IntVar(&ipv6SubnetMaskSizes.Min, "global-min-ipv6-subnet-mask", 0, "The minimum mask size of ipv6 subnets if network does not specify it, 0 means no restriction.")
+	pflag.IntVar(&ipv6SubnetMaskSizes.Max, "global-max-ipv6-subnet-mask", 0, "The maximum mask size of ipv6 subnets if network does not specify it, 0 means no restriction.")
 
 	// parse flags
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -73,6 +84,18 @@ func main() {
 	var entryLog = ctrllog.Log.WithName("entry")
 	entryLog.Info("starting hybridnet webhook", "known-features", feature.KnownFeatures(), "commit-id", gitCommit)
 
+	validatingOptions := validating.Options{
+		ControllerServiceAccount: controllerServiceAccount,
+		SubnetMaskSizes: map[networkingv1.IPVersion]validating.MaskSizeRange{
+			networkingv1.IPv4: ipv4SubnetMaskSizes,
+			networkingv1.IPv6: ipv6SubnetMaskSizes,
+		},
+	}
+	if err := validatingOptions.Validate(); err != nil {
+		entryLog.Error(err, "invalid flag")
+		os.Exit(1)
+	}
+
 	tlsCfgFunc := func(cfg *tls.Config) {
 		cfg.CipherSuites = cipherOrder()
 		cfg.MinVersion = tls.VersionTLS12
@@ -95,7 +118,7 @@ func main() {
 
 	// create webhooks
 	mgr.GetWebhookServer().Register("/validate", &webhook.Admission{
-		Handler: validating.NewHandler(),
+		Handler: validating.NewHandler(validatingOptions),
 	})
 	mgr.GetWebhookServer().Register("/mutate", &webhook.Admission{
 		Handler: mutating.NewHandler(),
@@ -42,6 +42,11 @@ type RemoteClusterSpec struct {
 	// Timeout is the maximum length of time to wait before giving up on a server request.
 	// A value of zero means no timeout.
 	Timeout int32 `json:"timeout,omitempty"`
+	// KubeConfigFile is the path of a kubeconfig file in manager, e.g., mounted from a secret,
+	// whose credentials are used instead of CAData, CertData and KeyData. Clients of the member
+	// cluster are rebuilt once the file changes.
+	// +kubuilder:validation:Optional
+	KubeConfigFile string `json:"kubeConfigFile,omitempty"`
 }
 
 // RemoteClusterStatus defines the observed state of RemoteCluster
@@ -34,6 +34,11 @@ type RemoteSubnetSpec struct {
 	// ClusterName is the name of parent cluster who owns this remote subnet.
 	// +kubebuilder:validation:Required
 	ClusterName string `json:"clusterName,omitempty"`
+	// RouteMetric is the metric of routes to this remote subnet on nodes, which can be used to make
+	// remote routes lose to local routes. If unset, the default metric of daemon will be used.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	RouteMetric *int32 `json:"routeMetric,omitempty"`
 }
 
 // RemoteSubnetStatus defines the observed state of RemoteSubnet
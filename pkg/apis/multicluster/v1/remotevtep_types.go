@@ -42,6 +42,18 @@ type RemoteVtepStatus struct {
 	// LastModifyTime shows the last timestamp when the remote VTEP was updated.
 	// +kubebuilder:validation:Optional
 	LastModifyTime metav1.Time `json:"lastModifyTime,omitempty"`
+	// Conditions represents the observations of nodes in local cluster on this remote VTEP,
+	// e.g., the BFD session state between them.
+	// +kubebuilder:validation:Optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+	// EffectiveMTUs is the max sizes of overlay packets which can reach this remote VTEP from
+	// nodes in local cluster, keyed by node names and probed with DF bit set.
+	// +kubebuilder:validation:Optional
+	EffectiveMTUs map[string]int32 `json:"effectiveMTUs,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -24,3 +24,7 @@ const (
 	ClusterOffline  = ClusterState("Offline")
 	ClusterUnknown  = ClusterState("Unknown")
 )
+
+// RemoteVtepConditionBFDSessionUp is the condition name of BFD session between a local node
+// and the remote VTEP, prefixed with the node name
+const RemoteVtepConditionBFDSessionUp = "BFDSessionUp"
@@ -25,3 +25,9 @@ func GetRemoteSubnetType(remoteSubnetObj *RemoteSubnet) networkingv1.NetworkType
 
 	return remoteSubnetObj.Spec.Type
 }
+
+// GetBFDSessionConditionType returns the condition type of BFD session between node and the
+// remote VTEP, every node owns its own condition
+func GetBFDSessionConditionType(nodeName string) string {
+	return nodeName + "/" + RemoteVtepConditionBFDSessionUp
+}
@@ -321,6 +321,11 @@ func (in *RemoteSubnetList) DeepCopyObject() runtime.Object {
 func (in *RemoteSubnetSpec) DeepCopyInto(out *RemoteSubnetSpec) {
 	*out = *in
 	in.Range.DeepCopyInto(&out.Range)
+	if in.RouteMetric != nil {
+		in, out := &in.RouteMetric, &out.RouteMetric
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteSubnetSpec.
@@ -433,6 +438,20 @@ func (in *RemoteVtepSpec) DeepCopy() *RemoteVtepSpec {
 func (in *RemoteVtepStatus) DeepCopyInto(out *RemoteVtepStatus) {
 	*out = *in
 	in.LastModifyTime.DeepCopyInto(&out.LastModifyTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EffectiveMTUs != nil {
+		in, out := &in.EffectiveMTUs, &out.EffectiveMTUs
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteVtepStatus.
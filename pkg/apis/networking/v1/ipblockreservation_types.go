@@ -0,0 +1,79 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPBlockReservationSpec defines the desired state of IPBlockReservation
+type IPBlockReservationSpec struct {
+	// Subnet is the name of subnet which the IP block is reserved from.
+	// +kubebuilder:validation:Required
+	Subnet string `json:"subnet"`
+	// Start is the first IP of the contiguous IP block.
+	// +kubebuilder:validation:Required
+	Start string `json:"start"`
+	// End is the last IP of the contiguous IP block.
+	// +kubebuilder:validation:Required
+	End string `json:"end"`
+}
+
+// IPBlockReservationStatus defines the observed state of IPBlockReservation
+type IPBlockReservationStatus struct {
+	// UsedIPs are the IPs of block being used by external services, which are reported out of band
+	// for inventory purposes.
+	// +kubebuilder:validation:Optional
+	UsedIPs []string `json:"usedIPs,omitempty"`
+	// UpdateTimestamp shows the last timestamp when the used IPs were reported.
+	// +kubebuilder:validation:Optional
+	UpdateTimestamp metav1.Time `json:"updateTimestamp,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Subnet",type=string,JSONPath=`.spec.subnet`
+// +kubebuilder:printcolumn:name="Start",type=string,JSONPath=`.spec.start`
+// +kubebuilder:printcolumn:name="End",type=string,JSONPath=`.spec.end`
+
+// IPBlockReservation is the Schema for the ipblockreservations API, IPs in the block will never be
+// allocated to pods and are reserved for the external services like VMs and appliances
+type IPBlockReservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IPBlockReservationSpec   `json:"spec,omitempty"`
+	Status IPBlockReservationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPBlockReservationList contains a list of IPBlockReservation
+type IPBlockReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPBlockReservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPBlockReservation{}, &IPBlockReservationList{})
+}
@@ -31,6 +31,26 @@ type IPInstanceSpec struct {
 	Address Address `json:"address"`
 	// +kubebuilder:validation:Optional
 	Binding Binding `json:"binding,omitempty"`
+	// SchemaVersion is the version of IPInstance schema, IPInstances without the latest
+	// schema version will be migrated to fill defaults of new fields.
+	// +kubebuilder:validation:Optional
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+	// AllocationContext records where the allocation of this IPInstance comes from, for audit.
+	// +kubebuilder:validation:Optional
+	AllocationContext *AllocationContext `json:"allocationContext,omitempty"`
+}
+
+// AllocationContext is the context of reconciliation which allocates or assigns an IPInstance
+type AllocationContext struct {
+	// TriggeringNode is the node of the pod which triggers the allocation.
+	// +kubebuilder:validation:Optional
+	TriggeringNode string `json:"triggeringNode,omitempty"`
+	// ControllerInstance is the identity of manager instance which allocates the IPInstance.
+	// +kubebuilder:validation:Optional
+	ControllerInstance string `json:"controllerInstance,omitempty"`
+	// ReconcileID is the unique ID of reconciliation which allocates the IPInstance.
+	// +kubebuilder:validation:Optional
+	ReconcileID types.UID `json:"reconcileID,omitempty"`
 }
 
 // Binding defines a binding object with necessary info of an IPInstance
@@ -78,6 +98,10 @@ type IPInstanceStatus struct {
 	SandboxID string `json:"sandboxID,omitempty"`
 	// +kubebuilder:validation:Optional
 	UpdateTimestamp metav1.Time `json:"updateTimestamp,omitempty"`
+	// DNSHostname is the hostname of pod registered in DNS for the underlay IP, which is the
+	// hostname of pod spec or pod name, with subdomain of pod spec appended if specified
+	// +kubebuilder:validation:Optional
+	DNSHostname string `json:"dnsHostname,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -34,6 +34,67 @@ type NetworkSpec struct {
 	Mode NetworkMode `json:"mode,omitempty"`
 	// +kubebuilder:validation:Optional
 	Config *NetworkConfig `json:"config,omitempty"`
+	// MinSubnetMaskSize is the minimum mask size of subnets created in this network
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	MinSubnetMaskSize *int32 `json:"minSubnetMaskSize,omitempty"`
+	// MaxSubnetMaskSize is the maximum mask size of subnets created in this network
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	MaxSubnetMaskSize *int32 `json:"maxSubnetMaskSize,omitempty"`
+	// MulticastGroup is the multicast address which BUM traffic of overlay network is flooded to
+	// +kubebuilder:validation:Optional
+	MulticastGroup string `json:"multicastGroup,omitempty"`
+	// Encapsulation is the vlan encapsulation of underlay VLAN network. For QinQ, frames of pods
+	// are double-tagged with ServiceVlanID as outer tag and net ID of subnet as inner tag
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Type=string
+	Encapsulation NetworkEncapsulation `json:"encapsulation,omitempty"`
+	// ServiceVlanID is the outer 802.1ad vlan tag (S-tag) of QinQ encapsulation
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	ServiceVlanID *int32 `json:"serviceVlanID,omitempty"`
+	// ChecksumOffloadMode decides whether udp tunnel checksum offload of VXLAN traffic is
+	// enabled on vtep interfaces, Auto means enabling it only if the nic supports, offload
+	// features of the nic are left unchanged if not set
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Auto;Enabled;Disabled
+	ChecksumOffloadMode ChecksumOffloadMode `json:"checksumOffloadMode,omitempty"`
+	// NodeMobilityGraceTimeout is how long the daemon waits before configuring a retained IP
+	// on a new node after it was released from another node, so that the old node can clean
+	// up the neigh caches of it, the wait never exceeds the termination grace period of pod
+	// +kubebuilder:validation:Optional
+	NodeMobilityGraceTimeout *metav1.Duration `json:"nodeMobilityGraceTimeout,omitempty"`
+	// NoSubnetPolicy decides how pods are handled when no subnet of network is available for
+	// them, Retry means requeueing them until a subnet is available, Fail means marking them
+	// as not network-ready, and Webhook means notifying NoSubnetWebhookURL besides retrying
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Retry;Fail;Webhook
+	NoSubnetPolicy NoSubnetPolicy `json:"noSubnetPolicy,omitempty"`
+	// NoSubnetWebhookURL is the url notified with pods having no available subnet, only
+	// works with Webhook policy
+	// +kubebuilder:validation:Optional
+	NoSubnetWebhookURL string `json:"noSubnetWebhookURL,omitempty"`
+	// SRIOVMode means pods of underlay VLAN network are assigned with SR-IOV virtual functions
+	// of the physical nic on node instead of virtual nics
+	// +kubebuilder:validation:Optional
+	SRIOVMode bool `json:"sriovMode,omitempty"`
+	// InheritNamespaceLabels is the list of namespace label keys copied onto IPInstances of
+	// pods in this network, so that IPInstances can be selected by namespace labels
+	// +kubebuilder:validation:Optional
+	InheritNamespaceLabels []string `json:"inheritNamespaceLabels,omitempty"`
+	// IPFamilyPreference decides which family of IPs is the primary address of DualStack pods,
+	// i.e., the first one of IPs in CNI result, IPv4First by default
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=IPv4First;IPv6First
+	IPFamilyPreference IPFamilyPreference `json:"ipFamilyPreference,omitempty"`
+	// BGPPodIPAnnouncement means IPs of pods in overlay network are announced as host routes to
+	// the bgp peers of nodes, so that pods can be reached directly from outside the cluster
+	// +kubebuilder:validation:Optional
+	BGPPodIPAnnouncement bool `json:"bgpPodIPAnnouncement,omitempty"`
 }
 
 // NetworkStatus defines the observed state of Network
@@ -0,0 +1,59 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkingPolicySpec defines the desired state of NetworkingPolicy
+type NetworkingPolicySpec struct {
+	// MaxIPInstanceAge is the maximum age of IPInstances, IPInstances older than it will be
+	// purged once their pods no longer exist, even if they are reserved, empty means never purging.
+	// +kubebuilder:validation:Optional
+	MaxIPInstanceAge *metav1.Duration `json:"maxIPInstanceAge,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="MaxIPInstanceAge",type=string,JSONPath=`.spec.maxIPInstanceAge`
+
+// NetworkingPolicy is the Schema for the networkingpolicies API, it holds cluster-wide policies
+// of networking resources like compliance requirements
+type NetworkingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetworkingPolicySpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkingPolicyList contains a list of NetworkingPolicy
+type NetworkingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkingPolicy{}, &NetworkingPolicyList{})
+}
@@ -0,0 +1,112 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPInstanceFamilyCount describes the numbers of IPInstances of each IP family
+type IPInstanceFamilyCount struct {
+	// +kubebuilder:validation:Optional
+	IPv4 int32 `json:"ipv4"`
+	// +kubebuilder:validation:Optional
+	IPv6 int32 `json:"ipv6"`
+}
+
+// RemoteClusterConnectivity describes the connectivity status of a remote cluster
+type RemoteClusterConnectivity struct {
+	// Name is the name of RemoteCluster
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// State is the connection state of RemoteCluster, e.g., Ready, NotReady or Offline
+	// +kubebuilder:validation:Optional
+	State string `json:"state,omitempty"`
+}
+
+// NetworkObservabilitySnapshot is the cluster-wide networking stats at a moment
+type NetworkObservabilitySnapshot struct {
+	// Timestamp shows when the stats are collected.
+	// +kubebuilder:validation:Optional
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+	// Networks is the number of Networks.
+	// +kubebuilder:validation:Optional
+	Networks int32 `json:"networks"`
+	// Subnets is the number of Subnets.
+	// +kubebuilder:validation:Optional
+	Subnets int32 `json:"subnets"`
+	// IPInstances is the numbers of IPInstances by IP family.
+	// +kubebuilder:validation:Optional
+	IPInstances IPInstanceFamilyCount `json:"ipInstances"`
+	// OverlayNodes is the number of nodes attached to overlay networks.
+	// +kubebuilder:validation:Optional
+	OverlayNodes int32 `json:"overlayNodes"`
+	// UnderlayNodes is the number of nodes attached to underlay networks.
+	// +kubebuilder:validation:Optional
+	UnderlayNodes int32 `json:"underlayNodes"`
+	// RemoteClusters is the connectivity status of remote clusters, only reported when
+	// multicluster is enabled.
+	// +kubebuilder:validation:Optional
+	RemoteClusters []RemoteClusterConnectivity `json:"remoteClusters,omitempty"`
+}
+
+// NetworkObservabilityReportStatus defines the observed state of NetworkObservabilityReport
+type NetworkObservabilityReportStatus struct {
+	NetworkObservabilitySnapshot `json:",inline"`
+
+	// History is the hourly archived snapshots, the oldest first.
+	// +kubebuilder:validation:Optional
+	History []NetworkObservabilitySnapshot `json:"history,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Networks",type=integer,JSONPath=`.status.networks`
+// +kubebuilder:printcolumn:name="Subnets",type=integer,JSONPath=`.status.subnets`
+// +kubebuilder:printcolumn:name="IPv4Instances",type=integer,JSONPath=`.status.ipInstances.ipv4`
+// +kubebuilder:printcolumn:name="IPv6Instances",type=integer,JSONPath=`.status.ipInstances.ipv6`
+// +kubebuilder:printcolumn:name="OverlayNodes",type=integer,JSONPath=`.status.overlayNodes`
+// +kubebuilder:printcolumn:name="UnderlayNodes",type=integer,JSONPath=`.status.underlayNodes`
+// +kubebuilder:printcolumn:name="Timestamp",type=date,JSONPath=`.status.timestamp`
+
+// NetworkObservabilityReport is the Schema for the networkobservabilityreports API, it is
+// populated periodically by manager with cluster-wide networking stats, so that they can be
+// consumed through apiserver without a metrics system
+type NetworkObservabilityReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NetworkObservabilityReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkObservabilityReportList contains a list of NetworkObservabilityReport
+type NetworkObservabilityReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkObservabilityReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkObservabilityReport{}, &NetworkObservabilityReportList{})
+}
@@ -0,0 +1,77 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkQuotaResources describes the amounts of network resources
+type NetworkQuotaResources struct {
+	// IPs is the number of IPInstances across all networks.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	IPs *int64 `json:"ips,omitempty"`
+}
+
+// NetworkQuotaSpec defines the desired state of NetworkQuota
+type NetworkQuotaSpec struct {
+	// Hard is the set of enforced hard limits of namespace.
+	// +kubebuilder:validation:Optional
+	Hard NetworkQuotaResources `json:"hard,omitempty"`
+}
+
+// NetworkQuotaStatus defines the observed state of NetworkQuota
+type NetworkQuotaStatus struct {
+	// Used is the current observed usage of namespace.
+	// +kubebuilder:validation:Optional
+	Used NetworkQuotaResources `json:"used,omitempty"`
+	// UpdateTimestamp shows the last timestamp when the usage was updated.
+	// +kubebuilder:validation:Optional
+	UpdateTimestamp metav1.Time `json:"updateTimestamp,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="HardIPs",type=integer,JSONPath=`.spec.hard.ips`
+// +kubebuilder:printcolumn:name="UsedIPs",type=integer,JSONPath=`.status.used.ips`
+
+// NetworkQuota is the Schema for the networkquotas API, it caps the network resources
+// which pods of a namespace can consume like ResourceQuota
+type NetworkQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NetworkQuotaSpec   `json:"spec,omitempty"`
+	Status NetworkQuotaStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NetworkQuotaList contains a list of NetworkQuota
+type NetworkQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NetworkQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NetworkQuota{}, &NetworkQuotaList{})
+}
@@ -33,6 +33,15 @@ type SubnetSpec struct {
 	Network string `json:"network"`
 	// +kubebuilder:validation:Optional
 	Config *SubnetConfig `json:"config"`
+	// +kubebuilder:validation:Optional
+	EvictionPolicy *SubnetEvictionPolicy `json:"evictionPolicy,omitempty"`
+	// PodRoutes are additional routes of pods using this vlan subnet, for destinations which
+	// are not reachable via the default gateway but through next hops inside subnet.
+	// +kubebuilder:validation:Optional
+	PodRoutes []StaticRoute `json:"podRoutes,omitempty"`
+	// DNSDomain is the dns domain injected into the dns search list of pods using this subnet
+	// +kubebuilder:validation:Optional
+	DNSDomain string `json:"dnsDomain,omitempty"`
 }
 
 // SubnetStatus defines the observed state of Subnet
@@ -41,6 +50,30 @@ type SubnetStatus struct {
 	Count `json:",inline"`
 	// +kubebuilder:validation:Optional
 	LastAllocatedIP string `json:"lastAllocatedIP"`
+	// TotalIPv4 and UsedIPv4 are the IPv4 part of statistics, which are always zero for IPv6 subnets.
+	// +kubebuilder:validation:Optional
+	TotalIPv4 int32 `json:"totalIPv4,omitempty"`
+	// +kubebuilder:validation:Optional
+	UsedIPv4 int32 `json:"usedIPv4,omitempty"`
+	// TotalIPv6 and UsedIPv6 are the IPv6 part of statistics, which are always zero for IPv4 subnets.
+	// +kubebuilder:validation:Optional
+	TotalIPv6 int32 `json:"totalIPv6,omitempty"`
+	// +kubebuilder:validation:Optional
+	UsedIPv6 int32 `json:"usedIPv6,omitempty"`
+	// LastAllocationTime shows the last timestamp when an IP of subnet was allocated.
+	// +kubebuilder:validation:Optional
+	LastAllocationTime metav1.Time `json:"lastAllocationTime,omitempty"`
+	// LastReleaseTime shows the last timestamp when an IP of subnet was released.
+	// +kubebuilder:validation:Optional
+	LastReleaseTime metav1.Time `json:"lastReleaseTime,omitempty"`
+	// Conditions represents the observations of controllers on this subnet, e.g., whether the
+	// reconciliation has been given up after too many failures.
+	// +kubebuilder:validation:Optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
 // +k8s:openapi-gen=true
@@ -0,0 +1,96 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SubnetPoolSpec defines the desired state of SubnetPool
+type SubnetPoolSpec struct {
+	// Network is the name of network which subnets carved from the pool belong to.
+	// +kubebuilder:validation:Required
+	Network string `json:"network"`
+	// CIDR is the supernet which subnets are carved from.
+	// +kubebuilder:validation:Required
+	CIDR string `json:"cidr"`
+	// SubnetMaskSize is the mask size of each subnet carved from the pool, e.g., 24.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=128
+	SubnetMaskSize int32 `json:"subnetMaskSize"`
+	// MinSubnets is the number of subnets always kept in the pool even if they are empty.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinSubnets int32 `json:"minSubnets,omitempty"`
+	// MaxSubnets is the maximum number of subnets carved from the pool, zero means only limited
+	// by the size of pool.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxSubnets int32 `json:"maxSubnets,omitempty"`
+	// NetID is the net ID of subnets carved from the pool.
+	// +kubebuilder:validation:Optional
+	NetID *int32 `json:"netID,omitempty"`
+}
+
+// SubnetPoolStatus defines the observed state of SubnetPool
+type SubnetPoolStatus struct {
+	// Subnets are the names of subnets carved from the pool.
+	// +kubebuilder:validation:Optional
+	Subnets []string `json:"subnets,omitempty"`
+	// Count is the IP statistics summed over subnets carved from the pool.
+	// +kubebuilder:validation:Optional
+	Count `json:",inline"`
+	// LastScaleTime shows the last timestamp when a subnet was carved or reclaimed.
+	// +kubebuilder:validation:Optional
+	LastScaleTime metav1.Time `json:"lastScaleTime,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Network",type=string,JSONPath=`.spec.network`
+// +kubebuilder:printcolumn:name="CIDR",type=string,JSONPath=`.spec.cidr`
+// +kubebuilder:printcolumn:name="SubnetMaskSize",type=integer,JSONPath=`.spec.subnetMaskSize`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.available`
+
+// SubnetPool is the Schema for the subnetpools API, subnets of a network are carved from the pool
+// automatically as demand grows and reclaimed once they are empty
+type SubnetPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubnetPoolSpec   `json:"spec,omitempty"`
+	Status SubnetPoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SubnetPoolList contains a list of SubnetPool
+type SubnetPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SubnetPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SubnetPool{}, &SubnetPoolList{})
+}
@@ -33,6 +33,35 @@ const (
 	NetworkModeGlobalBGP = NetworkMode("GlobalBGP")
 )
 
+type ChecksumOffloadMode string
+
+const (
+	ChecksumOffloadModeAuto     = ChecksumOffloadMode("Auto")
+	ChecksumOffloadModeEnabled  = ChecksumOffloadMode("Enabled")
+	ChecksumOffloadModeDisabled = ChecksumOffloadMode("Disabled")
+)
+
+type NoSubnetPolicy string
+
+const (
+	NoSubnetPolicyRetry   = NoSubnetPolicy("Retry")
+	NoSubnetPolicyFail    = NoSubnetPolicy("Fail")
+	NoSubnetPolicyWebhook = NoSubnetPolicy("Webhook")
+)
+
+type IPFamilyPreference string
+
+const (
+	IPFamilyPreferenceIPv4First = IPFamilyPreference("IPv4First")
+	IPFamilyPreferenceIPv6First = IPFamilyPreference("IPv6First")
+)
+
+type NetworkEncapsulation string
+
+const (
+	NetworkEncapsulationQinQ = NetworkEncapsulation("QinQ")
+)
+
 type Count struct {
 	// +kubebuilder:validation:Optional
 	Total int32 `json:"total"`
@@ -79,6 +108,23 @@ type SubnetConfig struct {
 	AllowSubnets []string `json:"allowSubnets"`
 }
 
+// SubnetEvictionPolicy describes whether running pods can be evicted to reclaim
+// addresses when subnet is exhausted
+type SubnetEvictionPolicy struct {
+	// Enabled means the lowest-priority running pod of an exhausted subnet will be evicted
+	// for a pending pod with higher priority
+	// +kubebuilder:validation:Optional
+	Enabled bool `json:"enabled"`
+}
+
+// StaticRoute describes a route to destination CIDR through a next hop
+type StaticRoute struct {
+	// +kubebuilder:validation:Required
+	Destination string `json:"destination"`
+	// +kubebuilder:validation:Required
+	NextHop string `json:"nextHop"`
+}
+
 type NetworkConfig struct {
 	// +kubebuilder:validation:Optional
 	BGPPeers []BGPPeer `json:"bgpPeers,omitempty"`
@@ -100,8 +146,13 @@ type Address struct {
 type BGPPeer struct {
 	// +kubebuilder:validation:Required
 	ASN int32 `json:"asn"`
-	// +kubebuilder:validation:Required
-	Address string `json:"address"`
+	// Address is the IP of peer, which must be empty for BGP unnumbered peers.
+	// +kubebuilder:validation:Optional
+	Address string `json:"address,omitempty"`
+	// BGPUnnumbered peers are discovered by IPv6 link-local addresses on the bgp interface of nodes,
+	// and IPv4 prefixes are advertised to them with IPv6 link-local next hops (RFC 5549).
+	// +kubebuilder:validation:Optional
+	BGPUnnumbered bool `json:"bgpUnnumbered,omitempty"`
 	// +kubebuilder:validation:Optional
 	GracefulRestartSeconds int32 `json:"gracefulRestartSeconds,omitempty"`
 	// +kubebuilder:validation:Optional
@@ -129,3 +180,15 @@ const (
 	IPInstanceV12           = "v1.2"
 	IPInstanceLatestVersion = IPInstanceV12
 )
+
+// Schema versions of IPInstance spec, which are different from the versions in IPInstance labels.
+// Schema v1 introduces the referred object of binding.
+const (
+	IPInstanceSchemaV1            = "v1"
+	IPInstanceSchemaV2            = "v2"
+	IPInstanceLatestSchemaVersion = IPInstanceSchemaV2
+)
+
+// SubnetConditionReconcileFailed is the condition of subnet whose reconciliation is given up
+// after too many consecutive failures, it is cleared after the subnet is reconciled again.
+const SubnetConditionReconcileFailed = "ReconcileFailed"
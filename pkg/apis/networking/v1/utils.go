@@ -80,6 +80,37 @@ func GetNetworkMode(networkObj *Network) NetworkMode {
 	return networkObj.Spec.Mode
 }
 
+// GetNoSubnetPolicy returns the no-subnet policy of network, Retry by default
+func GetNoSubnetPolicy(networkObj *Network) NoSubnetPolicy {
+	if networkObj == nil || len(networkObj.Spec.NoSubnetPolicy) == 0 {
+		return NoSubnetPolicyRetry
+	}
+	return networkObj.Spec.NoSubnetPolicy
+}
+
+// GetIPFamilyPreference returns the ip family preference of network, IPv4First by default
+func GetIPFamilyPreference(networkObj *Network) IPFamilyPreference {
+	if networkObj == nil || len(networkObj.Spec.IPFamilyPreference) == 0 {
+		return IPFamilyPreferenceIPv4First
+	}
+	return networkObj.Spec.IPFamilyPreference
+}
+
+// IsSRIOVNetwork checks if pods of network are assigned with SR-IOV virtual functions
+func IsSRIOVNetwork(networkObj *Network) bool {
+	return networkObj != nil && networkObj.Spec.SRIOVMode
+}
+
+// IsBGPPodIPAnnouncementNetwork checks if IPs of pods in network are announced to bgp peers of nodes
+func IsBGPPodIPAnnouncementNetwork(networkObj *Network) bool {
+	return networkObj != nil && networkObj.Spec.BGPPodIPAnnouncement
+}
+
+// IsQinQNetwork checks if frames of network are double-tagged through QinQ encapsulation
+func IsQinQNetwork(networkObj *Network) bool {
+	return networkObj != nil && networkObj.Spec.Encapsulation == NetworkEncapsulationQinQ
+}
+
 func IsGlobalUniqueNetwork(networkObj *Network) bool {
 	return IsGlobalUniqueNetworkType(GetNetworkType(networkObj))
 }
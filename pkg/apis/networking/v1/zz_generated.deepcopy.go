@@ -22,6 +22,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -85,6 +86,21 @@ func (in *BGPPeer) DeepCopy() *BGPPeer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllocationContext) DeepCopyInto(out *AllocationContext) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationContext.
+func (in *AllocationContext) DeepCopy() *AllocationContext {
+	if in == nil {
+		return nil
+	}
+	out := new(AllocationContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Binding) DeepCopyInto(out *Binding) {
 	*out = *in
@@ -121,6 +137,101 @@ func (in *Count) DeepCopy() *Count {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPBlockReservation) DeepCopyInto(out *IPBlockReservation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPBlockReservation.
+func (in *IPBlockReservation) DeepCopy() *IPBlockReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPBlockReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPBlockReservation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPBlockReservationList) DeepCopyInto(out *IPBlockReservationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPBlockReservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPBlockReservationList.
+func (in *IPBlockReservationList) DeepCopy() *IPBlockReservationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPBlockReservationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPBlockReservationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPBlockReservationSpec) DeepCopyInto(out *IPBlockReservationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPBlockReservationSpec.
+func (in *IPBlockReservationSpec) DeepCopy() *IPBlockReservationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPBlockReservationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPBlockReservationStatus) DeepCopyInto(out *IPBlockReservationStatus) {
+	*out = *in
+	if in.UsedIPs != nil {
+		in, out := &in.UsedIPs, &out.UsedIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.UpdateTimestamp.DeepCopyInto(&out.UpdateTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPBlockReservationStatus.
+func (in *IPBlockReservationStatus) DeepCopy() *IPBlockReservationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IPBlockReservationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPInstance) DeepCopyInto(out *IPInstance) {
 	*out = *in
@@ -148,6 +259,21 @@ func (in *IPInstance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPInstanceFamilyCount) DeepCopyInto(out *IPInstanceFamilyCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPInstanceFamilyCount.
+func (in *IPInstanceFamilyCount) DeepCopy() *IPInstanceFamilyCount {
+	if in == nil {
+		return nil
+	}
+	out := new(IPInstanceFamilyCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPInstanceList) DeepCopyInto(out *IPInstanceList) {
 	*out = *in
@@ -210,6 +336,11 @@ func (in *IPInstanceSpec) DeepCopyInto(out *IPInstanceSpec) {
 	*out = *in
 	in.Address.DeepCopyInto(&out.Address)
 	in.Binding.DeepCopyInto(&out.Binding)
+	if in.AllocationContext != nil {
+		in, out := &in.AllocationContext, &out.AllocationContext
+		*out = new(AllocationContext)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPInstanceSpec.
@@ -317,6 +448,221 @@ func (in *NetworkList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkObservabilityReport) DeepCopyInto(out *NetworkObservabilityReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkObservabilityReport.
+func (in *NetworkObservabilityReport) DeepCopy() *NetworkObservabilityReport {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkObservabilityReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkObservabilityReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkObservabilityReportList) DeepCopyInto(out *NetworkObservabilityReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkObservabilityReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkObservabilityReportList.
+func (in *NetworkObservabilityReportList) DeepCopy() *NetworkObservabilityReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkObservabilityReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkObservabilityReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkObservabilityReportStatus) DeepCopyInto(out *NetworkObservabilityReportStatus) {
+	*out = *in
+	in.NetworkObservabilitySnapshot.DeepCopyInto(&out.NetworkObservabilitySnapshot)
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]NetworkObservabilitySnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkObservabilityReportStatus.
+func (in *NetworkObservabilityReportStatus) DeepCopy() *NetworkObservabilityReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkObservabilityReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkObservabilitySnapshot) DeepCopyInto(out *NetworkObservabilitySnapshot) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	out.IPInstances = in.IPInstances
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]RemoteClusterConnectivity, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkObservabilitySnapshot.
+func (in *NetworkObservabilitySnapshot) DeepCopy() *NetworkObservabilitySnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkObservabilitySnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQuota) DeepCopyInto(out *NetworkQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQuota.
+func (in *NetworkQuota) DeepCopy() *NetworkQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQuotaList) DeepCopyInto(out *NetworkQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQuotaList.
+func (in *NetworkQuotaList) DeepCopy() *NetworkQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQuotaResources) DeepCopyInto(out *NetworkQuotaResources) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQuotaResources.
+func (in *NetworkQuotaResources) DeepCopy() *NetworkQuotaResources {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQuotaResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQuotaSpec) DeepCopyInto(out *NetworkQuotaSpec) {
+	*out = *in
+	in.Hard.DeepCopyInto(&out.Hard)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQuotaSpec.
+func (in *NetworkQuotaSpec) DeepCopy() *NetworkQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQuotaStatus) DeepCopyInto(out *NetworkQuotaStatus) {
+	*out = *in
+	in.Used.DeepCopyInto(&out.Used)
+	in.UpdateTimestamp.DeepCopyInto(&out.UpdateTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQuotaStatus.
+func (in *NetworkQuotaStatus) DeepCopy() *NetworkQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -337,6 +683,31 @@ func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 		*out = new(NetworkConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MinSubnetMaskSize != nil {
+		in, out := &in.MinSubnetMaskSize, &out.MinSubnetMaskSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSubnetMaskSize != nil {
+		in, out := &in.MaxSubnetMaskSize, &out.MaxSubnetMaskSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServiceVlanID != nil {
+		in, out := &in.ServiceVlanID, &out.ServiceVlanID
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeMobilityGraceTimeout != nil {
+		in, out := &in.NodeMobilityGraceTimeout, &out.NodeMobilityGraceTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.InheritNamespaceLabels != nil {
+		in, out := &in.InheritNamespaceLabels, &out.InheritNamespaceLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
@@ -389,6 +760,84 @@ func (in *NetworkStatus) DeepCopy() *NetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkingPolicy) DeepCopyInto(out *NetworkingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkingPolicy.
+func (in *NetworkingPolicy) DeepCopy() *NetworkingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkingPolicyList) DeepCopyInto(out *NetworkingPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkingPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkingPolicyList.
+func (in *NetworkingPolicyList) DeepCopy() *NetworkingPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkingPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkingPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkingPolicySpec) DeepCopyInto(out *NetworkingPolicySpec) {
+	*out = *in
+	if in.MaxIPInstanceAge != nil {
+		in, out := &in.MaxIPInstanceAge, &out.MaxIPInstanceAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkingPolicySpec.
+func (in *NetworkingPolicySpec) DeepCopy() *NetworkingPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeInfo) DeepCopyInto(out *NodeInfo) {
 	*out = *in
@@ -499,6 +948,21 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterConnectivity) DeepCopyInto(out *RemoteClusterConnectivity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterConnectivity.
+func (in *RemoteClusterConnectivity) DeepCopy() *RemoteClusterConnectivity {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterConnectivity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulInfo) DeepCopyInto(out *StatefulInfo) {
 	*out = *in
@@ -519,13 +983,28 @@ func (in *StatefulInfo) DeepCopy() *StatefulInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRoute) DeepCopyInto(out *StaticRoute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRoute.
+func (in *StaticRoute) DeepCopy() *StaticRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subnet) DeepCopyInto(out *Subnet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subnet.
@@ -576,6 +1055,21 @@ func (in *SubnetConfig) DeepCopy() *SubnetConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetEvictionPolicy) DeepCopyInto(out *SubnetEvictionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetEvictionPolicy.
+func (in *SubnetEvictionPolicy) DeepCopy() *SubnetEvictionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetEvictionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetList) DeepCopyInto(out *SubnetList) {
 	*out = *in
@@ -608,6 +1102,107 @@ func (in *SubnetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetPool) DeepCopyInto(out *SubnetPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetPool.
+func (in *SubnetPool) DeepCopy() *SubnetPool {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubnetPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetPoolList) DeepCopyInto(out *SubnetPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubnetPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetPoolList.
+func (in *SubnetPoolList) DeepCopy() *SubnetPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubnetPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetPoolSpec) DeepCopyInto(out *SubnetPoolSpec) {
+	*out = *in
+	if in.NetID != nil {
+		in, out := &in.NetID, &out.NetID
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetPoolSpec.
+func (in *SubnetPoolSpec) DeepCopy() *SubnetPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetPoolStatus) DeepCopyInto(out *SubnetPoolStatus) {
+	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Count = in.Count
+	in.LastScaleTime.DeepCopyInto(&out.LastScaleTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetPoolStatus.
+func (in *SubnetPoolStatus) DeepCopy() *SubnetPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 	*out = *in
@@ -622,6 +1217,16 @@ func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 		*out = new(SubnetConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.EvictionPolicy != nil {
+		in, out := &in.EvictionPolicy, &out.EvictionPolicy
+		*out = new(SubnetEvictionPolicy)
+		**out = **in
+	}
+	if in.PodRoutes != nil {
+		in, out := &in.PodRoutes, &out.PodRoutes
+		*out = make([]StaticRoute, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSpec.
@@ -638,6 +1243,15 @@ func (in *SubnetSpec) DeepCopy() *SubnetSpec {
 func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
 	*out = *in
 	out.Count = in.Count
+	in.LastAllocationTime.DeepCopyInto(&out.LastAllocationTime)
+	in.LastReleaseTime.DeepCopyInto(&out.LastReleaseTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetStatus.
@@ -27,6 +27,10 @@ type FakeNetworkingV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeNetworkingV1) IPBlockReservations() v1.IPBlockReservationInterface {
+	return &FakeIPBlockReservations{c}
+}
+
 func (c *FakeNetworkingV1) IPInstances(namespace string) v1.IPInstanceInterface {
 	return &FakeIPInstances{c, namespace}
 }
@@ -35,6 +39,18 @@ func (c *FakeNetworkingV1) Networks() v1.NetworkInterface {
 	return &FakeNetworks{c}
 }
 
+func (c *FakeNetworkingV1) NetworkObservabilityReports() v1.NetworkObservabilityReportInterface {
+	return &FakeNetworkObservabilityReports{c}
+}
+
+func (c *FakeNetworkingV1) NetworkQuotas(namespace string) v1.NetworkQuotaInterface {
+	return &FakeNetworkQuotas{c, namespace}
+}
+
+func (c *FakeNetworkingV1) NetworkingPolicies() v1.NetworkingPolicyInterface {
+	return &FakeNetworkingPolicies{c}
+}
+
 func (c *FakeNetworkingV1) NodeInfos() v1.NodeInfoInterface {
 	return &FakeNodeInfos{c}
 }
@@ -43,6 +59,10 @@ func (c *FakeNetworkingV1) Subnets() v1.SubnetInterface {
 	return &FakeSubnets{c}
 }
 
+func (c *FakeNetworkingV1) SubnetPools() v1.SubnetPoolInterface {
+	return &FakeSubnetPools{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeNetworkingV1) RESTClient() rest.Interface {
@@ -17,10 +17,20 @@ limitations under the License.
 
 package v1
 
+type IPBlockReservationExpansion interface{}
+
 type IPInstanceExpansion interface{}
 
 type NetworkExpansion interface{}
 
+type NetworkObservabilityReportExpansion interface{}
+
+type NetworkQuotaExpansion interface{}
+
+type NetworkingPolicyExpansion interface{}
+
 type NodeInfoExpansion interface{}
 
 type SubnetExpansion interface{}
+
+type SubnetPoolExpansion interface{}
@@ -27,10 +27,15 @@ import (
 
 type NetworkingV1Interface interface {
 	RESTClient() rest.Interface
+	IPBlockReservationsGetter
 	IPInstancesGetter
 	NetworksGetter
+	NetworkObservabilityReportsGetter
+	NetworkQuotasGetter
+	NetworkingPoliciesGetter
 	NodeInfosGetter
 	SubnetsGetter
+	SubnetPoolsGetter
 }
 
 // NetworkingV1Client is used to interact with features provided by the networking group.
@@ -38,6 +43,10 @@ type NetworkingV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *NetworkingV1Client) IPBlockReservations() IPBlockReservationInterface {
+	return newIPBlockReservations(c)
+}
+
 func (c *NetworkingV1Client) IPInstances(namespace string) IPInstanceInterface {
 	return newIPInstances(c, namespace)
 }
@@ -46,6 +55,18 @@ func (c *NetworkingV1Client) Networks() NetworkInterface {
 	return newNetworks(c)
 }
 
+func (c *NetworkingV1Client) NetworkObservabilityReports() NetworkObservabilityReportInterface {
+	return newNetworkObservabilityReports(c)
+}
+
+func (c *NetworkingV1Client) NetworkQuotas(namespace string) NetworkQuotaInterface {
+	return newNetworkQuotas(c, namespace)
+}
+
+func (c *NetworkingV1Client) NetworkingPolicies() NetworkingPolicyInterface {
+	return newNetworkingPolicies(c)
+}
+
 func (c *NetworkingV1Client) NodeInfos() NodeInfoInterface {
 	return newNodeInfos(c)
 }
@@ -54,6 +75,10 @@ func (c *NetworkingV1Client) Subnets() SubnetInterface {
 	return newSubnets(c)
 }
 
+func (c *NetworkingV1Client) SubnetPools() SubnetPoolInterface {
+	return newSubnetPools(c)
+}
+
 // NewForConfig creates a new NetworkingV1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
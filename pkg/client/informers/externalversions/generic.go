@@ -63,14 +63,24 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1().RemoteVteps().Informer()}, nil
 
 		// Group=networking, Version=v1
+	case networkingv1.SchemeGroupVersion.WithResource("ipblockreservations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().IPBlockReservations().Informer()}, nil
 	case networkingv1.SchemeGroupVersion.WithResource("ipinstances"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().IPInstances().Informer()}, nil
 	case networkingv1.SchemeGroupVersion.WithResource("networks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().Networks().Informer()}, nil
+	case networkingv1.SchemeGroupVersion.WithResource("networkobservabilityreports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().NetworkObservabilityReports().Informer()}, nil
+	case networkingv1.SchemeGroupVersion.WithResource("networkquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().NetworkQuotas().Informer()}, nil
+	case networkingv1.SchemeGroupVersion.WithResource("networkingpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().NetworkingPolicies().Informer()}, nil
 	case networkingv1.SchemeGroupVersion.WithResource("nodeinfos"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().NodeInfos().Informer()}, nil
 	case networkingv1.SchemeGroupVersion.WithResource("subnets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().Subnets().Informer()}, nil
+	case networkingv1.SchemeGroupVersion.WithResource("subnetpools"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networking().V1().SubnetPools().Informer()}, nil
 
 	}
 
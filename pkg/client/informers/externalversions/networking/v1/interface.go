@@ -23,14 +23,24 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// IPBlockReservations returns a IPBlockReservationInformer.
+	IPBlockReservations() IPBlockReservationInformer
 	// IPInstances returns a IPInstanceInformer.
 	IPInstances() IPInstanceInformer
 	// Networks returns a NetworkInformer.
 	Networks() NetworkInformer
+	// NetworkObservabilityReports returns a NetworkObservabilityReportInformer.
+	NetworkObservabilityReports() NetworkObservabilityReportInformer
+	// NetworkQuotas returns a NetworkQuotaInformer.
+	NetworkQuotas() NetworkQuotaInformer
+	// NetworkingPolicies returns a NetworkingPolicyInformer.
+	NetworkingPolicies() NetworkingPolicyInformer
 	// NodeInfos returns a NodeInfoInformer.
 	NodeInfos() NodeInfoInformer
 	// Subnets returns a SubnetInformer.
 	Subnets() SubnetInformer
+	// SubnetPools returns a SubnetPoolInformer.
+	SubnetPools() SubnetPoolInformer
 }
 
 type version struct {
@@ -44,6 +54,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// IPBlockReservations returns a IPBlockReservationInformer.
+func (v *version) IPBlockReservations() IPBlockReservationInformer {
+	return &iPBlockReservationInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // IPInstances returns a IPInstanceInformer.
 func (v *version) IPInstances() IPInstanceInformer {
 	return &iPInstanceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -54,6 +69,21 @@ func (v *version) Networks() NetworkInformer {
 	return &networkInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// NetworkObservabilityReports returns a NetworkObservabilityReportInformer.
+func (v *version) NetworkObservabilityReports() NetworkObservabilityReportInformer {
+	return &networkObservabilityReportInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// NetworkQuotas returns a NetworkQuotaInformer.
+func (v *version) NetworkQuotas() NetworkQuotaInformer {
+	return &networkQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// NetworkingPolicies returns a NetworkingPolicyInformer.
+func (v *version) NetworkingPolicies() NetworkingPolicyInformer {
+	return &networkingPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // NodeInfos returns a NodeInfoInformer.
 func (v *version) NodeInfos() NodeInfoInformer {
 	return &nodeInfoInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -63,3 +93,8 @@ func (v *version) NodeInfos() NodeInfoInformer {
 func (v *version) Subnets() SubnetInformer {
 	return &subnetInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// SubnetPools returns a SubnetPoolInformer.
+func (v *version) SubnetPools() SubnetPoolInformer {
+	return &subnetPoolInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
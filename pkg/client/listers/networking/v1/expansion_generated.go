@@ -17,6 +17,10 @@ limitations under the License.
 
 package v1
 
+// IPBlockReservationListerExpansion allows custom methods to be added to
+// IPBlockReservationLister.
+type IPBlockReservationListerExpansion interface{}
+
 // IPInstanceListerExpansion allows custom methods to be added to
 // IPInstanceLister.
 type IPInstanceListerExpansion interface{}
@@ -29,6 +33,22 @@ type IPInstanceNamespaceListerExpansion interface{}
 // NetworkLister.
 type NetworkListerExpansion interface{}
 
+// NetworkObservabilityReportListerExpansion allows custom methods to be added to
+// NetworkObservabilityReportLister.
+type NetworkObservabilityReportListerExpansion interface{}
+
+// NetworkQuotaListerExpansion allows custom methods to be added to
+// NetworkQuotaLister.
+type NetworkQuotaListerExpansion interface{}
+
+// NetworkQuotaNamespaceListerExpansion allows custom methods to be added to
+// NetworkQuotaNamespaceLister.
+type NetworkQuotaNamespaceListerExpansion interface{}
+
+// NetworkingPolicyListerExpansion allows custom methods to be added to
+// NetworkingPolicyLister.
+type NetworkingPolicyListerExpansion interface{}
+
 // NodeInfoListerExpansion allows custom methods to be added to
 // NodeInfoLister.
 type NodeInfoListerExpansion interface{}
@@ -36,3 +56,7 @@ type NodeInfoListerExpansion interface{}
 // SubnetListerExpansion allows custom methods to be added to
 // SubnetLister.
 type SubnetListerExpansion interface{}
+
+// SubnetPoolListerExpansion allows custom methods to be added to
+// SubnetPoolLister.
+type SubnetPoolListerExpansion interface{}
@@ -33,5 +33,46 @@ const (
 
 	AnnotationHandledByWebhook = "networking.alibaba.com/handled-by-webhook"
 
+	// AnnotationForceDelete on subnet allows it to be deleted even if IPInstances still reference it
+	AnnotationForceDelete = "networking.alibaba.com/force-delete"
+
+	// AnnotationAllowImmutableUpdate on subnet allows cluster-admins to correct its immutable
+	// fields, including CIDR, parent network and gateway
+	AnnotationAllowImmutableUpdate = "networking.alibaba.com/allow-immutable-update"
+
 	AnnotationCalicoPodIPs = "cni.projectcalico.org/podIPs"
+
+	// AnnotationLLDPDiscovery on node enables the underlay network discovery through LLDP
+	AnnotationLLDPDiscovery = "networking.alibaba.com/lldp-discovery"
+
+	// AnnotationLLDPVlanID and AnnotationLLDPPortDescription on underlay network are
+	// matched against the LLDP neighbor information of nodes
+	AnnotationLLDPVlanID          = "networking.alibaba.com/lldp-vlan-id"
+	AnnotationLLDPPortDescription = "networking.alibaba.com/lldp-port-description"
+
+	// AnnotationLastNode and AnnotationLastNodeReleaseTime on IPInstance record the node which
+	// a retained IP was released from last time and when it was released
+	AnnotationLastNode            = "networking.alibaba.com/last-node"
+	AnnotationLastNodeReleaseTime = "networking.alibaba.com/last-node-release-time"
+
+	// AnnotationOAMEnabled on overlay network makes OAM frames of its pods relayed to the
+	// OAM agent of node
+	AnnotationOAMEnabled = "networking.alibaba.com/oam-enabled"
+
+	// AnnotationIPAllocationTimeline on pod records when each phase of its IP allocation
+	// is reached, in json format
+	AnnotationIPAllocationTimeline = "networking.alibaba.com/ip-allocation-timeline"
+
+	// AnnotationDNSRegistered on IPInstance means the DNS records of its hostname are registered
+	AnnotationDNSRegistered = "networking.alibaba.com/dns-registered"
+
+	// AnnotationDHCPLeaseExpireTime on snooped IPInstance is when its DHCP lease expires, in RFC3339 format
+	AnnotationDHCPLeaseExpireTime = "networking.alibaba.com/dhcp-lease-expire-time"
+
+	// AnnotationVtepMTU on RemoteVtep is the vxlan mtu of the remote cluster, routes to the overlay
+	// subnets of the cluster take the minimum of it and the local vxlan mtu
+	AnnotationVtepMTU = "networking.alibaba.com/vtep-mtu"
+
+	// AnnotationMTU on pod overrides the mtu of its nic, which is the mtu of network mode by default
+	AnnotationMTU = "networking.alibaba.com/mtu"
 )
@@ -17,4 +17,5 @@
 package constants
 
 const FinalizerIPAllocated = "networking.alibaba.com/ip-allocated"
+const FinalizerSubnetProtection = "networking.alibaba.com/subnet-protection"
 const FinalizerManagerRuntimeRegistered = "multicluster.alibaba.com/manager-runtime-registered"
@@ -17,6 +17,9 @@
 package constants
 
 const (
+	// LabelDomainPrefix is the prefix of all built-in labels
+	LabelDomainPrefix = "networking.alibaba.com/"
+
 	LabelCluster = "networking.alibaba.com/cluster"
 	LabelSubnet  = "networking.alibaba.com/subnet"
 	LabelVM      = "networking.alibaba.com/vm"
@@ -26,6 +29,9 @@ const (
 	LabelPodUID  = "networking.alibaba.com/pod-uid"
 	LabelVersion = "networking.alibaba.com/version"
 
+	// LabelIPAddress is the label-safe format of IPInstance address, dots and colons are replaced by dashes
+	LabelIPAddress = "networking.alibaba.com/ip-address"
+
 	LabelSpecifiedNetwork = "networking.alibaba.com/specified-network"
 	LabelSpecifiedSubnet  = "networking.alibaba.com/specified-subnet"
 
@@ -41,6 +47,15 @@ const (
 	LabelBGPNetworkAttachment      = "networking.alibaba.com/bgp-network-attachment"
 
 	LabelRemoteCluster = "networking.alibaba.com/remote-cluster"
+
+	// LabelSubnetPool is the name of SubnetPool which the subnet is carved from
+	LabelSubnetPool = "networking.alibaba.com/subnet-pool"
+
+	// LabelDHCPSnoopingNode is the node which snoops the DHCP lease of IPInstance
+	LabelDHCPSnoopingNode = "networking.alibaba.com/dhcp-snooping-node"
+
+	// LabelIPAMExcludeNode is the node whose exclude list file reserves the IP of IPInstance
+	LabelIPAMExcludeNode = "networking.alibaba.com/ipam-exclude-node"
 )
 
 const (
@@ -39,6 +39,9 @@ const (
 	IPv4AppSolicitSysctl = "/proc/sys/net/ipv4/neigh/%s/app_solicit"
 	IPv6AppSolicitSysctl = "/proc/sys/net/ipv6/neigh/%s/app_solicit"
 
+	IPv4McastSolicitSysctl = "/proc/sys/net/ipv4/neigh/%s/mcast_solicit"
+	IPv6McastSolicitSysctl = "/proc/sys/net/ipv6/neigh/%s/mcast_solicit"
+
 	AcceptDADSysctl = "/proc/sys/net/ipv6/conf/%s/accept_dad"
 	AcceptRASysctl  = "/proc/sys/net/ipv6/conf/%s/accept_ra"
 
@@ -17,3 +17,8 @@
 package concurrency
 
 const MaxControllerConcurrency = 100
+
+// MaxPodControllerConcurrency is the recommended upper bound of pod controller workers, because
+// all of them serialize on the lock of IPAM manager while allocating, more workers only pile up
+// waiting for the lock with their reconcile requests held, a warning is logged if exceeded
+const MaxPodControllerConcurrency = 32
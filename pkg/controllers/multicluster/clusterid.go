@@ -0,0 +1,108 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+// peerClientBuilder builds a client reading objects of the peer cluster
+type peerClientBuilder func(remoteCluster *multiclusterv1.RemoteCluster) (client.Reader, error)
+
+func newPeerClientBuilder(scheme *runtime.Scheme) peerClientBuilder {
+	return func(remoteCluster *multiclusterv1.RemoteCluster) (client.Reader, error) {
+		restConfig, err := utils.NewRestConfigFromRemoteCluster(remoteCluster)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get rest config: %v", err)
+		}
+		return client.New(restConfig, client.Options{Scheme: scheme})
+	}
+}
+
+// checkClusterIDConflict verifies the cluster ID of local cluster is not registered by any other
+// cluster, RemoteVteps and RemoteSubnets are labeled by the names of RemoteClusters, so a peer
+// cluster which has registered another cluster with the same name as local cluster ID will have
+// conflicting routing entries. Peer clusters which are not reachable are skipped with logs.
+func checkClusterIDConflict(ctx context.Context, localReader client.Reader, clusterID string,
+	newPeerClient peerClientBuilder, logger logr.Logger) error {
+	if errs := validation.IsDNS1123Label(clusterID); len(errs) > 0 {
+		return fmt.Errorf("invalid cluster ID %s: %v", clusterID, errs)
+	}
+
+	localUUID, err := utils.GetClusterUUID(ctx, localReader)
+	if err != nil {
+		return fmt.Errorf("unable to get UUID of local cluster: %v", err)
+	}
+
+	var localRemoteClusters = &multiclusterv1.RemoteClusterList{}
+	if err = localReader.List(ctx, localRemoteClusters); err != nil {
+		return fmt.Errorf("unable to list remote clusters: %v", err)
+	}
+
+	for i := range localRemoteClusters.Items {
+		remoteCluster := &localRemoteClusters.Items[i]
+		if remoteCluster.Name == clusterID {
+			return fmt.Errorf("cluster ID %s is used by remote cluster %s registered in local cluster",
+				clusterID, remoteCluster.Name)
+		}
+
+		peerClient, err := newPeerClient(remoteCluster)
+		if err != nil {
+			logger.Error(err, "unable to build client of peer cluster, skip checking it", "remoteCluster", remoteCluster.Name)
+			continue
+		}
+
+		var peerRemoteClusters = &multiclusterv1.RemoteClusterList{}
+		if err = peerClient.List(ctx, peerRemoteClusters); err != nil {
+			logger.Error(err, "unable to list remote clusters of peer cluster, skip checking it", "remoteCluster", remoteCluster.Name)
+			continue
+		}
+
+		if conflict := findClusterIDConflict(clusterID, localUUID, peerRemoteClusters.Items); conflict != nil {
+			return fmt.Errorf("cluster ID %s is registered in peer cluster %s for another cluster of UUID %s",
+				clusterID, remoteCluster.Name, conflict.Status.UUID)
+		}
+	}
+
+	logger.Info("cluster ID has no conflict", "clusterID", clusterID, "peers", len(localRemoteClusters.Items))
+	return nil
+}
+
+// findClusterIDConflict returns the remote cluster of peer cluster which has the same name as
+// cluster ID but points to a cluster other than local one, clusters without UUID are ignored
+// because they may be still being registered
+func findClusterIDConflict(clusterID string, localUUID types.UID, peerRemoteClusters []multiclusterv1.RemoteCluster) *multiclusterv1.RemoteCluster {
+	for i := range peerRemoteClusters {
+		if peerRemoteClusters[i].Name != clusterID {
+			continue
+		}
+		if len(peerRemoteClusters[i].Status.UUID) > 0 && peerRemoteClusters[i].Status.UUID != localUUID {
+			return &peerRemoteClusters[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+)
+
+func remoteClusterOfUUID(name string, uuid types.UID) *multiclusterv1.RemoteCluster {
+	return &multiclusterv1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: multiclusterv1.RemoteClusterStatus{
+			UUID: uuid,
+		},
+	}
+}
+
+func TestCheckClusterIDConflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = multiclusterv1.AddToScheme(scheme)
+
+	kubeSystem := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "kube-system",
+			UID:  "local-uuid",
+		},
+	}
+
+	tests := []struct {
+		name          string
+		localClusters []client.Object
+		peerClusters  []client.Object
+		peerErr       error
+		expectErr     bool
+	}{
+		{
+			name:          "no conflict",
+			localClusters: []client.Object{remoteClusterOfUUID("peer", "peer-uuid")},
+			peerClusters:  []client.Object{remoteClusterOfUUID("other", "other-uuid")},
+		},
+		{
+			name:          "registered by peer for local cluster",
+			localClusters: []client.Object{remoteClusterOfUUID("peer", "peer-uuid")},
+			peerClusters:  []client.Object{remoteClusterOfUUID("cluster-a", "local-uuid")},
+		},
+		{
+			name:          "registered by peer for another cluster",
+			localClusters: []client.Object{remoteClusterOfUUID("peer", "peer-uuid")},
+			peerClusters:  []client.Object{remoteClusterOfUUID("cluster-a", "other-uuid")},
+			expectErr:     true,
+		},
+		{
+			name:          "registered by peer without uuid",
+			localClusters: []client.Object{remoteClusterOfUUID("peer", "peer-uuid")},
+			peerClusters:  []client.Object{remoteClusterOfUUID("cluster-a", "")},
+		},
+		{
+			name:          "used by local remote cluster",
+			localClusters: []client.Object{remoteClusterOfUUID("cluster-a", "peer-uuid")},
+			expectErr:     true,
+		},
+		{
+			name:          "unreachable peer",
+			localClusters: []client.Object{remoteClusterOfUUID("peer", "peer-uuid")},
+			peerErr:       errors.New("connection refused"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(test.localClusters, kubeSystem.DeepCopy())...).Build()
+			peerClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.peerClusters...).Build()
+
+			err := checkClusterIDConflict(context.Background(), localClient, "cluster-a",
+				func(*multiclusterv1.RemoteCluster) (client.Reader, error) {
+					if test.peerErr != nil {
+						return nil, test.peerErr
+					}
+					return peerClient, nil
+				}, logr.Discard())
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error %v but got %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckClusterIDConflictInvalidID(t *testing.T) {
+	err := checkClusterIDConflict(context.Background(), fake.NewClientBuilder().Build(), "Invalid_ID",
+		func(*multiclusterv1.RemoteCluster) (client.Reader, error) {
+			return nil, nil
+		}, logr.Discard())
+	if err == nil {
+		t.Errorf("expected error of invalid cluster ID")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const WatcherKubeConfig = "KubeConfig"
+
+// KubeConfigWatcher watches the kubeconfig files of remote clusters, and triggers the remote
+// cluster controller to rebuild the clients of a remote cluster once its kubeconfig file
+// changes, e.g., the certificates in it are rotated. Parent directories of files are watched
+// instead of files, because files mounted from secrets are replaced by atomic renaming.
+type KubeConfigWatcher struct {
+	client.Client
+
+	Logger       logr.Logger
+	ResyncPeriod time.Duration
+	EventChan    chan<- event.GenericEvent
+
+	// files and checksums of kubeconfig files are indexed by cluster name
+	files       map[string]string
+	checksums   map[string][sha256.Size]byte
+	watchedDirs map[string]struct{}
+}
+
+func (w *KubeConfigWatcher) Start(ctx context.Context) error {
+	w.Logger.Info("kubeconfig watcher is starting")
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return wrapError("unable to create fsnotify watcher", err)
+	}
+	defer watcher.Close()
+
+	w.files = map[string]string{}
+	w.checksums = map[string][sha256.Size]byte{}
+	w.watchedDirs = map[string]struct{}{}
+
+	ticker := time.NewTicker(w.ResyncPeriod)
+	defer ticker.Stop()
+
+	w.resync(ctx, watcher)
+	for {
+		select {
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			w.checkChanges(filepath.Dir(ev.Name))
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.Logger.Error(err, "fsnotify watcher error")
+		case <-ticker.C:
+			w.resync(ctx, watcher)
+		case <-ctx.Done():
+			w.Logger.Info("kubeconfig watcher is stopping")
+			return nil
+		}
+	}
+}
+
+// resync makes watched directories consistent with remote clusters, and checks changes of
+// all files in case that any event is missed.
+func (w *KubeConfigWatcher) resync(ctx context.Context, watcher *fsnotify.Watcher) {
+	remoteClusterList, err := utils.ListRemoteClusters(ctx, w)
+	if err != nil {
+		w.Logger.Error(err, "unable to list remote clusters")
+		return
+	}
+
+	files := map[string]string{}
+	dirs := map[string]struct{}{}
+	for i := range remoteClusterList.Items {
+		remoteCluster := &remoteClusterList.Items[i]
+		if len(remoteCluster.Spec.KubeConfigFile) == 0 || !remoteCluster.DeletionTimestamp.IsZero() {
+			continue
+		}
+		files[remoteCluster.Name] = remoteCluster.Spec.KubeConfigFile
+		dirs[filepath.Dir(remoteCluster.Spec.KubeConfigFile)] = struct{}{}
+	}
+
+	for dir := range dirs {
+		if _, exist := w.watchedDirs[dir]; exist {
+			continue
+		}
+		if err = watcher.Add(dir); err != nil {
+			w.Logger.Error(err, "unable to watch kubeconfig directory", "directory", dir)
+			continue
+		}
+		w.watchedDirs[dir] = struct{}{}
+	}
+	for dir := range w.watchedDirs {
+		if _, exist := dirs[dir]; !exist {
+			_ = watcher.Remove(dir)
+			delete(w.watchedDirs, dir)
+		}
+	}
+
+	for name, file := range w.files {
+		if files[name] != file {
+			delete(w.checksums, name)
+		}
+	}
+	w.files = files
+
+	w.checkChanges("")
+}
+
+// checkChanges compares the checksums of kubeconfig files in dir, or all the files if dir is
+// empty, with the recorded ones, and triggers remote clusters whose files change. Files seen
+// for the first time are only recorded, because their clients are just built from them.
+func (w *KubeConfigWatcher) checkChanges(dir string) {
+	for name, file := range w.files {
+		if len(dir) > 0 && filepath.Dir(file) != dir {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			// file might be absent temporarily while being replaced
+			w.Logger.V(1).Info("unable to read kubeconfig file", "cluster", name, "file", file, "error", err.Error())
+			continue
+		}
+
+		checksum := sha256.Sum256(content)
+		recorded, exist := w.checksums[name]
+		w.checksums[name] = checksum
+		if !exist || recorded == checksum {
+			continue
+		}
+
+		w.Logger.Info("kubeconfig file changes, rebuilding clients", "cluster", name, "file", file)
+		metrics.MultiClusterClientRebuildsCounter.WithLabelValues(name).Inc()
+		w.EventChan <- event.GenericEvent{
+			Object: &multiclusterv1.RemoteCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			},
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+)
+
+func TestKubeConfigWatcherTriggersOnChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = multiclusterv1.AddToScheme(scheme)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cluster-a")
+	if err := os.WriteFile(file, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	eventChan := make(chan event.GenericEvent, 10)
+	w := &KubeConfigWatcher{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&multiclusterv1.RemoteCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-a"},
+				Spec:       multiclusterv1.RemoteClusterSpec{KubeConfigFile: file},
+			},
+			&multiclusterv1.RemoteCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-b"},
+			},
+		).Build(),
+		Logger:       logr.Discard(),
+		ResyncPeriod: time.Minute,
+		EventChan:    eventChan,
+		files:        map[string]string{},
+		checksums:    map[string][sha256.Size]byte{},
+		watchedDirs:  map[string]struct{}{},
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	// the first resync only records checksums
+	w.resync(context.Background(), watcher)
+	if len(eventChan) != 0 {
+		t.Fatalf("expected no event on first resync, got %d", len(eventChan))
+	}
+	if _, exist := w.watchedDirs[dir]; !exist || len(w.watchedDirs) != 1 {
+		t.Fatalf("expected only %s to be watched, got %v", dir, w.watchedDirs)
+	}
+
+	// unchanged content does not trigger
+	w.checkChanges(dir)
+	if len(eventChan) != 0 {
+		t.Fatalf("expected no event for unchanged file, got %d", len(eventChan))
+	}
+
+	if err = os.WriteFile(file, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.checkChanges(dir)
+	if len(eventChan) != 1 {
+		t.Fatalf("expected one event for changed file, got %d", len(eventChan))
+	}
+	if ev := <-eventChan; ev.Object.GetName() != "cluster-a" {
+		t.Fatalf("expected event of cluster-a, got %s", ev.Object.GetName())
+	}
+
+	// a missing file keeps the recorded checksum
+	if err = os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	w.checkChanges(dir)
+	if len(eventChan) != 0 {
+		t.Fatalf("expected no event for missing file, got %d", len(eventChan))
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
@@ -29,6 +30,9 @@ import (
 
 type RegisterOptions struct {
 	ConcurrencyMap map[string]int
+	// ClusterID is the unique ID of local cluster across clusters, which is checked not to be
+	// registered by peer clusters for other clusters, empty means skipping the check
+	ClusterID string
 }
 
 func RegisterToManager(ctx context.Context, mgr manager.Manager, options RegisterOptions) error {
@@ -36,7 +40,16 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		options.ConcurrencyMap = map[string]int{}
 	}
 
+	if len(options.ClusterID) > 0 {
+		// manager cache is not started yet, read from apiserver directly
+		if err := checkClusterIDConflict(ctx, mgr.GetAPIReader(), options.ClusterID,
+			newPeerClientBuilder(mgr.GetScheme()), mgr.GetLogger().WithName("cluster-id")); err != nil {
+			return fmt.Errorf("unable to pass cluster ID check: %v", err)
+		}
+	}
+
 	clusterStatusCheckChan := make(chan string, 10)
+	kubeConfigChangeChan := make(chan event.GenericEvent, 10)
 
 	uuidMutex, err := NewUUIDMutexFromClient(ctx, mgr.GetClient())
 	if err != nil {
@@ -67,11 +80,21 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		DaemonHub:              daemonHub,
 		LocalManager:           mgr,
 		ClusterStatusCheckChan: clusterStatusCheckChan,
+		KubeConfigChangeChan:   kubeConfigChangeChan,
 		ControllerConcurrency:  concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerRemoteCluster]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerRemoteCluster, err)
 	}
 
+	if err = mgr.Add(&KubeConfigWatcher{
+		Client:       mgr.GetClient(),
+		Logger:       mgr.GetLogger().WithName("watcher").WithName(WatcherKubeConfig),
+		ResyncPeriod: time.Minute,
+		EventChan:    kubeConfigChangeChan,
+	}); err != nil {
+		return fmt.Errorf("unable to inject watcher %s: %v", WatcherKubeConfig, err)
+	}
+
 	if err = mgr.Add(&RemoteClusterStatusChecker{
 		Client:                 mgr.GetClient(),
 		Logger:                 mgr.GetLogger().WithName("checker").WithName(CheckerRemoteClusterStatus),
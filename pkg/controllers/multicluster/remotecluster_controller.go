@@ -30,9 +30,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
 	"github.com/alibaba/hybridnet/pkg/constants"
@@ -57,6 +59,9 @@ type RemoteClusterReconciler struct {
 
 	ClusterStatusCheckChan chan<- string
 
+	// KubeConfigChangeChan receives remote clusters whose kubeconfig files change
+	KubeConfigChangeChan <-chan event.GenericEvent
+
 	LocalManager manager.Manager
 
 	concurrency.ControllerConcurrency
@@ -155,6 +160,9 @@ func (r *RemoteClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				),
 			),
 		).
+		Watches(&source.Channel{Source: r.KubeConfigChangeChan, DestBufferSize: 100},
+			&handler.EnqueueRequestForObject{},
+		).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.Max(),
 			RecoverPanic:            true,
@@ -111,7 +111,7 @@ func (r *RemoteVtepReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			Name: generateVTEPName(r.ClusterName, req.Name),
 		},
 	}
-	if operationResult, err = controllerutil.CreateOrPatch(ctx, r.ParentCluster.GetClient(), remoteVTEP, func() error {
+	if operationResult, err = createOrPatchIdempotently(ctx, r.ParentCluster.GetClient(), r.ParentCluster.GetAPIReader(), remoteVTEP, func() error {
 		if !remoteVTEP.DeletionTimestamp.IsZero() {
 			return fmt.Errorf("remote VTEP %s is terminating, can not be updated", remoteVTEP.Name)
 		}
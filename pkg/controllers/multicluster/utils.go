@@ -20,7 +20,10 @@ import (
 	"context"
 	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/alibaba/hybridnet/pkg/controllers/multicluster/clusterchecker"
 	"github.com/alibaba/hybridnet/pkg/controllers/utils"
@@ -48,3 +51,24 @@ func InitClusterStatusChecker(ctx context.Context, mgr ctrl.Manager) (clusterche
 	}
 	return checker, nil
 }
+
+// createOrPatchIdempotently creates or patches an object of deterministic name. If the creation
+// has been persisted by apiserver but its response is lost, e.g., because of network errors, the
+// cache of client may not know the object in following reconciliations and creation will fail
+// with conflict, then the object will be fetched from apiserver directly and patched instead.
+func createOrPatchIdempotently(ctx context.Context, c client.Client, apiReader client.Reader, obj client.Object,
+	f controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	operationResult, err := controllerutil.CreateOrPatch(ctx, c, obj, f)
+	if !apierrors.IsAlreadyExists(err) && !apierrors.IsConflict(err) {
+		return operationResult, err
+	}
+
+	directClient, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader: apiReader,
+		Client:      c,
+	})
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("unable to create direct client: %v", err)
+	}
+	return controllerutil.CreateOrPatch(ctx, directClient, obj, f)
+}
@@ -0,0 +1,102 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+)
+
+// flakyClient persists objects on creation but returns network error, and its reads
+// work like a stale cache which does not know the persisted objects.
+type flakyClient struct {
+	client.Client
+	staleCache bool
+}
+
+func (f *flakyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if f.staleCache {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	return f.Client.Get(ctx, key, obj, opts...)
+}
+
+func (f *flakyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := f.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	f.staleCache = true
+	return errors.New("connection reset by peer")
+}
+
+func TestCreateOrPatchIdempotently(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := multiclusterv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	apiServer := fake.NewClientBuilder().WithScheme(scheme).Build()
+	c := &flakyClient{Client: apiServer}
+
+	sync := func(vtepIP string) (controllerutil.OperationResult, error) {
+		remoteVTEP := &multiclusterv1.RemoteVtep{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: generateVTEPName("cluster1", "node1"),
+			},
+		}
+		return createOrPatchIdempotently(context.Background(), c, apiServer, remoteVTEP, func() error {
+			remoteVTEP.Spec.ClusterName = "cluster1"
+			remoteVTEP.Spec.NodeName = "node1"
+			remoteVTEP.Spec.VTEPInfo.IP = vtepIP
+			return nil
+		})
+	}
+
+	if _, err := sync("192.168.0.1"); err == nil {
+		t.Fatalf("test fails: network error of creation is not returned")
+	}
+
+	operationResult, err := sync("192.168.0.2")
+	if err != nil {
+		t.Fatalf("test fails: unexpected error %v", err)
+	}
+	if operationResult != controllerutil.OperationResultUpdated {
+		t.Errorf("test fails: expect operation result %s but got %s", controllerutil.OperationResultUpdated, operationResult)
+	}
+
+	remoteVTEPList := &multiclusterv1.RemoteVtepList{}
+	if err = apiServer.List(context.Background(), remoteVTEPList); err != nil {
+		t.Fatalf("test fails: unable to list remote vteps: %v", err)
+	}
+	if len(remoteVTEPList.Items) != 1 {
+		t.Fatalf("test fails: expect 1 remote vtep but got %d", len(remoteVTEPList.Items))
+	}
+	if vtepIP := remoteVTEPList.Items[0].Spec.VTEPInfo.IP; vtepIP != "192.168.0.2" {
+		t.Errorf("test fails: expect vtep ip 192.168.0.2 but got %s", vtepIP)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const ControllerDNSSync = "DNSSync"
+
+// dnsEndpointGVK is the kind of external-dns CRD source, the records of which will be registered
+// by external-dns into DNS servers like CoreDNS or RFC2136 ones
+var dnsEndpointGVK = schema.GroupVersionKind{
+	Group:   "externaldns.k8s.io",
+	Version: "v1alpha1",
+	Kind:    "DNSEndpoint",
+}
+
+// DNSSyncReconciler registers the forward and reverse DNS records of IPInstances with DNS hostnames
+// as DNSEndpoints of external-dns, each of which has the same name with and is owned by the IPInstance
+type DNSSyncReconciler struct {
+	client.Client
+
+	// Zone is the DNS zone which hostnames of pods are registered under
+	Zone string
+
+	concurrency.ControllerConcurrency
+}
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=ipinstances,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=externaldns.k8s.io,resources=dnsendpoints,verbs=get;list;watch;create;update;patch;delete
+
+func (r *DNSSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var err error
+	var ipInstance = &networkingv1.IPInstance{}
+	if err = r.Get(ctx, req.NamespacedName, ipInstance); err != nil {
+		// DNSEndpoint will be deleted by garbage collector
+		return ctrl.Result{}, wrapError("unable to fetch IPInstance", client.IgnoreNotFound(err))
+	}
+
+	if !ipInstance.DeletionTimestamp.IsZero() || len(ipInstance.Status.DNSHostname) == 0 {
+		return ctrl.Result{}, wrapError("unable to deregister dns records", r.deregister(ctx, ipInstance))
+	}
+
+	return ctrl.Result{}, wrapError("unable to register dns records", r.register(ctx, ipInstance))
+}
+
+func (r *DNSSyncReconciler) register(ctx context.Context, ipInstance *networkingv1.IPInstance) error {
+	ip, _, err := net.ParseCIDR(ipInstance.Spec.Address.IP)
+	if err != nil {
+		return fmt.Errorf("invalid ip %s: %v", ipInstance.Spec.Address.IP, err)
+	}
+
+	recordType := "A"
+	if ip.To4() == nil {
+		recordType = "AAAA"
+	}
+	fqdn := fmt.Sprintf("%s.%s.%s", ipInstance.Status.DNSHostname, ipInstance.Namespace, r.Zone)
+
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointGVK)
+	dnsEndpoint.SetName(ipInstance.Name)
+	dnsEndpoint.SetNamespace(ipInstance.Namespace)
+
+	if _, err = controllerutil.CreateOrUpdate(ctx, r, dnsEndpoint, func() error {
+		if err := unstructured.SetNestedSlice(dnsEndpoint.Object, []interface{}{
+			map[string]interface{}{
+				"dnsName":    fqdn,
+				"recordType": recordType,
+				"targets":    []interface{}{ip.String()},
+			},
+			map[string]interface{}{
+				"dnsName":    utils.ReverseDNSName(ip),
+				"recordType": "PTR",
+				"targets":    []interface{}{fqdn},
+			},
+		}, "spec", "endpoints"); err != nil {
+			return err
+		}
+		return controllerutil.SetOwnerReference(ipInstance, dnsEndpoint, r.Scheme())
+	}); err != nil {
+		return fmt.Errorf("unable to create or update DNSEndpoint: %v", err)
+	}
+
+	if ipInstance.Annotations[constants.AnnotationDNSRegistered] == "true" {
+		return nil
+	}
+	return r.Patch(ctx, ipInstance, client.RawPatch(apitypes.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`, constants.AnnotationDNSRegistered))))
+}
+
+func (r *DNSSyncReconciler) deregister(ctx context.Context, ipInstance *networkingv1.IPInstance) error {
+	if _, registered := ipInstance.Annotations[constants.AnnotationDNSRegistered]; !registered {
+		return nil
+	}
+
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointGVK)
+	dnsEndpoint.SetName(ipInstance.Name)
+	dnsEndpoint.SetNamespace(ipInstance.Namespace)
+	if err := r.Delete(ctx, dnsEndpoint); client.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("unable to delete DNSEndpoint: %v", err)
+	}
+
+	return client.IgnoreNotFound(r.Patch(ctx, ipInstance, client.RawPatch(apitypes.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, constants.AnnotationDNSRegistered)))))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DNSSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerDNSSync).
+		For(&networkingv1.IPInstance{}, builder.WithPredicates(
+			&predicate.ResourceVersionChangedPredicate{},
+		)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
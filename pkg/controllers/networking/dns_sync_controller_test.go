@@ -0,0 +1,114 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestDNSSyncReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	ipInstance := &networkingv1.IPInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "192-168-0-10",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IPInstanceSpec{
+			Address: networkingv1.Address{
+				Version: networkingv1.IPv4,
+				IP:      "192.168.0.10/24",
+			},
+		},
+		Status: networkingv1.IPInstanceStatus{
+			DNSHostname: "web-0.nginx",
+		},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ipInstance).Build()
+	r := &DNSSyncReconciler{Client: c, Zone: "example.com"}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ipInstance)}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("fail to register: %v", err)
+	}
+
+	dnsEndpoint := &unstructured.Unstructured{}
+	dnsEndpoint.SetGroupVersionKind(dnsEndpointGVK)
+	if err := c.Get(ctx, req.NamespacedName, dnsEndpoint); err != nil {
+		t.Fatalf("fail to get DNSEndpoint: %v", err)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(dnsEndpoint.Object, "spec", "endpoints")
+	expected := []interface{}{
+		map[string]interface{}{
+			"dnsName":    "web-0.nginx.default.example.com",
+			"recordType": "A",
+			"targets":    []interface{}{"192.168.0.10"},
+		},
+		map[string]interface{}{
+			"dnsName":    "10.0.168.192.in-addr.arpa",
+			"recordType": "PTR",
+			"targets":    []interface{}{"web-0.nginx.default.example.com"},
+		},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Fatalf("expected endpoints %v but got %v", expected, endpoints)
+	}
+
+	if err := c.Get(ctx, req.NamespacedName, ipInstance); err != nil {
+		t.Fatalf("fail to get IPInstance: %v", err)
+	}
+	if ipInstance.Annotations[constants.AnnotationDNSRegistered] != "true" {
+		t.Fatalf("expected IPInstance annotated as registered")
+	}
+
+	// reserved IPInstance has no DNS hostname
+	ipInstance.Status.DNSHostname = ""
+	if err := c.Status().Update(ctx, ipInstance); err != nil {
+		t.Fatalf("fail to update IPInstance: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("fail to deregister: %v", err)
+	}
+
+	if err := c.Get(ctx, req.NamespacedName, dnsEndpoint); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected DNSEndpoint deleted but got %v", err)
+	}
+	if err := c.Get(ctx, req.NamespacedName, ipInstance); err != nil {
+		t.Fatalf("fail to get IPInstance: %v", err)
+	}
+	if _, exist := ipInstance.Annotations[constants.AnnotationDNSRegistered]; exist {
+		t.Fatalf("expected registered annotation removed")
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+	"github.com/alibaba/hybridnet/pkg/utils/transform"
+)
+
+const ControllerEndpointSlice = "EndpointSlice"
+
+const ReasonEndpointSliceMismatch = "EndpointSliceMismatch"
+
+// EndpointSliceSyncer verifies that the endpoints of pods in EndpointSlices are consistent with
+// the IPs allocated by IPInstances, a warning event will be emitted on service if not
+type EndpointSliceSyncer struct {
+	client.Client
+
+	Recorder record.EventRecorder
+
+	concurrency.ControllerConcurrency
+}
+
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+
+func (r *EndpointSliceSyncer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var err error
+	var ip networkingv1.IPInstance
+	if err = r.Get(ctx, req.NamespacedName, &ip); err != nil {
+		return ctrl.Result{}, wrapError("unable to fetch IPInstance", client.IgnoreNotFound(err))
+	}
+
+	// only the IPInstances bound to running pods are verified
+	if !ip.DeletionTimestamp.IsZero() || networkingv1.IsReserved(&ip) || len(ip.Spec.Binding.PodUID) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	var sliceList discoveryv1.EndpointSliceList
+	if err = r.List(ctx, &sliceList, client.InNamespace(ip.Namespace)); err != nil {
+		return ctrl.Result{}, wrapError("unable to list EndpointSlices", err)
+	}
+
+	for i := range sliceList.Items {
+		slice := &sliceList.Items[i]
+		serviceName := slice.Labels[discoveryv1.LabelServiceName]
+		if len(serviceName) == 0 {
+			continue
+		}
+
+		addresses, found := endpointAddressesOfIPInstance(slice, &ip)
+		if !found || containsAddress(addresses, ip.Spec.Address.IP) {
+			continue
+		}
+
+		var service corev1.Service
+		if err = r.Get(ctx, types.NamespacedName{Namespace: ip.Namespace, Name: serviceName}, &service); err != nil {
+			if err = client.IgnoreNotFound(err); err != nil {
+				return ctrl.Result{}, wrapError("unable to fetch Service", err)
+			}
+			continue
+		}
+
+		r.Recorder.Eventf(&service, corev1.EventTypeWarning, ReasonEndpointSliceMismatch,
+			"addresses %v of pod %s in EndpointSlice %s mismatch the allocated IP %s of IPInstance %s",
+			addresses, ip.Spec.Binding.PodName, slice.Name, ip.Spec.Address.IP, ip.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// endpointAddressesOfIPInstance returns the addresses of endpoint targeting the pod which IPInstance
+// is bound to, only the EndpointSlice of the same address type as IPInstance is concerned
+func endpointAddressesOfIPInstance(slice *discoveryv1.EndpointSlice, ip *networkingv1.IPInstance) ([]string, bool) {
+	addressType := discoveryv1.AddressTypeIPv4
+	if networkingv1.IsIPv6IPInstance(ip) {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+	if slice.AddressType != addressType {
+		return nil, false
+	}
+
+	for _, endpoint := range slice.Endpoints {
+		// endpoints of a pod recreated with the same name will be updated by endpoint slice controller
+		if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" ||
+			endpoint.TargetRef.Name != ip.Spec.Binding.PodName || endpoint.TargetRef.UID != ip.Spec.Binding.PodUID {
+			continue
+		}
+		return endpoint.Addresses, true
+	}
+	return nil, false
+}
+
+func containsAddress(addresses []string, address string) bool {
+	expected := net.ParseIP(address)
+	for _, addr := range addresses {
+		if expected.Equal(net.ParseIP(addr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EndpointSliceSyncer) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerEndpointSlice).
+		For(&networkingv1.IPInstance{}, builder.WithPredicates(
+			&utils.IgnoreDeletePredicate{},
+			&predicate.ResourceVersionChangedPredicate{},
+		)).
+		Watches(&source.Kind{Type: &discoveryv1.EndpointSlice{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				slice, ok := object.(*discoveryv1.EndpointSlice)
+				if !ok {
+					return nil
+				}
+
+				var requests []reconcile.Request
+				for _, endpoint := range slice.Endpoints {
+					if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+						continue
+					}
+
+					ipList, err := utils.ListIPInstances(context.TODO(), r, client.InNamespace(slice.Namespace),
+						client.MatchingLabels{
+							constants.LabelPod: transform.TransferPodNameForLabelValue(endpoint.TargetRef.Name),
+						})
+					if err != nil {
+						continue
+					}
+
+					for i := range ipList.Items {
+						requests = append(requests, reconcile.Request{
+							NamespacedName: types.NamespacedName{
+								Namespace: ipList.Items[i].Namespace,
+								Name:      ipList.Items[i].Name,
+							},
+						})
+					}
+				}
+				return requests
+			}),
+			builder.WithPredicates(
+				&utils.IgnoreDeletePredicate{},
+				&predicate.ResourceVersionChangedPredicate{},
+			)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
@@ -22,5 +22,5 @@ func wrapError(wrapMessage string, err error) error {
 	if err == nil {
 		return nil
 	}
-	return fmt.Errorf("%s: %v", wrapMessage, err)
+	return fmt.Errorf("%s: %w", wrapMessage, err)
 }
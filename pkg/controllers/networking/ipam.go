@@ -18,6 +18,7 @@ package networking
 
 import (
 	"context"
+	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,18 +38,25 @@ type IPAMManager interface {
 	ipam.Manager
 }
 
-func NewIPAMManager(ctx context.Context, c client.Client) (IPAMManager, error) {
+func NewIPAMManager(ctx context.Context, c client.Client, opts ...types.ManagerOption) (IPAMManager, error) {
+	return NewIPAMManagerOfShard(ctx, c, NetworkShard{}, opts...)
+}
+
+// NewIPAMManagerOfShard creates an IPAM manager only with the networks of shard
+func NewIPAMManagerOfShard(ctx context.Context, c client.Client, shard NetworkShard, opts ...types.ManagerOption) (IPAMManager, error) {
 	networkList, err := utils.ListNetworks(ctx, c)
 	if err != nil {
 		return nil, err
 	}
 
-	var networkNames = make([]string, len(networkList.Items))
+	var networkNames = make([]string, 0, len(networkList.Items))
 	for i := range networkList.Items {
-		networkNames[i] = networkList.Items[i].Name
+		if shard.Owns(networkList.Items[i].Name) {
+			networkNames = append(networkNames, networkList.Items[i].Name)
+		}
 	}
 
-	return manager.NewManager(networkNames, NetworkGetter(ctx, c), SubnetGetter(ctx, c), IPSetGetter(ctx, c))
+	return manager.NewManager(networkNames, NetworkGetter(ctx, c), SubnetGetter(ctx, c), IPSetGetter(ctx, c), opts...)
 }
 
 func NetworkGetter(ctx context.Context, c client.Reader) manager.NetworkGetter {
@@ -69,12 +77,34 @@ func SubnetGetter(ctx context.Context, c client.Reader) manager.SubnetGetter {
 			return nil, err
 		}
 
+		reservationList, err := utils.ListIPBlockReservations(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
 		var subnets []*ipamtypes.Subnet
 		for i := range subnetList.Items {
 			subnet := &subnetList.Items[i]
-			if subnet.Spec.Network == networkName {
-				subnets = append(subnets, transform.TransferSubnetForIPAM(subnet))
+			if subnet.Spec.Network != networkName {
+				continue
 			}
+
+			ipamSubnet := transform.TransferSubnetForIPAM(subnet)
+
+			// IPs in reserved blocks are excluded like black list for external services
+			for j := range reservationList.Items {
+				reservation := &reservationList.Items[j]
+				if reservation.Spec.Subnet != subnet.Name || !reservation.DeletionTimestamp.IsZero() {
+					continue
+				}
+				ipRange, err := transform.TransferIPBlockReservationForIPAM(reservation)
+				if err != nil {
+					return nil, fmt.Errorf("failed to transfer ip block reservation %s: %v", reservation.Name, err)
+				}
+				ipamSubnet.BlackRanges = append(ipamSubnet.BlackRanges, ipRange)
+			}
+
+			subnets = append(subnets, ipamSubnet)
 		}
 		return subnets, nil
 	}
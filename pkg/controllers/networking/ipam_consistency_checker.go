@@ -0,0 +1,107 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const CheckerIPAMConsistency = "IPAMConsistency"
+
+// IPAMConsistencyChecker checks periodically whether the in-memory state of IPAM manager drifts
+// from the IPInstances in apiserver, which may happen if some events are dropped
+type IPAMConsistencyChecker struct {
+	Logger      logr.Logger
+	IPAMManager IPAMManager
+
+	CheckPeriod time.Duration
+	// AutoHeal refreshes the drifted networks of IPAM manager from apiserver
+	AutoHeal bool
+
+	// suspected records the subnets drifted in the last check, because IPAM manager allocates
+	// IPs before IPInstances are created, only the drift lasting for two checks is reported
+	suspected map[string]struct{}
+}
+
+func (c *IPAMConsistencyChecker) Start(ctx context.Context) error {
+	c.Logger.Info("ipam consistency checker is starting", "period", c.CheckPeriod, "autoHeal", c.AutoHeal)
+
+	ticker := time.NewTicker(c.CheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.check()
+		case <-ctx.Done():
+			c.Logger.Info("ipam consistency checker is stopping")
+			return nil
+		}
+	}
+}
+
+func (c *IPAMConsistencyChecker) check() {
+	driftedSubnets, err := c.IPAMManager.CheckConsistency()
+	if err != nil {
+		c.Logger.Error(err, "unable to check ipam consistency")
+		return
+	}
+
+	var suspected = make(map[string]struct{})
+	var toHealNetworks []string
+	for networkName, subnetNames := range driftedSubnets {
+		var confirmed bool
+		for _, subnetName := range subnetNames {
+			suspected[subnetName] = struct{}{}
+			if _, exist := c.suspected[subnetName]; !exist {
+				continue
+			}
+
+			confirmed = true
+			c.Logger.Error(nil, "ipam state drift detected", "network", networkName, "subnet", subnetName)
+			metrics.IPAMDriftDetectedCounter.WithLabelValues(subnetName).Inc()
+		}
+
+		if confirmed && c.AutoHeal {
+			toHealNetworks = append(toHealNetworks, networkName)
+		}
+	}
+	c.suspected = suspected
+
+	if len(toHealNetworks) == 0 {
+		return
+	}
+
+	if err = c.IPAMManager.Refresh(ipamtypes.RefreshNetworks(toHealNetworks)); err != nil {
+		c.Logger.Error(err, "unable to rebuild drifted networks of ipam", "networks", toHealNetworks)
+		return
+	}
+	c.Logger.Info("drifted networks of ipam rebuilt", "networks", toHealNetworks)
+
+	// rebuilt subnets are consistent now
+	for _, networkName := range toHealNetworks {
+		for _, subnetName := range driftedSubnets[networkName] {
+			delete(c.suspected, subnetName)
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
 	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
 	"github.com/alibaba/hybridnet/pkg/controllers/utils"
 	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
@@ -49,6 +50,10 @@ type IPAMReconciler struct {
 	NetworkStatusUpdateChan chan<- event.GenericEvent
 	SubnetStatusUpdateChan  chan<- event.GenericEvent
 
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
 	concurrency.ControllerConcurrency
 }
 
@@ -59,6 +64,10 @@ type IPAMReconciler struct {
 func (r *IPAMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrllog.FromContext(ctx)
 
+	if !r.Shard.Owns(req.Name) {
+		return ctrl.Result{}, nil
+	}
+
 	if err := r.IPAMManager.Refresh(ipamtypes.RefreshNetworks{req.Name}); err != nil {
 		log.Error(err, "unable to refresh IPAM Manager", "network", req.Name)
 		return ctrl.Result{}, err
@@ -136,6 +145,49 @@ func (r *IPAMReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				&predicate.GenerationChangedPredicate{},
 				&utils.SubnetSpecChangePredicate{},
 			)).
+		Watches(&source.Kind{Type: &networkingv1.IPBlockReservation{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				reservation, ok := object.(*networkingv1.IPBlockReservation)
+				if !ok {
+					return nil
+				}
+
+				subnet, err := utils.GetSubnet(context.TODO(), r, reservation.Spec.Subnet)
+				if err != nil {
+					return nil
+				}
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name: subnet.Spec.Network,
+						},
+					},
+				}
+			}),
+			builder.WithPredicates(
+				&predicate.GenerationChangedPredicate{},
+			)).
+		// IPInstances reserved by daemons are created and deleted without IPAM manager,
+		// refresh networks to keep their IPs out of allocation
+		Watches(&source.Kind{Type: &networkingv1.IPInstance{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				ipInstance, ok := object.(*networkingv1.IPInstance)
+				if !ok {
+					return nil
+				}
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name: ipInstance.Spec.Network,
+						},
+					},
+				}
+			}),
+			builder.WithPredicates(
+				&utils.SpecifiedLabelExistPredicate{
+					LabelKeys: []string{constants.LabelIPAMExcludeNode, constants.LabelDHCPSnoopingNode},
+				},
+			)).
 		WithOptions(
 			controller.Options{
 				MaxConcurrentReconciles: r.Max(),
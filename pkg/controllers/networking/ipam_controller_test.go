@@ -166,7 +166,7 @@ var _ = Describe("IPAM controller integration test suite", func() {
 					g.Expect(createdSubnet).NotTo(BeNil())
 					g.Expect(createdSubnet.IsIPv4()).To(BeTrue())
 					g.Expect(createdSubnet.IsAvailable()).To(BeTrue())
-					g.Expect(createdSubnet.AvailableIPs.Count()).To(Equal(int(basicIPQuantity - networkAddress - broadcastAddress - gatewayAddress)))
+					g.Expect(createdSubnet.AvailableIPCount).To(Equal(int(basicIPQuantity - networkAddress - broadcastAddress - gatewayAddress)))
 					g.Expect(createdSubnet.UsingIPs.Count()).To(Equal(0))
 				}).
 				WithTimeout(30 * time.Second).
@@ -202,7 +202,7 @@ var _ = Describe("IPAM controller integration test suite", func() {
 					g.Expect(createdSubnet).NotTo(BeNil())
 					g.Expect(createdSubnet.IsIPv6()).To(BeTrue())
 					g.Expect(createdSubnet.IsAvailable()).To(BeTrue())
-					g.Expect(createdSubnet.AvailableIPs.Count()).To(Equal(int(basicIPQuantity - networkAddress)))
+					g.Expect(createdSubnet.AvailableIPCount).To(Equal(int(basicIPQuantity - networkAddress)))
 					g.Expect(createdSubnet.UsingIPs.Count()).To(Equal(0))
 				}).
 				WithTimeout(30 * time.Second).
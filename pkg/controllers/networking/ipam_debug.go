@@ -0,0 +1,87 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+const IPAMDebugPath = "/debug/ipam"
+
+// SubnetIPAMState is the in-memory ipam state of a subnet served by IPAMDebugHandler
+type SubnetIPAMState struct {
+	Network      string   `json:"network"`
+	Subnet       string   `json:"subnet"`
+	AllocatedIPs []string `json:"allocatedIPs"`
+}
+
+// IPAMDebugHandler serves the in-memory ipam state of subnets, it is registered on the metrics
+// server before manager starts, but IPAM manager is only initialized after leader election
+type IPAMDebugHandler struct {
+	sync.RWMutex
+
+	ipamManager IPAMManager
+}
+
+// SetIPAMManager sets the IPAM manager whose state will be served
+func (h *IPAMDebugHandler) SetIPAMManager(ipamManager IPAMManager) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.ipamManager = ipamManager
+}
+
+func (h *IPAMDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	networkName, subnetName := r.URL.Query().Get("network"), r.URL.Query().Get("subnet")
+	if len(networkName) == 0 || len(subnetName) == 0 {
+		http.Error(w, "network and subnet must be specified", http.StatusBadRequest)
+		return
+	}
+
+	h.RLock()
+	ipamManager := h.ipamManager
+	h.RUnlock()
+
+	if ipamManager == nil {
+		http.Error(w, "ipam is not initialized, this manager may not be the leader", http.StatusServiceUnavailable)
+		return
+	}
+
+	allocatedIPs, err := ipamManager.GetSubnetAllocatedIPs(networkName, subnetName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if allocatedIPs == nil {
+		allocatedIPs = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&SubnetIPAMState{
+		Network:      networkName,
+		Subnet:       subnetName,
+		AllocatedIPs: allocatedIPs,
+	})
+}
@@ -0,0 +1,157 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const CheckerIPInstanceAge = "IPInstanceAge"
+
+const ReasonCompliancePurge = "CompliancePurge"
+
+// IPInstanceAgeChecker checks periodically whether IPInstances are older than the max age of
+// NetworkingPolicies, and purges them for compliance. IPInstances used by running pods are never
+// purged, only those whose pods no longer exist or have been recreated with another uid are, even if
+// they are reserved for stateful pods. IPs of purged IPInstances are released from IPAM by IPInstance
+// controller on deletion, which is the same as decoupled IPInstances of pods.
+type IPInstanceAgeChecker struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+	Logger   logr.Logger
+
+	CheckPeriod time.Duration
+}
+
+func (c *IPInstanceAgeChecker) Start(ctx context.Context) error {
+	c.Logger.Info("ip instance age checker is starting", "period", c.CheckPeriod)
+
+	ticker := time.NewTicker(c.CheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.check(ctx, time.Now()); err != nil {
+				c.Logger.Error(err, "unable to check ip instance age")
+			}
+		case <-ctx.Done():
+			c.Logger.Info("ip instance age checker is stopping")
+			return nil
+		}
+	}
+}
+
+func (c *IPInstanceAgeChecker) check(ctx context.Context, now time.Time) error {
+	maxAge, err := c.maxIPInstanceAge(ctx)
+	if err != nil {
+		return err
+	}
+	if maxAge <= 0 {
+		return nil
+	}
+
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err = c.Client.List(ctx, ipInstanceList); err != nil {
+		return fmt.Errorf("unable to list ip instances: %v", err)
+	}
+
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if !ipInstance.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		age := now.Sub(ipInstance.CreationTimestamp.Time)
+		if age < maxAge {
+			continue
+		}
+
+		purgeable, err := c.isPurgeable(ctx, ipInstance)
+		if err != nil {
+			c.Logger.Error(err, "unable to check pod of ip instance", "ipInstance", client.ObjectKeyFromObject(ipInstance))
+			continue
+		}
+		if !purgeable {
+			continue
+		}
+
+		if err = client.IgnoreNotFound(c.Client.Delete(ctx, ipInstance)); err != nil {
+			c.Logger.Error(err, "unable to purge ip instance", "ipInstance", client.ObjectKeyFromObject(ipInstance))
+			continue
+		}
+
+		c.Recorder.Eventf(ipInstance, corev1.EventTypeWarning, ReasonCompliancePurge,
+			"ip instance is purged because its age %s exceeds the max age %s", age.Truncate(time.Second), maxAge)
+		metrics.IPInstanceCompliancePurgeCounter.WithLabelValues(ipInstance.Spec.Network).Inc()
+		c.Logger.Info("ip instance purged for compliance", "ipInstance", client.ObjectKeyFromObject(ipInstance),
+			"age", age, "maxAge", maxAge)
+	}
+
+	return nil
+}
+
+// isPurgeable checks if the pod bound by IPInstance no longer exists or the binding points to another
+// pod uid, IPInstances which are not bound by any pod, e.g., reserved by daemons, are never purged
+func (c *IPInstanceAgeChecker) isPurgeable(ctx context.Context, ipInstance *networkingv1.IPInstance) (bool, error) {
+	podName := ipInstance.Spec.Binding.PodName
+	if len(podName) == 0 {
+		return false, nil
+	}
+
+	pod := &corev1.Pod{}
+	if err := c.Client.Get(ctx, types.NamespacedName{Namespace: ipInstance.Namespace, Name: podName}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return pod.UID != ipInstance.Spec.Binding.PodUID, nil
+}
+
+// maxIPInstanceAge returns the strictest max age of all NetworkingPolicies, zero means no
+// NetworkingPolicy requires purging
+func (c *IPInstanceAgeChecker) maxIPInstanceAge(ctx context.Context) (time.Duration, error) {
+	policyList := &networkingv1.NetworkingPolicyList{}
+	if err := c.Client.List(ctx, policyList); err != nil {
+		return 0, fmt.Errorf("unable to list networking policies: %v", err)
+	}
+
+	var maxAge time.Duration
+	for i := range policyList.Items {
+		policyMaxAge := policyList.Items[i].Spec.MaxIPInstanceAge
+		if policyMaxAge == nil || policyMaxAge.Duration <= 0 {
+			continue
+		}
+		if maxAge == 0 || policyMaxAge.Duration < maxAge {
+			maxAge = policyMaxAge.Duration
+		}
+	}
+	return maxAge, nil
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestIPInstanceAgeChecker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	created := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	ipInstance := func(name string, binding networkingv1.Binding) *networkingv1.IPInstance {
+		return &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: networkingv1.IPInstanceSpec{
+				Network: "network1",
+				Subnet:  "subnet1",
+				Binding: binding,
+			},
+		}
+	}
+
+	livePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default", UID: "uid-live"}}
+	recreatedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "sts-1", Namespace: "default", UID: "uid-new"}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&networkingv1.NetworkingPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "compliance"},
+			Spec:       networkingv1.NetworkingPolicySpec{MaxIPInstanceAge: &metav1.Duration{Duration: 24 * time.Hour}},
+		},
+		livePod,
+		recreatedPod,
+		ipInstance("10-0-0-1", networkingv1.Binding{NodeName: "node1", PodName: "live", PodUID: "uid-live"}),
+		ipInstance("10-0-0-2", networkingv1.Binding{NodeName: "node1", PodName: "missing", PodUID: "uid-missing"}),
+		// reserved for a stateful pod which is not recreated yet
+		ipInstance("10-0-0-3", networkingv1.Binding{PodName: "sts-0", PodUID: "uid-old",
+			Stateful: &networkingv1.StatefulInfo{}}),
+		// retained for a stateful pod which has been recreated with another uid
+		ipInstance("10-0-0-4", networkingv1.Binding{PodName: "sts-1", PodUID: "uid-old",
+			Stateful: &networkingv1.StatefulInfo{}}),
+		// reserved by daemon without any pod
+		ipInstance("10-0-0-5", networkingv1.Binding{}),
+	).Build()
+
+	recorder := record.NewFakeRecorder(10)
+	checker := &IPInstanceAgeChecker{
+		Client:   c,
+		Recorder: recorder,
+		Logger:   ctrl.Log,
+	}
+
+	// nothing is old enough to be purged
+	if err := checker.check(context.Background(), created.Add(time.Hour)); err != nil {
+		t.Fatalf("fail to check: %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected nothing purged but got %d events", len(recorder.Events))
+	}
+
+	if err := checker.check(context.Background(), created.Add(48*time.Hour)); err != nil {
+		t.Fatalf("fail to check: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		purged bool
+	}{
+		{"10-0-0-1", false},
+		{"10-0-0-2", true},
+		{"10-0-0-3", true},
+		{"10-0-0-4", true},
+		{"10-0-0-5", false},
+	}
+	for _, test := range tests {
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: test.name},
+			&networkingv1.IPInstance{})
+		if purged := apierrors.IsNotFound(err); purged != test.purged {
+			t.Errorf("expected ip instance %s purged %v but got error %v", test.name, test.purged, err)
+		}
+	}
+	if len(recorder.Events) != 3 {
+		t.Fatalf("expected 3 purge events but got %d", len(recorder.Events))
+	}
+}
@@ -19,6 +19,8 @@ package networking
 import (
 	"context"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,6 +44,10 @@ type IPInstanceReconciler struct {
 	IPAMManager IPAMManager
 	IPAMStore   IPAMStore
 
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
 	concurrency.ControllerConcurrency
 }
 
@@ -56,19 +62,39 @@ func (r *IPInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, wrapError("unable to fetch IPInstance", client.IgnoreNotFound(err))
 	}
 
+	if !r.Shard.Owns(ip.Spec.Network) {
+		return ctrl.Result{}, nil
+	}
+
 	if !ip.DeletionTimestamp.IsZero() {
 		r.PodIPCache.ReleaseIP(ip.Name, ip.Namespace)
 
 		if err = r.releaseIP(ctx, &ip); err != nil {
 			return ctrl.Result{}, wrapError("unable to release IPInstance", err)
 		}
+		return ctrl.Result{}, nil
+	}
+
+	// garbage collector only removes the dangling owner reference of a deleted workload
+	// because parent subnet is still an owner, reserved IPInstances left behind should be
+	// deleted here like the way garbage collector does
+	if networkingv1.IsReserved(&ip) && ownedBySubnetOnly(&ip) {
+		return ctrl.Result{}, wrapError("unable to delete orphaned IPInstance", client.IgnoreNotFound(r.Delete(ctx, &ip)))
 	}
 
 	return ctrl.Result{}, nil
 }
 
 func (r *IPInstanceReconciler) releaseIP(ctx context.Context, ipInstance *networkingv1.IPInstance) (err error) {
-	if err = r.IPAMManager.Release(ipInstance.Spec.Network,
+	// IPs of a deleted subnet can not be released from IPAM, just unbind them
+	if err = r.Get(ctx, apitypes.NamespacedName{Name: ipInstance.Spec.Subnet}, &networkingv1.Subnet{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return
+		}
+		return r.IPAMStore.IPUnBind(ctx, ipInstance.Namespace, ipInstance.Name)
+	}
+
+	if err = r.IPAMManager.Release(ctx, ipInstance.Spec.Network,
 		[]types.SubnetIPSuite{
 			types.ReleaseIPOfSubnet(ipInstance.Spec.Subnet, utils.ToIPFormat(ipInstance.Name)),
 		},
@@ -80,6 +106,13 @@ func (r *IPInstanceReconciler) releaseIP(ctx context.Context, ipInstance *networ
 	return
 }
 
+// ownedBySubnetOnly checks if the parent subnet is the only owner of IPInstance
+func ownedBySubnetOnly(ipInstance *networkingv1.IPInstance) bool {
+	owners := ipInstance.GetOwnerReferences()
+	return len(owners) == 1 && owners[0].Kind == "Subnet" && owners[0].Name == ipInstance.Spec.Subnet &&
+		owners[0].APIVersion == networkingv1.GroupVersion.String()
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *IPInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -75,6 +75,29 @@ var _ = Describe("IPInstance controller integration test suite", func() {
 			availableAfterAllocation := networkUsage.GetByType(ipamtypes.IPv4).Available
 			Expect(availableAfterAllocation).To(Equal(availableOld - 1))
 
+			By("check parent subnet is a non-controller owner of IPInstance")
+			ipInstance := &networkingv1.IPInstance{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{
+				Name:      ipInstanceName,
+				Namespace: pod.Namespace,
+			}, ipInstance)).NotTo(HaveOccurred())
+
+			subnet := &networkingv1.Subnet{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: ipInstance.Spec.Subnet}, subnet)).NotTo(HaveOccurred())
+
+			var subnetOwner *metav1.OwnerReference
+			for i := range ipInstance.OwnerReferences {
+				if ipInstance.OwnerReferences[i].UID == subnet.UID {
+					subnetOwner = &ipInstance.OwnerReferences[i]
+				}
+			}
+			Expect(subnetOwner).NotTo(BeNil())
+			Expect(subnetOwner.Kind).To(Equal("Subnet"))
+			Expect(subnetOwner.Controller).NotTo(BeNil())
+			Expect(*subnetOwner.Controller).To(BeFalse())
+			Expect(metav1.GetControllerOf(ipInstance)).NotTo(BeNil())
+			Expect(metav1.GetControllerOf(ipInstance).Kind).To(Equal("Pod"))
+
 			By("deleting pod and IPInstance")
 			Expect(k8sClient.Delete(context.Background(), pod, client.GracePeriodSeconds(0))).NotTo(HaveOccurred())
 			Expect(k8sClient.Delete(context.Background(),
@@ -0,0 +1,134 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"net"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+const (
+	ControllerIPInstanceMigration = "IPInstanceMigration"
+
+	// schemaMigrationFieldOwner is the field manager of schema migration patches
+	schemaMigrationFieldOwner = "hybridnet-schema-migration"
+)
+
+// IPInstanceMigrationReconciler reconciles IPInstances without the latest schema version,
+// it applies defaults of new fields and marks them with the latest schema version
+type IPInstanceMigrationReconciler struct {
+	client.Client
+
+	concurrency.ControllerConcurrency
+}
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=ipinstances,verbs=get;list;watch;patch
+
+func (r *IPInstanceMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrllog.FromContext(ctx)
+
+	var ipInstance = &networkingv1.IPInstance{}
+
+	defer func() {
+		if err != nil {
+			log.Error(err, "reconciliation fails")
+		}
+	}()
+
+	if err = r.Get(ctx, req.NamespacedName, ipInstance); err != nil {
+		return ctrl.Result{}, wrapError("unable to fetch IPInstance", client.IgnoreNotFound(err))
+	}
+
+	// terminating IPInstances will never be used again, no need to migrate
+	if !ipInstance.DeletionTimestamp.IsZero() || ipInstance.Spec.SchemaVersion == networkingv1.IPInstanceLatestSchemaVersion {
+		return ctrl.Result{}, nil
+	}
+
+	from := ipInstance.Spec.SchemaVersion
+	patch := client.MergeFrom(ipInstance.DeepCopy())
+	migrateIPInstanceSchema(ipInstance)
+	if err = r.Patch(ctx, ipInstance, patch, client.FieldOwner(schemaMigrationFieldOwner)); err != nil {
+		return ctrl.Result{}, wrapError("unable to patch schema migration to IPInstance", err)
+	}
+
+	log.V(1).Info("migrate IPInstance schema", "from", from,
+		"to", networkingv1.IPInstanceLatestSchemaVersion)
+	return ctrl.Result{}, nil
+}
+
+// migrateIPInstanceSchema marks IPInstance with the latest schema version and fills the defaults of
+// fields missing in IPInstance. Existing fields are never touched, so a merge patch of the result
+// keeps all the other fields of binding and labels, and migrating repeatedly changes nothing.
+func migrateIPInstanceSchema(ipInstance *networkingv1.IPInstance) {
+	ipInstance.Spec.SchemaVersion = networkingv1.IPInstanceLatestSchemaVersion
+
+	// referred object of binding is introduced by schema v1, it is always the controller
+	// owner of IPInstance
+	if len(ipInstance.Spec.Binding.ReferredObject.Kind) == 0 {
+		if owner := metav1.GetControllerOf(ipInstance); owner != nil {
+			ipInstance.Spec.Binding.ReferredObject = networkingv1.ObjectMeta{
+				Kind: owner.Kind,
+				Name: owner.Name,
+				UID:  owner.UID,
+			}
+		}
+	}
+
+	// ip address label is introduced by schema v2 for label-selecting IPInstances by address
+	if _, exist := ipInstance.Labels[constants.LabelIPAddress]; !exist {
+		if ip, _, err := net.ParseCIDR(ipInstance.Spec.Address.IP); err == nil {
+			if ipInstance.Labels == nil {
+				ipInstance.Labels = map[string]string{}
+			}
+			ipInstance.Labels[constants.LabelIPAddress] = utils.ToDNSFormat(ip)
+		}
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IPInstanceMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerIPInstanceMigration).
+		For(&networkingv1.IPInstance{},
+			builder.WithPredicates(
+				predicate.NewPredicateFuncs(func(object client.Object) bool {
+					ipInstance, ok := object.(*networkingv1.IPInstance)
+					if !ok {
+						return false
+					}
+					return ipInstance.Spec.SchemaVersion != networkingv1.IPInstanceLatestSchemaVersion
+				}),
+			)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
@@ -0,0 +1,130 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/pointer"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+var _ = Describe("IPInstance migration controller integration test suite", func() {
+	Context("Lock", func() {
+		testLock.Lock()
+	})
+
+	Context("IPInstance schema migration", func() {
+		var (
+			ipInstanceName = "192-168-56-250"
+			ownerName      = "migration-test-pod"
+			ownerUID       = uuid.NewUUID()
+		)
+
+		It("IPInstance without schema version should be migrated to the latest schema", func() {
+			By("create IPInstance of an old schema")
+			Expect(k8sClient.Create(context.Background(), &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ipInstanceName,
+					Namespace: corev1.NamespaceDefault,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "v1",
+							Kind:       "Pod",
+							Name:       ownerName,
+							UID:        ownerUID,
+							Controller: pointer.Bool(true),
+						},
+					},
+				},
+				Spec: networkingv1.IPInstanceSpec{
+					Network: underlayNetworkName,
+					Subnet:  underlaySubnetName,
+					Address: networkingv1.Address{
+						IP:      "192.168.56.250/24",
+						Gateway: "192.168.56.1",
+						MAC:     "00:00:00:00:00:01",
+						Version: networkingv1.IPv4,
+					},
+				},
+			})).NotTo(HaveOccurred())
+
+			By("check schema version and defaults of IPInstance")
+			Eventually(
+				func(g Gomega) {
+					ipInstance := &networkingv1.IPInstance{}
+					g.Expect(k8sClient.Get(context.Background(),
+						types.NamespacedName{
+							Namespace: corev1.NamespaceDefault,
+							Name:      ipInstanceName,
+						},
+						ipInstance,
+					)).NotTo(HaveOccurred())
+					g.Expect(ipInstance.Spec.SchemaVersion).To(Equal(networkingv1.IPInstanceLatestSchemaVersion))
+					g.Expect(ipInstance.Spec.Binding.ReferredObject).To(Equal(networkingv1.ObjectMeta{
+						Kind: "Pod",
+						Name: ownerName,
+						UID:  ownerUID,
+					}))
+					g.Expect(ipInstance.Spec.Address.IP).To(Equal("192.168.56.250/24"))
+					g.Expect(ipInstance.Labels).To(HaveKeyWithValue(constants.LabelIPAddress, "192-168-56-250"))
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+		})
+
+		AfterEach(func() {
+			By("remove test IPInstance")
+			Expect(k8sClient.Delete(context.Background(), &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ipInstanceName,
+					Namespace: corev1.NamespaceDefault,
+				},
+			})).NotTo(HaveOccurred())
+
+			Eventually(
+				func(g Gomega) {
+					err := k8sClient.Get(context.Background(),
+						types.NamespacedName{
+							Namespace: corev1.NamespaceDefault,
+							Name:      ipInstanceName,
+						},
+						&networkingv1.IPInstance{},
+					)
+					g.Expect(errors.IsNotFound(err)).To(BeTrue())
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+		})
+	})
+
+	Context("Unlock", func() {
+		testLock.Unlock()
+	})
+})
@@ -0,0 +1,130 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// TestIPInstanceSchemaMigrationUpgrade migrates the unlabeled IPInstances which are created before
+// upgrading, all the existing fields of binding should be kept.
+func TestIPInstanceSchemaMigrationUpgrade(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	owner := metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Name:       "pod1",
+		UID:        "uid1",
+		Controller: pointer.Bool(true),
+	}
+	stateful := &networkingv1.StatefulInfo{Index: pointer.Int32(0)}
+
+	tests := []struct {
+		name    string
+		version string
+		binding networkingv1.Binding
+	}{
+		{
+			name:    "schema v1",
+			version: networkingv1.IPInstanceSchemaV1,
+			binding: networkingv1.Binding{
+				ReferredObject: networkingv1.ObjectMeta{Kind: "StatefulSet", Name: "sts1", UID: "uid0"},
+				NodeName:       "node1",
+				PodUID:         "uid1",
+				PodName:        "pod1",
+				Stateful:       stateful,
+			},
+		},
+		{
+			name: "without schema version",
+			binding: networkingv1.Binding{
+				NodeName: "node1",
+				PodUID:   "uid1",
+				PodName:  "pod1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ipInstance := &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "192-168-56-250",
+					Namespace:       corev1.NamespaceDefault,
+					Labels:          map[string]string{constants.LabelNode: "node1"},
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Spec: networkingv1.IPInstanceSpec{
+					Network:       "network1",
+					Subnet:        "subnet1",
+					Address:       networkingv1.Address{IP: "192.168.56.250/24", Version: networkingv1.IPv4},
+					Binding:       test.binding,
+					SchemaVersion: test.version,
+				},
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ipInstance).Build()
+			r := &IPInstanceMigrationReconciler{Client: c}
+			key := types.NamespacedName{Namespace: ipInstance.Namespace, Name: ipInstance.Name}
+			for i := 0; i < 2; i++ {
+				if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+					t.Fatalf("fail to reconcile: %v", err)
+				}
+			}
+
+			migrated := &networkingv1.IPInstance{}
+			if err := c.Get(context.Background(), key, migrated); err != nil {
+				t.Fatalf("fail to get ip instance: %v", err)
+			}
+
+			expectedBinding := test.binding
+			if len(expectedBinding.ReferredObject.Kind) == 0 {
+				expectedBinding.ReferredObject = networkingv1.ObjectMeta{Kind: "Pod", Name: "pod1", UID: "uid1"}
+			}
+			if !reflect.DeepEqual(migrated.Spec.Binding, expectedBinding) {
+				t.Fatalf("expected binding %+v but got %+v", expectedBinding, migrated.Spec.Binding)
+			}
+			if migrated.Spec.SchemaVersion != networkingv1.IPInstanceLatestSchemaVersion {
+				t.Fatalf("expected latest schema version but got %q", migrated.Spec.SchemaVersion)
+			}
+			expectedLabels := map[string]string{
+				constants.LabelNode:      "node1",
+				constants.LabelIPAddress: "192-168-56-250",
+			}
+			if !reflect.DeepEqual(migrated.Labels, expectedLabels) {
+				t.Fatalf("expected labels %v but got %v", expectedLabels, migrated.Labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const CheckerIPInstanceStatusConsistency = "IPInstanceStatusConsistency"
+
+// IPInstanceStatusConsistencyChecker checks periodically whether the status of IPInstances skews
+// from their bindings, which happens if the status written by daemon for a sandbox interleaves with
+// the reservation or rebinding of IPInstance by manager, and patches the status to match the binding
+type IPInstanceStatusConsistencyChecker struct {
+	Client client.Client
+	Logger logr.Logger
+
+	CheckPeriod time.Duration
+}
+
+func (c *IPInstanceStatusConsistencyChecker) Start(ctx context.Context) error {
+	c.Logger.Info("ip instance status consistency checker is starting", "period", c.CheckPeriod)
+
+	ticker := time.NewTicker(c.CheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.check(ctx, time.Now()); err != nil {
+				c.Logger.Error(err, "unable to check ip instance status consistency")
+			}
+		case <-ctx.Done():
+			c.Logger.Info("ip instance status consistency checker is stopping")
+			return nil
+		}
+	}
+}
+
+func (c *IPInstanceStatusConsistencyChecker) check(ctx context.Context, now time.Time) error {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.Client.List(ctx, ipInstanceList); err != nil {
+		return fmt.Errorf("unable to list ip instances: %v", err)
+	}
+
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if !ipInstance.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		expectedStatus, skewed := expectedIPInstanceStatus(ipInstance)
+		if !skewed {
+			continue
+		}
+
+		// the status may be refreshed by daemon after listing, which must not be overwritten
+		patch := client.MergeFromWithOptions(ipInstance.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		expectedStatus.UpdateTimestamp = metav1.NewTime(now)
+		ipInstance.Status = expectedStatus
+		if err := c.Client.Status().Patch(ctx, ipInstance, patch); err != nil {
+			c.Logger.Error(err, "unable to patch skewed status of ip instance", "ipInstance", client.ObjectKeyFromObject(ipInstance))
+			continue
+		}
+
+		metrics.IPInstanceSpecStatusSkewCounter.WithLabelValues(ipInstance.Spec.Network).Inc()
+		c.Logger.Info("skewed status of ip instance is fixed", "ipInstance", client.ObjectKeyFromObject(ipInstance),
+			"nodeName", ipInstance.Spec.Binding.NodeName, "podName", ipInstance.Spec.Binding.PodName)
+	}
+
+	return nil
+}
+
+// expectedIPInstanceStatus returns the status which is consistent with the binding of IPInstance,
+// and whether the current status skews from it
+func expectedIPInstanceStatus(ipInstance *networkingv1.IPInstance) (networkingv1.IPInstanceStatus, bool) {
+	binding := ipInstance.Spec.Binding
+	status := ipInstance.Status
+
+	switch {
+	case len(binding.NodeName) == 0 && len(binding.PodUID) == 0:
+		// reserved IPInstance is not used by any sandbox, as what ipam store does on reservation
+		status = networkingv1.IPInstanceStatus{
+			UpdateTimestamp: status.UpdateTimestamp,
+		}
+	case len(status.NodeName) != 0 && status.NodeName != binding.NodeName,
+		len(status.PodName) != 0 && status.PodName != binding.PodName,
+		len(status.PodNamespace) != 0 && status.PodNamespace != ipInstance.Namespace:
+		// status is left by the sandbox of last binding, daemon will record the new sandbox on
+		// the node of binding once it is created
+		status.NodeName = ""
+		status.PodName = ""
+		status.PodNamespace = ""
+		status.SandboxID = ""
+	}
+
+	return status, !equality.Semantic.DeepEqual(status, ipInstance.Status)
+}
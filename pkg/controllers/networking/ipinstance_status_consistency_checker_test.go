@@ -0,0 +1,117 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestExpectedIPInstanceStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  networkingv1.Binding
+		status   networkingv1.IPInstanceStatus
+		expected networkingv1.IPInstanceStatus
+		skewed   bool
+	}{
+		{
+			name:    "bound and configured",
+			binding: networkingv1.Binding{NodeName: "node1", PodUID: "uid1", PodName: "pod1"},
+			status:  networkingv1.IPInstanceStatus{NodeName: "node1", PodName: "pod1", PodNamespace: "ns1", SandboxID: "sandbox1"},
+			expected: networkingv1.IPInstanceStatus{NodeName: "node1", PodName: "pod1", PodNamespace: "ns1",
+				SandboxID: "sandbox1"},
+		},
+		{
+			name:     "bound but not configured yet",
+			binding:  networkingv1.Binding{NodeName: "node1", PodUID: "uid1", PodName: "pod1"},
+			status:   networkingv1.IPInstanceStatus{DNSHostname: "pod1"},
+			expected: networkingv1.IPInstanceStatus{DNSHostname: "pod1"},
+		},
+		{
+			name:     "reserved but status written by daemon afterwards",
+			binding:  networkingv1.Binding{PodName: "pod1"},
+			status:   networkingv1.IPInstanceStatus{NodeName: "node1", PodName: "pod1", PodNamespace: "ns1", SandboxID: "sandbox1", DNSHostname: "pod1"},
+			expected: networkingv1.IPInstanceStatus{},
+			skewed:   true,
+		},
+		{
+			name:     "rebound to another node",
+			binding:  networkingv1.Binding{NodeName: "node2", PodUID: "uid2", PodName: "pod1"},
+			status:   networkingv1.IPInstanceStatus{NodeName: "node1", PodName: "pod1", PodNamespace: "ns1", SandboxID: "sandbox1", DNSHostname: "pod1"},
+			expected: networkingv1.IPInstanceStatus{DNSHostname: "pod1"},
+			skewed:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ipInstance := &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "ip1", Namespace: "ns1"},
+				Spec:       networkingv1.IPInstanceSpec{Binding: test.binding},
+				Status:     test.status,
+			}
+			status, skewed := expectedIPInstanceStatus(ipInstance)
+			if skewed != test.skewed {
+				t.Fatalf("expected skewed %v but got %v", test.skewed, skewed)
+			}
+			if status != test.expected {
+				t.Fatalf("expected status %+v but got %+v", test.expected, status)
+			}
+		})
+	}
+}
+
+func TestIPInstanceStatusConsistencyCheckerCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&networkingv1.IPInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "ip1", Namespace: "ns1"},
+		Spec:       networkingv1.IPInstanceSpec{Network: "network1"},
+		Status:     networkingv1.IPInstanceStatus{NodeName: "node1", PodName: "pod1", PodNamespace: "ns1", SandboxID: "sandbox1"},
+	}).Build()
+
+	checker := &IPInstanceStatusConsistencyChecker{Client: c, Logger: log.Log, CheckPeriod: time.Minute}
+	now := time.Now()
+	if err := checker.check(ctx, now); err != nil {
+		t.Fatalf("fail to check: %v", err)
+	}
+
+	ipInstance := &networkingv1.IPInstance{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "ip1", Namespace: "ns1"}, ipInstance); err != nil {
+		t.Fatalf("fail to get ip instance: %v", err)
+	}
+	if len(ipInstance.Status.NodeName) != 0 || len(ipInstance.Status.SandboxID) != 0 {
+		t.Fatalf("expected status of reserved ip instance cleared but got %+v", ipInstance.Status)
+	}
+	if ipInstance.Status.UpdateTimestamp.Unix() != now.Unix() {
+		t.Fatalf("expected update timestamp %v but got %v", now, ipInstance.Status.UpdateTimestamp)
+	}
+}
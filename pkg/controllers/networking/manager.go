@@ -19,22 +19,92 @@ package networking
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/feature"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
 )
 
 type RegisterOptions struct {
-	NewIPAMManager NewIPAMManagerFunction
-	ConcurrencyMap map[string]int
+	NewIPAMManager  NewIPAMManagerFunction
+	ConcurrencyMap  map[string]int
+	IPAMFitStrategy ipamtypes.FitStrategy
+
+	// EnableSchemaMigration enables the migration of IPInstances which are not of the latest schema
+	EnableSchemaMigration bool
+
+	// IPAMConsistencyCheckInterval is the period of checking ipam state drift, zero disables it
+	IPAMConsistencyCheckInterval time.Duration
+	// IPAMAutoHeal rebuilds the drifted ipam state if drift is detected
+	IPAMAutoHeal bool
+
+	// EnableEndpointSliceSync enables the verification of EndpointSlices against IPInstances
+	EnableEndpointSliceSync bool
+
+	// SubnetRetryTimeout is how long a subnet keeps failing with permanent errors before subnet
+	// controller gives up retrying it until it is updated, zero means retrying forever
+	SubnetRetryTimeout time.Duration
+
+	// PodEventDebounce is the window of coalescing update events of a pod into one reconciliation,
+	// zero disables it
+	PodEventDebounce time.Duration
+
+	// NodeDeleteIPWorkers is the number of workers deleting IPInstances of a deleted node, zero disables it
+	NodeDeleteIPWorkers int
+
+	// StatefulIPStalenessCheckInterval is the period of checking retained IPInstances of StatefulSets
+	// with indexes out of replicas, zero disables it
+	StatefulIPStalenessCheckInterval time.Duration
+	// StatefulIPStalenessGracePeriod is how long a stale IPInstance is kept before deletion, zero means only warning
+	StatefulIPStalenessGracePeriod time.Duration
+
+	// IPInstanceAgeCheckInterval is the period of purging IPInstances older than the max age of
+	// NetworkingPolicies, zero disables it
+	IPInstanceAgeCheckInterval time.Duration
+
+	// IPInstanceStatusConsistencyCheckInterval is the period of fixing the status of IPInstances which
+	// skews from their bindings, zero disables it
+	IPInstanceStatusConsistencyCheckInterval time.Duration
+
+	// SubnetAllocationHistoryInterval is the period of snapshotting used IPs of subnets into their
+	// allocation histories, zero disables it
+	SubnetAllocationHistoryInterval time.Duration
+	// SubnetAllocationHistoryNamespace is where ConfigMaps of subnet allocation histories are stored
+	SubnetAllocationHistoryNamespace string
+
+	// NetworkObservabilityReportInterval is the period of refreshing NetworkObservabilityReport, zero disables it
+	NetworkObservabilityReportInterval time.Duration
+
+	// DNSRegistrationZone is the DNS zone which hostnames of pods with underlay IPs are registered
+	// under, only works if UnderlayDNSRegistration feature is enabled
+	DNSRegistrationZone string
+
+	// ControllerInstance is the identity of manager instance, which is recorded on allocated IPInstances
+	ControllerInstance string
+
+	// NetworkShard is the shard of networks reconciled by manager instance, cluster-scoped
+	// controllers only run in the primary shard
+	NetworkShard NetworkShard
+
+	// IPAMDebugHandler serves the in-memory ipam state once IPAM manager is initialized, nil disables it
+	IPAMDebugHandler *IPAMDebugHandler
 }
 
 func RegisterToManager(ctx context.Context, mgr manager.Manager, options RegisterOptions) error {
+	if len(options.IPAMFitStrategy) == 0 {
+		options.IPAMFitStrategy = ipamtypes.FirstFit
+	}
 	if options.NewIPAMManager == nil {
-		options.NewIPAMManager = NewIPAMManager
+		options.NewIPAMManager = func(ctx context.Context, c client.Client) (IPAMManager, error) {
+			return NewIPAMManagerOfShard(ctx, c, options.NetworkShard, options.IPAMFitStrategy)
+		}
 	}
 	if len(options.ConcurrencyMap) == 0 {
 		options.ConcurrencyMap = map[string]int{}
@@ -46,6 +116,10 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		return fmt.Errorf("unable to create IPAM manager: %v", err)
 	}
 
+	if options.IPAMDebugHandler != nil {
+		options.IPAMDebugHandler.SetIPAMManager(ipamManager)
+	}
+
 	podIPCache, err := NewPodIPCache(ctx, mgr.GetClient(), ctrllog.Log.WithName("pod-ip-cache"))
 	if err != nil {
 		return fmt.Errorf("unable to create Pod IP cache: %v", err)
@@ -62,6 +136,7 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		IPAMManager:             ipamManager,
 		NetworkStatusUpdateChan: networkStatusUpdateChan,
 		SubnetStatusUpdateChan:  subnetStatusUpdateChan,
+		Shard:                   options.NetworkShard,
 		ControllerConcurrency:   concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerIPAM]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerIPAM, err)
@@ -72,26 +147,67 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		PodIPCache:            podIPCache,
 		IPAMManager:           ipamManager,
 		IPAMStore:             ipamStore,
+		Shard:                 options.NetworkShard,
 		ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerIPInstance]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerIPInstance, err)
 	}
 
-	if err = (&NodeReconciler{
-		Context:               ctx,
-		Client:                mgr.GetClient(),
-		ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerNode]),
-	}).SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("unable to inject controller %s: %v", ControllerNode, err)
+	if options.NetworkShard.IsPrimary() && feature.UnderlayDNSRegistrationEnabled() {
+		if err = (&DNSSyncReconciler{
+			Client:                mgr.GetClient(),
+			Zone:                  options.DNSRegistrationZone,
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerDNSSync]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerDNSSync, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.EnableSchemaMigration {
+		if err = (&IPInstanceMigrationReconciler{
+			Client:                mgr.GetClient(),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerIPInstanceMigration]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerIPInstanceMigration, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() {
+		if err = (&NodeReconciler{
+			Context:               ctx,
+			Client:                mgr.GetClient(),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerNode]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerNode, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.NodeDeleteIPWorkers > 0 {
+		if err = (&NodeDeletionReconciler{
+			Client:                mgr.GetClient(),
+			Workers:               options.NodeDeleteIPWorkers,
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerNodeDeletion]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerNodeDeletion, err)
+		}
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("unable to create kubernetes client: %v", err)
 	}
 
 	if err = (&PodReconciler{
 		APIReader:             mgr.GetAPIReader(),
 		Client:                mgr.GetClient(),
 		Recorder:              mgr.GetEventRecorderFor(ControllerPod + "Controller"),
+		KubeClient:            kubeClient,
 		PodIPCache:            podIPCache,
 		IPAMStore:             ipamStore,
 		IPAMManager:           ipamManager,
+		ControllerInstance:    options.ControllerInstance,
+		EventDebounce:         options.PodEventDebounce,
+		Shard:                 options.NetworkShard,
 		ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerPod]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerPod, err)
@@ -103,6 +219,7 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		IPAMManager:             ipamManager,
 		Recorder:                mgr.GetEventRecorderFor(ControllerNetworkStatus + "Controller"),
 		NetworkStatusUpdateChan: networkStatusUpdateChan,
+		Shard:                   options.NetworkShard,
 		ControllerConcurrency:   concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerNetworkStatus]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerNetworkStatus, err)
@@ -113,17 +230,127 @@ func RegisterToManager(ctx context.Context, mgr manager.Manager, options Registe
 		IPAMManager:            ipamManager,
 		Recorder:               mgr.GetEventRecorderFor(ControllerSubnetStatus + "Controller"),
 		SubnetStatusUpdateChan: subnetStatusUpdateChan,
+		Shard:                  options.NetworkShard,
 		ControllerConcurrency:  concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerSubnetStatus]),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to inject controller %s: %v", ControllerSubnetStatus, err)
 	}
 
-	if err = (&QuotaReconciler{
-		Context:               ctx,
+	if err = (&SubnetReconciler{
 		Client:                mgr.GetClient(),
-		ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerQuota]),
+		Recorder:              mgr.GetEventRecorderFor(ControllerSubnet + "Controller"),
+		RetryTimeout:          options.SubnetRetryTimeout,
+		Shard:                 options.NetworkShard,
+		ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerSubnet]),
 	}).SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("unable to inject controller %s: %v", ControllerQuota, err)
+		return fmt.Errorf("unable to inject controller %s: %v", ControllerSubnet, err)
+	}
+
+	if options.NetworkShard.IsPrimary() {
+		if err = (&SubnetPoolReconciler{
+			Client:                mgr.GetClient(),
+			Recorder:              mgr.GetEventRecorderFor(ControllerSubnetPool + "Controller"),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerSubnetPool]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerSubnetPool, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() {
+		if err = (&NetworkQuotaReconciler{
+			Client:                mgr.GetClient(),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerNetworkQuota]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerNetworkQuota, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.EnableEndpointSliceSync {
+		if err = (&EndpointSliceSyncer{
+			Client:                mgr.GetClient(),
+			Recorder:              mgr.GetEventRecorderFor(ControllerEndpointSlice + "Controller"),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerEndpointSlice]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerEndpointSlice, err)
+		}
+	}
+
+	if options.IPAMConsistencyCheckInterval > 0 {
+		if err = mgr.Add(&IPAMConsistencyChecker{
+			Logger:      mgr.GetLogger().WithName("checker").WithName(CheckerIPAMConsistency),
+			IPAMManager: ipamManager,
+			CheckPeriod: options.IPAMConsistencyCheckInterval,
+			AutoHeal:    options.IPAMAutoHeal,
+		}); err != nil {
+			return fmt.Errorf("unable to inject checker %s: %v", CheckerIPAMConsistency, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.StatefulIPStalenessCheckInterval > 0 {
+		if err = mgr.Add(&StatefulIPStalenessChecker{
+			Client:      mgr.GetClient(),
+			APIReader:   mgr.GetAPIReader(),
+			Recorder:    mgr.GetEventRecorderFor(CheckerStatefulIPStaleness + "Checker"),
+			Logger:      mgr.GetLogger().WithName("checker").WithName(CheckerStatefulIPStaleness),
+			CheckPeriod: options.StatefulIPStalenessCheckInterval,
+			GracePeriod: options.StatefulIPStalenessGracePeriod,
+		}); err != nil {
+			return fmt.Errorf("unable to inject checker %s: %v", CheckerStatefulIPStaleness, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.IPInstanceAgeCheckInterval > 0 {
+		if err = mgr.Add(&IPInstanceAgeChecker{
+			Client:      mgr.GetClient(),
+			Recorder:    mgr.GetEventRecorderFor(CheckerIPInstanceAge + "Checker"),
+			Logger:      mgr.GetLogger().WithName("checker").WithName(CheckerIPInstanceAge),
+			CheckPeriod: options.IPInstanceAgeCheckInterval,
+		}); err != nil {
+			return fmt.Errorf("unable to inject checker %s: %v", CheckerIPInstanceAge, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.IPInstanceStatusConsistencyCheckInterval > 0 {
+		if err = mgr.Add(&IPInstanceStatusConsistencyChecker{
+			Client:      mgr.GetClient(),
+			Logger:      mgr.GetLogger().WithName("checker").WithName(CheckerIPInstanceStatusConsistency),
+			CheckPeriod: options.IPInstanceStatusConsistencyCheckInterval,
+		}); err != nil {
+			return fmt.Errorf("unable to inject checker %s: %v", CheckerIPInstanceStatusConsistency, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.NetworkObservabilityReportInterval > 0 {
+		if err = mgr.Add(&NetworkObservabilityReporter{
+			Client:       mgr.GetClient(),
+			Logger:       mgr.GetLogger().WithName("reporter").WithName(ReporterNetworkObservability),
+			ReportPeriod: options.NetworkObservabilityReportInterval,
+		}); err != nil {
+			return fmt.Errorf("unable to inject reporter %s: %v", ReporterNetworkObservability, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() && options.SubnetAllocationHistoryInterval > 0 {
+		if err = mgr.Add(&SubnetAllocationHistoryRecorder{
+			Client:       mgr.GetClient(),
+			APIReader:    mgr.GetAPIReader(),
+			Recorder:     mgr.GetEventRecorderFor(RecorderSubnetAllocationHistory + "Recorder"),
+			Logger:       mgr.GetLogger().WithName("recorder").WithName(RecorderSubnetAllocationHistory),
+			Namespace:    options.SubnetAllocationHistoryNamespace,
+			RecordPeriod: options.SubnetAllocationHistoryInterval,
+		}); err != nil {
+			return fmt.Errorf("unable to inject recorder %s: %v", RecorderSubnetAllocationHistory, err)
+		}
+	}
+
+	if options.NetworkShard.IsPrimary() {
+		if err = (&QuotaReconciler{
+			Context:               ctx,
+			Client:                mgr.GetClient(),
+			ControllerConcurrency: concurrency.ControllerConcurrency(options.ConcurrencyMap[ControllerQuota]),
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to inject controller %s: %v", ControllerQuota, err)
+		}
 	}
 
 	return nil
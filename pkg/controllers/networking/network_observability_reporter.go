@@ -0,0 +1,180 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/feature"
+)
+
+const ReporterNetworkObservability = "NetworkObservability"
+
+// NetworkObservabilityReportName is the name of the only NetworkObservabilityReport maintained by manager
+const NetworkObservabilityReportName = "cluster"
+
+const (
+	networkObservabilityArchivePeriod = time.Hour
+	networkObservabilityHistoryLimit  = 24
+)
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=networkobservabilityreports,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=networkobservabilityreports/status,verbs=get;update;patch
+
+// NetworkObservabilityReporter collects cluster-wide networking stats periodically into the
+// NetworkObservabilityReport, and archives them hourly into the history of report
+type NetworkObservabilityReporter struct {
+	Client client.Client
+	Logger logr.Logger
+
+	ReportPeriod time.Duration
+}
+
+func (r *NetworkObservabilityReporter) Start(ctx context.Context) error {
+	r.Logger.Info("network observability reporter is starting", "period", r.ReportPeriod)
+
+	ticker := time.NewTicker(r.ReportPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.report(ctx, time.Now()); err != nil {
+				r.Logger.Error(err, "unable to report network observability")
+			}
+		case <-ctx.Done():
+			r.Logger.Info("network observability reporter is stopping")
+			return nil
+		}
+	}
+}
+
+func (r *NetworkObservabilityReporter) report(ctx context.Context, now time.Time) error {
+	snapshot, err := r.collect(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	report := &networkingv1.NetworkObservabilityReport{}
+	if err = r.Client.Get(ctx, client.ObjectKey{Name: NetworkObservabilityReportName}, report); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to get network observability report: %v", err)
+		}
+
+		report = &networkingv1.NetworkObservabilityReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: NetworkObservabilityReportName,
+			},
+		}
+		if err = r.Client.Create(ctx, report); err != nil {
+			return fmt.Errorf("unable to create network observability report: %v", err)
+		}
+	}
+
+	report.Status.NetworkObservabilitySnapshot = *snapshot
+	report.Status.History = archiveNetworkObservabilitySnapshot(report.Status.History, snapshot)
+	if err = r.Client.Status().Update(ctx, report); err != nil {
+		return fmt.Errorf("unable to update network observability report: %v", err)
+	}
+	return nil
+}
+
+func (r *NetworkObservabilityReporter) collect(ctx context.Context, now time.Time) (*networkingv1.NetworkObservabilitySnapshot, error) {
+	snapshot := &networkingv1.NetworkObservabilitySnapshot{
+		Timestamp: metav1.NewTime(now),
+	}
+
+	networkList := &networkingv1.NetworkList{}
+	if err := r.Client.List(ctx, networkList); err != nil {
+		return nil, fmt.Errorf("unable to list networks: %v", err)
+	}
+	snapshot.Networks = int32(len(networkList.Items))
+
+	// a node may be attached to multiple networks of the same type
+	overlayNodes, underlayNodes := map[string]struct{}{}, map[string]struct{}{}
+	for i := range networkList.Items {
+		network := &networkList.Items[i]
+		nodes := underlayNodes
+		if networkingv1.GetNetworkType(network) == networkingv1.NetworkTypeOverlay {
+			nodes = overlayNodes
+		}
+		for _, nodeName := range network.Status.NodeList {
+			nodes[nodeName] = struct{}{}
+		}
+	}
+	snapshot.OverlayNodes, snapshot.UnderlayNodes = int32(len(overlayNodes)), int32(len(underlayNodes))
+
+	subnetList := &networkingv1.SubnetList{}
+	if err := r.Client.List(ctx, subnetList); err != nil {
+		return nil, fmt.Errorf("unable to list subnets: %v", err)
+	}
+	snapshot.Subnets = int32(len(subnetList.Items))
+
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := r.Client.List(ctx, ipInstanceList); err != nil {
+		return nil, fmt.Errorf("unable to list ip instances: %v", err)
+	}
+	for i := range ipInstanceList.Items {
+		switch ipInstanceList.Items[i].Spec.Address.Version {
+		case networkingv1.IPv4:
+			snapshot.IPInstances.IPv4++
+		case networkingv1.IPv6:
+			snapshot.IPInstances.IPv6++
+		}
+	}
+
+	if feature.MultiClusterEnabled() {
+		remoteClusterList := &multiclusterv1.RemoteClusterList{}
+		if err := r.Client.List(ctx, remoteClusterList); err != nil {
+			return nil, fmt.Errorf("unable to list remote clusters: %v", err)
+		}
+		for i := range remoteClusterList.Items {
+			remoteCluster := &remoteClusterList.Items[i]
+			snapshot.RemoteClusters = append(snapshot.RemoteClusters, networkingv1.RemoteClusterConnectivity{
+				Name:  remoteCluster.Name,
+				State: string(remoteCluster.Status.State),
+			})
+		}
+	}
+
+	return snapshot, nil
+}
+
+// archiveNetworkObservabilitySnapshot appends snapshot to history if the last archived one is
+// older than the archive period, and keeps only the latest snapshots within the history limit
+func archiveNetworkObservabilitySnapshot(history []networkingv1.NetworkObservabilitySnapshot,
+	snapshot *networkingv1.NetworkObservabilitySnapshot) []networkingv1.NetworkObservabilitySnapshot {
+	if len(history) > 0 &&
+		snapshot.Timestamp.Sub(history[len(history)-1].Timestamp.Time) < networkObservabilityArchivePeriod {
+		return history
+	}
+
+	history = append(history, *snapshot.DeepCopy())
+	if len(history) > networkObservabilityHistoryLimit {
+		history = history[len(history)-networkObservabilityHistoryLimit:]
+	}
+	return history
+}
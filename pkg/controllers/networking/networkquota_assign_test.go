@@ -0,0 +1,97 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestCheckNetworkQuotaOfAssignedIPs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&networkingv1.NetworkQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "ns1"},
+			Spec:       networkingv1.NetworkQuotaSpec{Hard: networkingv1.NetworkQuotaResources{IPs: pointer.Int64(2)}},
+		},
+		// retained IP of a stateful pod in namespace
+		&networkingv1.IPInstance{ObjectMeta: metav1.ObjectMeta{Name: "10-0-0-1", Namespace: "ns1"}},
+		// IP held by another namespace is not counted
+		&networkingv1.IPInstance{ObjectMeta: metav1.ObjectMeta{Name: "10-0-0-9", Namespace: "ns2"}},
+	).Build()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "sts-0", Namespace: "ns1"}}
+
+	tests := []struct {
+		name         string
+		ipCandidates []ipCandidate
+		newIPs       int64
+		exceeded     bool
+	}{
+		{
+			name:         "reusing retained ip",
+			ipCandidates: []ipCandidate{{subnet: "subnet1", ip: "10.0.0.1"}},
+			newIPs:       0,
+		},
+		{
+			name:         "pre-assigning one new ip",
+			ipCandidates: []ipCandidate{{ip: "10.0.0.2"}},
+			newIPs:       1,
+		},
+		{
+			name:         "pre-assigning ip held by another namespace",
+			ipCandidates: []ipCandidate{{ip: "10.0.0.9"}},
+			newIPs:       1,
+		},
+		{
+			name:         "pre-assigning new ips beyond quota",
+			ipCandidates: []ipCandidate{{ip: "10.0.0.2"}, {ip: "10.0.0.3"}},
+			newIPs:       2,
+			exceeded:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newIPs, err := countNewIPsOfCandidates(context.Background(), c, pod.Namespace, test.ipCandidates)
+			if err != nil {
+				t.Fatalf("fail to count new ips: %v", err)
+			}
+			if newIPs != test.newIPs {
+				t.Fatalf("expect %d new ips but got %d", test.newIPs, newIPs)
+			}
+
+			err = checkNetworkQuota(context.Background(), c, record.NewFakeRecorder(10), pod, newIPs)
+			if exceeded := errors.Is(err, ErrNetworkQuotaExceeded); exceeded != test.exceeded {
+				t.Fatalf("expect exceeded %v but got %v", test.exceeded, err)
+			}
+		})
+	}
+}
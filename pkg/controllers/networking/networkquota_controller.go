@@ -0,0 +1,214 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	globalutils "github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const ControllerNetworkQuota = "NetworkQuota"
+
+const ReasonNetworkQuotaExceeded = "NetworkQuotaExceeded"
+
+// ErrNetworkQuotaExceeded means allocating IPs for pod will exceed the hard limits of namespace
+var ErrNetworkQuotaExceeded = errors.New("network quota exceeded")
+
+// NetworkQuotaReconciler reconciles the usage of NetworkQuota by counting IPInstances of namespace,
+// the hard limits are enforced by pod controller before allocating IPs
+type NetworkQuotaReconciler struct {
+	client.Client
+
+	concurrency.ControllerConcurrency
+}
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=networkquotas,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=networkquotas/status,verbs=get;update;patch
+
+func (r *NetworkQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var err error
+	var quota networkingv1.NetworkQuota
+	if err = r.Get(ctx, req.NamespacedName, &quota); err != nil {
+		return ctrl.Result{}, wrapError("unable to fetch NetworkQuota", client.IgnoreNotFound(err))
+	}
+
+	if !quota.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	var usedIPs int64
+	if usedIPs, err = countIPInstancesOfNamespace(ctx, r, quota.Namespace); err != nil {
+		return ctrl.Result{}, wrapError("unable to count IPInstances", err)
+	}
+
+	if quota.Status.Used.IPs != nil && *quota.Status.Used.IPs == usedIPs {
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(quota.DeepCopy())
+	quota.Status.Used.IPs = &usedIPs
+	quota.Status.UpdateTimestamp = metav1.Now()
+	return ctrl.Result{}, wrapError("unable to update NetworkQuota status", r.Status().Patch(ctx, &quota, patch))
+}
+
+// countIPInstancesOfNamespace counts the IPInstances in namespace, reserved ones are included
+// because they still occupy IPs
+func countIPInstancesOfNamespace(ctx context.Context, c client.Reader, namespace string) (int64, error) {
+	ipList, err := utils.ListIPInstances(ctx, c, client.InNamespace(namespace))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for i := range ipList.Items {
+		if ipList.Items[i].DeletionTimestamp.IsZero() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// requiredIPsOfFamily returns the number of IPs allocated for a pod of IP family
+func requiredIPsOfFamily(ipFamily ipamtypes.IPFamilyMode) int64 {
+	if ipFamily == ipamtypes.DualStack {
+		return 2
+	}
+	return 1
+}
+
+// countNewIPsOfCandidates counts the IP candidates which are not held by IPInstances of namespace,
+// only assigning these ones consumes more quota, e.g., retained IPs of stateful pods do not
+func countNewIPsOfCandidates(ctx context.Context, c client.Reader, namespace string, ipCandidates []ipCandidate) (int64, error) {
+	var count int64
+	for _, candidate := range ipCandidates {
+		ip := net.ParseIP(candidate.ip)
+		if ip == nil {
+			return 0, fmt.Errorf("invalid ip candidate %s", candidate.ip)
+		}
+
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: globalutils.ToDNSFormat(ip)},
+			&networkingv1.IPInstance{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return 0, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// checkNetworkQuota checks if allocating more IPs for pod exceeds any NetworkQuota of namespace,
+// a warning event will be emitted on the exceeded NetworkQuota.
+// NOTICE: IPInstances are counted from cache, concurrent allocations in the same namespace may
+// exceed the hard limits in a short time
+func checkNetworkQuota(ctx context.Context, c client.Reader, recorder record.EventRecorder,
+	pod *corev1.Pod, requiredIPs int64) error {
+	if requiredIPs <= 0 {
+		return nil
+	}
+
+	var quotaList networkingv1.NetworkQuotaList
+	if err := c.List(ctx, &quotaList, client.InNamespace(pod.Namespace)); err != nil {
+		return fmt.Errorf("unable to list NetworkQuotas: %v", err)
+	}
+
+	if len(quotaList.Items) == 0 {
+		return nil
+	}
+
+	usedIPs, err := countIPInstancesOfNamespace(ctx, c, pod.Namespace)
+	if err != nil {
+		return fmt.Errorf("unable to count IPInstances: %v", err)
+	}
+
+	for i := range quotaList.Items {
+		quota := &quotaList.Items[i]
+		if quota.Spec.Hard.IPs == nil || usedIPs+requiredIPs <= *quota.Spec.Hard.IPs {
+			continue
+		}
+
+		recorder.Eventf(quota, corev1.EventTypeWarning, ReasonNetworkQuotaExceeded,
+			"pod %s requires %d IPs, used %d, limited %d", pod.Name, requiredIPs, usedIPs, *quota.Spec.Hard.IPs)
+		return fmt.Errorf("%w: NetworkQuota %s limits %d IPs, used %d, required %d", ErrNetworkQuotaExceeded,
+			quota.Name, *quota.Spec.Hard.IPs, usedIPs, requiredIPs)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NetworkQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerNetworkQuota).
+		For(&networkingv1.NetworkQuota{}, builder.WithPredicates(
+			&utils.IgnoreDeletePredicate{},
+			&predicate.GenerationChangedPredicate{},
+		)).
+		Watches(&source.Kind{Type: &networkingv1.IPInstance{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				var quotaList networkingv1.NetworkQuotaList
+				if err := r.List(context.TODO(), &quotaList, client.InNamespace(object.GetNamespace())); err != nil {
+					return nil
+				}
+
+				var requests []reconcile.Request
+				for i := range quotaList.Items {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Namespace: quotaList.Items[i].Namespace,
+							Name:      quotaList.Items[i].Name,
+						},
+					})
+				}
+				return requests
+			}),
+			builder.WithPredicates(
+				predicate.Funcs{
+					// usage only changes when IPInstance is created, deleted or becomes terminating
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						return e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero()
+					},
+				},
+			)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
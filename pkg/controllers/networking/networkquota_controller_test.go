@@ -0,0 +1,126 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+var _ = Describe("NetworkQuota controller integration test suite", func() {
+	Context("Lock", func() {
+		testLock.Lock()
+	})
+
+	Context("IP quota enforcement of namespace", func() {
+		var (
+			namespace = fmt.Sprintf("network-quota-%s", uuid.NewUUID())
+			quotaName = "test-network-quota"
+		)
+
+		It("Pods exceeding the IP quota of namespace should not be allocated", func() {
+			By("create test namespace and network quota")
+			Expect(k8sClient.Create(context.Background(), &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: namespace,
+				},
+			})).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(context.Background(), &networkingv1.NetworkQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      quotaName,
+					Namespace: namespace,
+				},
+				Spec: networkingv1.NetworkQuotaSpec{
+					Hard: networkingv1.NetworkQuotaResources{
+						IPs: pointer.Int64(1),
+					},
+				},
+			})).NotTo(HaveOccurred())
+
+			By("create the first pod within quota")
+			pod1 := simplePodRender(fmt.Sprintf("pod-%s", uuid.NewUUID()), node1Name)
+			pod1.Namespace = namespace
+			Expect(k8sClient.Create(context.Background(), pod1)).Should(Succeed())
+
+			Eventually(
+				func(g Gomega) {
+					ipInstances, err := utils.ListAllocatedIPInstancesOfPod(context.Background(), k8sClient, pod1)
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(ipInstances).To(HaveLen(1))
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("check usage of network quota")
+			Eventually(
+				func(g Gomega) {
+					quota := &networkingv1.NetworkQuota{}
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{
+						Namespace: namespace,
+						Name:      quotaName,
+					}, quota)).NotTo(HaveOccurred())
+					g.Expect(quota.Status.Used.IPs).To(Equal(pointer.Int64(1)))
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("create the second pod exceeding quota")
+			pod2 := simplePodRender(fmt.Sprintf("pod-%s", uuid.NewUUID()), node1Name)
+			pod2.Namespace = namespace
+			Expect(k8sClient.Create(context.Background(), pod2)).Should(Succeed())
+
+			Consistently(
+				func(g Gomega) {
+					ipInstances, err := utils.ListAllocatedIPInstancesOfPod(context.Background(), k8sClient, pod2)
+					g.Expect(err).NotTo(HaveOccurred())
+					g.Expect(ipInstances).To(BeEmpty())
+				}).
+				WithTimeout(5 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("remove the test pods and network quota")
+			Expect(k8sClient.Delete(context.Background(), pod1, client.GracePeriodSeconds(0))).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(context.Background(), pod2, client.GracePeriodSeconds(0))).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(context.Background(), &networkingv1.NetworkQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      quotaName,
+					Namespace: namespace,
+				},
+			})).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Unlock", func() {
+		testLock.Unlock()
+	})
+})
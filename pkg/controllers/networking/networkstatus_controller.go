@@ -60,6 +60,10 @@ type NetworkStatusReconciler struct {
 
 	NetworkStatusUpdateChan <-chan event.GenericEvent
 
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
 	concurrency.ControllerConcurrency
 }
 
@@ -72,6 +76,10 @@ func (r *NetworkStatusReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	var network = &networkingv1.Network{}
 
+	if !r.Shard.Owns(req.Name) {
+		return ctrl.Result{}, nil
+	}
+
 	defer func() {
 		if err != nil {
 			log.Error(err, "reconciliation fails")
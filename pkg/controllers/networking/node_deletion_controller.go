@@ -0,0 +1,120 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+const ControllerNodeDeletion = "NodeDeletion"
+
+// NodeDeletionReconciler deletes the IPInstances of pods on a deleted node in parallel, so that
+// IPs can be reclaimed without waiting for the pod controller to process pods one by one
+type NodeDeletionReconciler struct {
+	client.Client
+
+	// Workers is the number of workers deleting IPInstances of a node in parallel
+	Workers int
+
+	concurrency.ControllerConcurrency
+}
+
+func (r *NodeDeletionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	var err error
+	if err = r.Get(ctx, req.NamespacedName, &corev1.Node{}); err == nil {
+		return ctrl.Result{}, nil
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, wrapError("unable to fetch Node", err)
+	}
+
+	ipList, err := utils.ListIPInstances(ctx, r, client.MatchingLabels{constants.LabelNode: req.Name})
+	if err != nil {
+		return ctrl.Result{}, wrapError("unable to list IPInstances of node", err)
+	}
+
+	// IPInstances of stateful workloads and virtual machines are retained by pod controller,
+	// only the ones owned by pods directly are deleted here
+	var toDelete = make([]int, 0, len(ipList.Items))
+	for i := range ipList.Items {
+		ipInstance := &ipList.Items[i]
+		if ipInstance.DeletionTimestamp.IsZero() && ipInstance.Spec.Binding.ReferredObject.Kind == "Pod" {
+			toDelete = append(toDelete, i)
+		}
+	}
+
+	if len(toDelete) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	log.V(1).Info("deleting IPInstances of deleted node", "count", len(toDelete), "workers", r.Workers)
+
+	var errList []error
+	var errLock sync.Mutex
+	workqueue.ParallelizeUntil(ctx, r.Workers, len(toDelete), func(piece int) {
+		if err := client.IgnoreNotFound(r.Delete(ctx, &ipList.Items[toDelete[piece]])); err != nil {
+			errLock.Lock()
+			errList = append(errList, err)
+			errLock.Unlock()
+		}
+	})
+
+	return ctrl.Result{}, wrapError("unable to delete IPInstances of node", errors.NewAggregate(errList))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NodeDeletionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerNodeDeletion).
+		For(&corev1.Node{},
+			builder.WithPredicates(
+				predicate.Funcs{
+					CreateFunc: func(event.CreateEvent) bool {
+						return false
+					},
+					UpdateFunc: func(event.UpdateEvent) bool {
+						return false
+					},
+					GenericFunc: func(event.GenericEvent) bool {
+						return false
+					},
+				},
+			)).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// deleteFailingClient fails every deletion of objects with err
+type deleteFailingClient struct {
+	client.Client
+	err error
+}
+
+func (d deleteFailingClient) Delete(context.Context, client.Object, ...client.DeleteOption) error {
+	return d.err
+}
+
+func TestNodeDeletionReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	ipInstance := func(name, node, ownerKind string) *networkingv1.IPInstance {
+		return &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{constants.LabelNode: node},
+			},
+			Spec: networkingv1.IPInstanceSpec{
+				Binding: networkingv1.Binding{
+					ReferredObject: networkingv1.ObjectMeta{Kind: ownerKind, Name: name},
+					NodeName:       node,
+				},
+			},
+		}
+	}
+
+	newClient := func() client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "alive"}},
+			ipInstance("pod1", "deleted", "Pod"),
+			ipInstance("pod2", "deleted", "Pod"),
+			ipInstance("pod3", "deleted", "Pod"),
+			ipInstance("sts-0", "deleted", "StatefulSet"),
+			ipInstance("pod4", "alive", "Pod"),
+		).Build()
+	}
+
+	remaining := func(t *testing.T, c client.Client) []string {
+		ipList := &networkingv1.IPInstanceList{}
+		if err := c.List(context.Background(), ipList); err != nil {
+			t.Fatalf("fail to list IPInstances: %v", err)
+		}
+		var names []string
+		for _, ip := range ipList.Items {
+			names = append(names, ip.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("node exists", func(t *testing.T) {
+		c := newClient()
+		r := &NodeDeletionReconciler{Client: c, Workers: 2}
+		if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "alive"}}); err != nil {
+			t.Fatalf("fail to reconcile: %v", err)
+		}
+		if names := remaining(t, c); len(names) != 5 {
+			t.Errorf("expect no IPInstance deleted but got %v", names)
+		}
+	})
+
+	t.Run("node deleted", func(t *testing.T) {
+		c := newClient()
+		r := &NodeDeletionReconciler{Client: c, Workers: 2}
+		if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "deleted"}}); err != nil {
+			t.Fatalf("fail to reconcile: %v", err)
+		}
+		expected := []string{"pod4", "sts-0"}
+		if names := remaining(t, c); len(names) != len(expected) || names[0] != expected[0] || names[1] != expected[1] {
+			t.Errorf("expect remaining IPInstances %v but got %v", expected, names)
+		}
+	})
+
+	t.Run("deletion fails", func(t *testing.T) {
+		c := newClient()
+		r := &NodeDeletionReconciler{Client: deleteFailingClient{Client: c, err: errors.New("permanent error")}, Workers: 2}
+		if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "deleted"}}); err == nil {
+			t.Fatalf("expect errors of deletion to be returned")
+		}
+	})
+}
@@ -18,6 +18,7 @@ package networking
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -27,6 +28,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	kubevirtv1 "kubevirt.io/api/core/v1"
@@ -35,8 +37,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 	"github.com/alibaba/hybridnet/pkg/constants"
@@ -59,6 +65,8 @@ const (
 	ReasonIPAllocationFail    = "IPAllocationFail"
 	ReasonIPReleaseSucceed    = "IPReleaseSucceed"
 	ReasonIPReserveSucceed    = "IPReserveSucceed"
+	ReasonIPReclaimEviction   = "IPReclaimEviction"
+	ReasonNoAvailableSubnet   = "NoAvailableSubnet"
 )
 
 const (
@@ -75,10 +83,29 @@ type PodReconciler struct {
 
 	Recorder record.EventRecorder
 
+	// KubeClient is used to evict pods through the Eviction API, nil means
+	// eviction is never triggered
+	KubeClient kubernetes.Interface
+
 	PodIPCache  PodIPCache
 	IPAMStore   IPAMStore
 	IPAMManager IPAMManager
 
+	// ControllerInstance is the identity of manager instance, which is recorded
+	// on allocated IPInstances for audit
+	ControllerInstance string
+
+	// EventDebounce is the window of coalescing update events of a pod into one reconciliation,
+	// zero disables it
+	EventDebounce time.Duration
+
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
+	subnetExhaustionBackoff *subnetExhaustionBackoff
+	subnetExhaustionEvents  *subnetExhaustionEventAggregator
+
 	concurrency.ControllerConcurrency
 }
 
@@ -97,10 +124,25 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result
 	defer func() {
 		if err != nil {
 			log.Error(err, "reconciliation fails")
+			// requeue with backoff instead of retrying immediately if subnet is exhausted,
+			// pods will be requeued again once the network has available addresses, and the
+			// failures are aggregated into subnet events rather than flooding pod events
+			if errors.Is(err, ipamtypes.ErrSubnetExhausted) {
+				r.recordSubnetExhaustion(err, networkName, req.NamespacedName)
+				result, err = ctrl.Result{RequeueAfter: r.subnetExhaustionBackoff.Next(networkName, req.NamespacedName)}, nil
+				return
+			}
 			if len(pod.UID) > 0 {
 				r.Recorder.Event(pod, corev1.EventTypeWarning, ReasonIPAllocationFail, err.Error())
+				// no subnet of the selected network is available for pod, which is handled
+				// by the no-subnet policy of network
+				if errors.Is(err, ipamtypes.ErrNoAvailableSubnet) && len(networkName) > 0 {
+					result, err = r.handleNoAvailableSubnet(ctx, pod, networkName, err)
+				}
 			}
+			return
 		}
+		r.subnetExhaustionBackoff.Forget(req.NamespacedName)
 	}()
 
 	if err = r.Get(ctx, req.NamespacedName, pod); err != nil {
@@ -114,6 +156,11 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result
 	// For evicted and completed ip-retained pods, will be not reconciled while getting terminating, because
 	// finalizer is removed.
 	if pod.DeletionTimestamp != nil || utils.PodIsEvicted(pod) || utils.PodIsCompleted(pod) {
+		var owned bool
+		if owned, err = r.ownsPodIPs(ctx, pod); err != nil || !owned {
+			return ctrl.Result{}, err
+		}
+
 		var ownedObj client.Object = pod
 
 		// For terminating pods with no controller owner reference, try to get
@@ -247,6 +294,11 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result
 		return ctrl.Result{}, fmt.Errorf("unable to select network: %v", err)
 	}
 
+	if !r.Shard.Owns(networkName) {
+		log.V(1).Info("network of pod is not owned by shard, skip", "network", networkName)
+		return ctrl.Result{}, nil
+	}
+
 	if strategy.OwnByStatefulWorkload(pod) {
 		log.V(1).Info("strategic allocation for stateful pod")
 		return ctrl.Result{}, wrapError("unable to stateful allocate",
@@ -267,6 +319,27 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result
 		subnetStrFromWebhook, ipFamily, handledByWebhook))
 }
 
+// ownsPodIPs checks whether the IPs of pod are in the networks owned by shard, pods
+// without any IPInstance are handled by the primary shard
+func (r *PodReconciler) ownsPodIPs(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if !r.Shard.Enabled() {
+		return true, nil
+	}
+
+	var ipInstanceList = &networkingv1.IPInstanceList{}
+	if err := r.List(ctx, ipInstanceList,
+		client.MatchingLabels{constants.LabelPod: transform.TransferPodNameForLabelValue(pod.Name)},
+		client.InNamespace(pod.Namespace),
+	); err != nil {
+		return false, wrapError("failed to list ip instance for pod", err)
+	}
+
+	if len(ipInstanceList.Items) == 0 {
+		return r.Shard.IsPrimary(), nil
+	}
+	return r.Shard.Owns(ipInstanceList.Items[0].Spec.Network), nil
+}
+
 // decouple will unbind IP instance with Pod
 func (r *PodReconciler) decouple(ctx context.Context, pod *corev1.Pod) (err error) {
 	if err = r.IPAMStore.DeCouple(ctx, pod); err != nil {
@@ -568,6 +641,22 @@ func (r *PodReconciler) vmAllocate(ctx context.Context, pod *corev1.Pod, vmName,
 // assign means some allocated or pre-assigned IPs will be assigned to a specified pod
 func (r *PodReconciler) assign(ctx context.Context, pod *corev1.Pod, networkName string, ipCandidates []ipCandidate, force bool,
 	ipFamily types.IPFamilyMode, reCoupleOptions ...types.ReCoupleOption) (err error) {
+	var inheritedLabels map[string]string
+	if inheritedLabels, err = r.inheritedNamespaceLabels(ctx, pod, networkName); err != nil {
+		return err
+	}
+	reCoupleOptions = append([]types.ReCoupleOption{types.AdditionalLabels(inheritedLabels),
+		r.allocationContext(ctx, pod)}, reCoupleOptions...)
+
+	// pre-assigned IPs which are not held by namespace yet consume more quota
+	var newIPs int64
+	if newIPs, err = countNewIPsOfCandidates(ctx, r, pod.Namespace, ipCandidates); err != nil {
+		return fmt.Errorf("unable to count new IPs of candidates: %v", err)
+	}
+	if err = checkNetworkQuota(ctx, r, r.Recorder, pod, newIPs); err != nil {
+		return err
+	}
+
 	// try to assign candidate IPs to pod
 	var AssignedIPs []*types.IP
 	if AssignedIPs, err = r.IPAMManager.Assign(networkName,
@@ -586,7 +675,8 @@ func (r *PodReconciler) assign(ctx context.Context, pod *corev1.Pod, networkName
 
 	defer func() {
 		if err != nil {
-			_ = r.IPAMManager.Release(networkName, ipToReleaseSuite(AssignedIPs))
+			// rolling back must not be interrupted by the cancellation of reconcile context
+			_ = r.IPAMManager.Release(context.Background(), networkName, ipToReleaseSuite(AssignedIPs))
 		}
 	}()
 
@@ -594,6 +684,11 @@ func (r *PodReconciler) assign(ctx context.Context, pod *corev1.Pod, networkName
 		return fmt.Errorf("fail to force-couple IPs %+v with pod: %v", AssignedIPs, err)
 	}
 
+	// IPs have been assigned, failing here should not roll back them
+	if populateErr := r.populateDNSHostname(ctx, pod, networkName, AssignedIPs); populateErr != nil {
+		ctrllog.FromContext(ctx).Error(populateErr, "unable to populate dns hostname of ip instances")
+	}
+
 	// always keep updating pod ip cache the final step
 	r.PodIPCache.Record(pod.UID, pod.Name, pod.Namespace, ipToIPInstanceName(AssignedIPs))
 
@@ -627,8 +722,15 @@ func (r *PodReconciler) allocate(ctx context.Context, pod *corev1.Pod, networkNa
 		subnetNameStr        string
 		specifiedSubnetNames []string
 		allocatedIPs         []*types.IP
+		timeline             *utils.AllocationTimeline
 	)
 
+	// nil timeline records nothing
+	if feature.PodIPAllocationTimelineEnabled() {
+		timeline = &utils.AllocationTimeline{}
+		timeline.Record(utils.AllocationPhaseQueued, utils.PodQueuedTime(pod))
+	}
+
 	if !handledByWebhook {
 		subnetNameStr = subnetStrFromWebhook
 	} else {
@@ -640,33 +742,155 @@ func (r *PodReconciler) allocate(ctx context.Context, pod *corev1.Pod, networkNa
 		specifiedSubnetNames = strings.Split(subnetNameStr, "/")
 	}
 
-	if allocatedIPs, err = r.IPAMManager.Allocate(networkName, ipamtypes.PodInfo{
+	// pod will be pending with requeue until the usage of namespace falls below hard limits
+	if err = checkNetworkQuota(ctx, r, r.Recorder, pod, requiredIPsOfFamily(ipFamily)); err != nil {
+		return err
+	}
+
+	var inheritedLabels map[string]string
+	if inheritedLabels, err = r.inheritedNamespaceLabels(ctx, pod, networkName); err != nil {
+		return err
+	}
+	coupleOptions = append([]types.CoupleOption{types.AdditionalLabels(inheritedLabels),
+		r.allocationContext(ctx, pod)}, coupleOptions...)
+
+	if allocatedIPs, err = r.IPAMManager.Allocate(ctx, networkName, ipamtypes.PodInfo{
 		NamespacedName: apitypes.NamespacedName{
 			Namespace: pod.Namespace,
 			Name:      pod.Name,
 		},
 		IPFamily: ipFamily,
-	}, ipamtypes.AllocateSubnets(specifiedSubnetNames)); err != nil {
-		return fmt.Errorf("unable to allocate IP on family %s : %v", ipFamily, err)
+	}, ipamtypes.AllocateSubnets(specifiedSubnetNames), ipamtypes.LockAcquiredHook(func() {
+		timeline.RecordNow(utils.AllocationPhaseIPAMLockAcquired)
+	})); err != nil {
+		if errors.Is(err, ipamtypes.ErrSubnetExhausted) {
+			if _, evictErr := r.reclaimIPByEviction(ctx, pod, networkName, specifiedSubnetNames, ipFamily); evictErr != nil {
+				err = fmt.Errorf("%w, and fail to reclaim IP by eviction: %v", err, evictErr)
+			}
+		}
+		return fmt.Errorf("unable to allocate IP on family %s : %w", ipFamily, err)
 	}
+	timeline.RecordNow(utils.AllocationPhaseIPAMAllocated)
 
+	// IPs of all families are allocated atomically in IPAM manager, then IPInstances
+	// are created by store, if any of them fails to be created, store will roll back the
+	// partially-created IPInstances and all allocated IPs must be released here
 	defer func() {
 		if err != nil {
-			_ = r.IPAMManager.Release(networkName, ipToReleaseSuite(allocatedIPs))
+			// rolling back must not be interrupted by the cancellation of reconcile context
+			if releaseErr := r.IPAMManager.Release(context.Background(), networkName, ipToReleaseSuite(allocatedIPs)); releaseErr != nil {
+				err = fmt.Errorf("%w, and fail to release allocated IPs %v: %v", err, allocatedIPs, releaseErr)
+			}
 		}
 	}()
 
 	if err = r.IPAMStore.Couple(ctx, pod, allocatedIPs, coupleOptions...); err != nil {
 		return fmt.Errorf("unable to couple IPs %v with pod: %v", allocatedIPs, err)
 	}
+	timeline.RecordNow(utils.AllocationPhaseIPInstanceCreated)
 
 	// Always keep updating pod ip cache the final step.
 	r.PodIPCache.Record(pod.UID, pod.Name, pod.Namespace, ipToIPInstanceName(allocatedIPs))
 
 	r.Recorder.Eventf(pod, corev1.EventTypeNormal, ReasonIPAllocationSucceed, "allocate IPs %v successfully", ipToIPString(allocatedIPs))
+
+	// IPs have been allocated, failing here should not roll back them
+	if markErr := r.markNetworkReady(ctx, pod); markErr != nil {
+		ctrllog.FromContext(ctx).Error(markErr, "unable to mark pod as network-ready")
+	}
+	if populateErr := r.populateDNSHostname(ctx, pod, networkName, allocatedIPs); populateErr != nil {
+		ctrllog.FromContext(ctx).Error(populateErr, "unable to populate dns hostname of ip instances")
+	}
+	if timeline != nil {
+		if patchErr := r.patchAllocationTimeline(ctx, pod, timeline); patchErr != nil {
+			ctrllog.FromContext(ctx).Error(patchErr, "unable to patch allocation timeline of pod")
+		}
+	}
 	return nil
 }
 
+// allocationContext assembles the context of current reconciliation which allocates
+// or assigns IPs for pod
+func (r *PodReconciler) allocationContext(ctx context.Context, pod *corev1.Pod) types.AllocationContext {
+	return types.AllocationContext{
+		TriggeringNode:     pod.Spec.NodeName,
+		ControllerInstance: r.ControllerInstance,
+		ReconcileID:        reconcileIDFromContext(ctx),
+	}
+}
+
+// patchAllocationTimeline records the allocation timeline on pod annotation, the phase of
+// interface configuration will be appended by daemon
+func (r *PodReconciler) patchAllocationTimeline(ctx context.Context, pod *corev1.Pod, timeline *utils.AllocationTimeline) error {
+	return r.Patch(ctx, pod, client.RawPatch(apitypes.MergePatchType,
+		[]byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+			constants.AnnotationIPAllocationTimeline, timeline.String()))))
+}
+
+// populateDNSHostname records the hostname of pod on the status of its underlay IPInstances,
+// so that DNS records of them can be registered by DNS sync controller
+func (r *PodReconciler) populateDNSHostname(ctx context.Context, pod *corev1.Pod, networkName string, ips []*types.IP) error {
+	if !feature.UnderlayDNSRegistrationEnabled() {
+		return nil
+	}
+
+	network := &networkingv1.Network{}
+	if err := r.Get(ctx, apitypes.NamespacedName{Name: networkName}, network); err != nil {
+		return fmt.Errorf("unable to get network %s: %v", networkName, err)
+	}
+	if networkingv1.GetNetworkType(network) != networkingv1.NetworkTypeUnderlay {
+		return nil
+	}
+
+	patch := client.RawPatch(apitypes.MergePatchType,
+		[]byte(fmt.Sprintf(`{"status":{"dnsHostname":%q}}`, utils.PodDNSHostname(pod))))
+	for _, ipInstanceName := range ipToIPInstanceName(ips) {
+		ipInstance := &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ipInstanceName,
+				Namespace: pod.Namespace,
+			},
+		}
+		if err := r.Status().Patch(ctx, ipInstance, patch); err != nil {
+			return fmt.Errorf("unable to patch status of ip instance %s: %v", ipInstance.Name, err)
+		}
+	}
+	return nil
+}
+
+// recordSubnetExhaustion aggregates the allocation failure of pod into the event of exhausted subnet,
+// or the event of network if the exhausted subnet is unknown
+func (r *PodReconciler) recordSubnetExhaustion(err error, networkName string, pod apitypes.NamespacedName) {
+	var exhaustedErr *ipamtypes.SubnetExhaustedError
+	if errors.As(err, &exhaustedErr) {
+		r.subnetExhaustionEvents.RecordSubnet(exhaustedErr.Subnet, pod)
+		return
+	}
+	if len(networkName) > 0 {
+		r.subnetExhaustionEvents.RecordNetwork(networkName, pod)
+	}
+}
+
+// inheritedNamespaceLabels picks the namespace labels of pod which are listed in
+// .spec.inheritNamespaceLabels of network, they will be patched onto IPInstances
+func (r *PodReconciler) inheritedNamespaceLabels(ctx context.Context, pod *corev1.Pod, networkName string) (map[string]string, error) {
+	network, err := utils.GetNetwork(ctx, r, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get network %s: %v", networkName, err)
+	}
+
+	if len(network.Spec.InheritNamespaceLabels) == 0 {
+		return nil, nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err = r.Get(ctx, apitypes.NamespacedName{Name: pod.Namespace}, namespace); err != nil {
+		return nil, fmt.Errorf("unable to get namespace %s: %v", pod.Namespace, err)
+	}
+
+	return pickInheritedLabels(namespace.Labels, network.Spec.InheritNamespaceLabels), nil
+}
+
 func (r *PodReconciler) addFinalizer(ctx context.Context, pod *corev1.Pod) error {
 	if controllerutil.ContainsFinalizer(pod, constants.FinalizerIPAllocated) {
 		return nil
@@ -765,38 +989,144 @@ func parseSpecifiedMACAddressOption(pod *corev1.Pod) (mac ipamtypes.SpecifiedMAC
 	return ipamtypes.SpecifiedMACAddress(normalizedMAC), nil
 }
 
+// shouldAllocateForPod filters pods which need to be processed by pod controller.
+//
+// Init containers always share the network namespace of pod sandbox with regular
+// containers, so IPs are allocated per pod rather than per container. Pods only
+// consisting of init containers have no regular containers to bind IPs, even if
+// they carry the same networking annotations as normal pods, e.g., those injected
+// by webhooks, so they will be skipped without any IPInstance check.
+func shouldAllocateForPod(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	// ignore host networking pod
+	if pod.Spec.HostNetwork {
+		return false
+	}
+
+	if pod.DeletionTimestamp.IsZero() {
+		// ignore init-container-only pod
+		if utils.PodHasOnlyInitContainers(pod) {
+			return false
+		}
+
+		// only pod after scheduling should be processed
+		return utils.PodIsScheduled(pod)
+	}
+
+	// Terminating pods ip-allocated finalizer should be processed specially.
+	// Pods without ip-allocated finalizer will be considered as having no retained ip.
+	return controllerutil.ContainsFinalizer(pod, constants.FinalizerIPAllocated)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) (err error) {
-	return ctrl.NewControllerManagedBy(mgr).
-		Named(ControllerPod).
-		For(&corev1.Pod{},
-			builder.WithPredicates(
-				&utils.IgnoreDeletePredicate{},
-				&predicate.ResourceVersionChangedPredicate{},
-				predicate.NewPredicateFuncs(func(obj client.Object) bool {
-					pod, ok := obj.(*corev1.Pod)
-					if !ok {
+	if r.subnetExhaustionBackoff == nil {
+		r.subnetExhaustionBackoff = newSubnetExhaustionBackoff()
+	}
+	if r.subnetExhaustionEvents == nil {
+		r.subnetExhaustionEvents = newSubnetExhaustionEventAggregator(r.Recorder)
+	}
+
+	podPredicates := []predicate.Predicate{
+		&utils.IgnoreDeletePredicate{},
+		&predicate.ResourceVersionChangedPredicate{},
+		predicate.NewPredicateFuncs(shouldAllocateForPod),
+	}
+
+	blder := ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerPod)
+
+	if r.EventDebounce > 0 {
+		// update events of pods are handled by debouncer rather than enqueued directly
+		blder = blder.
+			For(&corev1.Pod{}, builder.WithPredicates(append(podPredicates, predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool {
+					return false
+				},
+			})...)).
+			Watches(&source.Kind{Type: &corev1.Pod{}}, newPodEventDebouncer(r.EventDebounce),
+				builder.WithPredicates(append(podPredicates, predicate.Funcs{
+					CreateFunc: func(event.CreateEvent) bool {
 						return false
-					}
-					// ignore host networking pod
-					if pod.Spec.HostNetwork {
+					},
+					GenericFunc: func(event.GenericEvent) bool {
 						return false
-					}
-
-					if pod.DeletionTimestamp.IsZero() {
-						// only pod after scheduling should be processed
-						return utils.PodIsScheduled(pod)
-					}
+					},
+				})...))
+	} else {
+		blder = blder.For(&corev1.Pod{}, builder.WithPredicates(podPredicates...))
+	}
 
-					// Terminating pods ip-allocated finalizer should be processed specially.
-					// Pods without ip-allocated finalizer will be considered as having no retained ip.
-					return controllerutil.ContainsFinalizer(pod, constants.FinalizerIPAllocated)
-				}),
+	return blder.
+		Watches(&source.Kind{Type: &networkingv1.Subnet{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				subnet, ok := object.(*networkingv1.Subnet)
+				if !ok {
+					return nil
+				}
+				var requests []reconcile.Request
+				for _, pod := range r.subnetExhaustionBackoff.Reset(subnet.Spec.Network) {
+					requests = append(requests, reconcile.Request{NamespacedName: pod})
+				}
+				return requests
+			}),
+			builder.WithPredicates(
+				predicate.Funcs{
+					CreateFunc: func(event.CreateEvent) bool {
+						return false
+					},
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						oldSubnet, ok := e.ObjectOld.(*networkingv1.Subnet)
+						if !ok {
+							return false
+						}
+						newSubnet, ok := e.ObjectNew.(*networkingv1.Subnet)
+						if !ok {
+							return false
+						}
+						// only care about subnets which have available addresses again
+						return oldSubnet.Status.Available == 0 && newSubnet.Status.Available > 0
+					},
+					DeleteFunc: func(event.DeleteEvent) bool {
+						return false
+					},
+					GenericFunc: func(event.GenericEvent) bool {
+						return false
+					},
+				},
 			),
 		).
+		// pods rejected before may be eligible for allocation after subnet spec changes
+		Watches(&source.Kind{Type: &networkingv1.Subnet{}},
+			handler.EnqueueRequestsFromMapFunc(r.pendingPodsOfSubnet),
+			builder.WithPredicates(subnetAddressRangeChangePredicate),
+		).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.Max(),
 			RecoverPanic:            true,
 		}).
+		// allocated IPInstances record the reconcile ID which controller-runtime attaches to logs
+		WithLogConstructor(podLogConstructor(mgr.GetLogger())).
 		Complete(r)
 }
+
+// pickInheritedLabels copies the listed keys from namespace labels, built-in labels
+// will never be inherited for they are managed by hybridnet itself
+func pickInheritedLabels(namespaceLabels map[string]string, keys []string) map[string]string {
+	var picked map[string]string
+	for _, key := range keys {
+		if strings.HasPrefix(key, constants.LabelDomainPrefix) {
+			continue
+		}
+		if value, exist := namespaceLabels[key]; exist {
+			if picked == nil {
+				picked = map[string]string{}
+			}
+			picked[key] = value
+		}
+	}
+	return picked
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -80,6 +81,22 @@ var _ = Describe("Pod controller integration test suite", func() {
 
 					g.Expect(ipInstance.Spec.Network).To(Equal(underlayNetworkName))
 					g.Expect(ipInstance.Spec.Subnet).To(BeElementOf(underlaySubnetName))
+
+					g.Expect(ipInstance.Spec.AllocationContext).NotTo(BeNil())
+					g.Expect(ipInstance.Spec.AllocationContext.TriggeringNode).To(Equal(node1Name))
+					g.Expect(ipInstance.Spec.AllocationContext.ControllerInstance).To(Equal("manager-0"))
+					g.Expect(ipInstance.Spec.AllocationContext.ReconcileID).NotTo(BeEmpty())
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("check last allocation time of subnet")
+			Eventually(
+				func(g Gomega) {
+					subnet := &networkingv1.Subnet{}
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: underlaySubnetName}, subnet)).NotTo(HaveOccurred())
+					g.Expect(subnet.Status.LastAllocationTime.IsZero()).To(BeFalse())
 				}).
 				WithTimeout(30 * time.Second).
 				WithPolling(time.Second).
@@ -2274,6 +2291,170 @@ var _ = Describe("Pod controller integration test suite", func() {
 		})
 	})
 
+	Context("Concurrent DualStack allocation under subnet exhaustion", func() {
+		const concurrentPodCount = 50
+		var podNames []string
+		var ipv4SubnetName = fmt.Sprintf("subnet-test-%s", uuid.NewUUID())
+		var ipv6SubnetName = fmt.Sprintf("subnet-test-%s", uuid.NewUUID())
+
+		BeforeEach(func() {
+			podNames = make([]string, 0, concurrentPodCount)
+			for i := 0; i < concurrentPodCount; i++ {
+				podNames = append(podNames, fmt.Sprintf("pod-%d-%s", i, uuid.NewUUID()))
+			}
+		})
+
+		It("Allocate DualStack addresses for concurrent pods without duplication", func() {
+			By("create small test subnets which can not satisfy all the pods")
+			Expect(k8sClient.Create(context.Background(),
+				subnetRender(ipv4SubnetName, overlayNetworkName, "100.20.0.0/27", nil, false))).
+				NotTo(HaveOccurred())
+			Expect(k8sClient.Create(context.Background(),
+				subnetRender(ipv6SubnetName, overlayNetworkName, "fd00:20::/123", nil, false))).
+				NotTo(HaveOccurred())
+
+			By("get the capacity of test subnets")
+			var capacity int32
+			Eventually(
+				func(g Gomega) {
+					ipv4Subnet := &networkingv1.Subnet{}
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: ipv4SubnetName}, ipv4Subnet)).NotTo(HaveOccurred())
+					g.Expect(ipv4Subnet.Status.Total).NotTo(BeZero())
+
+					ipv6Subnet := &networkingv1.Subnet{}
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: ipv6SubnetName}, ipv6Subnet)).NotTo(HaveOccurred())
+					g.Expect(ipv6Subnet.Status.Total).NotTo(BeZero())
+
+					capacity = ipv4Subnet.Status.Available
+					if ipv6Subnet.Status.Available < capacity {
+						capacity = ipv6Subnet.Status.Available
+					}
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+			Expect(capacity).To(BeNumerically("<", concurrentPodCount))
+
+			By("create DualStack pods simultaneously")
+			var wg sync.WaitGroup
+			for _, podName := range podNames {
+				wg.Add(1)
+				go func(podName string) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					pod := simplePodRender(podName, node3Name)
+					pod.Annotations = map[string]string{
+						constants.AnnotationNetworkType:     "Overlay",
+						constants.AnnotationIPFamily:        "DualStack",
+						constants.AnnotationSpecifiedSubnet: ipv4SubnetName + "/" + ipv6SubnetName,
+					}
+					Expect(k8sClient.Create(context.Background(), pod)).Should(Succeed())
+				}(podName)
+			}
+			wg.Wait()
+
+			By("check DualStack addresses allocation of all pods")
+			Eventually(
+				func(g Gomega) {
+					allocatedIPs := map[string]string{}
+					allocatedPodCount := 0
+
+					for _, podName := range podNames {
+						pod := &corev1.Pod{}
+						g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{
+							Namespace: "default",
+							Name:      podName,
+						}, pod)).NotTo(HaveOccurred())
+
+						ipInstances, err := utils.ListAllocatedIPInstancesOfPod(context.Background(), k8sClient, pod)
+						g.Expect(err).NotTo(HaveOccurred())
+						if len(ipInstances) == 0 {
+							continue
+						}
+
+						g.Expect(ipInstances).To(HaveLen(2))
+						allocatedPodCount++
+
+						networkingv1.SortIPInstancePointerSlice(ipInstances)
+						g.Expect(ipInstances[0].Spec.Address.Version).To(Equal(networkingv1.IPv4))
+						g.Expect(ipInstances[0].Spec.Subnet).To(Equal(ipv4SubnetName))
+						g.Expect(ipInstances[1].Spec.Address.Version).To(Equal(networkingv1.IPv6))
+						g.Expect(ipInstances[1].Spec.Subnet).To(Equal(ipv6SubnetName))
+						g.Expect(ipInstances[0].Spec.Address.MAC).To(Equal(ipInstances[1].Spec.Address.MAC))
+
+						for _, ipInstance := range ipInstances {
+							g.Expect(ipInstance.Spec.Binding.PodName).To(Equal(pod.Name))
+							g.Expect(allocatedIPs).NotTo(HaveKey(ipInstance.Spec.Address.IP))
+							allocatedIPs[ipInstance.Spec.Address.IP] = pod.Name
+						}
+					}
+
+					g.Expect(allocatedPodCount).To(Equal(int(capacity)))
+				}).
+				WithTimeout(2 * time.Minute).
+				WithPolling(2 * time.Second).
+				Should(Succeed())
+		})
+
+		AfterEach(func() {
+			By("remove the test pods")
+			for _, podName := range podNames {
+				Expect(client.IgnoreNotFound(
+					k8sClient.Delete(context.Background(),
+						&corev1.Pod{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: "default",
+								Name:      podName,
+							},
+						},
+						client.GracePeriodSeconds(0)))).NotTo(HaveOccurred())
+			}
+
+			By("clean up test ip instances")
+			for _, podName := range podNames {
+				Expect(k8sClient.DeleteAllOf(
+					context.Background(),
+					&networkingv1.IPInstance{},
+					client.MatchingLabels{
+						constants.LabelPod: transform.TransferPodNameForLabelValue(podName),
+					},
+					client.InNamespace("default"),
+				)).NotTo(HaveOccurred())
+			}
+
+			By("make sure test pods cleaned up")
+			Eventually(
+				func(g Gomega) {
+					for _, podName := range podNames {
+						err := k8sClient.Get(context.Background(),
+							types.NamespacedName{
+								Namespace: "default",
+								Name:      podName,
+							},
+							&corev1.Pod{})
+						g.Expect(err).NotTo(BeNil())
+						g.Expect(errors.IsNotFound(err)).To(BeTrue())
+					}
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("remove test subnets")
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), &networkingv1.Subnet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ipv4SubnetName,
+				},
+			}))).NotTo(HaveOccurred())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), &networkingv1.Subnet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: ipv6SubnetName,
+				},
+			}))).NotTo(HaveOccurred())
+		})
+	})
+
 	Context("Unlock", func() {
 		testLock.Unlock()
 	})
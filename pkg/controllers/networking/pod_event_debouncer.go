@@ -0,0 +1,100 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+// podEventDebouncer coalesces the update events of a pod in a debounce window into one reconciliation,
+// the window starts from the first event, so a pod updated continuously is still reconciled once per
+// window. Events which may trigger the first allocation of pods, i.e., creation and scheduling, are
+// enqueued immediately.
+type podEventDebouncer struct {
+	window time.Duration
+
+	mu sync.Mutex
+	// pending records the pods whose debounce timers are running
+	pending map[apitypes.NamespacedName]struct{}
+}
+
+func newPodEventDebouncer(window time.Duration) *podEventDebouncer {
+	return &podEventDebouncer{
+		window:  window,
+		pending: make(map[apitypes.NamespacedName]struct{}),
+	}
+}
+
+func (d *podEventDebouncer) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	enqueueObject(e.Object, q)
+}
+
+func (d *podEventDebouncer) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if e.ObjectNew == nil {
+		return
+	}
+
+	if oldPod, ok := e.ObjectOld.(*corev1.Pod); ok && !utils.PodIsScheduled(oldPod) {
+		enqueueObject(e.ObjectNew, q)
+		return
+	}
+
+	key := client.ObjectKeyFromObject(e.ObjectNew)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exist := d.pending[key]; exist {
+		metrics.PodEventsCoalescedCounter.Inc()
+		return
+	}
+	d.pending[key] = struct{}{}
+
+	time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		delete(d.pending, key)
+		d.mu.Unlock()
+
+		q.Add(reconcile.Request{NamespacedName: key})
+	})
+}
+
+func (d *podEventDebouncer) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	enqueueObject(e.Object, q)
+}
+
+func (d *podEventDebouncer) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	enqueueObject(e.Object, q)
+}
+
+func enqueueObject(obj client.Object, q workqueue.RateLimitingInterface) {
+	if obj == nil {
+		return
+	}
+	q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+}
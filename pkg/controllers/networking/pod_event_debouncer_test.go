@@ -0,0 +1,70 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestPodEventDebouncer(t *testing.T) {
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	d := newPodEventDebouncer(100 * time.Millisecond)
+
+	unscheduled := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"}}
+	scheduled := unscheduled.DeepCopy()
+	scheduled.Spec.NodeName = "node1"
+
+	d.Create(event.CreateEvent{Object: unscheduled}, q)
+	if q.Len() != 1 {
+		t.Fatalf("expected creation enqueued immediately")
+	}
+	item, _ := q.Get()
+	q.Done(item)
+
+	d.Update(event.UpdateEvent{ObjectOld: unscheduled, ObjectNew: scheduled}, q)
+	if q.Len() != 1 {
+		t.Fatalf("expected scheduling enqueued immediately")
+	}
+	item, _ = q.Get()
+	q.Done(item)
+
+	for i := 0; i < 3; i++ {
+		d.Update(event.UpdateEvent{ObjectOld: scheduled, ObjectNew: scheduled}, q)
+	}
+	if q.Len() != 0 {
+		t.Fatalf("expected updates delayed in debounce window")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if q.Len() != 1 {
+		t.Fatalf("expected updates coalesced into one request but got %d", q.Len())
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.pending) != 0 {
+		t.Fatalf("expected no pending pods after window")
+	}
+}
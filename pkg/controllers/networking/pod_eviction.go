@@ -0,0 +1,178 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	globalutils "github.com/alibaba/hybridnet/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+
+// reclaimIPByEviction evicts the lowest-priority running pod of exhausted subnets which
+// enable eviction policy, the pending pod will be requeued to retry allocation once the
+// evicted pod releases its addresses. It returns whether an eviction is triggered.
+func (r *PodReconciler) reclaimIPByEviction(ctx context.Context, pod *corev1.Pod, networkName string,
+	specifiedSubnetNames []string, ipFamily ipamtypes.IPFamilyMode) (bool, error) {
+	if r.KubeClient == nil {
+		return false, nil
+	}
+
+	subnetList := &networkingv1.SubnetList{}
+	if err := r.List(ctx, subnetList); err != nil {
+		return false, fmt.Errorf("unable to list subnets: %v", err)
+	}
+
+	var victim *corev1.Pod
+	var victimSubnet string
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		if !subnetEvictionCandidate(subnet, networkName, specifiedSubnetNames, ipFamily) {
+			continue
+		}
+
+		candidate, inProgress, err := r.pickEvictionVictim(ctx, subnet.Name, podPriority(pod))
+		if err != nil {
+			return false, err
+		}
+		// some pod of this subnet is terminating and its addresses will be released soon,
+		// do not evict more pods
+		if inProgress {
+			return false, nil
+		}
+		if candidate != nil && (victim == nil || podPriority(candidate) < podPriority(victim)) {
+			victim, victimSubnet = candidate, subnet.Name
+		}
+	}
+
+	if victim == nil {
+		return false, nil
+	}
+
+	if err := r.KubeClient.PolicyV1().Evictions(victim.Namespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      victim.Name,
+			Namespace: victim.Namespace,
+		},
+	}); err != nil {
+		return false, fmt.Errorf("unable to evict pod %s/%s: %v", victim.Namespace, victim.Name, err)
+	}
+
+	r.Recorder.Eventf(victim, corev1.EventTypeWarning, ReasonIPReclaimEviction,
+		"evicted to reclaim IP of exhausted subnet %s for pod %s/%s", victimSubnet, pod.Namespace, pod.Name)
+	r.Recorder.Eventf(pod, corev1.EventTypeNormal, ReasonIPReclaimEviction,
+		"evict pod %s/%s to reclaim IP of exhausted subnet %s", victim.Namespace, victim.Name, victimSubnet)
+	return true, nil
+}
+
+// pickEvictionVictim returns the lowest-priority running pod using addresses of subnet whose
+// priority is lower than the specified one and whose addresses are not retained, and whether some pod of subnet is already terminating.
+func (r *PodReconciler) pickEvictionVictim(ctx context.Context, subnetName string, priority int32) (*corev1.Pod, bool, error) {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := r.List(ctx, ipInstanceList, client.MatchingLabels{constants.LabelSubnet: subnetName}); err != nil {
+		return nil, false, fmt.Errorf("unable to list ip instances of subnet %s: %v", subnetName, err)
+	}
+
+	var victim *corev1.Pod
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if !ipInstance.DeletionTimestamp.IsZero() || len(ipInstance.Spec.Binding.PodName) == 0 {
+			continue
+		}
+
+		candidate := &corev1.Pod{}
+		if err := r.Get(ctx, apitypes.NamespacedName{
+			Namespace: ipInstance.Namespace,
+			Name:      ipInstance.Spec.Binding.PodName,
+		}, candidate); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, false, fmt.Errorf("unable to get pod %s/%s: %v", ipInstance.Namespace, ipInstance.Spec.Binding.PodName, err)
+		}
+
+		if candidate.UID != ipInstance.Spec.Binding.PodUID {
+			continue
+		}
+		// evicting pods with retained addresses reclaims nothing, their addresses are kept for them
+		if retainsIP(ipInstance, candidate) {
+			continue
+		}
+		if candidate.DeletionTimestamp != nil {
+			return nil, true, nil
+		}
+		if candidate.Status.Phase != corev1.PodRunning || podPriority(candidate) >= priority {
+			continue
+		}
+		if victim == nil || podPriority(candidate) < podPriority(victim) {
+			victim = candidate
+		}
+	}
+
+	return victim, false, nil
+}
+
+// retainsIP checks whether the addresses of pod are kept after it is deleted, which is true
+// for stateful workloads with retained IPs and pods with static IPs of ip-pool.
+func retainsIP(ipInstance *networkingv1.IPInstance, pod *corev1.Pod) bool {
+	return ipInstance.Spec.Binding.Stateful != nil || len(pod.Annotations[constants.AnnotationIPPool]) > 0
+}
+
+// subnetEvictionCandidate checks whether the subnet is exhausted and able to reclaim
+// addresses for pod by eviction.
+func subnetEvictionCandidate(subnet *networkingv1.Subnet, networkName string, specifiedSubnetNames []string,
+	ipFamily ipamtypes.IPFamilyMode) bool {
+	if subnet.Spec.EvictionPolicy == nil || !subnet.Spec.EvictionPolicy.Enabled {
+		return false
+	}
+	if subnet.Spec.Network != networkName || subnet.Status.Available > 0 {
+		return false
+	}
+	if len(specifiedSubnetNames) > 0 {
+		if _, specified := globalutils.StringSliceToMap(specifiedSubnetNames)[subnet.Name]; !specified {
+			return false
+		}
+	}
+
+	switch ipFamily {
+	case ipamtypes.IPv4:
+		return subnet.Spec.Range.Version == networkingv1.IPv4
+	case ipamtypes.IPv6:
+		return subnet.Spec.Range.Version == networkingv1.IPv6
+	default:
+		return true
+	}
+}
+
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
@@ -0,0 +1,146 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestPickEvictionVictim(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	priority := func(p int32) *int32 { return &p }
+	runningPod := func(name string, p int32, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				UID:         types.UID("uid-" + name),
+				Annotations: annotations,
+			},
+			Spec:   corev1.PodSpec{Priority: priority(p)},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	ipInstance := func(name, podName string, stateful *networkingv1.StatefulInfo) *networkingv1.IPInstance {
+		return &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{constants.LabelSubnet: "subnet1"},
+			},
+			Spec: networkingv1.IPInstanceSpec{
+				Network: "network1",
+				Subnet:  "subnet1",
+				Binding: networkingv1.Binding{
+					PodName:  podName,
+					PodUID:   types.UID("uid-" + podName),
+					Stateful: stateful,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		objs   []client.Object
+		victim string
+	}{
+		{
+			name: "lowest priority pod is picked",
+			objs: []client.Object{
+				runningPod("low", -10, nil),
+				runningPod("lower", -20, nil),
+				runningPod("high", 100, nil),
+				ipInstance("10-0-0-1", "low", nil),
+				ipInstance("10-0-0-2", "lower", nil),
+				ipInstance("10-0-0-3", "high", nil),
+			},
+			victim: "lower",
+		},
+		{
+			name: "stateful pod with retained ip is skipped",
+			objs: []client.Object{
+				runningPod("low", -10, nil),
+				runningPod("sts-0", -20, nil),
+				ipInstance("10-0-0-1", "low", nil),
+				ipInstance("10-0-0-2", "sts-0", &networkingv1.StatefulInfo{}),
+			},
+			victim: "low",
+		},
+		{
+			name: "pod with static ip is skipped",
+			objs: []client.Object{
+				runningPod("low", -10, nil),
+				runningPod("static", -20, map[string]string{constants.AnnotationIPPool: "10.0.0.2"}),
+				ipInstance("10-0-0-1", "low", nil),
+				ipInstance("10-0-0-2", "static", nil),
+			},
+			victim: "low",
+		},
+		{
+			name: "no victim if all pods retain ips",
+			objs: []client.Object{
+				runningPod("sts-0", -20, nil),
+				runningPod("static", -20, map[string]string{constants.AnnotationIPPool: "10.0.0.2"}),
+				ipInstance("10-0-0-1", "sts-0", &networkingv1.StatefulInfo{}),
+				ipInstance("10-0-0-2", "static", nil),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &PodReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(test.objs...).Build(),
+			}
+
+			victim, inProgress, err := r.pickEvictionVictim(context.Background(), "subnet1", 0)
+			if err != nil {
+				t.Fatalf("fail to pick eviction victim: %v", err)
+			}
+			if inProgress {
+				t.Errorf("expect no eviction in progress")
+			}
+
+			var victimName string
+			if victim != nil {
+				victimName = victim.Name
+			}
+			if victimName != test.victim {
+				t.Errorf("expect victim %q but got %q", test.victim, victimName)
+			}
+		})
+	}
+}
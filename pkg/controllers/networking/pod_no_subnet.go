@@ -0,0 +1,170 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+// PodConditionNetworkReady shows whether pod networking is ready, it is only set to False
+// by controller if no subnet is available for pod under Fail or Webhook no-subnet policy
+const PodConditionNetworkReady corev1.PodConditionType = "NetworkReady"
+
+const noSubnetWebhookTimeout = 10 * time.Second
+
+var noSubnetWebhookClient = &http.Client{Timeout: noSubnetWebhookTimeout}
+
+// noSubnetNotification is the request body posted to the no-subnet webhook of network
+type noSubnetNotification struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Node      string `json:"node"`
+	Network   string `json:"network"`
+	Message   string `json:"message"`
+}
+
+// handleNoAvailableSubnet handles the pod which has no available subnet in network following
+// the no-subnet policy of network, the returned error is nil if pod should not be requeued
+func (r *PodReconciler) handleNoAvailableSubnet(ctx context.Context, pod *corev1.Pod, networkName string,
+	allocateErr error) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	var network = &networkingv1.Network{}
+	if err := r.Get(ctx, apitypes.NamespacedName{Name: networkName}, network); err != nil {
+		return ctrl.Result{}, fmt.Errorf("%w, and fail to get network %s for no-subnet policy: %v", allocateErr, networkName, err)
+	}
+
+	switch networkingv1.GetNoSubnetPolicy(network) {
+	case networkingv1.NoSubnetPolicyFail:
+		if err := r.markNetworkNotReady(ctx, pod, allocateErr.Error()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("%w, and fail to mark pod as not network-ready: %v", allocateErr, err)
+		}
+		log.Info("pod is marked as not network-ready because no subnet is available", "network", networkName)
+		return ctrl.Result{}, nil
+	case networkingv1.NoSubnetPolicyWebhook:
+		// pods already marked have been notified, the webhook will not be called again
+		// until the pod gets network-ready
+		if isNetworkNotReady(pod) {
+			return ctrl.Result{}, allocateErr
+		}
+		if err := notifyNoSubnetWebhook(ctx, network.Spec.NoSubnetWebhookURL, &noSubnetNotification{
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
+			Node:      pod.Spec.NodeName,
+			Network:   networkName,
+			Message:   allocateErr.Error(),
+		}); err != nil {
+			return ctrl.Result{}, fmt.Errorf("%w, and fail to notify no-subnet webhook: %v", allocateErr, err)
+		}
+		if err := r.markNetworkNotReady(ctx, pod, allocateErr.Error()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("%w, and fail to mark pod as not network-ready: %v", allocateErr, err)
+		}
+		return ctrl.Result{}, allocateErr
+	default:
+		return ctrl.Result{}, allocateErr
+	}
+}
+
+// markNetworkNotReady sets the NetworkReady condition of pod to False through pod status
+func (r *PodReconciler) markNetworkNotReady(ctx context.Context, pod *corev1.Pod, message string) error {
+	if isNetworkNotReady(pod) {
+		return nil
+	}
+
+	patch := client.StrategicMergeFrom(pod.DeepCopy())
+	setPodCondition(pod, corev1.PodCondition{
+		Type:               PodConditionNetworkReady,
+		Status:             corev1.ConditionFalse,
+		Reason:             ReasonNoAvailableSubnet,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+	return r.Status().Patch(ctx, pod, patch)
+}
+
+// markNetworkReady sets the NetworkReady condition of pod to True if it has been marked as
+// not network-ready before
+func (r *PodReconciler) markNetworkReady(ctx context.Context, pod *corev1.Pod) error {
+	if !isNetworkNotReady(pod) {
+		return nil
+	}
+
+	patch := client.StrategicMergeFrom(pod.DeepCopy())
+	setPodCondition(pod, corev1.PodCondition{
+		Type:               PodConditionNetworkReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+	})
+	return r.Status().Patch(ctx, pod, patch)
+}
+
+func isNetworkNotReady(pod *corev1.Pod) bool {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == PodConditionNetworkReady {
+			return pod.Status.Conditions[i].Status == corev1.ConditionFalse
+		}
+	}
+	return false
+}
+
+func setPodCondition(pod *corev1.Pod, condition corev1.PodCondition) {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == condition.Type {
+			pod.Status.Conditions[i] = condition
+			return
+		}
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+}
+
+func notifyNoSubnetWebhook(ctx context.Context, webhookURL string, notification *noSubnetNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("unable to marshal notification: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := noSubnetWebhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to post %s: %v", webhookURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, webhookURL)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/networking"
+)
+
+var _ = Describe("Pod no-subnet policy integration test suite", func() {
+	Context("Lock", func() {
+		testLock.Lock()
+	})
+
+	Context("Fail policy of network without subnets", func() {
+		var (
+			networkName = fmt.Sprintf("no-subnet-network-%s", uuid.NewUUID())
+			nodeName    = fmt.Sprintf("no-subnet-node-%s", uuid.NewUUID())
+			podName     = fmt.Sprintf("no-subnet-pod-%s", uuid.NewUUID())
+		)
+
+		It("Pod should be marked as not network-ready", func() {
+			By("create underlay network of Fail policy and its node")
+			network := underlayNetworkRender(networkName, 400)
+			network.Spec.NoSubnetPolicy = networkingv1.NoSubnetPolicyFail
+			Expect(k8sClient.Create(context.Background(), network)).NotTo(HaveOccurred())
+			Expect(k8sClient.Create(context.Background(), nodeRender(nodeName, map[string]string{
+				"network": networkName,
+			}))).NotTo(HaveOccurred())
+
+			By("create pod with specified network in annotation")
+			pod := simplePodRender(podName, nodeName)
+			pod.Annotations = map[string]string{
+				constants.AnnotationSpecifiedNetwork: networkName,
+			}
+			Expect(k8sClient.Create(context.Background(), pod)).NotTo(HaveOccurred())
+
+			By("check NetworkReady condition of pod")
+			Eventually(
+				func(g Gomega) {
+					pod := &corev1.Pod{}
+					g.Expect(k8sClient.Get(context.Background(), types.NamespacedName{
+						Namespace: "default",
+						Name:      podName,
+					}, pod)).NotTo(HaveOccurred())
+
+					var condition *corev1.PodCondition
+					for i := range pod.Status.Conditions {
+						if pod.Status.Conditions[i].Type == networking.PodConditionNetworkReady {
+							condition = &pod.Status.Conditions[i]
+						}
+					}
+					g.Expect(condition).NotTo(BeNil())
+					g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+					g.Expect(condition.Reason).To(Equal(networking.ReasonNoAvailableSubnet))
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+		})
+
+		AfterEach(func() {
+			By("remove test pod, node and network")
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), simplePodRender(podName, nodeName),
+				client.GracePeriodSeconds(0)))).NotTo(HaveOccurred())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(),
+				nodeRender(nodeName, nil)))).NotTo(HaveOccurred())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(),
+				underlayNetworkRender(networkName, 400)))).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Unlock", func() {
+		testLock.Unlock()
+	})
+})
@@ -0,0 +1,130 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+const (
+	// IndexerFieldPendingNode indexes pods waiting for IP allocation by their nodes
+	IndexerFieldPendingNode = "pendingNode"
+	// AnyPendingNode is the index value shared by all pods waiting for IP allocation
+	AnyPendingNode = "*"
+)
+
+// pendingNodeIndexFunc indexes pending pods without IPs by their nodes, pods of all nodes
+// can also be listed by AnyPendingNode
+func pendingNodeIndexFunc(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return nil
+	}
+
+	if pod.Spec.HostNetwork || !pod.DeletionTimestamp.IsZero() || !utils.PodIsScheduled(pod) ||
+		pod.Status.Phase != corev1.PodPending || len(pod.Status.PodIP) > 0 {
+		return nil
+	}
+	return []string{pod.Spec.NodeName, AnyPendingNode}
+}
+
+// subnetAddressRangeChangePredicate selects the updates of subnets whose available addresses
+// may be changed by spec, e.g., reserved IPs are released
+var subnetAddressRangeChangePredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool {
+		return false
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldSubnet, ok := e.ObjectOld.(*networkingv1.Subnet)
+		if !ok {
+			return false
+		}
+		newSubnet, ok := e.ObjectNew.(*networkingv1.Subnet)
+		if !ok {
+			return false
+		}
+		return !reflect.DeepEqual(oldSubnet.Spec.Range.ReservedIPs, newSubnet.Spec.Range.ReservedIPs) ||
+			!reflect.DeepEqual(oldSubnet.Spec.Range.ExcludeIPs, newSubnet.Spec.Range.ExcludeIPs)
+	},
+	DeleteFunc: func(event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(event.GenericEvent) bool {
+		return false
+	},
+}
+
+// pendingPodsOfSubnet finds the pods waiting for IP allocation on the nodes covered by the
+// network of subnet, which may be eligible for allocation after the subnet is updated
+func (r *PodReconciler) pendingPodsOfSubnet(object client.Object) []reconcile.Request {
+	subnet, ok := object.(*networkingv1.Subnet)
+	if !ok {
+		return nil
+	}
+
+	ctx := context.TODO()
+	logger := ctrllog.FromContext(ctx).WithValues("subnet", subnet.Name)
+
+	network, err := utils.GetNetwork(ctx, r, subnet.Spec.Network)
+	if err != nil {
+		logger.Error(err, "unable to get network of updated subnet")
+		return nil
+	}
+
+	var nodeNames []string
+	switch networkingv1.GetNetworkType(network) {
+	case networkingv1.NetworkTypeUnderlay:
+		nodeNames = network.Status.NodeList
+	default:
+		// overlay and global bgp networks cover all nodes
+		nodeNames = []string{AnyPendingNode}
+	}
+
+	var requests []reconcile.Request
+	for _, nodeName := range nodeNames {
+		podList := &corev1.PodList{}
+		if err = r.List(ctx, podList, client.MatchingFields{IndexerFieldPendingNode: nodeName}); err != nil {
+			logger.Error(err, "unable to list pending pods", "node", nodeName)
+			continue
+		}
+
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			// pods with IPs allocated are just waiting for sandbox creation
+			if exist, _, _ := r.PodIPCache.Get(pod.Name, pod.Namespace); exist {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: apitypes.NamespacedName{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			}})
+		}
+	}
+	return requests
+}
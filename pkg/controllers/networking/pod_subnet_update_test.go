@@ -0,0 +1,206 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+// pendingNodeIndexingClient selects pods by the pending node index, which is ignored by fake client
+type pendingNodeIndexingClient struct {
+	client.Client
+}
+
+func (c pendingNodeIndexingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+
+	listOptions := &client.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	podList, ok := list.(*corev1.PodList)
+	if !ok || listOptions.FieldSelector == nil {
+		return nil
+	}
+	node, found := listOptions.FieldSelector.RequiresExactMatch(IndexerFieldPendingNode)
+	if !found {
+		return nil
+	}
+
+	var selected []corev1.Pod
+	for i := range podList.Items {
+		for _, value := range pendingNodeIndexFunc(&podList.Items[i]) {
+			if value == node {
+				selected = append(selected, podList.Items[i])
+				break
+			}
+		}
+	}
+	podList.Items = selected
+	return nil
+}
+
+func TestPendingNodeIndexFunc(t *testing.T) {
+	pod := func(nodeName string, phase corev1.PodPhase, podIP string) *corev1.Pod {
+		return &corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: phase, PodIP: podIP},
+		}
+	}
+	hostNetworkPod := pod("node1", corev1.PodPending, "")
+	hostNetworkPod.Spec.HostNetwork = true
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected []string
+	}{
+		{"pending pod", pod("node1", corev1.PodPending, ""), []string{"node1", AnyPendingNode}},
+		{"unscheduled pod", pod("", corev1.PodPending, ""), nil},
+		{"running pod", pod("node1", corev1.PodRunning, "10.0.0.1"), nil},
+		{"pending pod with ip", pod("node1", corev1.PodPending, "10.0.0.1"), nil},
+		{"host network pod", hostNetworkPod, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if values := pendingNodeIndexFunc(test.pod); len(values) != len(test.expected) ||
+				(len(values) > 0 && (values[0] != test.expected[0] || values[1] != test.expected[1])) {
+				t.Errorf("expect index values %v but got %v", test.expected, values)
+			}
+		})
+	}
+}
+
+func TestSubnetAddressRangeChangePredicate(t *testing.T) {
+	subnet := func(reservedIPs, excludeIPs []string, gateway string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{
+			Spec: networkingv1.SubnetSpec{
+				Range: networkingv1.AddressRange{
+					Gateway:     gateway,
+					ReservedIPs: reservedIPs,
+					ExcludeIPs:  excludeIPs,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		oldS     *networkingv1.Subnet
+		newS     *networkingv1.Subnet
+		expected bool
+	}{
+		{"reserved ips released", subnet([]string{"10.0.0.2"}, nil, "10.0.0.1"), subnet(nil, nil, "10.0.0.1"), true},
+		{"excluded ips changed", subnet(nil, []string{"10.0.0.2"}, "10.0.0.1"), subnet(nil, []string{"10.0.0.3"}, "10.0.0.1"), true},
+		{"other fields changed", subnet(nil, nil, "10.0.0.1"), subnet(nil, nil, "10.0.0.254"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := subnetAddressRangeChangePredicate.Update(event.UpdateEvent{ObjectOld: test.oldS, ObjectNew: test.newS}); got != test.expected {
+				t.Errorf("expect %v but got %v", test.expected, got)
+			}
+		})
+	}
+
+	if subnetAddressRangeChangePredicate.Create(event.CreateEvent{Object: subnet(nil, nil, "")}) ||
+		subnetAddressRangeChangePredicate.Delete(event.DeleteEvent{Object: subnet(nil, nil, "")}) {
+		t.Errorf("expect creation and deletion of subnets to be ignored")
+	}
+}
+
+func TestPendingPodsOfSubnet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	pendingPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		}
+	}
+	runningPod := pendingPod("running", "node1")
+	runningPod.Status = corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.10"}
+
+	c := pendingNodeIndexingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&networkingv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "underlay"},
+			Spec:       networkingv1.NetworkSpec{Type: networkingv1.NetworkTypeUnderlay},
+			Status:     networkingv1.NetworkStatus{NodeList: []string{"node1"}},
+		},
+		&networkingv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+			Spec:       networkingv1.NetworkSpec{Type: networkingv1.NetworkTypeOverlay},
+		},
+		pendingPod("pending-node1", "node1"),
+		pendingPod("allocated-node1", "node1"),
+		pendingPod("pending-node2", "node2"),
+		runningPod,
+	).Build()}
+
+	podIPCache, err := NewPodIPCache(context.Background(), c, logr.Discard())
+	if err != nil {
+		t.Fatalf("fail to create pod ip cache: %v", err)
+	}
+	podIPCache.Record("allocated-uid", "allocated-node1", "default", []string{"allocated-node1-ip"})
+
+	r := &PodReconciler{Client: c, PodIPCache: podIPCache}
+
+	tests := []struct {
+		network  string
+		expected []string
+	}{
+		{"underlay", []string{"pending-node1"}},
+		{"overlay", []string{"pending-node1", "pending-node2"}},
+		{"not-exist", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.network, func(t *testing.T) {
+			requests := r.pendingPodsOfSubnet(&networkingv1.Subnet{
+				ObjectMeta: metav1.ObjectMeta{Name: "subnet"},
+				Spec:       networkingv1.SubnetSpec{Network: test.network},
+			})
+
+			var names []string
+			for _, request := range requests {
+				names = append(names, request.Name)
+			}
+			sort.Strings(names)
+			if len(names) != len(test.expected) || (len(names) > 0 && names[0] != test.expected[0]) ||
+				(len(names) > 1 && names[1] != test.expected[1]) {
+				t.Errorf("expect requeued pods %v but got %v", test.expected, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileIDLogKey is the log key of the unique ID which controller-runtime attaches
+// to the logger of every reconciliation
+const reconcileIDLogKey = "reconcileID"
+
+// reconcileIDLogSink remembers the reconcile ID attached by controller-runtime, so that
+// the same ID in logs can be recorded on allocated IPInstances
+type reconcileIDLogSink struct {
+	logr.LogSink
+	reconcileID apitypes.UID
+}
+
+func (s *reconcileIDLogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	sink := &reconcileIDLogSink{LogSink: s.LogSink.WithValues(keysAndValues...), reconcileID: s.reconcileID}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if key, ok := keysAndValues[i].(string); ok && key == reconcileIDLogKey {
+			if reconcileID, ok := keysAndValues[i+1].(apitypes.UID); ok {
+				sink.reconcileID = reconcileID
+			}
+		}
+	}
+	return sink
+}
+
+func (s *reconcileIDLogSink) WithName(name string) logr.LogSink {
+	return &reconcileIDLogSink{LogSink: s.LogSink.WithName(name), reconcileID: s.reconcileID}
+}
+
+// newReconcileIDLogger wraps the sink of log to remember reconcile ID, the call depth
+// is increased by one for the frame of wrapper
+func newReconcileIDLogger(log logr.Logger) logr.Logger {
+	log = log.WithCallDepth(1)
+	return log.WithSink(&reconcileIDLogSink{LogSink: log.GetSink()})
+}
+
+// podLogConstructor builds the logger of pod controller with the same values as the default one
+// of controller-runtime, and remembers the reconcile ID attached later
+func podLogConstructor(log logr.Logger) func(*reconcile.Request) logr.Logger {
+	log = log.WithValues("controller", ControllerPod, "controllerGroup", "", "controllerKind", "Pod")
+	return func(req *reconcile.Request) logr.Logger {
+		log := log
+		if req != nil {
+			log = log.WithValues("Pod", klog.KRef(req.Namespace, req.Name),
+				"namespace", req.Namespace, "name", req.Name)
+		}
+		return newReconcileIDLogger(log)
+	}
+}
+
+// reconcileIDFromContext returns the reconcile ID attached by controller-runtime,
+// empty if the logger of ctx is not built by podLogConstructor
+func reconcileIDFromContext(ctx context.Context) apitypes.UID {
+	if sink, ok := ctrllog.FromContext(ctx).GetSink().(*reconcileIDLogSink); ok {
+		return sink.reconcileID
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcileIDFromContext(t *testing.T) {
+	if reconcileID := reconcileIDFromContext(ctrllog.IntoContext(context.Background(), logr.Discard())); len(reconcileID) != 0 {
+		t.Errorf("expect no reconcile ID for logger not built by pod log constructor but got %v", reconcileID)
+	}
+
+	req := &reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "pod"}}
+	log := podLogConstructor(logr.Discard())(req)
+	if reconcileID := reconcileIDFromContext(ctrllog.IntoContext(context.Background(), log)); len(reconcileID) != 0 {
+		t.Errorf("expect no reconcile ID before attached but got %v", reconcileID)
+	}
+
+	// the same way as controller-runtime attaches reconcile ID
+	expected := uuid.NewUUID()
+	log = log.WithValues(reconcileIDLogKey, expected).WithName("assign").WithValues("network", "test")
+	if reconcileID := reconcileIDFromContext(ctrllog.IntoContext(context.Background(), log)); reconcileID != expected {
+		t.Errorf("expect reconcile ID %v but got %v", expected, reconcileID)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// NetworkShard is the shard of networks reconciled by a manager instance. Networks are distributed
+// across shards by the 32-bit FNV-1a hash of their names modulo the shard count, so a network always
+// belongs to the same shard as long as the shard count is unchanged.
+//
+// Controllers of network-scoped objects, i.e., networks, subnets, IPInstances and pods, only reconcile
+// the objects of networks in their shard, while cluster-scoped controllers only run in the primary
+// shard, whose ID is 0.
+type NetworkShard struct {
+	// Count is the number of shards, sharding is disabled if it is not larger than 1
+	Count int
+	// ID is the shard of manager instance, in range [0, Count)
+	ID int
+}
+
+// Validate checks whether the shard ID is in range of shard count
+func (s NetworkShard) Validate() error {
+	if s.Count < 1 {
+		return fmt.Errorf("shard count %d must be positive", s.Count)
+	}
+	if s.ID < 0 || s.ID >= s.Count {
+		return fmt.Errorf("shard id %d must be in range [0, %d)", s.ID, s.Count)
+	}
+	return nil
+}
+
+// Enabled checks whether networks are distributed across multiple shards
+func (s NetworkShard) Enabled() bool {
+	return s.Count > 1
+}
+
+// IsPrimary checks whether cluster-scoped controllers should run in this shard
+func (s NetworkShard) IsPrimary() bool {
+	return !s.Enabled() || s.ID == 0
+}
+
+// Owns checks whether the network belongs to this shard
+func (s NetworkShard) Owns(networkName string) bool {
+	return !s.Enabled() || NetworkShardOf(networkName, s.Count) == s.ID
+}
+
+// NetworkShardOf returns the shard which network belongs to among count shards
+func NetworkShardOf(networkName string, count int) int {
+	if count <= 1 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(networkName))
+	return int(h.Sum32() % uint32(count))
+}
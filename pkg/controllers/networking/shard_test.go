@@ -0,0 +1,86 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNetworkShardOf(t *testing.T) {
+	// shards are documented as fnv32a(name) % count, which must never change across versions
+	tests := []struct {
+		name  string
+		count int
+		shard int
+	}{
+		{"network1", 1, 0},
+		{"network1", 0, 0},
+		{"", 3, 2166136261 % 3},
+		{"a", 2, 3826002220 % 2},
+		{"a", 7, 3826002220 % 7},
+	}
+
+	for _, test := range tests {
+		if shard := NetworkShardOf(test.name, test.count); shard != test.shard {
+			t.Errorf("expected shard %d of network %q among %d but got %d", test.shard, test.name, test.count, shard)
+		}
+	}
+
+	counts := make([]int, 4)
+	for i := 0; i < 400; i++ {
+		counts[NetworkShardOf(fmt.Sprintf("network-%d", i), len(counts))]++
+	}
+	for shard, count := range counts {
+		if count == 0 {
+			t.Errorf("expected networks distributed to shard %d", shard)
+		}
+	}
+}
+
+func TestNetworkShard(t *testing.T) {
+	disabled := NetworkShard{Count: 1}
+	if disabled.Enabled() || !disabled.IsPrimary() || !disabled.Owns("any") {
+		t.Fatalf("expected every network owned if sharding is disabled")
+	}
+
+	for id := 0; id < 3; id++ {
+		shard := NetworkShard{Count: 3, ID: id}
+		if err := shard.Validate(); err != nil {
+			t.Fatalf("unexpected error of shard %d: %v", id, err)
+		}
+		if shard.IsPrimary() != (id == 0) {
+			t.Fatalf("expected only shard 0 primary")
+		}
+	}
+
+	owners := 0
+	for id := 0; id < 3; id++ {
+		if (NetworkShard{Count: 3, ID: id}).Owns("network1") {
+			owners++
+		}
+	}
+	if owners != 1 {
+		t.Fatalf("expected network owned by exactly one shard but got %d", owners)
+	}
+
+	for _, invalid := range []NetworkShard{{Count: 0}, {Count: 3, ID: 3}, {Count: 3, ID: -1}} {
+		if invalid.Validate() == nil {
+			t.Fatalf("expected shard %+v invalid", invalid)
+		}
+	}
+}
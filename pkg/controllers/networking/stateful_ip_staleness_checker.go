@@ -0,0 +1,147 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+const CheckerStatefulIPStaleness = "StatefulIPStaleness"
+
+const ReasonStaleStatefulIP = "StaleStatefulIP"
+
+// StatefulIPStalenessChecker checks periodically whether retained IPInstances of StatefulSets
+// have indexes out of the replicas of StatefulSets, which may happen if the index format of
+// pods changes, and these IPInstances will be retained for pods which are permanently gone
+type StatefulIPStalenessChecker struct {
+	Client    client.Client
+	APIReader client.Reader
+	Recorder  record.EventRecorder
+	Logger    logr.Logger
+
+	CheckPeriod time.Duration
+	// GracePeriod is how long a stale IPInstance is kept before being deleted since it is
+	// detected, zero means never deleting stale IPInstances
+	GracePeriod time.Duration
+
+	// staleSince records the time when IPInstances are detected as stale for the first time
+	staleSince map[apitypes.UID]time.Time
+}
+
+func (c *StatefulIPStalenessChecker) Start(ctx context.Context) error {
+	c.Logger.Info("stateful ip staleness checker is starting", "period", c.CheckPeriod, "gracePeriod", c.GracePeriod)
+
+	ticker := time.NewTicker(c.CheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.check(ctx, time.Now()); err != nil {
+				c.Logger.Error(err, "unable to check stateful ip staleness")
+			}
+		case <-ctx.Done():
+			c.Logger.Info("stateful ip staleness checker is stopping")
+			return nil
+		}
+	}
+}
+
+func (c *StatefulIPStalenessChecker) check(ctx context.Context, now time.Time) error {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.Client.List(ctx, ipInstanceList); err != nil {
+		return fmt.Errorf("unable to list ip instances: %v", err)
+	}
+
+	var staleSince = make(map[apitypes.UID]time.Time)
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		stale, replicas, err := c.isStale(ctx, ipInstance)
+		if err != nil {
+			c.Logger.Error(err, "unable to check ip instance", "ipInstance", client.ObjectKeyFromObject(ipInstance))
+			continue
+		}
+		if !stale {
+			continue
+		}
+
+		since, exist := c.staleSince[ipInstance.UID]
+		if !exist {
+			since = now
+			c.Recorder.Eventf(ipInstance, corev1.EventTypeWarning, ReasonStaleStatefulIP,
+				"index %d of retained ip is out of %d replicas of statefulset %s",
+				*ipInstance.Spec.Binding.Stateful.Index, replicas, ipInstance.Spec.Binding.ReferredObject.Name)
+		}
+
+		if c.GracePeriod > 0 && now.Sub(since) >= c.GracePeriod {
+			if err = client.IgnoreNotFound(c.Client.Delete(ctx, ipInstance)); err != nil {
+				c.Logger.Error(err, "unable to delete stale ip instance", "ipInstance", client.ObjectKeyFromObject(ipInstance))
+			} else {
+				c.Logger.Info("stale ip instance deleted", "ipInstance", client.ObjectKeyFromObject(ipInstance), "staleSince", since)
+				continue
+			}
+		}
+		staleSince[ipInstance.UID] = since
+	}
+	c.staleSince = staleSince
+
+	return nil
+}
+
+// isStale checks if the ip instance is retained by a statefulset with an index out of replicas,
+// ip instances whose statefulsets do not exist are left to garbage collection of owner references
+func (c *StatefulIPStalenessChecker) isStale(ctx context.Context, ipInstance *networkingv1.IPInstance) (bool, int32, error) {
+	binding := &ipInstance.Spec.Binding
+	if !networkingv1.IsReserved(ipInstance) || !ipInstance.DeletionTimestamp.IsZero() ||
+		binding.ReferredObject.Kind != "StatefulSet" || binding.Stateful == nil || binding.Stateful.Index == nil {
+		return false, 0, nil
+	}
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := c.APIReader.Get(ctx, apitypes.NamespacedName{
+		Namespace: ipInstance.Namespace,
+		Name:      binding.ReferredObject.Name,
+	}, statefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, 0, nil
+		}
+		return false, 0, fmt.Errorf("unable to get statefulset %s: %v", binding.ReferredObject.Name, err)
+	}
+
+	// a recreated statefulset with the same name is not the owner any more
+	if len(binding.ReferredObject.UID) > 0 && binding.ReferredObject.UID != statefulSet.UID {
+		return false, 0, nil
+	}
+
+	var replicas int32 = 1
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	return *binding.Stateful.Index >= replicas, replicas, nil
+}
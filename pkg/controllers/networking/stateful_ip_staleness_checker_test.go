@@ -0,0 +1,143 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestStatefulIPStalenessChecker(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	replicas := int32(2)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+
+	ipInstance := func(name string, index int32, nodeName string, ownerUID apitypes.UID) *networkingv1.IPInstance {
+		return &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: apitypes.UID(name)},
+			Spec: networkingv1.IPInstanceSpec{
+				Binding: networkingv1.Binding{
+					ReferredObject: networkingv1.ObjectMeta{Kind: "StatefulSet", Name: "web", UID: ownerUID},
+					NodeName:       nodeName,
+					Stateful:       &networkingv1.StatefulInfo{Index: &index},
+				},
+			},
+		}
+	}
+
+	newChecker := func(gracePeriod time.Duration) (*StatefulIPStalenessChecker, *record.FakeRecorder) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			statefulSet,
+			ipInstance("web-0", 0, "", "web-uid"),
+			ipInstance("web-3", 3, "", "web-uid"),
+			ipInstance("web-4-in-use", 4, "node1", "web-uid"),
+			ipInstance("web-5-old-owner", 5, "", "old-web-uid"),
+		).Build()
+		recorder := record.NewFakeRecorder(10)
+		return &StatefulIPStalenessChecker{
+			Client:      c,
+			APIReader:   c,
+			Recorder:    recorder,
+			Logger:      logr.Discard(),
+			GracePeriod: gracePeriod,
+		}, recorder
+	}
+
+	remaining := func(t *testing.T, c client.Client) []string {
+		ipList := &networkingv1.IPInstanceList{}
+		if err := c.List(context.Background(), ipList); err != nil {
+			t.Fatalf("fail to list IPInstances: %v", err)
+		}
+		var names []string
+		for _, ip := range ipList.Items {
+			names = append(names, ip.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("stale ip deleted after grace period", func(t *testing.T) {
+		checker, recorder := newChecker(10 * time.Minute)
+		now := time.Now()
+
+		if err := checker.check(context.Background(), now); err != nil {
+			t.Fatalf("fail to check: %v", err)
+		}
+		if events := drainEvents(recorder); len(events) != 1 {
+			t.Fatalf("expect one event of stale ip but got %v", events)
+		}
+		if _, exist := checker.staleSince["web-3"]; !exist || len(checker.staleSince) != 1 {
+			t.Fatalf("expect only web-3 to be stale but got %v", checker.staleSince)
+		}
+
+		// stale ip is reported only once and kept within grace period
+		if err := checker.check(context.Background(), now.Add(5*time.Minute)); err != nil {
+			t.Fatalf("fail to check: %v", err)
+		}
+		if events := drainEvents(recorder); len(events) != 0 {
+			t.Fatalf("expect no more events but got %v", events)
+		}
+		if names := remaining(t, checker.Client); len(names) != 4 {
+			t.Fatalf("expect no ip deleted within grace period but got %v", names)
+		}
+
+		if err := checker.check(context.Background(), now.Add(10*time.Minute)); err != nil {
+			t.Fatalf("fail to check: %v", err)
+		}
+		for _, name := range remaining(t, checker.Client) {
+			if name == "web-3" {
+				t.Fatalf("expect stale ip to be deleted after grace period")
+			}
+		}
+		if len(checker.staleSince) != 0 {
+			t.Errorf("expect deleted ip to be forgotten but got %v", checker.staleSince)
+		}
+	})
+
+	t.Run("stale ip never deleted without grace period", func(t *testing.T) {
+		checker, _ := newChecker(0)
+		now := time.Now()
+		for _, elapsed := range []time.Duration{0, time.Hour, 24 * time.Hour} {
+			if err := checker.check(context.Background(), now.Add(elapsed)); err != nil {
+				t.Fatalf("fail to check: %v", err)
+			}
+		}
+		if names := remaining(t, checker.Client); len(names) != 4 {
+			t.Errorf("expect no ip deleted but got %v", names)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	ipamutils "github.com/alibaba/hybridnet/pkg/ipam/utils"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const RecorderSubnetAllocationHistory = "SubnetAllocationHistory"
+
+const (
+	// subnetAllocationHistoryLimit keeps the hourly snapshots of one week
+	subnetAllocationHistoryLimit = 24 * 7
+
+	subnetAllocationHistoryPrefix  = "subnet-allocation-history-"
+	subnetAllocationHistoryDataKey = "history"
+	subnetAllocationHistoryHour    = "2006-01-02T15"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+
+// SubnetAllocationSnapshot is the number of used IPs of subnet in an hour
+type SubnetAllocationSnapshot struct {
+	Hour string `json:"hour"`
+	Used int32  `json:"used"`
+}
+
+// SubnetAllocationHistoryRecorder snapshots the used IPs of subnets periodically into a bounded
+// history, which is stored in a ConfigMap per subnet for capacity planning. Only the latest snapshot
+// is exported as metrics, the trend over time is left to the time series storage of Prometheus.
+// Subnets forecasted to be exhausted soon by the trend of histories are warned by events.
+type SubnetAllocationHistoryRecorder struct {
+	Client    client.Client
+	APIReader client.Reader
+	Recorder  record.EventRecorder
+	Logger    logr.Logger
+
+	// Namespace is where ConfigMaps of histories are stored
+	Namespace    string
+	RecordPeriod time.Duration
+}
+
+func (r *SubnetAllocationHistoryRecorder) Start(ctx context.Context) error {
+	r.Logger.Info("subnet allocation history recorder is starting", "period", r.RecordPeriod)
+
+	ticker := time.NewTicker(r.RecordPeriod)
+	defer ticker.Stop()
+
+	// record once at start, or the metrics are missing until the first tick
+	if err := r.record(ctx, time.Now()); err != nil {
+		r.Logger.Error(err, "unable to record subnet allocation history")
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.record(ctx, time.Now()); err != nil {
+				r.Logger.Error(err, "unable to record subnet allocation history")
+			}
+		case <-ctx.Done():
+			r.Logger.Info("subnet allocation history recorder is stopping")
+			return nil
+		}
+	}
+}
+
+func (r *SubnetAllocationHistoryRecorder) record(ctx context.Context, now time.Time) error {
+	subnetList := &networkingv1.SubnetList{}
+	if err := r.Client.List(ctx, subnetList); err != nil {
+		return fmt.Errorf("unable to list subnets: %v", err)
+	}
+
+	// clean up the metrics of deleted subnets
+	metrics.SubnetAllocationHistoryGauge.Reset()
+	metrics.SubnetDaysToExhaustionGauge.Reset()
+
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		if !subnet.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		history, err := r.recordSubnet(ctx, subnet, now)
+		if err != nil {
+			r.Logger.Error(err, "unable to record allocation history of subnet", "subnet", subnet.Name)
+			continue
+		}
+
+		metrics.SubnetAllocationHistoryGauge.WithLabelValues(subnet.Name).Set(float64(subnet.Status.Used))
+
+		days := ForecastDaysToExhaustion(availableHistory(history, subnet.Status.Total))
+		metrics.SubnetDaysToExhaustionGauge.WithLabelValues(subnet.Name).Set(days)
+		if days < subnetExhaustionImminentDays {
+			r.Recorder.Eventf(subnet, corev1.EventTypeWarning, ReasonSubnetExhaustionImminent,
+				"subnet is forecasted to be exhausted in %.1f days, %d of %d IPs used", days,
+				subnet.Status.Used, subnet.Status.Total)
+		}
+	}
+	return nil
+}
+
+func (r *SubnetAllocationHistoryRecorder) recordSubnet(ctx context.Context, subnet *networkingv1.Subnet,
+	now time.Time) ([]SubnetAllocationSnapshot, error) {
+	// ConfigMaps are read from apiserver directly to avoid caching all ConfigMaps of cluster
+	configMap := &corev1.ConfigMap{}
+	err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: subnetAllocationHistoryPrefix + subnet.Name}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get history: %v", err)
+	}
+	exist := err == nil
+
+	var history []SubnetAllocationSnapshot
+	if data := configMap.Data[subnetAllocationHistoryDataKey]; len(data) > 0 {
+		if err = json.Unmarshal([]byte(data), &history); err != nil {
+			// a corrupted history is dropped instead of blocking the following records
+			r.Logger.Error(err, "unable to parse allocation history of subnet, reset it", "subnet", subnet.Name)
+			history = nil
+		}
+	}
+
+	history = appendSubnetAllocationSnapshot(history, SubnetAllocationSnapshot{
+		Hour: now.UTC().Format(subnetAllocationHistoryHour),
+		Used: subnet.Status.Used,
+	}, subnetAllocationHistoryLimit)
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      subnetAllocationHistoryPrefix + subnet.Name,
+				Namespace: r.Namespace,
+				Labels: map[string]string{
+					constants.LabelSubnet: subnet.Name,
+				},
+				// histories are garbage collected after subnets are deleted
+				OwnerReferences: []metav1.OwnerReference{
+					*ipamutils.NewControllerRef(subnet, networkingv1.GroupVersion.WithKind("Subnet"), false, false),
+				},
+			},
+			Data: map[string]string{
+				subnetAllocationHistoryDataKey: string(data),
+			},
+		}
+		return history, r.Client.Create(ctx, configMap)
+	}
+
+	configMap.Data = map[string]string{
+		subnetAllocationHistoryDataKey: string(data),
+	}
+	return history, r.Client.Update(ctx, configMap)
+}
+
+// availableHistory converts the used IPs of history into available IPs of the current subnet size,
+// together with the hours of snapshots since the oldest one. Snapshots of unknown hours are skipped.
+func availableHistory(history []SubnetAllocationSnapshot, total int32) ([]float64, []float64) {
+	hours := make([]float64, 0, len(history))
+	available := make([]float64, 0, len(history))
+
+	var oldest time.Time
+	for _, snapshot := range history {
+		hour, err := time.Parse(subnetAllocationHistoryHour, snapshot.Hour)
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() {
+			oldest = hour
+		}
+		hours = append(hours, hour.Sub(oldest).Hours())
+		available = append(available, float64(total-snapshot.Used))
+	}
+	return hours, available
+}
+
+// appendSubnetAllocationSnapshot appends snapshot to history as a ring buffer of limit, the
+// snapshot of the same hour is replaced by the latest one
+func appendSubnetAllocationSnapshot(history []SubnetAllocationSnapshot, snapshot SubnetAllocationSnapshot,
+	limit int) []SubnetAllocationSnapshot {
+	if len(history) > 0 && history[len(history)-1].Hour == snapshot.Hour {
+		history[len(history)-1] = snapshot
+		return history
+	}
+
+	history = append(history, snapshot)
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
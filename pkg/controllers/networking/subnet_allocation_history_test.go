@@ -0,0 +1,150 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+func TestSubnetAllocationHistoryRecorder(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	subnet := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1", UID: "c1a5"},
+		Status:     networkingv1.SubnetStatus{Count: networkingv1.Count{Total: 16, Used: 10}},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(subnet).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SubnetAllocationHistoryRecorder{
+		Client:    c,
+		APIReader: c,
+		Recorder:  recorder,
+		Logger:    ctrl.Log,
+		Namespace: "kube-system",
+	}
+
+	start := time.Date(2022, 10, 1, 8, 10, 0, 0, time.UTC)
+	if err := r.record(ctx, start); err != nil {
+		t.Fatalf("fail to record: %v", err)
+	}
+
+	subnet.Status.Used = 12
+	if err := c.Status().Update(ctx, subnet); err != nil {
+		t.Fatalf("fail to update subnet: %v", err)
+	}
+	// the snapshot of the same hour is replaced
+	if err := r.record(ctx, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("fail to record: %v", err)
+	}
+	if err := r.record(ctx, start.Add(time.Hour)); err != nil {
+		t.Fatalf("fail to record: %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "subnet-allocation-history-subnet1"}, configMap); err != nil {
+		t.Fatalf("fail to get history: %v", err)
+	}
+	if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].UID != subnet.UID {
+		t.Fatalf("expected history owned by subnet but got %+v", configMap.OwnerReferences)
+	}
+
+	var history []SubnetAllocationSnapshot
+	if err := json.Unmarshal([]byte(configMap.Data["history"]), &history); err != nil {
+		t.Fatalf("fail to parse history: %v", err)
+	}
+	expected := []SubnetAllocationSnapshot{
+		{Hour: "2022-10-01T08", Used: 12},
+		{Hour: "2022-10-01T09", Used: 12},
+	}
+	if !reflect.DeepEqual(history, expected) {
+		t.Fatalf("expected history %+v but got %+v", expected, history)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected no exhaustion forecasted for steady usage but got %d events", len(recorder.Events))
+	}
+	if used := testutil.ToFloat64(metrics.SubnetAllocationHistoryGauge.WithLabelValues("subnet1")); used != 12 {
+		t.Fatalf("expected latest used IPs exported but got %v", used)
+	}
+
+	// the subnet is expanded and one more IP is used per hour in the latest day, the last 2 IPs are
+	// forecasted to be used up in 2 hours
+	var growing []SubnetAllocationSnapshot
+	for i := 0; i < 23; i++ {
+		growing = append(growing, SubnetAllocationSnapshot{
+			Hour: start.Add(time.Duration(i-21) * time.Hour).Format(subnetAllocationHistoryHour),
+			Used: int32(i + 15),
+		})
+	}
+	data, _ := json.Marshal(growing)
+	configMap.Data["history"] = string(data)
+	if err := c.Update(ctx, configMap); err != nil {
+		t.Fatalf("fail to update history: %v", err)
+	}
+
+	subnet.Status.Total, subnet.Status.Used = 40, 38
+	if err := c.Status().Update(ctx, subnet); err != nil {
+		t.Fatalf("fail to update subnet: %v", err)
+	}
+	if err := r.record(ctx, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("fail to record: %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected exhaustion imminent event but got %d events", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, ReasonSubnetExhaustionImminent) {
+		t.Fatalf("expected event %s but got %s", ReasonSubnetExhaustionImminent, event)
+	}
+	if days := testutil.ToFloat64(metrics.SubnetDaysToExhaustionGauge.WithLabelValues("subnet1")); math.Abs(days-2.0/24) > 1e-9 {
+		t.Fatalf("expected exhaustion forecasted in 2 hours but got %v days", days)
+	}
+}
+
+func TestAppendSubnetAllocationSnapshot(t *testing.T) {
+	var history []SubnetAllocationSnapshot
+	for _, hour := range []string{"h1", "h2", "h3", "h4"} {
+		history = appendSubnetAllocationSnapshot(history, SubnetAllocationSnapshot{Hour: hour}, 3)
+	}
+	if len(history) != 3 || history[0].Hour != "h2" || history[2].Hour != "h4" {
+		t.Fatalf("expected the oldest snapshot evicted but got %+v", history)
+	}
+}
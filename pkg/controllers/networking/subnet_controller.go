@@ -0,0 +1,322 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	ipamutils "github.com/alibaba/hybridnet/pkg/ipam/utils"
+	globalutils "github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const ControllerSubnet = "Subnet"
+
+// SubnetReconciler reconciles a Subnet object, it protects subnets from being deleted
+// while active IPInstances are still referencing them
+type SubnetReconciler struct {
+	client.Client
+
+	Recorder record.EventRecorder
+
+	// RetryTimeout is how long a subnet keeps failing with permanent errors before its retries
+	// are given up, until the subnet is updated, non-positive means retrying forever
+	RetryTimeout time.Duration
+
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
+	concurrency.ControllerConcurrency
+
+	retryTrackerOnce sync.Once
+	retryTracker     *reconcileRetryTracker
+}
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=subnets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=subnets/finalizers,verbs=update
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=ipinstances,verbs=get;list;watch
+
+func (r *SubnetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrllog.FromContext(ctx)
+
+	var subnet = &networkingv1.Subnet{}
+
+	defer func() {
+		if err != nil {
+			log.Error(err, "reconciliation fails")
+		}
+	}()
+
+	if err = r.Get(ctx, req.NamespacedName, subnet); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.retries().Forget(req.Name)
+		}
+		return ctrl.Result{}, wrapError("unable to fetch Subnet", client.IgnoreNotFound(err))
+	}
+
+	if !r.Shard.Owns(subnet.Spec.Network) {
+		return ctrl.Result{}, nil
+	}
+
+	if r.retries().GivenUp(subnet) {
+		log.V(1).Info("retries of subnet have been given up, waiting for subnet to be updated")
+		return ctrl.Result{}, nil
+	}
+
+	if result, err = r.reconcile(ctx, subnet); err != nil {
+		// subnet in cache is out of date, e.g., it has been deleted and recreated with the same
+		// name, which is not a failure of reconciliation
+		if apierrors.IsConflict(err) {
+			log.V(1).Info("subnet is modified since it is fetched, reconcile again", "reason", err.Error())
+			return ctrl.Result{Requeue: true}, nil
+		}
+		if isTransientReconcileError(err) {
+			return result, err
+		}
+		if r.retries().Fail(subnet, time.Now()) {
+			return ctrl.Result{}, wrapError("unable to give up retries of subnet", r.giveUpRetries(ctx, subnet, err))
+		}
+		return result, err
+	}
+
+	r.retries().Forget(subnet.Name)
+	return result, wrapError("unable to clear reconcile failed condition of subnet", r.clearReconcileFailed(ctx, subnet))
+}
+
+func (r *SubnetReconciler) reconcile(ctx context.Context, subnet *networkingv1.Subnet) (result ctrl.Result, err error) {
+	log := ctrllog.FromContext(ctx)
+
+	if subnet.DeletionTimestamp.IsZero() {
+		if err = r.addFinalizer(ctx, subnet); err != nil {
+			return ctrl.Result{}, wrapError("unable to add finalizer to subnet", err)
+		}
+		return ctrl.Result{}, wrapError("unable to add network owner reference to subnet", r.addNetworkOwnerReference(ctx, subnet))
+	}
+
+	if !controllerutil.ContainsFinalizer(subnet, constants.FinalizerSubnetProtection) {
+		return ctrl.Result{}, nil
+	}
+
+	if !globalutils.ParseBoolOrDefault(subnet.Annotations[constants.AnnotationForceDelete], false) {
+		var blockingIPInstances []string
+		if blockingIPInstances, err = r.listActiveIPInstancesOfSubnet(ctx, subnet.Name); err != nil {
+			return ctrl.Result{}, wrapError("unable to list active IPInstances of subnet", err)
+		}
+
+		if len(blockingIPInstances) > 0 {
+			r.Recorder.Event(subnet, corev1.EventTypeWarning, "DeletionBlocked",
+				fmt.Sprintf("subnet is still referenced by active IPInstances %v", blockingIPInstances))
+			// the deletion of IPInstances will trigger the reconciliation again
+			log.V(1).Info("subnet deletion is blocked by active IPInstances", "ipinstances", blockingIPInstances)
+			return ctrl.Result{}, nil
+		}
+	} else {
+		log.Info("subnet is being force deleted, skip checking IPInstances")
+	}
+
+	return ctrl.Result{}, wrapError("unable to remove finalizer from subnet", r.removeFinalizer(ctx, subnet))
+}
+
+func (r *SubnetReconciler) retries() *reconcileRetryTracker {
+	r.retryTrackerOnce.Do(func() {
+		r.retryTracker = newReconcileRetryTracker(r.RetryTimeout)
+	})
+	return r.retryTracker
+}
+
+// giveUpRetries marks the subnet as failed to reconcile, human intervention is required
+// to resume retries because the failure is supposed to be permanent
+func (r *SubnetReconciler) giveUpRetries(ctx context.Context, subnet *networkingv1.Subnet, reconcileErr error) error {
+	ctrllog.FromContext(ctx).Error(reconcileErr, "give up retries of subnet after failing for too long", "retryTimeout", r.RetryTimeout)
+
+	message := fmt.Sprintf("reconciliation is given up after failing for %v, update subnet to retry: %v",
+		r.RetryTimeout, reconcileErr)
+	r.Recorder.Event(subnet, corev1.EventTypeWarning, networkingv1.SubnetConditionReconcileFailed, message)
+
+	patch := client.MergeFrom(subnet.DeepCopy())
+	meta.SetStatusCondition(&subnet.Status.Conditions, metav1.Condition{
+		Type:               networkingv1.SubnetConditionReconcileFailed,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: subnet.Generation,
+		Reason:             "RetryTimeoutExceeded",
+		Message:            message,
+	})
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.Status().Patch(ctx, subnet, patch)
+	})
+}
+
+func (r *SubnetReconciler) clearReconcileFailed(ctx context.Context, subnet *networkingv1.Subnet) error {
+	if !meta.IsStatusConditionTrue(subnet.Status.Conditions, networkingv1.SubnetConditionReconcileFailed) {
+		return nil
+	}
+
+	patch := client.MergeFrom(subnet.DeepCopy())
+	meta.SetStatusCondition(&subnet.Status.Conditions, metav1.Condition{
+		Type:               networkingv1.SubnetConditionReconcileFailed,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: subnet.Generation,
+		Reason:             "Reconciled",
+	})
+	return client.IgnoreNotFound(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.Status().Patch(ctx, subnet, patch)
+	}))
+}
+
+// listActiveIPInstancesOfSubnet returns the namespaced names of IPInstances which are
+// referencing the subnet and not being deleted
+func (r *SubnetReconciler) listActiveIPInstancesOfSubnet(ctx context.Context, subnetName string) ([]string, error) {
+	var ipInstanceList = &networkingv1.IPInstanceList{}
+	if err := r.List(ctx, ipInstanceList, client.MatchingLabels{constants.LabelSubnet: subnetName}); err != nil {
+		return nil, err
+	}
+
+	var activeIPInstances []string
+	for i := range ipInstanceList.Items {
+		var ipInstance = &ipInstanceList.Items[i]
+		if ipInstance.Spec.Subnet != subnetName || !ipInstance.DeletionTimestamp.IsZero() {
+			continue
+		}
+		activeIPInstances = append(activeIPInstances, strings.Join([]string{ipInstance.Namespace, ipInstance.Name}, "/"))
+	}
+	return activeIPInstances, nil
+}
+
+// patchSubnet patches the subnet with optimistic lock, so that a patch computed from an out-of-date
+// subnet, e.g., the deleted one before a subnet with the same name is recreated, is never applied to
+// another subnet, a conflict error is returned instead
+func (r *SubnetReconciler) patchSubnet(ctx context.Context, subnet *networkingv1.Subnet, mutate func()) error {
+	patch := client.MergeFromWithOptions(subnet.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	mutate()
+	return r.Patch(ctx, subnet, patch)
+}
+
+func (r *SubnetReconciler) addFinalizer(ctx context.Context, subnet *networkingv1.Subnet) error {
+	if controllerutil.ContainsFinalizer(subnet, constants.FinalizerSubnetProtection) {
+		return nil
+	}
+
+	return r.patchSubnet(ctx, subnet, func() {
+		controllerutil.AddFinalizer(subnet, constants.FinalizerSubnetProtection)
+	})
+}
+
+// addNetworkOwnerReference makes parent network a non-controller owner of subnet, so
+// that garbage collection of IPInstances can be chained from network to subnet
+func (r *SubnetReconciler) addNetworkOwnerReference(ctx context.Context, subnet *networkingv1.Subnet) error {
+	var network = &networkingv1.Network{}
+	if err := r.Get(ctx, types.NamespacedName{Name: subnet.Spec.Network}, network); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	for _, owner := range subnet.OwnerReferences {
+		if owner.UID == network.UID {
+			return nil
+		}
+	}
+
+	return r.patchSubnet(ctx, subnet, func() {
+		subnet.OwnerReferences = append(subnet.OwnerReferences,
+			*ipamutils.NewControllerRef(network, networkingv1.GroupVersion.WithKind("Network"), false, false))
+	})
+}
+
+// removeFinalizer releases a terminating subnet, the subnet must be the one being deleted
+// rather than a recreated one with the same name
+func (r *SubnetReconciler) removeFinalizer(ctx context.Context, subnet *networkingv1.Subnet) error {
+	if subnet.DeletionTimestamp.IsZero() {
+		return fmt.Errorf("subnet %s is not being deleted", subnet.Name)
+	}
+
+	if !controllerutil.ContainsFinalizer(subnet, constants.FinalizerSubnetProtection) {
+		return nil
+	}
+
+	return r.patchSubnet(ctx, subnet, func() {
+		controllerutil.RemoveFinalizer(subnet, constants.FinalizerSubnetProtection)
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SubnetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerSubnet).
+		For(&networkingv1.Subnet{},
+			builder.WithPredicates(
+				predicate.Or(
+					&predicate.GenerationChangedPredicate{},
+					&predicate.AnnotationChangedPredicate{},
+				),
+			)).
+		Watches(&source.Kind{Type: &networkingv1.IPInstance{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				ipInstance, ok := object.(*networkingv1.IPInstance)
+				if !ok {
+					return nil
+				}
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name: ipInstance.Spec.Subnet,
+						},
+					},
+				}
+			}),
+			builder.WithPredicates(
+				predicate.Funcs{
+					CreateFunc: func(event.CreateEvent) bool {
+						return false
+					},
+					UpdateFunc: func(e event.UpdateEvent) bool {
+						// only care about IPInstances starting to be deleted
+						return e.ObjectOld.GetDeletionTimestamp().IsZero() && !e.ObjectNew.GetDeletionTimestamp().IsZero()
+					},
+				},
+			),
+		).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
@@ -0,0 +1,125 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// staleCacheClient serves the subnet which has been deleted from apiserver, like an informer
+// cache which has not received the events of deletion and recreation yet
+type staleCacheClient struct {
+	client.Client
+	stale *networkingv1.Subnet
+}
+
+func (s staleCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if subnet, ok := obj.(*networkingv1.Subnet); ok && key.Name == s.stale.Name {
+		s.stale.DeepCopyInto(subnet)
+		return nil
+	}
+	return s.Client.Get(ctx, key, obj, opts...)
+}
+
+func TestSubnetReconcilerWithRecreatedSubnet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	ctx := context.Background()
+	recreated := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "subnet1",
+			UID:        "uid-2",
+			Finalizers: []string{constants.FinalizerSubnetProtection},
+		},
+		Spec: networkingv1.SubnetSpec{
+			Network: "network1",
+			Range:   networkingv1.AddressRange{Version: networkingv1.IPv4, CIDR: "192.168.0.0/24"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(recreated).Build()
+
+	live := &networkingv1.Subnet{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "subnet1"}, live); err != nil {
+		t.Fatalf("fail to get subnet: %v", err)
+	}
+
+	// the deleted subnet with the same name, which is still terminating in cache
+	deletionTimestamp := metav1.Now()
+	deleted := live.DeepCopy()
+	deleted.UID = "uid-1"
+	deleted.ResourceVersion = "1"
+	deleted.DeletionTimestamp = &deletionTimestamp
+
+	r := &SubnetReconciler{
+		Client:       staleCacheClient{Client: c, stale: deleted},
+		Recorder:     record.NewFakeRecorder(10),
+		RetryTimeout: time.Nanosecond,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "subnet1"}}
+
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("expected out-of-order subnet not taken as failure but got %v", err)
+	}
+	if !result.Requeue {
+		t.Fatalf("expected reconciliation requeued for cache to catch up")
+	}
+
+	if err = c.Get(ctx, types.NamespacedName{Name: "subnet1"}, live); err != nil {
+		t.Fatalf("fail to get subnet: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(live, constants.FinalizerSubnetProtection) {
+		t.Fatalf("expected finalizer of recreated subnet kept")
+	}
+
+	// cache catches up
+	r.Client = c
+	if _, err = r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("fail to reconcile: %v", err)
+	}
+	if r.retries().GivenUp(live) {
+		t.Fatalf("expected retries of recreated subnet not given up")
+	}
+}
+
+func TestSubnetReconcilerRemoveFinalizerOfActiveSubnet(t *testing.T) {
+	r := &SubnetReconciler{}
+	subnet := &networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{
+		Name:       "subnet1",
+		Finalizers: []string{constants.FinalizerSubnetProtection},
+	}}
+	if err := r.removeFinalizer(context.Background(), subnet); err == nil {
+		t.Fatalf("expected finalizer of subnet not being deleted kept")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+var _ = Describe("Subnet controller integration test suite", func() {
+	Context("Lock", func() {
+		testLock.Lock()
+	})
+
+	Context("Subnet protection finalizer", func() {
+		var networkName, subnetName string
+
+		BeforeEach(func() {
+			networkName = fmt.Sprintf("test-network-%s", uuid.NewUUID())
+			subnetName = fmt.Sprintf("test-subnet-%s", uuid.NewUUID())
+
+			By("create test overlay network")
+			network := overlayNetworkRender(networkName, 456)
+			Expect(k8sClient.Create(context.Background(), network)).NotTo(HaveOccurred())
+
+			By("create test subnet")
+			subnet := subnetRender(subnetName, networkName, "172.36.0.0/24", nil, false)
+			Expect(k8sClient.Create(context.Background(), subnet)).NotTo(HaveOccurred())
+		})
+
+		It("Subnet should be protected by finalizer and deleted if not referenced", func() {
+			By("check finalizer of subnet")
+			Eventually(
+				func(g Gomega) {
+					subnet := &networkingv1.Subnet{}
+					g.Expect(k8sClient.Get(context.Background(),
+						types.NamespacedName{
+							Name: subnetName,
+						},
+						subnet,
+					)).NotTo(HaveOccurred())
+					g.Expect(controllerutil.ContainsFinalizer(subnet, constants.FinalizerSubnetProtection)).To(BeTrue())
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+
+			By("delete subnet without any referencing IPInstance")
+			Expect(k8sClient.Delete(context.Background(), &networkingv1.Subnet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: subnetName,
+				},
+			})).NotTo(HaveOccurred())
+
+			By("check subnet deleted")
+			Eventually(
+				func(g Gomega) {
+					err := k8sClient.Get(context.Background(),
+						types.NamespacedName{
+							Name: subnetName,
+						},
+						&networkingv1.Subnet{},
+					)
+					g.Expect(err).NotTo(BeNil())
+					g.Expect(errors.IsNotFound(err)).To(BeTrue())
+				}).
+				WithTimeout(30 * time.Second).
+				WithPolling(time.Second).
+				Should(Succeed())
+		})
+
+		AfterEach(func() {
+			By("remove test network")
+			Expect(k8sClient.Delete(context.Background(), &networkingv1.Network{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: networkName,
+				},
+			})).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Unlock", func() {
+		testLock.Unlock()
+	})
+})
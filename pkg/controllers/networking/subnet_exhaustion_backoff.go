@@ -0,0 +1,87 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"sync"
+	"time"
+
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	subnetExhaustionBaseDelay = time.Second
+	subnetExhaustionMaxDelay  = 60 * time.Second
+	subnetExhaustionJitter    = 0.2
+)
+
+// subnetExhaustionBackoff calculates the requeue delays of pods which fail to allocate
+// IPs because of subnet exhaustion, the delays grow exponentially until the network
+// of pods has available addresses again
+type subnetExhaustionBackoff struct {
+	mu          sync.Mutex
+	rateLimiter workqueue.RateLimiter
+	// waitingPods records the network name which every backing-off pod is waiting for
+	waitingPods map[apitypes.NamespacedName]string
+}
+
+func newSubnetExhaustionBackoff() *subnetExhaustionBackoff {
+	return &subnetExhaustionBackoff{
+		rateLimiter: workqueue.NewItemExponentialFailureRateLimiter(subnetExhaustionBaseDelay, subnetExhaustionMaxDelay),
+		waitingPods: make(map[apitypes.NamespacedName]string),
+	}
+}
+
+// Next records a pod waiting for available addresses of network and returns its next requeue delay
+func (b *subnetExhaustionBackoff) Next(networkName string, pod apitypes.NamespacedName) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.waitingPods[pod] = networkName
+	return wait.Jitter(b.rateLimiter.When(pod), subnetExhaustionJitter)
+}
+
+// Forget stops tracking a pod and resets its backoff
+func (b *subnetExhaustionBackoff) Forget(pod apitypes.NamespacedName) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exist := b.waitingPods[pod]; !exist {
+		return
+	}
+	delete(b.waitingPods, pod)
+	b.rateLimiter.Forget(pod)
+}
+
+// Reset resets the backoff of all pods waiting for network and returns them
+func (b *subnetExhaustionBackoff) Reset(networkName string) []apitypes.NamespacedName {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var pods []apitypes.NamespacedName
+	for pod, waitingNetwork := range b.waitingPods {
+		if waitingNetwork != networkName {
+			continue
+		}
+		pods = append(pods, pod)
+		delete(b.waitingPods, pod)
+		b.rateLimiter.Forget(pod)
+	}
+	return pods
+}
@@ -0,0 +1,96 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+const (
+	ReasonSubnetExhausted = "SubnetExhausted"
+
+	subnetExhaustionEventInterval = 30 * time.Second
+)
+
+// subnetExhaustionEventAggregator aggregates the IP allocation failures caused by
+// subnet exhaustion, instead of one event for every failed pod, a single event is
+// emitted on the exhausted subnet (or network if no subnet is available) at most
+// once per interval
+type subnetExhaustionEventAggregator struct {
+	mu       sync.Mutex
+	recorder record.EventRecorder
+	interval time.Duration
+	now      func() time.Time
+	// waiting records the pods failing to get IPs from every exhausted object since last emission
+	waiting map[string]*exhaustionRecord
+}
+
+type exhaustionRecord struct {
+	lastEmitTime time.Time
+	pods         map[apitypes.NamespacedName]struct{}
+}
+
+func newSubnetExhaustionEventAggregator(recorder record.EventRecorder) *subnetExhaustionEventAggregator {
+	return &subnetExhaustionEventAggregator{
+		recorder: recorder,
+		interval: subnetExhaustionEventInterval,
+		now:      time.Now,
+		waiting:  make(map[string]*exhaustionRecord),
+	}
+}
+
+// RecordSubnet records a pod failing to allocate IP from an exhausted subnet
+func (a *subnetExhaustionEventAggregator) RecordSubnet(subnetName string, pod apitypes.NamespacedName) {
+	a.record(&networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: subnetName}}, "subnet", pod)
+}
+
+// RecordNetwork records a pod failing to allocate IP from a network whose subnets are all exhausted
+func (a *subnetExhaustionEventAggregator) RecordNetwork(networkName string, pod apitypes.NamespacedName) {
+	a.record(&networkingv1.Network{ObjectMeta: metav1.ObjectMeta{Name: networkName}}, "network", pod)
+}
+
+func (a *subnetExhaustionEventAggregator) record(obj client.Object, kind string, pod apitypes.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := kind + "/" + obj.GetName()
+	r, exist := a.waiting[key]
+	if !exist {
+		r = &exhaustionRecord{pods: make(map[apitypes.NamespacedName]struct{})}
+		a.waiting[key] = r
+	}
+	r.pods[pod] = struct{}{}
+
+	now := a.now()
+	if now.Sub(r.lastEmitTime) < a.interval {
+		return
+	}
+
+	a.recorder.Eventf(obj, corev1.EventTypeWarning, ReasonSubnetExhausted,
+		"%d pods waiting for IP in %s %s", len(r.pods), kind, obj.GetName())
+	r.lastEmitTime = now
+	r.pods = make(map[apitypes.NamespacedName]struct{})
+}
@@ -0,0 +1,128 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+)
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestSubnetExhaustionEventAggregator(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	now := time.Now()
+	a := newSubnetExhaustionEventAggregator(recorder)
+	a.now = func() time.Time { return now }
+
+	pod := func(name string) apitypes.NamespacedName {
+		return apitypes.NamespacedName{Namespace: "default", Name: name}
+	}
+
+	// the first failure is emitted immediately
+	a.RecordSubnet("subnet1", pod("pod1"))
+	events := drainEvents(recorder)
+	if len(events) != 1 || !strings.Contains(events[0], ReasonSubnetExhausted) ||
+		!strings.Contains(events[0], "1 pods waiting for IP in subnet subnet1") {
+		t.Fatalf("expect one aggregated event of subnet1 but got %v", events)
+	}
+
+	// failures within interval are aggregated, duplicated pods are counted once
+	a.RecordSubnet("subnet1", pod("pod2"))
+	a.RecordSubnet("subnet1", pod("pod3"))
+	a.RecordSubnet("subnet1", pod("pod3"))
+	if events = drainEvents(recorder); len(events) != 0 {
+		t.Fatalf("expect no event within interval but got %v", events)
+	}
+
+	// exhausted objects are aggregated separately
+	a.RecordNetwork("network1", pod("pod4"))
+	events = drainEvents(recorder)
+	if len(events) != 1 || !strings.Contains(events[0], "1 pods waiting for IP in network network1") {
+		t.Fatalf("expect one aggregated event of network1 but got %v", events)
+	}
+
+	now = now.Add(subnetExhaustionEventInterval)
+	a.RecordSubnet("subnet1", pod("pod5"))
+	events = drainEvents(recorder)
+	if len(events) != 1 || !strings.Contains(events[0], "3 pods waiting for IP in subnet subnet1") {
+		t.Fatalf("expect pods since last emission to be aggregated but got %v", events)
+	}
+}
+
+func TestRecordSubnetExhaustion(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &PodReconciler{subnetExhaustionEvents: newSubnetExhaustionEventAggregator(recorder)}
+	pod := apitypes.NamespacedName{Namespace: "default", Name: "pod1"}
+
+	tests := []struct {
+		name        string
+		err         error
+		networkName string
+		expected    string
+	}{
+		{
+			name:        "exhausted subnet known",
+			err:         fmt.Errorf("fail to allocate: %w", &ipamtypes.SubnetExhaustedError{Subnet: "subnet1"}),
+			networkName: "network1",
+			expected:    "in subnet subnet1",
+		},
+		{
+			name:        "exhausted subnet unknown",
+			err:         fmt.Errorf("fail to allocate: %w", ipamtypes.ErrSubnetExhausted),
+			networkName: "network1",
+			expected:    "in network network1",
+		},
+		{
+			name: "network unknown",
+			err:  fmt.Errorf("fail to allocate: %w", ipamtypes.ErrSubnetExhausted),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r.recordSubnetExhaustion(test.err, test.networkName, pod)
+			events := drainEvents(recorder)
+			if len(test.expected) == 0 {
+				if len(events) != 0 {
+					t.Fatalf("expect no event but got %v", events)
+				}
+				return
+			}
+			if len(events) != 1 || !strings.Contains(events[0], test.expected) {
+				t.Fatalf("expect event %q but got %v", test.expected, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"math"
+)
+
+const (
+	ReasonSubnetExhaustionImminent = "SubnetExhaustionImminent"
+
+	// subnetExhaustionImminentDays is the forecast below which subnet is warned to be expanded
+	subnetExhaustionImminentDays = 7
+
+	// subnetExhaustionForecastMinPoints is the minimum snapshots of history to forecast by, shorter
+	// histories are dominated by the fluctuation of a few hours
+	subnetExhaustionForecastMinPoints = 24
+)
+
+// ForecastDaysToExhaustion fits a linear regression to the available IPs of subnet in historyBuffer
+// at the corresponding hours, the oldest first, and estimates the days after the latest hour until no IP of subnet is
+// available. +Inf means the subnet is never exhausted at the current trend or the history is too
+// short to tell.
+func ForecastDaysToExhaustion(hours, historyBuffer []float64) float64 {
+	if len(hours) != len(historyBuffer) || len(historyBuffer) < subnetExhaustionForecastMinPoints {
+		return math.Inf(1)
+	}
+	n := float64(len(historyBuffer))
+
+	// least squares of available = intercept + slope * hour
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range historyBuffer {
+		x := hours[i]
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return math.Inf(1)
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	if slope >= 0 {
+		return math.Inf(1)
+	}
+	intercept := (sumY - slope*sumX) / n
+
+	remainingHours := -intercept/slope - hours[len(hours)-1]
+	if remainingHours <= 0 {
+		return 0
+	}
+	return remainingHours / 24
+}
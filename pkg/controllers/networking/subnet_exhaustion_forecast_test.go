@@ -0,0 +1,76 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"math"
+	"testing"
+)
+
+// hourlyTrend returns the available IPs of n consecutive hours, starting from start and
+// changing by delta per hour
+func hourlyTrend(n int, start, delta float64) ([]float64, []float64) {
+	hours := make([]float64, 0, n)
+	available := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		hours = append(hours, float64(i))
+		available = append(available, start+delta*float64(i))
+	}
+	return hours, available
+}
+
+func TestForecastDaysToExhaustion(t *testing.T) {
+	tooShortHours, tooShort := hourlyTrend(23, 100, -1)
+	steadyHours, steady := hourlyTrend(24, 100, 0)
+	releasedHours, released := hourlyTrend(24, 100, 1)
+	// one IP per hour, 48 available at the latest hour
+	linearHours, linear := hourlyTrend(24, 71, -1)
+	overdueHours, overdue := hourlyTrend(24, 10, -1)
+
+	// one IP per two hours snapshotted every two hours with a missing snapshot, 24 available at the latest hour
+	var gappedHours, gapped []float64
+	for i := 0; i <= 24; i++ {
+		if i != 10 {
+			gappedHours = append(gappedHours, float64(2*i))
+			gapped = append(gapped, 48-float64(i))
+		}
+	}
+
+	tests := []struct {
+		name      string
+		hours     []float64
+		available []float64
+		days      float64
+	}{
+		{"too short", tooShortHours, tooShort, math.Inf(1)},
+		{"mismatched", linearHours[1:], linear, math.Inf(1)},
+		{"steady", steadyHours, steady, math.Inf(1)},
+		{"released", releasedHours, released, math.Inf(1)},
+		{"linear", linearHours, linear, 2},
+		{"gapped", gappedHours, gapped, 2},
+		{"overdue", overdueHours, overdue, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if days := ForecastDaysToExhaustion(test.hours, test.available); math.Abs(days-test.days) > 1e-9 &&
+				!(math.IsInf(days, 1) && math.IsInf(test.days, 1)) {
+				t.Fatalf("expected %v days but got %v", test.days, days)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileRetryTracker tracks how long objects keep failing to reconcile with permanent errors,
+// retries of an object are given up after the retry timeout until the object is updated by someone
+type reconcileRetryTracker struct {
+	mu           sync.Mutex
+	retryTimeout time.Duration
+	failures     map[string]*reconcileFailures
+}
+
+type reconcileFailures struct {
+	// since is when the consecutive failures start
+	since   time.Time
+	givenUp bool
+	// revision is the uid, generation and annotations of object when failures are tracked,
+	// status updates are not taken as the intervention of human
+	revision string
+}
+
+// newReconcileRetryTracker creates a tracker, non-positive retry timeout means retrying forever
+func newReconcileRetryTracker(retryTimeout time.Duration) *reconcileRetryTracker {
+	return &reconcileRetryTracker{
+		retryTimeout: retryTimeout,
+		failures:     make(map[string]*reconcileFailures),
+	}
+}
+
+func revisionOf(obj client.Object) string {
+	// maps are printed in key-sorted order
+	return fmt.Sprintf("%s/%d/%v", obj.GetUID(), obj.GetGeneration(), obj.GetAnnotations())
+}
+
+// GivenUp checks if retries of object have been given up, tracking restarts once object is updated
+func (t *reconcileRetryTracker) GivenUp(obj client.Object) bool {
+	if t.retryTimeout <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failures, exist := t.failures[obj.GetName()]
+	if !exist {
+		return false
+	}
+	if failures.revision != revisionOf(obj) {
+		delete(t.failures, obj.GetName())
+		return false
+	}
+	return failures.givenUp
+}
+
+// Fail records a failed reconciliation of object at now, and returns true if retries are given up
+// right now because object has kept failing for the retry timeout
+func (t *reconcileRetryTracker) Fail(obj client.Object, now time.Time) bool {
+	if t.retryTimeout <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	revision := revisionOf(obj)
+	failures, exist := t.failures[obj.GetName()]
+	if !exist || failures.revision != revision {
+		failures = &reconcileFailures{since: now, revision: revision}
+		t.failures[obj.GetName()] = failures
+	}
+	if failures.givenUp || now.Sub(failures.since) < t.retryTimeout {
+		return false
+	}
+	failures.givenUp = true
+	return true
+}
+
+// Forget resets the failures of object
+func (t *reconcileRetryTracker) Forget(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, name)
+}
+
+// isTransientReconcileError checks if err is supposed to disappear by retrying, e.g., conflicts,
+// timeouts and throttling of apiserver, which are never tracked as failures
+func isTransientReconcileError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled)
+}
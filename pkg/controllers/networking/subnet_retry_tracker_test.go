@@ -0,0 +1,212 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+// patchFailingClient fails every patch of objects but not of status with err
+type patchFailingClient struct {
+	client.Client
+	err error
+}
+
+func (p patchFailingClient) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return p.err
+}
+
+func TestSubnetReconcilerGivesUpRetries(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1"},
+		Spec: networkingv1.SubnetSpec{
+			Network: "network1",
+			Range:   networkingv1.AddressRange{Version: networkingv1.IPv4, CIDR: "192.168.0.0/24"},
+		},
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SubnetReconciler{
+		Client:       patchFailingClient{Client: c, err: errors.New("permanent error")},
+		Recorder:     recorder,
+		RetryTimeout: time.Millisecond,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "subnet1"}}
+
+	getSubnet := func() *networkingv1.Subnet {
+		subnet := &networkingv1.Subnet{}
+		if err := c.Get(ctx, req.NamespacedName, subnet); err != nil {
+			t.Fatalf("fail to get subnet: %v", err)
+		}
+		return subnet
+	}
+
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatalf("expected failure returned for retrying")
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("expected retries given up but got %v", err)
+	}
+	if !meta.IsStatusConditionTrue(getSubnet().Status.Conditions, networkingv1.SubnetConditionReconcileFailed) {
+		t.Fatalf("expected condition %s set", networkingv1.SubnetConditionReconcileFailed)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected one warning event but got %d", len(recorder.Events))
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("expected no retry before subnet is updated but got %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected no more events but got %d", len(recorder.Events))
+	}
+
+	// human intervention resumes retries
+	subnet := getSubnet()
+	subnet.Annotations = map[string]string{"fixed": "true"}
+	if err := c.Update(ctx, subnet); err != nil {
+		t.Fatalf("fail to update subnet: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err == nil {
+		t.Fatalf("expected retries resumed after subnet is updated")
+	}
+
+	r.Client = c
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("fail to reconcile: %v", err)
+	}
+	if meta.IsStatusConditionTrue(getSubnet().Status.Conditions, networkingv1.SubnetConditionReconcileFailed) {
+		t.Fatalf("expected condition %s cleared after reconciliation succeeds", networkingv1.SubnetConditionReconcileFailed)
+	}
+}
+
+func TestSubnetReconcilerRetriesTransientErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1"},
+		Spec: networkingv1.SubnetSpec{
+			Network: "network1",
+			Range:   networkingv1.AddressRange{Version: networkingv1.IPv4, CIDR: "192.168.0.0/24"},
+		},
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SubnetReconciler{
+		Client:       patchFailingClient{Client: c, err: apierrors.NewTimeoutError("etcd is slow", 1)},
+		Recorder:     recorder,
+		RetryTimeout: time.Millisecond,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "subnet1"}}
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Reconcile(ctx, req); err == nil {
+			t.Fatalf("expected transient failure %d returned for retrying", i+1)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected retries of transient errors never given up but got %d events", len(recorder.Events))
+	}
+}
+
+func TestReconcileRetryTracker(t *testing.T) {
+	tracker := newReconcileRetryTracker(5 * time.Minute)
+	subnet := &networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1"}}
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 100; i++ {
+		if tracker.Fail(subnet, start.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("expected retries kept within retry timeout")
+		}
+	}
+	if !tracker.Fail(subnet, start.Add(5*time.Minute)) || !tracker.GivenUp(subnet) {
+		t.Fatalf("expected retries given up after retry timeout")
+	}
+	if tracker.Fail(subnet, start.Add(10*time.Minute)) {
+		t.Fatalf("expected retries given up only once")
+	}
+
+	// updated by human
+	subnet.Generation++
+	if tracker.GivenUp(subnet) || tracker.Fail(subnet, start.Add(11*time.Minute)) {
+		t.Fatalf("expected retries resumed after subnet is updated")
+	}
+
+	// successes reset the consecutive failures
+	tracker.Forget(subnet.Name)
+	if tracker.Fail(subnet, start.Add(16*time.Minute)) {
+		t.Fatalf("expected failures tracked again after success")
+	}
+}
+
+func TestReconcileRetryTrackerDisabled(t *testing.T) {
+	tracker := newReconcileRetryTracker(0)
+	subnet := &networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "subnet1"}}
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		if tracker.Fail(subnet, now.Add(time.Duration(i)*time.Hour)) || tracker.GivenUp(subnet) {
+			t.Fatalf("expected retrying forever if retry timeout is zero")
+		}
+	}
+}
+
+func TestIsTransientReconcileError(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{apierrors.NewConflict(networkingv1.GroupVersion.WithResource("subnets").GroupResource(), "subnet1", errors.New("modified")), true},
+		{apierrors.NewTimeoutError("etcd is slow", 1), true},
+		{apierrors.NewTooManyRequests("throttled", 1), true},
+		{fmt.Errorf("unable to add finalizer to subnet: %w", context.DeadlineExceeded), true},
+		{apierrors.NewBadRequest("invalid cidr"), false},
+		{errors.New("permanent error"), false},
+	}
+
+	for _, test := range tests {
+		if transient := isTransientReconcileError(test.err); transient != test.transient {
+			t.Errorf("expected transient %v of error %v but got %v", test.transient, test.err, transient)
+		}
+	}
+}
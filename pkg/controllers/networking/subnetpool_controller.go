@@ -0,0 +1,314 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
+	"github.com/alibaba/hybridnet/pkg/controllers/utils"
+)
+
+const ControllerSubnetPool = "SubnetPool"
+
+const (
+	ReasonSubnetCarved          = "SubnetCarved"
+	ReasonSubnetReclaimed       = "SubnetReclaimed"
+	ReasonInvalidSubnetMaskSize = "InvalidSubnetMaskSize"
+	ReasonSubnetPoolExhausted   = "SubnetPoolExhausted"
+)
+
+// subnetPoolMaxCarvingAttempts limits the blocks to be checked while carving a subnet, for pools
+// like an IPv6 /48 carved into /120s which have too many blocks to walk through
+const subnetPoolMaxCarvingAttempts = 1 << 16
+
+// SubnetPoolReconciler carves subnets from SubnetPool when IPs of carved subnets are used up,
+// and reclaims the empty ones if other carved subnets still have available IPs
+type SubnetPoolReconciler struct {
+	client.Client
+
+	Recorder record.EventRecorder
+
+	concurrency.ControllerConcurrency
+}
+
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=subnetpools,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=subnetpools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.alibaba.com,resources=subnets,verbs=get;list;watch;create;delete
+
+func (r *SubnetPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var err error
+	var pool = &networkingv1.SubnetPool{}
+	if err = r.Get(ctx, req.NamespacedName, pool); err != nil {
+		return ctrl.Result{}, wrapError("unable to fetch SubnetPool", client.IgnoreNotFound(err))
+	}
+
+	// carved subnets will be deleted by garbage collector after they are empty
+	if !pool.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	var network = &networkingv1.Network{}
+	if err = r.Get(ctx, client.ObjectKey{Name: pool.Spec.Network}, network); err != nil {
+		return ctrl.Result{}, wrapError("unable to fetch Network", err)
+	}
+
+	_, poolCIDR, err := net.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return ctrl.Result{}, wrapError("unable to parse cidr of SubnetPool", err)
+	}
+
+	if err = checkSubnetPoolMaskSize(pool, poolCIDR, network); err != nil {
+		// retrying is useless until the SubnetPool or Network is changed
+		r.Recorder.Event(pool, corev1.EventTypeWarning, ReasonInvalidSubnetMaskSize, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	var subnetList = &networkingv1.SubnetList{}
+	if err = r.List(ctx, subnetList); err != nil {
+		return ctrl.Result{}, wrapError("unable to list Subnets", err)
+	}
+
+	var poolSubnets []*networkingv1.Subnet
+	var existingCIDRs []*net.IPNet
+	var status = networkingv1.SubnetPoolStatus{LastScaleTime: pool.Status.LastScaleTime}
+	var pending bool
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		if _, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR); err == nil {
+			existingCIDRs = append(existingCIDRs, cidr)
+		}
+
+		if subnet.Labels[constants.LabelSubnetPool] != pool.Name || !subnet.DeletionTimestamp.IsZero() {
+			continue
+		}
+		poolSubnets = append(poolSubnets, subnet)
+		status.Subnets = append(status.Subnets, subnet.Name)
+		status.Total += subnet.Status.Total
+		status.Used += subnet.Status.Used
+		status.Available += subnet.Status.Available
+
+		// statistics of new subnet have not been reported by manager
+		if subnet.Status.Total == 0 {
+			pending = true
+		}
+	}
+	sort.Strings(status.Subnets)
+
+	if err = r.updateStatus(ctx, pool, &status); err != nil {
+		return ctrl.Result{}, wrapError("unable to update SubnetPool status", err)
+	}
+
+	// scaling decisions rely on statistics, the reconciliation will be triggered again once
+	// statistics of subnet are updated
+	if pending {
+		return ctrl.Result{}, nil
+	}
+
+	count := int32(len(poolSubnets))
+	switch {
+	case count < pool.Spec.MinSubnets ||
+		(status.Available == 0 && (pool.Spec.MaxSubnets == 0 || count < pool.Spec.MaxSubnets)):
+		return ctrl.Result{}, wrapError("unable to carve subnet", r.carve(ctx, pool, poolCIDR, network, existingCIDRs))
+	case count > pool.Spec.MinSubnets:
+		return ctrl.Result{}, wrapError("unable to reclaim subnet", r.reclaim(ctx, pool, poolSubnets, status.Available))
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *SubnetPoolReconciler) updateStatus(ctx context.Context, pool *networkingv1.SubnetPool,
+	status *networkingv1.SubnetPoolStatus) error {
+	if reflect.DeepEqual(&pool.Status, status) {
+		return nil
+	}
+
+	patch := client.MergeFrom(pool.DeepCopy())
+	pool.Status = *status
+	return r.Status().Patch(ctx, pool, patch)
+}
+
+func (r *SubnetPoolReconciler) carve(ctx context.Context, pool *networkingv1.SubnetPool, poolCIDR *net.IPNet,
+	network *networkingv1.Network, existingCIDRs []*net.IPNet) error {
+	index, cidr := carveSubnetCIDR(poolCIDR, int(pool.Spec.SubnetMaskSize), existingCIDRs)
+	if cidr == nil {
+		r.Recorder.Event(pool, corev1.EventTypeWarning, ReasonSubnetPoolExhausted,
+			"no more subnets can be carved from pool, all blocks are overlapped with existing subnets")
+		return nil
+	}
+
+	version := networkingv1.IPv4
+	if cidr.IP.To4() == nil {
+		version = networkingv1.IPv6
+	}
+
+	subnet := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%d", pool.Name, index),
+			Labels: map[string]string{
+				constants.LabelSubnetPool: pool.Name,
+			},
+		},
+		Spec: networkingv1.SubnetSpec{
+			Range: networkingv1.AddressRange{
+				Version: version,
+				CIDR:    cidr.String(),
+			},
+			NetID:   pool.Spec.NetID,
+			Network: pool.Spec.Network,
+		},
+	}
+
+	// pods of vlan networks need a gateway outside, which is assumed as the first IP of subnet
+	if networkingv1.GetNetworkMode(network) == networkingv1.NetworkModeVlan {
+		subnet.Spec.Range.Gateway = nthIP(cidr.IP, big.NewInt(1)).String()
+	}
+
+	if err := controllerutil.SetControllerReference(pool, subnet, r.Scheme()); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, subnet); err != nil {
+		return fmt.Errorf("unable to create subnet %s: %v", subnet.Name, err)
+	}
+
+	r.Recorder.Eventf(pool, corev1.EventTypeNormal, ReasonSubnetCarved, "subnet %s of %s is carved", subnet.Name, cidr)
+	return r.touchLastScaleTime(ctx, pool)
+}
+
+func (r *SubnetPoolReconciler) reclaim(ctx context.Context, pool *networkingv1.SubnetPool,
+	poolSubnets []*networkingv1.Subnet, available int32) error {
+	// reclaim the latest carved one first, an empty subnet is kept if it is the only one with
+	// available IPs, or it will be carved again for the next pod
+	sort.Slice(poolSubnets, func(i, j int) bool {
+		return networkingv1.GetIndexFromName(poolSubnets[i].Name) > networkingv1.GetIndexFromName(poolSubnets[j].Name)
+	})
+
+	for _, subnet := range poolSubnets {
+		if subnet.Status.Used > 0 || available-subnet.Status.Available <= 0 {
+			continue
+		}
+
+		// subnet is protected by finalizer if IPs are allocated from it concurrently
+		if err := r.Delete(ctx, subnet); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		r.Recorder.Eventf(pool, corev1.EventTypeNormal, ReasonSubnetReclaimed, "empty subnet %s is reclaimed", subnet.Name)
+		return r.touchLastScaleTime(ctx, pool)
+	}
+	return nil
+}
+
+func (r *SubnetPoolReconciler) touchLastScaleTime(ctx context.Context, pool *networkingv1.SubnetPool) error {
+	patch := client.MergeFrom(pool.DeepCopy())
+	pool.Status.LastScaleTime = metav1.Now()
+	return r.Status().Patch(ctx, pool, patch)
+}
+
+// checkSubnetPoolMaskSize checks if subnets of mask size can be carved from pool, and the mask size
+// is in the range restricted by network
+func checkSubnetPoolMaskSize(pool *networkingv1.SubnetPool, poolCIDR *net.IPNet, network *networkingv1.Network) error {
+	maskSize := pool.Spec.SubnetMaskSize
+	ones, bits := poolCIDR.Mask.Size()
+	if maskSize < int32(ones) || maskSize > int32(bits) {
+		return fmt.Errorf("mask size %d of subnets must be in range [%d, %d] of pool %s", maskSize, ones, bits, poolCIDR)
+	}
+	if network.Spec.MinSubnetMaskSize != nil && maskSize < *network.Spec.MinSubnetMaskSize {
+		return fmt.Errorf("mask size %d of subnets must not be smaller than %d of network %s",
+			maskSize, *network.Spec.MinSubnetMaskSize, network.Name)
+	}
+	if network.Spec.MaxSubnetMaskSize != nil && maskSize > *network.Spec.MaxSubnetMaskSize {
+		return fmt.Errorf("mask size %d of subnets must not be larger than %d of network %s",
+			maskSize, *network.Spec.MaxSubnetMaskSize, network.Name)
+	}
+	return nil
+}
+
+// carveSubnetCIDR returns the index and CIDR of the first block of mask size in pool, which is not
+// overlapped with any existing subnet, nil CIDR means no block is available
+func carveSubnetCIDR(poolCIDR *net.IPNet, maskSize int, existingCIDRs []*net.IPNet) (int64, *net.IPNet) {
+	ones, bits := poolCIDR.Mask.Size()
+	blockSize := new(big.Int).Lsh(big.NewInt(1), uint(bits-maskSize))
+	blocks := new(big.Int).Lsh(big.NewInt(1), uint(maskSize-ones))
+	if blocks.Cmp(big.NewInt(subnetPoolMaxCarvingAttempts)) > 0 {
+		blocks = big.NewInt(subnetPoolMaxCarvingAttempts)
+	}
+
+	for i := int64(0); i < blocks.Int64(); i++ {
+		candidate := &net.IPNet{
+			IP:   nthIP(poolCIDR.IP, new(big.Int).Mul(big.NewInt(i), blockSize)),
+			Mask: net.CIDRMask(maskSize, bits),
+		}
+
+		overlapped := false
+		for _, existing := range existingCIDRs {
+			if existing.Contains(candidate.IP) || candidate.Contains(existing.IP) {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			return i, candidate
+		}
+	}
+	return 0, nil
+}
+
+// nthIP returns the IP with offset n from base
+func nthIP(base net.IP, n *big.Int) net.IP {
+	if ipv4 := base.To4(); ipv4 != nil {
+		base = ipv4
+	}
+	sum := new(big.Int).Add(new(big.Int).SetBytes(base), n).Bytes()
+
+	ip := make(net.IP, len(base))
+	copy(ip[len(ip)-len(sum):], sum)
+	return ip
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SubnetPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerSubnetPool).
+		For(&networkingv1.SubnetPool{}, builder.WithPredicates(
+			&utils.IgnoreDeletePredicate{},
+			&predicate.GenerationChangedPredicate{},
+		)).
+		// statistics of carved subnets drive the scaling of pool
+		Owns(&networkingv1.Subnet{}).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Max(),
+			RecoverPanic:            true,
+		}).
+		Complete(r)
+}
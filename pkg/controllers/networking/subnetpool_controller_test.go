@@ -0,0 +1,211 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package networking
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestCarveSubnetCIDR(t *testing.T) {
+	_, pool, _ := net.ParseCIDR("10.0.0.0/22")
+	_, existing, _ := net.ParseCIDR("10.0.0.0/23")
+
+	index, cidr := carveSubnetCIDR(pool, 24, []*net.IPNet{existing})
+	if index != 2 || cidr.String() != "10.0.2.0/24" {
+		t.Fatalf("expected the third block carved but got %d, %v", index, cidr)
+	}
+
+	_, existing, _ = net.ParseCIDR("10.0.0.0/16")
+	if _, cidr = carveSubnetCIDR(pool, 24, []*net.IPNet{existing}); cidr != nil {
+		t.Fatalf("expected no block carved but got %v", cidr)
+	}
+
+	_, pool, _ = net.ParseCIDR("fd00::/48")
+	if index, cidr = carveSubnetCIDR(pool, 120, nil); index != 0 || cidr.String() != "fd00::/120" {
+		t.Fatalf("expected the first block carved but got %d, %v", index, cidr)
+	}
+}
+
+func TestSubnetPoolReconciler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	network := &networkingv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "underlay",
+		},
+		Spec: networkingv1.NetworkSpec{
+			Type:              networkingv1.NetworkTypeUnderlay,
+			Mode:              networkingv1.NetworkModeVlan,
+			NetID:             pointer.Int32(0),
+			MinSubnetMaskSize: pointer.Int32(24),
+		},
+	}
+	pool := &networkingv1.SubnetPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pool",
+		},
+		Spec: networkingv1.SubnetPoolSpec{
+			Network:        network.Name,
+			CIDR:           "10.0.0.0/22",
+			SubnetMaskSize: 24,
+			MaxSubnets:     2,
+		},
+	}
+	// subnets not carved from pool are avoided
+	manual := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "manual",
+		},
+		Spec: networkingv1.SubnetSpec{
+			Range: networkingv1.AddressRange{
+				Version: networkingv1.IPv4,
+				CIDR:    "10.0.1.0/24",
+			},
+			Network: network.Name,
+		},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(network, pool, manual).Build()
+	r := &SubnetPoolReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pool)}
+
+	setStatistics := func(name string, used, available int32) {
+		subnet := &networkingv1.Subnet{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, subnet); err != nil {
+			t.Fatalf("fail to get subnet %s: %v", name, err)
+		}
+		subnet.Status.Total, subnet.Status.Used, subnet.Status.Available = 253, used, available
+		if err := c.Status().Update(ctx, subnet); err != nil {
+			t.Fatalf("fail to update subnet %s: %v", name, err)
+		}
+	}
+	reconcile := func() *networkingv1.SubnetPool {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("fail to reconcile: %v", err)
+		}
+		if err := c.Get(ctx, req.NamespacedName, pool); err != nil {
+			t.Fatalf("fail to get pool: %v", err)
+		}
+		return pool
+	}
+
+	// the first subnet is carved for an empty pool
+	reconcile()
+	first := &networkingv1.Subnet{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "pool-0"}, first); err != nil {
+		t.Fatalf("fail to get carved subnet: %v", err)
+	}
+	if first.Spec.Range.CIDR != "10.0.0.0/24" || first.Spec.Range.Gateway != "10.0.0.1" {
+		t.Fatalf("unexpected carved subnet %v", first.Spec.Range)
+	}
+
+	// nothing is carved before statistics of subnet are reported
+	if pool = reconcile(); !reflect.DeepEqual(pool.Status.Subnets, []string{"pool-0"}) {
+		t.Fatalf("expected only one subnet in pool but got %v", pool.Status.Subnets)
+	}
+
+	// carve another subnet after IPs are used up
+	setStatistics("pool-0", 253, 0)
+	reconcile()
+	setStatistics("pool-2", 0, 253)
+	if pool = reconcile(); !reflect.DeepEqual(pool.Status.Subnets, []string{"pool-0", "pool-2"}) ||
+		pool.Status.Available != 253 {
+		t.Fatalf("expected two subnets in pool but got %v", pool.Status)
+	}
+
+	// no more subnets are carved out of max subnets
+	setStatistics("pool-2", 253, 0)
+	if pool = reconcile(); len(pool.Status.Subnets) != 2 {
+		t.Fatalf("expected max subnets respected but got %v", pool.Status.Subnets)
+	}
+
+	// the empty subnet is reclaimed while the other one still has available IPs
+	setStatistics("pool-0", 10, 243)
+	setStatistics("pool-2", 0, 253)
+	reconcile()
+	if err := c.Get(ctx, client.ObjectKey{Name: "pool-2"}, &networkingv1.Subnet{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected subnet reclaimed but got %v", err)
+	}
+
+	// the only subnet is kept even if it is empty
+	setStatistics("pool-0", 0, 253)
+	if pool = reconcile(); !reflect.DeepEqual(pool.Status.Subnets, []string{"pool-0"}) {
+		t.Fatalf("expected the only subnet kept but got %v", pool.Status.Subnets)
+	}
+}
+
+func TestSubnetPoolReconcilerInvalidMaskSize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	network := &networkingv1.Network{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "overlay",
+		},
+		Spec: networkingv1.NetworkSpec{
+			Type:              networkingv1.NetworkTypeOverlay,
+			MaxSubnetMaskSize: pointer.Int32(24),
+		},
+	}
+	pool := &networkingv1.SubnetPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pool",
+		},
+		Spec: networkingv1.SubnetPoolSpec{
+			Network:        network.Name,
+			CIDR:           "10.0.0.0/16",
+			SubnetMaskSize: 26,
+		},
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(network, pool).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &SubnetPoolReconciler{Client: c, Recorder: recorder}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pool)}); err != nil {
+		t.Fatalf("fail to reconcile: %v", err)
+	}
+
+	subnetList := &networkingv1.SubnetList{}
+	if err := c.List(ctx, subnetList); err != nil || len(subnetList.Items) != 0 {
+		t.Fatalf("expected no subnet carved but got %v, %v", subnetList.Items, err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected a warning event of invalid mask size")
+	}
+}
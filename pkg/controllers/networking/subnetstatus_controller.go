@@ -19,9 +19,10 @@ package networking
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
@@ -40,6 +41,7 @@ import (
 	"github.com/alibaba/hybridnet/pkg/controllers/concurrency"
 	"github.com/alibaba/hybridnet/pkg/controllers/utils"
 	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	"github.com/alibaba/hybridnet/pkg/metrics"
 )
 
 const ControllerSubnetStatus = "SubnetStatus"
@@ -53,6 +55,10 @@ type SubnetStatusReconciler struct {
 
 	SubnetStatusUpdateChan <-chan event.GenericEvent
 
+	// Shard is the network shard of manager instance, only networks owned by
+	// the shard are reconciled
+	Shard NetworkShard
+
 	concurrency.ControllerConcurrency
 }
 
@@ -75,7 +81,14 @@ func (r *SubnetStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}()
 
 	if err = r.Get(ctx, req.NamespacedName, subnet); err != nil {
-		return ctrl.Result{}, wrapError("unable to fetch Subnet", client.IgnoreNotFound(err))
+		if err = client.IgnoreNotFound(err); err == nil {
+			deleteSubnetMetrics(req.Name)
+		}
+		return ctrl.Result{}, wrapError("unable to fetch Subnet", err)
+	}
+
+	if !r.Shard.Owns(subnet.Spec.Network) {
+		return ctrl.Result{}, nil
 	}
 
 	// fetch subnet usage from manager
@@ -91,10 +104,36 @@ func (r *SubnetStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			Available: int32(usage.Available),
 		},
 		LastAllocatedIP: usage.LastAllocation,
+		// allocation and release time are only recorded in memory of IPAM manager,
+		// keep the previous ones if nothing happens after manager is rebuilt
+		LastAllocationTime: pickLatestTime(subnet.Status.LastAllocationTime, usage.LastAllocationTime),
+		LastReleaseTime:    pickLatestTime(subnet.Status.LastReleaseTime, usage.LastReleaseTime),
+		// conditions are maintained by subnet controller
+		Conditions: subnet.Status.Conditions,
+	}
+
+	var ipFamily = metrics.IPv4
+	if networkingv1.IsIPv6Subnet(subnet) {
+		ipFamily = metrics.IPv6
+		subnetStatus.TotalIPv6, subnetStatus.UsedIPv6 = subnetStatus.Total, subnetStatus.Used
+	} else {
+		subnetStatus.TotalIPv4, subnetStatus.UsedIPv4 = subnetStatus.Total, subnetStatus.Used
+	}
+
+	metrics.SubnetIPUsageGauge.WithLabelValues(subnet.Name, ipFamily, metrics.IPTotalUsageType).
+		Set(float64(usage.Total))
+	metrics.SubnetIPUsageGauge.WithLabelValues(subnet.Name, ipFamily, metrics.IPUsedUsageType).
+		Set(float64(usage.Used))
+	metrics.SubnetIPUsageGauge.WithLabelValues(subnet.Name, ipFamily, metrics.IPAvailableUsageType).
+		Set(float64(usage.Available))
+
+	if !subnetStatus.LastAllocationTime.IsZero() {
+		metrics.SubnetLastAllocationGauge.WithLabelValues(subnet.Name, ipFamily).
+			Set(float64(subnetStatus.LastAllocationTime.Unix()))
 	}
 
 	// diff for no-op
-	if reflect.DeepEqual(&subnet.Status, subnetStatus) {
+	if subnetStatusEqual(&subnet.Status, subnetStatus) {
 		log.V(1).Info("subnet status is up-to-date, skip updating")
 		return ctrl.Result{}, nil
 	}
@@ -114,6 +153,36 @@ func (r *SubnetStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// pickLatestTime returns the later one of status time and recorded time, status time
+// has the precision of seconds, so recorded time will be truncated to seconds too
+func pickLatestTime(statusTime metav1.Time, recordedTime time.Time) metav1.Time {
+	recordedTime = recordedTime.Truncate(time.Second)
+	if recordedTime.After(statusTime.Time) {
+		return metav1.NewTime(recordedTime)
+	}
+	return statusTime
+}
+
+// deleteSubnetMetrics deletes metrics of a removed subnet, the ip family of which is unknown
+// any more, so metrics of both families are deleted
+func deleteSubnetMetrics(subnetName string) {
+	for _, ipFamily := range []string{metrics.IPv4, metrics.IPv6} {
+		metrics.SubnetLastAllocationGauge.DeleteLabelValues(subnetName, ipFamily)
+		for _, usageType := range []string{metrics.IPTotalUsageType, metrics.IPUsedUsageType, metrics.IPAvailableUsageType} {
+			metrics.SubnetIPUsageGauge.DeleteLabelValues(subnetName, ipFamily, usageType)
+		}
+	}
+}
+
+func subnetStatusEqual(a, b *networkingv1.SubnetStatus) bool {
+	return a.Count == b.Count &&
+		a.TotalIPv4 == b.TotalIPv4 && a.UsedIPv4 == b.UsedIPv4 &&
+		a.TotalIPv6 == b.TotalIPv6 && a.UsedIPv6 == b.UsedIPv6 &&
+		a.LastAllocatedIP == b.LastAllocatedIP &&
+		a.LastAllocationTime.Equal(&b.LastAllocationTime) &&
+		a.LastReleaseTime.Equal(&b.LastReleaseTime)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *SubnetStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -127,6 +127,8 @@ var _ = Describe("Subnet status controller integration test suite", func() {
 					g.Expect(currentIPv4Subnet.Status.Total).To(Equal(basicIPQuantity - networkAddress - broadcastAddress - gatewayAddress))
 					g.Expect(currentIPv4Subnet.Status.Available).To(Equal(currentIPv4Subnet.Status.Total))
 					g.Expect(currentIPv4Subnet.Status.Used).To(Equal(int32(0)))
+					g.Expect(currentIPv4Subnet.Status.TotalIPv4).To(Equal(currentIPv4Subnet.Status.Total))
+					g.Expect(currentIPv4Subnet.Status.TotalIPv6).To(Equal(int32(0)))
 
 					currentIPv6Subnet := &networkingv1.Subnet{}
 					g.Expect(k8sClient.Get(context.Background(),
@@ -139,6 +141,8 @@ var _ = Describe("Subnet status controller integration test suite", func() {
 					g.Expect(currentIPv6Subnet.Status.Total).To(Equal(basicIPQuantity - networkAddress))
 					g.Expect(currentIPv6Subnet.Status.Available).To(Equal(currentIPv6Subnet.Status.Total))
 					g.Expect(currentIPv6Subnet.Status.Used).To(Equal(int32(0)))
+					g.Expect(currentIPv6Subnet.Status.TotalIPv6).To(Equal(currentIPv6Subnet.Status.Total))
+					g.Expect(currentIPv6Subnet.Status.TotalIPv4).To(Equal(int32(0)))
 				}).
 				WithTimeout(30 * time.Second).
 				WithPolling(time.Second).
@@ -135,6 +135,8 @@ var _ = BeforeSuite(func() {
 			ipamManager, err = networking.NewIPAMManager(ctx, c)
 			return ipamManager, err
 		},
+		EnableSchemaMigration: true,
+		ControllerInstance:    "manager-0",
 	})).NotTo(HaveOccurred())
 
 	// An underlay network and an overlay network.
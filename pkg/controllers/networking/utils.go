@@ -19,6 +19,8 @@ package networking
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
+
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -61,6 +63,12 @@ func InitIndexers(mgr ctrl.Manager) (err error) {
 		return err
 	}
 
+	// init pending node indexer for pods
+	if err = mgr.GetFieldIndexer().IndexField(context.TODO(), &corev1.Pod{},
+		IndexerFieldPendingNode, pendingNodeIndexFunc); err != nil {
+		return err
+	}
+
 	// init network indexer for Subnets
 	return mgr.GetFieldIndexer().IndexField(context.TODO(), &networkingv1.Subnet{},
 		IndexerFieldNetwork, func(obj client.Object) []string {
@@ -19,6 +19,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"net"
 
 	"github.com/alibaba/hybridnet/pkg/utils/transform"
 
@@ -84,6 +85,35 @@ func ListIPInstances(ctx context.Context, client client.Reader, opts ...client.L
 	return &ipList, nil
 }
 
+// GetIPInstanceByAddress gets the IPInstance of an IP address by label-selecting, nil will be
+// returned if no IPInstance is found. IPInstances created before the ip address label is introduced
+// are only labeled by schema migration, callers which may meet them should look up by address instead.
+func GetIPInstanceByAddress(ctx context.Context, c client.Reader, ip net.IP) (*networkingv1.IPInstance, error) {
+	ipList, err := ListIPInstances(ctx, c, client.MatchingLabels{
+		constants.LabelIPAddress: ToDNSFormat(ip),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IPInstances of ip %v: %v", ip, err)
+	}
+
+	switch len(ipList.Items) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &ipList.Items[0], nil
+	default:
+		return nil, fmt.Errorf("get more than one IPInstance for ip %v", ip)
+	}
+}
+
+func ListIPBlockReservations(ctx context.Context, client client.Reader, opts ...client.ListOption) (*networkingv1.IPBlockReservationList, error) {
+	var reservationList = networkingv1.IPBlockReservationList{}
+	if err := client.List(ctx, &reservationList, opts...); err != nil {
+		return nil, err
+	}
+	return &reservationList, nil
+}
+
 func ListActiveNodesToNames(ctx context.Context, client client.Reader, opts ...client.ListOption) ([]string, error) {
 	var nodeList = corev1.NodeList{}
 	if err := client.List(ctx, &nodeList, opts...); err != nil {
@@ -51,6 +51,12 @@ func PodIsScheduled(pod *v1.Pod) bool {
 	return len(pod.Spec.NodeName) > 0
 }
 
+// PodHasOnlyInitContainers means pod has init containers but no regular containers,
+// e.g., some pods rendered by webhooks injecting init containers only
+func PodHasOnlyInitContainers(pod *v1.Pod) bool {
+	return len(pod.Spec.InitContainers) > 0 && len(pod.Spec.Containers) == 0
+}
+
 func PodIsTerminated(pod *v1.Pod) bool {
 	for i := range pod.Status.ContainerStatuses {
 		if pod.Status.ContainerStatuses[i].State.Terminated == nil {
@@ -62,3 +68,16 @@ func PodIsTerminated(pod *v1.Pod) bool {
 }
 
 var ParseNetworkConfigOfPodByPriority = utils.ParseNetworkConfigOfPodByPriority
+
+// PodDNSHostname returns the hostname of pod registered in DNS, which is the hostname of pod
+// spec or pod name, with the subdomain of pod spec appended if specified
+func PodDNSHostname(pod *v1.Pod) string {
+	hostname := pod.Spec.Hostname
+	if len(hostname) == 0 {
+		hostname = pod.Name
+	}
+	if len(pod.Spec.Subdomain) > 0 {
+		hostname = hostname + "." + pod.Spec.Subdomain
+	}
+	return hostname
+}
@@ -186,6 +186,41 @@ func (s SpecifiedLabelChangedPredicate) Update(e event.UpdateEvent) bool {
 	return false
 }
 
+// SpecifiedLabelExistPredicate only passes the create and delete events of objects which have
+// any of the specified labels, updates are ignored
+type SpecifiedLabelExistPredicate struct {
+	predicate.Funcs
+	LabelKeys []string
+}
+
+func (s SpecifiedLabelExistPredicate) Create(e event.CreateEvent) bool {
+	return s.hasLabel(e.Object)
+}
+
+func (s SpecifiedLabelExistPredicate) Delete(e event.DeleteEvent) bool {
+	return s.hasLabel(e.Object)
+}
+
+func (SpecifiedLabelExistPredicate) Update(e event.UpdateEvent) bool {
+	return false
+}
+
+func (SpecifiedLabelExistPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
+
+func (s SpecifiedLabelExistPredicate) hasLabel(object client.Object) bool {
+	if object == nil {
+		return false
+	}
+	for _, labelKey := range s.LabelKeys {
+		if _, exist := object.GetLabels()[labelKey]; exist {
+			return true
+		}
+	}
+	return false
+}
+
 type RemoteClusterUUIDChangePredicate struct {
 	predicate.Funcs
 }
@@ -26,17 +26,20 @@ import (
 )
 
 func NewRestConfigFromRemoteCluster(remoteCluster *multiclusterv1.RemoteCluster) (*rest.Config, error) {
-	clusterConfig, err := clientcmd.BuildConfigFromFlags(remoteCluster.Spec.APIEndpoint, "")
+	// API endpoint always overrides the server in kubeconfig file
+	clusterConfig, err := clientcmd.BuildConfigFromFlags(remoteCluster.Spec.APIEndpoint, remoteCluster.Spec.KubeConfigFile)
 	if err != nil {
 		return nil, err
 	}
 
-	clusterConfig.CAData = make([]byte, len(remoteCluster.Spec.CAData))
-	copy(clusterConfig.CAData, remoteCluster.Spec.CAData)
-	clusterConfig.CertData = make([]byte, len(remoteCluster.Spec.CertData))
-	copy(clusterConfig.CertData, remoteCluster.Spec.CertData)
-	clusterConfig.KeyData = make([]byte, len(remoteCluster.Spec.KeyData))
-	copy(clusterConfig.KeyData, remoteCluster.Spec.KeyData)
+	if len(remoteCluster.Spec.KubeConfigFile) == 0 {
+		clusterConfig.CAData = make([]byte, len(remoteCluster.Spec.CAData))
+		copy(clusterConfig.CAData, remoteCluster.Spec.CAData)
+		clusterConfig.CertData = make([]byte, len(remoteCluster.Spec.CertData))
+		copy(clusterConfig.CertData, remoteCluster.Spec.CertData)
+		clusterConfig.KeyData = make([]byte, len(remoteCluster.Spec.KeyData))
+		copy(clusterConfig.KeyData, remoteCluster.Spec.KeyData)
+	}
 
 	// TODO: insecure mode
 
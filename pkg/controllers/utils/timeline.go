@@ -0,0 +1,106 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+type AllocationPhase string
+
+const (
+	AllocationPhaseQueued              AllocationPhase = "queued"
+	AllocationPhaseIPAMLockAcquired    AllocationPhase = "ipam-lock-acquired"
+	AllocationPhaseIPAMAllocated       AllocationPhase = "ipam-allocated"
+	AllocationPhaseIPInstanceCreated   AllocationPhase = "ipinstance-created"
+	AllocationPhaseInterfaceConfigured AllocationPhase = "interface-configured"
+)
+
+// AllocationTimeline records when each phase of IP allocation of pod is reached, the first
+// four phases are recorded by manager and the last one is recorded by daemon
+type AllocationTimeline struct {
+	Queued              *time.Time `json:"queued,omitempty"`
+	IPAMLockAcquired    *time.Time `json:"ipam-lock-acquired,omitempty"`
+	IPAMAllocated       *time.Time `json:"ipam-allocated,omitempty"`
+	IPInstanceCreated   *time.Time `json:"ipinstance-created,omitempty"`
+	InterfaceConfigured *time.Time `json:"interface-configured,omitempty"`
+}
+
+// Record sets the time of phase, it is a no-op on nil timeline so that callers
+// need not check whether the timeline is enabled
+func (t *AllocationTimeline) Record(phase AllocationPhase, at time.Time) {
+	if t == nil {
+		return
+	}
+
+	switch phase {
+	case AllocationPhaseQueued:
+		t.Queued = &at
+	case AllocationPhaseIPAMLockAcquired:
+		t.IPAMLockAcquired = &at
+	case AllocationPhaseIPAMAllocated:
+		t.IPAMAllocated = &at
+	case AllocationPhaseIPInstanceCreated:
+		t.IPInstanceCreated = &at
+	case AllocationPhaseInterfaceConfigured:
+		t.InterfaceConfigured = &at
+	}
+}
+
+// RecordNow sets the time of phase to now
+func (t *AllocationTimeline) RecordNow(phase AllocationPhase) {
+	t.Record(phase, time.Now())
+}
+
+// String returns the json format of timeline, which is the value of annotation
+func (t *AllocationTimeline) String() string {
+	content, _ := json.Marshal(t)
+	return string(content)
+}
+
+// ParseAllocationTimeline parses the allocation timeline from annotation of pod,
+// nil will be returned if pod has no timeline
+func ParseAllocationTimeline(pod *v1.Pod) (*AllocationTimeline, error) {
+	value, exist := pod.Annotations[constants.AnnotationIPAllocationTimeline]
+	if !exist {
+		return nil, nil
+	}
+
+	timeline := &AllocationTimeline{}
+	if err := json.Unmarshal([]byte(value), timeline); err != nil {
+		return nil, fmt.Errorf("invalid allocation timeline %q: %v", value, err)
+	}
+	return timeline, nil
+}
+
+// PodQueuedTime returns when pod becomes eligible for IP allocation, which is the time pod
+// is scheduled, now will be returned if the scheduled condition is not found
+func PodQueuedTime(pod *v1.Pod) time.Time {
+	for i := range pod.Status.Conditions {
+		condition := &pod.Status.Conditions[i]
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionTrue && !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Now()
+}
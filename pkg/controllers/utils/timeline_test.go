@@ -0,0 +1,90 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestAllocationTimeline(t *testing.T) {
+	// recording on nil timeline should not panic
+	var disabled *AllocationTimeline
+	disabled.RecordNow(AllocationPhaseQueued)
+
+	queued := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeline := &AllocationTimeline{}
+	timeline.Record(AllocationPhaseQueued, queued)
+	timeline.Record(AllocationPhaseIPAMAllocated, queued.Add(time.Second))
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				constants.AnnotationIPAllocationTimeline: timeline.String(),
+			},
+		},
+	}
+
+	parsed, err := ParseAllocationTimeline(pod)
+	if err != nil {
+		t.Fatalf("fail to parse timeline: %v", err)
+	}
+	if parsed.Queued == nil || !parsed.Queued.Equal(queued) {
+		t.Fatalf("expected queued at %v but got %v", queued, parsed.Queued)
+	}
+	if parsed.IPAMLockAcquired != nil || parsed.InterfaceConfigured != nil {
+		t.Fatalf("unrecorded phases should be nil")
+	}
+
+	pod.Annotations[constants.AnnotationIPAllocationTimeline] = "invalid"
+	if _, err = ParseAllocationTimeline(pod); err == nil {
+		t.Fatalf("expected error for invalid timeline")
+	}
+
+	delete(pod.Annotations, constants.AnnotationIPAllocationTimeline)
+	if parsed, err = ParseAllocationTimeline(pod); parsed != nil || err != nil {
+		t.Fatalf("expected nil timeline without annotation, but got %v, %v", parsed, err)
+	}
+}
+
+func TestPodQueuedTime(t *testing.T) {
+	scheduled := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod := &v1.Pod{
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{
+				{
+					Type:               v1.PodScheduled,
+					Status:             v1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(scheduled),
+				},
+			},
+		},
+	}
+	if queued := PodQueuedTime(pod); !queued.Equal(scheduled) {
+		t.Fatalf("expected %v but got %v", scheduled, queued)
+	}
+
+	pod.Status.Conditions = nil
+	if queued := PodQueuedTime(pod); time.Since(queued) > time.Minute {
+		t.Fatalf("expected now but got %v", queued)
+	}
+}
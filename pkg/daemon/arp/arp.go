@@ -17,18 +17,32 @@
 package arp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"syscall"
 	"time"
 
 	"github.com/mdlayher/ethernet"
+	"golang.org/x/time/rate"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const (
+	eagainMaxRetries = 5
+	eagainRetryDelay = 50 * time.Millisecond
 )
 
+// ping is replaceable for tests
+var ping = pingOverInterface
+
 // CheckWithTimeout checks vlan network environment and duplicate ip problems,
 // timeout parameter determines how long this function will exactly last.
 func CheckWithTimeout(ifi *net.Interface, srcPod, gateway net.IP, timeout time.Duration) error {
 	// Resolve gateway ip for vlan check.
-	if _, err := pingOverInterface(srcPod, gateway, ifi, timeout); err != nil {
+	if _, err := pingWithRetry(srcPod, gateway, ifi, timeout); err != nil {
 		return fmt.Errorf("failed to resolve arp from pod %v to gateway %v: %v"+
 			", vlan network seems not working, please check the setting of %v's upper physical switch port first",
 			srcPod.String(), gateway.String(), err, ifi.Name)
@@ -36,7 +50,7 @@ func CheckWithTimeout(ifi *net.Interface, srcPod, gateway net.IP, timeout time.D
 
 	// Resolve src pod ip for duplicate ip check and send gratuitous arp.
 	// Src ip should be 0.0.0.0 for arp probe.
-	if duplicatedHw, err := pingOverInterface(net.ParseIP("0.0.0.0"), srcPod, ifi, timeout); err == nil {
+	if duplicatedHw, err := pingWithRetry(net.ParseIP("0.0.0.0"), srcPod, ifi, timeout); err == nil {
 		return fmt.Errorf("pod ip %v duplicated"+
 			", please check if ip %v is occupied by other machines or containers, another hw addr is %v",
 			srcPod.String(), srcPod.String(), duplicatedHw.String())
@@ -50,10 +64,31 @@ func CheckWithTimeout(ifi *net.Interface, srcPod, gateway net.IP, timeout time.D
 	return nil
 }
 
+// ResolveWithTimeout resolves the hardware address of ip over interface with an arp probe.
+func ResolveWithTimeout(ifi *net.Interface, ip net.IP, timeout time.Duration) (net.HardwareAddr, error) {
+	return pingOverInterface(net.ParseIP("0.0.0.0"), ip, ifi, timeout)
+}
+
+// pingWithRetry retries the ping if raw socket returns EAGAIN, which is transient on high-traffic
+// interfaces. Every attempt only waits for the rest of timeout, so the overall deadline is respected.
+func pingWithRetry(srcIP, dstIP net.IP, iif *net.Interface, timeout time.Duration) (net.HardwareAddr, error) {
+	deadline := time.Now().Add(timeout)
+	for retries := 0; ; retries++ {
+		hw, err := ping(srcIP, dstIP, iif, time.Until(deadline))
+		if err == nil || !errors.Is(err, syscall.EAGAIN) ||
+			retries == eagainMaxRetries || time.Until(deadline) <= eagainRetryDelay {
+			return hw, err
+		}
+
+		metrics.ARPEAGAINRetriesCounter.Inc()
+		time.Sleep(eagainRetryDelay)
+	}
+}
+
 func pingOverInterface(srcIP, dstIP net.IP, iif *net.Interface, timeout time.Duration) (net.HardwareAddr, error) {
 	client, err := Dial(iif, srcIP)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init client with ip %v interface %v: %v", srcIP.String(), iif.Name, err)
+		return nil, fmt.Errorf("failed to init client with ip %v interface %v: %w", srcIP.String(), iif.Name, err)
 	}
 
 	defer func() {
@@ -66,7 +101,7 @@ func pingOverInterface(srcIP, dstIP net.IP, iif *net.Interface, timeout time.Dur
 
 	hw, err := client.Resolve(dstIP)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve dst ip %v: %v", dstIP.String(), err)
+		return nil, fmt.Errorf("failed to resolve dst ip %v: %w", dstIP.String(), err)
 	}
 
 	return hw, nil
@@ -82,8 +117,39 @@ func gratuitousOverInterface(ip net.IP, iif *net.Interface) error {
 		_ = client.Close()
 	}()
 
+	return sendGratuitous(client, ip)
+}
+
+// GratuitousBatch sends gratuitous arps for a batch of ips over one interface through a shared raw socket,
+// limiter restricts the rate of ips being announced and callback will be called after each ip is announced.
+func GratuitousBatch(ctx context.Context, iif *net.Interface, ips []net.IP, limiter *rate.Limiter,
+	callback func(ip net.IP, err error)) error {
+	if len(ips) == 0 {
+		return nil
+	}
+
+	client, err := Dial(iif, ips[0])
+	if err != nil {
+		return fmt.Errorf("failed to init client with interface %v: %v", iif.Name, err)
+	}
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	for _, ip := range ips {
+		if err := limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("failed to wait for rate limiter: %v", err)
+		}
+		callback(ip, sendGratuitous(client, ip))
+	}
+
+	return nil
+}
+
+func sendGratuitous(client *Client, ip net.IP) error {
 	for _, op := range []Operation{OperationRequest, OperationReply} {
-		arp, err := NewPacket(op, client.ifi.HardwareAddr, client.ip, ethernet.Broadcast, ip)
+		arp, err := NewPacket(op, client.ifi.HardwareAddr, ip, ethernet.Broadcast, ip)
 		if err != nil {
 			return fmt.Errorf("failed create arp packet: %v", err)
 		}
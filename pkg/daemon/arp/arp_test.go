@@ -0,0 +1,101 @@
+/*
+ Copyright 2021 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package arp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestPingWithRetry(t *testing.T) {
+	defer func() {
+		ping = pingOverInterface
+	}()
+
+	hwAddr, _ := net.ParseMAC("00:00:00:00:00:01")
+	tests := []struct {
+		name            string
+		failures        int
+		err             error
+		timeout         time.Duration
+		expectedAttempt int
+		expectSuccess   bool
+	}{
+		{
+			name:            "succeed after transient eagain",
+			failures:        2,
+			err:             syscall.EAGAIN,
+			timeout:         time.Second,
+			expectedAttempt: 3,
+			expectSuccess:   true,
+		},
+		{
+			name:            "give up after max retries",
+			failures:        10,
+			err:             syscall.EAGAIN,
+			timeout:         time.Second,
+			expectedAttempt: eagainMaxRetries + 1,
+		},
+		{
+			name:            "no retry for other errors",
+			failures:        1,
+			err:             syscall.ENETDOWN,
+			timeout:         time.Second,
+			expectedAttempt: 1,
+		},
+		{
+			name:            "no retry out of deadline",
+			failures:        10,
+			err:             syscall.EAGAIN,
+			timeout:         eagainRetryDelay,
+			expectedAttempt: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int
+			var lastTimeout time.Duration
+			ping = func(srcIP, dstIP net.IP, iif *net.Interface, timeout time.Duration) (net.HardwareAddr, error) {
+				attempts++
+				if lastTimeout != 0 && timeout >= lastTimeout {
+					t.Errorf("expect timeout of attempts decreasing, got %v after %v", timeout, lastTimeout)
+				}
+				lastTimeout = timeout
+				if attempts <= test.failures {
+					return nil, fmt.Errorf("failed to resolve dst ip %v: %w", dstIP, test.err)
+				}
+				return hwAddr, nil
+			}
+
+			hw, err := pingWithRetry(net.ParseIP("0.0.0.0"), net.ParseIP("192.168.0.1"), &net.Interface{}, test.timeout)
+			if attempts != test.expectedAttempt {
+				t.Errorf("expect %d attempts, got %d", test.expectedAttempt, attempts)
+			}
+			if test.expectSuccess && (err != nil || hw.String() != hwAddr.String()) {
+				t.Errorf("expect success, got %v, %v", hw, err)
+			}
+			if !test.expectSuccess && !errors.Is(err, test.err) {
+				t.Errorf("expect error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package arp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// InstallStaticNeighbor installs a permanent neigh entry of ip on interface, which works
+// like "ip neigh replace <ip> lladdr <mac> dev <iface> nud permanent"
+func InstallStaticNeighbor(iface string, ip net.IP, mac net.HardwareAddr) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("failed to get link %v: %v", iface, err)
+	}
+
+	if err = netlink.NeighSet(&netlink.Neigh{
+		LinkIndex:    link.Attrs().Index,
+		Family:       ipFamily(ip),
+		State:        netlink.NUD_PERMANENT,
+		IP:           ip,
+		HardwareAddr: mac,
+	}); err != nil {
+		return fmt.Errorf("failed to install static neigh %v/%v on %v: %v", ip, mac, iface, err)
+	}
+	return nil
+}
+
+// RemoveStaticNeighbors removes all the permanent neigh entries on interface, which works
+// like "ip neigh del <ip> dev <iface>" for each of them, nothing happens if interface
+// does not exist
+func RemoveStaticNeighbors(iface string) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		var linkNotFoundErr netlink.LinkNotFoundError
+		if errors.As(err, &linkNotFoundErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to get link %v: %v", iface, err)
+	}
+
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		neighList, err := netlink.NeighList(link.Attrs().Index, family)
+		if err != nil {
+			return fmt.Errorf("failed to list neigh of %v: %v", iface, err)
+		}
+
+		for i := range neighList {
+			if neighList[i].State != netlink.NUD_PERMANENT {
+				continue
+			}
+			if err := netlink.NeighDel(&neighList[i]); err != nil {
+				return fmt.Errorf("failed to remove static neigh %v: %v", neighList[i].String(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func ipFamily(ip net.IP) int {
+	if ip.To4() == nil {
+		return netlink.FAMILY_V6
+	}
+	return netlink.FAMILY_V4
+}
@@ -0,0 +1,431 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sys/unix"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const (
+	PeerTypeVtep    = "vtep"
+	PeerTypeGateway = "gateway"
+
+	// source ports of single-hop BFD control packets must be in range 49152 to 65535 (RFC 5881)
+	minSourcePort = 49152
+	maxSourcePort = 65535
+
+	// ttl or hop limit of single-hop BFD control packets must be 255 (RFC 5881)
+	ttl = 255
+
+	// control packets are sent no faster than once per second while session is not up (RFC 5880)
+	slowTxInterval = time.Second
+
+	stateChangeBufferSize = 128
+)
+
+// Peer is the remote system of a BFD session.
+type Peer struct {
+	Address net.IP
+	Type    string
+	// Source is the local address which control packets are sent from, it is chosen by
+	// kernel if not specified
+	Source net.IP
+}
+
+// StateChangeHandler is called sequentially with the new state of session to peer every time
+// session state changes.
+type StateChangeHandler func(peer Peer, state State, diagnostic Diagnostic)
+
+type stateChange struct {
+	peer       Peer
+	state      State
+	diagnostic Diagnostic
+}
+
+// Manager maintains single-hop BFD sessions to a dynamic set of peers.
+type Manager struct {
+	mu sync.Mutex
+
+	txInterval       time.Duration
+	detectMultiplier uint8
+
+	sessions       map[string]*Session
+	discriminators map[uint32]*Session
+	nextTx         map[uint32]time.Time
+
+	conn4 *net.UDPConn
+	conn6 *net.UDPConn
+
+	stateChanges  chan stateChange
+	onStateChange StateChangeHandler
+
+	logger logr.Logger
+}
+
+func NewManager(txInterval time.Duration, detectMultiplier uint8, onStateChange StateChangeHandler,
+	logger logr.Logger) *Manager {
+	return &Manager{
+		txInterval:       txInterval,
+		detectMultiplier: detectMultiplier,
+		sessions:         map[string]*Session{},
+		discriminators:   map[uint32]*Session{},
+		nextTx:           map[uint32]time.Time{},
+		stateChanges:     make(chan stateChange, stateChangeBufferSize),
+		onStateChange:    onStateChange,
+		logger:           logger,
+	}
+}
+
+// Start opens BFD sockets and starts to send, receive and time out control packets in
+// background until ctx is done.
+func (m *Manager) Start(ctx context.Context) error {
+	listener4, err := listenControlPort("udp4")
+	if err != nil {
+		return fmt.Errorf("failed to listen on ipv4 bfd control port %v: %v", ControlPort, err)
+	}
+
+	if m.conn4, err = listenSourcePort("udp4"); err != nil {
+		_ = listener4.Close()
+		return fmt.Errorf("failed to create ipv4 bfd source socket: %v", err)
+	}
+
+	// ipv6 might be disabled on node
+	listener6, err := listenControlPort("udp6")
+	if err == nil {
+		if m.conn6, err = listenSourcePort("udp6"); err != nil {
+			_ = listener6.Close()
+		}
+	}
+	if err != nil {
+		m.logger.Info("ipv6 bfd sessions are not available", "reason", err.Error())
+		listener6, m.conn6 = nil, nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener4.Close()
+		_ = m.conn4.Close()
+		if m.conn6 != nil {
+			_ = listener6.Close()
+			_ = m.conn6.Close()
+		}
+	}()
+
+	go m.receiveLoop(listener4)
+	if listener6 != nil {
+		go m.receiveLoop(listener6)
+	}
+	go m.timerLoop(ctx)
+	go m.notifyLoop(ctx)
+
+	return nil
+}
+
+// SetPeers makes sure that sessions exist for and only for the given peers.
+func (m *Manager) SetPeers(peers []Peer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expected := map[string]Peer{}
+	for _, peer := range peers {
+		expected[peer.Address.String()] = peer
+	}
+
+	for address, session := range m.sessions {
+		if peer, exist := expected[address]; exist && peer.Type == session.peer.Type &&
+			peer.Source.Equal(session.peer.Source) {
+			continue
+		}
+
+		delete(m.sessions, address)
+		delete(m.discriminators, session.localDiscriminator)
+		delete(m.nextTx, session.localDiscriminator)
+		metrics.BFDSessionStateGauge.DeleteLabelValues(address, session.peer.Type)
+	}
+
+	for address, peer := range expected {
+		if _, exist := m.sessions[address]; exist {
+			continue
+		}
+
+		session := newSession(peer, m.newDiscriminator(), m.txInterval, m.detectMultiplier)
+		m.sessions[address] = session
+		m.discriminators[session.localDiscriminator] = session
+		m.nextTx[session.localDiscriminator] = time.Now()
+		metrics.BFDSessionStateGauge.WithLabelValues(address, peer.Type).Set(float64(session.State()))
+	}
+}
+
+// newDiscriminator returns a random nonzero discriminator which is unique among sessions.
+func (m *Manager) newDiscriminator() uint32 {
+	for {
+		discriminator := rand.Uint32()
+		if _, exist := m.discriminators[discriminator]; discriminator != 0 && !exist {
+			return discriminator
+		}
+	}
+}
+
+func (m *Manager) receiveLoop(listener *net.UDPConn) {
+	buf := make([]byte, 128)
+	oob := make([]byte, unix.CmsgSpace(4))
+	for {
+		n, oobn, _, from, err := listener.ReadMsgUDP(buf, oob)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			// listener is closed
+			return
+		}
+
+		// packets which are not sent by directly connected systems must be discarded (RFC 5881)
+		if received, err := parseReceivedTTL(oob[:oobn]); err != nil || received != ttl {
+			m.logger.V(1).Info("drop bfd control packet", "from", from.String(),
+				"reason", fmt.Sprintf("ttl is not %d", ttl), "ttl", received)
+			continue
+		}
+
+		packet, err := ParseControlPacket(buf[:n])
+		if err != nil {
+			m.logger.V(1).Info("drop bfd control packet", "from", from.String(), "reason", err.Error())
+			continue
+		}
+
+		m.handlePacket(packet, from.IP)
+	}
+}
+
+func (m *Manager) handlePacket(packet *ControlPacket, from net.IP) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var session *Session
+	if packet.YourDiscriminator != 0 {
+		session = m.discriminators[packet.YourDiscriminator]
+	} else {
+		session = m.sessions[from.String()]
+	}
+
+	if session == nil || !session.peer.Address.Equal(from) {
+		return
+	}
+
+	if session.handle(packet, time.Now()) {
+		m.recordStateChange(session)
+	}
+}
+
+func (m *Manager) timerLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.txInterval / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.tick(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) tick(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, session := range m.sessions {
+		if session.checkTimeout(now) {
+			m.recordStateChange(session)
+		}
+
+		if now.Before(m.nextTx[session.localDiscriminator]) {
+			continue
+		}
+
+		if err := m.send(session); err != nil {
+			m.logger.V(1).Info("failed to send bfd control packet", "peer", session.peer.Address.String(),
+				"reason", err.Error())
+		}
+
+		interval := session.txInterval()
+		if session.State() != StateUp && interval < slowTxInterval {
+			interval = slowTxInterval
+		}
+		m.nextTx[session.localDiscriminator] = now.Add(jitter(interval, session.detectMultiplier))
+	}
+}
+
+func (m *Manager) send(session *Session) error {
+	conn := m.conn4
+	if session.peer.Address.To4() == nil {
+		conn = m.conn6
+	}
+	if conn == nil {
+		return fmt.Errorf("no available socket for peer %v", session.peer.Address.String())
+	}
+
+	if session.peer.Source == nil {
+		_, err := conn.WriteToUDP(session.controlPacket().Marshal(), session.peerAddr())
+		return err
+	}
+
+	_, _, err := conn.WriteMsgUDP(session.controlPacket().Marshal(), sourceControlMessage(session.peer.Source),
+		session.peerAddr())
+	return err
+}
+
+// sourceControlMessage returns the control message to send a packet from the source address.
+func sourceControlMessage(source net.IP) []byte {
+	if ip4 := source.To4(); ip4 != nil {
+		info := &unix.Inet4Pktinfo{}
+		copy(info.Spec_dst[:], ip4)
+		return unix.PktInfo4(info)
+	}
+
+	info := &unix.Inet6Pktinfo{}
+	copy(info.Addr[:], source.To16())
+	return unix.PktInfo6(info)
+}
+
+// recordStateChange must be called with lock held, state changes are notified in another
+// goroutine to avoid blocking the session timers.
+func (m *Manager) recordStateChange(session *Session) {
+	metrics.BFDSessionStateGauge.WithLabelValues(session.peer.Address.String(), session.peer.Type).
+		Set(float64(session.State()))
+
+	select {
+	case m.stateChanges <- stateChange{peer: session.peer, state: session.state, diagnostic: session.diagnostic}:
+	default:
+		m.logger.Info("bfd state change is dropped because of too many pending changes",
+			"peer", session.peer.Address.String(), "state", session.state.String())
+	}
+}
+
+func (m *Manager) notifyLoop(ctx context.Context) {
+	for {
+		select {
+		case change := <-m.stateChanges:
+			m.logger.Info("bfd session state changed", "peer", change.peer.Address.String(),
+				"peerType", change.peer.Type, "state", change.state.String(), "diagnostic", change.diagnostic)
+			if m.onStateChange != nil {
+				m.onStateChange(change.peer, change.state, change.diagnostic)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// jitter reduces interval randomly by 0 to 25 percent, or 10 to 25 percent if detect multiplier
+// is 1 (RFC 5880 section 6.8.7).
+func jitter(interval time.Duration, detectMultiplier uint8) time.Duration {
+	maxPercent := 75
+	if detectMultiplier == 1 {
+		maxPercent = 90
+	}
+	percent := 75 + rand.Intn(maxPercent-75+1)
+	return interval * time.Duration(percent) / 100
+}
+
+// listenControlPort binds a udp socket on the BFD control port, with ttl or hop limit of
+// received packets reported.
+func listenControlPort(network string) (*net.UDPConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				if network == "udp4" {
+					sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_RECVTTL, 1)
+				} else {
+					sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_RECVHOPLIMIT, 1)
+				}
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+
+	conn, err := lc.ListenPacket(context.Background(), network, fmt.Sprintf(":%d", ControlPort))
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// parseReceivedTTL returns the ttl or hop limit of a received packet from its control messages.
+func parseReceivedTTL(oob []byte) (int, error) {
+	messages, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		if (message.Header.Level == unix.IPPROTO_IP && message.Header.Type == unix.IP_TTL) ||
+			(message.Header.Level == unix.IPPROTO_IPV6 && message.Header.Type == unix.IPV6_HOPLIMIT) {
+			if len(message.Data) < 4 {
+				return 0, fmt.Errorf("invalid ttl control message")
+			}
+			return int(*(*int32)(unsafe.Pointer(&message.Data[0]))), nil
+		}
+	}
+	return 0, fmt.Errorf("no ttl control message")
+}
+
+// listenSourcePort binds a udp socket on a random port in the range of single-hop BFD source
+// ports, with ttl or hop limit set to 255.
+func listenSourcePort(network string) (*net.UDPConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				if network == "udp4" {
+					sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TTL, ttl)
+				} else {
+					sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_UNICAST_HOPS, ttl)
+				}
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+
+	var lastErr error
+	for i := 0; i < 10; i++ {
+		port := minSourcePort + rand.Intn(maxSourcePort-minSourcePort+1)
+		conn, err := lc.ListenPacket(context.Background(), network, fmt.Sprintf(":%d", port))
+		if err == nil {
+			return conn.(*net.UDPConn), nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to bind any source port: %v", lastErr)
+}
@@ -0,0 +1,113 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"net"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sys/unix"
+)
+
+func ttlControlMessage(level, typ int32, value int) []byte {
+	b := make([]byte, unix.CmsgSpace(4))
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[0]))
+	h.Level, h.Type = level, typ
+	h.SetLen(unix.CmsgLen(4))
+	*(*int32)(unsafe.Pointer(&b[unix.CmsgLen(0)])) = int32(value)
+	return b
+}
+
+func TestParseReceivedTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		oob       []byte
+		expectTTL int
+		expectErr bool
+	}{
+		{"ipv4 ttl", ttlControlMessage(unix.IPPROTO_IP, unix.IP_TTL, 255), 255, false},
+		{"ipv4 forwarded", ttlControlMessage(unix.IPPROTO_IP, unix.IP_TTL, 254), 254, false},
+		{"ipv6 hop limit", ttlControlMessage(unix.IPPROTO_IPV6, unix.IPV6_HOPLIMIT, 255), 255, false},
+		{"no ttl", ttlControlMessage(unix.IPPROTO_IP, unix.IP_TOS, 0), 0, true},
+		{"no control message", nil, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received, err := parseReceivedTTL(test.oob)
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expect error but got ttl %d", received)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fail to parse ttl: %v", err)
+			}
+			if received != test.expectTTL {
+				t.Errorf("expect ttl %d but got %d", test.expectTTL, received)
+			}
+		})
+	}
+}
+
+func TestSourceControlMessage(t *testing.T) {
+	for _, source := range []string{"10.0.0.1", "fd00::1"} {
+		messages, err := unix.ParseSocketControlMessage(sourceControlMessage(net.ParseIP(source)))
+		if err != nil || len(messages) != 1 {
+			t.Fatalf("fail to parse control message of %s: %v", source, err)
+		}
+
+		var got net.IP
+		if header := messages[0].Header; header.Level == unix.IPPROTO_IP && header.Type == unix.IP_PKTINFO {
+			info := (*unix.Inet4Pktinfo)(unsafe.Pointer(&messages[0].Data[0]))
+			got = net.IP(info.Spec_dst[:])
+		} else if header.Level == unix.IPPROTO_IPV6 && header.Type == unix.IPV6_PKTINFO {
+			info := (*unix.Inet6Pktinfo)(unsafe.Pointer(&messages[0].Data[0]))
+			got = net.IP(info.Addr[:])
+		}
+
+		if !got.Equal(net.ParseIP(source)) {
+			t.Errorf("expect source %s but got %v", source, got)
+		}
+	}
+}
+
+func TestSetPeersRecreatesSessionOnSourceChange(t *testing.T) {
+	m := NewManager(300*time.Millisecond, 3, nil, logr.Discard())
+
+	peer := Peer{Address: net.ParseIP("10.0.0.2"), Type: PeerTypeVtep, Source: net.ParseIP("10.0.0.1")}
+	m.SetPeers([]Peer{peer})
+	session := m.sessions[peer.Address.String()]
+
+	m.SetPeers([]Peer{peer})
+	if m.sessions[peer.Address.String()] != session {
+		t.Fatalf("expect session to be kept for unchanged peer")
+	}
+
+	peer.Source = net.ParseIP("10.0.0.3")
+	m.SetPeers([]Peer{peer})
+	if recreated := m.sessions[peer.Address.String()]; recreated == session || !recreated.peer.Source.Equal(peer.Source) {
+		t.Fatalf("expect session to be recreated with new source")
+	}
+	if len(m.discriminators) != 1 || len(m.nextTx) != 1 {
+		t.Fatalf("expect stale session to be removed")
+	}
+}
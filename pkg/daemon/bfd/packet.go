@@ -0,0 +1,158 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// ControlPort is the udp destination port of single-hop BFD control packets (RFC 5881)
+	ControlPort = 3784
+
+	version             = 1
+	controlPacketLength = 24
+)
+
+// State is the BFD session state carried in control packets (RFC 5880).
+type State uint8
+
+const (
+	StateAdminDown State = 0
+	StateDown      State = 1
+	StateInit      State = 2
+	StateUp        State = 3
+)
+
+func (s State) String() string {
+	switch s {
+	case StateAdminDown:
+		return "AdminDown"
+	case StateDown:
+		return "Down"
+	case StateInit:
+		return "Init"
+	case StateUp:
+		return "Up"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(s))
+	}
+}
+
+// Diagnostic tells the reason of the last session state change.
+type Diagnostic uint8
+
+const (
+	DiagnosticNone                        Diagnostic = 0
+	DiagnosticControlDetectionTimeExpired Diagnostic = 1
+	DiagnosticNeighborSignaledSessionDown Diagnostic = 3
+	DiagnosticAdministrativelyDown        Diagnostic = 7
+)
+
+var (
+	errInvalidControlPacket = errors.New("invalid BFD control packet")
+	errAuthNotSupported     = errors.New("BFD authentication is not supported")
+)
+
+// ControlPacket is the mandatory section of a BFD control packet, intervals are in
+// microseconds.
+type ControlPacket struct {
+	Diagnostic                Diagnostic
+	State                     State
+	Poll                      bool
+	Final                     bool
+	DetectMultiplier          uint8
+	MyDiscriminator           uint32
+	YourDiscriminator         uint32
+	DesiredMinTxInterval      uint32
+	RequiredMinRxInterval     uint32
+	RequiredMinEchoRxInterval uint32
+}
+
+// Marshal encodes the control packet without authentication section.
+func (p *ControlPacket) Marshal() []byte {
+	b := make([]byte, controlPacketLength)
+
+	b[0] = version<<5 | byte(p.Diagnostic)&0x1f
+	b[1] = byte(p.State) << 6
+	if p.Poll {
+		b[1] |= 1 << 5
+	}
+	if p.Final {
+		b[1] |= 1 << 4
+	}
+	b[2] = p.DetectMultiplier
+	b[3] = controlPacketLength
+
+	binary.BigEndian.PutUint32(b[4:8], p.MyDiscriminator)
+	binary.BigEndian.PutUint32(b[8:12], p.YourDiscriminator)
+	binary.BigEndian.PutUint32(b[12:16], p.DesiredMinTxInterval)
+	binary.BigEndian.PutUint32(b[16:20], p.RequiredMinRxInterval)
+	binary.BigEndian.PutUint32(b[20:24], p.RequiredMinEchoRxInterval)
+
+	return b
+}
+
+// ParseControlPacket decodes and validates a received control packet according to the
+// reception rules of RFC 5880 section 6.8.6.
+func ParseControlPacket(b []byte) (*ControlPacket, error) {
+	if len(b) < controlPacketLength {
+		return nil, errInvalidControlPacket
+	}
+
+	if b[0]>>5 != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", errInvalidControlPacket, b[0]>>5)
+	}
+
+	length := int(b[3])
+	if length < controlPacketLength || length > len(b) {
+		return nil, fmt.Errorf("%w: invalid length %d", errInvalidControlPacket, length)
+	}
+
+	// authentication present bit
+	if b[1]&(1<<2) != 0 {
+		return nil, errAuthNotSupported
+	}
+
+	p := &ControlPacket{
+		Diagnostic:                Diagnostic(b[0] & 0x1f),
+		State:                     State(b[1] >> 6),
+		Poll:                      b[1]&(1<<5) != 0,
+		Final:                     b[1]&(1<<4) != 0,
+		DetectMultiplier:          b[2],
+		MyDiscriminator:           binary.BigEndian.Uint32(b[4:8]),
+		YourDiscriminator:         binary.BigEndian.Uint32(b[8:12]),
+		DesiredMinTxInterval:      binary.BigEndian.Uint32(b[12:16]),
+		RequiredMinRxInterval:     binary.BigEndian.Uint32(b[16:20]),
+		RequiredMinEchoRxInterval: binary.BigEndian.Uint32(b[20:24]),
+	}
+
+	switch {
+	case p.DetectMultiplier == 0:
+		return nil, fmt.Errorf("%w: zero detect multiplier", errInvalidControlPacket)
+	case b[1]&1 != 0:
+		return nil, fmt.Errorf("%w: multipoint bit set", errInvalidControlPacket)
+	case p.MyDiscriminator == 0:
+		return nil, fmt.Errorf("%w: zero my discriminator", errInvalidControlPacket)
+	case p.YourDiscriminator == 0 && p.State != StateDown && p.State != StateAdminDown:
+		return nil, fmt.Errorf("%w: zero your discriminator in state %s", errInvalidControlPacket, p.State)
+	}
+
+	return p, nil
+}
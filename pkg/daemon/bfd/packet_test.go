@@ -0,0 +1,93 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestControlPacketRoundTrip(t *testing.T) {
+	p := &ControlPacket{
+		Diagnostic:            DiagnosticControlDetectionTimeExpired,
+		State:                 StateInit,
+		Poll:                  true,
+		DetectMultiplier:      3,
+		MyDiscriminator:       0x11223344,
+		YourDiscriminator:     0x55667788,
+		DesiredMinTxInterval:  300000,
+		RequiredMinRxInterval: 300000,
+	}
+
+	b := p.Marshal()
+	if len(b) != controlPacketLength {
+		t.Fatalf("expected length %d, got %d", controlPacketLength, len(b))
+	}
+	if b[0] != 0x21 || b[1] != 0xa0 {
+		t.Fatalf("unexpected header bytes %#x %#x", b[0], b[1])
+	}
+
+	parsed, err := ParseControlPacket(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(p, parsed) {
+		t.Fatalf("expected %+v, got %+v", p, parsed)
+	}
+}
+
+func TestParseControlPacketInvalid(t *testing.T) {
+	valid := func() []byte {
+		return (&ControlPacket{
+			State:             StateUp,
+			DetectMultiplier:  3,
+			MyDiscriminator:   1,
+			YourDiscriminator: 2,
+		}).Marshal()
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(b []byte) []byte
+		err    error
+	}{
+		{"too short", func(b []byte) []byte { return b[:20] }, errInvalidControlPacket},
+		{"wrong version", func(b []byte) []byte { b[0] = 2 << 5; return b }, errInvalidControlPacket},
+		{"wrong length", func(b []byte) []byte { b[3] = 30; return b }, errInvalidControlPacket},
+		{"authentication", func(b []byte) []byte { b[1] |= 1 << 2; return b }, errAuthNotSupported},
+		{"multipoint", func(b []byte) []byte { b[1] |= 1; return b }, errInvalidControlPacket},
+		{"zero detect multiplier", func(b []byte) []byte { b[2] = 0; return b }, errInvalidControlPacket},
+		{"zero my discriminator", func(b []byte) []byte { copy(b[4:8], []byte{0, 0, 0, 0}); return b }, errInvalidControlPacket},
+		{"zero your discriminator when up", func(b []byte) []byte { copy(b[8:12], []byte{0, 0, 0, 0}); return b }, errInvalidControlPacket},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ParseControlPacket(test.mutate(valid())); !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+
+	down := valid()
+	down[1] = byte(StateDown) << 6
+	copy(down[8:12], []byte{0, 0, 0, 0})
+	if _, err := ParseControlPacket(down); err != nil {
+		t.Fatalf("zero your discriminator should be allowed in down state, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"net"
+	"time"
+)
+
+// Session is a single-hop BFD session in asynchronous mode. It is a simplified implementation
+// of RFC 5880 without demand mode, echo function, poll sequences and authentication, the
+// intervals are fixed during the whole lifetime of session.
+type Session struct {
+	peer Peer
+
+	localDiscriminator  uint32
+	remoteDiscriminator uint32
+
+	state       State
+	remoteState State
+	diagnostic  Diagnostic
+
+	// local parameters
+	desiredMinTxInterval  time.Duration
+	requiredMinRxInterval time.Duration
+	detectMultiplier      uint8
+
+	// parameters announced by peer
+	remoteDesiredMinTxInterval  time.Duration
+	remoteRequiredMinRxInterval time.Duration
+	remoteDetectMultiplier      uint8
+
+	lastReceived time.Time
+}
+
+func newSession(peer Peer, localDiscriminator uint32, interval time.Duration, detectMultiplier uint8) *Session {
+	return &Session{
+		peer:                  peer,
+		localDiscriminator:    localDiscriminator,
+		state:                 StateDown,
+		remoteState:           StateDown,
+		desiredMinTxInterval:  interval,
+		requiredMinRxInterval: interval,
+		detectMultiplier:      detectMultiplier,
+	}
+}
+
+// State returns the local state of session.
+func (s *Session) State() State {
+	return s.state
+}
+
+// handle updates session with a received control packet, and returns whether session state
+// changes.
+func (s *Session) handle(p *ControlPacket, now time.Time) bool {
+	s.remoteDiscriminator = p.MyDiscriminator
+	s.remoteState = p.State
+	s.remoteDesiredMinTxInterval = time.Duration(p.DesiredMinTxInterval) * time.Microsecond
+	s.remoteRequiredMinRxInterval = time.Duration(p.RequiredMinRxInterval) * time.Microsecond
+	s.remoteDetectMultiplier = p.DetectMultiplier
+	s.lastReceived = now
+
+	previous := s.state
+	if p.State == StateAdminDown {
+		if s.state != StateDown {
+			s.state, s.diagnostic = StateDown, DiagnosticNeighborSignaledSessionDown
+		}
+		return previous != s.state
+	}
+
+	switch s.state {
+	case StateDown:
+		switch p.State {
+		case StateDown:
+			s.state = StateInit
+		case StateInit:
+			s.state, s.diagnostic = StateUp, DiagnosticNone
+		}
+	case StateInit:
+		if p.State == StateInit || p.State == StateUp {
+			s.state, s.diagnostic = StateUp, DiagnosticNone
+		}
+	case StateUp:
+		if p.State == StateDown {
+			s.state, s.diagnostic = StateDown, DiagnosticNeighborSignaledSessionDown
+		}
+	}
+
+	return previous != s.state
+}
+
+// checkTimeout brings session down if no control packet is received within detection time,
+// and returns whether session state changes.
+func (s *Session) checkTimeout(now time.Time) bool {
+	if s.state != StateInit && s.state != StateUp {
+		return false
+	}
+
+	if now.Sub(s.lastReceived) <= s.detectionTime() {
+		return false
+	}
+
+	s.state, s.diagnostic = StateDown, DiagnosticControlDetectionTimeExpired
+	s.remoteDiscriminator = 0
+	return true
+}
+
+// detectionTime is the remote detect multiplier times the agreed interval of peer sending
+// control packets to us.
+func (s *Session) detectionTime() time.Duration {
+	interval := s.requiredMinRxInterval
+	if s.remoteDesiredMinTxInterval > interval {
+		interval = s.remoteDesiredMinTxInterval
+	}
+	return time.Duration(s.remoteDetectMultiplier) * interval
+}
+
+// txInterval is the interval of sending control packets to peer, which must not be less
+// than the receive interval required by peer.
+func (s *Session) txInterval() time.Duration {
+	if s.remoteRequiredMinRxInterval > s.desiredMinTxInterval {
+		return s.remoteRequiredMinRxInterval
+	}
+	return s.desiredMinTxInterval
+}
+
+func (s *Session) controlPacket() *ControlPacket {
+	return &ControlPacket{
+		Diagnostic:            s.diagnostic,
+		State:                 s.state,
+		DetectMultiplier:      s.detectMultiplier,
+		MyDiscriminator:       s.localDiscriminator,
+		YourDiscriminator:     s.remoteDiscriminator,
+		DesiredMinTxInterval:  uint32(s.desiredMinTxInterval / time.Microsecond),
+		RequiredMinRxInterval: uint32(s.requiredMinRxInterval / time.Microsecond),
+	}
+}
+
+func (s *Session) peerAddr() *net.UDPAddr {
+	return &net.UDPAddr{IP: s.peer.Address, Port: ControlPort}
+}
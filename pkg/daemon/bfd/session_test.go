@@ -0,0 +1,93 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bfd
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func remotePacket(state State) *ControlPacket {
+	return &ControlPacket{
+		State:                 state,
+		DetectMultiplier:      3,
+		MyDiscriminator:       100,
+		DesiredMinTxInterval:  uint32(100 * time.Millisecond / time.Microsecond),
+		RequiredMinRxInterval: uint32(100 * time.Millisecond / time.Microsecond),
+	}
+}
+
+func TestSessionStateMachine(t *testing.T) {
+	now := time.Now()
+	s := newSession(Peer{Address: net.ParseIP("192.168.0.1"), Type: PeerTypeGateway}, 1, 300*time.Millisecond, 3)
+
+	steps := []struct {
+		remote  State
+		state   State
+		changed bool
+	}{
+		{StateDown, StateInit, true},
+		{StateDown, StateInit, false},
+		{StateUp, StateUp, true},
+		{StateUp, StateUp, false},
+		{StateDown, StateDown, true},
+		{StateInit, StateUp, true},
+		{StateAdminDown, StateDown, true},
+		{StateAdminDown, StateDown, false},
+	}
+
+	for i, step := range steps {
+		if changed := s.handle(remotePacket(step.remote), now); changed != step.changed || s.State() != step.state {
+			t.Fatalf("step %d: expected state %v changed %v, got state %v changed %v",
+				i, step.state, step.changed, s.State(), changed)
+		}
+	}
+
+	if p := s.controlPacket(); p.YourDiscriminator != 100 || p.MyDiscriminator != 1 ||
+		p.Diagnostic != DiagnosticNeighborSignaledSessionDown {
+		t.Fatalf("unexpected control packet %+v", p)
+	}
+}
+
+func TestSessionDetectionTimeout(t *testing.T) {
+	now := time.Now()
+	s := newSession(Peer{Address: net.ParseIP("10.0.0.1"), Type: PeerTypeVtep}, 1, 300*time.Millisecond, 3)
+
+	s.handle(remotePacket(StateInit), now)
+	if s.State() != StateUp {
+		t.Fatalf("expected state up, got %v", s.State())
+	}
+
+	// detection time is remote detect multiplier times max(local required rx, remote desired tx)
+	if s.detectionTime() != 900*time.Millisecond {
+		t.Fatalf("unexpected detection time %v", s.detectionTime())
+	}
+
+	if s.checkTimeout(now.Add(800 * time.Millisecond)) {
+		t.Fatalf("session should not time out within detection time")
+	}
+
+	if !s.checkTimeout(now.Add(time.Second)) || s.State() != StateDown ||
+		s.diagnostic != DiagnosticControlDetectionTimeExpired || s.remoteDiscriminator != 0 {
+		t.Fatalf("session should time out, got state %v diagnostic %v", s.State(), s.diagnostic)
+	}
+
+	if s.checkTimeout(now.Add(2 * time.Second)) {
+		t.Fatalf("down session should not time out again")
+	}
+}
@@ -150,18 +150,26 @@ func NewManager(peeringInterfaceName, grpcListenAddress string, logger logr.Logg
 	return manager, nil
 }
 
-func (m *Manager) RecordPeer(address, password string, asn int, gracefulRestartTime int32, allowNotEstablished bool) {
+// RecordPeer records a bgp peer, an unnumbered peer is discovered on the peering interface
+// and recorded with the name of interface instead of address.
+func (m *Manager) RecordPeer(address, password string, asn int, gracefulRestartTime int32, allowNotEstablished,
+	unnumbered bool) {
 	if gracefulRestartTime == 0 {
 		gracefulRestartTime = 300
 	}
 
-	m.peerMap[address] = &peerInfo{
+	peer := &peerInfo{
 		address:                address,
 		asn:                    asn,
 		gracefulRestartSeconds: uint32(gracefulRestartTime),
 		password:               password,
 		allowNotEstablished:    allowNotEstablished,
 	}
+	if unnumbered {
+		peer.address, peer.neighborInterface = "", m.peeringInterfaceName
+	}
+
+	m.peerMap[peer.key()] = peer
 }
 
 func (m *Manager) RecordSubnet(cidr *net.IPNet) {
@@ -240,21 +248,24 @@ func (m *Manager) SyncPeerInfos() error {
 		return nil
 	}
 
-	for _, peer := range m.peerMap {
-		if _, exist := existPeerMap[peer.address]; !exist {
+	for key, peer := range m.peerMap {
+		if _, exist := existPeerMap[key]; !exist {
 			if err := m.bgpServer.AddPeer(context.Background(), &api.AddPeerRequest{
 				Peer: generatePeerConfig(peer),
 			}); err != nil {
-				return fmt.Errorf("failed to add bgp peer %v: %v", peer.address, err)
+				return fmt.Errorf("failed to add bgp peer %v: %v", key, err)
 			}
 		}
 	}
 
 	for addr := range existPeerMap {
 		if _, exist := m.peerMap[addr]; !exist {
-			if err := m.bgpServer.DeletePeer(context.Background(), &api.DeletePeerRequest{
-				Address: addr,
-			}); err != nil {
+			request := &api.DeletePeerRequest{Address: addr}
+			if net.ParseIP(addr) == nil {
+				request = &api.DeletePeerRequest{Interface: addr}
+			}
+
+			if err := m.bgpServer.DeletePeer(context.Background(), request); err != nil {
 				return fmt.Errorf("failed to add bgp peer %v: %v", addr, err)
 			}
 		}
@@ -406,7 +417,14 @@ func (m *Manager) CheckRemotePeersEstablished() (bool, error) {
 	return true, nil
 }
 
+// getNextHopAddressByIP chooses the next hop address to advertise ipAddr. If there are bgp unnumbered peers,
+// ipv4 address is advertised with unspecified next hop, which will be replaced by the local address of session
+// for each peer, i.e., ipv6 link-local address for unnumbered peers and ipv4 address for the others.
 func (m *Manager) getNextHopAddressByIP(ipAddr net.IP) (net.IP, error) {
+	if ipAddr.To4() != nil && m.hasUnnumberedPeer() {
+		return net.IPv4zero, nil
+	}
+
 	if ipAddr.To4() == nil {
 		if m.routerV6Address == nil {
 			return nil, fmt.Errorf("router has no valid v6 nexthop address")
@@ -420,6 +438,15 @@ func (m *Manager) getNextHopAddressByIP(ipAddr net.IP) (net.IP, error) {
 	return m.routerV4Address, nil
 }
 
+func (m *Manager) hasUnnumberedPeer() bool {
+	for _, peer := range m.peerMap {
+		if len(peer.neighborInterface) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) listExistPath(existSubnetPathMap map[string]*net.IPNet, existIPPathMap map[string]net.IP) error {
 	listPathFunc := generatePathListFunc(existSubnetPathMap, existIPPathMap, m.logger)
 	if err := m.bgpServer.ListPath(context.Background(),
@@ -437,7 +464,12 @@ func (m *Manager) listRemoteBGPPeers(existPeerMap map[string]struct{}, filterFun
 	if err := m.bgpServer.ListPeer(context.Background(), &api.ListPeerRequest{EnableAdvertised: true},
 		func(peer *api.Peer) {
 			if filterFunc(peer) {
-				existPeerMap[peer.Conf.NeighborAddress] = struct{}{}
+				// unnumbered peers are keyed by interface, the same as recorded ones
+				if len(peer.Conf.NeighborInterface) != 0 {
+					existPeerMap[peer.Conf.NeighborInterface] = struct{}{}
+				} else {
+					existPeerMap[peer.Conf.NeighborAddress] = struct{}{}
+				}
 			}
 		}); err != nil {
 		return fmt.Errorf("failed to list bgp peers: %v", err)
@@ -0,0 +1,129 @@
+/*
+ Copyright 2021 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bgp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-logr/logr"
+	api "github.com/osrg/gobgp/v3/api"
+	"github.com/osrg/gobgp/v3/pkg/server"
+)
+
+func TestUnnumberedPeerAnnouncesIPv4WithUnspecifiedNextHop(t *testing.T) {
+	m := &Manager{
+		peeringInterfaceName: "eth0",
+		routerID:             "192.168.0.10",
+		routerV4Address:      net.ParseIP("192.168.0.10"),
+		bgpServer:            server.NewBgpServer(),
+		logger:               logr.Discard(),
+		peerMap:              map[string]*peerInfo{},
+		subnetMap:            map[string]*net.IPNet{},
+		ipMap:                map[string]*ipInfo{},
+	}
+	go m.bgpServer.Serve()
+	defer func() {
+		_ = m.bgpServer.StopBgp(context.Background(), &api.StopBgpRequest{})
+	}()
+
+	// do not listen on bgp port in test
+	m.localASN = 65001
+	if err := m.bgpServer.StartBgp(context.Background(), &api.StartBgpRequest{
+		Global: &api.Global{
+			Asn:        m.localASN,
+			RouterId:   m.routerID,
+			ListenPort: -1,
+		},
+	}); err != nil {
+		t.Fatalf("fail to start bgp server: %v", err)
+	}
+
+	m.RecordPeer("", "", 65000, 0, false, true)
+	peer := generatePeerConfig(m.peerMap["eth0"])
+	if peer.Conf.NeighborInterface != "eth0" || len(peer.Conf.NeighborAddress) != 0 {
+		t.Fatalf("expected unnumbered peer discovered on eth0 but got %v", peer.Conf)
+	}
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	m.RecordSubnet(subnet)
+	m.RecordIP(net.ParseIP("10.0.0.2"), true)
+	if err := m.SyncSubnetInfos(); err != nil {
+		t.Fatalf("fail to sync subnets: %v", err)
+	}
+	if err := m.SyncIPInfos(); err != nil {
+		t.Fatalf("fail to sync ips: %v", err)
+	}
+
+	// the next hop is replaced by local address of session for each peer while advertising
+	announced := map[string]string{}
+	if err := m.bgpServer.ListPath(context.Background(), &api.ListPathRequest{Family: v4Family},
+		func(d *api.Destination) {
+			for _, attr := range d.Paths[0].Pattrs {
+				nextHop := &api.NextHopAttribute{}
+				if attr.UnmarshalTo(nextHop) == nil {
+					announced[d.Prefix] = nextHop.NextHop
+				}
+			}
+		}); err != nil {
+		t.Fatalf("fail to list paths: %v", err)
+	}
+
+	for _, prefix := range []string{"10.0.0.0/24", "10.0.0.2/32"} {
+		if announced[prefix] != "0.0.0.0" {
+			t.Errorf("expected %s announced with unspecified next hop but got %q", prefix, announced[prefix])
+		}
+	}
+}
+
+func TestGetNextHopAddressByIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		unnumbered bool
+		ip         net.IP
+		expected   net.IP
+	}{
+		{"ipv4 for numbered peers", false, net.ParseIP("10.0.0.2"), net.ParseIP("192.168.0.10")},
+		{"ipv6 for numbered peers", false, net.ParseIP("fd00::2"), net.ParseIP("fd00:56::10")},
+		{"ipv4 with unnumbered peer", true, net.ParseIP("10.0.0.2"), net.IPv4zero},
+		{"ipv6 with unnumbered peer", true, net.ParseIP("fd00::2"), net.ParseIP("fd00:56::10")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &Manager{
+				peeringInterfaceName: "eth0",
+				routerV4Address:      net.ParseIP("192.168.0.10"),
+				routerV6Address:      net.ParseIP("fd00:56::10"),
+				peerMap:              map[string]*peerInfo{},
+			}
+			m.RecordPeer("192.168.0.1", "", 65000, 0, false, false)
+			if test.unnumbered {
+				m.RecordPeer("", "", 65000, 0, false, true)
+			}
+
+			nextHop, err := m.getNextHopAddressByIP(test.ip)
+			if err != nil {
+				t.Fatalf("fail to get next hop: %v", err)
+			}
+			if !nextHop.Equal(test.expected) {
+				t.Errorf("expect next hop %v but got %v", test.expected, nextHop)
+			}
+		})
+	}
+}
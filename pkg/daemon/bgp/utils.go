@@ -56,6 +56,16 @@ type peerInfo struct {
 	gracefulRestartSeconds uint32
 	password               string
 	allowNotEstablished    bool
+
+	// neighborInterface is the interface which bgp unnumbered peer is discovered on
+	neighborInterface string
+}
+
+func (p *peerInfo) key() string {
+	if len(p.neighborInterface) != 0 {
+		return p.neighborInterface
+	}
+	return p.address
 }
 
 type ipInfo struct {
@@ -66,9 +76,10 @@ type ipInfo struct {
 func generatePeerConfig(p *peerInfo) *api.Peer {
 	return &api.Peer{
 		Conf: &api.PeerConf{
-			NeighborAddress: p.address,
-			PeerAsn:         uint32(p.asn),
-			AuthPassword:    p.password,
+			NeighborAddress:   p.address,
+			NeighborInterface: p.neighborInterface,
+			PeerAsn:           uint32(p.asn),
+			AuthPassword:      p.password,
 		},
 		GracefulRestart: &api.GracefulRestart{
 			Enabled:         true,
@@ -126,7 +137,7 @@ func generatePathForIP(ip, nextHop net.IP, extraPathAttrs ...*apb.Any) *api.Path
 		PrefixLen: prefixBytesLen * 8,
 	})
 
-	pAttrs := append([]*apb.Any{generateNextHopAttr(isIPv6, nextHop.String(), nlri), originAttr}, extraPathAttrs...)
+	pAttrs := append([]*apb.Any{generateNextHopAttr(getIPFamilyFromIP(ip), nextHop, nlri), originAttr}, extraPathAttrs...)
 
 	return &api.Path{
 		Family: getIPFamilyFromIP(ip),
@@ -147,7 +158,7 @@ func generatePathForSubnet(subnet *net.IPNet, nextHop net.IP, extraPathAttrs ...
 		PrefixLen: uint32(prefixLen),
 	})
 
-	pAttrs := append([]*apb.Any{generateNextHopAttr(subnet.IP.To4() == nil, nextHop.String(), nlri), originAttr},
+	pAttrs := append([]*apb.Any{generateNextHopAttr(getIPFamilyFromIP(subnet.IP), nextHop, nlri), originAttr},
 		extraPathAttrs...)
 
 	return &api.Path{
@@ -157,18 +168,20 @@ func generatePathForSubnet(subnet *net.IPNet, nextHop net.IP, extraPathAttrs ...
 	}
 }
 
-func generateNextHopAttr(isIPv6 bool, nextHop string, nlri *apb.Any) *apb.Any {
-	if isIPv6 {
-		v6NextHopAttr, _ := apb.New(&api.MpReachNLRIAttribute{
-			Family:   v6Family,
-			NextHops: []string{nextHop},
+// generateNextHopAttr generates MP_REACH_NLRI attribute for IPv6 next hop, which is also used
+// to advertise IPv4 prefixes over IPv6 next hop (RFC 5549)
+func generateNextHopAttr(family *api.Family, nextHop net.IP, nlri *apb.Any) *apb.Any {
+	if nextHop.To4() == nil {
+		mpNextHopAttr, _ := apb.New(&api.MpReachNLRIAttribute{
+			Family:   family,
+			NextHops: []string{nextHop.String()},
 			Nlris:    []*apb.Any{nlri},
 		})
-		return v6NextHopAttr
+		return mpNextHopAttr
 	}
 
 	v4NextHopAttr, _ := apb.New(&api.NextHopAttribute{
-		NextHop: nextHop,
+		NextHop: nextHop.String(),
 	})
 	return v4NextHopAttr
 }
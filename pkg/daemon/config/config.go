@@ -43,6 +43,22 @@ const (
 	DefaultIPtablesCheckDuration                = 5 * time.Second
 	DefaultVxlanBaseReachableTime               = 5 * time.Second
 	DefaultVxlanExpiredNeighCachesClearInterval = 1 * time.Hour
+	DefaultLLDPDiscoveryInterval                = 5 * time.Minute
+	DefaultIPAMRebuildTimeout                   = 2 * time.Minute
+	DefaultBFDTxInterval                        = 300 * time.Millisecond
+	DefaultBFDDetectMultiplier                  = 3
+	DefaultMTUProbeInterval                     = 5 * time.Minute
+	DefaultMTUProbePort                         = 8473
+	DefaultOverlayLatencyProbeInterval          = 30 * time.Second
+
+	DefaultCNIAddWorkers   = 16
+	DefaultCNIDelWorkers   = 16
+	DefaultCNICheckWorkers = 8
+	// kubelet cancels a cni ADD after its --runtime-request-timeout (2m by default), the ADD must
+	// fail before that so that runtime gets the error instead of retrying with a running ADD
+	DefaultCNIAddTimeout   = 90 * time.Second
+	DefaultCNIDelTimeout   = time.Minute
+	DefaultCNICheckTimeout = 30 * time.Second
 
 	DefaultNeighGCThresh1 = 1024
 	DefaultNeighGCThresh2 = 2048
@@ -84,15 +100,64 @@ type Configuration struct {
 	VxlanExpiredNeighCachesClearInterval time.Duration
 	VtepAddressCIDRs                     []*net.IPNet
 
+	LLDPDiscoveryInterval time.Duration
+
+	// CNI commands are served by separate worker pools, so that slow ADDs never starve DELs,
+	// a command which can not finish within its timeout is failed to be retried by runtime
+	CNIAddWorkers   int
+	CNIDelWorkers   int
+	CNICheckWorkers int
+	CNIAddTimeout   time.Duration
+	CNIDelTimeout   time.Duration
+	CNICheckTimeout time.Duration
+
+	// IPAMRebuildTimeout is how long daemon waits for ipam state to be rebuilt before exiting
+	IPAMRebuildTimeout time.Duration
+
+	// EnableBFD enables BFD sessions to underlay gateways and remote vteps
+	EnableBFD           bool
+	BFDTxInterval       time.Duration
+	BFDDetectMultiplier int
+
+	// EnableMTUProbe enables the detection of mtu black holes on paths to remote vteps
+	EnableMTUProbe   bool
+	MTUProbeInterval time.Duration
+	MTUProbePort     int
+
+	// EnableOverlayLatencyProbe enables the icmp probes from local vtep to vteps of remote nodes,
+	// which record the round-trip time as histogram per remote node
+	EnableOverlayLatencyProbe   bool
+	OverlayLatencyProbeInterval time.Duration
+
+	// OAMAgentAddress is where OAM frames of overlay pods are relayed to, empty means disabled
+	OAMAgentAddress string
+
+	// EnableDHCPSnooping records IPs leased by external DHCP servers on underlay subnets as IPInstances
+	EnableDHCPSnooping    bool
+	DHCPSnoopingNamespace string
+
+	// IPAMExcludeListFile lists the IPs used by other systems on node, which are reserved as IPInstances
+	// in underlay subnets, empty means disabled
+	IPAMExcludeListFile      string
+	IPAMExcludeListNamespace string
+
 	// Use fixed table num to mark "local-pod-direct rule"
 	LocalDirectTableNum int
 
+	// HostVRFName is the VRF which host-side pod nics and vtep interface are attached to, routes of
+	// local pods and subnets will also be installed into table of it, empty means no VRF
+	HostVRFName string
+
 	// Use fixed table num to mark "to-overlay-pod-subnet rule"
 	ToOverlaySubnetTableNum int
 
 	// Use fixed table num to mark "overlay-mark-table rule"
 	OverlayMarkTableNum int
 
+	// ClusterRouteTables are the dedicated route tables of remote clusters indexed by cluster name, routes
+	// to remote overlay subnets of these clusters are installed in them instead of to-overlay-pod-subnet table
+	ClusterRouteTables map[string]int
+
 	NeighGCThresh1 int
 	NeighGCThresh2 int
 	NeighGCThresh3 int
@@ -104,6 +169,11 @@ type Configuration struct {
 	PatchCalicoPodIPsAnnotation  bool
 	CheckPodConnectivityFromHost bool
 	UpdateIPInstanceStatus       bool
+	EnableRemoteRouteCompression bool
+	EnableARPSuppression         bool
+	RemoteRouteDefaultMetric     int
+
+	CrashDir string
 }
 
 // ParseFlags will parse cmd args then init kubeClient and configuration
@@ -118,9 +188,11 @@ func ParseFlags() (*Configuration, error) {
 		argMetricsServerAddress                 = pflag.String("metrics-addr", DefaultMetricsServerBindAddress, "The address which daemon metrics server bind")
 		argBGPgRPCServerAddress                 = pflag.String("bgp-grpc-server-addr", DefaultBGPgRPCServerBindAddress, "The address which daemon bgp grpc server bind, for using gobgp command to debug")
 		argLocalDirectTableNum                  = pflag.Int("local-direct-table", DefaultLocalDirectTableNum, "The number of local-pod-direct route table")
+		argHostVRFName                          = pflag.String("host-vrf-name", "", "The VRF which host-side pod nics and vtep interface are attached to and routes of local pods and subnets are installed into, empty means no VRF")
 		argIPtablesCheckDuration                = pflag.Duration("iptables-check-duration", DefaultIPtablesCheckDuration, "The time period for iptables manager to check iptables rules")
 		argToOverlaySubnetTableNum              = pflag.Int("to-overlay-table", DefaultToOverlaySubnetTableNum, "The number of to-overlay-pod-subnet route table")
 		argOverlayMarkTableNum                  = pflag.Int("overlay-mark-table", DefaultOverlayMarkTableNum, "The number of overlay-mark routing table")
+		argClusterRouteTables                   = pflag.StringToInt("cluster-route-table", nil, "The dedicated route tables of remote clusters in <cluster>=<table> format, e.g., test=41000,prod=41001, routes to remote overlay subnets of a cluster are installed in its own table so that overlapped cidrs of clusters do not conflict")
 		argVlanCheckTimeout                     = pflag.Duration("vlan-check-timeout", DefaultVlanCheckTimeout, "The timeout of vlan network environment check while pod creating")
 		argVxlanUDPPort                         = pflag.Int("vxlan-udp-port", DefaultVxlanUDPPort, "The local udp port which vxlan tunnel use")
 		argVxlanBaseReachableTime               = pflag.Duration("vxlan-base-reachable-time", DefaultVxlanBaseReachableTime, "The time for neigh caches of vxlan device to get STALE from REACHABLE")
@@ -136,6 +208,31 @@ func ParseFlags() (*Configuration, error) {
 		argPatchCalicoPodIPsAnnotation          = pflag.Bool("patch-calico-pod-ips-annotation", true, "Patch \"cni.projectcalico.org/podIPs\" annotations to pod")
 		argCheckPodConnectivityFromHost         = pflag.Bool("check-pod-connectivity-from-host", true, "Check pod's connectivity from host before start it")
 		argUpdateIPInstanceStatus               = pflag.Bool("update-ipinstance-status", true, "Update ipinstance status while creating pod sandbox")
+		argEnableRemoteRouteCompression         = pflag.Bool("enable-remote-route-compression", false, "Aggregate contiguous routes of remote overlay subnets into summarized prefixes")
+		argEnableARPSuppression                 = pflag.Bool("enable-arp-suppression", false, "Answer arp/ndp requests of overlay pods from local caches instead of flooding them through vxlan tunnels")
+		argRemoteRouteDefaultMetric             = pflag.Int("remote-route-default-metric", 0, "The metric of routes to remote subnets which do not specify one, 0 means kernel default")
+		argCrashDir                             = pflag.String("crash-dir", "", "The directory to write crash reports into if daemon panics, empty means crash reports are disabled")
+		argLLDPDiscoveryInterval                = pflag.Duration("lldp-discovery-interval", DefaultLLDPDiscoveryInterval, "The interval for daemon to discover underlay network through LLDP if enabled on node")
+		argIPAMRebuildTimeout                   = pflag.Duration("ipam-rebuild-timeout", DefaultIPAMRebuildTimeout, "The timeout for daemon to rebuild ipam state before exiting to be restarted, 0 means waiting forever")
+		argCNIAddWorkers                        = pflag.Int("cni-add-workers", DefaultCNIAddWorkers, "The number of cni ADD commands served concurrently")
+		argCNIDelWorkers                        = pflag.Int("cni-del-workers", DefaultCNIDelWorkers, "The number of cni DEL commands served concurrently")
+		argCNICheckWorkers                      = pflag.Int("cni-check-workers", DefaultCNICheckWorkers, "The number of cni CHECK commands served concurrently")
+		argCNIAddTimeout                        = pflag.Duration("cni-add-timeout", DefaultCNIAddTimeout, "The timeout of a cni ADD command, including the time waiting for a free worker")
+		argCNIDelTimeout                        = pflag.Duration("cni-del-timeout", DefaultCNIDelTimeout, "The timeout of a cni DEL command, including the time waiting for a free worker")
+		argCNICheckTimeout                      = pflag.Duration("cni-check-timeout", DefaultCNICheckTimeout, "The timeout of a cni CHECK command, including the time waiting for a free worker")
+		argEnableBFD                            = pflag.Bool("enable-bfd", false, "Whether enable bfd sessions to gateways of underlay vlan subnets and remote vteps for fast failure detection")
+		argBFDTxInterval                        = pflag.Duration("bfd-tx-interval", DefaultBFDTxInterval, "The desired interval of bfd control packets, so as the required receive interval")
+		argBFDDetectMultiplier                  = pflag.Int("bfd-detect-multiplier", DefaultBFDDetectMultiplier, "The number of missed bfd control packets before a session is considered down")
+		argEnableMTUProbe                       = pflag.Bool("enable-mtu-probe", false, "Whether probe the effective mtu of paths to remote vteps periodically for detecting mtu black holes")
+		argMTUProbeInterval                     = pflag.Duration("mtu-probe-interval", DefaultMTUProbeInterval, "The interval of probing the effective mtu of paths to remote vteps")
+		argMTUProbePort                         = pflag.Int("mtu-probe-port", DefaultMTUProbePort, "The udp port which mtu probes are sent to and answered on")
+		argEnableOverlayLatencyProbe            = pflag.Bool("enable-overlay-latency-probe", false, "Whether probe the round-trip time from local vtep to vteps of remote nodes periodically")
+		argOverlayLatencyProbeInterval          = pflag.Duration("overlay-latency-probe-interval", DefaultOverlayLatencyProbeInterval, "The interval of probing the round-trip time from local vtep to vteps of remote nodes")
+		argOAMAgentAddress                      = pflag.String("oam-agent-address", "", "The udp address of OAM agent which OAM frames of pods on oam-enabled overlay networks are relayed to, empty means disabled")
+		argEnableDHCPSnooping                   = pflag.Bool("enable-dhcp-snooping", false, "Whether snoop DHCPACKs on the vlan interface and record the leased IPs of underlay subnets as IPInstances")
+		argDHCPSnoopingNamespace                = pflag.String("dhcp-snooping-namespace", "kube-system", "The namespace which IPInstances of snooped DHCP leases are created in")
+		argIPAMExcludeListFile                  = pflag.String("ipam-exclude-list-file", "", "The file listing IPs used by other systems on this node, one IP per line, which are reserved in underlay subnets and reloaded on SIGHUP, empty means disabled")
+		argIPAMExcludeListNamespace             = pflag.String("ipam-exclude-list-namespace", "kube-system", "The namespace which IPInstances of IPs reserved by the exclude list file are created in")
 	)
 
 	// mute info log for ipset lib
@@ -159,8 +256,10 @@ func ParseFlags() (*Configuration, error) {
 		MetricsServerAddress:                 *argMetricsServerAddress,
 		BGPgRPCServerAddress:                 *argBGPgRPCServerAddress,
 		LocalDirectTableNum:                  *argLocalDirectTableNum,
+		HostVRFName:                          *argHostVRFName,
 		ToOverlaySubnetTableNum:              *argToOverlaySubnetTableNum,
 		OverlayMarkTableNum:                  *argOverlayMarkTableNum,
+		ClusterRouteTables:                   *argClusterRouteTables,
 		VlanCheckTimeout:                     *argVlanCheckTimeout,
 		VxlanUDPPort:                         *argVxlanUDPPort,
 		IptablesCheckDuration:                *argIPtablesCheckDuration,
@@ -175,6 +274,31 @@ func ParseFlags() (*Configuration, error) {
 		PatchCalicoPodIPsAnnotation:          *argPatchCalicoPodIPsAnnotation,
 		CheckPodConnectivityFromHost:         *argCheckPodConnectivityFromHost,
 		UpdateIPInstanceStatus:               *argUpdateIPInstanceStatus,
+		LLDPDiscoveryInterval:                *argLLDPDiscoveryInterval,
+		IPAMRebuildTimeout:                   *argIPAMRebuildTimeout,
+		CNIAddWorkers:                        *argCNIAddWorkers,
+		CNIDelWorkers:                        *argCNIDelWorkers,
+		CNICheckWorkers:                      *argCNICheckWorkers,
+		CNIAddTimeout:                        *argCNIAddTimeout,
+		CNIDelTimeout:                        *argCNIDelTimeout,
+		CNICheckTimeout:                      *argCNICheckTimeout,
+		EnableBFD:                            *argEnableBFD,
+		BFDTxInterval:                        *argBFDTxInterval,
+		BFDDetectMultiplier:                  *argBFDDetectMultiplier,
+		EnableMTUProbe:                       *argEnableMTUProbe,
+		MTUProbeInterval:                     *argMTUProbeInterval,
+		MTUProbePort:                         *argMTUProbePort,
+		EnableOverlayLatencyProbe:            *argEnableOverlayLatencyProbe,
+		OverlayLatencyProbeInterval:          *argOverlayLatencyProbeInterval,
+		OAMAgentAddress:                      *argOAMAgentAddress,
+		EnableDHCPSnooping:                   *argEnableDHCPSnooping,
+		DHCPSnoopingNamespace:                *argDHCPSnoopingNamespace,
+		IPAMExcludeListFile:                  *argIPAMExcludeListFile,
+		IPAMExcludeListNamespace:             *argIPAMExcludeListNamespace,
+		EnableRemoteRouteCompression:         *argEnableRemoteRouteCompression,
+		EnableARPSuppression:                 *argEnableARPSuppression,
+		RemoteRouteDefaultMetric:             *argRemoteRouteDefaultMetric,
+		CrashDir:                             *argCrashDir,
 	}
 
 	if *argPreferVlanInterfaces == "" {
@@ -254,9 +378,13 @@ func (config *Configuration) initNicConfig() error {
 		config.BGPMTU = bgpNodeInterface.MTU
 	}
 
-	// VXLAN uses a 50-byte header
-	if config.VxlanMTU == 0 || config.VxlanMTU > vxlanNodeInterface.MTU-50 {
-		config.VxlanMTU = vxlanNodeInterface.MTU - 50
+	// VXLAN uses a 50-byte header over ipv4 underlay and a 70-byte one over ipv6 underlay
+	vxlanOverhead, err := daemonutils.VxlanOverhead(vxlanNodeInterface.Name, config.VtepAddressCIDRs)
+	if err != nil {
+		return fmt.Errorf("failed to get vxlan overhead: %v", err)
+	}
+	if config.VxlanMTU == 0 || config.VxlanMTU > vxlanNodeInterface.MTU-vxlanOverhead {
+		config.VxlanMTU = vxlanNodeInterface.MTU - vxlanOverhead
 	}
 
 	return nil
@@ -0,0 +1,239 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/alibaba/hybridnet/pkg/daemon/route"
+)
+
+// ValidationError describes an invalid flag of daemon and how to fix it
+type ValidationError struct {
+	Flag    string
+	Problem string
+	Hint    string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid flag --%s: %s, %s", e.Flag, e.Problem, e.Hint)
+}
+
+// reservedRouteTables are the tables used by kernel, which must not be taken by daemon
+var reservedRouteTables = map[int]string{
+	0:   "unspec",
+	253: "default",
+	254: "main",
+	255: "local",
+}
+
+// ValidateConfiguration checks the configuration of daemon at startup, and returns all the
+// problems found, so that misconfigurations are not discovered until features are used
+func ValidateConfiguration(config *Configuration) []error {
+	var errs []error
+	invalid := func(flag, hint, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			Flag:    flag,
+			Problem: fmt.Sprintf(format, args...),
+			Hint:    hint,
+		})
+	}
+
+	if len(config.BindSocket) == 0 {
+		invalid("bind-socket", "set it to the socket path shared with cni plugin, e.g., /var/run/hybridnet.sock",
+			"socket path is empty")
+	}
+
+	for _, address := range []struct {
+		flag  string
+		value string
+	}{
+		{"health-probe-addr", config.HealthyServerAddress},
+		{"metrics-addr", config.MetricsServerAddress},
+		{"bgp-grpc-server-addr", config.BGPgRPCServerAddress},
+	} {
+		if _, _, err := net.SplitHostPort(address.value); err != nil {
+			invalid(address.flag, "set it in host:port format, e.g., :9899", "address %q is illegal: %v", address.value, err)
+		}
+	}
+
+	for _, ifName := range []struct {
+		flag  string
+		value string
+	}{
+		{"prefer-vlan-interfaces", config.NodeVlanIfName},
+		{"prefer-vxlan-interfaces", config.NodeVxlanIfName},
+		{"prefer-bgp-interfaces", config.NodeBGPIfName},
+	} {
+		if len(ifName.value) == 0 {
+			invalid(ifName.flag, "set it to the interfaces connecting to underlay network, or make sure default route exists",
+				"no parent interface is found")
+		}
+	}
+
+	validatePort := func(flag string, port int) {
+		if port <= 0 || port > 65535 {
+			invalid(flag, "set it in range 1 to 65535", "port %v is out of range", port)
+		}
+	}
+	validatePort("vxlan-udp-port", config.VxlanUDPPort)
+
+	validatePositiveDuration := func(flag string, duration time.Duration) {
+		if duration <= 0 {
+			invalid(flag, "set it to a positive duration, e.g., 5s", "duration %v is not positive", duration)
+		}
+	}
+	validatePositiveDuration("vlan-check-timeout", config.VlanCheckTimeout)
+	validatePositiveDuration("iptables-check-duration", config.IptablesCheckDuration)
+	validatePositiveDuration("vxlan-base-reachable-time", config.VxlanBaseReachableTime)
+	validatePositiveDuration("vxlan-expired-neigh-caches-clear-interval", config.VxlanExpiredNeighCachesClearInterval)
+	validatePositiveDuration("lldp-discovery-interval", config.LLDPDiscoveryInterval)
+
+	if config.IPAMRebuildTimeout < 0 {
+		invalid("ipam-rebuild-timeout", "set it to a positive duration, or 0 to wait forever",
+			"duration %v is negative", config.IPAMRebuildTimeout)
+	}
+
+	for _, pool := range []struct {
+		command string
+		workers int
+		timeout time.Duration
+	}{
+		{"add", config.CNIAddWorkers, config.CNIAddTimeout},
+		{"del", config.CNIDelWorkers, config.CNIDelTimeout},
+		{"check", config.CNICheckWorkers, config.CNICheckTimeout},
+	} {
+		if pool.workers <= 0 {
+			invalid("cni-"+pool.command+"-workers", "set it to a positive number, e.g., 16",
+				"number %v is not positive", pool.workers)
+		}
+		validatePositiveDuration("cni-"+pool.command+"-timeout", pool.timeout)
+	}
+
+	type routeTable struct {
+		flag string
+		num  int
+	}
+	routeTables := []routeTable{
+		{"local-direct-table", config.LocalDirectTableNum},
+		{"to-overlay-table", config.ToOverlaySubnetTableNum},
+		{"overlay-mark-table", config.OverlayMarkTableNum},
+	}
+
+	clusters := make([]string, 0, len(config.ClusterRouteTables))
+	for cluster := range config.ClusterRouteTables {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		num := config.ClusterRouteTables[cluster]
+		if num >= route.MinRouteTableNum && num <= route.MaxRouteTableNum {
+			invalid("cluster-route-table", fmt.Sprintf("choose a table number out of range %v to %v", route.MinRouteTableNum,
+				route.MaxRouteTableNum), "table %v of cluster %s may be allocated to local subnets", num, cluster)
+			continue
+		}
+		routeTables = append(routeTables, routeTable{"cluster-route-table", num})
+	}
+
+	tables := map[int]string{}
+	for _, table := range routeTables {
+		if table.num < 0 {
+			invalid(table.flag, "choose a positive table number, e.g., 39999", "table %v is negative", table.num)
+			continue
+		}
+		if name, reserved := reservedRouteTables[table.num]; reserved {
+			invalid(table.flag, "choose a table number not used by kernel, e.g., 39999",
+				"table %v is the reserved %s table", table.num, name)
+			continue
+		}
+		if flag, exist := tables[table.num]; exist {
+			invalid(table.flag, "every route table of daemon should have its own number",
+				"table %v is already used by --%s", table.num, flag)
+			continue
+		}
+		tables[table.num] = table.flag
+	}
+
+	if config.NeighGCThresh1 <= 0 || config.NeighGCThresh1 > config.NeighGCThresh2 ||
+		config.NeighGCThresh2 > config.NeighGCThresh3 {
+		invalid("neigh-gc-thresh1", "make sure 0 < neigh-gc-thresh1 <= neigh-gc-thresh2 <= neigh-gc-thresh3",
+			"thresholds %v, %v and %v are not in ascending order", config.NeighGCThresh1,
+			config.NeighGCThresh2, config.NeighGCThresh3)
+	}
+
+	if config.IPv6RouteCacheMaxSize <= 0 {
+		invalid("ipv6-route-cache-max-size", "set it to a positive number, e.g., 524288",
+			"size %v is not positive", config.IPv6RouteCacheMaxSize)
+	}
+	if config.IPv6RouteCacheGCThresh <= 0 || config.IPv6RouteCacheGCThresh > config.IPv6RouteCacheMaxSize {
+		invalid("ipv6-route-cache-gc-thresh", "set it to a positive number not larger than --ipv6-route-cache-max-size",
+			"threshold %v is out of range", config.IPv6RouteCacheGCThresh)
+	}
+
+	if config.RemoteRouteDefaultMetric < 0 {
+		invalid("remote-route-default-metric", "set it to a positive number, or 0 for kernel default",
+			"metric %v is negative", config.RemoteRouteDefaultMetric)
+	}
+
+	if config.EnableBFD {
+		if config.BFDTxInterval < time.Millisecond {
+			invalid("bfd-tx-interval", "set it to at least 1ms, e.g., 300ms",
+				"interval %v is less than 1ms", config.BFDTxInterval)
+		}
+		if config.BFDDetectMultiplier < 1 || config.BFDDetectMultiplier > 255 {
+			invalid("bfd-detect-multiplier", "set it in range 1 to 255, e.g., 3",
+				"multiplier %v is out of range", config.BFDDetectMultiplier)
+		}
+	}
+
+	if config.EnableMTUProbe {
+		validatePositiveDuration("mtu-probe-interval", config.MTUProbeInterval)
+		validatePort("mtu-probe-port", config.MTUProbePort)
+		if config.MTUProbePort == config.VxlanUDPPort {
+			invalid("mtu-probe-port", "choose a port different from --vxlan-udp-port",
+				"port %v is already used by vxlan tunnels", config.MTUProbePort)
+		}
+	}
+
+	if config.EnableOverlayLatencyProbe {
+		validatePositiveDuration("overlay-latency-probe-interval", config.OverlayLatencyProbeInterval)
+	}
+
+	if len(config.HostVRFName) > unix.IFNAMSIZ-1 {
+		invalid("host-vrf-name", "use the name of an existing VRF device",
+			"name %q is longer than %v characters", config.HostVRFName, unix.IFNAMSIZ-1)
+	}
+
+	if len(config.OAMAgentAddress) != 0 {
+		if _, _, err := net.SplitHostPort(config.OAMAgentAddress); err != nil {
+			invalid("oam-agent-address", "set it in host:port format, e.g., 127.0.0.1:8809",
+				"address %q is illegal: %v", config.OAMAgentAddress, err)
+		}
+	}
+
+	if config.EnableDHCPSnooping && len(config.DHCPSnoopingNamespace) == 0 {
+		invalid("dhcp-snooping-namespace", "set it to an existing namespace, e.g., kube-system",
+			"namespace is required when dhcp snooping is enabled")
+	}
+
+	return errs
+}
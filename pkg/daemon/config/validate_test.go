@@ -0,0 +1,190 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func validConfiguration() *Configuration {
+	return &Configuration{
+		BindSocket:                           "/var/run/hybridnet.sock",
+		NodeVlanIfName:                       "eth0",
+		NodeVxlanIfName:                      "eth0",
+		NodeBGPIfName:                        "eth0",
+		HealthyServerAddress:                 DefaultHealthyServerBindAddress,
+		MetricsServerAddress:                 DefaultMetricsServerBindAddress,
+		BGPgRPCServerAddress:                 DefaultBGPgRPCServerBindAddress,
+		VxlanUDPPort:                         DefaultVxlanUDPPort,
+		VlanCheckTimeout:                     DefaultVlanCheckTimeout,
+		IptablesCheckDuration:                DefaultIPtablesCheckDuration,
+		VxlanBaseReachableTime:               DefaultVxlanBaseReachableTime,
+		VxlanExpiredNeighCachesClearInterval: DefaultVxlanExpiredNeighCachesClearInterval,
+		LLDPDiscoveryInterval:                DefaultLLDPDiscoveryInterval,
+		IPAMRebuildTimeout:                   DefaultIPAMRebuildTimeout,
+		CNIAddWorkers:                        DefaultCNIAddWorkers,
+		CNIDelWorkers:                        DefaultCNIDelWorkers,
+		CNICheckWorkers:                      DefaultCNICheckWorkers,
+		CNIAddTimeout:                        DefaultCNIAddTimeout,
+		CNIDelTimeout:                        DefaultCNIDelTimeout,
+		CNICheckTimeout:                      DefaultCNICheckTimeout,
+		BFDTxInterval:                        DefaultBFDTxInterval,
+		BFDDetectMultiplier:                  DefaultBFDDetectMultiplier,
+		MTUProbeInterval:                     DefaultMTUProbeInterval,
+		MTUProbePort:                         DefaultMTUProbePort,
+		OverlayLatencyProbeInterval:          DefaultOverlayLatencyProbeInterval,
+		LocalDirectTableNum:                  DefaultLocalDirectTableNum,
+		ToOverlaySubnetTableNum:              DefaultToOverlaySubnetTableNum,
+		OverlayMarkTableNum:                  DefaultOverlayMarkTableNum,
+		NeighGCThresh1:                       DefaultNeighGCThresh1,
+		NeighGCThresh2:                       DefaultNeighGCThresh2,
+		NeighGCThresh3:                       DefaultNeighGCThresh3,
+		IPv6RouteCacheMaxSize:                DefaultIPv6RouteCacheMaxSize,
+		IPv6RouteCacheGCThresh:               DefaultIPv6RouteCacheGCThresh,
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(config *Configuration)
+		expectedFlags []string
+	}{
+		{
+			name:   "defaults",
+			modify: func(config *Configuration) {},
+		},
+		{
+			name: "invalid vxlan port and empty interface",
+			modify: func(config *Configuration) {
+				config.VxlanUDPPort = 70000
+				config.NodeVxlanIfName = ""
+			},
+			expectedFlags: []string{"prefer-vxlan-interfaces", "vxlan-udp-port"},
+		},
+		{
+			name: "non-positive durations",
+			modify: func(config *Configuration) {
+				config.VlanCheckTimeout = 0
+				config.LLDPDiscoveryInterval = -1
+			},
+			expectedFlags: []string{"vlan-check-timeout", "lldp-discovery-interval"},
+		},
+		{
+			name: "invalid cni worker pools",
+			modify: func(config *Configuration) {
+				config.CNIDelWorkers = 0
+				config.CNICheckTimeout = 0
+			},
+			expectedFlags: []string{"cni-del-workers", "cni-check-timeout"},
+		},
+		{
+			name: "conflicted and reserved route tables",
+			modify: func(config *Configuration) {
+				config.ToOverlaySubnetTableNum = config.LocalDirectTableNum
+				config.OverlayMarkTableNum = 254
+			},
+			expectedFlags: []string{"to-overlay-table", "overlay-mark-table"},
+		},
+		{
+			name: "conflicted cluster route tables",
+			modify: func(config *Configuration) {
+				config.ClusterRouteTables = map[string]int{
+					"prod":    41000,
+					"staging": 41000,
+					"test":    20000,
+				}
+			},
+			expectedFlags: []string{"cluster-route-table", "cluster-route-table"},
+		},
+		{
+			name: "bfd flags are only checked if enabled",
+			modify: func(config *Configuration) {
+				config.BFDDetectMultiplier = 0
+			},
+		},
+		{
+			name: "invalid bfd and mtu probe flags",
+			modify: func(config *Configuration) {
+				config.EnableBFD = true
+				config.BFDDetectMultiplier = 0
+				config.EnableMTUProbe = true
+				config.MTUProbePort = config.VxlanUDPPort
+			},
+			expectedFlags: []string{"bfd-detect-multiplier", "mtu-probe-port"},
+		},
+		{
+			name: "disordered neigh gc thresholds",
+			modify: func(config *Configuration) {
+				config.NeighGCThresh1 = config.NeighGCThresh3 + 1
+			},
+			expectedFlags: []string{"neigh-gc-thresh1"},
+		},
+		{
+			name: "oam agent address without port",
+			modify: func(config *Configuration) {
+				config.OAMAgentAddress = "127.0.0.1"
+			},
+			expectedFlags: []string{"oam-agent-address"},
+		},
+		{
+			name: "zero overlay latency probe interval",
+			modify: func(config *Configuration) {
+				config.EnableOverlayLatencyProbe = true
+				config.OverlayLatencyProbeInterval = 0
+			},
+			expectedFlags: []string{"overlay-latency-probe-interval"},
+		},
+		{
+			name: "dhcp snooping without namespace",
+			modify: func(config *Configuration) {
+				config.EnableDHCPSnooping = true
+				config.DHCPSnoopingNamespace = ""
+			},
+			expectedFlags: []string{"dhcp-snooping-namespace"},
+		},
+		{
+			name: "too long host vrf name",
+			modify: func(config *Configuration) {
+				config.HostVRFName = "hybridnet-vrf-tenant"
+			},
+			expectedFlags: []string{"host-vrf-name"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := validConfiguration()
+			test.modify(config)
+
+			errs := ValidateConfiguration(config)
+			if len(errs) != len(test.expectedFlags) {
+				t.Fatalf("expect %d errors, got %v", len(test.expectedFlags), errs)
+			}
+			for i, err := range errs {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("unexpected error type %T", err)
+				}
+				if validationErr.Flag != test.expectedFlags[i] {
+					t.Errorf("expect error of flag %s, got %v", test.expectedFlags[i], err)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@
 package containernetwork
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"time"
@@ -36,12 +37,25 @@ import (
 	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
 )
 
-func ConfigureHostNic(nicName string, allocatedIPs map[networkingv1.IPVersion]*daemonutils.IPInfo, localDirectTableNum int) error {
+// ConfigureHostNic configures the host side nic of pod and installs routes of pod ips into local direct table.
+// If hostVRFName is not empty, the nic will be attached to the VRF and routes will also be installed into
+// table of the VRF, while routes in local direct table are kept for host to reach pods, e.g., health probes.
+func ConfigureHostNic(nicName string, allocatedIPs map[networkingv1.IPVersion]*daemonutils.IPInfo, localDirectTableNum int,
+	hostVRFName string) error {
 	hostLink, err := netlink.LinkByName(nicName)
 	if err != nil {
 		return fmt.Errorf("can not find host nic %s %v", nicName, err)
 	}
 
+	podRouteTables := []int{localDirectTableNum}
+	if len(hostVRFName) != 0 {
+		vrf, _, err := daemonutils.EnsureLinkInVRF(hostLink, hostVRFName)
+		if err != nil {
+			return err
+		}
+		podRouteTables = append(podRouteTables, int(vrf.Table))
+	}
+
 	if err = netlink.LinkSetUp(hostLink); err != nil {
 		return fmt.Errorf("can not set host nic %s up %v", nicName, err)
 	}
@@ -92,17 +106,19 @@ func ConfigureHostNic(nicName string, allocatedIPs map[networkingv1.IPVersion]*d
 		}
 
 		mask := net.IPMask(net.ParseIP(constants.DefaultIP4Mask).To4())
-		localPodRoute := &netlink.Route{
-			LinkIndex: hostLink.Attrs().Index,
-			Dst: &net.IPNet{
-				IP:   allocatedIPs[networkingv1.IPv4].Addr,
-				Mask: mask,
-			},
-			Table: localDirectTableNum,
-		}
+		for _, table := range podRouteTables {
+			localPodRoute := &netlink.Route{
+				LinkIndex: hostLink.Attrs().Index,
+				Dst: &net.IPNet{
+					IP:   allocatedIPs[networkingv1.IPv4].Addr,
+					Mask: mask,
+				},
+				Table: table,
+			}
 
-		if err := netlink.RouteReplace(localPodRoute); err != nil {
-			return fmt.Errorf("failed to add route %v: %v", localPodRoute.String(), err)
+			if err := netlink.RouteReplace(localPodRoute); err != nil {
+				return fmt.Errorf("failed to add route %v: %v", localPodRoute.String(), err)
+			}
 		}
 	}
 
@@ -126,17 +142,19 @@ func ConfigureHostNic(nicName string, allocatedIPs map[networkingv1.IPVersion]*d
 		}
 
 		mask := net.IPMask(net.ParseIP(constants.DefaultIP6Mask).To16())
-		localPodRoute := &netlink.Route{
-			LinkIndex: hostLink.Attrs().Index,
-			Dst: &net.IPNet{
-				IP:   allocatedIPs[networkingv1.IPv6].Addr,
-				Mask: mask,
-			},
-			Table: localDirectTableNum,
-		}
+		for _, table := range podRouteTables {
+			localPodRoute := &netlink.Route{
+				LinkIndex: hostLink.Attrs().Index,
+				Dst: &net.IPNet{
+					IP:   allocatedIPs[networkingv1.IPv6].Addr,
+					Mask: mask,
+				},
+				Table: table,
+			}
 
-		if err := netlink.RouteReplace(localPodRoute); err != nil {
-			return fmt.Errorf("failed to add route %v: %v", localPodRoute.String(), err)
+			if err := netlink.RouteReplace(localPodRoute); err != nil {
+				return fmt.Errorf("failed to add route %v: %v", localPodRoute.String(), err)
+			}
 		}
 
 		if err := netlink.NeighAdd(&netlink.Neigh{
@@ -154,7 +172,7 @@ func ConfigureHostNic(nicName string, allocatedIPs map[networkingv1.IPVersion]*d
 }
 
 func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, allocatedIPs map[networkingv1.IPVersion]*daemonutils.IPInfo,
-	macAddr net.HardwareAddr, netns ns.NetNS, mtu int, vlanCheckTimeout time.Duration, networkMode networkingv1.NetworkMode,
+	macAddr net.HardwareAddr, netns ns.NetNS, mtu int, vlanCheckTimeout time.Duration, skipVlanCheck bool, networkMode networkingv1.NetworkMode,
 	neighGCThresh1, neighGCThresh2, neighGCThresh3, ipv6RouteCacheMaxSize, ipv6RouteCacheGCThresh int,
 	bgpManager *bgp.Manager) error {
 
@@ -173,6 +191,7 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 			Dst: net.IPNet{IP: net.ParseIP("0.0.0.0").To4(), Mask: net.CIDRMask(0, 32)},
 			GW:  net.ParseIP(constants.PodVirtualV4DefaultGateway),
 		})
+		defaultRouteNets = append(defaultRouteNets, allocatedIPs[networkingv1.IPv4].Routes...)
 
 		podIP := allocatedIPs[networkingv1.IPv4].Addr
 		podCidr := allocatedIPs[networkingv1.IPv4].Cidr
@@ -211,9 +230,12 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 				return fmt.Errorf("get a nil gateway for ip %v", allocatedIPs[networkingv1.IPv4].Addr)
 			}
 
-			if err := arp.CheckWithTimeout(forwardNodeIf, podIP,
-				allocatedIPs[networkingv1.IPv4].Gw, vlanCheckTimeout); err != nil {
-				return fmt.Errorf("failed to check ipv4 vlan environment: %v", err)
+			// ip of pod programmed before daemon restarts has already been checked and announced
+			if !skipVlanCheck {
+				if err := arp.CheckWithTimeout(forwardNodeIf, podIP,
+					allocatedIPs[networkingv1.IPv4].Gw, vlanCheckTimeout); err != nil {
+					return fmt.Errorf("failed to check ipv4 vlan environment: %v", err)
+				}
 			}
 		}
 
@@ -235,6 +257,7 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 			Dst: net.IPNet{IP: net.ParseIP("::").To16(), Mask: net.CIDRMask(0, 128)},
 			GW:  net.ParseIP(constants.PodVirtualV6DefaultGateway),
 		})
+		defaultRouteNets = append(defaultRouteNets, allocatedIPs[networkingv1.IPv6].Routes...)
 
 		podIP := allocatedIPs[networkingv1.IPv6].Addr
 		podCidr := allocatedIPs[networkingv1.IPv6].Cidr
@@ -265,9 +288,11 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 				return fmt.Errorf("get a nil gateway for ip %v", allocatedIPs[networkingv1.IPv6].Addr)
 			}
 
-			if err := ndp.CheckWithTimeout(forwardNodeIf, podIP,
-				allocatedIPs[networkingv1.IPv6].Gw, vlanCheckTimeout); err != nil {
-				return fmt.Errorf("failed to check ipv6 vlan environment: %v", err)
+			if !skipVlanCheck {
+				if err := ndp.CheckWithTimeout(forwardNodeIf, podIP,
+					allocatedIPs[networkingv1.IPv6].Gw, vlanCheckTimeout); err != nil {
+					return fmt.Errorf("failed to check ipv6 vlan environment: %v", err)
+				}
 			}
 		}
 
@@ -291,6 +316,17 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 		if err != nil {
 			return err
 		}
+
+		// The mac address recorded in IPInstances must be programmed before the nic is set up,
+		// otherwise the kernel-assigned random one will be reported in cni result and used to
+		// generate the ipv6 link-local address, which changes every time a stateful pod restarts.
+		if err = ensureLinkHardwareAddr(link, macAddr); err != nil {
+			return err
+		}
+		if link, err = netlink.LinkByName(constants.ContainerNicName); err != nil {
+			return err
+		}
+
 		containerInterface := &current.Interface{
 			Name:    link.Attrs().Name,
 			Mac:     link.Attrs().HardwareAddr.String(),
@@ -317,10 +353,6 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 			return fmt.Errorf("failed to config container nic: %v", err)
 		}
 
-		if err = netlink.LinkSetHardwareAddr(link, macAddr); err != nil {
-			return fmt.Errorf("can not set mac address to nic %s %v", link, err)
-		}
-
 		if err = netlink.LinkSetMTU(link, mtu); err != nil {
 			return fmt.Errorf("can not set nic %s mtu %v", link, err)
 		}
@@ -332,6 +364,18 @@ func ConfigureContainerNic(containerNicName, hostNicName, nodeIfName string, all
 	return nil
 }
 
+// ensureLinkHardwareAddr sets the mac address of link if it is different
+func ensureLinkHardwareAddr(link netlink.Link, macAddr net.HardwareAddr) error {
+	if bytes.Equal(link.Attrs().HardwareAddr, macAddr) {
+		return nil
+	}
+
+	if err := netlink.LinkSetHardwareAddr(link, macAddr); err != nil {
+		return fmt.Errorf("can not set mac address %s to nic %s: %v", macAddr, link.Attrs().Name, err)
+	}
+	return nil
+}
+
 func ensureForwardNodeIf(networkMode networkingv1.NetworkMode, nodeIfName string, netID *int32) (
 	forwardNodeIf *net.Interface, err error) {
 	var forwardNodeIfName string
@@ -0,0 +1,172 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package containernetwork
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/current"
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+// ConfigureContainerVF moves the net device of virtual function into container netns as the
+// container nic, pods are attached to underlay network directly through virtual functions, so
+// that the gateways of subnets are used as default gateways instead of virtual ones
+func ConfigureContainerVF(vfNetdevName string, allocatedIPs map[networkingv1.IPVersion]*daemonutils.IPInfo,
+	netns ns.NetNS, mtu int) error {
+
+	var defaultRouteNets []*types.Route
+	var ipConfigs []*current.IPConfig
+
+	for _, version := range []networkingv1.IPVersion{networkingv1.IPv4, networkingv1.IPv6} {
+		ipInfo := allocatedIPs[version]
+		if ipInfo == nil {
+			continue
+		}
+		if ipInfo.Gw == nil {
+			return fmt.Errorf("get a nil gateway for ip %v", ipInfo.Addr)
+		}
+
+		ipVersion, defaultDst := "4", net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+		if version == networkingv1.IPv6 {
+			ipVersion, defaultDst = "6", net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)}
+		}
+
+		defaultRouteNets = append(defaultRouteNets, &types.Route{
+			Dst: defaultDst,
+			GW:  ipInfo.Gw,
+		})
+		defaultRouteNets = append(defaultRouteNets, ipInfo.Routes...)
+
+		ipConfigs = append(ipConfigs, &current.IPConfig{
+			Version: ipVersion,
+			Address: net.IPNet{
+				IP:   ipInfo.Addr,
+				Mask: ipInfo.Cidr.Mask,
+			},
+			Interface: current.Int(0),
+		})
+	}
+
+	vfLink, err := netlink.LinkByName(vfNetdevName)
+	if err != nil {
+		return fmt.Errorf("can not find net device %s of virtual function: %v", vfNetdevName, err)
+	}
+
+	if err = netlink.LinkSetDown(vfLink); err != nil {
+		return fmt.Errorf("failed to set link %s down: %v", vfNetdevName, err)
+	}
+
+	if err = netlink.LinkSetNsFd(vfLink, int(netns.Fd())); err != nil {
+		return fmt.Errorf("failed to move link %s to netns %v: %v", vfNetdevName, netns.Path(), err)
+	}
+
+	return ns.WithNetNSPath(netns.Path(), func(_ ns.NetNS) error {
+		containerLink, err := netlink.LinkByName(vfNetdevName)
+		if err != nil {
+			return fmt.Errorf("can not find container nic %s %v", vfNetdevName, err)
+		}
+
+		if err = netlink.LinkSetName(containerLink, constants.ContainerNicName); err != nil {
+			return err
+		}
+
+		if err = netlink.LinkSetMTU(containerLink, mtu); err != nil {
+			return fmt.Errorf("can not set nic %s mtu %v", constants.ContainerNicName, err)
+		}
+
+		link, err := netlink.LinkByName(constants.ContainerNicName)
+		if err != nil {
+			return err
+		}
+
+		if allocatedIPs[networkingv1.IPv6] != nil {
+			sysctlPath := fmt.Sprintf(constants.AcceptDADSysctl, constants.ContainerNicName)
+			if err := daemonutils.SetSysctl(sysctlPath, 0); err != nil {
+				return fmt.Errorf("failed to set sysctl parameter %s to %v: %v", sysctlPath, 0, err)
+			}
+		}
+
+		result := &current.Result{
+			IPs:    ipConfigs,
+			Routes: defaultRouteNets,
+			Interfaces: []*current.Interface{{
+				Name:    link.Attrs().Name,
+				Mac:     link.Attrs().HardwareAddr.String(),
+				Sandbox: netns.Path(),
+			}},
+		}
+
+		if err := daemonutils.ConfigureIface(constants.ContainerNicName, result); err != nil {
+			return fmt.Errorf("failed to config container nic: %v", err)
+		}
+		return nil
+	})
+}
+
+// GetContainerVFMAC returns the mac address of container nic if it is the net device of a
+// virtual function, or a nil mac otherwise
+func GetContainerVFMAC(netns ns.NetNS) (net.HardwareAddr, error) {
+	var mac net.HardwareAddr
+
+	err := netns.Do(func(_ ns.NetNS) error {
+		link, err := netlink.LinkByName(constants.ContainerNicName)
+		if err != nil {
+			if _, ok := err.(netlink.LinkNotFoundError); ok {
+				return nil
+			}
+			return fmt.Errorf("failed to get container nic: %v", err)
+		}
+
+		// net devices of virtual functions are physical devices rather than veth
+		if _, isVeth := link.(*netlink.Veth); !isVeth {
+			mac = link.Attrs().HardwareAddr
+		}
+		return nil
+	})
+
+	return mac, err
+}
+
+// ReturnContainerVF moves the net device of virtual function out of container netns, and
+// renames it to vfNetdevName in host netns
+func ReturnContainerVF(netns, hostNS ns.NetNS, vfNetdevName string) error {
+	return netns.Do(func(_ ns.NetNS) error {
+		link, err := netlink.LinkByName(constants.ContainerNicName)
+		if err != nil {
+			return fmt.Errorf("failed to get container nic: %v", err)
+		}
+
+		if err = netlink.LinkSetDown(link); err != nil {
+			return fmt.Errorf("failed to set container nic down: %v", err)
+		}
+		if err = netlink.LinkSetName(link, vfNetdevName); err != nil {
+			return fmt.Errorf("failed to rename container nic to %s: %v", vfNetdevName, err)
+		}
+		if err = netlink.LinkSetNsFd(link, int(hostNS.Fd())); err != nil {
+			return fmt.Errorf("failed to move link %s to host netns: %v", vfNetdevName, err)
+		}
+		return nil
+	})
+}
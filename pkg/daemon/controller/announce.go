@@ -0,0 +1,111 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/arp"
+	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+// announceUnderlayPodsOnLink sends gratuitous arps for all the local vlan pods forwarded by the link,
+// it is supposed to be called while the link recovers, in case arp caches of remote hosts are stale.
+func (c *CtrlHub) announceUnderlayPodsOnLink(ctx context.Context, linkName string) error {
+	podIPsOfLink, podOfIP, err := c.listUnderlayPodIPsOnLinks(ctx, map[string]bool{linkName: true})
+	if err != nil {
+		return err
+	}
+
+	if len(podIPsOfLink[linkName]) == 0 {
+		return nil
+	}
+
+	link, err := net.InterfaceByName(linkName)
+	if err != nil {
+		return fmt.Errorf("failed to get interface %v: %v", linkName, err)
+	}
+
+	return arp.GratuitousBatch(ctx, link, podIPsOfLink[linkName], c.gratuitousARPLimiter, func(ip net.IP, err error) {
+		if err != nil {
+			c.logger.Error(err, "failed to resend gratuitous arp for pod", "pod", podOfIP[ip.String()],
+				"ip", ip.String(), "interface", linkName)
+			return
+		}
+		c.logger.Info("gratuitous arp resent for pod", "pod", podOfIP[ip.String()],
+			"ip", ip.String(), "interface", linkName)
+	})
+}
+
+// listUnderlayPodIPsOnLinks returns the IPv4 addresses of local vlan pods grouped by the links forwarding
+// them, only the links in linkNames are taken into account, and the pods of addresses are returned as well.
+func (c *CtrlHub) listUnderlayPodIPsOnLinks(ctx context.Context, linkNames map[string]bool) (map[string][]net.IP,
+	map[string]string, error) {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.mgr.GetClient().List(ctx, ipInstanceList,
+		client.MatchingLabels{constants.LabelNode: c.config.NodeName}); err != nil {
+		return nil, nil, fmt.Errorf("failed to list ip instances for node %v: %v", c.config.NodeName, err)
+	}
+
+	var podIPsOfLink = map[string][]net.IP{}
+	var podOfIP = map[string]string{}
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if networkingv1.IsReserved(ipInstance) || ipInstance.Spec.Address.Version != networkingv1.IPv4 {
+			continue
+		}
+
+		network := &networkingv1.Network{}
+		if err := c.mgr.GetClient().Get(ctx, client.ObjectKey{Name: ipInstance.Spec.Network}, network); err != nil {
+			return nil, nil, fmt.Errorf("failed to get network for ip instance %v: %v", ipInstance.Name, err)
+		}
+
+		if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan {
+			continue
+		}
+
+		vlanParentIfName, err := daemonutils.GetVlanParentIfName(c.config.NodeVlanIfName, network)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get vlan parent interface name: %v", err)
+		}
+
+		forwardNodeIfName, err := daemonutils.GenerateVlanNetIfName(vlanParentIfName, ipInstance.Spec.Address.NetID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate vlan forward node interface name: %v", err)
+		}
+
+		if !linkNames[forwardNodeIfName] {
+			continue
+		}
+
+		podIP, _, err := net.ParseCIDR(ipInstance.Spec.Address.IP)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse pod ip %v: %v", ipInstance.Spec.Address.IP, err)
+		}
+
+		podIPsOfLink[forwardNodeIfName] = append(podIPsOfLink[forwardNodeIfName], podIP)
+		podOfIP[podIP.String()] = ipInstance.Namespace + "/" + ipInstance.Labels[constants.LabelPod]
+	}
+
+	return podIPsOfLink, podOfIP, nil
+}
@@ -0,0 +1,343 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/daemon/arp"
+	"github.com/alibaba/hybridnet/pkg/daemon/bfd"
+	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+	"github.com/alibaba/hybridnet/pkg/feature"
+)
+
+const (
+	bfdPeerSyncInterval = 30 * time.Second
+	bfdARPCheckTimeout  = 3 * time.Second
+)
+
+// bfdGatewayInterfaces records the forward interfaces of underlay gateways with bfd sessions.
+type bfdGatewayInterfaces struct {
+	mu      sync.Mutex
+	ifNames map[string]string
+}
+
+func (g *bfdGatewayInterfaces) set(ifNames map[string]string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ifNames = ifNames
+}
+
+func (g *bfdGatewayInterfaces) get(gateway net.IP) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.ifNames[gateway.String()]
+}
+
+// bfdSessionLoop will maintain bfd sessions from node to the gateways of underlay vlan subnets
+// and to remote vteps, so that failures can be detected much faster than arp probes. Once a
+// session goes down, gateways will be arp checked and routes will be re-synced immediately.
+func (c *CtrlHub) bfdSessionLoop(ctx context.Context) error {
+	if !c.config.EnableBFD {
+		return nil
+	}
+
+	gatewayInterfaces := &bfdGatewayInterfaces{}
+	manager := bfd.NewManager(c.config.BFDTxInterval, uint8(c.config.BFDDetectMultiplier),
+		func(peer bfd.Peer, state bfd.State, _ bfd.Diagnostic) {
+			c.handleBFDStateChange(ctx, gatewayInterfaces, peer, state)
+		}, c.logger.WithName("bfd"))
+
+	if err := manager.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start bfd manager: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(bfdPeerSyncInterval)
+		defer ticker.Stop()
+
+		for {
+			// wait for cache to be synced before listing resources
+			if c.CacheSynced(ctx) {
+				peers, ifNames, err := c.collectBFDPeers(ctx)
+				if err != nil {
+					c.logger.Error(err, "failed to collect bfd peers")
+				} else {
+					gatewayInterfaces.set(ifNames)
+					manager.SetPeers(peers)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// collectBFDPeers returns the bfd peers of node, and the forward interfaces of gateway peers.
+func (c *CtrlHub) collectBFDPeers(ctx context.Context) ([]bfd.Peer, map[string]string, error) {
+	var peers []bfd.Peer
+	ifNames := map[string]string{}
+
+	subnetList := &networkingv1.SubnetList{}
+	if err := c.mgr.GetClient().List(ctx, subnetList); err != nil {
+		return nil, nil, fmt.Errorf("failed to list subnet: %v", err)
+	}
+
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		gateway := net.ParseIP(subnet.Spec.Range.Gateway)
+		if gateway == nil {
+			continue
+		}
+
+		network := &networkingv1.Network{}
+		if err := c.mgr.GetClient().Get(ctx, types.NamespacedName{Name: subnet.Spec.Network}, network); err != nil {
+			return nil, nil, fmt.Errorf("failed to get network for subnet %v: %v", subnet.Name, err)
+		}
+
+		if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan ||
+			!nodeBelongsToNetwork(c.config.NodeName, network) {
+			continue
+		}
+
+		netID := subnet.Spec.NetID
+		if netID == nil {
+			netID = network.Spec.NetID
+		}
+
+		vlanParentIfName, err := daemonutils.GetVlanParentIfName(c.config.NodeVlanIfName, network)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get vlan parent interface of network %v: %v", network.Name, err)
+		}
+
+		forwardNodeIfName, err := daemonutils.GenerateVlanNetIfName(vlanParentIfName, netID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate vlan forward interface name of subnet %v: %v", subnet.Name, err)
+		}
+
+		if _, exist := ifNames[gateway.String()]; !exist {
+			peers = append(peers, bfd.Peer{Address: gateway, Type: bfd.PeerTypeGateway})
+		}
+		ifNames[gateway.String()] = forwardNodeIfName
+	}
+
+	if feature.MultiClusterEnabled() {
+		// control packets to remote vteps are sent from the vtep address of this node, so that
+		// they go along the same path as vxlan packets
+		localVtepIP, err := c.localVtepIP(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if localVtepIP != nil {
+			remoteVtepList := &multiclusterv1.RemoteVtepList{}
+			if err := c.mgr.GetClient().List(ctx, remoteVtepList); err != nil {
+				return nil, nil, fmt.Errorf("failed to list remote vtep: %v", err)
+			}
+
+			for _, remoteVtep := range remoteVtepList.Items {
+				vtepIP := net.ParseIP(remoteVtep.Spec.VTEPInfo.IP)
+				if vtepIP == nil || (vtepIP.To4() == nil) != (localVtepIP.To4() == nil) {
+					continue
+				}
+				peers = append(peers, bfd.Peer{Address: vtepIP, Type: bfd.PeerTypeVtep, Source: localVtepIP})
+			}
+		}
+	}
+
+	return peers, ifNames, nil
+}
+
+// localVtepIP returns the vtep address of this node, nil if vtep of this node is not ready.
+func (c *CtrlHub) localVtepIP(ctx context.Context) (net.IP, error) {
+	nodeInfo := &networkingv1.NodeInfo{}
+	if err := c.mgr.GetClient().Get(ctx, types.NamespacedName{Name: c.config.NodeName}, nodeInfo); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node info of %v: %v", c.config.NodeName, err)
+	}
+
+	if nodeInfo.Spec.VTEPInfo == nil {
+		return nil, nil
+	}
+	return net.ParseIP(nodeInfo.Spec.VTEPInfo.IP), nil
+}
+
+func (c *CtrlHub) handleBFDStateChange(ctx context.Context, gatewayInterfaces *bfdGatewayInterfaces,
+	peer bfd.Peer, state bfd.State) {
+	switch peer.Type {
+	case bfd.PeerTypeGateway:
+		if state != bfd.StateDown {
+			return
+		}
+
+		if ifName := gatewayInterfaces.get(peer.Address); ifName != "" {
+			if err := checkGatewayThroughARP(ifName, peer.Address); err != nil {
+				c.logger.Error(err, "underlay gateway is unreachable after bfd session down",
+					"gateway", peer.Address.String())
+			}
+		}
+
+		// make sure routes of subnets are re-synced
+		c.subnetTriggerSourceForHostLink.Trigger()
+	case bfd.PeerTypeVtep:
+		if err := c.updateRemoteVtepBFDCondition(ctx, peer.Address, state); err != nil {
+			c.logger.Error(err, "failed to update bfd condition of remote vtep", "vtep", peer.Address.String())
+		}
+
+		if state == bfd.StateDown {
+			// make sure vxlan fdbs and routes of remote vteps are re-synced
+			c.nodeInfoTriggerSourceForVtepLink.Trigger()
+		}
+	}
+}
+
+func checkGatewayThroughARP(ifName string, gateway net.IP) error {
+	if gateway.To4() == nil {
+		return nil
+	}
+
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return fmt.Errorf("failed to get interface %v: %v", ifName, err)
+	}
+
+	if _, err = arp.ResolveWithTimeout(ifi, gateway, bfdARPCheckTimeout); err != nil {
+		return fmt.Errorf("failed to resolve gateway %v over interface %v: %v", gateway.String(), ifName, err)
+	}
+	return nil
+}
+
+// updateRemoteVtepBFDCondition reflects the bfd session state of node to remote vtep in the
+// status conditions of RemoteVtep, only the condition owned by this node is patched because
+// every node in cluster keeps its own condition on the same RemoteVtep.
+func (c *CtrlHub) updateRemoteVtepBFDCondition(ctx context.Context, vtepIP net.IP, state bfd.State) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		// conflicts of resource version or failed tests of json patch
+		return errors.IsConflict(err) || errors.IsInvalid(err)
+	}, func() error {
+		remoteVtepList := &multiclusterv1.RemoteVtepList{}
+		if err := c.mgr.GetClient().List(ctx, remoteVtepList); err != nil {
+			return fmt.Errorf("failed to list remote vtep: %v", err)
+		}
+
+		for i := range remoteVtepList.Items {
+			remoteVtep := &remoteVtepList.Items[i]
+			if !vtepIP.Equal(net.ParseIP(remoteVtep.Spec.VTEPInfo.IP)) {
+				continue
+			}
+
+			status := metav1.ConditionFalse
+			if state == bfd.StateUp {
+				status = metav1.ConditionTrue
+			}
+
+			patch, err := remoteVtepConditionPatch(remoteVtep, metav1.Condition{
+				Type:    multiclusterv1.GetBFDSessionConditionType(c.config.NodeName),
+				Status:  status,
+				Reason:  state.String(),
+				Message: fmt.Sprintf("bfd session from node %v to vtep %v is %v", c.config.NodeName, vtepIP.String(), state.String()),
+			})
+			if err != nil {
+				return err
+			}
+
+			if patch == nil {
+				continue
+			}
+
+			if err := c.mgr.GetClient().Status().Patch(ctx, remoteVtep, patch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// remoteVtepConditionPatch returns a patch which sets only the given condition of RemoteVtep,
+// nil if the condition is unchanged. The index of an existing condition is tested before being
+// replaced, and a new condition is appended, so that conditions of other nodes are never overwritten.
+func remoteVtepConditionPatch(remoteVtep *multiclusterv1.RemoteVtep, condition metav1.Condition) (client.Patch, error) {
+	existing := meta.FindStatusCondition(remoteVtep.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason {
+		return nil, nil
+	}
+
+	// conditions array need to be created, optimistic lock keeps it from replacing the one
+	// created by other nodes concurrently
+	if len(remoteVtep.Status.Conditions) == 0 {
+		patch := client.MergeFromWithOptions(remoteVtep.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		meta.SetStatusCondition(&remoteVtep.Status.Conditions, condition)
+		return patch, nil
+	}
+
+	conditions := append([]metav1.Condition(nil), remoteVtep.Status.Conditions...)
+	meta.SetStatusCondition(&conditions, condition)
+	condition = *meta.FindStatusCondition(conditions, condition.Type)
+
+	var operations []jsonPatchOperation
+	if existing == nil {
+		operations = []jsonPatchOperation{
+			{Op: "add", Path: "/status/conditions/-", Value: condition},
+		}
+	} else {
+		index := 0
+		for index = range remoteVtep.Status.Conditions {
+			if remoteVtep.Status.Conditions[index].Type == condition.Type {
+				break
+			}
+		}
+		path := fmt.Sprintf("/status/conditions/%d", index)
+		operations = []jsonPatchOperation{
+			{Op: "test", Path: path + "/type", Value: condition.Type},
+			{Op: "replace", Path: path, Value: condition},
+		}
+	}
+
+	data, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json patch: %v", err)
+	}
+	return client.RawPatch(types.JSONPatchType, data), nil
+}
@@ -0,0 +1,111 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+)
+
+func TestRemoteVtepConditionPatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = multiclusterv1.AddToScheme(scheme)
+
+	condition := func(node string, status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{
+			Type:               multiclusterv1.GetBFDSessionConditionType(node),
+			Status:             status,
+			Reason:             reason,
+			Message:            "bfd session of " + node,
+			LastTransitionTime: metav1.Now(),
+		}
+	}
+
+	remoteVtep := &multiclusterv1.RemoteVtep{
+		ObjectMeta: metav1.ObjectMeta{Name: "vtep"},
+		Status: multiclusterv1.RemoteVtepStatus{
+			Conditions: []metav1.Condition{
+				condition("node1", metav1.ConditionTrue, "Up"),
+				condition("node2", metav1.ConditionTrue, "Up"),
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(remoteVtep).Build()
+
+	steps := []struct {
+		name      string
+		condition metav1.Condition
+	}{
+		{"update existing condition", condition("node2", metav1.ConditionFalse, "Down")},
+		{"add new condition", condition("node3", metav1.ConditionTrue, "Up")},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			current := &multiclusterv1.RemoteVtep{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "vtep"}, current); err != nil {
+				t.Fatalf("fail to get remote vtep: %v", err)
+			}
+
+			// condition of node1 is changed by others after the remote vtep is read
+			stale := current.DeepCopy()
+			meta.SetStatusCondition(&current.Status.Conditions, condition("node1", metav1.ConditionFalse, "Down"))
+			if err := c.Status().Update(context.Background(), current); err != nil {
+				t.Fatalf("fail to update remote vtep: %v", err)
+			}
+
+			patch, err := remoteVtepConditionPatch(stale, step.condition)
+			if err != nil || patch == nil {
+				t.Fatalf("fail to generate patch: %v", err)
+			}
+			if err = c.Status().Patch(context.Background(), stale, patch); err != nil {
+				t.Fatalf("fail to patch remote vtep: %v", err)
+			}
+
+			if err := c.Get(context.Background(), types.NamespacedName{Name: "vtep"}, current); err != nil {
+				t.Fatalf("fail to get remote vtep: %v", err)
+			}
+			if got := meta.FindStatusCondition(current.Status.Conditions, step.condition.Type); got == nil ||
+				got.Status != step.condition.Status {
+				t.Errorf("expect condition %s to be %s but got %v", step.condition.Type, step.condition.Status, got)
+			}
+			if got := meta.FindStatusCondition(current.Status.Conditions,
+				multiclusterv1.GetBFDSessionConditionType("node1")); got == nil || got.Status != metav1.ConditionFalse {
+				t.Errorf("expect condition of node1 not to be overwritten but got %v", got)
+			}
+
+			// restore condition of node1 for next step
+			meta.SetStatusCondition(&current.Status.Conditions, condition("node1", metav1.ConditionTrue, "Up"))
+			if err := c.Status().Update(context.Background(), current); err != nil {
+				t.Fatalf("fail to update remote vtep: %v", err)
+			}
+		})
+	}
+
+	patch, err := remoteVtepConditionPatch(remoteVtep, condition("node1", metav1.ConditionTrue, "Up"))
+	if err != nil || patch != nil {
+		t.Fatalf("expect no patch for unchanged condition but got %v, %v", patch, err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	"golang.org/x/time/rate"
+
+	"github.com/alibaba/hybridnet/pkg/daemon/arp"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+// bondActiveState identifies the active links of a bond, which is the active slave in
+// active-backup mode, or the active aggregator in 802.3ad mode
+func bondActiveState(bond *netlink.Bond) string {
+	if bond.Mode == netlink.BOND_MODE_802_3AD && bond.AdInfo != nil {
+		return fmt.Sprintf("aggregator/%d", bond.AdInfo.AggregatorId)
+	}
+	if bond.ActiveSlave > 0 {
+		return fmt.Sprintf("slave/%d", bond.ActiveSlave)
+	}
+	return ""
+}
+
+// handleBondFailover sends gratuitous arps for local underlay pods on a bond and its vlan links once the
+// active links of bond change, because the switches may still forward traffic of pods to the failed ports
+// until their mac tables are refreshed.
+func (c *CtrlHub) handleBondFailover(update netlink.LinkUpdate, bondActiveStateMap map[int]string) {
+	bond, ok := update.Link.(*netlink.Bond)
+	if !ok {
+		return
+	}
+
+	linkIndex, linkName := bond.Attrs().Index, bond.Attrs().Name
+	if update.Header.Type == unix.RTM_DELLINK {
+		delete(bondActiveStateMap, linkIndex)
+		return
+	}
+
+	activeState := bondActiveState(bond)
+	lastActiveState, exist := bondActiveStateMap[linkIndex]
+	bondActiveStateMap[linkIndex] = activeState
+
+	// the first event of bond after daemon starting is ignored
+	if !exist || len(lastActiveState) == 0 || len(activeState) == 0 || activeState == lastActiveState {
+		return
+	}
+
+	c.logger.Info("active links of bond change, announce underlay pods", "interface", linkName,
+		"from", lastActiveState, "to", activeState)
+
+	go func() {
+		if err := c.announceUnderlayPodsOnBond(context.TODO(), bond); err != nil {
+			c.logger.Error(err, "failed to announce underlay pods after bond failover", "interface", linkName)
+		}
+	}()
+}
+
+// announceUnderlayPodsOnBond sends gratuitous arps for the local underlay pods forwarded by the bond or the
+// vlan links on it. Arps are not rate limited, so that all the pods are announced right after failover.
+func (c *CtrlHub) announceUnderlayPodsOnBond(ctx context.Context, bond *netlink.Bond) error {
+	bondName := bond.Attrs().Name
+	linkNames := map[string]bool{bondName: true}
+
+	links, err := netlink.LinkList()
+	if err != nil {
+		return fmt.Errorf("failed to list links: %v", err)
+	}
+	for _, link := range links {
+		if link.Type() == "vlan" && link.Attrs().ParentIndex == bond.Attrs().Index {
+			linkNames[link.Attrs().Name] = true
+		}
+	}
+
+	podIPsOfLink, podOfIP, err := c.listUnderlayPodIPsOnLinks(ctx, linkNames)
+	if err != nil {
+		return err
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	for linkName, podIPs := range podIPsOfLink {
+		link, err := net.InterfaceByName(linkName)
+		if err != nil {
+			return fmt.Errorf("failed to get interface %v: %v", linkName, err)
+		}
+
+		if err = arp.GratuitousBatch(ctx, link, podIPs, limiter, func(ip net.IP, err error) {
+			if err != nil {
+				c.logger.Error(err, "failed to send gratuitous arp for pod after bond failover",
+					"pod", podOfIP[ip.String()], "ip", ip.String(), "interface", linkName)
+				return
+			}
+			metrics.LACPFailoverARPSentCounter.WithLabelValues(bondName).Inc()
+		}); err != nil {
+			return fmt.Errorf("failed to send gratuitous arps over %v: %v", linkName, err)
+		}
+	}
+
+	return nil
+}
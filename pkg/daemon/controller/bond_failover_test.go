@@ -0,0 +1,43 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestBondActiveState(t *testing.T) {
+	activeBackup := netlink.NewLinkBond(netlink.LinkAttrs{Name: "bond0"})
+	activeBackup.Mode = netlink.BOND_MODE_ACTIVE_BACKUP
+	if state := bondActiveState(activeBackup); state != "" {
+		t.Fatalf("expected no active state without active slave but got %q", state)
+	}
+
+	activeBackup.ActiveSlave = 3
+	if state := bondActiveState(activeBackup); state != "slave/3" {
+		t.Fatalf("expected active slave state but got %q", state)
+	}
+
+	lacp := netlink.NewLinkBond(netlink.LinkAttrs{Name: "bond1"})
+	lacp.Mode = netlink.BOND_MODE_802_3AD
+	lacp.AdInfo = &netlink.BondAdInfo{AggregatorId: 2}
+	if state := bondActiveState(lacp); state != "aggregator/2" {
+		t.Fatalf("expected active aggregator state but got %q", state)
+	}
+}
@@ -32,11 +32,13 @@ import (
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netns"
 	"golang.org/x/sys/unix"
+	"golang.org/x/time/rate"
 
 	"github.com/alibaba/hybridnet/pkg/daemon/bgp"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -49,6 +51,7 @@ import (
 	"github.com/alibaba/hybridnet/pkg/daemon/neigh"
 	"github.com/alibaba/hybridnet/pkg/daemon/route"
 	"github.com/alibaba/hybridnet/pkg/feature"
+	"github.com/alibaba/hybridnet/pkg/metrics"
 )
 
 const (
@@ -60,6 +63,9 @@ const (
 	AddrUpdateChainSize = 200
 
 	NetlinkSubscribeRetryInterval = 10 * time.Second
+
+	// GratuitousARPRateLimit is the max number of pods announced by gratuitous arps per second
+	GratuitousARPRateLimit = 100
 )
 
 type CtrlHub struct {
@@ -70,6 +76,7 @@ type CtrlHub struct {
 	subnetTriggerSourceForNodeInfoChange *simpleTriggerSource
 	ipInstanceTriggerSourceForHostLink   *simpleTriggerSource
 	nodeInfoTriggerSourceForHostAddr     *simpleTriggerSource
+	nodeInfoTriggerSourceForVtepLink     *simpleTriggerSource
 
 	routeV4Manager *route.Manager
 	routeV6Manager *route.Manager
@@ -88,6 +95,8 @@ type CtrlHub struct {
 
 	nodeIPCache *NodeIPCache
 
+	gratuitousARPLimiter *rate.Limiter
+
 	logger logr.Logger
 }
 
@@ -96,6 +105,8 @@ func NewCtrlHub(config *daemonconfig.Configuration, mgr ctrl.Manager, logger log
 		config.ToOverlaySubnetTableNum,
 		config.OverlayMarkTableNum,
 		netlink.FAMILY_V4,
+		config.EnableRemoteRouteCompression,
+		config.ClusterRouteTables,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ipv4 route manager: %v", err)
@@ -105,6 +116,8 @@ func NewCtrlHub(config *daemonconfig.Configuration, mgr ctrl.Manager, logger log
 		config.ToOverlaySubnetTableNum,
 		config.OverlayMarkTableNum,
 		netlink.FAMILY_V6,
+		config.EnableRemoteRouteCompression,
+		config.ClusterRouteTables,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ipv6 route manager: %v", err)
@@ -138,6 +151,7 @@ func NewCtrlHub(config *daemonconfig.Configuration, mgr ctrl.Manager, logger log
 		subnetTriggerSourceForNodeInfoChange: &simpleTriggerSource{key: "ForNodeInfo"},
 		ipInstanceTriggerSourceForHostLink:   &simpleTriggerSource{key: "ForHostLinkEvent"},
 		nodeInfoTriggerSourceForHostAddr:     &simpleTriggerSource{key: "ForHostAddr"},
+		nodeInfoTriggerSourceForVtepLink:     &simpleTriggerSource{key: "ForVtepLink"},
 
 		routeV4Manager: routeV4Manager,
 		routeV6Manager: routeV6Manager,
@@ -156,6 +170,8 @@ func NewCtrlHub(config *daemonconfig.Configuration, mgr ctrl.Manager, logger log
 
 		nodeIPCache: NewNodeIPCache(),
 
+		gratuitousARPLimiter: rate.NewLimiter(GratuitousARPRateLimit, 1),
+
 		logger: logger,
 	}
 
@@ -170,6 +186,9 @@ func NewCtrlHub(config *daemonconfig.Configuration, mgr ctrl.Manager, logger log
 func (c *CtrlHub) Run(ctx context.Context) error {
 	c.runHealthyServer()
 
+	// IPInstances are looked up by address through indexer instead of the ip address label,
+	// because IPInstances created before the label is introduced are never labeled unless
+	// schema migration is enabled
 	if err := c.mgr.GetFieldIndexer().IndexField(context.TODO(), &networkingv1.IPInstance{},
 		InstanceIPIndex, instanceIPIndexer); err != nil {
 		return fmt.Errorf("failed to add instance ip indexer to manager: %v", err)
@@ -213,6 +232,25 @@ func (c *CtrlHub) Run(ctx context.Context) error {
 
 	c.iptablesSyncLoop()
 
+	c.lldpDiscoveryLoop(ctx)
+
+	if err := c.bfdSessionLoop(ctx); err != nil {
+		return fmt.Errorf("failed to start bfd session loop: %v", err)
+	}
+
+	if err := c.mtuProbeLoop(ctx); err != nil {
+		return fmt.Errorf("failed to start mtu probe loop: %v", err)
+	}
+
+	c.vtepStatsLoop(ctx)
+
+	c.overlayLatencyProbeLoop(ctx)
+
+	c.oamRelayLoop(ctx)
+
+	c.dhcpSnoopingLoop(ctx)
+	c.ipamExcludeListLoop(ctx)
+
 	if err := c.mgr.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start controller manager: %v", err)
 	}
@@ -235,6 +273,10 @@ func (c *CtrlHub) GetBGPManager() *bgp.Manager {
 	return c.bgpManager
 }
 
+func (c *CtrlHub) GetEventRecorder() record.EventRecorder {
+	return c.mgr.GetEventRecorderFor("hybridnet-daemon")
+}
+
 // Once node network interface is set from down to up for some reasons, the routes and neigh caches for this interface
 // will be cleaned, which should cause unrecoverable problems. Listening "UP" netlink events for interfaces and
 // triggering subnet and ip instance reconcile loop will be the best way to recover routes and neigh caches.
@@ -247,6 +289,14 @@ func (c *CtrlHub) handleLocalNetworkDeviceEvent() error {
 	}
 
 	go func() {
+		// record the operational states of links to find out the links recovering from down
+		linkOperUpMap := map[int]bool{}
+		// record the administrative states of vtep links by name to find out the vtep links
+		// which are set up again or recreated
+		vtepLinkUpMap := map[string]bool{}
+		// record the active links of bonds to find out failovers
+		bondActiveStateMap := map[int]string{}
+
 		for {
 			linkCh := make(chan netlink.LinkUpdate, LinkUpdateChainSize)
 			exitCh := make(chan struct{})
@@ -276,6 +326,10 @@ func (c *CtrlHub) handleLocalNetworkDeviceEvent() error {
 						c.subnetTriggerSourceForHostLink.Trigger()
 						c.ipInstanceTriggerSourceForHostLink.Trigger()
 					}
+
+					c.handleLinkOperStateChange(update, linkOperUpMap)
+					c.handleVtepLinkStateChange(update, vtepLinkUpMap)
+					c.handleBondFailover(update, bondActiveStateMap)
 				case <-exitCh:
 					break linkLoop
 				}
@@ -328,6 +382,61 @@ func (c *CtrlHub) handleLocalNetworkDeviceEvent() error {
 	return nil
 }
 
+// handleLinkOperStateChange sends gratuitous arps for local underlay pods if a link comes up after being down,
+// e.g., the physical link recovers from failure
+func (c *CtrlHub) handleLinkOperStateChange(update netlink.LinkUpdate, linkOperUpMap map[int]bool) {
+	linkIndex, linkName := update.Link.Attrs().Index, update.Link.Attrs().Name
+	if update.Header.Type == unix.RTM_DELLINK {
+		delete(linkOperUpMap, linkIndex)
+		return
+	}
+
+	isOperUp := update.Link.Attrs().OperState == netlink.OperUp
+	wasOperUp, exist := linkOperUpMap[linkIndex]
+	linkOperUpMap[linkIndex] = isOperUp
+
+	if !exist || wasOperUp || !isOperUp || daemonutils.CheckIfContainerNetworkLink(linkName) {
+		return
+	}
+
+	go func() {
+		if err := c.announceUnderlayPodsOnLink(context.TODO(), linkName); err != nil {
+			c.logger.Error(err, "failed to announce underlay pods after link up", "interface", linkName)
+		}
+	}()
+}
+
+// handleVtepLinkStateChange triggers a full reconciliation of fdb entries and routes if the vtep link comes
+// up after being down or recreated, because all the fdb entries of remote vteps on it are lost
+func (c *CtrlHub) handleVtepLinkStateChange(update netlink.LinkUpdate, vtepLinkUpMap map[string]bool) {
+	if update.Link.Type() != "vxlan" {
+		return
+	}
+
+	linkName := update.Link.Attrs().Name
+	if update.Header.Type == unix.RTM_DELLINK {
+		// keep the record as down, a recreated vtep link should be considered as a reset
+		vtepLinkUpMap[linkName] = false
+		return
+	}
+
+	isUp := update.IfInfomsg.Flags&unix.IFF_UP != 0
+	wasUp, exist := vtepLinkUpMap[linkName]
+	vtepLinkUpMap[linkName] = isUp
+
+	// the first event of vtep link after daemon starting is ignored, because
+	// a full reconciliation will always happen when controllers start
+	if !exist || wasUp || !isUp {
+		return
+	}
+
+	c.logger.Info("vtep interface is reset, trigger reconciliation of fdb entries and routes", "interface", linkName)
+	metrics.VtepInterfaceResetsCounter.Inc()
+
+	c.nodeInfoTriggerSourceForVtepLink.Trigger()
+	c.subnetTriggerSourceForHostLink.Trigger()
+}
+
 func (c *CtrlHub) handleVxlanInterfaceNeighEvent() error {
 
 	ipSearch := func(ip net.IP, link netlink.Link) error {
@@ -396,6 +505,10 @@ func (c *CtrlHub) handleVxlanInterfaceNeighEvent() error {
 			return fmt.Errorf("failed to set neigh %v: %v", neighEntry.String(), err)
 		}
 
+		if c.config.EnableARPSuppression {
+			metrics.ARPSuppressedCounter.Inc()
+		}
+
 		c.logger.V(4).Info("neigh proxy resolve success", "ip", ip.String(), "mac", vtepMac.String())
 
 		return nil
@@ -605,7 +718,13 @@ func (c *CtrlHub) iptablesSyncLoop() {
 					netID = network.Spec.NetID
 				}
 
-				vlanForwardIfName, err := daemonutils.GenerateVlanNetIfName(c.config.NodeVlanIfName, netID)
+				vlanParentIfName, err := daemonutils.GetVlanParentIfName(c.config.NodeVlanIfName, network)
+				if err != nil {
+					c.logger.Error(err, "failed to get vlan parent interface name", "vlanMasterInterface", c.config.NodeVlanIfName, "network", network.Name)
+					continue
+				}
+
+				vlanForwardIfName, err := daemonutils.GenerateVlanNetIfName(vlanParentIfName, netID)
 				if err != nil {
 					c.logger.Error(err, "failed to generate vlan network interface name", "vlanMasterInterface", c.config.NodeVlanIfName, "netID", netID)
 					continue
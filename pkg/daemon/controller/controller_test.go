@@ -0,0 +1,71 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestInstanceIPIndexer(t *testing.T) {
+	tests := []struct {
+		name       string
+		ipInstance *networkingv1.IPInstance
+		expected   []string
+	}{
+		{
+			// IPInstances created before upgrading have no ip address label
+			name: "unlabeled ipv4",
+			ipInstance: &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "192-168-0-2", Namespace: "default"},
+				Spec: networkingv1.IPInstanceSpec{
+					Address: networkingv1.Address{IP: "192.168.0.2/24", Version: networkingv1.IPv4},
+				},
+			},
+			expected: []string{"192.168.0.2"},
+		},
+		{
+			name: "unlabeled ipv6",
+			ipInstance: &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "fe80--2", Namespace: "default"},
+				Spec: networkingv1.IPInstanceSpec{
+					Address: networkingv1.Address{IP: "fe80::2/64", Version: networkingv1.IPv6},
+				},
+			},
+			expected: []string{"fe80::2"},
+		},
+		{
+			name: "invalid address",
+			ipInstance: &networkingv1.IPInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "default"},
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if indexes := instanceIPIndexer(test.ipInstance); !reflect.DeepEqual(indexes, test.expected) {
+				t.Fatalf("expected indexes %v but got %v", test.expected, indexes)
+			}
+		})
+	}
+}
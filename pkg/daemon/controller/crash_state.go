@@ -0,0 +1,94 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// IPAMStateSummary summarizes the ip instances on this node, used in crash report
+func (c *CtrlHub) IPAMStateSummary() (interface{}, error) {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.mgr.GetClient().List(context.TODO(), ipInstanceList,
+		client.MatchingLabels{constants.LabelNode: c.config.NodeName}); err != nil {
+		return nil, fmt.Errorf("failed to list ip instances of node %v: %v", c.config.NodeName, err)
+	}
+
+	summary := struct {
+		Total    int            `json:"total"`
+		Reserved int            `json:"reserved"`
+		Subnets  map[string]int `json:"subnets"`
+	}{
+		Total:   len(ipInstanceList.Items),
+		Subnets: map[string]int{},
+	}
+
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		summary.Subnets[ipInstance.Spec.Subnet]++
+		if networkingv1.IsReserved(ipInstance) {
+			summary.Reserved++
+		}
+	}
+
+	return summary, nil
+}
+
+// VtepInterfaceState lists the state of vxlan interfaces on this node, used in crash report
+func (c *CtrlHub) VtepInterfaceState() (interface{}, error) {
+	linkList, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %v", err)
+	}
+
+	type vtepState struct {
+		Name      string `json:"name"`
+		Index     int    `json:"index"`
+		OperState string `json:"operState"`
+		MAC       string `json:"mac"`
+		MTU       int    `json:"mtu"`
+		VxlanID   int    `json:"vxlanID"`
+		SrcAddr   string `json:"srcAddr"`
+	}
+
+	var states []vtepState
+	for _, link := range linkList {
+		vxlanLink, ok := link.(*netlink.Vxlan)
+		if !ok {
+			continue
+		}
+
+		states = append(states, vtepState{
+			Name:      vxlanLink.Name,
+			Index:     vxlanLink.Index,
+			OperState: vxlanLink.OperState.String(),
+			MAC:       vxlanLink.HardwareAddr.String(),
+			MTU:       vxlanLink.MTU,
+			VxlanID:   vxlanLink.VxlanId,
+			SrcAddr:   vxlanLink.SrcAddr.String(),
+		})
+	}
+
+	return states, nil
+}
@@ -0,0 +1,319 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/dhcp"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const (
+	dhcpSnoopingRetryInterval = 10 * time.Second
+
+	// dhcpLeaseCheckInterval is the interval of releasing snooped IPInstances whose leases expire
+	dhcpLeaseCheckInterval = time.Minute
+)
+
+// dhcpSnoopingLoop listens DHCPACKs on the vlan interface of node, and records the snooped
+// IP/MAC bindings of underlay subnets as IPInstances, so that IPs assigned by external DHCP
+// servers are visible through hybridnet API.
+//
+// Broadcast DHCPACKs reach every node on the same vlan, so only the node which owns the
+// client, i.e., the leased MAC is of one of its links, records the lease. Interface is never
+// set to promiscuous mode because unicast DHCPACKs to local clients are received anyway.
+//
+// Snooped IPInstances are never bound to any pod or node, so they are not configured by
+// daemon and are reserved in IPAM of manager, until their leases expire without renewal.
+func (c *CtrlHub) dhcpSnoopingLoop(ctx context.Context) {
+	if !c.config.EnableDHCPSnooping {
+		return
+	}
+
+	go func() {
+		for {
+			ifi, err := net.InterfaceByName(c.config.NodeVlanIfName)
+			if err != nil {
+				err = fmt.Errorf("failed to get vlan interface %v: %v", c.config.NodeVlanIfName, err)
+			} else {
+				err = dhcp.Snoop(ctx, ifi, func(lease *dhcp.Lease) {
+					if !c.CacheSynced(ctx) {
+						return
+					}
+
+					local, err := isLocalHardwareAddr(lease.MAC)
+					if err != nil {
+						c.logger.Error(err, "failed to check owner of dhcp lease", "ip", lease.IP, "mac", lease.MAC)
+						return
+					}
+					if !local {
+						return
+					}
+
+					if err := c.recordDHCPLease(ctx, lease); err != nil {
+						c.logger.Error(err, "failed to record dhcp lease", "ip", lease.IP, "mac", lease.MAC)
+					}
+				})
+			}
+			if err != nil {
+				c.logger.Error(err, "failed to snoop dhcp")
+			}
+
+			select {
+			case <-time.After(dhcpSnoopingRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(dhcpLeaseCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !c.CacheSynced(ctx) {
+					continue
+				}
+				released, err := releaseExpiredDHCPLeases(ctx, c.mgr.GetClient(), c.config.DHCPSnoopingNamespace,
+					c.config.NodeName, time.Now())
+				if err != nil {
+					c.logger.Error(err, "failed to release expired dhcp leases")
+				}
+				for _, ip := range released {
+					c.logger.Info("expired dhcp lease released", "ip", ip)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// recordDHCPLease creates or updates the IPInstance of a snooped lease, in the underlay subnet
+// containing the leased IP. IPInstances are named by their IPs in all namespaces, so an existing
+// IPInstance of the IP is looked up by address, and the ones allocated to pods by hybridnet or
+// recorded by other nodes are never overwritten.
+//
+// IPAM of manager is refreshed once the IPInstance is created or deleted.
+func (c *CtrlHub) recordDHCPLease(ctx context.Context, lease *dhcp.Lease) error {
+	subnet, err := c.findUnderlaySubnetOfIP(ctx, lease.IP)
+	if err != nil {
+		return err
+	}
+	if subnet == nil {
+		c.logger.V(1).Info("no underlay subnet contains snooped dhcp ip", "ip", lease.IP, "mac", lease.MAC)
+		return nil
+	}
+
+	existing, err := c.getIPInstanceByAddress(lease.IP)
+	if err != nil {
+		return fmt.Errorf("failed to get ip instance of snooped dhcp ip %v: %v", lease.IP, err)
+	}
+	if existing != nil && existing.Labels[constants.LabelDHCPSnoopingNode] != c.config.NodeName {
+		if len(existing.Labels[constants.LabelDHCPSnoopingNode]) != 0 {
+			c.logger.V(1).Info("snooped dhcp ip has been recorded by another node", "ip", lease.IP,
+				"node", existing.Labels[constants.LabelDHCPSnoopingNode])
+			return nil
+		}
+		c.logger.Info("snooped dhcp ip has been allocated, skip recording it", "ip", lease.IP, "mac", lease.MAC,
+			"ipInstance", existing.Namespace+"/"+existing.Name)
+		return nil
+	}
+
+	// Node objects are not supposed to be in list/watch cache.
+	thisNode := &corev1.Node{}
+	if err = c.mgr.GetAPIReader().Get(ctx, types.NamespacedName{Name: c.config.NodeName}, thisNode); err != nil {
+		return fmt.Errorf("failed to get node %v: %v", c.config.NodeName, err)
+	}
+
+	ipInstance := &networkingv1.IPInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.ToDNSFormat(lease.IP),
+			Namespace: c.config.DHCPSnoopingNamespace,
+		},
+	}
+
+	operationResult, err := controllerutil.CreateOrPatch(ctx, c.mgr.GetClient(), ipInstance, func() error {
+		if len(ipInstance.Spec.Address.IP) != 0 && ipInstance.Labels[constants.LabelDHCPSnoopingNode] != thisNode.Name {
+			return fmt.Errorf("ip instance %s/%s is not created by dhcp snooping of this node, can not be updated",
+				ipInstance.Namespace, ipInstance.Name)
+		}
+		assembleDHCPSnoopedIPInstance(ipInstance, lease, subnet, thisNode, time.Now())
+		return nil
+	})
+	if err != nil {
+		// created by another node or allocated after the cache is read
+		if apierrors.IsAlreadyExists(err) {
+			c.logger.V(1).Info("snooped dhcp ip has been created by others", "ip", lease.IP)
+			return nil
+		}
+		return fmt.Errorf("failed to create or patch ip instance of dhcp lease: %v", err)
+	}
+
+	if operationResult != controllerutil.OperationResultNone {
+		c.logger.Info("dhcp lease recorded", "ip", lease.IP, "mac", lease.MAC, "subnet", subnet.Name,
+			"operation", operationResult)
+	}
+	return nil
+}
+
+// releaseExpiredDHCPLeases deletes the snooped IPInstances of node whose leases expire, and returns
+// their IPs. IPInstances without expire time are kept because their leases are infinite.
+func releaseExpiredDHCPLeases(ctx context.Context, c client.Client, namespace, nodeName string, now time.Time) ([]string, error) {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.List(ctx, ipInstanceList, client.InNamespace(namespace),
+		client.MatchingLabels{constants.LabelDHCPSnoopingNode: nodeName}); err != nil {
+		return nil, fmt.Errorf("failed to list ip instances of dhcp leases: %v", err)
+	}
+
+	var errList []error
+	var released []string
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if !dhcpLeaseExpired(ipInstance, now) {
+			continue
+		}
+		if err := c.Delete(ctx, ipInstance); err != nil && !apierrors.IsNotFound(err) {
+			errList = append(errList, fmt.Errorf("failed to release ip instance %v of expired dhcp lease: %v",
+				ipInstance.Name, err))
+			continue
+		}
+		released = append(released, ipInstance.Spec.Address.IP)
+	}
+	return released, utilerrors.NewAggregate(errList)
+}
+
+// dhcpLeaseExpired returns whether the lease of a snooped IPInstance expires, an unparsable
+// expire time is regarded as expired so that the IPInstance is recorded again on renewal.
+func dhcpLeaseExpired(ipInstance *networkingv1.IPInstance, now time.Time) bool {
+	expireTime, exist := ipInstance.Annotations[constants.AnnotationDHCPLeaseExpireTime]
+	if !exist {
+		return false
+	}
+	expire, err := time.Parse(time.RFC3339, expireTime)
+	return err != nil || !now.Before(expire)
+}
+
+// isLocalHardwareAddr returns whether mac is the address of any link on node
+func isLocalHardwareAddr(mac net.HardwareAddr) (bool, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return false, fmt.Errorf("failed to list links: %v", err)
+	}
+	for _, link := range links {
+		if bytes.Equal(link.Attrs().HardwareAddr, mac) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *CtrlHub) findUnderlaySubnetOfIP(ctx context.Context, ip net.IP) (*networkingv1.Subnet, error) {
+	subnetList := &networkingv1.SubnetList{}
+	if err := c.mgr.GetClient().List(ctx, subnetList); err != nil {
+		return nil, fmt.Errorf("failed to list subnet: %v", err)
+	}
+
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		_, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR)
+		if err != nil || !cidr.Contains(ip) {
+			continue
+		}
+
+		network := &networkingv1.Network{}
+		if err = c.mgr.GetClient().Get(ctx, types.NamespacedName{Name: subnet.Spec.Network}, network); err != nil {
+			return nil, fmt.Errorf("failed to get network %v: %v", subnet.Spec.Network, err)
+		}
+		if networkingv1.GetNetworkType(network) == networkingv1.NetworkTypeUnderlay {
+			return subnet, nil
+		}
+	}
+	return nil, nil
+}
+
+// assembleDHCPSnoopedIPInstance fills the IPInstance of a snooped lease, the node which snoops
+// the lease is the owner, so that the IPInstance is garbage collected after node is deleted.
+func assembleDHCPSnoopedIPInstance(ipInstance *networkingv1.IPInstance, lease *dhcp.Lease, subnet *networkingv1.Subnet,
+	node *corev1.Node, now time.Time) {
+	if ipInstance.Labels == nil {
+		ipInstance.Labels = map[string]string{}
+	}
+	ipInstance.Labels[constants.LabelVersion] = networkingv1.IPInstanceLatestVersion
+	ipInstance.Labels[constants.LabelNetwork] = subnet.Spec.Network
+	ipInstance.Labels[constants.LabelSubnet] = subnet.Name
+	ipInstance.Labels[constants.LabelIPAddress] = utils.ToDNSFormat(lease.IP)
+	ipInstance.Labels[constants.LabelDHCPSnoopingNode] = node.Name
+
+	if lease.LeaseTime > 0 {
+		if ipInstance.Annotations == nil {
+			ipInstance.Annotations = map[string]string{}
+		}
+		ipInstance.Annotations[constants.AnnotationDHCPLeaseExpireTime] = now.Add(lease.LeaseTime).UTC().Format(time.RFC3339)
+	} else {
+		delete(ipInstance.Annotations, constants.AnnotationDHCPLeaseExpireTime)
+	}
+
+	ipInstance.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "Node",
+		Name:       node.Name,
+		UID:        node.UID,
+	}}
+
+	var prefixLength int
+	if _, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR); err == nil {
+		prefixLength, _ = cidr.Mask.Size()
+	}
+	if lease.SubnetMask != nil {
+		prefixLength, _ = lease.SubnetMask.Size()
+	}
+
+	ipInstance.Spec.SchemaVersion = networkingv1.IPInstanceLatestSchemaVersion
+	ipInstance.Spec.Network = subnet.Spec.Network
+	ipInstance.Spec.Subnet = subnet.Name
+	ipInstance.Spec.Address = networkingv1.Address{
+		Version: networkingv1.IPv4,
+		IP:      fmt.Sprintf("%s/%d", lease.IP, prefixLength),
+		MAC:     lease.MAC.String(),
+		NetID:   subnet.Spec.NetID,
+	}
+	if lease.Router != nil {
+		ipInstance.Spec.Address.Gateway = lease.Router.String()
+	} else {
+		ipInstance.Spec.Address.Gateway = subnet.Spec.Range.Gateway
+	}
+}
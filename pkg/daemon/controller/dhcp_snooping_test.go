@@ -0,0 +1,138 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/dhcp"
+)
+
+func TestAssembleDHCPSnoopedIPInstance(t *testing.T) {
+	subnet := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1"},
+		Spec: networkingv1.SubnetSpec{
+			Network: "network1",
+			Range:   networkingv1.AddressRange{Version: networkingv1.IPv4, CIDR: "192.168.0.0/24", Gateway: "192.168.0.1"},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "c1a5"}}
+	mac, _ := net.ParseMAC("00:00:5e:00:53:01")
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	ipInstance := &networkingv1.IPInstance{}
+	assembleDHCPSnoopedIPInstance(ipInstance, &dhcp.Lease{
+		IP:         net.ParseIP("192.168.0.10"),
+		MAC:        mac,
+		SubnetMask: net.CIDRMask(25, 32),
+		Router:     net.ParseIP("192.168.0.126"),
+		LeaseTime:  time.Hour,
+	}, subnet, node, now)
+
+	if ipInstance.Spec.Address.IP != "192.168.0.10/25" || ipInstance.Spec.Address.Gateway != "192.168.0.126" ||
+		ipInstance.Spec.Address.MAC != mac.String() {
+		t.Fatalf("unexpected address %+v", ipInstance.Spec.Address)
+	}
+	if ipInstance.Labels[constants.LabelDHCPSnoopingNode] != "node1" || ipInstance.Labels[constants.LabelSubnet] != "subnet1" {
+		t.Fatalf("unexpected labels %v", ipInstance.Labels)
+	}
+	if ipInstance.Annotations[constants.AnnotationDHCPLeaseExpireTime] != "2022-10-01T01:00:00Z" {
+		t.Fatalf("unexpected annotations %v", ipInstance.Annotations)
+	}
+	// never bound to any node, so that the ip is reserved in ipam
+	if !networkingv1.IsReserved(ipInstance) {
+		t.Fatalf("expected snooped ip instance reserved")
+	}
+
+	// renewed with an infinite lease
+	assembleDHCPSnoopedIPInstance(ipInstance, &dhcp.Lease{IP: net.ParseIP("192.168.0.10"), MAC: mac}, subnet, node, now)
+	if _, exist := ipInstance.Annotations[constants.AnnotationDHCPLeaseExpireTime]; exist {
+		t.Fatalf("expected expire time removed but got %v", ipInstance.Annotations)
+	}
+	if ipInstance.Spec.Address.IP != "192.168.0.10/24" || ipInstance.Spec.Address.Gateway != "192.168.0.1" {
+		t.Fatalf("unexpected address %+v", ipInstance.Spec.Address)
+	}
+}
+
+func TestReleaseExpiredDHCPLeases(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = networkingv1.AddToScheme(scheme)
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	snooped := func(name, namespace, node, expireTime string) *networkingv1.IPInstance {
+		ipInstance := &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{constants.LabelDHCPSnoopingNode: node},
+			},
+			Spec: networkingv1.IPInstanceSpec{Address: networkingv1.Address{IP: name}},
+		}
+		if len(expireTime) != 0 {
+			ipInstance.Annotations = map[string]string{constants.AnnotationDHCPLeaseExpireTime: expireTime}
+		}
+		return ipInstance
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		snooped("expired", "kube-system", "node1", "2022-09-30T23:00:00Z"),
+		snooped("valid", "kube-system", "node1", "2022-10-01T01:00:00Z"),
+		snooped("infinite", "kube-system", "node1", ""),
+		snooped("invalid", "kube-system", "node1", "tomorrow"),
+		snooped("other-node", "kube-system", "node2", "2022-09-30T23:00:00Z"),
+		snooped("other-namespace", "default", "node1", "2022-09-30T23:00:00Z"),
+	).Build()
+
+	released, err := releaseExpiredDHCPLeases(context.Background(), c, "kube-system", "node1", now)
+	if err != nil {
+		t.Fatalf("fail to release expired dhcp leases: %v", err)
+	}
+	if len(released) != 2 {
+		t.Fatalf("expected 2 leases released but got %v", released)
+	}
+
+	for name, exist := range map[string]bool{
+		"expired":  false,
+		"valid":    true,
+		"infinite": true,
+		"invalid":  false,
+	} {
+		err := c.Get(context.Background(), client.ObjectKey{Namespace: "kube-system", Name: name}, &networkingv1.IPInstance{})
+		if (err == nil) != exist {
+			t.Errorf("expected ip instance %s exist %v but got error %v", name, exist, err)
+		}
+	}
+	if err = c.Get(context.Background(), client.ObjectKey{Namespace: "kube-system", Name: "other-node"},
+		&networkingv1.IPInstance{}); err != nil {
+		t.Errorf("expected ip instance of other node kept but got %v", err)
+	}
+	if err = c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "other-namespace"},
+		&networkingv1.IPInstance{}); err != nil {
+		t.Errorf("expected ip instance in other namespace kept but got %v", err)
+	}
+}
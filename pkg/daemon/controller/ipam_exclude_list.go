@@ -0,0 +1,266 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const ipamExcludeListRetryInterval = 10 * time.Second
+
+// ipamExcludeListLoop reserves the IPs listed in the exclude list file as IPInstances of underlay
+// subnets, so that IPs used by other systems on node, e.g., host-level services and DHCP reservations,
+// are never allocated to pods by IPAM of manager. The file is loaded on start and reloaded on SIGHUP.
+//
+// Like snooped DHCP leases, the IPInstances are never bound to any pod or node, IPs removed from the
+// file are released by deleting their IPInstances on reload.
+func (c *CtrlHub) ipamExcludeListLoop(ctx context.Context) {
+	if len(c.config.IPAMExcludeListFile) == 0 {
+		return
+	}
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(reload)
+
+		if !c.CacheSynced(ctx) {
+			return
+		}
+
+		for {
+			var retry <-chan time.Time
+			if err := c.syncIPAMExcludeList(ctx); err != nil {
+				c.logger.Error(err, "failed to sync ipam exclude list", "file", c.config.IPAMExcludeListFile)
+				retry = time.After(ipamExcludeListRetryInterval)
+			}
+
+			select {
+			case <-reload:
+				c.logger.Info("reload ipam exclude list", "file", c.config.IPAMExcludeListFile)
+			case <-retry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *CtrlHub) syncIPAMExcludeList(ctx context.Context) error {
+	file, err := os.Open(c.config.IPAMExcludeListFile)
+	if err != nil {
+		return fmt.Errorf("failed to open exclude list file: %v", err)
+	}
+	excludedIPs, err := parseIPAMExcludeList(file)
+	_ = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse exclude list file: %v", err)
+	}
+
+	// Node objects are not supposed to be in list/watch cache.
+	thisNode := &corev1.Node{}
+	if err = c.mgr.GetAPIReader().Get(ctx, types.NamespacedName{Name: c.config.NodeName}, thisNode); err != nil {
+		return fmt.Errorf("failed to get node %v: %v", c.config.NodeName, err)
+	}
+
+	var errList []error
+	var reserved int
+	listed := map[string]bool{}
+	for _, ip := range excludedIPs {
+		if listed[utils.ToDNSFormat(ip)] {
+			continue
+		}
+
+		subnet, err := c.findUnderlaySubnetOfIP(ctx, ip)
+		if err != nil {
+			return err
+		}
+		if subnet == nil {
+			c.logger.V(1).Info("no underlay subnet contains excluded ip", "ip", ip)
+			continue
+		}
+
+		listed[utils.ToDNSFormat(ip)] = true
+		if err = c.reserveExcludedIP(ctx, ip, subnet, thisNode); err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		reserved++
+	}
+	metrics.IPAMExcludeListReservedIPsGauge.Set(float64(reserved))
+
+	// release the IPs which are removed from file
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err = c.mgr.GetClient().List(ctx, ipInstanceList, client.InNamespace(c.config.IPAMExcludeListNamespace),
+		client.MatchingLabels{constants.LabelIPAMExcludeNode: c.config.NodeName}); err != nil {
+		return fmt.Errorf("failed to list ip instances of exclude list: %v", err)
+	}
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if listed[ipInstance.Name] {
+			continue
+		}
+		if err = c.mgr.GetClient().Delete(ctx, ipInstance); err != nil && !apierrors.IsNotFound(err) {
+			errList = append(errList, fmt.Errorf("failed to release excluded ip instance %v: %v", ipInstance.Name, err))
+			continue
+		}
+		c.logger.Info("excluded ip released", "ip", ipInstance.Spec.Address.IP)
+	}
+
+	return utilerrors.NewAggregate(errList)
+}
+
+// reserveExcludedIP creates the IPInstance of an excluded IP, IPs which have been allocated by
+// hybridnet are never taken over. IPInstances are named by their IPs in all namespaces, so an
+// existing IPInstance of the IP is looked up by address, which works for unlabeled IPInstances
+// created before upgrading too.
+//
+// IPs listed by other nodes are regarded as reserved, only the first node owns the IPInstance.
+// IPAM of manager is refreshed once the IPInstance is created or deleted.
+func (c *CtrlHub) reserveExcludedIP(ctx context.Context, ip net.IP, subnet *networkingv1.Subnet, node *corev1.Node) error {
+	existing, err := c.getIPInstanceByAddress(ip)
+	if err != nil {
+		return fmt.Errorf("failed to get ip instance of excluded ip %v: %v", ip, err)
+	}
+	if existing != nil && existing.Labels[constants.LabelIPAMExcludeNode] != node.Name {
+		if len(existing.Labels[constants.LabelIPAMExcludeNode]) != 0 {
+			c.logger.V(1).Info("excluded ip has been reserved by another node", "ip", ip,
+				"node", existing.Labels[constants.LabelIPAMExcludeNode])
+			return nil
+		}
+		// retrying never helps until the ip instance is released
+		c.logger.Info("excluded ip has been allocated, skip reserving it", "ip", ip,
+			"ipInstance", existing.Namespace+"/"+existing.Name)
+		return nil
+	}
+
+	ipInstance := &networkingv1.IPInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.ToDNSFormat(ip),
+			Namespace: c.config.IPAMExcludeListNamespace,
+		},
+	}
+
+	operationResult, err := controllerutil.CreateOrPatch(ctx, c.mgr.GetClient(), ipInstance, func() error {
+		if len(ipInstance.Spec.Address.IP) != 0 && ipInstance.Labels[constants.LabelIPAMExcludeNode] != node.Name {
+			return fmt.Errorf("ip instance %s/%s is not created by exclude list of this node, can not be updated",
+				ipInstance.Namespace, ipInstance.Name)
+		}
+		assembleExcludedIPInstance(ipInstance, ip, subnet, node)
+		return nil
+	})
+	if err != nil {
+		// created by another node or allocated after the cache is read
+		if apierrors.IsAlreadyExists(err) {
+			c.logger.V(1).Info("excluded ip has been created by others", "ip", ip)
+			return nil
+		}
+		return fmt.Errorf("failed to create or patch ip instance of excluded ip: %v", err)
+	}
+
+	if operationResult != controllerutil.OperationResultNone {
+		c.logger.Info("excluded ip reserved", "ip", ip, "subnet", subnet.Name, "operation", operationResult)
+	}
+	return nil
+}
+
+// assembleExcludedIPInstance fills the IPInstance of an excluded IP, the node whose file lists the
+// IP is the owner, so that the IPInstance is garbage collected after node is deleted.
+func assembleExcludedIPInstance(ipInstance *networkingv1.IPInstance, ip net.IP, subnet *networkingv1.Subnet,
+	node *corev1.Node) {
+	if ipInstance.Labels == nil {
+		ipInstance.Labels = map[string]string{}
+	}
+	ipInstance.Labels[constants.LabelVersion] = networkingv1.IPInstanceLatestVersion
+	ipInstance.Labels[constants.LabelNetwork] = subnet.Spec.Network
+	ipInstance.Labels[constants.LabelSubnet] = subnet.Name
+	ipInstance.Labels[constants.LabelIPAddress] = utils.ToDNSFormat(ip)
+	ipInstance.Labels[constants.LabelIPAMExcludeNode] = node.Name
+
+	ipInstance.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: corev1.SchemeGroupVersion.String(),
+		Kind:       "Node",
+		Name:       node.Name,
+		UID:        node.UID,
+	}}
+
+	version := networkingv1.IPv4
+	if ip.To4() == nil {
+		version = networkingv1.IPv6
+	}
+
+	var prefixLength int
+	if _, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR); err == nil {
+		prefixLength, _ = cidr.Mask.Size()
+	}
+
+	ipInstance.Spec.SchemaVersion = networkingv1.IPInstanceLatestSchemaVersion
+	ipInstance.Spec.Network = subnet.Spec.Network
+	ipInstance.Spec.Subnet = subnet.Name
+	ipInstance.Spec.Address = networkingv1.Address{
+		Version: version,
+		IP:      fmt.Sprintf("%s/%d", ip, prefixLength),
+		Gateway: subnet.Spec.Range.Gateway,
+		NetID:   subnet.Spec.NetID,
+	}
+}
+
+// parseIPAMExcludeList parses one IP per line, empty lines and comments starting with # are ignored
+func parseIPAMExcludeList(r io.Reader) ([]net.IP, error) {
+	var ips []net.IP
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		ip := net.ParseIP(line)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip %q at line %d", line, lineNum)
+		}
+		ips = append(ips, ip)
+	}
+	return ips, scanner.Err()
+}
@@ -0,0 +1,80 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestParseIPAMExcludeList(t *testing.T) {
+	ips, err := parseIPAMExcludeList(strings.NewReader(`
+# reserved by dhcp server
+192.168.0.2
+  192.168.0.3  # host service
+
+fe80::1
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []net.IP{net.ParseIP("192.168.0.2"), net.ParseIP("192.168.0.3"), net.ParseIP("fe80::1")}
+	if !reflect.DeepEqual(ips, expected) {
+		t.Fatalf("expected ips %v but got %v", expected, ips)
+	}
+
+	if _, err = parseIPAMExcludeList(strings.NewReader("192.168.0.2\n192.168.0.0/24\n")); err == nil ||
+		!strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected invalid ip at line 2 but got %v", err)
+	}
+}
+
+func TestAssembleExcludedIPInstance(t *testing.T) {
+	subnet := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1"},
+		Spec: networkingv1.SubnetSpec{
+			Network: "network1",
+			Range:   networkingv1.AddressRange{Version: networkingv1.IPv4, CIDR: "192.168.0.0/24", Gateway: "192.168.0.1"},
+		},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "c1a5"}}
+
+	ipInstance := &networkingv1.IPInstance{}
+	assembleExcludedIPInstance(ipInstance, net.ParseIP("192.168.0.2"), subnet, node)
+
+	if ipInstance.Spec.Address.IP != "192.168.0.2/24" || ipInstance.Spec.Address.Gateway != "192.168.0.1" {
+		t.Fatalf("unexpected address %+v", ipInstance.Spec.Address)
+	}
+	if ipInstance.Labels[constants.LabelIPAMExcludeNode] != "node1" || ipInstance.Labels[constants.LabelSubnet] != "subnet1" {
+		t.Fatalf("unexpected labels %v", ipInstance.Labels)
+	}
+	// never bound to any node, so that the ip is reserved in ipam
+	if !networkingv1.IsReserved(ipInstance) {
+		t.Fatalf("expected excluded ip instance reserved")
+	}
+	if len(ipInstance.OwnerReferences) != 1 || ipInstance.OwnerReferences[0].UID != node.UID {
+		t.Fatalf("expected ip instance owned by node but got %+v", ipInstance.OwnerReferences)
+	}
+}
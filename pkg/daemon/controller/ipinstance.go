@@ -94,7 +94,13 @@ func (r *ipInstanceReconciler) Reconcile(ctx context.Context, request reconcile.
 		var forwardNodeIfName string
 		switch networkingv1.GetNetworkMode(network) {
 		case networkingv1.NetworkModeVlan:
-			forwardNodeIfName, err = daemonutils.GenerateVlanNetIfName(r.ctrlHubRef.config.NodeVlanIfName, netID)
+			var vlanParentIfName string
+			vlanParentIfName, err = daemonutils.GetVlanParentIfName(r.ctrlHubRef.config.NodeVlanIfName, network)
+			if err != nil {
+				return reconcile.Result{Requeue: true}, fmt.Errorf("failed to get vlan parent interface name: %v", err)
+			}
+
+			forwardNodeIfName, err = daemonutils.GenerateVlanNetIfName(vlanParentIfName, netID)
 			if err != nil {
 				return reconcile.Result{Requeue: true}, fmt.Errorf("failed to generate vlan forward node interface name: %v", err)
 			}
@@ -110,10 +116,11 @@ func (r *ipInstanceReconciler) Reconcile(ctx context.Context, request reconcile.
 			if err != nil {
 				return reconcile.Result{Requeue: true}, fmt.Errorf("failed to generate vxlan forward node interface name: %v", err)
 			}
-		case networkingv1.NetworkModeBGP:
-			r.ctrlHubRef.bgpManager.RecordIP(podIP, false)
-		case networkingv1.NetworkModeGlobalBGP:
-			r.ctrlHubRef.bgpManager.RecordIP(podIP, true)
+		}
+
+		// ips are announced only if node belongs to a bgp network, whose peers are where ips are announced to
+		if announced, exported := bgpAnnouncementOfNetwork(network); announced {
+			r.ctrlHubRef.bgpManager.RecordIP(podIP, exported)
 		}
 
 		// create proxy neigh
@@ -159,6 +166,22 @@ func (r *ipInstanceReconciler) Reconcile(ctx context.Context, request reconcile.
 	return reconcile.Result{}, nil
 }
 
+// bgpAnnouncementOfNetwork returns if ips of pods in network are announced to bgp peers of node, and if
+// they are exported out of the AS of peers, ips of overlay pods are announced only if enabled on network
+func bgpAnnouncementOfNetwork(network *networkingv1.Network) (announced, exported bool) {
+	switch networkingv1.GetNetworkMode(network) {
+	case networkingv1.NetworkModeVxlan:
+		announced = networkingv1.IsBGPPodIPAnnouncementNetwork(network)
+		return announced, announced
+	case networkingv1.NetworkModeBGP:
+		return true, false
+	case networkingv1.NetworkModeGlobalBGP:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
 func (r *ipInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	ipInstanceController, err := controller.New("ip-instance", mgr, controller.Options{
 		Reconciler:   r,
@@ -199,6 +222,30 @@ func (r *ipInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return fmt.Errorf("failed to watch networkingv1.IPInstance for ip instance controller: %v", err)
 	}
 
+	if err := ipInstanceController.Watch(&source.Kind{Type: &networkingv1.Network{}},
+		&fixedKeyHandler{key: "ForNetworkChange"},
+		&predicate.Funcs{
+			CreateFunc: func(createEvent event.CreateEvent) bool {
+				return false
+			},
+			DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
+				return false
+			},
+			UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+				oldNetwork := updateEvent.ObjectOld.(*networkingv1.Network)
+				newNetwork := updateEvent.ObjectNew.(*networkingv1.Network)
+
+				// pod ips are announced or withdrawn as soon as the announcement is switched
+				return networkingv1.IsBGPPodIPAnnouncementNetwork(oldNetwork) !=
+					networkingv1.IsBGPPodIPAnnouncementNetwork(newNetwork)
+			},
+			GenericFunc: func(genericEvent event.GenericEvent) bool {
+				return false
+			},
+		}); err != nil {
+		return fmt.Errorf("failed to watch networkingv1.Network for ip instance controller: %v", err)
+	}
+
 	if err := ipInstanceController.Watch(r.ctrlHubRef.ipInstanceTriggerSourceForHostLink, &handler.Funcs{}); err != nil {
 		return fmt.Errorf("failed to watch ipInstanceTriggerSourceForHostLink for ip instance controller: %v", err)
 	}
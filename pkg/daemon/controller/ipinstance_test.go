@@ -0,0 +1,84 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestBGPAnnouncementOfNetwork(t *testing.T) {
+	tests := []struct {
+		name      string
+		network   *networkingv1.Network
+		announced bool
+		exported  bool
+	}{
+		{
+			name: "vxlan network",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Type: networkingv1.NetworkTypeOverlay,
+				Mode: networkingv1.NetworkModeVxlan,
+			}},
+		},
+		{
+			name: "vxlan network with announcement",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Type:                 networkingv1.NetworkTypeOverlay,
+				Mode:                 networkingv1.NetworkModeVxlan,
+				BGPPodIPAnnouncement: true,
+			}},
+			announced: true,
+			exported:  true,
+		},
+		{
+			name: "vlan network",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Type: networkingv1.NetworkTypeUnderlay,
+				Mode: networkingv1.NetworkModeVlan,
+			}},
+		},
+		{
+			name: "bgp network",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Type: networkingv1.NetworkTypeUnderlay,
+				Mode: networkingv1.NetworkModeBGP,
+			}},
+			announced: true,
+		},
+		{
+			name: "global bgp network",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Type: networkingv1.NetworkTypeGlobalBGP,
+				Mode: networkingv1.NetworkModeGlobalBGP,
+			}},
+			announced: true,
+			exported:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			announced, exported := bgpAnnouncementOfNetwork(test.network)
+			if announced != test.announced || exported != test.exported {
+				t.Errorf("expect announced %v and exported %v but got %v and %v",
+					test.announced, test.exported, announced, exported)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/lldp"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+// lldpDiscoveryLoop will periodically listen LLDP frames on the vlan interface of node, and
+// attach node to the underlay network which matches the LLDP neighbor information, by
+// patching the node selector of network to node labels.
+//
+// Only nodes with the lldp-discovery annotation will be discovered.
+func (c *CtrlHub) lldpDiscoveryLoop(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.config.LLDPDiscoveryInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.discoverUnderlayNetworkThroughLLDP(ctx); err != nil {
+				c.logger.Error(err, "failed to discover underlay network through lldp")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *CtrlHub) discoverUnderlayNetworkThroughLLDP(ctx context.Context) error {
+	// Node objects are not supposed to be in list/watch cache.
+	thisNode := &corev1.Node{}
+	if err := c.mgr.GetAPIReader().Get(ctx, types.NamespacedName{Name: c.config.NodeName}, thisNode); err != nil {
+		return fmt.Errorf("failed to get node %v: %v", c.config.NodeName, err)
+	}
+
+	if !utils.ParseBoolOrDefault(thisNode.Annotations[constants.AnnotationLLDPDiscovery], false) {
+		return nil
+	}
+
+	ifi, err := net.InterfaceByName(c.config.NodeVlanIfName)
+	if err != nil {
+		return fmt.Errorf("failed to get vlan interface %v: %v", c.config.NodeVlanIfName, err)
+	}
+
+	neighbor, err := lldp.Listen(ifi, lldp.DefaultListenTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to get lldp neighbor: %v", err)
+	}
+
+	c.logger.V(1).Info("lldp neighbor found", "interface", ifi.Name, "neighbor", *neighbor)
+
+	networkList := &networkingv1.NetworkList{}
+	if err = c.mgr.GetAPIReader().List(ctx, networkList); err != nil {
+		return fmt.Errorf("failed to list network: %v", err)
+	}
+
+	var matchedNetwork *networkingv1.Network
+	for i := range networkList.Items {
+		network := &networkList.Items[i]
+		if !lldpNeighborMatchesNetwork(neighbor, network) {
+			continue
+		}
+
+		if matchedNetwork != nil {
+			return fmt.Errorf("lldp neighbor %+v matches more than one network, %v and %v",
+				*neighbor, matchedNetwork.Name, network.Name)
+		}
+		matchedNetwork = network
+	}
+
+	if matchedNetwork == nil {
+		c.logger.Info("no underlay network matches lldp neighbor", "neighbor", *neighbor)
+		return nil
+	}
+
+	if labels.SelectorFromSet(matchedNetwork.Spec.NodeSelector).Matches(labels.Set(thisNode.Labels)) {
+		return nil
+	}
+
+	nodePatch := client.MergeFrom(thisNode.DeepCopy())
+	if thisNode.Labels == nil {
+		thisNode.Labels = map[string]string{}
+	}
+	for key, value := range matchedNetwork.Spec.NodeSelector {
+		thisNode.Labels[key] = value
+	}
+
+	if err = c.mgr.GetClient().Patch(ctx, thisNode, nodePatch); err != nil {
+		return fmt.Errorf("failed to patch node selector labels of network %v to node: %v", matchedNetwork.Name, err)
+	}
+
+	c.logger.Info("node is attached to underlay network through lldp", "network", matchedNetwork.Name,
+		"neighbor", *neighbor)
+	return nil
+}
+
+// lldpNeighborMatchesNetwork checks if LLDP neighbor matches all the LLDP annotations of
+// an underlay network, networks without any LLDP annotations or node selector never match.
+func lldpNeighborMatchesNetwork(neighbor *lldp.Neighbor, network *networkingv1.Network) bool {
+	if networkingv1.GetNetworkType(network) != networkingv1.NetworkTypeUnderlay || len(network.Spec.NodeSelector) == 0 {
+		return false
+	}
+
+	vlanID, vlanIDExist := network.Annotations[constants.AnnotationLLDPVlanID]
+	portDescription, portDescriptionExist := network.Annotations[constants.AnnotationLLDPPortDescription]
+	if !vlanIDExist && !portDescriptionExist {
+		return false
+	}
+
+	if vlanIDExist && vlanID != strconv.Itoa(int(neighbor.PortVLANID)) {
+		return false
+	}
+
+	if portDescriptionExist && portDescription != neighbor.PortDescription {
+		return false
+	}
+
+	return true
+}
@@ -48,6 +48,7 @@ import (
 	"github.com/alibaba/hybridnet/pkg/daemon/vxlan"
 	"github.com/alibaba/hybridnet/pkg/feature"
 	ipamutils "github.com/alibaba/hybridnet/pkg/ipam/utils"
+	"github.com/alibaba/hybridnet/pkg/metrics"
 
 	"github.com/vishvananda/netlink"
 
@@ -68,6 +69,8 @@ func (r *nodeInfoReconciler) Reconcile(ctx context.Context, request reconcile.Re
 
 	var overlayNetID *int32
 	var overlayNodeNum int
+	var overlayMulticastGroup net.IP
+	var overlayChecksumOffloadMode networkingv1.ChecksumOffloadMode
 
 	networkList := &networkingv1.NetworkList{}
 	if err := r.List(ctx, networkList); err != nil {
@@ -78,6 +81,8 @@ func (r *nodeInfoReconciler) Reconcile(ctx context.Context, request reconcile.Re
 		if networkingv1.GetNetworkType(&network) == networkingv1.NetworkTypeOverlay {
 			overlayNetID = network.Spec.NetID
 			overlayNodeNum = len(network.Status.NodeList)
+			overlayMulticastGroup = net.ParseIP(network.Spec.MulticastGroup)
+			overlayChecksumOffloadMode = network.Spec.ChecksumOffloadMode
 			break
 		}
 	}
@@ -119,14 +124,61 @@ func (r *nodeInfoReconciler) Reconcile(ctx context.Context, request reconcile.Re
 		return reconcile.Result{Requeue: true}, fmt.Errorf("failed list node: %v", err)
 	}
 
+	wirelessParent, err := vxlan.IsWirelessInterface(r.ctrlHubRef.config.NodeVxlanIfName)
+	if err != nil {
+		return reconcile.Result{Requeue: true}, fmt.Errorf("failed to check if vxlan parent interface %v is wireless: %v",
+			r.ctrlHubRef.config.NodeVxlanIfName, err)
+	}
+
+	baseReachableTime := r.ctrlHubRef.config.VxlanBaseReachableTime
+	if wirelessParent {
+		baseReachableTime *= vxlan.WirelessReachableTimeFactor
+		logger.Info("Warning: vxlan parent interface is wireless, arp timeout is extended and gro is disabled on vxlan device",
+			"interface", r.ctrlHubRef.config.NodeVxlanIfName, "baseReachableTime", baseReachableTime)
+	}
+
 	// if the vtep ip change, vxlan interface will be rebuilt
 	vxlanDev, err := vxlan.NewVxlanDevice(vxlanLinkName, int(*overlayNetID),
 		r.ctrlHubRef.config.NodeVxlanIfName, vtepIP, r.ctrlHubRef.config.VxlanUDPPort,
-		r.ctrlHubRef.config.VxlanBaseReachableTime, true)
+		baseReachableTime, true, r.ctrlHubRef.config.EnableARPSuppression)
 	if err != nil {
 		return reconcile.Result{Requeue: true}, fmt.Errorf("failed to create vxlan device %v: %v", vxlanLinkName, err)
 	}
 
+	if wirelessParent {
+		if err := vxlan.DisableGRO(vxlanLinkName); err != nil {
+			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to disable gro of vxlan device %v: %v", vxlanLinkName, err)
+		}
+	}
+
+	if len(r.ctrlHubRef.config.HostVRFName) != 0 {
+		_, attached, err := utils.EnsureLinkInVRF(vxlanDev.Link(), r.ctrlHubRef.config.HostVRFName)
+		if err != nil {
+			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to attach vxlan device %v to vrf: %v", vxlanLinkName, err)
+		}
+
+		// routes through vxlan device are flushed while attaching, subnet controller need to install them again
+		if attached {
+			r.ctrlHubRef.subnetTriggerSourceForNodeInfoChange.Trigger()
+		}
+	}
+
+	vxlanDev.RecordMulticastGroup(overlayMulticastGroup)
+
+	// checksum offload only affects performance, vxlan devices still work if it fails,
+	// offload features of nic are left unchanged if no mode is specified
+	var checksumOffloadFallback float64
+	if len(overlayChecksumOffloadMode) != 0 {
+		if fallback, err := vxlan.EnsureChecksumOffload(r.ctrlHubRef.config.NodeVxlanIfName, overlayChecksumOffloadMode); err != nil {
+			logger.Error(err, "failed to ensure checksum offload", "interface", r.ctrlHubRef.config.NodeVxlanIfName)
+		} else if fallback {
+			logger.Info("udp tunnel checksum offload is not available, fall back to software checksumming",
+				"interface", r.ctrlHubRef.config.NodeVxlanIfName, "mode", overlayChecksumOffloadMode)
+			checksumOffloadFallback = 1
+		}
+	}
+	metrics.VxlanChecksumOffloadFallbackGauge.WithLabelValues(r.ctrlHubRef.config.NodeVxlanIfName).Set(checksumOffloadFallback)
+
 	if err := ensureVxlanInterfaceAddresses(vxlanDev, nodeLocalVxlanAddrs); err != nil {
 		return reconcile.Result{Requeue: true}, fmt.Errorf("failed to ensure addresses for vxlan device %v: %v",
 			vxlanLinkName, err)
@@ -156,12 +208,14 @@ func (r *nodeInfoReconciler) Reconcile(ctx context.Context, request reconcile.Re
 	}
 
 	var remoteVtepList []*multiclusterv1.RemoteVtep
+	var cachedRemoteVteps []multiclusterv1.RemoteVtep
 
 	if feature.MultiClusterEnabled() {
 		remoteVtepList := &multiclusterv1.RemoteVtepList{}
 		if err = r.List(ctx, remoteVtepList); err != nil {
 			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to list remote vtep: %v", err)
 		}
+		cachedRemoteVteps = remoteVtepList.Items
 
 		for _, remoteVtep := range remoteVtepList.Items {
 			vtepMac, err := net.ParseMAC(remoteVtep.Spec.VTEPInfo.MAC)
@@ -204,6 +258,19 @@ func (r *nodeInfoReconciler) Reconcile(ctx context.Context, request reconcile.Re
 	// So subnet controller need to be triggered again.
 	r.ctrlHubRef.subnetTriggerSourceForNodeInfoChange.Trigger()
 
+	// fdb entries of remote vteps are installed from a listed snapshot of cache, which may have been
+	// updated during installation
+	if len(cachedRemoteVteps) > 0 {
+		staleNames, err := findStaleRemoteVteps(ctx, r, cachedRemoteVteps)
+		if err != nil {
+			logger.Error(err, "failed to check staleness of cached remote vteps")
+		} else if len(staleNames) > 0 {
+			logger.Info("Cached remote vteps are stale, reconcile again after cache catches up",
+				"remoteVteps", staleNames)
+			return reconcile.Result{RequeueAfter: staleRemoteVtepRequeueDelay}, nil
+		}
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -396,7 +463,9 @@ func (r *nodeInfoReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			UpdateFunc: func(updateEvent event.UpdateEvent) bool {
 				oldNetwork := updateEvent.ObjectOld.(*networkingv1.Network)
 				newNetwork := updateEvent.ObjectNew.(*networkingv1.Network)
-				return !utils2.DeepEqualStringSlice(oldNetwork.Status.NodeList, newNetwork.Status.NodeList)
+				return !utils2.DeepEqualStringSlice(oldNetwork.Status.NodeList, newNetwork.Status.NodeList) ||
+					oldNetwork.Spec.MulticastGroup != newNetwork.Spec.MulticastGroup ||
+					oldNetwork.Spec.ChecksumOffloadMode != newNetwork.Spec.ChecksumOffloadMode
 			},
 			CreateFunc: func(createEvent event.CreateEvent) bool {
 				network := createEvent.Object.(*networkingv1.Network)
@@ -418,6 +487,10 @@ func (r *nodeInfoReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return fmt.Errorf("failed to watch nodeInfoTriggerSourceForHostAddr for node controller: %v", err)
 	}
 
+	if err := nodeController.Watch(r.ctrlHubRef.nodeInfoTriggerSourceForVtepLink, &handler.Funcs{}); err != nil {
+		return fmt.Errorf("failed to watch nodeInfoTriggerSourceForVtepLink for node controller: %v", err)
+	}
+
 	if feature.MultiClusterEnabled() {
 		if err := nodeController.Watch(&source.Kind{Type: &multiclusterv1.RemoteVtep{}},
 			&fixedKeyHandler{key: "ForRemoteVtepChange"},
@@ -0,0 +1,104 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/containernetwork"
+	"github.com/alibaba/hybridnet/pkg/daemon/oam"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+const oamRelaySyncInterval = 30 * time.Second
+
+// oamRelayLoop periodically intercepts OAM frames on the host nics of local pods of overlay
+// networks with the oam-enabled annotation, and relays them to the OAM agent.
+//
+// Pod traffic to overlay networks is routed by node instead of being bridged into vxlan
+// tunnels, so the intercepted frames never reach remote nodes.
+func (c *CtrlHub) oamRelayLoop(ctx context.Context) {
+	if len(c.config.OAMAgentAddress) == 0 {
+		return
+	}
+
+	go func() {
+		relay := oam.NewRelay(c.config.OAMAgentAddress, c.logger.WithName("oam-relay"))
+		defer relay.Stop()
+
+		ticker := time.NewTicker(oamRelaySyncInterval)
+		defer ticker.Stop()
+
+		for {
+			if c.CacheSynced(ctx) {
+				if err := c.syncOAMRelay(ctx, relay); err != nil {
+					c.logger.Error(err, "failed to sync oam relay")
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *CtrlHub) syncOAMRelay(ctx context.Context, relay *oam.Relay) error {
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.mgr.GetClient().List(ctx, ipInstanceList,
+		client.MatchingLabels{constants.LabelNode: c.config.NodeName}); err != nil {
+		return fmt.Errorf("failed to list ip instances for node %v: %v", c.config.NodeName, err)
+	}
+
+	oamEnabled := map[string]bool{}
+	var hostNicNames []string
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if networkingv1.IsReserved(ipInstance) {
+			continue
+		}
+
+		enabled, checked := oamEnabled[ipInstance.Spec.Network]
+		if !checked {
+			network := &networkingv1.Network{}
+			if err := c.mgr.GetClient().Get(ctx, client.ObjectKey{Name: ipInstance.Spec.Network}, network); err != nil {
+				return fmt.Errorf("failed to get network for ip instance %v: %v", ipInstance.Name, err)
+			}
+
+			enabled = networkingv1.GetNetworkType(network) == networkingv1.NetworkTypeOverlay &&
+				utils.ParseBoolOrDefault(network.Annotations[constants.AnnotationOAMEnabled], false)
+			oamEnabled[ipInstance.Spec.Network] = enabled
+		}
+		if !enabled {
+			continue
+		}
+
+		hostNicName, _ := containernetwork.GenerateContainerVethPair(ipInstance.Namespace, ipInstance.Labels[constants.LabelPod])
+		hostNicNames = append(hostNicNames, hostNicName)
+	}
+
+	// ip instances of a dual-stack pod share the same host nic, duplicated names are ignored by relay
+	return relay.Sync(hostNicNames)
+}
@@ -0,0 +1,100 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/daemon/latency"
+)
+
+const overlayLatencyProbeTimeout = time.Second
+
+// overlayLatencyProbeLoop probes the round-trip time from local vtep to vteps of remote nodes
+// periodically, so that degradation of vxlan tunnels can be detected before it impacts pods.
+func (c *CtrlHub) overlayLatencyProbeLoop(ctx context.Context) {
+	if !c.config.EnableOverlayLatencyProbe {
+		return
+	}
+
+	prober := latency.NewOverlayLatencyProber(overlayLatencyProbeTimeout)
+
+	go func() {
+		ticker := time.NewTicker(c.config.OverlayLatencyProbeInterval)
+		defer ticker.Stop()
+
+		for {
+			// wait for cache to be synced before listing resources
+			if c.CacheSynced(ctx) {
+				if err := c.probeOverlayLatency(ctx, prober); err != nil {
+					c.logger.Error(err, "failed to probe overlay latency of remote nodes")
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *CtrlHub) probeOverlayLatency(ctx context.Context, prober *latency.OverlayLatencyProber) error {
+	nodeInfoList := &networkingv1.NodeInfoList{}
+	if err := c.mgr.GetClient().List(ctx, nodeInfoList); err != nil {
+		return fmt.Errorf("failed to list node info: %v", err)
+	}
+
+	var localVtep net.IP
+	remoteVteps := map[string]net.IP{}
+	for i := range nodeInfoList.Items {
+		nodeInfo := &nodeInfoList.Items[i]
+		if nodeInfo.Spec.VTEPInfo == nil {
+			continue
+		}
+
+		vtepIP := net.ParseIP(nodeInfo.Spec.VTEPInfo.IP)
+		if vtepIP == nil {
+			continue
+		}
+
+		if nodeInfo.Name == c.config.NodeName {
+			localVtep = vtepIP
+			continue
+		}
+		remoteVteps[nodeInfo.Name] = vtepIP
+	}
+
+	if localVtep == nil {
+		// vtep of this node is not ready or there is no overlay network
+		return nil
+	}
+
+	for node, vtepIP := range remoteVteps {
+		if (vtepIP.To4() == nil) != (localVtep.To4() == nil) {
+			delete(remoteVteps, node)
+		}
+	}
+
+	prober.Probe(c.config.NodeName, localVtep, remoteVteps)
+	return nil
+}
@@ -0,0 +1,193 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/daemon/pmtu"
+	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+	"github.com/alibaba/hybridnet/pkg/feature"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const (
+	mtuProbeTimeout = time.Second
+	mtuProbeStep    = 100
+
+	// mtuBlackholeThreshold is how many bytes the effective mtu can be below the
+	// configured vxlan mtu before an mtu black hole is reported
+	mtuBlackholeThreshold = 100
+
+	// mtuStatusPatchRateLimit limits the patches of RemoteVtep status from every node, because
+	// all of the nodes in cluster record effective mtus on the same RemoteVtep objects
+	mtuStatusPatchRateLimit = rate.Limit(1)
+)
+
+// mtuProbeLoop answers mtu probes from other nodes, and probes the effective mtu of paths to
+// remote vteps periodically, because large overlay packets can be silently dropped by middle
+// boxes which never send back icmp fragmentation-needed messages.
+func (c *CtrlHub) mtuProbeLoop(ctx context.Context) error {
+	if !c.config.EnableMTUProbe {
+		return nil
+	}
+
+	if err := pmtu.NewResponder(c.config.MTUProbePort, c.logger.WithName("mtu-probe")).Start(ctx); err != nil {
+		return fmt.Errorf("failed to start mtu probe responder: %v", err)
+	}
+
+	if !feature.MultiClusterEnabled() {
+		return nil
+	}
+
+	go func() {
+		limiter := rate.NewLimiter(mtuStatusPatchRateLimit, 1)
+		ticker := time.NewTicker(c.config.MTUProbeInterval)
+		defer ticker.Stop()
+
+		for {
+			// wait for cache to be synced before listing resources
+			if c.CacheSynced(ctx) {
+				if err := c.probeRemoteVteps(ctx, limiter); err != nil {
+					c.logger.Error(err, "failed to probe effective mtu of remote vteps")
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (c *CtrlHub) probeRemoteVteps(ctx context.Context, limiter *rate.Limiter) error {
+	remoteVtepList := &multiclusterv1.RemoteVtepList{}
+	if err := c.mgr.GetClient().List(ctx, remoteVtepList); err != nil {
+		return fmt.Errorf("failed to list remote vtep: %v", err)
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := c.mgr.GetClient().List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list node: %v", err)
+	}
+
+	existingNodes := map[string]bool{}
+	for _, node := range nodeList.Items {
+		existingNodes[node.Name] = true
+	}
+
+	// clean up the metrics of deleted remote vteps
+	metrics.VtepMTUBlackholeDetectedGauge.Reset()
+
+	for i := range remoteVtepList.Items {
+		remoteVtep := &remoteVtepList.Items[i]
+		vtepIP := net.ParseIP(remoteVtep.Spec.VTEPInfo.IP)
+		if vtepIP == nil {
+			continue
+		}
+
+		overhead, minSize := daemonutils.VxlanIPv4Overhead, 576
+		if vtepIP.To4() == nil {
+			overhead, minSize = daemonutils.VxlanIPv6Overhead, 1280
+		}
+
+		expectedMTU, err := remoteVtepMTU(remoteVtep, c.config.VxlanMTU)
+		if err != nil {
+			c.logger.Error(err, "ignore mtu of remote vtep")
+		}
+
+		size, err := pmtu.Probe(vtepIP, c.config.MTUProbePort, minSize, expectedMTU+overhead,
+			mtuProbeStep, mtuProbeTimeout)
+		if err != nil {
+			c.logger.Error(err, "failed to probe effective mtu", "remoteVtep", remoteVtep.Name, "vtep", vtepIP.String())
+			continue
+		}
+
+		effectiveMTU := size - overhead
+		if effectiveMTU < expectedMTU-mtuBlackholeThreshold {
+			metrics.VtepMTUBlackholeDetectedGauge.WithLabelValues(remoteVtep.Name).Set(1)
+			c.logger.Info("mtu black hole detected on path to remote vtep", "remoteVtep", remoteVtep.Name,
+				"vtep", vtepIP.String(), "effectiveMTU", effectiveMTU, "configuredMTU", expectedMTU)
+		} else {
+			metrics.VtepMTUBlackholeDetectedGauge.WithLabelValues(remoteVtep.Name).Set(0)
+		}
+
+		if err = limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		if err = c.updateRemoteVtepEffectiveMTU(ctx, remoteVtep.Name, int32(effectiveMTU), existingNodes); err != nil {
+			c.logger.Error(err, "failed to update effective mtu of remote vtep", "remoteVtep", remoteVtep.Name)
+		}
+	}
+
+	return nil
+}
+
+// updateRemoteVtepEffectiveMTU records the effective mtu from node to remote vtep in the status
+// of RemoteVtep, the ones recorded by nodes which no longer exist are pruned together.
+func (c *CtrlHub) updateRemoteVtepEffectiveMTU(ctx context.Context, name string, effectiveMTU int32,
+	existingNodes map[string]bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		remoteVtep := &multiclusterv1.RemoteVtep{}
+		if err := c.mgr.GetClient().Get(ctx, client.ObjectKey{Name: name}, remoteVtep); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		patch := client.MergeFromWithOptions(remoteVtep.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if !updateEffectiveMTUs(&remoteVtep.Status.EffectiveMTUs, c.config.NodeName, effectiveMTU, existingNodes) {
+			return nil
+		}
+		return c.mgr.GetClient().Status().Patch(ctx, remoteVtep, patch)
+	})
+}
+
+// updateEffectiveMTUs sets the effective mtu of node and removes the ones of nodes which no
+// longer exist, returns whether effective mtus are changed.
+func updateEffectiveMTUs(effectiveMTUs *map[string]int32, nodeName string, effectiveMTU int32,
+	existingNodes map[string]bool) bool {
+	changed := false
+	for node := range *effectiveMTUs {
+		if !existingNodes[node] && node != nodeName {
+			delete(*effectiveMTUs, node)
+			changed = true
+		}
+	}
+
+	if existing, exist := (*effectiveMTUs)[nodeName]; exist && existing == effectiveMTU {
+		return changed
+	}
+
+	if *effectiveMTUs == nil {
+		*effectiveMTUs = map[string]int32{}
+	}
+	(*effectiveMTUs)[nodeName] = effectiveMTU
+	return true
+}
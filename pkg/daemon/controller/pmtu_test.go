@@ -0,0 +1,74 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpdateEffectiveMTUs(t *testing.T) {
+	existingNodes := map[string]bool{"node1": true, "node2": true}
+
+	tests := []struct {
+		name          string
+		effectiveMTUs map[string]int32
+		effectiveMTU  int32
+		expected      map[string]int32
+		changed       bool
+	}{
+		{
+			name:         "first record",
+			effectiveMTU: 1450,
+			expected:     map[string]int32{"node1": 1450},
+			changed:      true,
+		},
+		{
+			name:          "unchanged",
+			effectiveMTUs: map[string]int32{"node1": 1450, "node2": 1400},
+			effectiveMTU:  1450,
+			expected:      map[string]int32{"node1": 1450, "node2": 1400},
+			changed:       false,
+		},
+		{
+			name:          "updated",
+			effectiveMTUs: map[string]int32{"node1": 1450, "node2": 1400},
+			effectiveMTU:  1350,
+			expected:      map[string]int32{"node1": 1350, "node2": 1400},
+			changed:       true,
+		},
+		{
+			name:          "prune deleted nodes",
+			effectiveMTUs: map[string]int32{"node1": 1450, "node2": 1400, "node3": 1400},
+			effectiveMTU:  1450,
+			expected:      map[string]int32{"node1": 1450, "node2": 1400},
+			changed:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effectiveMTUs := test.effectiveMTUs
+			if changed := updateEffectiveMTUs(&effectiveMTUs, "node1", test.effectiveMTU, existingNodes); changed != test.changed {
+				t.Errorf("expect changed %v but got %v", test.changed, changed)
+			}
+			if !reflect.DeepEqual(effectiveMTUs, test.expected) {
+				t.Errorf("expect effective mtus %v but got %v", test.expected, effectiveMTUs)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// remoteVtepMTU returns the mtu of overlay traffic to a remote vtep, which is the minimum of the
+// local vxlan mtu and the one of remote cluster in the vtep-mtu annotation of RemoteVtep.
+func remoteVtepMTU(remoteVtep *multiclusterv1.RemoteVtep, localMTU int) (int, error) {
+	value, exist := remoteVtep.Annotations[constants.AnnotationVtepMTU]
+	if !exist {
+		return localMTU, nil
+	}
+
+	mtu, err := strconv.Atoi(value)
+	if err != nil || mtu <= 0 {
+		return localMTU, fmt.Errorf("invalid %s annotation %q of remote vtep %v", constants.AnnotationVtepMTU,
+			value, remoteVtep.Name)
+	}
+
+	if mtu < localMTU {
+		return mtu, nil
+	}
+	return localMTU, nil
+}
+
+// remoteClusterRouteMTUs returns the mtu of routes to the overlay subnets of every remote cluster
+// whose RemoteVteps have smaller mtu than the local one. Pod IPs of a remote cluster are not bound
+// to any single vtep, so the smallest mtu of its vteps is taken.
+func remoteClusterRouteMTUs(remoteVteps []multiclusterv1.RemoteVtep, localMTU int) (map[string]int, []error) {
+	var errs []error
+	clusterMTUs := map[string]int{}
+	for i := range remoteVteps {
+		mtu, err := remoteVtepMTU(&remoteVteps[i], localMTU)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		clusterName := remoteVteps[i].Spec.ClusterName
+		if current, exist := clusterMTUs[clusterName]; mtu < localMTU && (!exist || mtu < current) {
+			clusterMTUs[clusterName] = mtu
+		}
+	}
+	return clusterMTUs, errs
+}
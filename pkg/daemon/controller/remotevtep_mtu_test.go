@@ -0,0 +1,58 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestRemoteClusterRouteMTUs(t *testing.T) {
+	remoteVtep := func(name, clusterName, mtu string) multiclusterv1.RemoteVtep {
+		vtep := multiclusterv1.RemoteVtep{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       multiclusterv1.RemoteVtepSpec{ClusterName: clusterName},
+		}
+		if len(mtu) > 0 {
+			vtep.Annotations = map[string]string{constants.AnnotationVtepMTU: mtu}
+		}
+		return vtep
+	}
+
+	clusterMTUs, errs := remoteClusterRouteMTUs([]multiclusterv1.RemoteVtep{
+		remoteVtep("a-1", "cluster-a", "1400"),
+		remoteVtep("a-2", "cluster-a", "1300"),
+		remoteVtep("a-3", "cluster-a", ""),
+		remoteVtep("b-1", "cluster-b", "9000"),
+		remoteVtep("c-1", "cluster-c", ""),
+		remoteVtep("d-1", "cluster-d", "invalid"),
+		remoteVtep("d-2", "cluster-d", "-1"),
+	}, 1450)
+
+	expected := map[string]int{"cluster-a": 1300}
+	if !reflect.DeepEqual(clusterMTUs, expected) {
+		t.Fatalf("expected route mtus %v but got %v", expected, clusterMTUs)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected two invalid annotations but got %v", errs)
+	}
+}
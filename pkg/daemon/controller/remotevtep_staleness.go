@@ -0,0 +1,69 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+// staleRemoteVtepRequeueDelay is the delay of reconciling again after stale RemoteVteps
+// are found, which gives the informer cache some time to catch up
+const staleRemoteVtepRequeueDelay = time.Second
+
+// findStaleRemoteVteps reads the RemoteVteps again from the informer cache after their fdb entries
+// are installed, and returns the names of the ones which have been deleted or whose vtep infos or
+// endpoint ips differ from the installed ones, i.e., the cache has been updated during installation.
+// Only the fields used to install fdb entries are compared, so status updates never trigger reconciling.
+func findStaleRemoteVteps(ctx context.Context, reader client.Reader, installed []multiclusterv1.RemoteVtep) ([]string, error) {
+	var staleNames []string
+	for i := range installed {
+		remoteVtep := &installed[i]
+		current := &multiclusterv1.RemoteVtep{}
+		if err := reader.Get(ctx, client.ObjectKeyFromObject(remoteVtep), current); err != nil {
+			if apierrors.IsNotFound(err) {
+				staleNames = append(staleNames, remoteVtep.Name)
+				continue
+			}
+			return nil, fmt.Errorf("failed to get remote vtep %v: %v", remoteVtep.Name, err)
+		}
+
+		if remoteVtepInfoChanged(&remoteVtep.Spec, &current.Spec) {
+			staleNames = append(staleNames, remoteVtep.Name)
+		}
+	}
+
+	if len(staleNames) > 0 {
+		metrics.RemoteVtepStaleCacheCounter.Add(float64(len(staleNames)))
+	}
+	return staleNames, nil
+}
+
+func remoteVtepInfoChanged(installed, current *multiclusterv1.RemoteVtepSpec) bool {
+	return installed.VTEPInfo.IP != current.VTEPInfo.IP ||
+		installed.VTEPInfo.MAC != current.VTEPInfo.MAC ||
+		!utils.DeepEqualStringSlice(installed.VTEPInfo.LocalIPs, current.VTEPInfo.LocalIPs) ||
+		!utils.DeepEqualStringSlice(installed.EndpointIPList, current.EndpointIPList)
+}
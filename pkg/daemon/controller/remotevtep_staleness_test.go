@@ -0,0 +1,93 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+func TestFindStaleRemoteVteps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = multiclusterv1.AddToScheme(scheme)
+
+	remoteVtep := func(name, ip string) *multiclusterv1.RemoteVtep {
+		return &multiclusterv1.RemoteVtep{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: multiclusterv1.RemoteVtepSpec{
+				ClusterName: "cluster1",
+				VTEPInfo: networkingv1.VTEPInfo{
+					IP:  ip,
+					MAC: "aa:bb:cc:dd:ee:ff",
+				},
+				EndpointIPList: []string{"10.0.0.1"},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		remoteVtep("fresh", "192.168.0.1"),
+		remoteVtep("status-updated", "192.168.0.2"),
+		remoteVtep("ip-updated", "192.168.0.3"),
+		remoteVtep("endpoints-updated", "192.168.0.4"),
+	).Build()
+
+	var installed []multiclusterv1.RemoteVtep
+	for _, name := range []string{"fresh", "status-updated", "ip-updated", "endpoints-updated"} {
+		remoteVtep := &multiclusterv1.RemoteVtep{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: name}, remoteVtep); err != nil {
+			t.Fatalf("fail to get remote vtep %s: %v", name, err)
+		}
+		installed = append(installed, *remoteVtep)
+	}
+	installed = append(installed, *remoteVtep("deleted", "192.168.0.5"))
+
+	statusUpdated := installed[1].DeepCopy()
+	statusUpdated.Status.LastModifyTime = metav1.Now()
+	ipUpdated := installed[2].DeepCopy()
+	ipUpdated.Spec.VTEPInfo.IP = "192.168.1.3"
+	endpointsUpdated := installed[3].DeepCopy()
+	endpointsUpdated.Spec.EndpointIPList = append(endpointsUpdated.Spec.EndpointIPList, "10.0.0.2")
+	for _, updated := range []*multiclusterv1.RemoteVtep{statusUpdated, ipUpdated, endpointsUpdated} {
+		if err := c.Update(context.Background(), updated); err != nil {
+			t.Fatalf("fail to update remote vtep %s: %v", updated.Name, err)
+		}
+	}
+
+	before := testutil.ToFloat64(metrics.RemoteVtepStaleCacheCounter)
+	staleNames, err := findStaleRemoteVteps(context.Background(), c, installed)
+	if err != nil {
+		t.Fatalf("fail to find stale remote vteps: %v", err)
+	}
+	if len(staleNames) != 3 || staleNames[0] != "ip-updated" || staleNames[1] != "endpoints-updated" ||
+		staleNames[2] != "deleted" {
+		t.Fatalf("expected [ip-updated endpoints-updated deleted] but got %v", staleNames)
+	}
+	if got := testutil.ToFloat64(metrics.RemoteVtepStaleCacheCounter) - before; got != 3 {
+		t.Fatalf("expected 3 stale cache hits but got %v", got)
+	}
+}
@@ -19,8 +19,10 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 
+	"github.com/alibaba/hybridnet/pkg/daemon/route"
 	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,12 +36,14 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
 	"github.com/alibaba/hybridnet/pkg/feature"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -93,15 +97,28 @@ func (r *subnetReconciler) Reconcile(ctx context.Context, request reconcile.Requ
 
 		var forwardNodeIfName string
 		var autoNatOutgoing, isOverlay bool
+		var podRoutes []*route.PodRoute
 		networkMode := networkingv1.GetNetworkMode(network)
 
 		switch networkMode {
 		case networkingv1.NetworkModeVlan:
 			if isUnderlayOnHost {
-				forwardNodeIfName, err = daemonutils.EnsureVlanIf(r.ctrlHubRef.config.NodeVlanIfName, netID)
+				vlanParentIfName := r.ctrlHubRef.config.NodeVlanIfName
+				if networkingv1.IsQinQNetwork(network) {
+					vlanParentIfName, err = daemonutils.EnsureQinQServiceIf(vlanParentIfName, network.Spec.ServiceVlanID)
+					if err != nil {
+						return reconcile.Result{Requeue: true}, fmt.Errorf("failed to ensure qinq service interface: %v", err)
+					}
+				}
+
+				forwardNodeIfName, err = daemonutils.EnsureVlanIf(vlanParentIfName, netID)
 				if err != nil {
 					return reconcile.Result{Requeue: true}, fmt.Errorf("failed to ensure vlan forward node interface: %v", err)
 				}
+
+				if podRoutes, err = parseSubnetPodRoutes(subnet.Spec.PodRoutes); err != nil {
+					return reconcile.Result{Requeue: true}, fmt.Errorf("failed to parse pod routes of subnet %v: %v", subnet.Name, err)
+				}
 			}
 		case networkingv1.NetworkModeVxlan:
 			forwardNodeIfName = overlayForwardNodeIfName
@@ -132,7 +149,7 @@ func (r *subnetReconciler) Reconcile(ctx context.Context, request reconcile.Requ
 		// create policy route
 		routeManager := r.ctrlHubRef.getRouterManager(subnet.Spec.Range.Version)
 		routeManager.AddSubnetInfo(subnetCidr, gatewayIP, startIP, endIP, excludeIPs,
-			forwardNodeIfName, autoNatOutgoing, isOverlay, isUnderlayOnHost, networkMode)
+			forwardNodeIfName, autoNatOutgoing, isOverlay, isUnderlayOnHost, networkMode, podRoutes)
 	}
 
 	if feature.MultiClusterEnabled() {
@@ -143,6 +160,28 @@ func (r *subnetReconciler) Reconcile(ctx context.Context, request reconcile.Requ
 			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to list remote subnet %v", err)
 		}
 
+		remoteVtepList := &multiclusterv1.RemoteVtepList{}
+		if err := r.List(ctx, remoteVtepList); err != nil {
+			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to list remote vtep %v", err)
+		}
+
+		// dedicated route tables of remote clusters are selected by rules from the vtep address of this node
+		thisNodeInfo := &networkingv1.NodeInfo{}
+		if err := r.Get(ctx, types.NamespacedName{Name: r.ctrlHubRef.config.NodeName}, thisNodeInfo); err != nil {
+			if !errors.IsNotFound(err) {
+				return reconcile.Result{Requeue: true}, fmt.Errorf("failed to get node info %v", err)
+			}
+		} else if thisNodeInfo.Spec.VTEPInfo != nil {
+			localVtepIP := net.ParseIP(thisNodeInfo.Spec.VTEPInfo.IP)
+			r.ctrlHubRef.routeV4Manager.SetLocalVtepIP(localVtepIP)
+			r.ctrlHubRef.routeV6Manager.SetLocalVtepIP(localVtepIP)
+		}
+
+		clusterRouteMTUs, errs := remoteClusterRouteMTUs(remoteVtepList.Items, r.ctrlHubRef.config.VxlanMTU)
+		for _, err := range errs {
+			logger.Error(err, "ignore mtu of remote vtep")
+		}
+
 		for _, remoteSubnet := range remoteSubnetList.Items {
 			subnetCidr, gatewayIP, startIP, endIP, excludeIPs,
 				_, err := parseSubnetSpecRangeMeta(&remoteSubnet.Spec.Range)
@@ -153,8 +192,14 @@ func (r *subnetReconciler) Reconcile(ctx context.Context, request reconcile.Requ
 
 			var isOverlay = multiclusterv1.GetRemoteSubnetType(&remoteSubnet) == networkingv1.NetworkTypeOverlay
 
+			var routeMetric = r.ctrlHubRef.config.RemoteRouteDefaultMetric
+			if remoteSubnet.Spec.RouteMetric != nil {
+				routeMetric = int(*remoteSubnet.Spec.RouteMetric)
+			}
+
 			routeManager := r.ctrlHubRef.getRouterManager(remoteSubnet.Spec.Range.Version)
-			err = routeManager.AddRemoteSubnetInfo(subnetCidr, gatewayIP, startIP, endIP, excludeIPs, isOverlay)
+			err = routeManager.AddRemoteSubnetInfo(subnetCidr, gatewayIP, startIP, endIP, excludeIPs, isOverlay,
+				remoteSubnet.Spec.ClusterName, routeMetric, clusterRouteMTUs[remoteSubnet.Spec.ClusterName])
 
 			if err != nil {
 				return reconcile.Result{Requeue: true}, fmt.Errorf("failed to add remote subnet info: %v", err)
@@ -162,6 +207,15 @@ func (r *subnetReconciler) Reconcile(ctx context.Context, request reconcile.Requ
 		}
 	}
 
+	if len(r.ctrlHubRef.config.HostVRFName) != 0 {
+		vrf, err := daemonutils.GetVRF(r.ctrlHubRef.config.HostVRFName)
+		if err != nil {
+			return reconcile.Result{Requeue: true}, fmt.Errorf("failed to get host vrf: %v", err)
+		}
+		r.ctrlHubRef.routeV4Manager.SetVRFTable(int(vrf.Table))
+		r.ctrlHubRef.routeV6Manager.SetVRFTable(int(vrf.Table))
+	}
+
 	if err := r.ctrlHubRef.routeV4Manager.SyncRoutes(); err != nil {
 		return reconcile.Result{Requeue: true}, fmt.Errorf("failed to sync ipv4 routes: %v", err)
 	}
@@ -211,6 +265,7 @@ func (r *subnetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 					(oldSubnetNetID != nil && newSubnetNetID != nil && *oldSubnetNetID != *newSubnetNetID) ||
 					oldSubnet.Spec.Network != newSubnet.Spec.Network ||
 					!reflect.DeepEqual(oldSubnet.Spec.Range, newSubnet.Spec.Range) ||
+					!reflect.DeepEqual(oldSubnet.Spec.PodRoutes, newSubnet.Spec.PodRoutes) ||
 					networkingv1.IsSubnetAutoNatOutgoing(&oldSubnet.Spec) != networkingv1.IsSubnetAutoNatOutgoing(&newSubnet.Spec) {
 					return true
 				}
@@ -278,6 +333,22 @@ func (r *subnetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		); err != nil {
 			return fmt.Errorf("failed to watch multiclusterv1.RemoteSubnet for subnet controller: %v", err)
 		}
+
+		if err := subnetController.Watch(&source.Kind{
+			Type: &multiclusterv1.RemoteVtep{}},
+			&fixedKeyHandler{key: "ForRemoteVtepChange"},
+			predicate.Funcs{
+				UpdateFunc: func(updateEvent event.UpdateEvent) bool {
+					oldRv := updateEvent.ObjectOld.(*multiclusterv1.RemoteVtep)
+					newRv := updateEvent.ObjectNew.(*multiclusterv1.RemoteVtep)
+
+					return oldRv.Spec.ClusterName != newRv.Spec.ClusterName ||
+						oldRv.Annotations[constants.AnnotationVtepMTU] != newRv.Annotations[constants.AnnotationVtepMTU]
+				},
+			},
+		); err != nil {
+			return fmt.Errorf("failed to watch multiclusterv1.RemoteVtep for subnet controller: %v", err)
+		}
 	}
 
 	return nil
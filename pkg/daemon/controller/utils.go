@@ -248,6 +248,28 @@ func parseSubnetSpecRangeMeta(addressRange *networkingv1.AddressRange) (cidr *ne
 	return
 }
 
+// parseSubnetPodRoutes parses the pod routes of subnet for route manager
+func parseSubnetPodRoutes(podRoutes []networkingv1.StaticRoute) ([]*route.PodRoute, error) {
+	var routes []*route.PodRoute
+	for _, podRoute := range podRoutes {
+		_, dst, err := net.ParseCIDR(podRoute.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse destination %v of pod route: %v", podRoute.Destination, err)
+		}
+
+		nextHop := net.ParseIP(podRoute.NextHop)
+		if nextHop == nil {
+			return nil, fmt.Errorf("failed to parse next hop %v of pod route", podRoute.NextHop)
+		}
+
+		routes = append(routes, &route.PodRoute{
+			Dst:     dst,
+			NextHop: nextHop,
+		})
+	}
+	return routes, nil
+}
+
 func isIPListEqual(a, b []string) bool {
 	if len(a) == 0 && len(b) == 0 {
 		return true
@@ -314,10 +336,11 @@ func collectGlobalNetworkInfoAndInit(ctx context.Context, client client.Reader,
 			for _, peer := range network.Spec.Config.BGPPeers {
 				if recordBGPPeers {
 					bgpManager.RecordPeer(peer.Address, peer.Password, int(peer.ASN),
-						peer.GracefulRestartSeconds, peer.AllowNotEstablished)
+						peer.GracefulRestartSeconds, peer.AllowNotEstablished, peer.BGPUnnumbered)
 				}
 
-				if peer.DoesNotRouteTraffic {
+				// routing IPv4 traffic of pods through link-local gateway is not supported yet
+				if peer.DoesNotRouteTraffic || peer.BGPUnnumbered {
 					continue
 				}
 
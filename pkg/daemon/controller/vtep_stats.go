@@ -0,0 +1,69 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/stats"
+)
+
+const vtepStatsCollectInterval = 15 * time.Second
+
+// vtepStatsLoop periodically collects the traffic statistics of the vtep interfaces created by
+// hybridnet and exports them as metrics.
+func (c *CtrlHub) vtepStatsLoop(ctx context.Context) {
+	go func() {
+		recorder := stats.NewVTEPStatsRecorder()
+		ticker := time.NewTicker(vtepStatsCollectInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := recordVtepStats(recorder); err != nil {
+				c.logger.Error(err, "failed to record vtep stats")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func recordVtepStats(recorder *stats.VTEPStatsRecorder) error {
+	linkList, err := netlink.LinkList()
+	if err != nil {
+		return fmt.Errorf("failed to list links: %v", err)
+	}
+
+	var vtepList []string
+	for _, link := range linkList {
+		if link.Type() == "vxlan" && strings.Contains(link.Attrs().Name, constants.VxlanLinkInfix) {
+			vtepList = append(vtepList, link.Attrs().Name)
+		}
+	}
+
+	return recorder.Record(vtepList)
+}
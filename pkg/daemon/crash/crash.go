@@ -0,0 +1,173 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExitCode is the exit code of daemon after crash report is written
+const ExitCode = 2
+
+// stateCollectTimeout is the max time to wait for collecting a registered state
+const stateCollectTimeout = 5 * time.Second
+
+// StateFunc collects a piece of daemon state to be recorded in crash report
+type StateFunc func() (interface{}, error)
+
+// Report is the structured crash report of daemon
+type Report struct {
+	Time       time.Time              `json:"time"`
+	NodeName   string                 `json:"nodeName"`
+	Panic      string                 `json:"panic"`
+	Goroutines []string               `json:"goroutines"`
+	State      map[string]interface{} `json:"state,omitempty"`
+}
+
+// Reporter writes crash reports into crash directory if daemon panics
+type Reporter struct {
+	dir      string
+	nodeName string
+
+	mu     sync.Mutex
+	states map[string]StateFunc
+}
+
+// NewReporter creates a crash reporter, empty dir means crash reports are disabled
+// and panics will be propagated as usual
+func NewReporter(dir, nodeName string) *Reporter {
+	return &Reporter{
+		dir:      dir,
+		nodeName: nodeName,
+		states:   map[string]StateFunc{},
+	}
+}
+
+// RegisterState registers a state which will be collected into crash report with name
+func (r *Reporter) RegisterState(name string, f StateFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[name] = f
+}
+
+// Recover must be deferred directly at the beginning of goroutines, it recovers the
+// panic, writes crash report and exits daemon with ExitCode
+func (r *Reporter) Recover() {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	if len(r.dir) == 0 {
+		panic(p)
+	}
+
+	path, err := r.WriteReport(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "daemon panics and crash report is written to %s\n", path)
+	}
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s", p, stack(false))
+	os.Exit(ExitCode)
+}
+
+// WriteReport writes the crash report of panic into crash directory and returns its path
+func (r *Reporter) WriteReport(p interface{}) (string, error) {
+	now := time.Now()
+	report := &Report{
+		Time:       now,
+		NodeName:   r.nodeName,
+		Panic:      fmt.Sprint(p),
+		Goroutines: strings.Split(strings.TrimSpace(string(stack(true))), "\n\n"),
+		State:      r.collectStates(),
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal crash report: %v", err)
+	}
+
+	if err = os.MkdirAll(r.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash directory %s: %v", r.dir, err)
+	}
+
+	path := filepath.Join(r.dir, fmt.Sprintf("hybridnet-daemon-crash-%s.json", now.Format("20060102-150405.000")))
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write crash report %s: %v", path, err)
+	}
+	return path, nil
+}
+
+func (r *Reporter) collectStates() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make(map[string]interface{}, len(r.states))
+	for name, f := range r.states {
+		states[name] = collectState(f)
+	}
+	return states
+}
+
+// collectState runs state function with timeout, the state may be broken because of the
+// panic, so any error will be recorded instead of being propagated
+func collectState(f StateFunc) interface{} {
+	type result struct {
+		state interface{}
+		err   error
+	}
+
+	resultCh := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				resultCh <- result{err: fmt.Errorf("panic while collecting state: %v", p)}
+			}
+		}()
+		state, err := f()
+		resultCh <- result{state: state, err: err}
+	}()
+
+	select {
+	case res := <-resultCh:
+		if res.err != nil {
+			return map[string]string{"error": res.err.Error()}
+		}
+		return res.state
+	case <-time.After(stateCollectTimeout):
+		return map[string]string{"error": "timeout while collecting state"}
+	}
+}
+
+func stack(all bool) []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestReporter_WriteReport(t *testing.T) {
+	reporter := NewReporter(t.TempDir(), "node1")
+	reporter.RegisterState("normal", func() (interface{}, error) {
+		return map[string]int{"count": 1}, nil
+	})
+	reporter.RegisterState("error", func() (interface{}, error) {
+		return nil, fmt.Errorf("broken")
+	})
+	reporter.RegisterState("panic", func() (interface{}, error) {
+		panic("broken")
+	})
+
+	path, err := reporter.WriteReport("test panic")
+	if err != nil {
+		t.Fatalf("fail to write report: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("fail to read report: %v", err)
+	}
+
+	report := &Report{}
+	if err = json.Unmarshal(content, report); err != nil {
+		t.Fatalf("fail to unmarshal report: %v", err)
+	}
+
+	if report.NodeName != "node1" || report.Panic != "test panic" {
+		t.Errorf("unexpected node name %s or panic %s", report.NodeName, report.Panic)
+	}
+	if len(report.Goroutines) == 0 {
+		t.Errorf("goroutine dump should not be empty")
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"normal", `{"count":1}`},
+		{"error", `{"error":"broken"}`},
+		{"panic", `{"error":"panic while collecting state: broken"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state, _ := json.Marshal(report.State[test.name])
+			if string(state) != test.expected {
+				t.Errorf("test %s fails: expected %s but got %s", test.name, test.expected, state)
+			}
+		})
+	}
+}
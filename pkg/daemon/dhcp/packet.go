@@ -0,0 +1,162 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	ipProtocolUDP  = 17
+	dhcpClientPort = 68
+
+	bootReply       = 2
+	hardwareTypeEth = 1
+
+	// fixed fields of BOOTP message before the magic cookie (RFC 2131)
+	bootpFixedLength = 236
+)
+
+// Option codes of DHCP (RFC 2132) which hybridnet cares about.
+const (
+	optionPad         = 0
+	optionSubnetMask  = 1
+	optionRouter      = 3
+	optionLeaseTime   = 51
+	optionMessageType = 53
+	optionServerID    = 54
+	optionEnd         = 255
+)
+
+const messageTypeACK = 5
+
+var magicCookie = []byte{99, 130, 83, 99}
+
+var (
+	errInvalidPacket = errors.New("invalid DHCP packet")
+	errNotACK        = errors.New("not a DHCPACK")
+)
+
+// Lease is the IP/MAC binding granted by a DHCP server.
+type Lease struct {
+	IP         net.IP
+	MAC        net.HardwareAddr
+	SubnetMask net.IPMask
+	Router     net.IP
+	ServerID   net.IP
+
+	// LeaseTime will be zero if the server does not announce it
+	LeaseTime time.Duration
+}
+
+// ParseIPv4Packet parses an IPv4 packet carrying a DHCPACK to a client into a Lease,
+// fragmented packets are not supported.
+func ParseIPv4Packet(b []byte) (*Lease, error) {
+	if len(b) < 20 || b[0]>>4 != 4 {
+		return nil, errInvalidPacket
+	}
+
+	headerLength := int(b[0]&0x0f) * 4
+	if headerLength < 20 || len(b) < headerLength+8 || b[9] != ipProtocolUDP {
+		return nil, errInvalidPacket
+	}
+
+	udp := b[headerLength:]
+	if binary.BigEndian.Uint16(udp[2:4]) != dhcpClientPort {
+		return nil, errInvalidPacket
+	}
+	udpLength := int(binary.BigEndian.Uint16(udp[4:6]))
+	if udpLength < 8 || udpLength > len(udp) {
+		return nil, errInvalidPacket
+	}
+
+	return ParseACK(udp[8:udpLength])
+}
+
+// ParseACK parses the BOOTP message of a DHCPACK into a Lease.
+func ParseACK(b []byte) (*Lease, error) {
+	if len(b) < bootpFixedLength+len(magicCookie) {
+		return nil, errInvalidPacket
+	}
+
+	if b[0] != bootReply || b[1] != hardwareTypeEth || b[2] != 6 {
+		return nil, errInvalidPacket
+	}
+
+	if string(b[bootpFixedLength:bootpFixedLength+len(magicCookie)]) != string(magicCookie) {
+		return nil, errInvalidPacket
+	}
+
+	lease := &Lease{
+		IP:  net.IP(append([]byte{}, b[16:20]...)),
+		MAC: net.HardwareAddr(append([]byte{}, b[28:34]...)),
+	}
+
+	var messageType byte
+	options := b[bootpFixedLength+len(magicCookie):]
+	for len(options) > 0 {
+		code := options[0]
+		if code == optionEnd {
+			break
+		}
+		if code == optionPad {
+			options = options[1:]
+			continue
+		}
+
+		if len(options) < 2 || len(options) < 2+int(options[1]) {
+			return nil, errInvalidPacket
+		}
+		value := options[2 : 2+int(options[1])]
+		options = options[2+int(options[1]):]
+
+		switch code {
+		case optionMessageType:
+			if len(value) == 1 {
+				messageType = value[0]
+			}
+		case optionSubnetMask:
+			if len(value) == 4 {
+				lease.SubnetMask = net.IPMask(append([]byte{}, value...))
+			}
+		case optionRouter:
+			// only the first router is taken
+			if len(value) >= 4 {
+				lease.Router = net.IP(append([]byte{}, value[:4]...))
+			}
+		case optionServerID:
+			if len(value) == 4 {
+				lease.ServerID = net.IP(append([]byte{}, value...))
+			}
+		case optionLeaseTime:
+			if len(value) == 4 {
+				lease.LeaseTime = time.Duration(binary.BigEndian.Uint32(value)) * time.Second
+			}
+		}
+	}
+
+	if messageType != messageTypeACK {
+		return nil, errNotACK
+	}
+	if lease.IP.IsUnspecified() {
+		return nil, errInvalidPacket
+	}
+	return lease, nil
+}
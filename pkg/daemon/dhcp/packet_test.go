@@ -0,0 +1,156 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+func bootpReply(yiaddr net.IP, chaddr net.HardwareAddr, options ...[]byte) []byte {
+	b := make([]byte, bootpFixedLength)
+	b[0], b[1], b[2] = bootReply, hardwareTypeEth, 6
+	copy(b[16:20], yiaddr.To4())
+	copy(b[28:34], chaddr)
+	b = append(b, magicCookie...)
+	for _, option := range options {
+		b = append(b, option...)
+	}
+	return append(b, optionEnd)
+}
+
+func option(code byte, value ...byte) []byte {
+	return append([]byte{code, byte(len(value))}, value...)
+}
+
+// ipv4UDPPacket wraps payload into an unfragmented udp packet to the DHCP client port
+func ipv4UDPPacket(payload []byte) []byte {
+	b := make([]byte, 28, 28+len(payload))
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:4], uint16(28+len(payload)))
+	b[9] = ipProtocolUDP
+	binary.BigEndian.PutUint16(b[20:22], 67)
+	binary.BigEndian.PutUint16(b[22:24], dhcpClientPort)
+	binary.BigEndian.PutUint16(b[24:26], uint16(8+len(payload)))
+	return append(b, payload...)
+}
+
+func TestParseACK(t *testing.T) {
+	mac, _ := net.ParseMAC("00:00:5e:00:53:01")
+	ack := bootpReply(net.ParseIP("192.168.0.10"), mac,
+		option(optionMessageType, messageTypeACK),
+		[]byte{optionPad},
+		option(optionSubnetMask, 255, 255, 255, 0),
+		option(optionRouter, 192, 168, 0, 1, 192, 168, 0, 2),
+		option(optionServerID, 192, 168, 0, 254),
+		option(optionLeaseTime, 0x00, 0x00, 0x0e, 0x10),
+	)
+
+	tests := []struct {
+		name  string
+		b     []byte
+		lease *Lease
+		err   error
+	}{
+		{
+			"full options",
+			ack,
+			&Lease{
+				IP:         net.IP{192, 168, 0, 10},
+				MAC:        mac,
+				SubnetMask: net.IPMask{255, 255, 255, 0},
+				Router:     net.IP{192, 168, 0, 1},
+				ServerID:   net.IP{192, 168, 0, 254},
+				LeaseTime:  time.Hour,
+			},
+			nil,
+		},
+		{
+			"offer is ignored",
+			bootpReply(net.ParseIP("192.168.0.10"), mac, option(optionMessageType, 2)),
+			nil,
+			errNotACK,
+		},
+		{
+			"missing message type",
+			bootpReply(net.ParseIP("192.168.0.10"), mac),
+			nil,
+			errNotACK,
+		},
+		{
+			"truncated option",
+			append(bootpReply(net.ParseIP("192.168.0.10"), mac)[:bootpFixedLength+4], optionSubnetMask, 4, 255),
+			nil,
+			errInvalidPacket,
+		},
+		{
+			"bad magic cookie",
+			append(make([]byte, bootpFixedLength), 1, 2, 3, 4),
+			nil,
+			errInvalidPacket,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lease, err := ParseACK(test.b)
+			if err != test.err {
+				t.Fatalf("expected error %v but got %v", test.err, err)
+			}
+			if !reflect.DeepEqual(lease, test.lease) {
+				t.Fatalf("expected lease %+v but got %+v", test.lease, lease)
+			}
+		})
+	}
+
+	lease, err := ParseIPv4Packet(ipv4UDPPacket(ack))
+	if err != nil || !lease.IP.Equal(net.ParseIP("192.168.0.10")) {
+		t.Fatalf("expected lease parsed from ipv4 packet but got %+v, %v", lease, err)
+	}
+}
+
+func TestClientPortFilter(t *testing.T) {
+	vm, err := bpf.NewVM(clientPortFilter)
+	if err != nil {
+		t.Fatalf("invalid filter: %v", err)
+	}
+
+	ethernetHeader := make([]byte, 14)
+	binary.BigEndian.PutUint16(ethernetHeader[12:14], 0x0800)
+
+	packet := ipv4UDPPacket(make([]byte, 300))
+	if n, err := vm.Run(append(ethernetHeader, packet...)); err != nil || n == 0 {
+		t.Fatalf("expected packet to client port accepted but got %v, %v", n, err)
+	}
+
+	packet = ipv4UDPPacket(make([]byte, 300))
+	binary.BigEndian.PutUint16(packet[22:24], 53)
+	if n, err := vm.Run(append(ethernetHeader, packet...)); err != nil || n != 0 {
+		t.Fatalf("expected packet to other ports dropped but got %v, %v", n, err)
+	}
+
+	packet = ipv4UDPPacket(make([]byte, 300))
+	packet[9] = 6
+	if n, err := vm.Run(append(ethernetHeader, packet...)); err != nil || n != 0 {
+		t.Fatalf("expected tcp packet dropped but got %v, %v", n, err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/mdlayher/ethernet"
+	"github.com/mdlayher/raw"
+	"golang.org/x/net/bpf"
+)
+
+// readTimeout bounds each read so that the cancellation of context can be noticed.
+const readTimeout = time.Second
+
+// clientPortFilter only accepts unfragmented udp packets to the DHCP client port,
+// so that other IPv4 traffic of the interface never reaches user space.
+var clientPortFilter = []bpf.Instruction{
+	// ip protocol
+	bpf.LoadAbsolute{Off: 23, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: ipProtocolUDP, SkipTrue: 6},
+	// fragment offset
+	bpf.LoadAbsolute{Off: 20, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 4},
+	// udp destination port after ip header
+	bpf.LoadMemShift{Off: 14},
+	bpf.LoadIndirect{Off: 16, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpNotEqual, Val: dhcpClientPort, SkipTrue: 1},
+	bpf.RetConstant{Val: 0xffff},
+	bpf.RetConstant{Val: 0},
+}
+
+// Snoop listens DHCPACKs on the interface until context is done, and calls handle with
+// the lease of every DHCPACK. Interface is never set to promiscuous mode, so only the
+// DHCPACKs broadcast or unicast to MAC addresses accepted by the interface are snooped.
+func Snoop(ctx context.Context, ifi *net.Interface, handle func(*Lease)) error {
+	filter, err := bpf.Assemble(clientPortFilter)
+	if err != nil {
+		return fmt.Errorf("failed to assemble bpf filter: %v", err)
+	}
+
+	conn, err := raw.ListenPacket(ifi, uint16(ethernet.EtherTypeIPv4), &raw.Config{Filter: filter})
+	if err != nil {
+		return fmt.Errorf("failed to listen DHCP on interface %v: %v", ifi.Name, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, ifi.MTU+14)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if err = conn.SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+			return fmt.Errorf("failed to set read deadline: %v", err)
+		}
+
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return fmt.Errorf("failed to read DHCP packet from interface %v: %v", ifi.Name, err)
+		}
+
+		frame := &ethernet.Frame{}
+		if err := frame.UnmarshalBinary(buf[:n]); err != nil {
+			continue
+		}
+
+		if frame.EtherType != ethernet.EtherTypeIPv4 {
+			continue
+		}
+
+		lease, err := ParseIPv4Packet(frame.Payload)
+		if err != nil {
+			continue
+		}
+		handle(lease)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package latency
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-ping/ping"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const (
+	// smoothingFactor is the weight of the latest rtt in the exponentially smoothed one
+	smoothingFactor = 0.3
+
+	// maxConcurrentProbes bounds the number of remote nodes probed at the same time
+	maxConcurrentProbes = 16
+)
+
+// PingFunc sends an icmp echo request from source to target and returns the round-trip time.
+type PingFunc func(source, target net.IP, timeout time.Duration) (time.Duration, error)
+
+// OverlayLatencyProber probes the round-trip time from local vtep to vteps of remote nodes,
+// and records the exponentially smoothed rtt of each remote node, so that a single dropped
+// or delayed probe will not make a spike of metrics.
+type OverlayLatencyProber struct {
+	ping    PingFunc
+	timeout time.Duration
+
+	mu       sync.Mutex
+	smoothed map[string]time.Duration
+}
+
+func NewOverlayLatencyProber(timeout time.Duration) *OverlayLatencyProber {
+	return newOverlayLatencyProber(icmpPing, timeout)
+}
+
+func newOverlayLatencyProber(ping PingFunc, timeout time.Duration) *OverlayLatencyProber {
+	return &OverlayLatencyProber{
+		ping:     ping,
+		timeout:  timeout,
+		smoothed: map[string]time.Duration{},
+	}
+}
+
+// Probe pings the vteps of remote nodes keyed by node names from local vtep, metrics of nodes
+// missing from remoteVteps are cleaned up.
+func (p *OverlayLatencyProber) Probe(localNode string, localVtep net.IP, remoteVteps map[string]net.IP) {
+	p.mu.Lock()
+	for node := range p.smoothed {
+		if _, exist := remoteVteps[node]; !exist {
+			delete(p.smoothed, node)
+			metrics.OverlayRTTSeconds.DeleteLabelValues(node, localNode)
+		}
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentProbes)
+	for node, vtep := range remoteVteps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(node string, vtep net.IP) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			rtt, err := p.ping(localVtep, vtep, p.timeout)
+			if err != nil {
+				// dropped probes are not observed, the smoothed rtt is kept
+				return
+			}
+			metrics.OverlayRTTSeconds.WithLabelValues(node, localNode).Observe(p.smooth(node, rtt).Seconds())
+		}(node, vtep)
+	}
+	wg.Wait()
+}
+
+func (p *OverlayLatencyProber) smooth(node string, rtt time.Duration) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if previous, exist := p.smoothed[node]; exist {
+		rtt = time.Duration(smoothingFactor*float64(rtt) + (1-smoothingFactor)*float64(previous))
+	}
+	p.smoothed[node] = rtt
+	return rtt
+}
+
+// SmoothedRTT returns the smoothed rtt of remote node, false if it has never been probed successfully.
+func (p *OverlayLatencyProber) SmoothedRTT(node string) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rtt, exist := p.smoothed[node]
+	return rtt, exist
+}
+
+func icmpPing(source, target net.IP, timeout time.Duration) (time.Duration, error) {
+	pinger, err := ping.NewPinger(target.String())
+	if err != nil {
+		return 0, fmt.Errorf("failed to init pinger: %v", err)
+	}
+
+	pinger.SetPrivileged(true)
+	pinger.Source = source.String()
+	pinger.Count = 1
+	pinger.Timeout = timeout
+
+	if err = pinger.Run(); err != nil {
+		return 0, fmt.Errorf("failed to ping %v: %v", target, err)
+	}
+
+	statistics := pinger.Statistics()
+	if statistics.PacketsRecv == 0 {
+		return 0, fmt.Errorf("no reply from %v in %v", target, timeout)
+	}
+	return statistics.AvgRtt, nil
+}
@@ -0,0 +1,79 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package latency
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProbeSmoothsRTT(t *testing.T) {
+	replies := map[string][]time.Duration{
+		"node2": {10 * time.Millisecond, 20 * time.Millisecond, 0, 20 * time.Millisecond},
+		"node3": {0},
+	}
+	var mu sync.Mutex
+	p := newOverlayLatencyProber(func(source, target net.IP, timeout time.Duration) (time.Duration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		node := "node2"
+		if target.Equal(net.ParseIP("192.168.0.3")) {
+			node = "node3"
+		}
+		rtt := replies[node][0]
+		replies[node] = replies[node][1:]
+		if rtt == 0 {
+			return 0, errors.New("timeout")
+		}
+		return rtt, nil
+	}, time.Second)
+
+	local := net.ParseIP("192.168.0.1")
+	remotes := map[string]net.IP{
+		"node2": net.ParseIP("192.168.0.2"),
+		"node3": net.ParseIP("192.168.0.3"),
+	}
+
+	p.Probe("node1", local, remotes)
+	if rtt, _ := p.SmoothedRTT("node2"); rtt != 10*time.Millisecond {
+		t.Fatalf("expected the first rtt taken as it is but got %v", rtt)
+	}
+	if _, exist := p.SmoothedRTT("node3"); exist {
+		t.Fatalf("expected no rtt of node without replies")
+	}
+
+	delete(remotes, "node3")
+	p.Probe("node1", local, remotes)
+	if rtt, _ := p.SmoothedRTT("node2"); rtt != 13*time.Millisecond {
+		t.Fatalf("expected smoothed rtt 13ms but got %v", rtt)
+	}
+
+	// a dropped probe keeps the smoothed rtt
+	p.Probe("node1", local, remotes)
+	if rtt, _ := p.SmoothedRTT("node2"); rtt != 13*time.Millisecond {
+		t.Fatalf("expected smoothed rtt kept after a dropped probe but got %v", rtt)
+	}
+
+	p.Probe("node1", local, map[string]net.IP{})
+	if _, exist := p.SmoothedRTT("node2"); exist {
+		t.Fatalf("expected rtt of removed node cleaned up")
+	}
+}
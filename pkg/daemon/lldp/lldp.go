@@ -0,0 +1,84 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package lldp
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mdlayher/ethernet"
+	"github.com/mdlayher/raw"
+)
+
+// protocolLLDP is the uint16 EtherType representation of LLDP (Link Layer
+// Discovery Protocol, IEEE 802.1AB).
+const protocolLLDP = 0x88cc
+
+// DefaultListenTimeout is a little longer than the default LLDP transmit
+// interval (30s) of most switches.
+const DefaultListenTimeout = 35 * time.Second
+
+// Listen waits for the first LLDP frame received on the interface and returns
+// the neighbor information in it, error will be returned if timeout.
+func Listen(ifi *net.Interface, timeout time.Duration) (*Neighbor, error) {
+	conn, err := raw.ListenPacket(ifi, protocolLLDP, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen LLDP on interface %v: %v", ifi.Name, err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// LLDP frames are sent to a multicast address which will be usually
+	// dropped by nic if not in promiscuous mode
+	if err := conn.SetPromiscuous(true); err != nil {
+		return nil, fmt.Errorf("failed to set promiscuous mode for interface %v: %v", ifi.Name, err)
+	}
+
+	defer func() {
+		_ = conn.SetPromiscuous(false)
+	}()
+
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, fmt.Errorf("failed to set read deadline: %v", err)
+	}
+
+	buf := make([]byte, ifi.MTU+14)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LLDP frame from interface %v: %v", ifi.Name, err)
+		}
+
+		frame := &ethernet.Frame{}
+		if err := frame.UnmarshalBinary(buf[:n]); err != nil {
+			continue
+		}
+
+		if frame.EtherType != protocolLLDP {
+			continue
+		}
+
+		neighbor, err := ParseLLDPDU(frame.Payload)
+		if err != nil {
+			continue
+		}
+		return neighbor, nil
+	}
+}
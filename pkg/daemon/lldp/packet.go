@@ -0,0 +1,133 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package lldp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// TLV types of LLDP (IEEE 802.1AB) which hybridnet cares about.
+const (
+	tlvTypeEnd             = 0
+	tlvTypeChassisID       = 1
+	tlvTypePortID          = 2
+	tlvTypeTTL             = 3
+	tlvTypePortDescription = 4
+	tlvTypeSystemName      = 5
+	tlvTypeOrgSpecific     = 127
+)
+
+// orgIEEE8021 is the OUI of IEEE 802.1 organizationally specific TLVs, and
+// subtype 1 of it carries the port VLAN ID.
+var orgIEEE8021 = [3]byte{0x00, 0x80, 0xc2}
+
+const orgIEEE8021SubtypePortVLANID = 1
+
+var (
+	errInvalidLLDPFrame = errors.New("invalid LLDP frame")
+	errMissingTLV       = errors.New("mandatory TLV missing")
+)
+
+// Neighbor is the information announced by the directly connected switch port.
+type Neighbor struct {
+	ChassisID       string
+	PortID          string
+	PortDescription string
+	SystemName      string
+	TTL             uint16
+
+	// PortVLANID will be zero if the switch does not announce it
+	PortVLANID uint16
+}
+
+// ParseLLDPDU parses the payload of an LLDP ethernet frame into a Neighbor.
+func ParseLLDPDU(b []byte) (*Neighbor, error) {
+	var (
+		neighbor     = &Neighbor{}
+		mandatoryTLV = 0
+	)
+
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return nil, errInvalidLLDPFrame
+		}
+
+		header := binary.BigEndian.Uint16(b[:2])
+		tlvType, tlvLength := int(header>>9), int(header&0x01ff)
+		if len(b) < 2+tlvLength {
+			return nil, errInvalidLLDPFrame
+		}
+		value := b[2 : 2+tlvLength]
+		b = b[2+tlvLength:]
+
+		switch tlvType {
+		case tlvTypeEnd:
+			if mandatoryTLV != 3 {
+				return nil, errMissingTLV
+			}
+			return neighbor, nil
+		case tlvTypeChassisID:
+			if len(value) < 2 {
+				return nil, errInvalidLLDPFrame
+			}
+			neighbor.ChassisID = formatID(value[0], value[1:], chassisIDSubtypeMACAddress)
+			mandatoryTLV++
+		case tlvTypePortID:
+			if len(value) < 2 {
+				return nil, errInvalidLLDPFrame
+			}
+			neighbor.PortID = formatID(value[0], value[1:], portIDSubtypeMACAddress)
+			mandatoryTLV++
+		case tlvTypeTTL:
+			if len(value) != 2 {
+				return nil, errInvalidLLDPFrame
+			}
+			neighbor.TTL = binary.BigEndian.Uint16(value)
+			mandatoryTLV++
+		case tlvTypePortDescription:
+			neighbor.PortDescription = string(value)
+		case tlvTypeSystemName:
+			neighbor.SystemName = string(value)
+		case tlvTypeOrgSpecific:
+			if len(value) == 6 && [3]byte{value[0], value[1], value[2]} == orgIEEE8021 &&
+				value[3] == orgIEEE8021SubtypePortVLANID {
+				neighbor.PortVLANID = binary.BigEndian.Uint16(value[4:6])
+			}
+		}
+	}
+
+	// End of LLDPDU TLV is missing, accept it if all mandatory TLVs are found
+	if mandatoryTLV != 3 {
+		return nil, errMissingTLV
+	}
+	return neighbor, nil
+}
+
+const (
+	chassisIDSubtypeMACAddress = 4
+	portIDSubtypeMACAddress    = 3
+)
+
+// formatID prints MAC address as usual and other ids as strings.
+func formatID(subtype byte, id []byte, macSubtype byte) string {
+	if subtype == macSubtype && len(id) == 6 {
+		return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", id[0], id[1], id[2], id[3], id[4], id[5])
+	}
+	return string(id)
+}
@@ -0,0 +1,110 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package lldp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func tlv(tlvType int, value ...byte) []byte {
+	header := uint16(tlvType)<<9 | uint16(len(value))
+	return append([]byte{byte(header >> 8), byte(header)}, value...)
+}
+
+func lldpdu(tlvs ...[]byte) (b []byte) {
+	for _, t := range tlvs {
+		b = append(b, t...)
+	}
+	return
+}
+
+func TestParseLLDPDU(t *testing.T) {
+	tests := []struct {
+		name     string
+		b        []byte
+		neighbor *Neighbor
+		err      error
+	}{
+		{
+			"full tlvs",
+			lldpdu(
+				tlv(tlvTypeChassisID, 4, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55),
+				tlv(tlvTypePortID, 5, 'G', 'E', '1', '/', '0', '/', '1'),
+				tlv(tlvTypeTTL, 0x00, 0x78),
+				tlv(tlvTypePortDescription, 'r', 'a', 'c', 'k', '-', '1'),
+				tlv(tlvTypeSystemName, 's', 'w', '1'),
+				tlv(tlvTypeOrgSpecific, 0x00, 0x80, 0xc2, 0x01, 0x00, 0x64),
+				tlv(tlvTypeEnd),
+			),
+			&Neighbor{
+				ChassisID:       "00:11:22:33:44:55",
+				PortID:          "GE1/0/1",
+				PortDescription: "rack-1",
+				SystemName:      "sw1",
+				TTL:             120,
+				PortVLANID:      100,
+			},
+			nil,
+		},
+		{
+			"without end tlv",
+			lldpdu(
+				tlv(tlvTypeChassisID, 7, 's', 'w'),
+				tlv(tlvTypePortID, 3, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55),
+				tlv(tlvTypeTTL, 0x00, 0x78),
+			),
+			&Neighbor{
+				ChassisID: "sw",
+				PortID:    "00:11:22:33:44:55",
+				TTL:       120,
+			},
+			nil,
+		},
+		{
+			"missing ttl tlv",
+			lldpdu(
+				tlv(tlvTypeChassisID, 7, 's', 'w'),
+				tlv(tlvTypePortID, 5, 'p'),
+				tlv(tlvTypeEnd),
+			),
+			nil,
+			errMissingTLV,
+		},
+		{
+			"truncated tlv",
+			lldpdu(
+				tlv(tlvTypeChassisID, 7, 's', 'w'),
+				[]byte{0x04, 0x10, 0x05},
+			),
+			nil,
+			errInvalidLLDPFrame,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			neighbor, err := ParseLLDPDU(test.b)
+			if err != test.err {
+				t.Fatalf("test %s fails: expected error %v but got %v", test.name, test.err, err)
+			}
+			if !reflect.DeepEqual(neighbor, test.neighbor) {
+				t.Fatalf("test %s fails: expected %+v but got %+v", test.name, test.neighbor, neighbor)
+			}
+		})
+	}
+}
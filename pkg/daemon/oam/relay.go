@@ -0,0 +1,185 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package oam
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/mdlayher/ethernet"
+	"github.com/mdlayher/raw"
+)
+
+const (
+	// protocolSlow is the uint16 EtherType representation of slow protocols (IEEE 802.3 Annex 57A),
+	// which carries LACP, marker and OAM (IEEE 802.3ah) frames.
+	protocolSlow = 0x8809
+
+	// subtypeOAM is the slow protocol subtype of OAM frames, the first byte of payload
+	subtypeOAM = 0x03
+)
+
+// Relay intercepts OAM frames on interfaces and delivers them to an OAM agent over udp. Every
+// frame is sent to agent in a single datagram, which starts with one byte of the interface name
+// length, followed by the interface name and the whole ethernet frame.
+type Relay struct {
+	agentAddress string
+	logger       logr.Logger
+
+	mu        sync.Mutex
+	agent     net.Conn
+	listeners map[string]*raw.Conn
+}
+
+func NewRelay(agentAddress string, logger logr.Logger) *Relay {
+	return &Relay{
+		agentAddress: agentAddress,
+		logger:       logger,
+		listeners:    map[string]*raw.Conn{},
+	}
+}
+
+// Sync makes relay intercept OAM frames on exactly the interfaces, listeners on other
+// interfaces are stopped.
+func (r *Relay) Sync(ifNames []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.agent == nil {
+		agent, err := net.Dial("udp", r.agentAddress)
+		if err != nil {
+			return fmt.Errorf("failed to dial oam agent %v: %v", r.agentAddress, err)
+		}
+		r.agent = agent
+	}
+
+	expected := map[string]bool{}
+	var errs []error
+	for _, ifName := range ifNames {
+		expected[ifName] = true
+		if _, exist := r.listeners[ifName]; exist {
+			continue
+		}
+
+		conn, err := listen(ifName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.listeners[ifName] = conn
+		go r.relayFrames(ifName, conn)
+	}
+
+	for ifName, conn := range r.listeners {
+		if !expected[ifName] {
+			stop(conn)
+			delete(r.listeners, ifName)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to intercept oam frames on some interfaces: %v", errs)
+	}
+	return nil
+}
+
+// Stop stops all the listeners and the connection to agent.
+func (r *Relay) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ifName, conn := range r.listeners {
+		stop(conn)
+		delete(r.listeners, ifName)
+	}
+
+	if r.agent != nil {
+		_ = r.agent.Close()
+		r.agent = nil
+	}
+}
+
+func listen(ifName string) (*raw.Conn, error) {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface %v: %v", ifName, err)
+	}
+
+	conn, err := raw.ListenPacket(ifi, protocolSlow, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen oam frames on interface %v: %v", ifName, err)
+	}
+
+	// slow protocol frames are sent to a multicast address which will be usually
+	// dropped by nic if not in promiscuous mode
+	if err := conn.SetPromiscuous(true); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to set promiscuous mode for interface %v: %v", ifName, err)
+	}
+
+	return conn, nil
+}
+
+func stop(conn *raw.Conn) {
+	_ = conn.SetPromiscuous(false)
+	_ = conn.Close()
+}
+
+func (r *Relay) relayFrames(ifName string, conn *raw.Conn) {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			// connection is closed once the interface is not supposed to be intercepted
+			r.logger.V(5).Info("stop intercepting oam frames", "interface", ifName, "reason", err.Error())
+			return
+		}
+
+		if !isOAMFrame(buf[:n]) {
+			continue
+		}
+
+		r.mu.Lock()
+		agent := r.agent
+		r.mu.Unlock()
+		if agent == nil {
+			return
+		}
+
+		if _, err := agent.Write(encodeFrame(ifName, buf[:n])); err != nil {
+			r.logger.Error(err, "failed to relay oam frame to agent", "interface", ifName, "agent", r.agentAddress)
+		}
+	}
+}
+
+// isOAMFrame filters out the other slow protocols sharing the EtherType with OAM
+func isOAMFrame(b []byte) bool {
+	frame := &ethernet.Frame{}
+	if err := frame.UnmarshalBinary(b); err != nil {
+		return false
+	}
+	return frame.EtherType == protocolSlow && len(frame.Payload) > 0 && frame.Payload[0] == subtypeOAM
+}
+
+func encodeFrame(ifName string, frame []byte) []byte {
+	b := make([]byte, 0, 1+len(ifName)+len(frame))
+	b = append(b, byte(len(ifName)))
+	b = append(b, ifName...)
+	return append(b, frame...)
+}
@@ -0,0 +1,83 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package oam
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/mdlayher/ethernet"
+)
+
+func marshalFrame(t *testing.T, etherType ethernet.EtherType, payload []byte) []byte {
+	frame := &ethernet.Frame{
+		Destination: net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x02},
+		Source:      net.HardwareAddr{0xee, 0xee, 0xee, 0xee, 0xee, 0xee},
+		EtherType:   etherType,
+		Payload:     payload,
+	}
+	b, err := frame.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestIsOAMFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		frame    []byte
+		expected bool
+	}{
+		{
+			name:     "oam",
+			frame:    marshalFrame(t, protocolSlow, []byte{subtypeOAM, 0x00, 0x50}),
+			expected: true,
+		},
+		{
+			name:     "lacp",
+			frame:    marshalFrame(t, protocolSlow, []byte{0x01, 0x01}),
+			expected: false,
+		},
+		{
+			name:     "ipv4",
+			frame:    marshalFrame(t, ethernet.EtherTypeIPv4, []byte{subtypeOAM}),
+			expected: false,
+		},
+		{
+			name:     "truncated",
+			frame:    []byte{0x01, 0x80},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		if got := isOAMFrame(test.frame); got != test.expected {
+			t.Errorf("test %v: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	frame := marshalFrame(t, protocolSlow, []byte{subtypeOAM})
+	b := encodeFrame("hybr1234", frame)
+
+	if int(b[0]) != len("hybr1234") || string(b[1:9]) != "hybr1234" || !bytes.Equal(b[9:], frame) {
+		t.Errorf("unexpected encoded frame %v", b)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pmtu
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// probeMagic identifies probe and reply packets, "HNMP" in ascii
+	probeMagic uint32 = 0x484e4d50
+
+	probeHeaderLength = 8
+	replyLength       = 12
+
+	ipv4HeaderLength = 20
+	ipv6HeaderLength = 40
+	udpHeaderLength  = 8
+
+	// probeAttempts is the number of probes sent for a size before it is considered not working,
+	// so that a single packet loss will not be mistaken for a black hole
+	probeAttempts = 2
+)
+
+var errProbeTimeout = errors.New("probe timeout")
+
+// Probe finds the largest ip packet size in range [low, high] which reaches the responder on
+// target port with DF bit set. Sizes are increased by step until packets get lost, and then the
+// last gap is narrowed down by binary search. An error is returned if even the packets of low
+// size can not reach target.
+func Probe(target net.IP, port, low, high, step int, timeout time.Duration) (int, error) {
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: target, Port: port})
+	if err != nil {
+		return 0, fmt.Errorf("failed to dial udp %v: %v", target, err)
+	}
+	defer conn.Close()
+
+	if err = setDontFragment(conn, target.To4() == nil); err != nil {
+		return 0, err
+	}
+
+	headerLength := ipv4HeaderLength + udpHeaderLength
+	if target.To4() == nil {
+		headerLength = ipv6HeaderLength + udpHeaderLength
+	}
+	if low < headerLength+probeHeaderLength {
+		low = headerLength + probeHeaderLength
+	}
+	if high < low {
+		high = low
+	}
+	if step <= 0 {
+		step = high - low + 1
+	}
+
+	var seq uint32
+	works := func(size int) bool {
+		for i := 0; i < probeAttempts; i++ {
+			seq++
+			if err := sendProbe(conn, size-headerLength, seq, timeout); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !works(low) {
+		return 0, fmt.Errorf("packets of %v bytes do not reach %v", low, target)
+	}
+
+	// increase sizes until the first failure
+	failed := high + 1
+	for low < high {
+		size := low + step
+		if size > high {
+			size = high
+		}
+		if !works(size) {
+			failed = size
+			break
+		}
+		low = size
+	}
+
+	// narrow down the gap between the last working size and the first failed one
+	for high = failed - 1; low < high; {
+		mid := (low + high + 1) / 2
+		if works(mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, nil
+}
+
+// sendProbe sends a probe packet with payload of length and waits for the matched reply.
+func sendProbe(conn *net.UDPConn, length int, seq uint32, timeout time.Duration) error {
+	probe := make([]byte, length)
+	binary.BigEndian.PutUint32(probe[0:4], probeMagic)
+	binary.BigEndian.PutUint32(probe[4:8], seq)
+
+	// EMSGSIZE is returned for packets larger than the mtu of egress interface
+	if _, err := conn.Write(probe); err != nil {
+		return err
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, replyLength)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return errProbeTimeout
+			}
+			return err
+		}
+
+		if n != replyLength || binary.BigEndian.Uint32(buf[0:4]) != probeMagic {
+			continue
+		}
+		// replies of former probes may arrive late
+		if binary.BigEndian.Uint32(buf[4:8]) == seq && int(binary.BigEndian.Uint32(buf[8:12])) == length {
+			return nil
+		}
+	}
+}
+
+// setDontFragment sets DF bit on all packets and ignores the path mtu cached by kernel,
+// so that oversized packets will be dropped on path instead of being fragmented.
+func setDontFragment(conn *net.UDPConn, ipv6 bool) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err = rawConn.Control(func(fd uintptr) {
+		if ipv6 {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_MTU_DISCOVER, unix.IPV6_PMTUDISC_PROBE)
+		} else {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_MTU_DISCOVER, unix.IP_PMTUDISC_PROBE)
+		}
+	}); err != nil {
+		return err
+	}
+	if sockErr != nil {
+		return fmt.Errorf("failed to set DF bit: %v", sockErr)
+	}
+	return nil
+}
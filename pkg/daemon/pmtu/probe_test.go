@@ -0,0 +1,80 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pmtu
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+func TestProbe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// pick a free port for responder
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen udp: %v", err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	_ = conn.Close()
+
+	if err = NewResponder(port, logr.Discard()).Start(ctx); err != nil {
+		t.Fatalf("failed to start responder: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		low, high int
+		step      int
+	}{
+		{"full range", 576, 1500, 100},
+		{"single size", 1400, 1400, 100},
+		{"no step", 576, 9000, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			size, err := Probe(net.IPv4(127, 0, 0, 1), port, test.low, test.high, test.step, time.Second)
+			if err != nil {
+				t.Fatalf("failed to probe: %v", err)
+			}
+			// loopback mtu is much larger than any tested size
+			if size != test.high {
+				t.Fatalf("unexpected probed size %v, expected %v", size, test.high)
+			}
+		})
+	}
+}
+
+func TestProbeNoResponder(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("failed to listen udp: %v", err)
+	}
+	defer conn.Close()
+
+	// a listener which never replies
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	if _, err = Probe(net.IPv4(127, 0, 0, 1), port, 576, 1500, 100, 100*time.Millisecond); err == nil {
+		t.Fatalf("expect error without responder")
+	}
+}
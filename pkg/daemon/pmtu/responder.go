@@ -0,0 +1,83 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pmtu
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/go-logr/logr"
+)
+
+// Responder answers the probes from other nodes. Only a small reply carrying the received
+// length is sent back for every probe, so that only the forward path is measured and the
+// responder can not be used for traffic amplification.
+type Responder struct {
+	port   int
+	logger logr.Logger
+}
+
+func NewResponder(port int, logger logr.Logger) *Responder {
+	return &Responder{
+		port:   port,
+		logger: logger,
+	}
+}
+
+// Start listens on the port and answers probes in background until ctx is done.
+func (r *Responder) Start(ctx context.Context) error {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: r.port})
+	if err != nil {
+		return fmt.Errorf("failed to listen udp port %v: %v", r.port, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	go func() {
+		// buffer should be larger than any possible mtu
+		buf := make([]byte, 65536)
+		reply := make([]byte, replyLength)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				r.logger.Error(err, "failed to read mtu probe")
+				continue
+			}
+
+			if n < probeHeaderLength || binary.BigEndian.Uint32(buf[0:4]) != probeMagic {
+				continue
+			}
+
+			binary.BigEndian.PutUint32(reply[0:4], probeMagic)
+			copy(reply[4:8], buf[4:8])
+			binary.BigEndian.PutUint32(reply[8:12], uint32(n))
+			if _, err = conn.WriteToUDP(reply, from); err != nil {
+				r.logger.Error(err, "failed to reply mtu probe", "from", from.String())
+			}
+		}
+	}()
+
+	return nil
+}
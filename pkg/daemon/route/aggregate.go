@@ -0,0 +1,134 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package route
+
+import (
+	"net"
+	"sort"
+)
+
+// aggregatedPrefix is a summarized prefix which covers one or more original prefixes exactly
+type aggregatedPrefix struct {
+	cidr *net.IPNet
+	// count is the number of original prefixes summarized by cidr
+	count int
+	// metric is the priority of route to cidr
+	metric int
+	// mtu is the mtu of route to cidr, 0 means the mtu of device
+	mtu int
+}
+
+// prefixTrieNode is a node of binary radix tree, the depth of node is the prefix length
+type prefixTrieNode struct {
+	children [2]*prefixTrieNode
+	// covered means the whole prefix of this node is covered by original prefixes
+	covered bool
+	count   int
+}
+
+// compact merges covered sibling nodes into their parent bottom-up, and returns
+// the number of original prefixes in the subtree
+func (n *prefixTrieNode) compact() int {
+	total := n.count
+	for _, child := range n.children {
+		if child != nil {
+			total += child.compact()
+		}
+	}
+
+	if n.covered || (n.children[0] != nil && n.children[0].covered &&
+		n.children[1] != nil && n.children[1].covered) {
+		n.covered = true
+		n.count = total
+		n.children = [2]*prefixTrieNode{}
+	}
+	return total
+}
+
+func (n *prefixTrieNode) collect(ip net.IP, depth int, result []*aggregatedPrefix) []*aggregatedPrefix {
+	if n.covered {
+		prefixIP := make(net.IP, len(ip))
+		copy(prefixIP, ip)
+		return append(result, &aggregatedPrefix{
+			cidr: &net.IPNet{
+				IP:   prefixIP,
+				Mask: net.CIDRMask(depth, len(ip)*8),
+			},
+			count: n.count,
+		})
+	}
+
+	for bit, child := range n.children {
+		if child == nil {
+			continue
+		}
+		if bit == 1 {
+			ip[depth/8] |= 1 << (7 - depth%8)
+		}
+		result = child.collect(ip, depth+1, result)
+		ip[depth/8] &^= 1 << (7 - depth%8)
+	}
+	return result
+}
+
+// aggregatePrefixes summarizes prefixes into the minimum set of prefixes covering exactly the
+// same addresses, with a radix tree, contiguous prefixes like 10.0.0.0/32 and 10.0.0.1/32 will
+// be aggregated into 10.0.0.0/31 and prefixes covered by others will be dropped
+func aggregatePrefixes(cidrs []*net.IPNet) []*aggregatedPrefix {
+	var roots = map[int]*prefixTrieNode{}
+
+	for _, cidr := range cidrs {
+		ip := cidr.IP.To4()
+		if ip == nil {
+			ip = cidr.IP.To16()
+		}
+		if ip == nil {
+			continue
+		}
+
+		ones, bits := cidr.Mask.Size()
+		if bits != len(ip)*8 {
+			continue
+		}
+
+		if roots[len(ip)] == nil {
+			roots[len(ip)] = &prefixTrieNode{}
+		}
+
+		node := roots[len(ip)]
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - i%8)) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &prefixTrieNode{}
+			}
+			node = node.children[bit]
+		}
+		node.covered = true
+		node.count++
+	}
+
+	var result []*aggregatedPrefix
+	for length, root := range roots {
+		root.compact()
+		result = root.collect(make(net.IP, length), 0, result)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].cidr.String() < result[j].cidr.String()
+	})
+	return result
+}
@@ -0,0 +1,91 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package route
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAggregatePrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidrs    []string
+		expected map[string]int
+	}{
+		{
+			name:  "contiguous host routes",
+			cidrs: []string{"10.0.0.0/32", "10.0.0.1/32", "10.0.0.2/32", "10.0.0.3/32"},
+			expected: map[string]int{
+				"10.0.0.0/30": 4,
+			},
+		},
+		{
+			name:  "partially contiguous host routes",
+			cidrs: []string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.3/32", "10.0.0.5/32"},
+			expected: map[string]int{
+				"10.0.0.1/32": 1,
+				"10.0.0.2/31": 2,
+				"10.0.0.5/32": 1,
+			},
+		},
+		{
+			name:  "non-sibling host routes",
+			cidrs: []string{"10.0.0.1/32", "10.0.0.2/32"},
+			expected: map[string]int{
+				"10.0.0.1/32": 1,
+				"10.0.0.2/32": 1,
+			},
+		},
+		{
+			name:  "covered prefixes",
+			cidrs: []string{"10.0.0.0/24", "10.0.0.7/32", "10.0.1.0/24"},
+			expected: map[string]int{
+				"10.0.0.0/23": 3,
+			},
+		},
+		{
+			name:  "ipv6 host routes",
+			cidrs: []string{"fe80::/128", "fe80::1/128", "fe80::3/128"},
+			expected: map[string]int{
+				"fe80::/127":  2,
+				"fe80::3/128": 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cidrs []*net.IPNet
+			for _, cidrString := range test.cidrs {
+				_, cidr, _ := net.ParseCIDR(cidrString)
+				cidrs = append(cidrs, cidr)
+			}
+
+			result := aggregatePrefixes(cidrs)
+			if len(result) != len(test.expected) {
+				t.Errorf("test %s fails: expected %d prefixes but got %v", test.name, len(test.expected), result)
+				return
+			}
+			for _, prefix := range result {
+				if count, exist := test.expected[prefix.cidr.String()]; !exist || count != prefix.count {
+					t.Errorf("test %s fails: unexpected prefix %s with count %d", test.name, prefix.cidr.String(), prefix.count)
+				}
+			}
+		})
+	}
+}
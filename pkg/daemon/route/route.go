@@ -19,11 +19,13 @@ package route
 import (
 	"fmt"
 	"net"
+	"sort"
 
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 
 	"github.com/alibaba/hybridnet/pkg/daemon/iptables"
 	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+	"github.com/alibaba/hybridnet/pkg/metrics"
 	"github.com/vishvananda/netlink"
 )
 
@@ -39,6 +41,8 @@ import (
 //    |                v (followed with)
 //    |         overlay-mark rule
 //    |                v (followed with)
+//    |  from-local-vtep cluster-route-table rules (if any)
+//    |                v (followed with)
 //    |     from-every-pod-subnet rules
 //    |                ...
 //    |     from-every-pod-subnet rules
@@ -73,9 +77,24 @@ type Manager struct {
 	// add cluster-mesh remote subnet info
 	remoteOverlaySubnetInfoMap  SubnetInfoMap
 	remoteUnderlaySubnetInfoMap SubnetInfoMap
+
+	// dedicated route tables of remote clusters, remote overlay subnets of these clusters are
+	// recorded per cluster, so that overlapped cidrs of different clusters do not conflict
+	clusterRouteTables           map[string]int
+	clusterOverlaySubnetInfoMaps map[string]SubnetInfoMap
+
+	// vtep address of this node, dedicated route tables of remote clusters are selected by rules from it
+	localVtepIP net.IP
+
+	// aggregate remote overlay subnet routes into summarized prefixes
+	enableRemoteRouteCompression bool
+
+	// table of the host VRF which pod nics and vxlan device are attached to, 0 means no VRF
+	vrfTableNum int
 }
 
-func CreateRouteManager(localDirectTableNum, toOverlaySubnetTableNum, overlayMarkTableNum, family int) (*Manager, error) {
+func CreateRouteManager(localDirectTableNum, toOverlaySubnetTableNum, overlayMarkTableNum, family int,
+	enableRemoteRouteCompression bool, clusterRouteTables map[string]int) (*Manager, error) {
 	// Check if route tables are being used by others.
 	if empty, err := checkIfRouteTableEmpty(localDirectTableNum, family); err != nil {
 		return nil, fmt.Errorf("failed to check table %v empty: %v", localDirectTableNum, err)
@@ -108,34 +127,15 @@ func CreateRouteManager(localDirectTableNum, toOverlaySubnetTableNum, overlayMar
 		}
 	}
 
-	if empty, err := checkIfRouteTableEmpty(toOverlaySubnetTableNum, family); err != nil {
-		return nil, fmt.Errorf("failed to check table %v empty: %v", toOverlaySubnetTableNum, err)
-	} else if !empty {
-		routes, err := listRoutesByTable(toOverlaySubnetTableNum, family)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list routes for to overlay subnet route table %v: %v", toOverlaySubnetTableNum, err)
-		}
-
-		for _, route := range routes {
-			if route.LinkIndex <= 0 && !isExcludeRoute(&route) {
-				return nil, fmt.Errorf("find no device route, to overlay subnet route table %v is used by others", toOverlaySubnetTableNum)
-			}
-
-			if route.LinkIndex > 0 {
-				routeIf, err := netlink.LinkByIndex(route.LinkIndex)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get route interface by index %v: %v", route.LinkIndex, err)
-				}
-
-				// "throw" v6 routes without a specified device will always be specified to "dev lo" by kernel automatically
-				if routeIf.Attrs().Flags&net.FlagLoopback != 0 && isExcludeRoute(&route) {
-					continue
-				}
+	if err := checkIfToOverlayRouteTableUsedByOthers(toOverlaySubnetTableNum, family, "to overlay subnet"); err != nil {
+		return nil, err
+	}
 
-				if route.Gw != nil || routeIf.Type() != "vxlan" {
-					return nil, fmt.Errorf("to overlay subnet route table %v is used by others", toOverlaySubnetTableNum)
-				}
-			}
+	// dedicated route tables of remote clusters only contain routes to remote overlay subnets
+	// like the to-overlay-pod-subnet table
+	for clusterName, table := range clusterRouteTables {
+		if err := checkIfToOverlayRouteTableUsedByOthers(table, family, "cluster "+clusterName); err != nil {
+			return nil, err
 		}
 	}
 
@@ -175,6 +175,9 @@ func CreateRouteManager(localDirectTableNum, toOverlaySubnetTableNum, overlayMar
 		localClusterUnderlaySubnetInfoMap: SubnetInfoMap{},
 		remoteOverlaySubnetInfoMap:        SubnetInfoMap{},
 		remoteUnderlaySubnetInfoMap:       SubnetInfoMap{},
+		clusterRouteTables:                clusterRouteTables,
+		clusterOverlaySubnetInfoMaps:      map[string]SubnetInfoMap{},
+		enableRemoteRouteCompression:      enableRemoteRouteCompression,
 	}, nil
 }
 
@@ -184,10 +187,26 @@ func (m *Manager) ResetInfos() {
 	m.localClusterOverlaySubnetInfoMap = SubnetInfoMap{}
 	m.remoteOverlaySubnetInfoMap = SubnetInfoMap{}
 	m.remoteUnderlaySubnetInfoMap = SubnetInfoMap{}
+	m.clusterOverlaySubnetInfoMaps = map[string]SubnetInfoMap{}
+	m.localVtepIP = nil
+}
+
+// SetLocalVtepIP records the vtep address of this node, addresses of the other family are ignored
+func (m *Manager) SetLocalVtepIP(ip net.IP) {
+	if ip == nil || (ip.To4() != nil) != (m.family == netlink.FAMILY_V4) {
+		return
+	}
+	m.localVtepIP = ip
+}
+
+// SetVRFTable records the table of host VRF, routes to subnets will also be installed into it
+func (m *Manager) SetVRFTable(table int) {
+	m.vrfTableNum = table
 }
 
 func (m *Manager) AddSubnetInfo(cidr *net.IPNet, gateway, start, end net.IP, excludeIPs []net.IP,
-	forwardNodeIfName string, autoNatOutgoing, isOverlay, isUnderlayOnHost bool, mode networkingv1.NetworkMode) {
+	forwardNodeIfName string, autoNatOutgoing, isOverlay, isUnderlayOnHost bool, mode networkingv1.NetworkMode,
+	podRoutes []*PodRoute) {
 
 	cidrString := cidr.String()
 
@@ -210,6 +229,10 @@ func (m *Manager) AddSubnetInfo(cidr *net.IPNet, gateway, start, end net.IP, exc
 		subnetInfo.excludeIPs = append(subnetInfo.excludeIPs, excludeIPs...)
 	}
 
+	if len(podRoutes) != 0 {
+		subnetInfo.podRoutes = append(subnetInfo.podRoutes, podRoutes...)
+	}
+
 	if start != nil || end != nil {
 		if start == nil {
 			start = cidr.IP
@@ -233,13 +256,22 @@ func (m *Manager) AddSubnetInfo(cidr *net.IPNet, gateway, start, end net.IP, exc
 	}
 }
 
-func (m *Manager) AddRemoteSubnetInfo(cidr *net.IPNet, gateway, start, end net.IP, excludeIPs []net.IP, isOverlay bool) error {
+func (m *Manager) AddRemoteSubnetInfo(cidr *net.IPNet, gateway, start, end net.IP, excludeIPs []net.IP, isOverlay bool,
+	clusterName string, routeMetric, routeMTU int) error {
 	cidrString := cidr.String()
 
 	var subnetInfo *SubnetInfo
 	if isOverlay {
-		if _, exists := m.remoteOverlaySubnetInfoMap[cidrString]; !exists {
-			m.remoteOverlaySubnetInfoMap[cidrString] = &SubnetInfo{
+		overlaySubnetInfoMap := m.remoteOverlaySubnetInfoMap
+		if _, dedicated := m.clusterRouteTables[clusterName]; dedicated {
+			if _, exists := m.clusterOverlaySubnetInfoMaps[clusterName]; !exists {
+				m.clusterOverlaySubnetInfoMaps[clusterName] = SubnetInfoMap{}
+			}
+			overlaySubnetInfoMap = m.clusterOverlaySubnetInfoMaps[clusterName]
+		}
+
+		if _, exists := overlaySubnetInfoMap[cidrString]; !exists {
+			overlaySubnetInfoMap[cidrString] = &SubnetInfo{
 				cidr:             cidr,
 				gateway:          gateway,
 				includedIPRanges: []*daemonutils.IPRange{},
@@ -247,7 +279,7 @@ func (m *Manager) AddRemoteSubnetInfo(cidr *net.IPNet, gateway, start, end net.I
 			}
 		}
 
-		subnetInfo = m.remoteOverlaySubnetInfoMap[cidrString]
+		subnetInfo = overlaySubnetInfoMap[cidrString]
 	} else {
 		if _, exists := m.remoteUnderlaySubnetInfoMap[cidrString]; !exists {
 			m.remoteUnderlaySubnetInfoMap[cidrString] = &SubnetInfo{
@@ -261,6 +293,9 @@ func (m *Manager) AddRemoteSubnetInfo(cidr *net.IPNet, gateway, start, end net.I
 		subnetInfo = m.remoteUnderlaySubnetInfoMap[cidrString]
 	}
 
+	subnetInfo.routeMetric = routeMetric
+	subnetInfo.routeMTU = routeMTU
+
 	if len(excludeIPs) != 0 {
 		subnetInfo.excludeIPs = append(subnetInfo.excludeIPs, excludeIPs...)
 	}
@@ -297,6 +332,23 @@ func (m *Manager) SyncRoutes() error {
 		return fmt.Errorf("failed to append overlay-mark rule: %v", err)
 	}
 
+	// Rules and tables of removed clusters or a stale vtep address are cleaned up first
+	if err := m.cleanStaleClusterRules(); err != nil {
+		return fmt.Errorf("failed to clean stale cluster route table rules: %v", err)
+	}
+
+	// Rules of cluster route tables are selected by the vtep address of this node, and appended
+	// in order of table numbers, so the route to an overlapped cidr of the cluster with the smallest
+	// table number takes effect.
+	if vtepNet := m.localVtepNet(); vtepNet != nil {
+		for _, table := range m.sortedClusterRouteTables() {
+			if err := appendHighestUnusedPriorityRuleIfNotExist(vtepNet, table, m.family,
+				fromRuleMark, fromRuleMask); err != nil {
+				return fmt.Errorf("failed to append cluster route table %v rule: %v", table, err)
+			}
+		}
+	}
+
 	// Find excluded ip ranges.
 	// TODO: if CIDRs are different but overlapped, exclude IP blocks might be conflicted
 	localUnderlayExcludeIPBlockMap, err := findExcludeIPBlockMap(m.localClusterUnderlaySubnetInfoMap)
@@ -322,6 +374,13 @@ func (m *Manager) SyncRoutes() error {
 		return fmt.Errorf("failed to ensure to-overlay-pod-subnet routes: %v", err)
 	}
 
+	// Sync routes of remote clusters with dedicated tables
+	for clusterName, table := range m.clusterRouteTables {
+		if err := m.ensureClusterOverlaySubnetRoutes(table, m.clusterOverlaySubnetInfoMaps[clusterName]); err != nil {
+			return fmt.Errorf("failed to ensure routes of cluster %v: %v", clusterName, err)
+		}
+	}
+
 	// Ensure overlay-mark table rule if overlay interface exist.
 	if err := m.ensureOverlayMarkRoutes(); err != nil {
 		return fmt.Errorf("failed to ensure overlay-mark routes: %v", err)
@@ -371,7 +430,7 @@ func (m *Manager) SyncRoutes() error {
 		if err := ensureFromPodSubnetRuleAndRoutes(info.forwardNodeIfName, info.cidr, info.gateway, info.autoNatOutgoing, m.family,
 			combineSubnetInfoMap(m.localClusterUnderlaySubnetInfoMap, m.remoteUnderlaySubnetInfoMap),
 			combineNetMap(localUnderlayExcludeIPBlockMap, remoteUnderlayExcludeIPBlockMap),
-			info.mode,
+			info.mode, nil,
 		); err != nil {
 			return fmt.Errorf("failed to add overlay subnet %v rule and routes: %v", info.cidr, err)
 		}
@@ -385,12 +444,19 @@ func (m *Manager) SyncRoutes() error {
 
 		// Append underlay from-pod-subnet rules which don't exist and adapt to subnet configuration
 		if err := ensureFromPodSubnetRuleAndRoutes(info.forwardNodeIfName, info.cidr,
-			info.gateway, info.autoNatOutgoing, m.family, nil, nil, info.mode,
+			info.gateway, info.autoNatOutgoing, m.family, nil, nil, info.mode, info.podRoutes,
 		); err != nil {
 			return fmt.Errorf("failed to add underlay subnet %v rule and routes: %v", info.cidr, err)
 		}
 	}
 
+	// Traffic from pods attached to host VRF is looked up in table of the VRF rather than the tables above
+	if m.vrfTableNum != 0 {
+		if err := m.ensureVRFRoutes(); err != nil {
+			return fmt.Errorf("failed to ensure routes of vrf table %v: %v", m.vrfTableNum, err)
+		}
+	}
+
 	return nil
 }
 
@@ -404,6 +470,8 @@ func (m *Manager) ensureToOverlaySubnetRoutes(excludeIPBlockMap map[string]*net.
 	existOverlaySubnetRouteMap := map[string]bool{}
 	existRemoteOverlaySubnetRouteMap := map[string]bool{}
 
+	remoteOverlayRouteMap := m.remoteOverlaySubnetRouteMap(m.remoteOverlaySubnetInfoMap)
+
 	for _, route := range toOverlaySubnetRoutes {
 		// skip exclude routes
 		if isExcludeRoute(&route) {
@@ -412,7 +480,8 @@ func (m *Manager) ensureToOverlaySubnetRoutes(excludeIPBlockMap map[string]*net.
 
 		if _, exist := m.localClusterOverlaySubnetInfoMap[route.Dst.String()]; exist {
 			existOverlaySubnetRouteMap[route.Dst.String()] = true
-		} else if _, exist := m.remoteOverlaySubnetInfoMap[route.Dst.String()]; exist {
+		} else if prefix, exist := remoteOverlayRouteMap[route.Dst.String()]; exist &&
+			routeMetricMatches(&route, prefix.metric, m.family) && route.MTU == prefix.mtu {
 			existRemoteOverlaySubnetRouteMap[route.Dst.String()] = true
 		} else if err := netlink.RouteDel(&route); err != nil {
 			return fmt.Errorf("failed to delete route %v: %v", route.String(), err)
@@ -438,31 +507,243 @@ func (m *Manager) ensureToOverlaySubnetRoutes(excludeIPBlockMap map[string]*net.
 	}
 
 	// add route for remote overlay subnets
-	for _, info := range m.remoteOverlaySubnetInfoMap {
-		if _, exist := existRemoteOverlaySubnetRouteMap[info.cidr.String()]; !exist {
+	if err := m.addRemoteOverlaySubnetRoutes(m.toOverlaySubnetTableNum, remoteOverlayRouteMap,
+		existRemoteOverlaySubnetRouteMap); err != nil {
+		return err
+	}
+
+	// For the traffic of accessing overlay excluded ip addresses, should not be forced to pass through vxlan device.
+	if err := ensureExcludedIPBlockRoutes(excludeIPBlockMap, m.toOverlaySubnetTableNum, m.family); err != nil {
+		return fmt.Errorf("failed to ensure exclude ip block routes: %v", err)
+	}
+	return nil
+}
+
+// ensureClusterOverlaySubnetRoutes makes the dedicated route table of a remote cluster only contain the
+// routes to its remote overlay subnets
+func (m *Manager) ensureClusterOverlaySubnetRoutes(table int, infoMap SubnetInfoMap) error {
+	excludeIPBlockMap, err := findExcludeIPBlockMap(infoMap)
+	if err != nil {
+		return fmt.Errorf("failed to find exclude ip blocks for overlay subnet: %v", err)
+	}
+
+	routes, err := listRoutesByTable(table, m.family)
+	if err != nil {
+		return fmt.Errorf("failed to list cluster routes for table %v: %v", table, err)
+	}
+
+	existRouteMap := map[string]bool{}
+	routeMap := m.remoteOverlaySubnetRouteMap(infoMap)
+
+	for _, route := range routes {
+		// skip exclude routes
+		if isExcludeRoute(&route) {
+			continue
+		}
+
+		if prefix, exist := routeMap[route.Dst.String()]; exist &&
+			routeMetricMatches(&route, prefix.metric, m.family) && route.MTU == prefix.mtu {
+			existRouteMap[route.Dst.String()] = true
+		} else if err := netlink.RouteDel(&route); err != nil {
+			return fmt.Errorf("failed to delete route %v: %v", route.String(), err)
+		}
+	}
+
+	if err := m.addRemoteOverlaySubnetRoutes(table, routeMap, existRouteMap); err != nil {
+		return err
+	}
+
+	// For the traffic of accessing overlay excluded ip addresses, should not be forced to pass through vxlan device.
+	if err := ensureExcludedIPBlockRoutes(excludeIPBlockMap, table, m.family); err != nil {
+		return fmt.Errorf("failed to ensure exclude ip block routes: %v", err)
+	}
+	return nil
+}
+
+// addRemoteOverlaySubnetRoutes adds the routes to remote overlay subnets which do not exist in table
+func (m *Manager) addRemoteOverlaySubnetRoutes(table int, routeMap map[string]*aggregatedPrefix,
+	existRouteMap map[string]bool) error {
+	for _, prefix := range routeMap {
+		if _, exist := existRouteMap[prefix.cidr.String()]; !exist {
 			overlayLink, err := netlink.LinkByName(m.overlayIfName)
 			if err != nil {
 				return fmt.Errorf("failed to get overlay link %v: %v", m.overlayIfName, err)
 			}
 
 			if err := netlink.RouteReplace(&netlink.Route{
-				Dst:       info.cidr,
+				Dst:       prefix.cidr,
 				LinkIndex: overlayLink.Attrs().Index,
-				Table:     m.toOverlaySubnetTableNum,
+				Table:     table,
 				Scope:     netlink.SCOPE_UNIVERSE,
+				Priority:  prefix.metric,
+				MTU:       prefix.mtu,
 			}); err != nil {
-				return fmt.Errorf("failed to add to remote overlay pod subnet route for %v: %v", info.cidr.String(), err)
+				return fmt.Errorf("failed to add to remote overlay pod subnet route for %v: %v", prefix.cidr.String(), err)
 			}
+
+			metrics.RemoteRoutesCompressedCounter.Add(float64(prefix.count - 1))
 		}
 	}
+	return nil
+}
 
-	// For the traffic of accessing overlay excluded ip addresses, should not be forced to pass through vxlan device.
-	if err := ensureExcludedIPBlockRoutes(excludeIPBlockMap, m.toOverlaySubnetTableNum, m.family); err != nil {
-		return fmt.Errorf("failed to ensure exclude ip block routes: %v", err)
+// ensureVRFRoutes makes the routes to subnets in table of host VRF up to date, only the routes installed
+// by hybridnet are maintained because the table is shared with others, e.g., routes of local pods
+func (m *Manager) ensureVRFRoutes() error {
+	expectedRoutes := m.vrfRoutes()
+
+	routes, err := listRoutesByTable(m.vrfTableNum, m.family)
+	if err != nil {
+		return fmt.Errorf("failed to list routes for vrf table %v: %v", m.vrfTableNum, err)
+	}
+
+	for _, route := range routes {
+		if route.Protocol != vrfRouteProtocol || route.Dst == nil {
+			continue
+		}
+		if _, exist := expectedRoutes[route.Dst.String()]; !exist {
+			if err := netlink.RouteDel(&route); err != nil {
+				return fmt.Errorf("failed to delete vrf route %v: %v", route.String(), err)
+			}
+		}
+	}
+
+	for _, route := range expectedRoutes {
+		link, err := netlink.LinkByName(route.ifName)
+		if err != nil {
+			return fmt.Errorf("failed to get link %v: %v", route.ifName, err)
+		}
+
+		if err := netlink.RouteReplace(&netlink.Route{
+			Dst:       route.cidr,
+			LinkIndex: link.Attrs().Index,
+			Table:     m.vrfTableNum,
+			Scope:     route.scope,
+			Protocol:  vrfRouteProtocol,
+		}); err != nil {
+			return fmt.Errorf("failed to add vrf route for %v: %v", route.cidr.String(), err)
+		}
 	}
 	return nil
 }
 
+// vrfRoutes returns the routes to subnets supposed to be in table of host VRF indexed by cidr, overlay
+// subnets are routed through the vxlan device and vlan subnets on this host through their forward interfaces
+func (m *Manager) vrfRoutes() map[string]*vrfRoute {
+	routes := map[string]*vrfRoute{}
+
+	for _, info := range m.localClusterUnderlaySubnetInfoMap {
+		if !info.isUnderlayOnHost || info.mode != networkingv1.NetworkModeVlan {
+			continue
+		}
+		routes[info.cidr.String()] = &vrfRoute{cidr: info.cidr, ifName: info.forwardNodeIfName, scope: netlink.SCOPE_LINK}
+	}
+
+	for _, info := range m.localClusterOverlaySubnetInfoMap {
+		routes[info.cidr.String()] = &vrfRoute{cidr: info.cidr, ifName: info.forwardNodeIfName, scope: netlink.SCOPE_UNIVERSE}
+	}
+
+	for cidr, prefix := range m.remoteOverlaySubnetRouteMap(m.remoteOverlaySubnetInfoMap) {
+		routes[cidr] = &vrfRoute{cidr: prefix.cidr, ifName: m.overlayIfName, scope: netlink.SCOPE_UNIVERSE}
+	}
+
+	return routes
+}
+
+// localVtepNet returns the host prefix of the vtep address of this node, nil if vtep is unknown
+func (m *Manager) localVtepNet() *net.IPNet {
+	if m.localVtepIP == nil {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if m.family == netlink.FAMILY_V4 {
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: m.localVtepIP, Mask: net.CIDRMask(bits, bits)}
+}
+
+// cleanStaleClusterRules deletes the rules of cluster route tables which are not configured any more
+// or not selected by the current vtep address, tables of removed clusters are cleared together
+func (m *Manager) cleanStaleClusterRules() error {
+	configuredTables := map[int]bool{}
+	for _, table := range m.clusterRouteTables {
+		configuredTables[table] = true
+	}
+
+	ruleList, err := netlink.RuleList(m.family)
+	if err != nil {
+		return fmt.Errorf("failed to list rule: %v", err)
+	}
+
+	vtepNet := m.localVtepNet()
+	for _, rule := range ruleList {
+		if !checkIsClusterRule(rule) {
+			continue
+		}
+		if configuredTables[rule.Table] && vtepNet != nil && rule.Src.String() == vtepNet.String() {
+			continue
+		}
+
+		rule.Family = m.family
+		if err := netlink.RuleDel(&rule); err != nil {
+			return fmt.Errorf("failed to delete cluster route table rule %v: %v", rule.String(), err)
+		}
+
+		if !configuredTables[rule.Table] {
+			if err := clearRouteTable(rule.Table, m.family); err != nil {
+				return fmt.Errorf("failed to clear route table %v: %v", rule.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// sortedClusterRouteTables returns the dedicated route tables of remote clusters in ascending order
+func (m *Manager) sortedClusterRouteTables() []int {
+	tables := make([]int, 0, len(m.clusterRouteTables))
+	for _, table := range m.clusterRouteTables {
+		tables = append(tables, table)
+	}
+	sort.Ints(tables)
+	return tables
+}
+
+// remoteOverlaySubnetRouteMap returns the destinations of remote overlay subnet routes indexed by cidr string,
+// all of them are reachable through the same vxlan device, so they will be aggregated if compression is enabled,
+// only subnets with the same route metric and mtu can be aggregated together
+func (m *Manager) remoteOverlaySubnetRouteMap(infoMap SubnetInfoMap) map[string]*aggregatedPrefix {
+	type routeAttrs struct {
+		metric, mtu int
+	}
+
+	var prefixes []*aggregatedPrefix
+	if m.enableRemoteRouteCompression {
+		var attrsCIDRsMap = map[routeAttrs][]*net.IPNet{}
+		for _, info := range infoMap {
+			attrs := routeAttrs{metric: info.routeMetric, mtu: info.routeMTU}
+			attrsCIDRsMap[attrs] = append(attrsCIDRsMap[attrs], info.cidr)
+		}
+
+		for attrs, cidrs := range attrsCIDRsMap {
+			for _, prefix := range aggregatePrefixes(cidrs) {
+				prefix.metric, prefix.mtu = attrs.metric, attrs.mtu
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	} else {
+		for _, info := range infoMap {
+			prefixes = append(prefixes, &aggregatedPrefix{cidr: info.cidr, count: 1, metric: info.routeMetric,
+				mtu: info.routeMTU})
+		}
+	}
+
+	routeMap := map[string]*aggregatedPrefix{}
+	for _, prefix := range prefixes {
+		routeMap[prefix.cidr.String()] = prefix
+	}
+	return routeMap
+}
+
 func (m *Manager) ensureOverlayMarkRoutes() error {
 	if m.overlayIfName != "" {
 		overlayLink, err := netlink.LinkByName(m.overlayIfName)
@@ -0,0 +1,262 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package route
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestRemoteOverlaySubnetRouteMapWithMetric(t *testing.T) {
+	infoMap := SubnetInfoMap{}
+	for cidrString, metric := range map[string]int{
+		"10.0.0.0/25":   0,
+		"10.0.0.128/25": 0,
+		"10.0.1.0/25":   100,
+		"10.0.1.128/25": 200,
+	} {
+		_, cidr, _ := net.ParseCIDR(cidrString)
+		infoMap[cidr.String()] = &SubnetInfo{cidr: cidr, routeMetric: metric}
+	}
+
+	m := &Manager{
+		remoteOverlaySubnetInfoMap:   infoMap,
+		enableRemoteRouteCompression: true,
+	}
+
+	expected := map[string]int{
+		"10.0.0.0/24":   0,
+		"10.0.1.0/25":   100,
+		"10.0.1.128/25": 200,
+	}
+
+	routeMap := m.remoteOverlaySubnetRouteMap(m.remoteOverlaySubnetInfoMap)
+	if len(routeMap) != len(expected) {
+		t.Fatalf("expected %d routes but got %v", len(expected), routeMap)
+	}
+	for cidrString, metric := range expected {
+		prefix, exist := routeMap[cidrString]
+		if !exist {
+			t.Errorf("route to %s is expected", cidrString)
+			continue
+		}
+		if prefix.metric != metric {
+			t.Errorf("route to %s is expected to have metric %d but got %d", cidrString, metric, prefix.metric)
+		}
+	}
+}
+
+func TestRemoteOverlaySubnetRouteMapWithMTU(t *testing.T) {
+	infoMap := SubnetInfoMap{}
+	for cidrString, mtu := range map[string]int{
+		"10.0.0.0/25":   0,
+		"10.0.0.128/25": 0,
+		"10.0.1.0/25":   1400,
+		"10.0.1.128/25": 1400,
+		"10.0.2.0/25":   1300,
+	} {
+		_, cidr, _ := net.ParseCIDR(cidrString)
+		infoMap[cidr.String()] = &SubnetInfo{cidr: cidr, routeMTU: mtu}
+	}
+
+	m := &Manager{
+		remoteOverlaySubnetInfoMap:   infoMap,
+		enableRemoteRouteCompression: true,
+	}
+
+	expected := map[string]int{
+		"10.0.0.0/24": 0,
+		"10.0.1.0/24": 1400,
+		"10.0.2.0/25": 1300,
+	}
+
+	routeMap := m.remoteOverlaySubnetRouteMap(m.remoteOverlaySubnetInfoMap)
+	if len(routeMap) != len(expected) {
+		t.Fatalf("expected %d routes but got %v", len(expected), routeMap)
+	}
+	for cidrString, mtu := range expected {
+		prefix, exist := routeMap[cidrString]
+		if !exist {
+			t.Errorf("route to %s is expected", cidrString)
+			continue
+		}
+		if prefix.mtu != mtu {
+			t.Errorf("route to %s is expected to have mtu %d but got %d", cidrString, mtu, prefix.mtu)
+		}
+	}
+}
+
+func TestAddRemoteSubnetInfoOfDedicatedCluster(t *testing.T) {
+	m := &Manager{
+		clusterRouteTables: map[string]int{"test": 41000, "prod": 41001},
+	}
+	m.ResetInfos()
+
+	// the same cidr exported by three clusters
+	_, cidr, _ := net.ParseCIDR("10.0.0.0/24")
+	for _, cluster := range []string{"test", "prod", "other"} {
+		if err := m.AddRemoteSubnetInfo(cidr, nil, nil, nil, nil, true, cluster, 0, 0); err != nil {
+			t.Fatalf("fail to add remote subnet info of cluster %s: %v", cluster, err)
+		}
+	}
+
+	if len(m.remoteOverlaySubnetInfoMap) != 1 {
+		t.Fatalf("expected only subnet of cluster without dedicated table in shared map but got %v", m.remoteOverlaySubnetInfoMap)
+	}
+	for _, cluster := range []string{"test", "prod"} {
+		if _, exist := m.clusterOverlaySubnetInfoMaps[cluster][cidr.String()]; !exist {
+			t.Errorf("expected subnet recorded for cluster %s", cluster)
+		}
+	}
+
+	if tables := m.sortedClusterRouteTables(); len(tables) != 2 || tables[0] != 41000 || tables[1] != 41001 {
+		t.Fatalf("expected cluster route tables in ascending order but got %v", tables)
+	}
+}
+
+func TestLocalVtepNet(t *testing.T) {
+	m := &Manager{family: netlink.FAMILY_V4}
+	if m.localVtepNet() != nil {
+		t.Fatalf("expected no vtep net before vtep is known")
+	}
+
+	// vtep of the other family is ignored
+	m.SetLocalVtepIP(net.ParseIP("fd00::1"))
+	if m.localVtepNet() != nil {
+		t.Fatalf("expected ipv6 vtep ignored by ipv4 manager")
+	}
+
+	m.SetLocalVtepIP(net.ParseIP("192.168.0.1"))
+	if vtepNet := m.localVtepNet(); vtepNet == nil || vtepNet.String() != "192.168.0.1/32" {
+		t.Fatalf("expected host prefix of vtep but got %v", vtepNet)
+	}
+
+	m.ResetInfos()
+	if m.localVtepNet() != nil {
+		t.Fatalf("expected vtep forgotten after infos are reset")
+	}
+
+	m6 := &Manager{family: netlink.FAMILY_V6}
+	m6.SetLocalVtepIP(net.ParseIP("fd00::1"))
+	if vtepNet := m6.localVtepNet(); vtepNet == nil || vtepNet.String() != "fd00::1/128" {
+		t.Fatalf("expected host prefix of ipv6 vtep but got %v", vtepNet)
+	}
+}
+
+func TestCheckIsClusterRule(t *testing.T) {
+	_, vtepNet, _ := net.ParseCIDR("192.168.0.1/32")
+	_, podSubnet, _ := net.ParseCIDR("10.0.0.0/24")
+
+	tests := []struct {
+		name     string
+		rule     netlink.Rule
+		expected bool
+	}{
+		{"cluster rule", netlink.Rule{Src: vtepNet, Table: 41000, Mask: fromRuleMask}, true},
+		{"cluster rule below subnet tables", netlink.Rule{Src: vtepNet, Table: 9000, Mask: fromRuleMask}, true},
+		{"from pod subnet rule", netlink.Rule{Src: podSubnet, Table: MinRouteTableNum, Mask: fromRuleMask}, false},
+		{"rule of others", netlink.Rule{Src: vtepNet, Table: 41000}, false},
+		{"basic rule", netlink.Rule{Table: 39999, Mask: fromRuleMask}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if checkIsClusterRule(test.rule) != test.expected {
+				t.Fatalf("expected %v for rule %v", test.expected, test.rule)
+			}
+		})
+	}
+}
+
+func TestIsStalePodRoute(t *testing.T) {
+	_, dst, _ := net.ParseCIDR("10.10.0.0/16")
+	_, dstV6, _ := net.ParseCIDR("fd00:10::/64")
+	podRoutes := []*PodRoute{
+		{Dst: dst, NextHop: net.ParseIP("192.168.56.254")},
+		{Dst: dstV6, NextHop: net.ParseIP("fd00:56::fe")},
+	}
+	_, subnetCidr, _ := net.ParseCIDR("192.168.56.0/24")
+	_, removedDst, _ := net.ParseCIDR("10.20.0.0/16")
+
+	tests := []struct {
+		name  string
+		route netlink.Route
+		stale bool
+	}{
+		{"default route", netlink.Route{Gw: net.ParseIP("192.168.56.1")}, false},
+		{"subnet direct route", netlink.Route{Dst: subnetCidr}, false},
+		{"pod route", netlink.Route{Dst: dst, Gw: net.ParseIP("192.168.56.254")}, false},
+		{"ipv6 pod route", netlink.Route{Dst: dstV6, Gw: net.ParseIP("fd00:56::fe")}, false},
+		{"next hop changed", netlink.Route{Dst: dst, Gw: net.ParseIP("192.168.56.253")}, true},
+		{"removed pod route", netlink.Route{Dst: removedDst, Gw: net.ParseIP("192.168.56.254")}, true},
+	}
+
+	for _, test := range tests {
+		if stale := isStalePodRoute(&test.route, podRoutes); stale != test.stale {
+			t.Errorf("%s: expected stale %v but got %v", test.name, test.stale, stale)
+		}
+	}
+}
+
+func TestVRFRoutes(t *testing.T) {
+	m := &Manager{family: netlink.FAMILY_V4}
+	m.ResetInfos()
+
+	_, overlayCidr, _ := net.ParseCIDR("100.10.0.0/24")
+	_, vlanCidr, _ := net.ParseCIDR("192.168.56.0/24")
+	_, vlanNotOnHostCidr, _ := net.ParseCIDR("192.168.57.0/24")
+	_, bgpCidr, _ := net.ParseCIDR("10.20.0.0/24")
+	_, remoteCidr, _ := net.ParseCIDR("100.20.0.0/24")
+
+	m.AddSubnetInfo(overlayCidr, nil, nil, nil, nil, "eth0.vxlan4", false, true, false,
+		networkingv1.NetworkModeVxlan, nil)
+	m.AddSubnetInfo(vlanCidr, nil, nil, nil, nil, "eth0.10", false, false, true,
+		networkingv1.NetworkModeVlan, nil)
+	m.AddSubnetInfo(vlanNotOnHostCidr, nil, nil, nil, nil, "eth0.11", false, false, false,
+		networkingv1.NetworkModeVlan, nil)
+	m.AddSubnetInfo(bgpCidr, nil, nil, nil, nil, "eth1", false, false, true,
+		networkingv1.NetworkModeBGP, nil)
+	if err := m.AddRemoteSubnetInfo(remoteCidr, nil, nil, nil, nil, true, "remote", 0, 0); err != nil {
+		t.Fatalf("fail to add remote subnet info: %v", err)
+	}
+
+	expected := map[string]vrfRoute{
+		overlayCidr.String(): {ifName: "eth0.vxlan4", scope: netlink.SCOPE_UNIVERSE},
+		vlanCidr.String():    {ifName: "eth0.10", scope: netlink.SCOPE_LINK},
+		remoteCidr.String():  {ifName: "eth0.vxlan4", scope: netlink.SCOPE_UNIVERSE},
+	}
+
+	routes := m.vrfRoutes()
+	if len(routes) != len(expected) {
+		t.Fatalf("expected vrf routes to %v but got %v", expected, routes)
+	}
+	for cidr, expectedRoute := range expected {
+		route, exist := routes[cidr]
+		if !exist {
+			t.Errorf("expected vrf route to %s", cidr)
+			continue
+		}
+		if route.cidr.String() != cidr || route.ifName != expectedRoute.ifName || route.scope != expectedRoute.scope {
+			t.Errorf("expected vrf route to %s through %s with scope %v but got %s through %s with scope %v",
+				cidr, expectedRoute.ifName, expectedRoute.scope, route.cidr, route.ifName, route.scope)
+		}
+	}
+}
@@ -40,6 +40,12 @@ const (
 
 	fromRuleMask = iptables.KubeProxyMasqueradeMark + iptables.FullNATedPodTrafficMark
 	fromRuleMark = 0x0
+
+	// the metric assigned by kernel for ipv6 routes without priority
+	defaultIPv6RouteMetric = 1024
+
+	// the protocol to mark routes installed into table of host VRF by hybridnet
+	vrfRouteProtocol netlink.RouteProtocol = 104
 )
 
 type SubnetInfo struct {
@@ -58,10 +64,43 @@ type SubnetInfo struct {
 	isUnderlayOnHost bool
 
 	mode networkingv1.NetworkMode
+
+	// the priority of routes to remote subnet, 0 means kernel default
+	routeMetric int
+
+	// the mtu of routes to remote overlay subnet, 0 means the mtu of vxlan device
+	routeMTU int
+
+	// the additional routes of pods in vlan subnet, through next hops inside subnet
+	podRoutes []*PodRoute
+}
+
+// PodRoute is an additional route of pods to destination through a next hop inside subnet.
+// Pods always send packets to host through the virtual gateway answered by proxy arp/ndp of
+// host nic, so pod routes take effect in the from-pod-subnet table of host.
+type PodRoute struct {
+	Dst     *net.IPNet
+	NextHop net.IP
 }
 
 type SubnetInfoMap map[string]*SubnetInfo
 
+// vrfRoute is a route to subnet in table of host VRF
+type vrfRoute struct {
+	cidr   *net.IPNet
+	ifName string
+	scope  netlink.Scope
+}
+
+// routeMetricMatches checks if the priority of an existing route is the expected metric, kernel will
+// assign a default metric for ipv6 routes which are added without priority
+func routeMetricMatches(route *netlink.Route, metric, family int) bool {
+	if metric == 0 && family == netlink.FAMILY_V6 {
+		return route.Priority == 0 || route.Priority == defaultIPv6RouteMetric
+	}
+	return route.Priority == metric
+}
+
 func checkIfRouteTableEmpty(tableNum, family int) (bool, error) {
 	routeList, err := netlink.RouteListFiltered(family, &netlink.Route{
 		Table: tableNum,
@@ -169,6 +208,51 @@ func checkIsFromPodSubnetRule(rule netlink.Rule) bool {
 		rule.Table >= MinRouteTableNum && rule.Table <= MaxRouteTableNum
 }
 
+// checkIsClusterRule checks if rule selects the dedicated route table of a remote cluster, which is from
+// the vtep address with the same fwmark selector as from-pod-subnet rules, but out of their table range
+func checkIsClusterRule(rule netlink.Rule) bool {
+	return rule.Src != nil && rule.Mask == fromRuleMask &&
+		(rule.Table < MinRouteTableNum || rule.Table > MaxRouteTableNum)
+}
+
+// checkIfToOverlayRouteTableUsedByOthers checks if a table supposed to contain only routes to overlay
+// subnets through vxlan device and exclude routes is being used by others
+func checkIfToOverlayRouteTableUsedByOthers(table, family int, tableName string) error {
+	if empty, err := checkIfRouteTableEmpty(table, family); err != nil {
+		return fmt.Errorf("failed to check table %v empty: %v", table, err)
+	} else if empty {
+		return nil
+	}
+
+	routes, err := listRoutesByTable(table, family)
+	if err != nil {
+		return fmt.Errorf("failed to list routes for %v route table %v: %v", tableName, table, err)
+	}
+
+	for _, route := range routes {
+		if route.LinkIndex <= 0 && !isExcludeRoute(&route) {
+			return fmt.Errorf("find no device route, %v route table %v is used by others", tableName, table)
+		}
+
+		if route.LinkIndex > 0 {
+			routeIf, err := netlink.LinkByIndex(route.LinkIndex)
+			if err != nil {
+				return fmt.Errorf("failed to get route interface by index %v: %v", route.LinkIndex, err)
+			}
+
+			// "throw" v6 routes without a specified device will always be specified to "dev lo" by kernel automatically
+			if routeIf.Attrs().Flags&net.FlagLoopback != 0 && isExcludeRoute(&route) {
+				continue
+			}
+
+			if route.Gw != nil || routeIf.Type() != "vxlan" {
+				return fmt.Errorf("%v route table %v is used by others", tableName, table)
+			}
+		}
+	}
+	return nil
+}
+
 func clearRouteTable(table int, family int) error {
 	defaultRouteDst := defaultRouteDstByFamily(family)
 
@@ -194,7 +278,7 @@ func clearRouteTable(table int, family int) error {
 
 func ensureFromPodSubnetRuleAndRoutes(forwardNodeIfName string, cidr *net.IPNet,
 	gateway net.IP, autoNatOutgoing bool, family int, underlaySubnetInfoMap SubnetInfoMap,
-	underlayExcludeIPBlockMap map[string]*net.IPNet, mode networkingv1.NetworkMode) error {
+	underlayExcludeIPBlockMap map[string]*net.IPNet, mode networkingv1.NetworkMode, podRoutes []*PodRoute) error {
 
 	var table int
 	var err error
@@ -226,7 +310,7 @@ func ensureFromPodSubnetRuleAndRoutes(forwardNodeIfName string, cidr *net.IPNet,
 			return fmt.Errorf("failed to ensure routes for vxlan subnet %v: %v", cidr.String(), err)
 		}
 	case networkingv1.NetworkModeVlan:
-		if err := ensureRoutesForVlanSubnet(forwardLink, cidr, gateway, table, family); err != nil {
+		if err := ensureRoutesForVlanSubnet(forwardLink, cidr, gateway, table, family, podRoutes); err != nil {
 			return fmt.Errorf("failed to ensure routes for vlan subnet %v: %v", cidr.String(), err)
 		}
 	case networkingv1.NetworkModeBGP, networkingv1.NetworkModeGlobalBGP:
@@ -286,7 +370,8 @@ func ensureRoutesForVxlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, table
 			}
 
 			if route.Dst != nil {
-				if _, exist := underlaySubnetInfoMap[route.Dst.String()]; exist {
+				if subnet, exist := underlaySubnetInfoMap[route.Dst.String()]; exist &&
+					routeMetricMatches(&route, subnet.routeMetric, family) {
 					continue
 				}
 			} else {
@@ -305,6 +390,7 @@ func ensureRoutesForVxlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, table
 				Dst:       subnet.cidr,
 				Table:     table,
 				Scope:     netlink.SCOPE_UNIVERSE,
+				Priority:  subnet.routeMetric,
 			}
 
 			if err := netlink.RouteReplace(subnetRoute); err != nil {
@@ -320,7 +406,8 @@ func ensureRoutesForVxlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, table
 	return nil
 }
 
-func ensureRoutesForVlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, gateway net.IP, table, family int) error {
+func ensureRoutesForVlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, gateway net.IP, table, family int,
+	podRoutes []*PodRoute) error {
 	localAddrList, err := netlink.AddrList(nil, family)
 	if err != nil {
 		return fmt.Errorf("failed to list local addresses: %v", err)
@@ -399,9 +486,52 @@ func ensureRoutesForVlanSubnet(forwardLink netlink.Link, cidr *net.IPNet, gatewa
 		return fmt.Errorf("failed to add vlan subnet %v default route %v: %v", cidr.String(), defaultRoute.String(), err)
 	}
 
+	for _, podRoute := range podRoutes {
+		route := &netlink.Route{
+			LinkIndex: forwardLink.Attrs().Index,
+			Dst:       podRoute.Dst,
+			Table:     table,
+			Scope:     netlink.SCOPE_UNIVERSE,
+			Gw:        podRoute.NextHop,
+		}
+		if err := netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to add vlan subnet %v pod route %v: %v", cidr.String(), route.String(), err)
+		}
+	}
+
+	routeList, err := netlink.RouteListFiltered(family, &netlink.Route{
+		Table: table,
+	}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return fmt.Errorf("failed to list route for table %v: %v", table, err)
+	}
+
+	for _, route := range routeList {
+		if isStalePodRoute(&route, podRoutes) {
+			if err := netlink.RouteDel(&route); err != nil {
+				return fmt.Errorf("failed to delete stale pod route %v for table %v: %v", route.String(), table, err)
+			}
+		}
+	}
+
 	return nil
 }
 
+// isStalePodRoute checks if route of a vlan subnet table is a pod route removed from subnet,
+// pod routes are the only routes of table with both destination and gateway
+func isStalePodRoute(route *netlink.Route, podRoutes []*PodRoute) bool {
+	if route.Dst == nil || route.Gw == nil {
+		return false
+	}
+
+	for _, podRoute := range podRoutes {
+		if podRoute.Dst.String() == route.Dst.String() && podRoute.NextHop.Equal(route.Gw) {
+			return false
+		}
+	}
+	return true
+}
+
 func ensureRoutesForBGPSubnet(forwardLink netlink.Link, cidr *net.IPNet, gateway net.IP, table, family int) error {
 	// default route is always needed
 	var defaultRoute *netlink.Route
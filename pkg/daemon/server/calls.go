@@ -0,0 +1,83 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alibaba/hybridnet/pkg/request"
+)
+
+type cniCall struct {
+	Operation    string    `json:"operation"`
+	PodName      string    `json:"podName"`
+	PodNamespace string    `json:"podNamespace"`
+	ContainerID  string    `json:"containerID"`
+	StartTime    time.Time `json:"startTime"`
+}
+
+// cniCallTracker tracks the in-flight cni calls, which will be recorded in crash report
+type cniCallTracker struct {
+	mu     sync.Mutex
+	nextID uint64
+	calls  map[uint64]*cniCall
+}
+
+func newCNICallTracker() *cniCallTracker {
+	return &cniCallTracker{
+		calls: map[uint64]*cniCall{},
+	}
+}
+
+// start records a cni call and returns the function to mark it done
+func (t *cniCallTracker) start(operation string, podRequest *request.PodRequest) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.nextID
+	t.nextID++
+	t.calls[id] = &cniCall{
+		Operation:    operation,
+		PodName:      podRequest.PodName,
+		PodNamespace: podRequest.PodNamespace,
+		ContainerID:  podRequest.ContainerID,
+		StartTime:    time.Now(),
+	}
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.calls, id)
+	}
+}
+
+// ActiveCalls lists the in-flight cni calls ordered by start time
+func (t *cniCallTracker) ActiveCalls() (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	calls := make([]cniCall, 0, len(t.calls))
+	for _, call := range t.calls {
+		calls = append(calls, *call)
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].StartTime.Before(calls[j].StartTime)
+	})
+	return calls, nil
+}
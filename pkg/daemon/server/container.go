@@ -28,23 +28,30 @@ import (
 
 	"github.com/containernetworking/plugins/pkg/ns"
 	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
 
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/daemon/arp"
 	"github.com/alibaba/hybridnet/pkg/daemon/containernetwork"
 )
 
 // ipAddr is a CIDR notation IP address and prefix length
-func (cdh *cniDaemonHandler) configureNic(podName, podNamespace, netns, mac string,
-	allocatedIPs map[networkingv1.IPVersion]*utils.IPInfo, networkMode networkingv1.NetworkMode) (string, error) {
+func (cdh *cniDaemonHandler) configureNic(pod *corev1.Pod, netns, mac string,
+	allocatedIPs map[networkingv1.IPVersion]*utils.IPInfo, network *networkingv1.Network) (string, error) {
+
+	podName, podNamespace := pod.Name, pod.Namespace
 
 	var err error
 	var nodeIfName string
 	var mtu int
 
+	networkMode := networkingv1.GetNetworkMode(network)
 	switch networkMode {
 	case networkingv1.NetworkModeVlan:
 		mtu = cdh.config.VlanMTU
-		nodeIfName = cdh.config.NodeVlanIfName
+		if nodeIfName, err = utils.GetVlanParentIfName(cdh.config.NodeVlanIfName, network); err != nil {
+			return "", fmt.Errorf("failed to get vlan parent interface name: %v", err)
+		}
 	case networkingv1.NetworkModeVxlan:
 		mtu = cdh.config.VxlanMTU
 		nodeIfName = cdh.config.NodeVxlanIfName
@@ -58,6 +65,23 @@ func (cdh *cniDaemonHandler) configureNic(podName, podNamespace, netns, mac stri
 		return "", fmt.Errorf("failed to parse mac %s %v", macAddr, err)
 	}
 
+	if networkingv1.IsSRIOVNetwork(network) {
+		return cdh.configureVF(podName, podNamespace, netns, macAddr, allocatedIPs, network)
+	}
+
+	if mtu, err = cdh.podMTU(pod, networkMode, nodeIfName, mtu); err != nil {
+		return "", fmt.Errorf("failed to get mtu of pod %v: %v", podName, err)
+	}
+
+	// nics of pods which were programmed before daemon restarts are recreated with the same ips,
+	// arp/ndp checks for them are skipped because the ips have already been checked and announced
+	skipVlanCheck := cdh.podNics.isSettled(podName, podNamespace, allocatedIPs)
+	if skipVlanCheck {
+		if _, err = deleteContainerNic(netns); err != nil {
+			return "", fmt.Errorf("failed to clean existing container nic for pod %v: %v", podName, err)
+		}
+	}
+
 	containerNicName, hostNicName, podNS, err := initContainerNic(podName, podNamespace, netns, mtu)
 	if err != nil {
 		return "", fmt.Errorf("failed to init container nic for pod %v: %v", podName, err)
@@ -66,21 +90,32 @@ func (cdh *cniDaemonHandler) configureNic(podName, podNamespace, netns, mac stri
 	defer func() {
 		if err != nil {
 			// clean the veth pair
-			_ = deleteContainerNic(netns)
+			_, _ = deleteContainerNic(netns)
 		}
 	}()
 
-	if err = containernetwork.ConfigureHostNic(hostNicName, allocatedIPs, cdh.config.LocalDirectTableNum); err != nil {
+	if err = containernetwork.ConfigureHostNic(hostNicName, allocatedIPs, cdh.config.LocalDirectTableNum,
+		cdh.config.HostVRFName); err != nil {
 		return "", fmt.Errorf("failed to configure host nic for %v.%v: %v", podName, podNamespace, err)
 	}
 
 	if err = containernetwork.ConfigureContainerNic(containerNicName, hostNicName, nodeIfName,
-		allocatedIPs, macAddr, podNS, mtu, cdh.config.VlanCheckTimeout, networkMode,
+		allocatedIPs, macAddr, podNS, mtu, cdh.config.VlanCheckTimeout, skipVlanCheck, networkMode,
 		cdh.config.NeighGCThresh1, cdh.config.NeighGCThresh2, cdh.config.NeighGCThresh3, cdh.config.IPv6RouteCacheMaxSize,
 		cdh.config.IPv6RouteCacheGCThresh, cdh.bgpManager); err != nil {
 		return "", fmt.Errorf("failed to configure container nic for %v.%v: %v", podName, podNamespace, err)
 	}
 
+	// install static neigh entries of overlay pods on host, so that the first packet from
+	// host to pod need not wait for address resolution
+	if networkingv1.GetNetworkType(network) == networkingv1.NetworkTypeOverlay {
+		for _, ipInfo := range allocatedIPs {
+			if err = arp.InstallStaticNeighbor(hostNicName, ipInfo.Addr, macAddr); err != nil {
+				return "", fmt.Errorf("failed to install static neigh for %v.%v: %v", podName, podNamespace, err)
+			}
+		}
+	}
+
 	if allocatedIPs[networkingv1.IPv4] != nil {
 		podIP := allocatedIPs[networkingv1.IPv4].Addr
 
@@ -108,11 +143,34 @@ func (cdh *cniDaemonHandler) configureNic(podName, podNamespace, netns, mac stri
 	return hostNicName, nil
 }
 
-func (cdh *cniDaemonHandler) deleteNic(netns string) error {
-	return deleteContainerNic(netns)
+func (cdh *cniDaemonHandler) deleteNic(podName, podNamespace, netns string) error {
+	if err := cdh.deleteVF(podName, podNamespace, netns); err != nil {
+		return fmt.Errorf("failed to delete virtual function of %v.%v: %v", podName, podNamespace, err)
+	}
+
+	cdh.podNics.forget(podName, podNamespace)
+
+	// static neigh entries of overlay pods are removed before the host nic disappears
+	hostNicName, _ := containernetwork.GenerateContainerVethPair(podNamespace, podName)
+	if err := arp.RemoveStaticNeighbors(hostNicName); err != nil {
+		return fmt.Errorf("failed to remove static neigh of %v: %v", hostNicName, err)
+	}
+
+	deleted, err := deleteContainerNic(netns)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		cdh.logger.V(1).Info("Container nic not found, treated as deleted",
+			"podName", podName,
+			"podNamespace", podNamespace,
+			"netns", netns)
+	}
+	return nil
 }
 
-func deleteContainerNic(netns string) error {
+// checkNic makes sure the nic of container configured by ADD still exists
+func (cdh *cniDaemonHandler) checkNic(podName, podNamespace, netns string) error {
 	nsHandler, err := ns.GetNS(netns)
 	if err != nil {
 		return fmt.Errorf("get ns error: %v", err)
@@ -120,11 +178,41 @@ func deleteContainerNic(netns string) error {
 	defer nsHandler.Close()
 
 	return nsHandler.Do(func(netNS ns.NetNS) error {
-		if err := ip.DelLinkByName(constants.ContainerNicName); err != nil && err != ip.ErrLinkNotFound {
-			return err
+		if _, err := netlink.LinkByName(constants.ContainerNicName); err != nil {
+			return fmt.Errorf("failed to get nic %v: %v", constants.ContainerNicName, err)
+		}
+		return nil
+	})
+}
+
+// deleteContainerNic deletes the nic of container, routes through it including pod routes
+// of subnet are cleaned up by kernel together. A missing nic or netns is not an error because
+// the nic has been deleted by a previous call or together with the netns, false is returned then.
+func deleteContainerNic(netns string) (bool, error) {
+	if len(netns) == 0 {
+		return false, nil
+	}
+
+	nsHandler, err := ns.GetNS(netns)
+	if err != nil {
+		if _, ok := err.(ns.NSPathNotExistErr); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("get ns error: %v", err)
+	}
+	defer nsHandler.Close()
+
+	deleted := true
+	err = nsHandler.Do(func(netNS ns.NetNS) error {
+		if err := ip.DelLinkByName(constants.ContainerNicName); err != nil {
+			if err != ip.ErrLinkNotFound {
+				return err
+			}
+			deleted = false
 		}
 		return nil
 	})
+	return deleted, err
 }
 
 func initContainerNic(podName, podNamespace, netns string, mtu int) (string, string, ns.NetNS, error) {
@@ -0,0 +1,62 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-logr/logr"
+
+	daemonconfig "github.com/alibaba/hybridnet/pkg/daemon/config"
+	"github.com/alibaba/hybridnet/pkg/daemon/sriov"
+	"github.com/alibaba/hybridnet/pkg/request"
+)
+
+func TestHandleDelIdempotent(t *testing.T) {
+	cdh := &cniDaemonHandler{
+		config:      &daemonconfig.Configuration{},
+		vfAllocator: sriov.NewVFAllocator(),
+		cniCalls:    newCNICallTracker(),
+		podNics:     &podNicInventory{settled: map[string][]string{}},
+		logger:      logr.Discard(),
+	}
+
+	container := restful.NewContainer()
+	ws := new(restful.WebService)
+	ws.Path("/api/v1").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.DELETE("/del").To(cdh.handleDel).Reads(request.PodRequest{}))
+	container.Add(ws)
+
+	// the netns has been removed together with the nic by the first DEL
+	netns := filepath.Join(t.TempDir(), "netns")
+	body := `{"pod_name":"pod1","pod_namespace":"default","net_ns":"` + netns + `"}`
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/del", strings.NewReader(body))
+		req.Header.Set("Content-Type", restful.MIME_JSON)
+		recorder := httptest.NewRecorder()
+		container.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusNoContent {
+			t.Fatalf("expected DEL %d succeeded but got %d %s", i+1, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-logr/logr"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+	"github.com/alibaba/hybridnet/pkg/request"
+)
+
+const (
+	cniCommandAdd   = "add"
+	cniCommandDel   = "del"
+	cniCommandCheck = "check"
+)
+
+// cniCommandPool serves one kind of cni command with a fixed number of workers, so that
+// a burst of slow commands, e.g., ADDs waiting for ips, never blocks commands of other kinds
+type cniCommandPool struct {
+	command    string
+	workers    chan struct{}
+	timeout    time.Duration
+	containers *containerSerializer
+	logger     logr.Logger
+}
+
+// newCNICommandPool creates a pool of command, pools sharing the same containers serializer
+// never run commands of the same container concurrently
+func newCNICommandPool(command string, workers int, timeout time.Duration, containers *containerSerializer,
+	logger logr.Logger) *cniCommandPool {
+	return &cniCommandPool{
+		command:    command,
+		workers:    make(chan struct{}, workers),
+		timeout:    timeout,
+		containers: containers,
+		logger:     logger.WithValues("command", command),
+	}
+}
+
+// dispatch wraps handler to be served by a free worker of pool within timeout. Handlers can
+// not be interrupted, so a handler which times out keeps its worker until it returns, and its
+// response is dropped since cni plugin has been answered with failure and runtime will retry.
+//
+// Commands of the same container are serialized, so the DEL or ADD retried by runtime after a
+// timed out ADD waits until the ADD returns, instead of racing with it on the same container.
+func (p *cniCommandPool) dispatch(handler restful.RouteFunction) restful.RouteFunction {
+	return func(req *restful.Request, resp *restful.Response) {
+		start := time.Now()
+		defer func() {
+			metrics.CNICommandDuration.WithLabelValues(p.command).Observe(time.Since(start).Seconds())
+		}()
+
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+
+		// the request must not be touched once the http handler returns
+		body, err := io.ReadAll(req.Request.Body)
+		if err != nil {
+			p.fail(fmt.Errorf("failed to read cni %s request: %v", p.command, err), http.StatusBadRequest, resp)
+			return
+		}
+
+		podRequest := request.PodRequest{}
+		if err = json.Unmarshal(body, &podRequest); err != nil {
+			p.fail(fmt.Errorf("failed to parse cni %s request: %v", p.command, err), http.StatusBadRequest, resp)
+			return
+		}
+
+		if !p.containers.lock(podRequest.ContainerID, timer.C) {
+			p.fail(fmt.Errorf("previous command of container %v does not finish within %v", podRequest.ContainerID,
+				p.timeout), http.StatusServiceUnavailable, resp)
+			return
+		}
+
+		select {
+		case p.workers <- struct{}{}:
+		case <-timer.C:
+			p.containers.unlock(podRequest.ContainerID)
+			p.fail(fmt.Errorf("no free worker for cni %s command within %v", p.command, p.timeout),
+				http.StatusServiceUnavailable, resp)
+			return
+		}
+
+		detachedReq := *req
+		detachedReq.Request = req.Request.Clone(context.Background())
+		detachedReq.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		buffer := &responseBuffer{header: http.Header{}}
+		bufferedResp := *resp
+		bufferedResp.ResponseWriter = buffer
+
+		done := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				<-p.workers
+				p.containers.unlock(podRequest.ContainerID)
+				done <- recover()
+			}()
+			handler(&detachedReq, &bufferedResp)
+		}()
+
+		select {
+		case panicked := <-done:
+			if panicked != nil {
+				// keep the behavior of panics in handlers as if they were not dispatched
+				panic(panicked)
+			}
+			buffer.flushTo(resp.ResponseWriter)
+		case <-timer.C:
+			p.fail(fmt.Errorf("cni %s command does not finish within %v", p.command, p.timeout),
+				http.StatusGatewayTimeout, resp)
+			go func() {
+				if panicked := <-done; panicked != nil {
+					p.logger.Error(fmt.Errorf("%v", panicked), "timed out cni command panics")
+				}
+			}()
+		}
+	}
+}
+
+func (p *cniCommandPool) fail(err error, status int, resp *restful.Response) {
+	p.logger.Error(err, "dispatch error")
+	_ = resp.WriteHeaderAndEntity(status, request.PodResponse{
+		Err: err.Error(),
+	})
+}
+
+// containerSerializer serializes cni commands of the same container
+type containerSerializer struct {
+	mu    sync.Mutex
+	locks map[string]*containerLock
+}
+
+type containerLock struct {
+	held chan struct{}
+	// refs is the number of commands holding or waiting for the lock
+	refs int
+}
+
+func newContainerSerializer() *containerSerializer {
+	return &containerSerializer{
+		locks: map[string]*containerLock{},
+	}
+}
+
+// lock waits for the lock of container until timeout, and returns false if it times out
+func (s *containerSerializer) lock(containerID string, timeout <-chan time.Time) bool {
+	s.mu.Lock()
+	l, exist := s.locks[containerID]
+	if !exist {
+		l = &containerLock{held: make(chan struct{}, 1)}
+		s.locks[containerID] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	select {
+	case l.held <- struct{}{}:
+		return true
+	case <-timeout:
+		s.release(containerID, l)
+		return false
+	}
+}
+
+func (s *containerSerializer) unlock(containerID string) {
+	s.mu.Lock()
+	l := s.locks[containerID]
+	s.mu.Unlock()
+
+	<-l.held
+	s.release(containerID, l)
+}
+
+func (s *containerSerializer) release(containerID string, l *containerLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l.refs--
+	if l.refs == 0 {
+		delete(s.locks, containerID)
+	}
+}
+
+// responseBuffer holds the response of a dispatched handler until it is known to finish in time
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(data)
+}
+
+func (b *responseBuffer) flushTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	b.WriteHeader(http.StatusOK)
+	w.WriteHeader(b.status)
+	_, _ = w.Write(b.body.Bytes())
+}
@@ -0,0 +1,134 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-logr/logr"
+
+	"github.com/alibaba/hybridnet/pkg/request"
+)
+
+func serveDispatched(pool *cniCommandPool, handler restful.RouteFunction) *httptest.ResponseRecorder {
+	return serveDispatchedContainer(pool, handler, "c1")
+}
+
+func serveDispatchedContainer(pool *cniCommandPool, handler restful.RouteFunction, containerID string) *httptest.ResponseRecorder {
+	container := restful.NewContainer()
+	ws := new(restful.WebService)
+	ws.Path("/api/v1").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.POST("/test").To(pool.dispatch(handler)).Reads(request.PodRequest{}))
+	container.Add(ws)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/test", strings.NewReader(`{"pod_name":"pod1","container_id":"`+containerID+`"}`))
+	req.Header.Set("Content-Type", restful.MIME_JSON)
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCNICommandPoolDispatch(t *testing.T) {
+	pool := newCNICommandPool(cniCommandAdd, 1, time.Second, newContainerSerializer(), logr.Discard())
+
+	recorder := serveDispatched(pool, func(req *restful.Request, resp *restful.Response) {
+		podRequest := request.PodRequest{}
+		if err := req.ReadEntity(&podRequest); err != nil {
+			t.Errorf("fail to read request: %v", err)
+		}
+		_ = resp.WriteHeaderAndEntity(http.StatusOK, request.PodResponse{HostInterface: podRequest.PodName})
+	})
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"host_interface": "pod1"`) {
+		t.Fatalf("expected response of handler but got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestCNICommandPoolTimeout(t *testing.T) {
+	pool := newCNICommandPool(cniCommandDel, 1, 50*time.Millisecond, newContainerSerializer(), logr.Discard())
+
+	release := make(chan struct{})
+	blocking := func(req *restful.Request, resp *restful.Response) {
+		<-release
+		resp.WriteHeader(http.StatusNoContent)
+	}
+
+	if recorder := serveDispatched(pool, blocking); recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected handler timed out but got %d", recorder.Code)
+	}
+
+	// the timed out handler still holds the only worker
+	if recorder := serveDispatchedContainer(pool, blocking, "c2"); recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected no free worker but got %d", recorder.Code)
+	}
+
+	close(release)
+	if recorder := serveDispatched(pool, blocking); recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected worker released after handler returns but got %d", recorder.Code)
+	}
+}
+
+func TestCNICommandPoolSerializesContainers(t *testing.T) {
+	containers := newContainerSerializer()
+	addPool := newCNICommandPool(cniCommandAdd, 2, 50*time.Millisecond, containers, logr.Discard())
+	delPool := newCNICommandPool(cniCommandDel, 2, time.Second, containers, logr.Discard())
+
+	release := make(chan struct{})
+	deleted := make(chan struct{})
+	blockingAdd := func(req *restful.Request, resp *restful.Response) {
+		<-release
+		select {
+		case <-deleted:
+			t.Errorf("expected del waits until add of the same container returns")
+		default:
+		}
+		resp.WriteHeader(http.StatusOK)
+	}
+	del := func(req *restful.Request, resp *restful.Response) {
+		close(deleted)
+		resp.WriteHeader(http.StatusNoContent)
+	}
+
+	if recorder := serveDispatched(addPool, blockingAdd); recorder.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected add timed out but got %d", recorder.Code)
+	}
+
+	// commands of other containers are never blocked
+	if recorder := serveDispatchedContainer(delPool, func(req *restful.Request, resp *restful.Response) {
+		resp.WriteHeader(http.StatusNoContent)
+	}, "c2"); recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected del of other container served but got %d", recorder.Code)
+	}
+
+	result := make(chan int)
+	go func() {
+		result <- serveDispatched(delPool, del).Code
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if code := <-result; code != http.StatusNoContent {
+		t.Fatalf("expected del served after add returns but got %d", code)
+	}
+	if len(containers.locks) != 0 {
+		t.Fatalf("expected container locks released but got %v", containers.locks)
+	}
+}
@@ -21,25 +21,31 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
+	cnitypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/emicklei/go-restful"
 	"github.com/go-logr/logr"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 	"github.com/alibaba/hybridnet/pkg/constants"
+	controllerutils "github.com/alibaba/hybridnet/pkg/controllers/utils"
 	"github.com/alibaba/hybridnet/pkg/daemon/bgp"
 	daemonconfig "github.com/alibaba/hybridnet/pkg/daemon/config"
 	"github.com/alibaba/hybridnet/pkg/daemon/controller"
+	"github.com/alibaba/hybridnet/pkg/daemon/sriov"
 	"github.com/alibaba/hybridnet/pkg/daemon/utils"
 	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	"github.com/alibaba/hybridnet/pkg/metrics"
 	"github.com/alibaba/hybridnet/pkg/request"
 	globalutils "github.com/alibaba/hybridnet/pkg/utils"
 	webhookutils "github.com/alibaba/hybridnet/pkg/webhook/utils"
@@ -50,6 +56,12 @@ type cniDaemonHandler struct {
 	mgrClient    client.Client
 	mgrAPIReader client.Reader
 	bgpManager   *bgp.Manager
+	vfAllocator  *sriov.VFAllocator
+	recorder     record.EventRecorder
+
+	cniCalls *cniCallTracker
+
+	podNics *podNicInventory
 
 	logger logr.Logger
 }
@@ -61,13 +73,26 @@ func createCniDaemonHandler(ctx context.Context, config *daemonconfig.Configurat
 		mgrClient:    ctrlRef.GetMgrClient(),
 		mgrAPIReader: ctrlRef.GetMgrAPIReader(),
 		bgpManager:   ctrlRef.GetBGPManager(),
+		vfAllocator:  sriov.NewVFAllocator(),
+		recorder:     ctrlRef.GetEventRecorder(),
+		cniCalls:     newCNICallTracker(),
 		logger:       logger,
 	}
 
-	if ok := ctrlRef.CacheSynced(ctx); !ok {
+	stopWatchdog := startIPAMRebuildWatchdog(config.IPAMRebuildTimeout, logger.WithName("ipam-rebuild-watchdog"))
+	ok := ctrlRef.CacheSynced(ctx)
+	stopWatchdog()
+	if !ok {
 		return nil, fmt.Errorf("failed to wait for ip instance & pod caches to sync")
 	}
 
+	podNics, err := buildPodNicInventory(ctx, cdh.mgrClient, config.NodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inventory of existing pod nics: %v", err)
+	}
+	cdh.podNics = podNics
+	logger.Info("inventory of existing pod nics built", "count", len(podNics.settled))
+
 	return cdh, nil
 }
 
@@ -79,6 +104,7 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 		cdh.errorWrapper(errMsg, http.StatusBadRequest, resp)
 		return
 	}
+	defer cdh.cniCalls.start(cniCommandAdd, &podRequest)()
 	cdh.logger.V(5).Info("handle add request", "content", podRequest)
 
 	var macAddr string
@@ -191,6 +217,13 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 
 		gatewayIP := net.ParseIP(ipInstance.Spec.Address.Gateway)
 
+		podRoutes, err := cdh.getPodRoutesOfSubnet(ipInstance.Spec.Subnet)
+		if err != nil {
+			errMsg := fmt.Errorf("failed to get pod routes of subnet %v: %v", ipInstance.Spec.Subnet, err)
+			cdh.errorWrapper(errMsg, http.StatusInternalServerError, resp)
+			return
+		}
+
 		ipVersion := networkingv1.IPv4
 		switch ipInstance.Spec.Address.Version {
 		case networkingv1.IPv4:
@@ -201,10 +234,11 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 			}
 
 			allocatedIPs[networkingv1.IPv4] = &utils.IPInfo{
-				Addr:  containerIP,
-				Gw:    gatewayIP,
-				Cidr:  cidrNet,
-				NetID: ipInstance.Spec.Address.NetID,
+				Addr:   containerIP,
+				Gw:     gatewayIP,
+				Cidr:   cidrNet,
+				NetID:  ipInstance.Spec.Address.NetID,
+				Routes: podRoutes,
 			}
 		case networkingv1.IPv6:
 			if allocatedIPs[networkingv1.IPv6] != nil {
@@ -214,10 +248,11 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 			}
 
 			allocatedIPs[networkingv1.IPv6] = &utils.IPInfo{
-				Addr:  containerIP,
-				Gw:    gatewayIP,
-				Cidr:  cidrNet,
-				NetID: ipInstance.Spec.Address.NetID,
+				Addr:   containerIP,
+				Gw:     gatewayIP,
+				Cidr:   cidrNet,
+				NetID:  ipInstance.Spec.Address.NetID,
+				Routes: podRoutes,
 			}
 
 			ipVersion = networkingv1.IPv6
@@ -262,13 +297,30 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 		return
 	}
 
+	// the first ip in cni result is taken as the primary address of pod
+	sortIPAddressesByFamilyPreference(returnIPAddress, networkingv1.GetIPFamilyPreference(network))
+
+	// wait for the last node to release IPs of stateful pod
+	if wait := nodeMobilityWaitDuration(network, pod, affectedIPInstances, cdh.config.NodeName, time.Now()); wait > 0 {
+		cdh.logger.Info("Wait for node mobility grace timeout",
+			"podName", podRequest.PodName,
+			"podNamespace", podRequest.PodNamespace,
+			"wait", wait)
+		if err := waitForNodeMobility(req.Request.Context(), wait); err != nil {
+			errMsg := fmt.Errorf("failed to wait for node mobility grace timeout of pod %v/%v: %v",
+				podRequest.PodNamespace, podRequest.PodName, err)
+			cdh.errorWrapper(errMsg, http.StatusInternalServerError, resp)
+			return
+		}
+	}
+
 	cdh.logger.Info("Create container",
 		"podName", podRequest.PodName,
 		"podNamespace", podRequest.PodNamespace,
 		"ipAddr", printAllocatedIPs(allocatedIPs),
 		"macAddr", macAddr)
-	hostInterface, err := cdh.configureNic(podRequest.PodName, podRequest.PodNamespace, podRequest.NetNs, macAddr,
-		allocatedIPs, networkingv1.GetNetworkMode(network))
+	hostInterface, err := cdh.configureNic(pod, podRequest.NetNs, macAddr,
+		allocatedIPs, network)
 	if err != nil {
 		errMsg := fmt.Errorf("failed to configure nic: %v", err)
 		cdh.errorWrapper(errMsg, http.StatusInternalServerError, resp)
@@ -280,6 +332,13 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 		"ipAddr", printAllocatedIPs(allocatedIPs),
 		"macAddr", macAddr)
 
+	// failing to record timeline should not fail the pod creation
+	if err := cdh.recordInterfaceConfigured(pod); err != nil {
+		cdh.logger.Error(err, "failed to record allocation timeline",
+			"podName", podRequest.PodName,
+			"podNamespace", podRequest.PodNamespace)
+	}
+
 	// update IPInstance crd status
 	if cdh.config.UpdateIPInstanceStatus {
 		for _, ip := range affectedIPInstances {
@@ -308,6 +367,25 @@ func (cdh *cniDaemonHandler) handleAdd(req *restful.Request, resp *restful.Respo
 	})
 }
 
+// recordInterfaceConfigured appends the interface configuration phase to the allocation timeline
+// of pod, only if timeline has been recorded by manager and the phase has never been recorded
+func (cdh *cniDaemonHandler) recordInterfaceConfigured(pod *corev1.Pod) error {
+	timeline, err := controllerutils.ParseAllocationTimeline(pod)
+	if err != nil || timeline == nil || timeline.InterfaceConfigured != nil {
+		return err
+	}
+
+	timeline.RecordNow(controllerutils.AllocationPhaseInterfaceConfigured)
+	if timeline.Queued != nil {
+		metrics.IPAllocationE2ESeconds.Observe(timeline.InterfaceConfigured.Sub(*timeline.Queued).Seconds())
+	}
+
+	return cdh.mgrClient.Patch(context.TODO(), pod,
+		client.RawPatch(types.MergePatchType,
+			[]byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+				constants.AnnotationIPAllocationTimeline, timeline.String()))))
+}
+
 func (cdh *cniDaemonHandler) handleDel(req *restful.Request, resp *restful.Response) {
 	podRequest := request.PodRequest{}
 	err := req.ReadEntity(&podRequest)
@@ -316,6 +394,7 @@ func (cdh *cniDaemonHandler) handleDel(req *restful.Request, resp *restful.Respo
 		cdh.errorWrapper(errMsg, http.StatusBadRequest, resp)
 		return
 	}
+	defer cdh.cniCalls.start(cniCommandDel, &podRequest)()
 
 	cdh.logger.Info("Delete container",
 		"podName", podRequest.PodName,
@@ -324,7 +403,7 @@ func (cdh *cniDaemonHandler) handleDel(req *restful.Request, resp *restful.Respo
 
 	cdh.logger.V(5).Info("handle del request", "content", podRequest)
 
-	err = cdh.deleteNic(podRequest.NetNs)
+	err = cdh.deleteNic(podRequest.PodName, podRequest.PodNamespace, podRequest.NetNs)
 	if err != nil {
 		errMsg := fmt.Errorf("failed to del container nic for %s: %v",
 			fmt.Sprintf("%s.%s", podRequest.PodName, podRequest.PodNamespace), err)
@@ -340,6 +419,28 @@ func (cdh *cniDaemonHandler) handleDel(req *restful.Request, resp *restful.Respo
 	resp.WriteHeader(http.StatusNoContent)
 }
 
+func (cdh *cniDaemonHandler) handleCheck(req *restful.Request, resp *restful.Response) {
+	podRequest := request.PodRequest{}
+	err := req.ReadEntity(&podRequest)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to parse check request: %v", err)
+		cdh.errorWrapper(errMsg, http.StatusBadRequest, resp)
+		return
+	}
+	defer cdh.cniCalls.start(cniCommandCheck, &podRequest)()
+
+	cdh.logger.V(5).Info("handle check request", "content", podRequest)
+
+	if err = cdh.checkNic(podRequest.PodName, podRequest.PodNamespace, podRequest.NetNs); err != nil {
+		errMsg := fmt.Errorf("failed to check container nic for %s: %v",
+			fmt.Sprintf("%s.%s", podRequest.PodName, podRequest.PodNamespace), err)
+		cdh.errorWrapper(errMsg, http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
 func (cdh *cniDaemonHandler) errorWrapper(err error, status int, resp *restful.Response) {
 	cdh.logger.Error(err, "handler error")
 	_ = resp.WriteHeaderAndEntity(status, request.PodResponse{
@@ -366,6 +467,51 @@ func (cdh *cniDaemonHandler) listAvailableIPInstanceOfPod(podUID, podNamespace s
 	return availableIPInstances, nil
 }
 
+// sortIPAddressesByFamilyPreference puts IPs of the preferred family ahead of the others
+func sortIPAddressesByFamilyPreference(addresses []request.IPAddress, preference networkingv1.IPFamilyPreference) {
+	preferred := networkingv1.IPv4
+	if preference == networkingv1.IPFamilyPreferenceIPv6First {
+		preferred = networkingv1.IPv6
+	}
+
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].Protocol == preferred && addresses[j].Protocol != preferred
+	})
+}
+
+// getPodRoutesOfSubnet converts pod routes of subnet to routes which will be injected into pod
+func (cdh *cniDaemonHandler) getPodRoutesOfSubnet(subnetName string) ([]*cnitypes.Route, error) {
+	subnet := &networkingv1.Subnet{}
+	if err := cdh.mgrClient.Get(context.TODO(), types.NamespacedName{Name: subnetName}, subnet); err != nil {
+		return nil, err
+	}
+	return podRoutesOfSubnet(subnet)
+}
+
+// podRoutesOfSubnet returns routes to destinations of pod routes through the virtual default gateway,
+// which is answered by proxy arp/ndp of host nic. The next hops of pod routes are not reachable from
+// pod directly, packets are routed to them by the from-pod-subnet table of host.
+func podRoutesOfSubnet(subnet *networkingv1.Subnet) ([]*cnitypes.Route, error) {
+	var routes []*cnitypes.Route
+	for _, podRoute := range subnet.Spec.PodRoutes {
+		_, dst, err := net.ParseCIDR(podRoute.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse destination %v of pod route: %v", podRoute.Destination, err)
+		}
+
+		gateway := net.ParseIP(constants.PodVirtualV4DefaultGateway)
+		if dst.IP.To4() == nil {
+			gateway = net.ParseIP(constants.PodVirtualV6DefaultGateway)
+		}
+
+		routes = append(routes, &cnitypes.Route{
+			Dst: *dst,
+			GW:  gateway,
+		})
+	}
+	return routes, nil
+}
+
 func printAllocatedIPs(allocatedIPs map[networkingv1.IPVersion]*utils.IPInfo) string {
 	ipAddressString := ""
 	if allocatedIPs[networkingv1.IPv4] != nil && allocatedIPs[networkingv1.IPv4].Addr != nil {
@@ -0,0 +1,60 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"net"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestPodRoutesOfSubnet(t *testing.T) {
+	subnet := &networkingv1.Subnet{
+		ObjectMeta: metav1.ObjectMeta{Name: "subnet1"},
+		Spec: networkingv1.SubnetSpec{
+			PodRoutes: []networkingv1.StaticRoute{
+				{Destination: "10.10.0.0/16", NextHop: "192.168.56.254"},
+				{Destination: "fd00:10::/64", NextHop: "fd00:56::fe"},
+			},
+		},
+	}
+
+	routes, err := podRoutesOfSubnet(subnet)
+	if err != nil {
+		t.Fatalf("fail to get pod routes: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes but got %v", routes)
+	}
+
+	// next hops are reached by node, pods only send packets to the virtual gateway
+	if routes[0].Dst.String() != "10.10.0.0/16" || !routes[0].GW.Equal(net.ParseIP(constants.PodVirtualV4DefaultGateway)) {
+		t.Errorf("unexpected ipv4 route %v", routes[0])
+	}
+	if routes[1].Dst.String() != "fd00:10::/64" || !routes[1].GW.Equal(net.ParseIP(constants.PodVirtualV6DefaultGateway)) {
+		t.Errorf("unexpected ipv6 route %v", routes[1])
+	}
+
+	subnet.Spec.PodRoutes = []networkingv1.StaticRoute{{Destination: "10.10.0.0", NextHop: "192.168.56.254"}}
+	if _, err = podRoutesOfSubnet(subnet); err == nil {
+		t.Fatalf("expected invalid destination rejected")
+	}
+}
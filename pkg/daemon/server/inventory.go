@@ -0,0 +1,132 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/containernetwork"
+	"github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+// podNicInventory records the pods whose veth pairs have been programmed before daemon starts,
+// together with the ips of them. The ips of these pods have already been checked and announced,
+// so arp/ndp checks are unnecessary if they are configured again with the same ips.
+type podNicInventory struct {
+	mu sync.Mutex
+	// host nic name -> sorted ips
+	settled map[string][]string
+}
+
+// buildPodNicInventory correlates the existing container host nics in host network namespace
+// with the ip instances of this node.
+func buildPodNicInventory(ctx context.Context, c client.Reader, nodeName string) (*podNicInventory, error) {
+	linkList, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %v", err)
+	}
+
+	existingHostNics := map[string]bool{}
+	for _, link := range linkList {
+		if link.Type() == "veth" && strings.HasPrefix(link.Attrs().Name, constants.ContainerHostLinkPrefix) {
+			existingHostNics[link.Attrs().Name] = true
+		}
+	}
+
+	inventory := &podNicInventory{
+		settled: map[string][]string{},
+	}
+	if len(existingHostNics) == 0 {
+		return inventory, nil
+	}
+
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := c.List(ctx, ipInstanceList, client.MatchingLabels{constants.LabelNode: nodeName}); err != nil {
+		return nil, fmt.Errorf("failed to list ip instances for node %v: %v", nodeName, err)
+	}
+
+	for i := range ipInstanceList.Items {
+		ipInstance := &ipInstanceList.Items[i]
+		if networkingv1.IsReserved(ipInstance) {
+			continue
+		}
+
+		hostNicName, _ := containernetwork.GenerateContainerVethPair(ipInstance.Namespace, ipInstance.Labels[constants.LabelPod])
+		if !existingHostNics[hostNicName] {
+			continue
+		}
+
+		podIP, _, err := net.ParseCIDR(ipInstance.Spec.Address.IP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ip %v of ip instance %v: %v", ipInstance.Spec.Address.IP, ipInstance.Name, err)
+		}
+		inventory.settled[hostNicName] = append(inventory.settled[hostNicName], podIP.String())
+	}
+
+	for hostNicName := range inventory.settled {
+		sort.Strings(inventory.settled[hostNicName])
+	}
+
+	return inventory, nil
+}
+
+// isSettled checks if the nic of pod has been programmed with exactly the same ips before daemon starts.
+func (p *podNicInventory) isSettled(podName, podNamespace string, allocatedIPs map[networkingv1.IPVersion]*utils.IPInfo) bool {
+	hostNicName, _ := containernetwork.GenerateContainerVethPair(podNamespace, podName)
+
+	var ips []string
+	for _, ipInfo := range allocatedIPs {
+		if ipInfo != nil {
+			ips = append(ips, ipInfo.Addr.String())
+		}
+	}
+	sort.Strings(ips)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	settledIPs, exist := p.settled[hostNicName]
+	if !exist || len(settledIPs) != len(ips) {
+		return false
+	}
+	for i := range ips {
+		if ips[i] != settledIPs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// forget removes the record of pod, the nic of pod is no longer settled once it is deleted.
+func (p *podNicInventory) forget(podName, podNamespace string) {
+	hostNicName, _ := containernetwork.GenerateContainerVethPair(podNamespace, podName)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.settled, hostNicName)
+}
@@ -0,0 +1,81 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// nodeMobilityWaitDuration returns how long to wait before configuring IPs which were released
+// from another node recently, according to the node mobility grace timeout of network. The wait
+// is capped by the termination grace period of pod, the last node must have cleaned up by then.
+func nodeMobilityWaitDuration(network *networkingv1.Network, pod *corev1.Pod, ipInstances []*networkingv1.IPInstance,
+	nodeName string, now time.Time) time.Duration {
+	if network.Spec.NodeMobilityGraceTimeout == nil || network.Spec.NodeMobilityGraceTimeout.Duration <= 0 {
+		return 0
+	}
+
+	var wait time.Duration
+	for _, ipInstance := range ipInstances {
+		lastNode := ipInstance.Annotations[constants.AnnotationLastNode]
+		if len(lastNode) == 0 || lastNode == nodeName {
+			continue
+		}
+
+		releaseTime, err := time.Parse(time.RFC3339, ipInstance.Annotations[constants.AnnotationLastNodeReleaseTime])
+		if err != nil {
+			continue
+		}
+
+		if remaining := releaseTime.Add(network.Spec.NodeMobilityGraceTimeout.Duration).Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	if limit := terminationGracePeriod(pod); wait > limit {
+		wait = limit
+	}
+	return wait
+}
+
+// terminationGracePeriod returns the termination grace period of pod, the default one of
+// kubernetes is returned if it is not specified
+func terminationGracePeriod(pod *corev1.Pod) time.Duration {
+	if pod.Spec.TerminationGracePeriodSeconds == nil {
+		return corev1.DefaultTerminationGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second
+}
+
+// waitForNodeMobility blocks for the wait duration, it returns early with error if ctx is done
+func waitForNodeMobility(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
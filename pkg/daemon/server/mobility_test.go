@@ -0,0 +1,103 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+func TestNodeMobilityWaitDuration(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	network := &networkingv1.Network{
+		Spec: networkingv1.NetworkSpec{
+			NodeMobilityGraceTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+	ipInstances := []*networkingv1.IPInstance{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					constants.AnnotationLastNode:            "node2",
+					constants.AnnotationLastNodeReleaseTime: now.Add(-time.Minute).Format(time.RFC3339),
+				},
+			},
+		},
+	}
+	gracePeriod := func(seconds int64) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{TerminationGracePeriodSeconds: &seconds}}
+	}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		nodeName string
+		expected time.Duration
+	}{
+		{
+			name:     "capped by termination grace period of pod",
+			pod:      gracePeriod(120),
+			nodeName: "node1",
+			expected: 2 * time.Minute,
+		},
+		{
+			name:     "capped by default termination grace period",
+			pod:      &corev1.Pod{},
+			nodeName: "node1",
+			expected: corev1.DefaultTerminationGracePeriodSeconds * time.Second,
+		},
+		{
+			name:     "remaining grace timeout",
+			pod:      gracePeriod(3600),
+			nodeName: "node1",
+			expected: 9 * time.Minute,
+		},
+		{
+			name:     "released from the same node",
+			pod:      gracePeriod(3600),
+			nodeName: "node2",
+			expected: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if wait := nodeMobilityWaitDuration(network, test.pod, ipInstances, test.nodeName, now); wait != test.expected {
+				t.Errorf("expect wait %v but got %v", test.expected, wait)
+			}
+		})
+	}
+}
+
+func TestWaitForNodeMobility(t *testing.T) {
+	if err := waitForNodeMobility(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("expect wait to finish but got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := waitForNodeMobility(ctx, time.Hour); err != context.Canceled {
+		t.Fatalf("expect wait to be canceled but got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+// minPodMTU is the minimum mtu of ipv4 links, which is also the minimum mtu of veth
+const minPodMTU = 68
+
+// maxPodMTU returns the largest mtu of pod nic which can be carried by the parent interface,
+// vxlanOverhead depends on the ip family of underlay and is only excluded for vxlan pods
+func maxPodMTU(networkMode networkingv1.NetworkMode, parentMTU, vxlanOverhead int) int {
+	if networkMode == networkingv1.NetworkModeVxlan {
+		return parentMTU - vxlanOverhead
+	}
+	return parentMTU
+}
+
+// podMTUOverride parses the mtu annotation of pod, zero is returned if pod does not override the mtu
+// of network. An mtu larger than maxMTU is refused because it can never be carried by the parent interface.
+func podMTUOverride(pod *corev1.Pod, maxMTU int) (int, error) {
+	value, exist := pod.Annotations[constants.AnnotationMTU]
+	if !exist {
+		return 0, nil
+	}
+
+	mtu, err := strconv.Atoi(value)
+	if err != nil || mtu < minPodMTU {
+		return 0, fmt.Errorf("invalid mtu annotation %q, should be an integer not less than %d", value, minPodMTU)
+	}
+	if mtu > maxMTU {
+		return 0, fmt.Errorf("mtu %d of annotation is larger than %d which parent interface supports", mtu, maxMTU)
+	}
+	return mtu, nil
+}
+
+// podMTU returns the mtu of pod nic, the mtu of network mode is overridden by the mtu annotation of pod.
+// Overridden mtu larger than the mtu of network mode is allowed but warned, because packets to peers
+// out of this node may be fragmented or dropped.
+func (cdh *cniDaemonHandler) podMTU(pod *corev1.Pod, networkMode networkingv1.NetworkMode, parentIfName string,
+	networkMTU int) (int, error) {
+	if _, exist := pod.Annotations[constants.AnnotationMTU]; !exist {
+		return networkMTU, nil
+	}
+
+	parentLink, err := netlink.LinkByName(parentIfName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get parent interface %v: %v", parentIfName, err)
+	}
+
+	vxlanOverhead := 0
+	if networkMode == networkingv1.NetworkModeVxlan {
+		if vxlanOverhead, err = utils.VxlanOverhead(parentIfName, cdh.config.VtepAddressCIDRs); err != nil {
+			return 0, fmt.Errorf("failed to get vxlan overhead of parent interface %v: %v", parentIfName, err)
+		}
+	}
+
+	mtu, err := podMTUOverride(pod, maxPodMTU(networkMode, parentLink.Attrs().MTU, vxlanOverhead))
+	if err != nil {
+		cdh.recorder.Event(pod, corev1.EventTypeWarning, "InvalidMTU", err.Error())
+		return 0, err
+	}
+
+	if mtu > networkMTU {
+		cdh.recorder.Eventf(pod, corev1.EventTypeWarning, "MTUMayFragment",
+			"mtu %d of annotation is larger than mtu %d of %v network, packets may be fragmented",
+			mtu, networkMTU, networkMode)
+	}
+	return mtu, nil
+}
@@ -0,0 +1,67 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+func TestPodMTUOverride(t *testing.T) {
+	maxMTU := maxPodMTU(networkingv1.NetworkModeVxlan, 9000, utils.VxlanIPv4Overhead)
+	if maxMTU != 8950 {
+		t.Fatalf("expected vxlan overhead excluded from max mtu but got %d", maxMTU)
+	}
+	if mtu := maxPodMTU(networkingv1.NetworkModeVxlan, 9000, utils.VxlanIPv6Overhead); mtu != 8930 {
+		t.Fatalf("expected vxlan overhead of ipv6 underlay excluded from max mtu but got %d", mtu)
+	}
+	if maxPodMTU(networkingv1.NetworkModeVlan, 9000, 0) != 9000 {
+		t.Fatalf("expected max mtu of vlan pods the same as parent interface")
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		mtu         int
+		expectErr   bool
+	}{
+		{"no annotation", nil, 0, false},
+		{"valid", map[string]string{constants.AnnotationMTU: "8950"}, 8950, false},
+		{"larger than parent", map[string]string{constants.AnnotationMTU: "9000"}, 0, true},
+		{"too small", map[string]string{constants.AnnotationMTU: "60"}, 0, true},
+		{"not integer", map[string]string{constants.AnnotationMTU: "jumbo"}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			mtu, err := podMTUOverride(pod, maxMTU)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error %v but got %v", test.expectErr, err)
+			}
+			if mtu != test.mtu {
+				t.Fatalf("expected mtu %d but got %d", test.mtu, mtu)
+			}
+		})
+	}
+}
@@ -26,18 +26,22 @@ import (
 
 	"github.com/alibaba/hybridnet/pkg/daemon/config"
 	"github.com/alibaba/hybridnet/pkg/daemon/controller"
+	"github.com/alibaba/hybridnet/pkg/daemon/crash"
 	"github.com/alibaba/hybridnet/pkg/request"
 
 	"github.com/emicklei/go-restful"
 )
 
 // RunServer runs the cniDaemon http restful server
-func RunServer(ctx context.Context, config *config.Configuration, ctrlRef *controller.CtrlHub, logger logr.Logger) {
+func RunServer(ctx context.Context, config *config.Configuration, ctrlRef *controller.CtrlHub,
+	crashReporter *crash.Reporter, logger logr.Logger) {
 	cdh, err := createCniDaemonHandler(ctx, config, ctrlRef, logger.WithName("daemon-cni-server"))
 	if err != nil {
 		logger.Error(err, "failed to create cni daemon handler", "socket path", config.BindSocket)
 		return
 	}
+	crashReporter.RegisterState("cniCalls", cdh.cniCalls.ActiveCalls)
+
 	server := http.Server{
 		Handler: createHandler(cdh),
 	}
@@ -63,13 +67,22 @@ func createHandler(cdh *cniDaemonHandler) http.Handler {
 		Produces(restful.MIME_JSON)
 	wsContainer.Add(ws)
 
+	containers := newContainerSerializer()
+	addPool := newCNICommandPool(cniCommandAdd, cdh.config.CNIAddWorkers, cdh.config.CNIAddTimeout, containers, cdh.logger)
+	delPool := newCNICommandPool(cniCommandDel, cdh.config.CNIDelWorkers, cdh.config.CNIDelTimeout, containers, cdh.logger)
+	checkPool := newCNICommandPool(cniCommandCheck, cdh.config.CNICheckWorkers, cdh.config.CNICheckTimeout, containers, cdh.logger)
+
 	ws.Route(
 		ws.POST("/add").
-			To(cdh.handleAdd).
+			To(addPool.dispatch(cdh.handleAdd)).
 			Reads(request.PodRequest{}))
 	ws.Route(
 		ws.POST("/del").
-			To(cdh.handleDel).
+			To(delPool.dispatch(cdh.handleDel)).
+			Reads(request.PodRequest{}))
+	ws.Route(
+		ws.POST("/check").
+			To(checkPool.dispatch(cdh.handleCheck)).
 			Reads(request.PodRequest{}))
 
 	return wsContainer
@@ -0,0 +1,126 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/daemon/containernetwork"
+	"github.com/alibaba/hybridnet/pkg/daemon/sriov"
+	"github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+// configureVF assigns a virtual function of the node vlan interface to pod, and returns the
+// name of physical function as host interface
+func (cdh *cniDaemonHandler) configureVF(podName, podNamespace, netns string, macAddr net.HardwareAddr,
+	allocatedIPs map[networkingv1.IPVersion]*utils.IPInfo, network *networkingv1.Network) (string, error) {
+
+	pfName, err := utils.GetVlanParentIfName(cdh.config.NodeVlanIfName, network)
+	if err != nil {
+		return "", fmt.Errorf("failed to get physical function name: %v", err)
+	}
+
+	var vlan int
+	for _, ipInfo := range allocatedIPs {
+		if ipInfo != nil && ipInfo.NetID != nil {
+			vlan = int(*ipInfo.NetID)
+		}
+	}
+
+	index, err := cdh.vfAllocator.Allocate(pfName, podKey(podName, podNamespace), macAddr, vlan)
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate virtual function: %v", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = cdh.deleteVF(podName, podNamespace, netns)
+		}
+	}()
+
+	var vfNetdevName string
+	if vfNetdevName, err = sriov.VFNetdevName(pfName, index); err != nil {
+		return "", err
+	}
+
+	var podNS ns.NetNS
+	if podNS, err = ns.GetNS(netns); err != nil {
+		return "", fmt.Errorf("failed to open netns %q: %v", netns, err)
+	}
+	defer podNS.Close()
+
+	if err = containernetwork.ConfigureContainerVF(vfNetdevName, allocatedIPs, podNS, cdh.config.VlanMTU); err != nil {
+		return "", fmt.Errorf("failed to configure virtual function %d of %s: %v", index, pfName, err)
+	}
+
+	cdh.logger.Info("Virtual function assigned",
+		"podName", podName,
+		"podNamespace", podNamespace,
+		"pf", pfName,
+		"vf", index)
+
+	return pfName, nil
+}
+
+// deleteVF resets the virtual function of pod and returns it to host netns and the pool,
+// nothing will be done if the pod is not assigned with a virtual function
+func (cdh *cniDaemonHandler) deleteVF(podName, podNamespace, netns string) error {
+	var mac net.HardwareAddr
+
+	podNS, err := ns.GetNS(netns)
+	if err == nil {
+		defer podNS.Close()
+
+		if mac, err = containernetwork.GetContainerVFMAC(podNS); err != nil {
+			return fmt.Errorf("failed to get mac of virtual function: %v", err)
+		}
+	}
+
+	pfName, index, found, err := cdh.vfAllocator.Release(podKey(podName, podNamespace), mac)
+	if err != nil {
+		return err
+	}
+	if !found || mac == nil {
+		return nil
+	}
+
+	hostNS, err := ns.GetCurrentNS()
+	if err != nil {
+		return fmt.Errorf("failed to get host namespace: %v", err)
+	}
+	defer hostNS.Close()
+
+	if err = containernetwork.ReturnContainerVF(podNS, hostNS, sriov.GenerateVFNetdevName(pfName, index)); err != nil {
+		return fmt.Errorf("failed to return virtual function %d of %s: %v", index, pfName, err)
+	}
+
+	cdh.logger.Info("Virtual function released",
+		"podName", podName,
+		"podNamespace", podNamespace,
+		"pf", pfName,
+		"vf", index)
+
+	return nil
+}
+
+func podKey(podName, podNamespace string) string {
+	return podNamespace + "/" + podName
+}
@@ -0,0 +1,56 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+// startIPAMRebuildWatchdog watches the rebuild of ipam state, i.e., the caches of ip instances
+// and pods which cni requests are served with. If the rebuild does not finish within timeout,
+// e.g., apiserver keeps returning empty responses, daemon exits so that container runtime can
+// restart it instead of failing to serve cni requests silently. The returned function stops
+// the watchdog once rebuild finishes, and a zero timeout disables the watchdog.
+func startIPAMRebuildWatchdog(timeout time.Duration, logger logr.Logger) func() {
+	if timeout <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+			metrics.IPAMRebuildTimeoutCounter.Inc()
+			logger.Error(fmt.Errorf("ipam rebuild does not finish within %v", timeout), "watchdog timeout, daemon exits")
+			os.Exit(1)
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
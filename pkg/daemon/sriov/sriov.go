@@ -0,0 +1,219 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sriov
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+const (
+	vfNetDirPattern = "/sys/class/net/%s/device/virtfn%d/net"
+
+	// maxIfNameLength is the max length of interface names, which is IFNAMSIZ-1
+	maxIfNameLength = 15
+)
+
+var emptyMAC = net.HardwareAddr{0, 0, 0, 0, 0, 0}
+
+// VFAllocator manages pools of virtual function indexes of physical functions on node, and
+// programs the mac address and vlan tag of virtual functions assigned to pods
+type VFAllocator struct {
+	mutex sync.Mutex
+	pools map[string]*vfPool
+
+	listVFs func(pfName string) ([]netlink.VfInfo, error)
+	setVF   func(pfName string, index int, mac net.HardwareAddr, vlan int) error
+}
+
+type vfPool struct {
+	total int
+	// owners records keys of pods using virtual functions, for a virtual function found in use
+	// when the pool is built, e.g., after daemon restarts, owner is unknown and left empty
+	owners map[int]string
+	// macs records mac addresses of virtual functions in use
+	macs map[int]string
+}
+
+func NewVFAllocator() *VFAllocator {
+	return &VFAllocator{
+		pools:   map[string]*vfPool{},
+		listVFs: listVFs,
+		setVF:   setVF,
+	}
+}
+
+// Allocate assigns a free virtual function of the physical function to owner, and programs
+// its mac address and vlan tag, a zero vlan means no tag. The same virtual function will be
+// returned if owner has been assigned with one already.
+func (a *VFAllocator) Allocate(pfName, owner string, mac net.HardwareAddr, vlan int) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	pool, err := a.getPool(pfName)
+	if err != nil {
+		return 0, err
+	}
+
+	index, found := pool.indexOf(owner, mac)
+	if !found {
+		if index, found = pool.free(); !found {
+			return 0, fmt.Errorf("no free virtual function of %s, %d in total", pfName, pool.total)
+		}
+	}
+
+	if err = a.setVF(pfName, index, mac, vlan); err != nil {
+		return 0, fmt.Errorf("failed to program virtual function %d of %s: %v", index, pfName, err)
+	}
+
+	pool.owners[index] = owner
+	pool.macs[index] = mac.String()
+	updateMetrics(pfName, pool)
+
+	return index, nil
+}
+
+// Release resets the virtual function of owner to default state and returns it to the pool,
+// virtual functions without known owners are matched by mac address
+func (a *VFAllocator) Release(owner string, mac net.HardwareAddr) (pfName string, index int, found bool, err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for pfName, pool := range a.pools {
+		if index, found = pool.indexOf(owner, mac); !found {
+			continue
+		}
+
+		if err = a.setVF(pfName, index, emptyMAC, 0); err != nil {
+			return pfName, index, true, fmt.Errorf("failed to reset virtual function %d of %s: %v", index, pfName, err)
+		}
+
+		delete(pool.owners, index)
+		delete(pool.macs, index)
+		updateMetrics(pfName, pool)
+
+		return pfName, index, true, nil
+	}
+
+	return "", 0, false, nil
+}
+
+func (a *VFAllocator) getPool(pfName string) (*vfPool, error) {
+	if pool, exist := a.pools[pfName]; exist {
+		return pool, nil
+	}
+
+	vfs, err := a.listVFs(pfName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list virtual functions of %s: %v", pfName, err)
+	}
+	if len(vfs) == 0 {
+		return nil, fmt.Errorf("no virtual function is enabled on %s", pfName)
+	}
+
+	pool := &vfPool{
+		total:  len(vfs),
+		owners: map[int]string{},
+		macs:   map[int]string{},
+	}
+	for _, vf := range vfs {
+		if len(vf.Mac) > 0 && vf.Mac.String() != emptyMAC.String() {
+			pool.owners[vf.ID] = ""
+			pool.macs[vf.ID] = vf.Mac.String()
+		}
+	}
+
+	a.pools[pfName] = pool
+	updateMetrics(pfName, pool)
+
+	return pool, nil
+}
+
+func (p *vfPool) indexOf(owner string, mac net.HardwareAddr) (int, bool) {
+	for index, o := range p.owners {
+		if (len(o) > 0 && o == owner) || (len(o) == 0 && p.macs[index] == mac.String()) {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+func (p *vfPool) free() (int, bool) {
+	for index := 0; index < p.total; index++ {
+		if _, used := p.owners[index]; !used {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// VFNetdevName returns the name of net device of virtual function in host netns
+func VFNetdevName(pfName string, index int) (string, error) {
+	entries, err := os.ReadDir(fmt.Sprintf(vfNetDirPattern, pfName, index))
+	if err != nil {
+		return "", fmt.Errorf("failed to read net device of virtual function %d of %s: %v", index, pfName, err)
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no net device of virtual function %d of %s in host netns", index, pfName)
+	}
+	return filepath.Base(entries[0].Name()), nil
+}
+
+// GenerateVFNetdevName generates the name of net device of virtual function when it is
+// returned to host netns
+func GenerateVFNetdevName(pfName string, index int) string {
+	suffix := fmt.Sprintf("v%d", index)
+	if len(pfName)+len(suffix) > maxIfNameLength {
+		pfName = pfName[:maxIfNameLength-len(suffix)]
+	}
+	return pfName + suffix
+}
+
+func listVFs(pfName string) ([]netlink.VfInfo, error) {
+	link, err := netlink.LinkByName(pfName)
+	if err != nil {
+		return nil, err
+	}
+	return link.Attrs().Vfs, nil
+}
+
+func setVF(pfName string, index int, mac net.HardwareAddr, vlan int) error {
+	link, err := netlink.LinkByName(pfName)
+	if err != nil {
+		return err
+	}
+
+	if err = netlink.LinkSetVfHardwareAddr(link, index, mac); err != nil {
+		return fmt.Errorf("failed to set mac %s: %v", mac, err)
+	}
+	if err = netlink.LinkSetVfVlan(link, index, vlan); err != nil {
+		return fmt.Errorf("failed to set vlan %d: %v", vlan, err)
+	}
+	return nil
+}
+
+func updateMetrics(pfName string, pool *vfPool) {
+	metrics.SRIOVVFUsageGauge.WithLabelValues(pfName, metrics.IPTotalUsageType).Set(float64(pool.total))
+	metrics.SRIOVVFUsageGauge.WithLabelValues(pfName, metrics.IPUsedUsageType).Set(float64(len(pool.owners)))
+}
@@ -0,0 +1,96 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package sriov
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+type fakeVF struct {
+	mac  string
+	vlan int
+}
+
+func newFakeAllocator(vfs []netlink.VfInfo) (*VFAllocator, map[int]*fakeVF) {
+	programmed := map[int]*fakeVF{}
+	allocator := NewVFAllocator()
+	allocator.listVFs = func(pfName string) ([]netlink.VfInfo, error) {
+		return vfs, nil
+	}
+	allocator.setVF = func(pfName string, index int, mac net.HardwareAddr, vlan int) error {
+		programmed[index] = &fakeVF{mac: mac.String(), vlan: vlan}
+		return nil
+	}
+	return allocator, programmed
+}
+
+func TestVFAllocator(t *testing.T) {
+	mac1, _ := net.ParseMAC("00:11:22:33:44:01")
+	mac2, _ := net.ParseMAC("00:11:22:33:44:02")
+	mac3, _ := net.ParseMAC("00:11:22:33:44:03")
+
+	// vf 0 has been used before daemon restarts
+	allocator, programmed := newFakeAllocator([]netlink.VfInfo{
+		{ID: 0, Mac: mac1},
+		{ID: 1, Mac: emptyMAC},
+	})
+
+	index, err := allocator.Allocate("eth0", "default/pod2", mac2, 100)
+	if err != nil {
+		t.Fatalf("fail to allocate vf: %v", err)
+	}
+	if index != 1 || programmed[1].mac != mac2.String() || programmed[1].vlan != 100 {
+		t.Errorf("unexpected vf %d programmed as %+v", index, programmed[1])
+	}
+
+	if index, err = allocator.Allocate("eth0", "default/pod2", mac2, 100); err != nil || index != 1 {
+		t.Errorf("allocation of the same owner should return vf 1, but get %d: %v", index, err)
+	}
+
+	if _, err = allocator.Allocate("eth0", "default/pod3", mac3, 100); err == nil {
+		t.Errorf("allocation should fail without free vf")
+	}
+
+	// vf without known owner is released by mac
+	pfName, index, found, err := allocator.Release("default/pod1", mac1)
+	if err != nil || !found || pfName != "eth0" || index != 0 {
+		t.Fatalf("unexpected release result %s %d %v: %v", pfName, index, found, err)
+	}
+	if programmed[0].mac != emptyMAC.String() || programmed[0].vlan != 0 {
+		t.Errorf("vf 0 should be reset, but get %+v", programmed[0])
+	}
+
+	if index, err = allocator.Allocate("eth0", "default/pod3", mac3, 0); err != nil || index != 0 {
+		t.Errorf("allocation should return released vf 0, but get %d: %v", index, err)
+	}
+
+	if _, _, found, _ = allocator.Release("default/pod4", nil); found {
+		t.Errorf("pod without vf should not release anything")
+	}
+}
+
+func TestGenerateVFNetdevName(t *testing.T) {
+	if name := GenerateVFNetdevName("eth0", 3); name != "eth0v3" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if name := GenerateVFNetdevName("enp175s0f0np0", 12); name != "enp175s0f0npv12" {
+		t.Errorf("unexpected truncated name %s", name)
+	}
+}
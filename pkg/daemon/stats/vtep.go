@@ -0,0 +1,129 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package stats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+// sysClassNetPath is where kernel exposes the statistics of interfaces
+var sysClassNetPath = "/sys/class/net"
+
+// VTEPStats is a snapshot of the kernel counters of a vtep interface
+type VTEPStats struct {
+	RxBytes  uint64
+	TxBytes  uint64
+	RxErrors uint64
+	TxDrops  uint64
+}
+
+// CollectVTEPStats reads the counters of vtep interface from sysfs.
+func CollectVTEPStats(iface string) (*VTEPStats, error) {
+	stats := &VTEPStats{}
+	for name, counter := range map[string]*uint64{
+		"rx_bytes":   &stats.RxBytes,
+		"tx_bytes":   &stats.TxBytes,
+		"rx_errors":  &stats.RxErrors,
+		"tx_dropped": &stats.TxDrops,
+	} {
+		value, err := readCounter(iface, name)
+		if err != nil {
+			return nil, err
+		}
+		*counter = value
+	}
+	return stats, nil
+}
+
+func readCounter(iface, name string) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(sysClassNetPath, iface, "statistics", name))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read counter %v of interface %v: %v", name, iface, err)
+	}
+
+	value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse counter %v of interface %v: %v", name, iface, err)
+	}
+	return value, nil
+}
+
+// VTEPStatsRecorder converts the snapshots of kernel counters into prometheus counters, which
+// only increase by the deltas between snapshots.
+type VTEPStatsRecorder struct {
+	last map[string]*VTEPStats
+}
+
+func NewVTEPStatsRecorder() *VTEPStatsRecorder {
+	return &VTEPStatsRecorder{
+		last: map[string]*VTEPStats{},
+	}
+}
+
+// Record collects the stats of vtep interfaces and adds the deltas to metrics, metrics of the
+// interfaces which no longer exist are removed.
+func (r *VTEPStatsRecorder) Record(ifaces []string) error {
+	current := map[string]*VTEPStats{}
+	var errs []string
+	for _, iface := range ifaces {
+		stats, err := CollectVTEPStats(iface)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		current[iface] = stats
+
+		last := r.last[iface]
+		if last == nil {
+			last = &VTEPStats{}
+		}
+		metrics.VtepRxBytesCounter.WithLabelValues(iface).Add(float64(delta(last.RxBytes, stats.RxBytes)))
+		metrics.VtepTxBytesCounter.WithLabelValues(iface).Add(float64(delta(last.TxBytes, stats.TxBytes)))
+		metrics.VtepRxErrorsCounter.WithLabelValues(iface).Add(float64(delta(last.RxErrors, stats.RxErrors)))
+		metrics.VtepTxDropsCounter.WithLabelValues(iface).Add(float64(delta(last.TxDrops, stats.TxDrops)))
+	}
+
+	for iface := range r.last {
+		if _, exist := current[iface]; !exist {
+			metrics.VtepRxBytesCounter.DeleteLabelValues(iface)
+			metrics.VtepTxBytesCounter.DeleteLabelValues(iface)
+			metrics.VtepRxErrorsCounter.DeleteLabelValues(iface)
+			metrics.VtepTxDropsCounter.DeleteLabelValues(iface)
+		}
+	}
+	r.last = current
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to collect vtep stats: %v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// delta returns the increase of a kernel counter, which starts from zero again if the
+// interface is recreated
+func delta(last, current uint64) uint64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
@@ -0,0 +1,104 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+func writeCounters(t *testing.T, root, iface string, counters map[string]string) {
+	dir := filepath.Join(root, iface, "statistics")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range counters {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollectVTEPStats(t *testing.T) {
+	root := t.TempDir()
+	sysClassNetPath = root
+	defer func() { sysClassNetPath = "/sys/class/net" }()
+
+	writeCounters(t, root, "eth0.vxlan4", map[string]string{
+		"rx_bytes":   "100",
+		"tx_bytes":   "200",
+		"rx_errors":  "3",
+		"tx_dropped": "4",
+	})
+
+	stats, err := CollectVTEPStats("eth0.vxlan4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *stats != (VTEPStats{RxBytes: 100, TxBytes: 200, RxErrors: 3, TxDrops: 4}) {
+		t.Errorf("unexpected stats: %+v", *stats)
+	}
+
+	if _, err := CollectVTEPStats("eth1.vxlan4"); err == nil {
+		t.Errorf("expected error for nonexistent interface")
+	}
+}
+
+func TestVTEPStatsRecorder(t *testing.T) {
+	root := t.TempDir()
+	sysClassNetPath = root
+	defer func() { sysClassNetPath = "/sys/class/net" }()
+
+	iface := "eth2.vxlan4"
+	recorder := NewVTEPStatsRecorder()
+
+	writeCounters(t, root, iface, map[string]string{
+		"rx_bytes": "100", "tx_bytes": "200", "rx_errors": "0", "tx_dropped": "0",
+	})
+	if err := recorder.Record([]string{iface}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeCounters(t, root, iface, map[string]string{
+		"rx_bytes": "150", "tx_bytes": "260", "rx_errors": "1", "tx_dropped": "2",
+	})
+	if err := recorder.Record([]string{iface}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.VtepRxBytesCounter.WithLabelValues(iface)); got != 150 {
+		t.Errorf("expected rx bytes 150, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.VtepTxDropsCounter.WithLabelValues(iface)); got != 2 {
+		t.Errorf("expected tx drops 2, got %v", got)
+	}
+
+	// counters restart from zero after the interface is recreated
+	writeCounters(t, root, iface, map[string]string{
+		"rx_bytes": "10", "tx_bytes": "20", "rx_errors": "1", "tx_dropped": "2",
+	})
+	if err := recorder.Record([]string{iface}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.VtepRxBytesCounter.WithLabelValues(iface)); got != 160 {
+		t.Errorf("expected rx bytes 160, got %v", got)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// StripManagedFields is a transform of informer cache which drops the managed fields of objects
+// before they are stored. Daemon never reads them, but every read of cached client deep-copies
+// them, e.g., listing IPInstances of pod on CNI ADD. Updates sent with objects of stripped cache
+// are still safe because apiserver keeps the managed fields if they are unset in the request.
+func StripManagedFields(obj interface{}) (interface{}, error) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	return obj, nil
+}
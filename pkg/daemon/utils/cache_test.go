@@ -0,0 +1,114 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func ipInstanceWithManagedFields() *networkingv1.IPInstance {
+	ipInstance := &networkingv1.IPInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "192-168-0-10",
+			Namespace: "default",
+			Labels: map[string]string{
+				"networking.alibaba.com/node": "node1",
+			},
+		},
+		Spec: networkingv1.IPInstanceSpec{
+			Network: "network1",
+			Subnet:  "subnet1",
+			Address: networkingv1.Address{
+				Version: networkingv1.IPv4,
+				IP:      "192.168.0.10/24",
+				Gateway: "192.168.0.1",
+				MAC:     "00:00:00:00:00:01",
+			},
+		},
+	}
+
+	// managers of IPInstance, e.g., manager, daemon and users of kubectl
+	for i := 0; i < 3; i++ {
+		ipInstance.ManagedFields = append(ipInstance.ManagedFields, metav1.ManagedFieldsEntry{
+			Manager:    fmt.Sprintf("manager-%d", i),
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "networking.alibaba.com/v1",
+			FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{
+				Raw: []byte(`{"f:metadata":{"f:labels":{".":{},"f:networking.alibaba.com/node":{}}},` +
+					`"f:spec":{".":{},"f:address":{".":{},"f:gateway":{},"f:ip":{},"f:mac":{},"f:version":{}},` +
+					`"f:network":{},"f:subnet":{}},"f:status":{".":{},"f:nodeName":{},"f:podName":{},` +
+					`"f:podNamespace":{},"f:sandboxID":{},"f:updateTimestamp":{}}}`),
+			},
+		})
+	}
+	return ipInstance
+}
+
+func TestStripManagedFields(t *testing.T) {
+	ipInstance := ipInstanceWithManagedFields()
+
+	obj, err := StripManagedFields(ipInstance)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stripped := obj.(*networkingv1.IPInstance)
+	if stripped.ManagedFields != nil {
+		t.Fatalf("expected managed fields stripped but got %v", stripped.ManagedFields)
+	}
+	if stripped.Spec.Address.IP != "192.168.0.10/24" || stripped.Labels["networking.alibaba.com/node"] != "node1" {
+		t.Fatalf("expected other fields kept but got %v", stripped)
+	}
+
+	// tombstones of deleted objects should be passed through
+	tombstone := cache.DeletedFinalStateUnknown{Key: "default/192-168-0-10"}
+	if obj, err = StripManagedFields(tombstone); err != nil || obj != tombstone {
+		t.Fatalf("expected tombstone passed through but got %v, %v", obj, err)
+	}
+}
+
+// BenchmarkIPInstanceRead measures the deep copy of every read from cached client, and reports
+// the encoded size of IPInstance with and without managed fields
+func BenchmarkIPInstanceRead(b *testing.B) {
+	full := ipInstanceWithManagedFields()
+	stripped := full.DeepCopy()
+	_, _ = StripManagedFields(stripped)
+
+	for _, bc := range []struct {
+		name       string
+		ipInstance *networkingv1.IPInstance
+	}{
+		{name: "full", ipInstance: full},
+		{name: "stripped", ipInstance: stripped},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			content, _ := json.Marshal(bc.ipInstance)
+			b.ReportAllocs()
+			b.ReportMetric(float64(len(content)), "bytes/object")
+			for i := 0; i < b.N; i++ {
+				_ = bc.ipInstance.DeepCopy()
+			}
+		})
+	}
+}
@@ -23,8 +23,10 @@ import (
 	"os"
 	"strings"
 
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 	"github.com/alibaba/hybridnet/pkg/constants"
 
+	"github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/cni/pkg/types/current"
 	"github.com/containernetworking/plugins/pkg/ip"
 	"github.com/containernetworking/plugins/pkg/utils/sysctl"
@@ -33,11 +35,21 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
+const (
+	// VxlanIPv4Overhead and VxlanIPv6Overhead are the overheads of vxlan encapsulation over ipv4 and ipv6
+	// underlay, including outer ip, udp, vxlan and inner ethernet headers
+	VxlanIPv4Overhead = 50
+	VxlanIPv6Overhead = 70
+)
+
 type IPInfo struct {
 	Addr  net.IP
 	Gw    net.IP
 	Cidr  *net.IPNet
 	NetID *int32
+
+	// Routes are additional routes of subnet injected into pod
+	Routes []*types.Route
 }
 
 func GenerateVlanNetIfName(parentName string, vlanID *int32) (string, error) {
@@ -45,7 +57,7 @@ func GenerateVlanNetIfName(parentName string, vlanID *int32) (string, error) {
 		return "", fmt.Errorf("vlan id should not be nil")
 	}
 
-	if *vlanID > 4094 {
+	if *vlanID < 0 || *vlanID > 4094 {
 		return "", fmt.Errorf("vlan id's value range is from 0 to 4094")
 	}
 
@@ -56,6 +68,26 @@ func GenerateVlanNetIfName(parentName string, vlanID *int32) (string, error) {
 	return fmt.Sprintf("%s.%v", parentName, *vlanID), nil
 }
 
+// GenerateQinQServiceIfName returns the name of 802.1ad sub-interface which carries the
+// outer vlan tag (S-tag), it is distinguished from 802.1q sub-interfaces by a "q" prefix
+func GenerateQinQServiceIfName(parentName string, serviceVlanID *int32) (string, error) {
+	if serviceVlanID == nil || *serviceVlanID <= 0 || *serviceVlanID > 4094 {
+		return "", fmt.Errorf("service vlan id's value range is from 1 to 4094")
+	}
+
+	return fmt.Sprintf("%s.q%v", parentName, *serviceVlanID), nil
+}
+
+// GetVlanParentIfName returns the interface on which vlan sub-interfaces of network are created,
+// it is the 802.1ad sub-interface of node vlan interface for QinQ network
+func GetVlanParentIfName(nodeVlanIfName string, network *networkingv1.Network) (string, error) {
+	if !networkingv1.IsQinQNetwork(network) {
+		return nodeVlanIfName, nil
+	}
+
+	return GenerateQinQServiceIfName(nodeVlanIfName, network.Spec.ServiceVlanID)
+}
+
 func GenerateVxlanNetIfName(parentName string, vlanID *int32) (string, error) {
 	if vlanID == nil || *vlanID == 0 {
 		return "", fmt.Errorf("vxlan id should not be nil or zero")
@@ -114,6 +146,51 @@ func EnsureVlanIf(nodeIfName string, vlanID *int32) (string, error) {
 	return vlanIfName, nil
 }
 
+// EnsureQinQServiceIf ensures the 802.1ad sub-interface with outer vlan tag on node interface,
+// 802.1q sub-interfaces created on it will send double-tagged frames
+func EnsureQinQServiceIf(nodeIfName string, serviceVlanID *int32) (string, error) {
+	nodeIf, err := netlink.LinkByName(nodeIfName)
+	if err != nil {
+		return "", err
+	}
+
+	serviceIfName, err := GenerateQinQServiceIfName(nodeIfName, serviceVlanID)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate qinq service interface name: %v", err)
+	}
+
+	if len(serviceIfName) >= unix.IFNAMSIZ {
+		return "", fmt.Errorf("qinq service interface name %v is too long", serviceIfName)
+	}
+
+	var serviceIf netlink.Link
+	if serviceIf, err = netlink.LinkByName(serviceIfName); err != nil {
+		vif := &netlink.Vlan{
+			VlanId:       int(*serviceVlanID),
+			VlanProtocol: netlink.VLAN_PROTOCOL_8021AD,
+			LinkAttrs:    netlink.NewLinkAttrs(),
+		}
+		vif.ParentIndex = nodeIf.Attrs().Index
+		vif.Name = serviceIfName
+
+		if err = netlink.LinkAdd(vif); err != nil {
+			return serviceIfName, err
+		}
+
+		if serviceIf, err = netlink.LinkByName(serviceIfName); err != nil {
+			return serviceIfName, err
+		}
+	} else if vlanIf, ok := serviceIf.(*netlink.Vlan); !ok || vlanIf.VlanProtocol != netlink.VLAN_PROTOCOL_8021AD {
+		return serviceIfName, fmt.Errorf("interface %v exists but is not an 802.1ad vlan interface", serviceIfName)
+	}
+
+	if err = netlink.LinkSetUp(serviceIf); err != nil {
+		return serviceIfName, err
+	}
+
+	return serviceIfName, nil
+}
+
 func GetDefaultInterface(family int) (*net.Interface, error) {
 	defaultRoute, err := GetDefaultRoute(family)
 	if err != nil {
@@ -317,6 +394,39 @@ func AddRoute(ipn *net.IPNet, gw net.IP, dev netlink.Link) error {
 	})
 }
 
+// GetVRF returns the VRF device named vrfName.
+func GetVRF(vrfName string) (*netlink.Vrf, error) {
+	vrfLink, err := netlink.LinkByName(vrfName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vrf %v: %v", vrfName, err)
+	}
+
+	vrf, ok := vrfLink.(*netlink.Vrf)
+	if !ok {
+		return nil, fmt.Errorf("link %v is not a vrf device but %v", vrfName, vrfLink.Type())
+	}
+	return vrf, nil
+}
+
+// EnsureLinkInVRF attaches link to the VRF device named vrfName if not yet, and returns the VRF device.
+// Kernel flushes routes through link while attaching, whether link is newly attached is returned so
+// that callers can install the routes again.
+func EnsureLinkInVRF(link netlink.Link, vrfName string) (*netlink.Vrf, bool, error) {
+	vrf, err := GetVRF(vrfName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if link.Attrs().MasterIndex == vrf.Index {
+		return vrf, false, nil
+	}
+
+	if err = netlink.LinkSetMaster(link, vrf); err != nil {
+		return nil, false, fmt.Errorf("failed to attach link %v to vrf %v: %v", link.Attrs().Name, vrfName, err)
+	}
+	return vrf, true, nil
+}
+
 func EnableIPForward(family int) error {
 	if family == netlink.FAMILY_V4 {
 		return ip.EnableIP4Forward()
@@ -650,3 +760,30 @@ func EnsureRpFilter(containerIfs ...string) error {
 
 	return nil
 }
+
+// VxlanOverhead returns the overhead of vxlan encapsulation through the parent interface. Underlay family
+// is decided by the first global unicast address of parent in vtepCIDRs, which is how vtep address is
+// selected, and ipv4 is assumed if no address matches.
+func VxlanOverhead(parentIfName string, vtepCIDRs []*net.IPNet) (int, error) {
+	link, err := netlink.LinkByName(parentIfName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get link %v: %v", parentIfName, err)
+	}
+
+	addrList, err := ListAllGlobalUnicastAddress(link)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, addr := range addrList {
+		for _, cidr := range vtepCIDRs {
+			if cidr.Contains(addr.IP) {
+				if addr.IP.To4() == nil {
+					return VxlanIPv6Overhead, nil
+				}
+				return VxlanIPv4Overhead, nil
+			}
+		}
+	}
+	return VxlanIPv4Overhead, nil
+}
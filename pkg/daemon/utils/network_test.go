@@ -0,0 +1,152 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containernetworking/plugins/pkg/testutils"
+	"github.com/vishvananda/netlink"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestGetVlanParentIfName(t *testing.T) {
+	serviceVlanID := int32(100)
+	invalidServiceVlanID := int32(4095)
+
+	tests := []struct {
+		name      string
+		network   *networkingv1.Network
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "plain vlan network",
+			network:  &networkingv1.Network{},
+			expected: "eth0",
+		},
+		{
+			name: "qinq network",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Encapsulation: networkingv1.NetworkEncapsulationQinQ,
+				ServiceVlanID: &serviceVlanID,
+			}},
+			expected: "eth0.q100",
+		},
+		{
+			name: "qinq network without service vlan id",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Encapsulation: networkingv1.NetworkEncapsulationQinQ,
+			}},
+			expectErr: true,
+		},
+		{
+			name: "qinq network with invalid service vlan id",
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				Encapsulation: networkingv1.NetworkEncapsulationQinQ,
+				ServiceVlanID: &invalidServiceVlanID,
+			}},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ifName, err := GetVlanParentIfName("eth0", test.network)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected error but got interface %s", ifName)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if ifName != test.expected {
+				t.Errorf("expected interface %s but got %s", test.expected, ifName)
+			}
+		})
+	}
+}
+
+func TestEnsureLinkInVRF(t *testing.T) {
+	testNS, err := testutils.NewNS()
+	if err != nil {
+		t.Skipf("skip for netns is not available: %v", err)
+	}
+	defer func() {
+		_ = testNS.Close()
+		_ = testutils.UnmountNS(testNS)
+	}()
+
+	var vrfUnsupported error
+	if err := testNS.Do(func(_ ns.NetNS) error {
+		lo, err := netlink.LinkByName("lo")
+		if err != nil {
+			return fmt.Errorf("fail to get lo: %v", err)
+		}
+
+		if _, _, err := EnsureLinkInVRF(lo, "vrf-missing"); err == nil {
+			t.Errorf("expect error for missing vrf")
+		}
+		if _, _, err := EnsureLinkInVRF(lo, "lo"); err == nil {
+			t.Errorf("expect error for link which is not a vrf device")
+		}
+
+		if vrfUnsupported = netlink.LinkAdd(&netlink.Vrf{LinkAttrs: netlink.LinkAttrs{Name: "vrf-test"}, Table: 1234}); vrfUnsupported != nil {
+			return nil
+		}
+		if err := netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "veth-test"}, PeerName: "veth-peer"}); err != nil {
+			return fmt.Errorf("fail to add veth: %v", err)
+		}
+
+		link, err := netlink.LinkByName("veth-test")
+		if err != nil {
+			return fmt.Errorf("fail to get veth: %v", err)
+		}
+
+		vrf, attached, err := EnsureLinkInVRF(link, "vrf-test")
+		if err != nil {
+			return fmt.Errorf("fail to attach link to vrf: %v", err)
+		}
+		if !attached || vrf.Table != 1234 {
+			t.Errorf("expect link newly attached to vrf with table 1234 but got attached %v and table %v", attached, vrf.Table)
+		}
+
+		if link, err = netlink.LinkByName("veth-test"); err != nil {
+			return fmt.Errorf("fail to get veth: %v", err)
+		}
+		if link.Attrs().MasterIndex != vrf.Index {
+			t.Errorf("expect master of link %v but got %v", vrf.Index, link.Attrs().MasterIndex)
+		}
+
+		if _, attached, err = EnsureLinkInVRF(link, "vrf-test"); err != nil || attached {
+			t.Errorf("expect link already in vrf not attached again but got attached %v and error %v", attached, err)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if vrfUnsupported != nil {
+		t.Skipf("skip attaching for vrf is not supported: %v", vrfUnsupported)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vxlan
+
+import (
+	"fmt"
+
+	"github.com/safchain/ethtool"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+const (
+	txUDPTunnelChecksumFeature = "tx-udp_tnl-csum-segmentation"
+	rxUDPTunnelGROFeature      = "rx-udp_tnl-gro"
+)
+
+var udpTunnelChecksumOffloadFeatures = []string{
+	txUDPTunnelChecksumFeature,
+	rxUDPTunnelGROFeature,
+}
+
+// EnsureChecksumOffload configures udp tunnel checksum offload features of the parent interface
+// which vxlan traffic is encapsulated through according to mode, and returns whether checksums of vxlan traffic fall back to software.
+// For Auto mode, features are enabled only if the interface supports them, and it is a fallback
+// if tx checksum offload is not supported; for Enabled mode, it is a fallback if any of the
+// features can not be enabled; for Disabled mode, all the supported features are disabled.
+func EnsureChecksumOffload(ifName string, mode networkingv1.ChecksumOffloadMode) (bool, error) {
+	e, err := ethtool.NewEthtool()
+	if err != nil {
+		return false, fmt.Errorf("failed to init ethtool: %v", err)
+	}
+	defer e.Close()
+
+	features, err := e.Features(ifName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get features of %v: %v", ifName, err)
+	}
+
+	enabled := mode != networkingv1.ChecksumOffloadModeDisabled
+	changes := map[string]bool{}
+	for _, feature := range udpTunnelChecksumOffloadFeatures {
+		if active, supported := features[feature]; supported && active != enabled {
+			changes[feature] = enabled
+		}
+	}
+
+	if len(changes) != 0 {
+		// features which are fixed by driver will be checked after changing
+		_ = e.Change(ifName, changes)
+
+		if features, err = e.Features(ifName); err != nil {
+			return false, fmt.Errorf("failed to get features of %v: %v", ifName, err)
+		}
+	}
+
+	switch mode {
+	case networkingv1.ChecksumOffloadModeDisabled:
+		return false, nil
+	case networkingv1.ChecksumOffloadModeEnabled:
+		for _, feature := range udpTunnelChecksumOffloadFeatures {
+			if !features[feature] {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return !features[txUDPTunnelChecksumFeature], nil
+	}
+}
@@ -33,8 +33,12 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
+// defaultMcastSolicit is the kernel default of neigh mcast_solicit.
+const defaultMcastSolicit = 3
+
 var (
 	broadcastFdbMac, _ = net.ParseMAC("FF:FF:FF:FF:FF:F1")
+	zeroFdbMac, _      = net.ParseMAC("00:00:00:00:00:00")
 )
 
 type Device struct {
@@ -42,10 +46,13 @@ type Device struct {
 
 	// remote vtep ip and mac address it should be forward to.
 	remoteIPToMacMap map[string]net.HardwareAddr
+
+	// multicast group which BUM traffic of this vni is flooded to, nil means not configured.
+	multicastGroup net.IP
 }
 
 func NewVxlanDevice(name string, vxlanID int, parent string, localAddr net.IP, port int, baseReachableTime time.Duration,
-	learning bool) (*Device, error) {
+	learning, arpSuppression bool) (*Device, error) {
 	parentLink, err := netlink.LinkByName(parent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent link %v: %v", parent, err)
@@ -75,6 +82,18 @@ func NewVxlanDevice(name string, vxlanID int, parent string, localAddr net.IP, p
 		return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
 	}
 
+	// With arp suppression, no multicast solicitation is flooded through vxlan tunnels and
+	// neigh requests are only resolved by daemon through app_solicit from local caches.
+	mcastSolicit := defaultMcastSolicit
+	if arpSuppression {
+		mcastSolicit = 0
+	}
+
+	sysctlPath = fmt.Sprintf(constants.IPv4McastSolicitSysctl, link.Name)
+	if err := daemonutils.SetSysctlIgnoreNotExist(sysctlPath, mcastSolicit); err != nil {
+		return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
+	}
+
 	sysctlPath = fmt.Sprintf(constants.IPv4BaseReachableTimeMSSysctl, link.Name)
 	if err := daemonutils.SetSysctlIgnoreNotExist(sysctlPath, int(1000*baseReachableTime.Seconds())); err != nil {
 		return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
@@ -91,6 +110,11 @@ func NewVxlanDevice(name string, vxlanID int, parent string, localAddr net.IP, p
 			return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
 		}
 
+		sysctlPath = fmt.Sprintf(constants.IPv6McastSolicitSysctl, link.Name)
+		if err := daemonutils.SetSysctlIgnoreNotExist(sysctlPath, mcastSolicit); err != nil {
+			return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
+		}
+
 		sysctlPath = fmt.Sprintf(constants.IPv6BaseReachableTimeMSSysctl, link.Name)
 		if err := daemonutils.SetSysctlIgnoreNotExist(sysctlPath, int(1000*baseReachableTime.Seconds())); err != nil {
 			return nil, fmt.Errorf("failed to set sysctl parameter %v: %v", sysctlPath, err)
@@ -127,7 +151,30 @@ func (dev *Device) RecordVtepInfo(vtepMac net.HardwareAddr, vtepIP net.IP) {
 	}
 }
 
+// RecordMulticastGroup records the multicast group for BUM flooding of this vni, nil group means
+// the multicast fdb entry should be removed.
+func (dev *Device) RecordMulticastGroup(group net.IP) {
+	dev.multicastGroup = group
+}
+
 func (dev *Device) SyncVtepInfo(execDel bool) error {
+	if dev.multicastGroup != nil {
+		// Same as "bridge fdb append 00:00:00:00:00:00 dev <vtep> dst <multicast group>".
+		multicastFdbEntry := netlink.Neigh{
+			LinkIndex:    dev.link.Index,
+			Family:       syscall.AF_BRIDGE,
+			State:        netlink.NUD_PERMANENT,
+			Flags:        netlink.NTF_SELF,
+			IP:           dev.multicastGroup,
+			HardwareAddr: zeroFdbMac,
+		}
+
+		// Duplicate append action will not case error.
+		if err := netlink.NeighAppend(&multicastFdbEntry); err != nil {
+			return fmt.Errorf("failed to append multicast fdb entry %v for interface %v: %v", multicastFdbEntry.String(), dev.link.Name, err)
+		}
+	}
+
 	for remoteIPString, macAddr := range dev.remoteIPToMacMap {
 		unicastFdbEntry := netlink.Neigh{
 			LinkIndex:    dev.link.Index,
@@ -165,6 +212,10 @@ func (dev *Device) SyncVtepInfo(execDel bool) error {
 
 	if execDel {
 		for _, entry := range fdbEntryList {
+			if dev.isMulticastFdbEntry(&entry) {
+				continue
+			}
+
 			// Delete invalid entries.
 			if vtepMac, exist := dev.remoteIPToMacMap[entry.IP.String()]; !exist ||
 				(vtepMac.String() != entry.HardwareAddr.String() &&
@@ -180,6 +231,11 @@ func (dev *Device) SyncVtepInfo(execDel bool) error {
 	return nil
 }
 
+func (dev *Device) isMulticastFdbEntry(entry *netlink.Neigh) bool {
+	return dev.multicastGroup != nil && dev.multicastGroup.Equal(entry.IP) &&
+		entry.HardwareAddr.String() == zeroFdbMac.String()
+}
+
 func ensureLink(vxlan *netlink.Vxlan) (*netlink.Vxlan, error) {
 	err := netlink.LinkAdd(vxlan)
 	if err == syscall.EEXIST {
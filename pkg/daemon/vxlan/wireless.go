@@ -0,0 +1,85 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vxlan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/safchain/ethtool"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// encapTypeIEEE80211 is the netlink encap type string of ARPHRD_IEEE80211 links.
+	encapTypeIEEE80211 = "ieee802.11"
+
+	groFeature = "rx-gro"
+
+	// WirelessReachableTimeFactor is the multiplier applied to neigh base reachable time
+	// of vxlan devices whose parent is wireless, for frames are lost more easily over the air.
+	WirelessReachableTimeFactor = 2
+)
+
+const sysClassNetPath = "/sys/class/net"
+
+// IsWirelessInterface checks if an interface is an 802.11 wireless one. Besides the
+// ARPHRD_IEEE80211 hardware type, interfaces of most cfg80211 drivers in station mode
+// report an ethernet hardware type, so the sysfs wireless entries are also checked.
+func IsWirelessInterface(ifName string) (bool, error) {
+	link, err := netlink.LinkByName(ifName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get link %v: %v", ifName, err)
+	}
+
+	if link.Attrs().EncapType == encapTypeIEEE80211 {
+		return true, nil
+	}
+
+	for _, entry := range []string{"wireless", "phy80211"} {
+		if _, err := os.Stat(filepath.Join(sysClassNetPath, ifName, entry)); err == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// DisableGRO disables generic receive offload of an interface, which causes issues
+// on vxlan devices over some 802.11 drivers.
+func DisableGRO(ifName string) error {
+	e, err := ethtool.NewEthtool()
+	if err != nil {
+		return fmt.Errorf("failed to init ethtool: %v", err)
+	}
+	defer e.Close()
+
+	features, err := e.Features(ifName)
+	if err != nil {
+		return fmt.Errorf("failed to get features of %v: %v", ifName, err)
+	}
+
+	if active, supported := features[groFeature]; !supported || !active {
+		return nil
+	}
+
+	if err := e.Change(ifName, map[string]bool{groFeature: false}); err != nil {
+		return fmt.Errorf("failed to disable gro of %v: %v", ifName, err)
+	}
+	return nil
+}
@@ -45,6 +45,14 @@ const (
 	MultiCluster featuregate.Feature = "MultiCluster"
 
 	VMIPRetain featuregate.Feature = "VMIPRetain"
+
+	// Enable recording the timeline of IP allocation phases on pod annotation.
+
+	PodIPAllocationTimeline featuregate.Feature = "PodIPAllocationTimeline"
+
+	// Enable registering DNS records of pods with underlay IPs through external-dns.
+
+	UnderlayDNSRegistration featuregate.Feature = "UnderlayDNSRegistration"
 )
 
 var DefaultHybridnetFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -56,6 +64,14 @@ var DefaultHybridnetFeatureGates = map[featuregate.Feature]featuregate.FeatureSp
 		Default:    false,
 		PreRelease: featuregate.Alpha,
 	},
+	PodIPAllocationTimeline: {
+		Default:    false,
+		PreRelease: featuregate.Alpha,
+	},
+	UnderlayDNSRegistration: {
+		Default:    false,
+		PreRelease: featuregate.Alpha,
+	},
 }
 
 func MultiClusterEnabled() bool {
@@ -66,6 +82,14 @@ func VMIPRetainEnabled() bool {
 	return feature.DefaultMutableFeatureGate.Enabled(VMIPRetain)
 }
 
+func PodIPAllocationTimelineEnabled() bool {
+	return feature.DefaultMutableFeatureGate.Enabled(PodIPAllocationTimeline)
+}
+
+func UnderlayDNSRegistrationEnabled() bool {
+	return feature.DefaultMutableFeatureGate.Enabled(UnderlayDNSRegistration)
+}
+
 func KnownFeatures() []string {
 	return feature.DefaultMutableFeatureGate.KnownFeatures()
 }
@@ -0,0 +1,99 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package allocator
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/alibaba/hybridnet/pkg/ipam"
+)
+
+// benchmarkCIDR is a /12 subnet of about one million IPs
+const benchmarkCIDR = "10.0.0.0/12"
+
+// newFullAllocator returns an allocator whose IPs are all allocated, so that every allocation
+// after a random release has to search from the start of pool
+func newFullAllocator(b *testing.B, newAllocator func(cidrs []string) (ipam.IPAMAllocator, error)) (ipam.IPAMAllocator, []net.IP) {
+	a, err := newAllocator([]string{benchmarkCIDR})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var ips []net.IP
+	for {
+		ip, err := a.Allocate()
+		if err != nil {
+			break
+		}
+		ips = append(ips, ip)
+	}
+	return a, ips
+}
+
+func benchmarkAllocate(b *testing.B, newAllocator func(cidrs []string) (ipam.IPAMAllocator, error)) {
+	a, ips := newFullAllocator(b, newAllocator)
+	r := rand.New(rand.NewSource(1))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.Release(ips[r.Intn(len(ips))])
+		if _, err := a.Allocate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkAllocateParallel serializes allocations with a mutex, like the ipam manager does
+func benchmarkAllocateParallel(b *testing.B, newAllocator func(cidrs []string) (ipam.IPAMAllocator, error)) {
+	a, ips := newFullAllocator(b, newAllocator)
+	var mu sync.Mutex
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			ip := ips[r.Intn(len(ips))]
+			mu.Lock()
+			a.Release(ip)
+			_, err := a.Allocate()
+			mu.Unlock()
+			if err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkBitmapAllocate(b *testing.B) {
+	benchmarkAllocate(b, NewBitmapAllocator)
+}
+
+func BenchmarkRadixAllocate(b *testing.B) {
+	benchmarkAllocate(b, NewRadixAllocator)
+}
+
+func BenchmarkBitmapAllocateParallel(b *testing.B) {
+	benchmarkAllocateParallel(b, NewBitmapAllocator)
+}
+
+func BenchmarkRadixAllocateParallel(b *testing.B) {
+	benchmarkAllocateParallel(b, NewRadixAllocator)
+}
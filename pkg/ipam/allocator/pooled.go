@@ -0,0 +1,128 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package allocator
+
+import (
+	"net"
+
+	"github.com/alibaba/hybridnet/pkg/ipam"
+	"github.com/alibaba/hybridnet/pkg/ipam/types"
+)
+
+// RadixThreshold is the pool size from which radix bitmap is used by default, searching
+// in sparse bitmap is faster for small pools but grows linearly with the number of pages
+const RadixThreshold = 1 << 16
+
+// indexSet is the set of used indexes of pool, implemented by SparseBitmap and RadixBitmap
+type indexSet interface {
+	Test(index int) bool
+	Set(index int)
+	Clear(index int)
+	NextClear(index int) int
+	Count() int
+}
+
+// PooledAllocator allocates IPs from a pool of non-contiguous CIDRs, every CIDR will be
+// filled in order before moving to the next one
+type PooledAllocator struct {
+	pool               *types.Pool
+	bitmap             indexSet
+	lastAllocatedIndex int
+}
+
+// NewPooledAllocator creates an allocator whose used indexes are kept in a sparse bitmap,
+// or a radix bitmap if the pool contains not less than RadixThreshold IPs
+func NewPooledAllocator(cidrs []string) (ipam.IPAMAllocator, error) {
+	pool, err := types.NewPool(cidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool.Size() >= RadixThreshold {
+		return newPooledAllocator(pool, types.NewRadixBitmap(pool.Size())), nil
+	}
+	return newPooledAllocator(pool, types.NewSparseBitmap(pool.Size())), nil
+}
+
+// NewBitmapAllocator creates an allocator whose used indexes are kept in a sparse bitmap
+func NewBitmapAllocator(cidrs []string) (ipam.IPAMAllocator, error) {
+	pool, err := types.NewPool(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	return newPooledAllocator(pool, types.NewSparseBitmap(pool.Size())), nil
+}
+
+// NewRadixAllocator creates an allocator whose used indexes are kept in a radix bitmap
+func NewRadixAllocator(cidrs []string) (ipam.IPAMAllocator, error) {
+	pool, err := types.NewPool(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	return newPooledAllocator(pool, types.NewRadixBitmap(pool.Size())), nil
+}
+
+func newPooledAllocator(pool *types.Pool, bitmap indexSet) *PooledAllocator {
+	return &PooledAllocator{
+		pool:               pool,
+		bitmap:             bitmap,
+		lastAllocatedIndex: -1,
+	}
+}
+
+// Allocate picks the next free IP after the last allocated one, and wraps around if the
+// last CIDR is exhausted
+func (a *PooledAllocator) Allocate() (net.IP, error) {
+	index := a.bitmap.NextClear(a.lastAllocatedIndex + 1)
+	if index < 0 {
+		index = a.bitmap.NextClear(0)
+	}
+	if index < 0 {
+		return nil, types.ErrSubnetExhausted
+	}
+
+	a.bitmap.Set(index)
+	a.lastAllocatedIndex = index
+	return a.pool.IPAt(index), nil
+}
+
+func (a *PooledAllocator) Assign(ip net.IP) error {
+	index := a.pool.IndexOf(ip)
+	switch {
+	case index < 0:
+		return types.ErrNotFoundAssignedIP
+	case a.bitmap.Test(index):
+		return types.ErrNotAvailableAssignedIP
+	}
+
+	a.bitmap.Set(index)
+	return nil
+}
+
+func (a *PooledAllocator) Release(ip net.IP) {
+	if index := a.pool.IndexOf(ip); index >= 0 {
+		a.bitmap.Clear(index)
+	}
+}
+
+func (a *PooledAllocator) Contains(ip net.IP) bool {
+	return a.pool.Contains(ip)
+}
+
+func (a *PooledAllocator) Usage() (total, used int) {
+	return a.pool.Size(), a.bitmap.Count()
+}
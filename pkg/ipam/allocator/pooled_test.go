@@ -0,0 +1,107 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package allocator
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/alibaba/hybridnet/pkg/ipam/types"
+)
+
+func TestPooledAllocatorFillsCIDRsInOrder(t *testing.T) {
+	a, err := NewPooledAllocator([]string{"10.0.2.0/30", "10.0.0.0/30"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"10.0.2.1", "10.0.2.2", "10.0.0.1", "10.0.0.2"}
+	for _, e := range expected {
+		ip, err := a.Allocate()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ip.Equal(net.ParseIP(e)) {
+			t.Fatalf("expected %s, got %s", e, ip)
+		}
+	}
+
+	if _, err = a.Allocate(); !errors.Is(err, types.ErrSubnetExhausted) {
+		t.Fatalf("expected exhausted error, got %v", err)
+	}
+
+	// released ip will be reused after wrapping around
+	a.Release(net.ParseIP("10.0.2.2"))
+	if ip, _ := a.Allocate(); !ip.Equal(net.ParseIP("10.0.2.2")) {
+		t.Fatalf("expected released ip to be reused, got %v", ip)
+	}
+
+	if total, used := a.Usage(); total != 4 || used != 4 {
+		t.Fatalf("unexpected usage %d/%d", used, total)
+	}
+}
+
+func TestPooledAllocatorAssign(t *testing.T) {
+	a, err := NewPooledAllocator([]string{"fd00:0:0:1::/126", "fd00:0:0:3::/126"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = a.Assign(net.ParseIP("fd00:0:0:1::1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = a.Assign(net.ParseIP("fd00:0:0:1::1")); !errors.Is(err, types.ErrNotAvailableAssignedIP) {
+		t.Fatalf("expected not available error, got %v", err)
+	}
+	if err = a.Assign(net.ParseIP("fd00:0:0:2::1")); !errors.Is(err, types.ErrNotFoundAssignedIP) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if ip, _ := a.Allocate(); !ip.Equal(net.ParseIP("fd00:0:0:1::2")) {
+		t.Fatalf("expected next ip of assigned one, got %v", ip)
+	}
+	if !a.Contains(net.ParseIP("fd00:0:0:3::3")) || a.Contains(net.ParseIP("fd00:0:0:3::")) {
+		t.Fatalf("unexpected result of contains")
+	}
+}
+
+func TestNewPooledAllocatorSelectsBitmap(t *testing.T) {
+	small, err := NewPooledAllocator([]string{"10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := small.(*PooledAllocator).bitmap.(*types.SparseBitmap); !ok {
+		t.Fatalf("expected sparse bitmap for small pool")
+	}
+
+	large, err := NewPooledAllocator([]string{"10.0.0.0/16", "10.2.0.0/24"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := large.(*PooledAllocator).bitmap.(*types.RadixBitmap); !ok {
+		t.Fatalf("expected radix bitmap for large pool")
+	}
+
+	// allocation results do not depend on bitmap implementation
+	radix, _ := NewRadixAllocator([]string{"10.0.2.0/30", "10.0.0.0/30"})
+	for _, e := range []string{"10.0.2.1", "10.0.2.2", "10.0.0.1", "10.0.0.2"} {
+		if ip, _ := radix.Allocate(); !ip.Equal(net.ParseIP(e)) {
+			t.Fatalf("expected %s, got %v", e, ip)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package ipam
 
 import (
 	"context"
+	"net"
 
 	v1 "k8s.io/api/core/v1"
 
@@ -29,11 +30,14 @@ type Manager interface {
 
 	GetNetworkUsage(networkName string) (*types.NetworkUsage, error)
 	GetSubnetUsage(networkName, subnetName string) (*types.Usage, error)
+	GetSubnetAllocatedIPs(networkName, subnetName string) ([]string, error)
 
-	Allocate(networkName string, podInfo types.PodInfo, options ...types.AllocateOption) (allocatedIPs []*types.IP, err error)
+	Allocate(ctx context.Context, networkName string, podInfo types.PodInfo, options ...types.AllocateOption) (allocatedIPs []*types.IP, err error)
 	Assign(networkName string, podInfo types.PodInfo, assignedSuites []types.SubnetIPSuite, options ...types.AssignOption) (assignedIPs []*types.IP, err error)
-	Release(networkName string, releaseSuites []types.SubnetIPSuite) (err error)
+	Release(ctx context.Context, networkName string, releaseSuites []types.SubnetIPSuite) (err error)
 	Reserve(networkName string, reserveSuites []types.SubnetIPSuite) (err error)
+
+	CheckConsistency() (driftedSubnets map[string][]string, err error)
 }
 
 type Store interface {
@@ -44,3 +48,12 @@ type Store interface {
 	IPRecycle(ctx context.Context, namespace string, ip *types.IP) (err error)
 	IPUnBind(ctx context.Context, namespace, ip string) (err error)
 }
+
+// IPAMAllocator allocates IPs from an address space without any pod or subnet information
+type IPAMAllocator interface {
+	Allocate() (net.IP, error)
+	Assign(ip net.IP) error
+	Release(ip net.IP)
+	Contains(ip net.IP) bool
+	Usage() (total, used int)
+}
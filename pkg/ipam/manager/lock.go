@@ -0,0 +1,90 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
+)
+
+// tracedRWMutex is a sync.RWMutex recording the time waiting for lock and whether lock is
+// contended, which means it can not be acquired immediately
+type tracedRWMutex struct {
+	sync.RWMutex
+}
+
+func (t *tracedRWMutex) Lock() {
+	if t.RWMutex.TryLock() {
+		observeLockWait(metrics.IPAMLockTypeWrite, 0, false)
+		return
+	}
+
+	start := time.Now()
+	t.RWMutex.Lock()
+	observeLockWait(metrics.IPAMLockTypeWrite, time.Since(start), true)
+}
+
+// LockContext acquires the write lock like Lock, but gives up waiting and returns an error once
+// ctx is done. The lock acquired by the abandoned waiter will be released automatically.
+func (t *tracedRWMutex) LockContext(ctx context.Context) error {
+	if t.RWMutex.TryLock() {
+		observeLockWait(metrics.IPAMLockTypeWrite, 0, false)
+		return nil
+	}
+
+	start := time.Now()
+	acquired := make(chan struct{})
+	go func() {
+		t.RWMutex.Lock()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		observeLockWait(metrics.IPAMLockTypeWrite, time.Since(start), true)
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-acquired
+			t.RWMutex.Unlock()
+		}()
+		observeLockWait(metrics.IPAMLockTypeWrite, time.Since(start), true)
+		return fmt.Errorf("failed to acquire ipam lock: %v", ctx.Err())
+	}
+}
+
+func (t *tracedRWMutex) RLock() {
+	if t.RWMutex.TryRLock() {
+		observeLockWait(metrics.IPAMLockTypeRead, 0, false)
+		return
+	}
+
+	start := time.Now()
+	t.RWMutex.RLock()
+	observeLockWait(metrics.IPAMLockTypeRead, time.Since(start), true)
+}
+
+func observeLockWait(lockType string, wait time.Duration, contended bool) {
+	metrics.IPAMLockWaitSeconds.WithLabelValues(lockType).Observe(wait.Seconds())
+	if contended {
+		metrics.IPAMLockContentionCounter.WithLabelValues(lockType).Inc()
+	}
+}
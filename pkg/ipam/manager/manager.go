@@ -17,8 +17,9 @@
 package manager
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/util/errors"
 
@@ -31,20 +32,33 @@ var _ ipam.Manager = &Manager{}
 
 // Manager is the build-in IPAM Manager implementation
 type Manager struct {
-	sync.RWMutex
+	tracedRWMutex
 
 	NetworkSet types.NetworkSet
 
+	FitStrategy types.FitStrategy
+
 	NetworkGetter NetworkGetter
 	SubnetGetter  SubnetGetter
 	IPSetGetter   IPSetGetter
 }
 
 // NewManager is a kind of constructor of ipam.Manager
-func NewManager(networks []string, nGetter NetworkGetter, sGetter SubnetGetter, iGetter IPSetGetter) (ipam.Manager, error) {
+func NewManager(networks []string, nGetter NetworkGetter, sGetter SubnetGetter, iGetter IPSetGetter,
+	opts ...types.ManagerOption) (ipam.Manager, error) {
+	options := &types.ManagerOptions{
+		FitStrategy: types.FirstFit,
+	}
+	options.ApplyOptions(opts)
+
+	if !types.IsValidFitStrategy(options.FitStrategy) {
+		return nil, fmt.Errorf("unsupported fit strategy %s", options.FitStrategy)
+	}
+
 	manager := &Manager{
-		RWMutex:       sync.RWMutex{},
+		tracedRWMutex: tracedRWMutex{},
 		NetworkSet:    types.NewNetworkSet(),
+		FitStrategy:   options.FitStrategy,
 		NetworkGetter: nGetter,
 		SubnetGetter:  sGetter,
 		IPSetGetter:   iGetter,
@@ -131,14 +145,50 @@ func (m *Manager) GetSubnetUsage(networkName, subnetName string) (*types.Usage,
 	return subnet.Usage(), nil
 }
 
-// Allocate will allocate some new IP for a specified pod
-func (m *Manager) Allocate(networkName string, podInfo types.PodInfo, opts ...types.AllocateOption) (allocatedIPs []*types.IP, err error) {
+// GetSubnetAllocatedIPs will return the IPs marked as used in the in-memory bitmap of a
+// specified subnet of a network
+func (m *Manager) GetSubnetAllocatedIPs(networkName, subnetName string) ([]string, error) {
 	m.Lock()
 	defer m.Unlock()
 
+	validateFunctions := []func() error{
+		func() error { return utils.CheckNotEmpty("network name", networkName) },
+		func() error { return utils.CheckNotEmpty("subnet name", subnetName) },
+	}
+
+	if err := errors.AggregateGoroutines(validateFunctions...); err != nil {
+		return nil, fmt.Errorf("validation fail: %v", err)
+	}
+
+	var network *types.Network
+	var err error
+	if network, err = m.NetworkSet.GetNetworkByName(networkName); err != nil {
+		return nil, fmt.Errorf("fail to get network %s: %v", networkName, err)
+	}
+
+	var subnet *types.Subnet
+	if subnet, err = network.GetSubnetByName(subnetName); err != nil {
+		return nil, fmt.Errorf("fail to get subnet %s: %v", subnetName, err)
+	}
+
+	// bitmap may be initialized lazily, so write lock is required
+	return subnet.AllocatedIPs(), nil
+}
+
+// Allocate will allocate some new IP for a specified pod
+func (m *Manager) Allocate(ctx context.Context, networkName string, podInfo types.PodInfo, opts ...types.AllocateOption) (allocatedIPs []*types.IP, err error) {
+	if err = m.LockContext(ctx); err != nil {
+		return nil, err
+	}
+	defer m.Unlock()
+
 	var options = &types.AllocateOptions{}
 	options.ApplyOptions(opts)
 
+	if options.LockAcquiredHook != nil {
+		options.LockAcquiredHook()
+	}
+
 	validateFunctions := []func() error{
 		func() error { return utils.CheckNotEmpty("network name", networkName) },
 		func() error { return utils.CheckNotEmpty("pod name", podInfo.Name) },
@@ -181,12 +231,12 @@ func (m *Manager) allocateIPv4(networkName string, podInfo types.PodInfo, option
 
 	var subnet *types.Subnet
 	if subnet, err = network.GetIPv4SubnetByNameOrAvailable(specifiedSubnetName); err != nil {
-		return nil, fmt.Errorf("fail to get ipv4 subnet: %v", err)
+		return nil, fmt.Errorf("fail to get ipv4 subnet: %w", err)
 	}
 
 	var ip *types.IP
-	if ip = subnet.AllocateNext(podInfo.Name, podInfo.Namespace); ip == nil {
-		return nil, fmt.Errorf("fail to get one available ipv4 address from subnet %s", subnet.Name)
+	if ip = subnet.Allocate(m.FitStrategy, podInfo.Name, podInfo.Namespace); ip == nil {
+		return nil, fmt.Errorf("fail to get one available ipv4 address from subnet %s: %w", subnet.Name, &types.SubnetExhaustedError{Subnet: subnet.Name})
 	}
 
 	IPs = append(IPs, ip)
@@ -212,12 +262,12 @@ func (m *Manager) allocateIPv6(networkName string, podInfo types.PodInfo, option
 
 	var subnet *types.Subnet
 	if subnet, err = network.GetIPv6SubnetByNameOrAvailable(specifiedSubnetName); err != nil {
-		return nil, fmt.Errorf("fail to get ipv6 subnet: %v", err)
+		return nil, fmt.Errorf("fail to get ipv6 subnet: %w", err)
 	}
 
 	var ip *types.IP
-	if ip = subnet.AllocateNext(podInfo.Name, podInfo.Namespace); ip == nil {
-		return nil, fmt.Errorf("fail to get one available ipv6 address from subnet %s", subnet.Name)
+	if ip = subnet.Allocate(m.FitStrategy, podInfo.Name, podInfo.Namespace); ip == nil {
+		return nil, fmt.Errorf("fail to get one available ipv6 address from subnet %s: %w", subnet.Name, &types.SubnetExhaustedError{Subnet: subnet.Name})
 	}
 
 	IPs = append(IPs, ip)
@@ -243,17 +293,17 @@ func (m *Manager) allocateDualStack(networkName string, podInfo types.PodInfo, o
 
 	var ipv4Subnet, ipv6Subnet *types.Subnet
 	if ipv4Subnet, ipv6Subnet, err = network.GetDualStackSubnetsByNameOrAvailable(specifiedIPv4SubnetName, specifiedIPv6SubnetName); err != nil {
-		return nil, fmt.Errorf("fail to get paired subnets: %v", err)
+		return nil, fmt.Errorf("fail to get paired subnets: %w", err)
 	}
 
 	var ipv4IP, ipv6IP *types.IP
-	if ipv4IP = ipv4Subnet.AllocateNext(podInfo.Name, podInfo.Namespace); ipv4IP == nil {
-		return nil, fmt.Errorf("fail to get ipv4 address from subnet %s", ipv4Subnet.Name)
+	if ipv4IP = ipv4Subnet.Allocate(m.FitStrategy, podInfo.Name, podInfo.Namespace); ipv4IP == nil {
+		return nil, fmt.Errorf("fail to get ipv4 address from subnet %s: %w", ipv4Subnet.Name, &types.SubnetExhaustedError{Subnet: ipv4Subnet.Name})
 	}
-	if ipv6IP = ipv6Subnet.AllocateNext(podInfo.Name, podInfo.Namespace); ipv6IP == nil {
+	if ipv6IP = ipv6Subnet.Allocate(m.FitStrategy, podInfo.Name, podInfo.Namespace); ipv6IP == nil {
 		// recycle IPv4 address if IPv6 allocation fails
 		ipv4Subnet.Release(ipv4IP.Address.IP.String())
-		return nil, fmt.Errorf("fail to get ipv6 address zfrom subnet %s", ipv6Subnet.Name)
+		return nil, fmt.Errorf("fail to get ipv6 address zfrom subnet %s: %w", ipv6Subnet.Name, &types.SubnetExhaustedError{Subnet: ipv6Subnet.Name})
 	}
 
 	IPs = append(IPs, ipv4IP, ipv6IP)
@@ -389,8 +439,10 @@ func (m *Manager) assignDualStack(networkName string, podInfo types.PodInfo, ass
 }
 
 // Release will release some IPs from allocation or assignment to a specified pod
-func (m *Manager) Release(networkName string, releaseSuites []types.SubnetIPSuite) (err error) {
-	m.Lock()
+func (m *Manager) Release(ctx context.Context, networkName string, releaseSuites []types.SubnetIPSuite) (err error) {
+	if err = m.LockContext(ctx); err != nil {
+		return err
+	}
 	defer m.Unlock()
 
 	validateFunctions := []func() error{
@@ -454,9 +506,90 @@ func (m *Manager) Reserve(networkName string, reserveSuites []types.SubnetIPSuit
 	return
 }
 
+// CheckConsistency recomputes the expected allocated IPs of subnets from the getters and compares
+// them with the in-memory ones, the drifted subnets are returned by network name. In-memory state
+// is only snapshotted under read lock, before and after the getters are called, so that allocation
+// is not blocked by checking and IPs allocated or released meanwhile are not taken as drifted.
+func (m *Manager) CheckConsistency() (driftedSubnets map[string][]string, err error) {
+	before := m.snapshotAllocatedIPs()
+
+	expectedNetworks := make(map[string]*types.Network, len(before))
+	for name := range before {
+		var expected *types.Network
+		if expected, err = m.buildNetwork(name); err != nil {
+			return nil, err
+		}
+
+		// network deletion will be handled by refreshing
+		if expected != nil {
+			expectedNetworks[name] = expected
+		}
+	}
+
+	after := m.snapshotAllocatedIPs()
+
+	driftedSubnets = map[string][]string{}
+	for name, expected := range expectedNetworks {
+		for subnetName, beforeIPs := range before[name] {
+			afterIPs, exist := after[name][subnetName]
+			if !exist {
+				continue
+			}
+
+			expectedSubnet, getErr := expected.GetSubnetByName(subnetName)
+			if getErr != nil {
+				continue
+			}
+			if allocatedIPsDrifted(expectedSubnet.AllocatedIPSnapshot(), beforeIPs, afterIPs) {
+				driftedSubnets[name] = append(driftedSubnets[name], subnetName)
+			}
+		}
+		sort.Strings(driftedSubnets[name])
+	}
+
+	return driftedSubnets, nil
+}
+
+// snapshotAllocatedIPs returns the allocated IPs of all the in-memory subnets by network and subnet name
+func (m *Manager) snapshotAllocatedIPs() map[string]map[string]map[string]struct{} {
+	m.RLock()
+	defer m.RUnlock()
+
+	snapshot := make(map[string]map[string]map[string]struct{}, len(m.NetworkSet))
+	for name, network := range m.NetworkSet {
+		snapshot[name] = map[string]map[string]struct{}{}
+		for _, subnetSlice := range []*types.SubnetSlice{network.IPv4Subnets, network.IPv6Subnets} {
+			for _, subnet := range subnetSlice.Subnets {
+				snapshot[name][subnet.Name] = subnet.AllocatedIPSnapshot()
+			}
+		}
+	}
+	return snapshot
+}
+
+// allocatedIPsDrifted checks whether the expected IPs differ from the in-memory ones of both snapshots,
+// IPs changed between the two snapshots may be either in or not in the expected ones
+func allocatedIPsDrifted(expected, before, after map[string]struct{}) bool {
+	for ip := range expected {
+		_, inBefore := before[ip]
+		_, inAfter := after[ip]
+		if !inBefore && !inAfter {
+			return true
+		}
+	}
+	for ip := range before {
+		if _, inAfter := after[ip]; !inAfter {
+			continue
+		}
+		if _, inExpected := expected[ip]; !inExpected {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) refreshNetwork(name string) error {
-	// get network spec
-	network, err := m.NetworkGetter(name)
+	network, err := m.buildNetwork(name)
 	if err != nil {
 		return err
 	}
@@ -466,10 +599,28 @@ func (m *Manager) refreshNetwork(name string) error {
 		return nil
 	}
 
+	m.NetworkSet.RefreshNetwork(name, network)
+
+	return nil
+}
+
+// buildNetwork builds network with its subnets and using IPs from getters, nil will be
+// returned if network does not exist
+func (m *Manager) buildNetwork(name string) (*types.Network, error) {
+	// get network spec
+	network, err := m.NetworkGetter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == nil {
+		return nil, nil
+	}
+
 	// get subnets which belongs to this network
 	subnets, err := m.SubnetGetter(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var ips types.IPSet
@@ -477,14 +628,12 @@ func (m *Manager) refreshNetwork(name string) error {
 		// get using ips which belongs to this subnet
 		ips, err = m.IPSetGetter(subnet.Name)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if err = network.AddSubnet(subnet, ips); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	m.NetworkSet.RefreshNetwork(name, network)
-
-	return nil
+	return network, nil
 }
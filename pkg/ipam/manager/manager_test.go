@@ -17,9 +17,12 @@
 package manager_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	apitypes "k8s.io/apimachinery/pkg/types"
 
@@ -91,7 +94,7 @@ func TestManager(t *testing.T) {
 			ipFamilyMode = types.DualStack
 		}
 
-		ips, err := manager.Allocate(networkTest, types.PodInfo{
+		ips, err := manager.Allocate(context.Background(), networkTest, types.PodInfo{
 			NamespacedName: apitypes.NamespacedName{
 				Namespace: "testns",
 				Name:      "testname",
@@ -112,6 +115,398 @@ func TestManager(t *testing.T) {
 	}
 }
 
+func TestManagerSubnetExhausted(t *testing.T) {
+	var networkGetter = func(network string) (*types.Network, error) {
+		return &types.Network{
+			Name:        network,
+			NetID:       nil,
+			IPv4Subnets: types.NewSubnetSlice(""),
+			IPv6Subnets: types.NewSubnetSlice(""),
+			Type:        types.Underlay,
+		}, nil
+	}
+
+	var subnetGetter = func(networkName string) ([]*types.Subnet, error) {
+		// only one available address in subnet
+		_, cidrNet, _ := net.ParseCIDR("172.168.0.0/30")
+		return []*types.Subnet{
+			types.NewSubnet("subnet1", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("172.168.0.1"), cidrNet, nil, nil, nil, false, false),
+		}, nil
+	}
+
+	var ipSetGetter = func(subnet string) (types.IPSet, error) {
+		return types.NewIPSet(), nil
+	}
+
+	networkTest := "network-test-1"
+	manager, err := manager.NewManager([]string{networkTest}, networkGetter, subnetGetter, ipSetGetter)
+	if err != nil {
+		t.Errorf("fail to new manager: %v", err)
+		return
+	}
+
+	tests := []struct {
+		name    string
+		podName string
+		subnets []string
+		err     error
+	}{
+		{
+			name:    "allocate the only address",
+			podName: "pod1",
+			err:     nil,
+		},
+		{
+			name:    "allocate from exhausted network",
+			podName: "pod2",
+			err:     types.ErrSubnetExhausted,
+		},
+		{
+			name:    "allocate from specified exhausted subnet",
+			podName: "pod3",
+			subnets: []string{"subnet1"},
+			err:     types.ErrSubnetExhausted,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := manager.Allocate(context.Background(), networkTest, types.PodInfo{
+				NamespacedName: apitypes.NamespacedName{
+					Namespace: "testns",
+					Name:      test.podName,
+				},
+				IPFamily: types.IPv4,
+			}, types.AllocateSubnets(test.subnets))
+			if !errors.Is(err, test.err) {
+				t.Errorf("test %s fails: expected error %v but got %v", test.name, test.err, err)
+			}
+		})
+	}
+}
+
+func TestManagerDualStackAtomicity(t *testing.T) {
+	var networkGetter = func(network string) (*types.Network, error) {
+		return &types.Network{
+			Name:        network,
+			NetID:       nil,
+			IPv4Subnets: types.NewSubnetSlice(""),
+			IPv6Subnets: types.NewSubnetSlice(""),
+			Type:        types.Underlay,
+		}, nil
+	}
+
+	var subnetGetter = func(networkName string) ([]*types.Subnet, error) {
+		_, ipv4CIDR, _ := net.ParseCIDR("172.168.0.0/30")
+		_, ipv6CIDR, _ := net.ParseCIDR("2048::0000/126")
+		return []*types.Subnet{
+			types.NewSubnet("subnet1", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("172.168.0.1"), ipv4CIDR, nil, nil, nil, false, false),
+			types.NewSubnet("subnet2", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("2048::0001"), ipv6CIDR, nil, nil, nil, false, true),
+		}, nil
+	}
+
+	var ipSetGetter = func(subnet string) (types.IPSet, error) {
+		return types.NewIPSet(), nil
+	}
+
+	networkTest := "network-test-1"
+	manager, err := manager.NewManager([]string{networkTest}, networkGetter, subnetGetter, ipSetGetter)
+	if err != nil {
+		t.Errorf("fail to new manager: %v", err)
+		return
+	}
+
+	var allocate = func(podName string, ipFamily types.IPFamilyMode, subnets ...string) ([]*types.IP, error) {
+		return manager.Allocate(context.Background(), networkTest, types.PodInfo{
+			NamespacedName: apitypes.NamespacedName{
+				Namespace: "testns",
+				Name:      podName,
+			},
+			IPFamily: ipFamily,
+		}, types.AllocateSubnets(subnets))
+	}
+
+	// exhaust the IPv6 subnet
+	for i := 0; ; i++ {
+		if _, err = allocate(fmt.Sprintf("ipv6-pod%d", i), types.IPv6); err != nil {
+			if !errors.Is(err, types.ErrSubnetExhausted) {
+				t.Errorf("fail to exhaust ipv6 subnet: %v", err)
+				return
+			}
+			break
+		}
+	}
+
+	// specified subnets will make IPv4 allocated before IPv6 allocation fails
+	if _, err = allocate("dual-stack-pod", types.DualStack, "subnet1", "subnet2"); !errors.Is(err, types.ErrSubnetExhausted) {
+		t.Errorf("expected error %v when ipv6 subnet is exhausted but got %v", types.ErrSubnetExhausted, err)
+		return
+	}
+
+	var exhaustedErr *types.SubnetExhaustedError
+	if !errors.As(err, &exhaustedErr) || exhaustedErr.Subnet != "subnet2" {
+		t.Errorf("expected exhausted subnet subnet2 but got %v", err)
+		return
+	}
+
+	// the only IPv4 address must not be leaked by the failed dual-stack allocation
+	ips, err := allocate("ipv4-pod", types.IPv4)
+	if err != nil {
+		t.Errorf("fail to allocate ipv4 address after failed dual-stack allocation: %v", err)
+		return
+	}
+	if len(ips) != 1 || ips[0].Address.IP.String() != "172.168.0.2" {
+		t.Errorf("expected ipv4 address 172.168.0.2 but got %+v", ips)
+	}
+}
+
+func TestManagerCheckConsistency(t *testing.T) {
+	var networkGetter = func(network string) (*types.Network, error) {
+		return &types.Network{
+			Name:        network,
+			NetID:       nil,
+			IPv4Subnets: types.NewSubnetSlice(""),
+			IPv6Subnets: types.NewSubnetSlice(""),
+			Type:        types.Underlay,
+		}, nil
+	}
+
+	var subnetGetter = func(networkName string) ([]*types.Subnet, error) {
+		_, cidrNet, _ := net.ParseCIDR("172.168.0.0/24")
+		return []*types.Subnet{
+			types.NewSubnet("subnet1", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("172.168.0.254"), cidrNet, nil, nil, nil, false, false),
+		}, nil
+	}
+
+	// the allocated IPs will be returned by getter as IPInstances
+	ipSet := types.NewIPSet()
+	var onGetIPSet func()
+	var ipSetGetter = func(subnet string) (types.IPSet, error) {
+		if onGetIPSet != nil {
+			onGetIPSet()
+		}
+		copied := types.NewIPSet()
+		for ip, content := range ipSet {
+			copied.Add(ip, content)
+		}
+		return copied, nil
+	}
+
+	networkTest := "network-test-1"
+	manager, err := manager.NewManager([]string{networkTest}, networkGetter, subnetGetter, ipSetGetter)
+	if err != nil {
+		t.Fatalf("fail to new manager: %v", err)
+	}
+
+	allocate := func(podName string) *types.IP {
+		ips, err := manager.Allocate(context.Background(), networkTest, types.PodInfo{
+			NamespacedName: apitypes.NamespacedName{
+				Namespace: "testns",
+				Name:      podName,
+			},
+			IPFamily: types.IPv4,
+		})
+		if err != nil {
+			t.Fatalf("fail to allocate ip: %v", err)
+		}
+		return ips[0]
+	}
+
+	checkDrifted := func(expected bool) {
+		driftedSubnets, err := manager.CheckConsistency()
+		if err != nil {
+			t.Fatalf("fail to check consistency: %v", err)
+		}
+		if drifted := len(driftedSubnets[networkTest]) > 0; drifted != expected {
+			t.Fatalf("expected drifted %v but got %v", expected, driftedSubnets)
+		}
+	}
+
+	ip := allocate("pod1")
+	ipSet.Add(ip.Address.IP.String(), ip)
+	checkDrifted(false)
+
+	// IP allocated in memory without IPInstance
+	allocate("pod2")
+	checkDrifted(true)
+
+	// rebuild from getters
+	if err = manager.Refresh(types.RefreshNetworks([]string{networkTest})); err != nil {
+		t.Fatalf("fail to refresh: %v", err)
+	}
+	checkDrifted(false)
+
+	// IPInstance exists without IP in memory
+	ipSet.Add("172.168.0.100", &types.IP{
+		Address: &net.IPNet{IP: net.ParseIP("172.168.0.100"), Mask: net.CIDRMask(24, 32)},
+		Subnet:  "subnet1",
+		Network: networkTest,
+	})
+	checkDrifted(true)
+
+	ipSet.Delete("172.168.0.100")
+	checkDrifted(false)
+
+	// IP allocated while checking is not taken as drifted, and checking does not block allocation
+	onGetIPSet = func() {
+		onGetIPSet = nil
+		ip := allocate("pod3")
+		ipSet.Add(ip.Address.IP.String(), ip)
+	}
+	checkDrifted(false)
+}
+
 func generatePointerInt(a uint32) *uint32 {
 	return &a
 }
+
+func TestManagerSubnetCIDRExpansion(t *testing.T) {
+	var networkGetter = func(network string) (*types.Network, error) {
+		return &types.Network{
+			Name:        network,
+			NetID:       nil,
+			IPv4Subnets: types.NewSubnetSlice(""),
+			IPv6Subnets: types.NewSubnetSlice(""),
+			Type:        types.Underlay,
+		}, nil
+	}
+
+	// only one available address in subnet before expansion
+	cidr := "172.168.0.0/30"
+	var subnetGetter = func(networkName string) ([]*types.Subnet, error) {
+		_, cidrNet, _ := net.ParseCIDR(cidr)
+		return []*types.Subnet{
+			types.NewSubnet("subnet1", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("172.168.0.1"), cidrNet, nil, nil, nil, false, false),
+		}, nil
+	}
+
+	// the allocated IPs will be returned by getter as IPInstances
+	ipSet := types.NewIPSet()
+	var ipSetGetter = func(subnet string) (types.IPSet, error) {
+		copied := types.NewIPSet()
+		for ip, content := range ipSet {
+			copied.Add(ip, content)
+		}
+		return copied, nil
+	}
+
+	networkTest := "network-test-1"
+	manager, err := manager.NewManager([]string{networkTest}, networkGetter, subnetGetter, ipSetGetter)
+	if err != nil {
+		t.Fatalf("fail to new manager: %v", err)
+	}
+
+	allocate := func(podName string) ([]*types.IP, error) {
+		return manager.Allocate(context.Background(), networkTest, types.PodInfo{
+			NamespacedName: apitypes.NamespacedName{
+				Namespace: "testns",
+				Name:      podName,
+			},
+			IPFamily: types.IPv4,
+		})
+	}
+
+	ips, err := allocate("pod0")
+	if err != nil {
+		t.Fatalf("fail to allocate ip before expansion: %v", err)
+	}
+	ipSet.Add(ips[0].Address.IP.String(), ips[0])
+
+	if _, err = allocate("pod-exhausted"); !errors.Is(err, types.ErrSubnetExhausted) {
+		t.Fatalf("expected subnet exhausted before expansion but got %v", err)
+	}
+
+	// expand CIDR from /30 to /29, the broadcast address of /30 and the addresses
+	// of the expanded range become available
+	cidr = "172.168.0.0/29"
+	if err = manager.Refresh(types.RefreshNetworks([]string{networkTest})); err != nil {
+		t.Fatalf("fail to refresh: %v", err)
+	}
+
+	allocated := map[string]struct{}{}
+	for i := 1; i <= 4; i++ {
+		ips, err = allocate(fmt.Sprintf("pod%d", i))
+		if err != nil {
+			t.Fatalf("fail to allocate ip %d after expansion: %v", i, err)
+		}
+		ip := ips[0].Address.IP.String()
+		if ip == "172.168.0.2" {
+			t.Fatalf("ip %s allocated before expansion is allocated again", ip)
+		}
+		if _, exist := allocated[ip]; exist {
+			t.Fatalf("ip %s is allocated twice", ip)
+		}
+		allocated[ip] = struct{}{}
+	}
+
+	for _, ip := range []string{"172.168.0.3", "172.168.0.4", "172.168.0.5", "172.168.0.6"} {
+		if _, exist := allocated[ip]; !exist {
+			t.Errorf("ip %s of expanded range is not allocated, allocated ips are %v", ip, allocated)
+		}
+	}
+
+	if _, err = allocate("pod-exhausted"); !errors.Is(err, types.ErrSubnetExhausted) {
+		t.Fatalf("expected subnet exhausted after expansion but got %v", err)
+	}
+}
+
+func TestManagerAllocateCancelledWhileLocked(t *testing.T) {
+	var networkGetter = func(network string) (*types.Network, error) {
+		return &types.Network{
+			Name:        network,
+			IPv4Subnets: types.NewSubnetSlice(""),
+			IPv6Subnets: types.NewSubnetSlice(""),
+			Type:        types.Underlay,
+		}, nil
+	}
+	var subnetGetter = func(networkName string) ([]*types.Subnet, error) {
+		_, cidrNet, _ := net.ParseCIDR("172.168.0.0/24")
+		return []*types.Subnet{
+			types.NewSubnet("subnet1", networkName, generatePointerInt(60), nil, nil,
+				net.ParseIP("172.168.0.1"), cidrNet, nil, nil, nil, false, false),
+		}, nil
+	}
+	var ipSetGetter = func(subnet string) (types.IPSet, error) {
+		return types.NewIPSet(), nil
+	}
+
+	networkTest := "network-test-1"
+	m, err := manager.NewManager([]string{networkTest}, networkGetter, subnetGetter, ipSetGetter)
+	if err != nil {
+		t.Fatalf("fail to new manager: %v", err)
+	}
+
+	podInfo := types.PodInfo{
+		NamespacedName: apitypes.NamespacedName{
+			Namespace: "testns",
+			Name:      "pod0",
+		},
+		IPFamily: types.IPv4,
+	}
+
+	// hold the lock to simulate a long-running operation
+	m.(*manager.Manager).Lock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = m.Allocate(ctx, networkTest, podInfo); err == nil {
+		t.Fatalf("expected allocation to fail after context is done")
+	}
+	if err = m.Release(ctx, networkTest, nil); err == nil {
+		t.Fatalf("expected release to fail after context is done")
+	}
+
+	m.(*manager.Manager).Unlock()
+
+	// the lock acquired by the abandoned waiters must be released
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err = m.Allocate(ctx, networkTest, podInfo); err != nil {
+		t.Fatalf("fail to allocate after lock is released: %v", err)
+	}
+}
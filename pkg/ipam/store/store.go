@@ -19,6 +19,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/alibaba/hybridnet/pkg/utils/transform"
 
@@ -39,7 +40,10 @@ import (
 	"github.com/alibaba/hybridnet/pkg/utils/mac"
 )
 
-const podKind = "Pod"
+const (
+	podKind    = "Pod"
+	subnetKind = "Subnet"
+)
 
 var _ ipam.Store = &crdStore{}
 
@@ -54,7 +58,9 @@ func NewCRDStore(c client.Client) ipam.Store {
 	}
 }
 
-// Couple will create related IPInstances bind to a specified pod
+// Couple will create related IPInstances bind to a specified pod, IPInstances
+// of all IPs will be created or none of them, which means any partially-created
+// IPInstances will be rolled back if one creation fails
 func (s *crdStore) Couple(ctx context.Context, pod *corev1.Pod, IPs []*ipamtypes.IP, opts ...ipamtypes.CoupleOption) (err error) {
 	var (
 		createdNames []string
@@ -65,9 +71,9 @@ func (s *crdStore) Couple(ctx context.Context, pod *corev1.Pod, IPs []*ipamtypes
 	options.ApplyOptions(opts)
 
 	defer func() {
-		if err != nil {
-			for _, name := range createdNames {
-				_ = s.deleteIPInstance(ctx, pod.Namespace, name)
+		if err != nil && len(createdNames) > 0 {
+			if rollbackErr := s.rollbackIPInstances(ctx, pod.Namespace, createdNames); rollbackErr != nil {
+				err = fmt.Errorf("%w, and fail to roll back created IPInstances %v: %v", err, createdNames, rollbackErr)
 			}
 		}
 	}()
@@ -80,7 +86,7 @@ func (s *crdStore) Couple(ctx context.Context, pod *corev1.Pod, IPs []*ipamtypes
 	}
 	for _, ip := range IPs {
 		var ipInstance *networkingv1.IPInstance
-		if ipInstance, err = s.createIPInstance(ctx, pod, ip, unifiedMACAddr, options.OwnerReference, options.AdditionalLabels); err != nil {
+		if ipInstance, err = s.createIPInstance(ctx, pod, ip, unifiedMACAddr, options.OwnerReference, options.AdditionalLabels, options.AllocationContext); err != nil {
 			return err
 		}
 		createdNames = append(createdNames, ipInstance.Name)
@@ -129,7 +135,7 @@ func (s *crdStore) ReCouple(ctx context.Context, pod *corev1.Pod, IPs []*ipamtyp
 	}
 
 	for _, ip := range IPs {
-		if _, err = s.createOrUpdateIPInstance(ctx, pod, ip, unifiedMACAddr, options.OwnerReference, options.AdditionalLabels); err != nil {
+		if _, err = s.createOrUpdateIPInstance(ctx, pod, ip, unifiedMACAddr, options.OwnerReference, options.AdditionalLabels, options.AllocationContext); err != nil {
 			return
 		}
 	}
@@ -207,7 +213,7 @@ func (s *crdStore) IPUnBind(ctx context.Context, namespace, ip string) (err erro
 }
 
 // createIPInstance will create an IPInstance by pod info, ip info and mac address
-func (s *crdStore) createIPInstance(ctx context.Context, pod *corev1.Pod, ip *ipamtypes.IP, macAddr string, ownerReference *metav1.OwnerReference, additionalLabels map[string]string) (ipIns *networkingv1.IPInstance, err error) {
+func (s *crdStore) createIPInstance(ctx context.Context, pod *corev1.Pod, ip *ipamtypes.IP, macAddr string, ownerReference *metav1.OwnerReference, additionalLabels map[string]string, allocationContext *networkingv1.AllocationContext) (ipIns *networkingv1.IPInstance, err error) {
 	ipInstance := &networkingv1.IPInstance{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      utils.ToDNSLabelFormatName(ip),
@@ -215,13 +221,18 @@ func (s *crdStore) createIPInstance(ctx context.Context, pod *corev1.Pod, ip *ip
 		},
 	}
 
-	assembleIPInstance(ipInstance, ip, pod, macAddr, ownerReference, additionalLabels)
+	subnetOwner, err := s.subnetOwnerReference(ctx, ip.Subnet)
+	if err != nil {
+		return nil, err
+	}
+
+	assembleIPInstance(ipInstance, ip, pod, macAddr, ownerReference, subnetOwner, additionalLabels, allocationContext)
 
 	return ipInstance, s.Create(ctx, ipInstance)
 }
 
 // createOrUpdateIPInstance will create or update an IPInstance by pod info, ip info and mac address
-func (s *crdStore) createOrUpdateIPInstance(ctx context.Context, pod *corev1.Pod, ip *ipamtypes.IP, macAddr string, ownerReference *metav1.OwnerReference, additionalLabels map[string]string) (ipIns *networkingv1.IPInstance, err error) {
+func (s *crdStore) createOrUpdateIPInstance(ctx context.Context, pod *corev1.Pod, ip *ipamtypes.IP, macAddr string, ownerReference *metav1.OwnerReference, additionalLabels map[string]string, allocationContext *networkingv1.AllocationContext) (ipIns *networkingv1.IPInstance, err error) {
 	var ipInstance = &networkingv1.IPInstance{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      utils.ToDNSLabelFormatName(ip),
@@ -229,19 +240,34 @@ func (s *crdStore) createOrUpdateIPInstance(ctx context.Context, pod *corev1.Pod
 		},
 	}
 
+	subnetOwner, err := s.subnetOwnerReference(ctx, ip.Subnet)
+	if err != nil {
+		return nil, err
+	}
+
 	_, err = controllerutil.CreateOrPatch(ctx, s, ipInstance, func() error {
 		if !ipInstance.DeletionTimestamp.IsZero() {
 			return fmt.Errorf("ip instance %s/%s is deleting, can not be updated", ipInstance.Namespace, ipInstance.Name)
 		}
 
 		// mac address will be regenerated if reused ipInstance was deleted unexpectedly
-		assembleIPInstance(ipInstance, ip, pod, macAddr, ownerReference, additionalLabels)
+		assembleIPInstance(ipInstance, ip, pod, macAddr, ownerReference, subnetOwner, additionalLabels, allocationContext)
 		return nil
 	})
 
 	return ipInstance, err
 }
 
+// subnetOwnerReference will generate a non-controller owner reference of the parent
+// subnet, so that IPInstances can be garbage collected after subnet is deleted
+func (s *crdStore) subnetOwnerReference(ctx context.Context, subnetName string) (*metav1.OwnerReference, error) {
+	var subnet = &networkingv1.Subnet{}
+	if err := s.Get(ctx, types.NamespacedName{Name: subnetName}, subnet); err != nil {
+		return nil, fmt.Errorf("unable to get parent subnet %s: %v", subnetName, err)
+	}
+	return utils.NewControllerRef(subnet, networkingv1.GroupVersion.WithKind(subnetKind), false, false), nil
+}
+
 // deleteIPInstance will remove an IPInstance by namespace and name
 func (s *crdStore) deleteIPInstance(ctx context.Context, namespace, name string) error {
 	return s.Delete(ctx, &networkingv1.IPInstance{
@@ -252,6 +278,23 @@ func (s *crdStore) deleteIPInstance(ctx context.Context, namespace, name string)
 	})
 }
 
+// rollbackIPInstances will clean up IPInstances which are created but not
+// completely coupled, the blocking finalizers must be removed because IPs of
+// them have never been successfully allocated and will be released by caller
+func (s *crdStore) rollbackIPInstances(ctx context.Context, namespace string, names []string) error {
+	var rollbackFunctions []func() error
+	for i := range names {
+		var name = names[i]
+		rollbackFunctions = append(rollbackFunctions, func() error {
+			if err := client.IgnoreNotFound(s.removeFinalizerOfIPInstance(ctx, namespace, name)); err != nil {
+				return err
+			}
+			return client.IgnoreNotFound(s.deleteIPInstance(ctx, namespace, name))
+		})
+	}
+	return errors.AggregateGoroutines(rollbackFunctions...)
+}
+
 // getIPInstance will get an IPInstance by namespace and name
 func (s *crdStore) getIPInstance(ctx context.Context, namespace string, ip *ipamtypes.IP) (*networkingv1.IPInstance, error) {
 	var ipInstance = &networkingv1.IPInstance{}
@@ -272,6 +315,9 @@ func (s *crdStore) reserveIPInstance(ctx context.Context, ipInstance *networking
 				return nil
 			}
 
+			// remember where this IP was released from for cross-node mobility
+			recordLastNode(ipInstance)
+
 			// clean pod uid & node info means this IP is not being used by any pod
 			ipInstance.Spec.Binding.NodeName = ""
 			ipInstance.Spec.Binding.PodUID = ""
@@ -289,6 +335,7 @@ func (s *crdStore) reserveIPInstance(ctx context.Context, ipInstance *networking
 			ipInstance.Status.PodNamespace = ""
 			ipInstance.Status.NodeName = ""
 			ipInstance.Status.SandboxID = ""
+			ipInstance.Status.DNSHostname = ""
 			ipInstance.Status.UpdateTimestamp = metav1.Now()
 			return nil
 		},
@@ -315,9 +362,23 @@ func (s *crdStore) removeFinalizerOfIPInstance(ctx context.Context, namespace, n
 	})
 }
 
+// recordLastNode annotates the node which IPInstance is bound to currently and the release time,
+// daemon on the next node will wait for the grace timeout of network before configuring this IP
+func recordLastNode(ipIns *networkingv1.IPInstance) {
+	if len(ipIns.Spec.Binding.NodeName) == 0 {
+		return
+	}
+
+	if ipIns.Annotations == nil {
+		ipIns.Annotations = map[string]string{}
+	}
+	ipIns.Annotations[constants.AnnotationLastNode] = ipIns.Spec.Binding.NodeName
+	ipIns.Annotations[constants.AnnotationLastNodeReleaseTime] = time.Now().UTC().Format(time.RFC3339)
+}
+
 // assembleIPInstance will assemble the spec of IPInstance with provided inputs,
 // including pod, ip info and mac address
-func assembleIPInstance(ipIns *networkingv1.IPInstance, ip *ipamtypes.IP, pod *corev1.Pod, macAddr string, ownerReference *metav1.OwnerReference, additionalLabels map[string]string) {
+func assembleIPInstance(ipIns *networkingv1.IPInstance, ip *ipamtypes.IP, pod *corev1.Pod, macAddr string, ownerReference, subnetOwner *metav1.OwnerReference, additionalLabels map[string]string, allocationContext *networkingv1.AllocationContext) {
 	// finalizer will block deletion for garbage collection
 	ipIns.Finalizers = []string{constants.FinalizerIPAllocated}
 
@@ -331,6 +392,7 @@ func assembleIPInstance(ipIns *networkingv1.IPInstance, ip *ipamtypes.IP, pod *c
 	ipIns.Labels[constants.LabelNode] = pod.Spec.NodeName
 	ipIns.Labels[constants.LabelPod] = transform.TransferPodNameForLabelValue(pod.Name)
 	ipIns.Labels[constants.LabelPodUID] = string(pod.UID)
+	ipIns.Labels[constants.LabelIPAddress] = utils.ToDNSLabelFormatName(ip)
 
 	// additional labels will be patched
 	// NOTICE: additional labels will take higher priority than built-in lables
@@ -351,7 +413,18 @@ func assembleIPInstance(ipIns *networkingv1.IPInstance, ip *ipamtypes.IP, pod *c
 		}
 	}
 
-	ipIns.OwnerReferences = []metav1.OwnerReference{*owner}
+	// parent subnet is always a non-controller owner, garbage collector will delete IPInstance
+	// only if both the workload and subnet disappear, IPInstances of running pods are kept
+	ipIns.OwnerReferences = []metav1.OwnerReference{*owner, *subnetOwner}
+
+	// IPInstances created or updated by store are always of the latest schema
+	ipIns.Spec.SchemaVersion = networkingv1.IPInstanceLatestSchemaVersion
+
+	// allocation context is only refreshed if provided, IPInstances reserved or assigned
+	// without context keep the one of last allocation
+	if allocationContext != nil {
+		ipIns.Spec.AllocationContext = allocationContext.DeepCopy()
+	}
 
 	// parent network and subnet name
 	ipIns.Spec.Network = ip.Network
@@ -380,6 +453,11 @@ func assembleIPInstance(ipIns *networkingv1.IPInstance, ip *ipamtypes.IP, pod *c
 		}
 	}
 
+	// IP moves to another node without reservation
+	if ipIns.Spec.Binding.NodeName != pod.Spec.NodeName {
+		recordLastNode(ipIns)
+	}
+
 	// binding point to the owner
 	ipIns.Spec.Binding = networkingv1.Binding{
 		ReferredObject: networkingv1.ObjectMeta{
@@ -0,0 +1,155 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import "math/bits"
+
+const (
+	bitmapWordBits  = 64
+	bitmapPageWords = 64
+	bitmapPageBits  = bitmapWordBits * bitmapPageWords
+)
+
+// bitmapPage is a fixed-size block of bits, count is the number of set bits in it
+type bitmapPage struct {
+	words [bitmapPageWords]uint64
+	count int
+}
+
+// SparseBitmap is a bitmap of a fixed size whose pages are allocated on demand, a nil
+// page means all the bits in it are clear, so ranges which are never used take no memory
+// except the page pointers, and the page pointers are also allocated on the first set
+type SparseBitmap struct {
+	size  int
+	count int
+	pages []*bitmapPage
+}
+
+func NewSparseBitmap(size int) *SparseBitmap {
+	return &SparseBitmap{
+		size: size,
+	}
+}
+
+// Size returns the number of bits in bitmap
+func (b *SparseBitmap) Size() int {
+	return b.size
+}
+
+// Count returns the number of set bits in bitmap
+func (b *SparseBitmap) Count() int {
+	return b.count
+}
+
+// Test returns whether the bit of index is set
+func (b *SparseBitmap) Test(index int) bool {
+	if index < 0 || index >= b.size || b.pages == nil {
+		return false
+	}
+	page := b.pages[index/bitmapPageBits]
+	if page == nil {
+		return false
+	}
+	offset := index % bitmapPageBits
+	return page.words[offset/bitmapWordBits]&(1<<(offset%bitmapWordBits)) != 0
+}
+
+// Set sets the bit of index, the page will be allocated if it is nil
+func (b *SparseBitmap) Set(index int) {
+	if index < 0 || index >= b.size || b.Test(index) {
+		return
+	}
+	if b.pages == nil {
+		b.pages = make([]*bitmapPage, (b.size+bitmapPageBits-1)/bitmapPageBits)
+	}
+	page := b.pages[index/bitmapPageBits]
+	if page == nil {
+		page = &bitmapPage{}
+		b.pages[index/bitmapPageBits] = page
+	}
+	offset := index % bitmapPageBits
+	page.words[offset/bitmapWordBits] |= 1 << (offset % bitmapWordBits)
+	page.count++
+	b.count++
+}
+
+// Clear clears the bit of index, the page will be freed if all the bits in it are clear
+func (b *SparseBitmap) Clear(index int) {
+	if !b.Test(index) {
+		return
+	}
+	page := b.pages[index/bitmapPageBits]
+	offset := index % bitmapPageBits
+	page.words[offset/bitmapWordBits] &^= 1 << (offset % bitmapWordBits)
+	page.count--
+	b.count--
+	if page.count == 0 {
+		b.pages[index/bitmapPageBits] = nil
+	}
+}
+
+// NextClear returns the index of the first clear bit from index, -1 will be returned if not found
+func (b *SparseBitmap) NextClear(index int) int {
+	return b.next(index, false)
+}
+
+// NextSet returns the index of the first set bit from index, -1 will be returned if not found
+func (b *SparseBitmap) NextSet(index int) int {
+	return b.next(index, true)
+}
+
+func (b *SparseBitmap) next(index int, set bool) int {
+	if index < 0 {
+		index = 0
+	}
+
+	for index < b.size {
+		var page *bitmapPage
+		if b.pages != nil {
+			page = b.pages[index/bitmapPageBits]
+		}
+
+		// skip the whole page if no bit in it is expected
+		pageEnd := (index/bitmapPageBits + 1) * bitmapPageBits
+		switch {
+		case page == nil && !set:
+			return index
+		case page == nil, set && page.count == 0, !set && page.count == bitmapPageBits:
+			index = pageEnd
+			continue
+		}
+
+		for index < pageEnd && index < b.size {
+			offset := index % bitmapPageBits
+			word := page.words[offset/bitmapWordBits]
+			if !set {
+				word = ^word
+			}
+			// ignore the bits before index
+			word >>= offset % bitmapWordBits
+			if word != 0 {
+				if found := index + bits.TrailingZeros64(word); found < b.size {
+					return found
+				}
+				return -1
+			}
+			index += bitmapWordBits - offset%bitmapWordBits
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,173 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSparseBitmap(t *testing.T) {
+	tests := []struct {
+		name              string
+		size              int
+		setIndexes        []int
+		clearIndexes      []int
+		from              int
+		expectedCount     int
+		expectedNextClear int
+		expectedNextSet   int
+	}{
+		{
+			name:              "empty bitmap",
+			size:              100,
+			from:              0,
+			expectedCount:     0,
+			expectedNextClear: 0,
+			expectedNextSet:   -1,
+		},
+		{
+			name:              "set bits in one word",
+			size:              100,
+			setIndexes:        []int{0, 1, 2, 5},
+			from:              0,
+			expectedCount:     4,
+			expectedNextClear: 3,
+			expectedNextSet:   0,
+		},
+		{
+			name:              "search across pages",
+			size:              3 * bitmapPageBits,
+			setIndexes:        []int{2*bitmapPageBits + 10},
+			from:              1,
+			expectedCount:     1,
+			expectedNextClear: 1,
+			expectedNextSet:   2*bitmapPageBits + 10,
+		},
+		{
+			name:              "skip full page",
+			size:              2*bitmapPageBits + 1,
+			setIndexes:        rangeIndexes(0, bitmapPageBits+3),
+			from:              0,
+			expectedCount:     bitmapPageBits + 3,
+			expectedNextClear: bitmapPageBits + 3,
+			expectedNextSet:   0,
+		},
+		{
+			name:              "full bitmap",
+			size:              70,
+			setIndexes:        rangeIndexes(0, 70),
+			from:              0,
+			expectedCount:     70,
+			expectedNextClear: -1,
+			expectedNextSet:   0,
+		},
+		{
+			name:              "cleared bits",
+			size:              70,
+			setIndexes:        rangeIndexes(0, 70),
+			clearIndexes:      []int{3, 69},
+			from:              4,
+			expectedCount:     68,
+			expectedNextClear: 69,
+			expectedNextSet:   4,
+		},
+		{
+			name:              "out of range",
+			size:              10,
+			setIndexes:        []int{-1, 10, 11},
+			from:              10,
+			expectedCount:     0,
+			expectedNextClear: -1,
+			expectedNextSet:   -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bitmap := NewSparseBitmap(test.size)
+			for _, index := range test.setIndexes {
+				bitmap.Set(index)
+			}
+			for _, index := range test.clearIndexes {
+				bitmap.Clear(index)
+			}
+
+			if bitmap.Count() != test.expectedCount {
+				t.Errorf("test %s fails: expected count %d but got %d", test.name, test.expectedCount, bitmap.Count())
+			}
+			if nextClear := bitmap.NextClear(test.from); nextClear != test.expectedNextClear {
+				t.Errorf("test %s fails: expected next clear %d but got %d", test.name, test.expectedNextClear, nextClear)
+			}
+			if nextSet := bitmap.NextSet(test.from); nextSet != test.expectedNextSet {
+				t.Errorf("test %s fails: expected next set %d but got %d", test.name, test.expectedNextSet, nextSet)
+			}
+		})
+	}
+}
+
+func TestSparseBitmap_FreePage(t *testing.T) {
+	bitmap := NewSparseBitmap(2 * bitmapPageBits)
+	if bitmap.pages != nil {
+		t.Fatalf("pages should not be allocated before setting")
+	}
+
+	bitmap.Set(bitmapPageBits + 1)
+	if bitmap.pages[0] != nil || bitmap.pages[1] == nil {
+		t.Fatalf("only the page of set bit should be allocated")
+	}
+
+	bitmap.Clear(bitmapPageBits + 1)
+	if bitmap.pages[1] != nil {
+		t.Fatalf("page should be freed if all bits are clear")
+	}
+}
+
+// BenchmarkSparseBitmap reports the memory of bitmaps for subnets of different sizes
+// under 50% utilization, comparing with dense bitmaps
+func BenchmarkSparseBitmap(b *testing.B) {
+	for _, maskSize := range []int{8, 16, 20, 24} {
+		size := 1 << (32 - maskSize)
+
+		b.Run(fmt.Sprintf("sparse/%d", maskSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				bitmap := NewSparseBitmap(size)
+				for index := 0; index < size/2; index++ {
+					bitmap.Set(index)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("dense/%d", maskSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				bitmap := make([]uint64, (size+bitmapWordBits-1)/bitmapWordBits)
+				for index := 0; index < size/2; index++ {
+					bitmap[index/bitmapWordBits] |= 1 << (index % bitmapWordBits)
+				}
+			}
+		})
+	}
+}
+
+func rangeIndexes(start, end int) (indexes []int) {
+	for i := start; i < end; i++ {
+		indexes = append(indexes, i)
+	}
+	return
+}
@@ -30,6 +30,10 @@ const (
 	DualStack = IPFamilyMode("DualStack")
 )
 
+// MaxSubnetRangeSize is the max number of IPs in range [Start, End] of a subnet,
+// which is the size of a /8 IPv4 subnet
+const MaxSubnetRangeSize = 1 << 24
+
 // short aliases
 const (
 	IPv4Alias = "IPv4"
@@ -143,3 +147,33 @@ func ParseNetworkTypeFromEnv() NetworkType {
 		return NetworkType(networkTypeEnv)
 	}
 }
+
+type FitStrategy string
+
+const (
+	// FirstFit picks the first available IP after the last allocated one
+	FirstFit = FitStrategy("first-fit")
+	// BestFit picks the first IP of the smallest contiguous free block which
+	// is able to satisfy the allocation
+	BestFit = FitStrategy("best-fit")
+)
+
+func ParseFitStrategyFromString(in string) FitStrategy {
+	switch strings.ToLower(in) {
+	case string(FirstFit), "":
+		return FirstFit
+	case string(BestFit):
+		return BestFit
+	default:
+		return FitStrategy(in)
+	}
+}
+
+func IsValidFitStrategy(fitStrategy FitStrategy) bool {
+	switch fitStrategy {
+	case FirstFit, BestFit:
+		return true
+	default:
+		return false
+	}
+}
@@ -18,8 +18,30 @@ package types
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
 )
 
+type ManagerOption interface {
+	ApplyToManager(*ManagerOptions)
+}
+
+// ManagerOptions is a collection of all configurable configs for IPAM Manager
+type ManagerOptions struct {
+	// FitStrategy decides how an IP is picked from the available ones of a subnet
+	FitStrategy FitStrategy
+}
+
+func (m *ManagerOptions) ApplyOptions(opts []ManagerOption) {
+	for _, opt := range opts {
+		opt.ApplyToManager(m)
+	}
+}
+
+func (f FitStrategy) ApplyToManager(options *ManagerOptions) {
+	options.FitStrategy = f
+}
+
 type RefreshOption interface {
 	ApplyToRefresh(*RefreshOptions)
 }
@@ -59,6 +81,9 @@ type AllocateOption interface {
 type AllocateOptions struct {
 	// Subnets is the specified subnet list where IP should be allocated from
 	Subnets []string
+
+	// LockAcquiredHook is called once the lock of IPAM manager is acquired
+	LockAcquiredHook func()
 }
 
 func (a *AllocateOptions) ApplyOptions(opts []AllocateOption) {
@@ -73,6 +98,13 @@ func (a AllocateSubnets) ApplyToAllocate(options *AllocateOptions) {
 	options.Subnets = a
 }
 
+// LockAcquiredHook is called once the lock of IPAM manager is acquired
+type LockAcquiredHook func()
+
+func (l LockAcquiredHook) ApplyToAllocate(options *AllocateOptions) {
+	options.LockAcquiredHook = l
+}
+
 type AssignOption interface {
 	ApplyToAssign(options *AssignOptions)
 }
@@ -109,6 +141,9 @@ type CoupleOptions struct {
 
 	// OwnerReference will replace the owner reference fetched from Pod explicitly
 	OwnerReference *metav1.OwnerReference
+
+	// AllocationContext will be recorded on IPs for audit
+	AllocationContext *networkingv1.AllocationContext
 }
 
 func (c *CoupleOptions) ApplyOptions(opts []CoupleOption) {
@@ -131,6 +166,9 @@ type ReCoupleOptions struct {
 
 	// OwnerReference will replace the owner reference fetched from Pod explicitly
 	OwnerReference *metav1.OwnerReference
+
+	// AllocationContext will be recorded on IPs for audit
+	AllocationContext *networkingv1.AllocationContext
 }
 
 func (r *ReCoupleOptions) ApplyOptions(opts []ReCoupleOption) {
@@ -156,14 +194,30 @@ func (r *ReserveOptions) ApplyOptions(opts []ReserveOption) {
 	}
 }
 
+// AdditionalLabels will be patched onto IPInstances, labels of multiple
+// AdditionalLabels options are merged and the latter one wins on conflict
 type AdditionalLabels map[string]string
 
 func (a AdditionalLabels) ApplyToReCouple(options *ReCoupleOptions) {
-	options.AdditionalLabels = a
+	options.AdditionalLabels = a.mergeInto(options.AdditionalLabels)
 }
 
 func (a AdditionalLabels) ApplyToCouple(options *CoupleOptions) {
-	options.AdditionalLabels = a
+	options.AdditionalLabels = a.mergeInto(options.AdditionalLabels)
+}
+
+func (a AdditionalLabels) mergeInto(orig map[string]string) map[string]string {
+	if len(orig) == 0 {
+		return a
+	}
+	merged := make(map[string]string, len(orig)+len(a))
+	for k, v := range orig {
+		merged[k] = v
+	}
+	for k, v := range a {
+		merged[k] = v
+	}
+	return merged
 }
 
 type OwnerReference metav1.OwnerReference
@@ -192,6 +246,19 @@ func ResetOwnerReference(orig *metav1.OwnerReference) OwnerReference {
 	}
 }
 
+// AllocationContext records the reconciliation which allocates or assigns IPs
+type AllocationContext networkingv1.AllocationContext
+
+func (a AllocationContext) ApplyToReCouple(options *ReCoupleOptions) {
+	aCopy := networkingv1.AllocationContext(a)
+	options.AllocationContext = &aCopy
+}
+
+func (a AllocationContext) ApplyToCouple(options *CoupleOptions) {
+	aCopy := networkingv1.AllocationContext(a)
+	options.AllocationContext = &aCopy
+}
+
 type DropPodName bool
 
 func (d DropPodName) ApplyToReserve(options *ReserveOptions) {
@@ -0,0 +1,75 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestAdditionalLabelsMerge(t *testing.T) {
+	inherited := AdditionalLabels{"team": "a", "env": "prod"}
+	vm := AdditionalLabels{"vm": "vm1", "env": "test"}
+
+	coupleOptions := &CoupleOptions{}
+	coupleOptions.ApplyOptions([]CoupleOption{inherited, vm})
+
+	expected := map[string]string{"team": "a", "env": "test", "vm": "vm1"}
+	if !reflect.DeepEqual(coupleOptions.AdditionalLabels, expected) {
+		t.Fatalf("unexpected couple labels %v, expected %v", coupleOptions.AdditionalLabels, expected)
+	}
+
+	reCoupleOptions := &ReCoupleOptions{}
+	reCoupleOptions.ApplyOptions([]ReCoupleOption{AdditionalLabels(nil), vm})
+	if !reflect.DeepEqual(reCoupleOptions.AdditionalLabels, map[string]string(vm)) {
+		t.Fatalf("unexpected re-couple labels %v, expected %v", reCoupleOptions.AdditionalLabels, vm)
+	}
+
+	// merging must not modify the original labels
+	if inherited["env"] != "prod" || len(inherited) != 2 {
+		t.Fatalf("original labels are modified: %v", inherited)
+	}
+}
+
+func TestAllocationContextOption(t *testing.T) {
+	allocationContext := AllocationContext{
+		TriggeringNode:     "node1",
+		ControllerInstance: "manager-0",
+		ReconcileID:        "0d3b8a5e-6b8e-4c8e-9f4a-1b2c3d4e5f60",
+	}
+
+	coupleOptions := &CoupleOptions{}
+	coupleOptions.ApplyOptions([]CoupleOption{allocationContext})
+	if coupleOptions.AllocationContext == nil ||
+		*coupleOptions.AllocationContext != networkingv1.AllocationContext(allocationContext) {
+		t.Fatalf("unexpected couple allocation context %v", coupleOptions.AllocationContext)
+	}
+
+	reCoupleOptions := &ReCoupleOptions{}
+	reCoupleOptions.ApplyOptions([]ReCoupleOption{allocationContext})
+	if reCoupleOptions.AllocationContext == nil ||
+		*reCoupleOptions.AllocationContext != networkingv1.AllocationContext(allocationContext) {
+		t.Fatalf("unexpected re-couple allocation context %v", reCoupleOptions.AllocationContext)
+	}
+
+	// options of different actions must not share the same context
+	if coupleOptions.AllocationContext == reCoupleOptions.AllocationContext {
+		t.Fatalf("allocation context is shared between options")
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/alibaba/hybridnet/pkg/utils"
+)
+
+// poolRange is the range [start, end] of allocatable IPs in a CIDR of pool, offset is
+// the index of start in pool
+type poolRange struct {
+	cidr   *net.IPNet
+	start  net.IP
+	end    net.IP
+	offset int
+	size   int
+}
+
+// Pool is an ordered list of non-contiguous CIDRs of the same IP family, the allocatable
+// IPs of all the CIDRs are indexed continuously following the order of CIDRs, so IPs of a
+// former CIDR always have smaller indexes than the ones of a latter CIDR.
+//
+// The allocatable IPs of a CIDR are the ones in range [network address + 1, last IP], the
+// broadcast address of IPv4 excluded, and at most the first MaxSubnetRangeSize IPs of a
+// CIDR will be indexed, which are enough for pod IPs of a large IPv6 prefix like /64.
+type Pool struct {
+	ranges []*poolRange
+	size   int
+	ipv6   bool
+}
+
+func NewPool(cidrs []string) (*Pool, error) {
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("pool must contain at least one cidr")
+	}
+
+	p := &Pool{}
+	for i, cidrStr := range cidrs {
+		_, cidr, err := net.ParseCIDR(cidrStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %s: %v", cidrStr, err)
+		}
+
+		ipv6 := cidr.IP.To4() == nil
+		if i == 0 {
+			p.ipv6 = ipv6
+		} else if ipv6 != p.ipv6 {
+			return nil, fmt.Errorf("cidr %s is not of the same ip family as %s", cidrStr, cidrs[0])
+		}
+
+		ones, bits := cidr.Mask.Size()
+		if ones > bits-2 {
+			return nil, fmt.Errorf("cidr %s too small to allocate from", cidrStr)
+		}
+
+		for _, r := range p.ranges {
+			if r.cidr.Contains(cidr.IP) || cidr.Contains(r.cidr.IP) {
+				return nil, fmt.Errorf("cidr %s overlaps with %s", cidrStr, r.cidr.String())
+			}
+		}
+
+		start, end := utils.NextIP(cidr.IP), utils.LastIP(cidr)
+		capacity := utils.Capacity(start, end)
+		size := MaxSubnetRangeSize
+		if capacity.IsInt64() && capacity.Int64() < MaxSubnetRangeSize {
+			size = int(capacity.Int64())
+		}
+		end = utils.OffsetIP(start, int64(size-1))
+
+		p.ranges = append(p.ranges, &poolRange{
+			cidr:   cidr,
+			start:  start,
+			end:    end,
+			offset: p.size,
+			size:   size,
+		})
+		p.size += size
+	}
+
+	return p, nil
+}
+
+// Size returns the number of indexed IPs of pool
+func (p *Pool) Size() int {
+	return p.size
+}
+
+// CIDRs returns the CIDRs of pool in order
+func (p *Pool) CIDRs() []*net.IPNet {
+	cidrs := make([]*net.IPNet, 0, len(p.ranges))
+	for _, r := range p.ranges {
+		cidrs = append(cidrs, r.cidr)
+	}
+	return cidrs
+}
+
+func (p *Pool) IsIPv6() bool {
+	return p.ipv6
+}
+
+// Contains checks if ip is an indexed IP of pool
+func (p *Pool) Contains(ip net.IP) bool {
+	return p.rangeOf(ip) != nil
+}
+
+// CIDROf returns the CIDR which ip belongs to, nil will be returned if ip is not indexed
+func (p *Pool) CIDROf(ip net.IP) *net.IPNet {
+	if r := p.rangeOf(ip); r != nil {
+		return r.cidr
+	}
+	return nil
+}
+
+// IndexOf returns the index of ip in pool, -1 will be returned if ip is not indexed
+func (p *Pool) IndexOf(ip net.IP) int {
+	r := p.rangeOf(ip)
+	if r == nil {
+		return -1
+	}
+	return r.offset + int(utils.Capacity(r.start, ip).Int64()) - 1
+}
+
+// IPAt returns the IP of index in pool, nil will be returned if index is out of range
+func (p *Pool) IPAt(index int) net.IP {
+	if index < 0 || index >= p.size {
+		return nil
+	}
+
+	// find the last range whose offset is not greater than index
+	i := sort.Search(len(p.ranges), func(i int) bool {
+		return p.ranges[i].offset > index
+	}) - 1
+	r := p.ranges[i]
+	return utils.OffsetIP(r.start, int64(index-r.offset))
+}
+
+func (p *Pool) rangeOf(ip net.IP) *poolRange {
+	if ip == nil {
+		return nil
+	}
+
+	for _, r := range p.ranges {
+		if r.cidr.Contains(ip) && utils.Cmp(ip, r.start) >= 0 && utils.Cmp(ip, r.end) <= 0 {
+			return r
+		}
+	}
+	return nil
+}
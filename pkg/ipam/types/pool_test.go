@@ -0,0 +1,105 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPoolIndex(t *testing.T) {
+	pool, err := NewPool([]string{"10.0.2.0/30", "10.0.0.0/29"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 10.0.2.1-10.0.2.2 and 10.0.0.1-10.0.0.6
+	if pool.Size() != 8 {
+		t.Fatalf("expected size 8, got %d", pool.Size())
+	}
+
+	tests := []struct {
+		ip    string
+		index int
+		cidr  string
+	}{
+		{"10.0.2.1", 0, "10.0.2.0/30"},
+		{"10.0.2.2", 1, "10.0.2.0/30"},
+		{"10.0.0.1", 2, "10.0.0.0/29"},
+		{"10.0.0.6", 7, "10.0.0.0/29"},
+		{"10.0.2.0", -1, ""},
+		{"10.0.2.3", -1, ""},
+		{"10.0.0.7", -1, ""},
+		{"10.0.1.1", -1, ""},
+	}
+
+	for _, test := range tests {
+		ip := net.ParseIP(test.ip)
+		if index := pool.IndexOf(ip); index != test.index {
+			t.Errorf("expected index of %s to be %d, got %d", test.ip, test.index, index)
+		}
+
+		cidr := pool.CIDROf(ip)
+		if (cidr == nil && test.cidr != "") || (cidr != nil && cidr.String() != test.cidr) {
+			t.Errorf("expected cidr of %s to be %q, got %v", test.ip, test.cidr, cidr)
+		}
+
+		if test.index >= 0 && !pool.IPAt(test.index).Equal(ip) {
+			t.Errorf("expected ip at %d to be %s, got %v", test.index, test.ip, pool.IPAt(test.index))
+		}
+	}
+
+	if pool.IPAt(8) != nil || pool.IPAt(-1) != nil {
+		t.Errorf("expected nil ip for out of range indexes")
+	}
+}
+
+func TestPoolLargeIPv6Prefix(t *testing.T) {
+	pool, err := NewPool([]string{"fd00:0:0:1::/64", "fd00:0:0:3::/64"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pool.Size() != 2*MaxSubnetRangeSize {
+		t.Fatalf("expected size %d, got %d", 2*MaxSubnetRangeSize, pool.Size())
+	}
+
+	if ip := pool.IPAt(MaxSubnetRangeSize); !ip.Equal(net.ParseIP("fd00:0:0:3::1")) {
+		t.Fatalf("expected the first ip of second prefix, got %v", ip)
+	}
+}
+
+func TestNewPoolInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		cidrs []string
+	}{
+		{"empty", nil},
+		{"invalid cidr", []string{"10.0.0.0/33"}},
+		{"mixed families", []string{"10.0.0.0/24", "fd00::/120"}},
+		{"overlapped", []string{"10.0.0.0/16", "10.0.1.0/24"}},
+		{"too small", []string{"10.0.0.0/31"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewPool(test.cidrs); err == nil {
+				t.Fatalf("expected error for cidrs %v", test.cidrs)
+			}
+		})
+	}
+}
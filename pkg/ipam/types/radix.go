@@ -0,0 +1,190 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import "math/bits"
+
+// radixNode covers a power-of-two range of bitmap words, used is the number of set bits
+// in the range, leaves hold the words
+type radixNode struct {
+	used     int
+	children [2]*radixNode
+	word     uint64
+}
+
+// RadixBitmap is a bitmap of a fixed size organized as a binary radix tree over 64-bit words,
+// nodes are allocated on demand and every node knows the number of set bits under it, so
+// searching for the next clear or set bit skips full or empty subtrees in O(log n), while
+// SparseBitmap has to walk through all the pages before the found one.
+type RadixBitmap struct {
+	size   int
+	levels int
+	root   *radixNode
+}
+
+func NewRadixBitmap(size int) *RadixBitmap {
+	words := (size + bitmapWordBits - 1) / bitmapWordBits
+	levels := 0
+	for 1<<levels < words {
+		levels++
+	}
+	return &RadixBitmap{
+		size:   size,
+		levels: levels,
+	}
+}
+
+// Size returns the number of bits in bitmap
+func (b *RadixBitmap) Size() int {
+	return b.size
+}
+
+// Count returns the number of set bits in bitmap
+func (b *RadixBitmap) Count() int {
+	if b.root == nil {
+		return 0
+	}
+	return b.root.used
+}
+
+// Test returns whether the bit of index is set
+func (b *RadixBitmap) Test(index int) bool {
+	if index < 0 || index >= b.size {
+		return false
+	}
+
+	node := b.root
+	for level := b.levels; node != nil && level > 0; level-- {
+		node = node.children[(index>>(level-1+6))&1]
+	}
+	return node != nil && node.word&(1<<(index%bitmapWordBits)) != 0
+}
+
+// Set sets the bit of index, the nodes on path will be allocated if they are nil
+func (b *RadixBitmap) Set(index int) {
+	if index < 0 || index >= b.size || b.Test(index) {
+		return
+	}
+
+	if b.root == nil {
+		b.root = &radixNode{}
+	}
+	node := b.root
+	node.used++
+	for level := b.levels; level > 0; level-- {
+		child := &node.children[(index>>(level-1+6))&1]
+		if *child == nil {
+			*child = &radixNode{}
+		}
+		node = *child
+		node.used++
+	}
+	node.word |= 1 << (index % bitmapWordBits)
+}
+
+// Clear clears the bit of index, the nodes without any set bit under them will be freed
+func (b *RadixBitmap) Clear(index int) {
+	if !b.Test(index) {
+		return
+	}
+
+	path := make([]**radixNode, 0, b.levels+1)
+	path = append(path, &b.root)
+	node := b.root
+	for level := b.levels; level > 0; level-- {
+		child := &node.children[(index>>(level-1+6))&1]
+		path = append(path, child)
+		node = *child
+	}
+	node.word &^= 1 << (index % bitmapWordBits)
+
+	for _, p := range path {
+		(*p).used--
+	}
+	// free empty nodes from bottom to top
+	for i := len(path) - 1; i >= 0 && (*path[i]).used == 0; i-- {
+		*path[i] = nil
+	}
+}
+
+// NextClear returns the index of the first clear bit from index, -1 will be returned if not found
+func (b *RadixBitmap) NextClear(index int) int {
+	if index < 0 {
+		index = 0
+	}
+	return b.next(b.root, b.levels, 0, index, false)
+}
+
+// NextSet returns the index of the first set bit from index, -1 will be returned if not found
+func (b *RadixBitmap) NextSet(index int) int {
+	if index < 0 {
+		index = 0
+	}
+	return b.next(b.root, b.levels, 0, index, true)
+}
+
+// next searches in the subtree of node whose first bit is base
+func (b *RadixBitmap) next(node *radixNode, level, base, index int, set bool) int {
+	span := bitmapWordBits << level
+	if base+span > b.size {
+		span = b.size - base
+	}
+	if span <= 0 || index >= base+span {
+		return -1
+	}
+
+	used := 0
+	if node != nil {
+		used = node.used
+	}
+	switch {
+	case set && used == 0, !set && used == span:
+		return -1
+	case !set && node == nil:
+		if index > base {
+			return index
+		}
+		return base
+	}
+
+	if level == 0 {
+		word := node.word
+		if !set {
+			word = ^word
+		}
+		offset := 0
+		if index > base {
+			offset = index - base
+		}
+		word >>= offset
+		if word == 0 {
+			return -1
+		}
+		if found := base + offset + bits.TrailingZeros64(word); found < b.size {
+			return found
+		}
+		return -1
+	}
+
+	half := bitmapWordBits << (level - 1)
+	if index < base+half {
+		if found := b.next(node.children[0], level-1, base, index, set); found >= 0 {
+			return found
+		}
+	}
+	return b.next(node.children[1], level-1, base+half, index, set)
+}
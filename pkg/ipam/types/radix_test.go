@@ -0,0 +1,89 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package types
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestRadixBitmapMatchesSparseBitmap runs random operations on both bitmaps and expects
+// the same results
+func TestRadixBitmapMatchesSparseBitmap(t *testing.T) {
+	for _, size := range []int{1, 63, 64, 65, 1000, 3*bitmapPageBits + 17} {
+		r := rand.New(rand.NewSource(int64(size)))
+		radix, sparse := NewRadixBitmap(size), NewSparseBitmap(size)
+
+		for i := 0; i < 20*size; i++ {
+			index := r.Intn(size+2) - 1
+			switch r.Intn(4) {
+			case 0, 1:
+				radix.Set(index)
+				sparse.Set(index)
+			case 2:
+				radix.Clear(index)
+				sparse.Clear(index)
+			}
+
+			if radix.Count() != sparse.Count() {
+				t.Fatalf("size %d: expected count %d, got %d", size, sparse.Count(), radix.Count())
+			}
+			if radix.Test(index) != sparse.Test(index) {
+				t.Fatalf("size %d: unexpected test result of %d", size, index)
+			}
+			if e, g := sparse.NextClear(index), radix.NextClear(index); e != g {
+				t.Fatalf("size %d: expected next clear from %d to be %d, got %d", size, index, e, g)
+			}
+			if e, g := sparse.NextSet(index), radix.NextSet(index); e != g {
+				t.Fatalf("size %d: expected next set from %d to be %d, got %d", size, index, e, g)
+			}
+		}
+	}
+}
+
+func TestRadixBitmapFreesEmptyNodes(t *testing.T) {
+	b := NewRadixBitmap(10 * bitmapPageBits)
+	b.Set(5)
+	b.Set(9*bitmapPageBits + 1)
+	b.Clear(5)
+	b.Clear(9*bitmapPageBits + 1)
+
+	if b.root != nil || b.Count() != 0 {
+		t.Fatalf("expected all nodes to be freed")
+	}
+
+	if b.NextClear(0) != 0 || b.NextSet(0) != -1 {
+		t.Fatalf("unexpected search result on empty bitmap")
+	}
+}
+
+func TestRadixBitmapFull(t *testing.T) {
+	size := 130
+	b := NewRadixBitmap(size)
+	for i := 0; i < size; i++ {
+		b.Set(i)
+	}
+
+	if b.NextClear(0) != -1 {
+		t.Fatalf("expected no clear bit in full bitmap, got %d", b.NextClear(0))
+	}
+
+	b.Clear(129)
+	if b.NextClear(0) != 129 {
+		t.Fatalf("expected clear bit 129, got %d", b.NextClear(0))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/alibaba/hybridnet/pkg/utils"
 )
@@ -29,8 +30,23 @@ var (
 	ErrNotFoundSubnet         = errors.New("subnet not found")
 	ErrNotFoundAssignedIP     = errors.New("assigned ip not found")
 	ErrNotAvailableAssignedIP = errors.New("assigned ip is not available")
+	ErrSubnetExhausted        = errors.New("subnet exhausted")
 )
 
+// SubnetExhaustedError is returned if a specific subnet runs out of addresses,
+// it matches ErrSubnetExhausted and tells which subnet is exhausted
+type SubnetExhaustedError struct {
+	Subnet string
+}
+
+func (e *SubnetExhaustedError) Error() string {
+	return ErrSubnetExhausted.Error()
+}
+
+func (e *SubnetExhaustedError) Unwrap() error {
+	return ErrSubnetExhausted
+}
+
 func NewSubnetSlice(lastAllocatedSubnet string) *SubnetSlice {
 	return &SubnetSlice{
 		Subnets:             make([]*Subnet, 0),
@@ -72,13 +88,19 @@ func (s *SubnetSlice) GetAvailableSubnet() (*Subnet, error) {
 	}
 
 	lastIndex := s.SubnetIndex
+	exhausted := false
 	for {
 		if s.Subnets[s.SubnetIndex].IsAvailable() {
 			return s.Subnets[s.SubnetIndex], nil
 		}
+		// non-private subnets which are not available must run out of addresses
+		exhausted = exhausted || !s.Subnets[s.SubnetIndex].Private
 
 		s.SubnetIndex = (s.SubnetIndex + 1) % s.SubnetCount
 		if s.SubnetIndex == lastIndex {
+			if exhausted {
+				return nil, fmt.Errorf("%v: %w", ErrNoAvailableSubnet, ErrSubnetExhausted)
+			}
 			return nil, ErrNoAvailableSubnet
 		}
 	}
@@ -238,7 +260,7 @@ func (s *Subnet) Contains(addr net.IP) bool {
 	return true
 }
 
-// Sync will generate netID, filtered Reserved List, Available IP Slice
+// Sync will generate netID, filtered Reserved List, Available IP Range
 // and Using IP Set based on subnet spec and input
 func (s *Subnet) Sync(parentNetID *uint32, ipSet IPSet) error {
 	// generate valid netID, inherit from parent if NetID is null
@@ -283,20 +305,142 @@ func (s *Subnet) Sync(parentNetID *uint32, ipSet IPSet) error {
 		}
 	}
 
-	// generate valid Available IP Slice
-	s.AvailableIPs = NewIPSlice()
-	for i := s.Start; utils.Cmp(i, s.End) <= 0; i = utils.NextIP(i) {
-		if !s.Contains(i) {
+	// generate valid available IP range, bitmap will not be initialized
+	// until the first allocation request
+	s.rangeSize = 0
+	if utils.Cmp(s.Start, s.End) <= 0 {
+		capacity := utils.Capacity(s.Start, s.End)
+		if !capacity.IsInt64() || capacity.Int64() > MaxSubnetRangeSize {
+			return fmt.Errorf("subnet %s contains more than %d IPs", s.Name, MaxSubnetRangeSize)
+		}
+		s.rangeSize = int(capacity.Int64())
+	}
+	s.bitmap = nil
+	s.AvailableIPCount = s.rangeSize - len(s.unavailableIndexes())
+
+	s.lastAllocatedIndex = -1
+	if s.LastAllocatedIP != nil && s.Contains(s.LastAllocatedIP) && !s.IsReservedIP(s.LastAllocatedIP.String()) {
+		s.lastAllocatedIndex = s.indexOf(s.LastAllocatedIP)
+	}
+
+	return nil
+}
+
+// unavailableIndexes returns the indexes of IPs which are in range [Start, End]
+// but can not be allocated, including gateway, black list and reserved IPs
+func (s *Subnet) unavailableIndexes() map[int]struct{} {
+	var candidates []net.IP
+	if s.Gateway != nil {
+		candidates = append(candidates, s.Gateway)
+	}
+	for ip := range s.BlackList {
+		candidates = append(candidates, net.ParseIP(ip))
+	}
+	for ip := range s.ReservedList {
+		candidates = append(candidates, net.ParseIP(ip))
+	}
+
+	var indexes = make(map[int]struct{})
+	for _, ip := range candidates {
+		if ip == nil || !s.CIDR.Contains(ip) || utils.Cmp(ip, s.Start) < 0 || utils.Cmp(ip, s.End) > 0 {
+			continue
+		}
+		indexes[s.indexOf(ip)] = struct{}{}
+	}
+
+	// black ranges are clipped by [Start, End]
+	for _, ipRange := range s.BlackRanges {
+		if (ipRange.Start.To4() == nil) != (s.Start.To4() == nil) {
 			continue
 		}
-		// ignore reserved ip
-		if s.IsReservedIP(i.String()) {
+
+		start, end := ipRange.Start, ipRange.End
+		if utils.Cmp(start, s.Start) < 0 {
+			start = s.Start
+		}
+		if utils.Cmp(end, s.End) > 0 {
+			end = s.End
+		}
+		if utils.Cmp(start, end) > 0 {
 			continue
 		}
-		s.AvailableIPs.Add(i.String(), i.Equal(s.LastAllocatedIP))
+
+		for index, last := s.indexOf(start), s.indexOf(end); index <= last; index++ {
+			indexes[index] = struct{}{}
+		}
 	}
+	return indexes
+}
 
-	return nil
+// indexOf returns the offset of IP from Start, IP must be in range [Start, End]
+func (s *Subnet) indexOf(ip net.IP) int {
+	return int(utils.Capacity(s.Start, ip).Int64()) - 1
+}
+
+// ipAt returns the IP of index in range [Start, End]
+func (s *Subnet) ipAt(index int) net.IP {
+	return utils.OffsetIP(s.Start, int64(index))
+}
+
+// ensureBitmap initializes bitmap with unavailable and using IPs if it is not initialized
+func (s *Subnet) ensureBitmap() {
+	if s.bitmap != nil {
+		return
+	}
+
+	s.bitmap = NewSparseBitmap(s.rangeSize)
+	for index := range s.unavailableIndexes() {
+		s.bitmap.Set(index)
+	}
+	for _, usingIP := range s.UsingIPs {
+		if s.Contains(usingIP.Address.IP) {
+			s.bitmap.Set(s.indexOf(usingIP.Address.IP))
+		}
+	}
+}
+
+// AllocatedIPs returns the IPs marked as used in bitmap, except the unavailable ones like
+// gateway, black list and reserved IPs, bitmap will be initialized if it is not
+func (s *Subnet) AllocatedIPs() []string {
+	s.ensureBitmap()
+
+	unavailableIndexes := s.unavailableIndexes()
+	var ips []string
+	for index := s.bitmap.NextSet(0); index >= 0; index = s.bitmap.NextSet(index + 1) {
+		if _, unavailable := unavailableIndexes[index]; unavailable {
+			continue
+		}
+		ips = append(ips, s.ipAt(index).String())
+	}
+	return ips
+}
+
+// AllocatedIPSnapshot returns the set of allocated IPs like AllocatedIPs but never initializes
+// bitmap, so it is safe to be called concurrently under read lock. Only set bits of bitmap or
+// using IPs are iterated.
+func (s *Subnet) AllocatedIPSnapshot() map[string]struct{} {
+	unavailableIndexes := s.unavailableIndexes()
+	ips := make(map[string]struct{})
+	if s.bitmap == nil {
+		for _, usingIP := range s.UsingIPs {
+			if !s.Contains(usingIP.Address.IP) {
+				continue
+			}
+			if _, unavailable := unavailableIndexes[s.indexOf(usingIP.Address.IP)]; unavailable {
+				continue
+			}
+			ips[usingIP.Address.IP.String()] = struct{}{}
+		}
+		return ips
+	}
+
+	for index := s.bitmap.NextSet(0); index >= 0; index = s.bitmap.NextSet(index + 1) {
+		if _, unavailable := unavailableIndexes[index]; unavailable {
+			continue
+		}
+		ips[s.ipAt(index).String()] = struct{}{}
+	}
+	return ips
 }
 
 // Overlap must be called **after** Canonicalize
@@ -312,7 +456,7 @@ func (s *Subnet) Overlap(s1 *Subnet) bool {
 }
 
 func (s *Subnet) IsAvailable() bool {
-	return s.AvailableIPs.Count() > s.UsingIPCount() && !s.Private
+	return s.AvailableIPCount > s.UsingIPCount() && !s.Private
 }
 
 // UsingIPCount will count the IP which are being used, but
@@ -322,48 +466,131 @@ func (s *Subnet) UsingIPCount() int {
 }
 
 func (s *Subnet) Usage() *Usage {
+	var lastAllocation string
+	if s.lastAllocatedIndex >= 0 {
+		lastAllocation = s.ipAt(s.lastAllocatedIndex).String()
+	}
+
 	return &Usage{
-		Total:          uint32(s.AvailableIPs.Count()),
+		Total:          uint32(s.AvailableIPCount),
 		Used:           uint32(s.UsingIPCount()),
-		Available:      uint32(s.AvailableIPs.Count() - s.UsingIPCount()),
-		LastAllocation: s.AvailableIPs.Current(),
+		Available:      uint32(s.AvailableIPCount - s.UsingIPCount()),
+		LastAllocation: lastAllocation,
+
+		LastAllocationTime: s.lastAllocationTime,
+		LastReleaseTime:    s.lastReleaseTime,
+	}
+}
+
+// Allocate picks an available IP for pod following the given fit strategy
+func (s *Subnet) Allocate(fitStrategy FitStrategy, podName, podNamespace string) *IP {
+	switch fitStrategy {
+	case BestFit:
+		return s.AllocateBestFit(podName, podNamespace)
+	default:
+		return s.AllocateNext(podName, podNamespace)
 	}
 }
 
 func (s *Subnet) AllocateNext(podName, podNamespace string) *IP {
-	for i := 0; i < s.AvailableIPs.Count(); i++ {
-		ipCandidate := s.AvailableIPs.Next()
-		if s.UsingIPs.Has(ipCandidate) {
+	s.ensureBitmap()
+
+	// search from the next one of last allocated IP, and wrap around if not found
+	index := s.bitmap.NextClear(s.lastAllocatedIndex + 1)
+	if index < 0 {
+		index = s.bitmap.NextClear(0)
+	}
+	if index < 0 {
+		return nil
+	}
+
+	return s.allocate(index, podName, podNamespace)
+}
+
+// AllocateBestFit picks the first IP of the smallest contiguous free block, so that
+// larger free blocks will be kept for the later allocations
+func (s *Subnet) AllocateBestFit(podName, podNamespace string) *IP {
+	s.ensureBitmap()
+
+	index := s.findBestFitBlock(1)
+	if index < 0 {
+		return nil
+	}
+
+	return s.allocate(index, podName, podNamespace)
+}
+
+// findBestFitBlock returns the start index of the smallest contiguous free block whose
+// size is not less than the required one, -1 will be returned if not found
+func (s *Subnet) findBestFitBlock(size int) int {
+	bestIndex, bestSize := -1, 0
+	for _, block := range s.freeBlocks() {
+		blockSize := block[1] - block[0]
+		if blockSize < size {
 			continue
 		}
-
-		availableIP := &IP{
-			Address: &net.IPNet{
-				IP:   net.ParseIP(ipCandidate),
-				Mask: s.CIDR.Mask,
-			},
-			Gateway:      s.Gateway,
-			NetID:        s.NetID,
-			Subnet:       s.Name,
-			Network:      s.ParentNetwork,
-			PodName:      podName,
-			PodNamespace: podNamespace,
-			Status:       IPStatusAllocated,
+		if bestIndex < 0 || blockSize < bestSize {
+			bestIndex, bestSize = block[0], blockSize
 		}
+		// no block can fit better
+		if bestSize == size {
+			break
+		}
+	}
+	return bestIndex
+}
 
-		s.UsingIPs.Add(ipCandidate, availableIP)
-
-		return availableIP
+// freeBlocks splits the clear bits of bitmap into contiguous blocks, every block
+// is presented as an index range [start, end)
+func (s *Subnet) freeBlocks() (blocks [][2]int) {
+	for start := s.bitmap.NextClear(0); start >= 0; {
+		end := s.bitmap.NextSet(start)
+		if end < 0 {
+			blocks = append(blocks, [2]int{start, s.rangeSize})
+			break
+		}
+		blocks = append(blocks, [2]int{start, end})
+		start = s.bitmap.NextClear(end)
 	}
+	return
+}
 
-	return nil
+func (s *Subnet) allocate(index int, podName, podNamespace string) *IP {
+	allocatedIP := &IP{
+		Address: &net.IPNet{
+			IP:   s.ipAt(index),
+			Mask: s.CIDR.Mask,
+		},
+		Gateway:      s.Gateway,
+		NetID:        s.NetID,
+		Subnet:       s.Name,
+		Network:      s.ParentNetwork,
+		PodName:      podName,
+		PodNamespace: podNamespace,
+		Status:       IPStatusAllocated,
+	}
+
+	s.UsingIPs.Add(allocatedIP.Address.IP.String(), allocatedIP)
+	s.bitmap.Set(index)
+	s.lastAllocatedIndex = index
+	s.lastAllocationTime = time.Now()
+
+	return allocatedIP
 }
 
 func (s *Subnet) Release(ip string) {
 	if s.IsReservedIP(ip) {
 		s.UsingIPs.Update(ip, "", "", IPStatusReserved)
-	} else {
+		s.lastReleaseTime = time.Now()
+		return
+	}
+
+	if usingIP := s.UsingIPs.Get(ip); usingIP != nil {
 		s.UsingIPs.Delete(ip)
+		s.lastReleaseTime = time.Now()
+		if s.bitmap != nil && s.Contains(usingIP.Address.IP) {
+			s.bitmap.Clear(s.indexOf(usingIP.Address.IP))
+		}
 	}
 }
 
@@ -378,6 +605,9 @@ func (s *Subnet) Assign(podName, podNamespace, ip string, forced bool) (*IP, err
 
 	switch {
 	case !s.UsingIPs.Has(ip):
+		if s.bitmap != nil {
+			s.bitmap.Set(s.indexOf(net.ParseIP(ip)))
+		}
 		s.UsingIPs.Add(ip, &IP{
 			Address: &net.IPNet{
 				IP:   net.ParseIP(ip),
@@ -399,6 +629,7 @@ func (s *Subnet) Assign(podName, podNamespace, ip string, forced bool) (*IP, err
 		return nil, ErrNotAvailableAssignedIP
 	}
 
+	s.lastAllocationTime = time.Now()
 	return s.UsingIPs.Get(ip), nil
 }
 
@@ -408,8 +639,18 @@ func (s *Subnet) IsReservedIP(ip string) bool {
 }
 
 func (s *Subnet) IsBlackIP(ip string) bool {
-	_, found := s.BlackList[ip]
-	return found
+	if _, found := s.BlackList[ip]; found {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	for _, ipRange := range s.BlackRanges {
+		if (addr.To4() == nil) == (ipRange.Start.To4() == nil) &&
+			utils.Cmp(addr, ipRange.Start) >= 0 && utils.Cmp(addr, ipRange.End) <= 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Subnet) IsIPv6() bool {
@@ -17,8 +17,11 @@
 package types
 
 import (
+	"fmt"
+	"math/rand"
 	"net"
 	"testing"
+	"time"
 )
 
 func TestSubnetSlice_CurrentSubnetName(t *testing.T) {
@@ -98,3 +101,261 @@ func TestSubnet_SyncSubnetStartsWithZeroByteIP(t *testing.T) {
 		t.Fatalf("fail to sync: %v", err)
 	}
 }
+
+func TestSubnet_AllocateBestFit(t *testing.T) {
+	var err error
+	var cidr *net.IPNet
+	var ip net.IP
+
+	ip, cidr, _ = net.ParseCIDR("192.168.0.1/24")
+	subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+	if err = subnet.Canonicalize(); err != nil {
+		t.Fatalf("fail to canonicalize: %v", err)
+	}
+
+	// leave a free block of only one IP 192.168.0.10 and a large one from 192.168.0.21
+	usingIPs := NewIPSet()
+	for i := 2; i <= 20; i++ {
+		if i == 10 {
+			continue
+		}
+		usingIP := fmt.Sprintf("192.168.0.%d", i)
+		usingIPs.Add(usingIP, &IP{
+			Address: &net.IPNet{IP: net.ParseIP(usingIP), Mask: cidr.Mask},
+			Subnet:  "test",
+			Status:  IPStatusAllocated,
+		})
+	}
+	if err = subnet.Sync(nil, usingIPs); err != nil {
+		t.Fatalf("fail to sync: %v", err)
+	}
+
+	for _, expected := range []string{"192.168.0.10", "192.168.0.21", "192.168.0.22"} {
+		allocatedIP := subnet.Allocate(BestFit, "", "")
+		if allocatedIP == nil {
+			t.Fatalf("fail to allocate ip, expected %s", expected)
+		}
+		if allocatedIP.Address.IP.String() != expected {
+			t.Fatalf("expected %s but got %s", expected, allocatedIP.Address.IP.String())
+		}
+		if subnet.Usage().LastAllocation != expected {
+			t.Fatalf("expected last allocation %s but got %s", expected, subnet.Usage().LastAllocation)
+		}
+	}
+}
+
+func TestSubnet_LazyBitmap(t *testing.T) {
+	ip, cidr, _ := net.ParseCIDR("10.0.0.1/8")
+	subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, map[string]struct{}{"10.0.0.2": {}}, nil, nil, false, false)
+	if err := subnet.Canonicalize(); err != nil {
+		t.Fatalf("fail to canonicalize: %v", err)
+	}
+	if err := subnet.Sync(nil, NewIPSet()); err != nil {
+		t.Fatalf("fail to sync: %v", err)
+	}
+
+	if subnet.bitmap != nil {
+		t.Fatalf("bitmap should not be initialized before allocation")
+	}
+	// network address, broadcast address, gateway and reserved IP are excluded
+	if expected := 1<<24 - 4; subnet.Usage().Total != uint32(expected) {
+		t.Fatalf("expected total %d but got %d", expected, subnet.Usage().Total)
+	}
+
+	allocatedIP := subnet.AllocateNext("", "")
+	if allocatedIP == nil || allocatedIP.Address.IP.String() != "10.0.0.3" {
+		t.Fatalf("expected 10.0.0.3 but got %v", allocatedIP)
+	}
+	if subnet.bitmap == nil {
+		t.Fatalf("bitmap should be initialized after allocation")
+	}
+
+	subnet.Release("10.0.0.3")
+	if subnet.Usage().Used != 0 || subnet.bitmap.Test(subnet.indexOf(net.ParseIP("10.0.0.3"))) {
+		t.Fatalf("fail to release 10.0.0.3")
+	}
+}
+
+func TestSubnet_AllocatedIPs(t *testing.T) {
+	ip, cidr, _ := net.ParseCIDR("10.0.0.1/24")
+	subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, map[string]struct{}{"10.0.0.2": {}}, nil, nil, false, false)
+	if err := subnet.Canonicalize(); err != nil {
+		t.Fatalf("fail to canonicalize: %v", err)
+	}
+	if err := subnet.Sync(nil, NewIPSet()); err != nil {
+		t.Fatalf("fail to sync: %v", err)
+	}
+
+	// gateway and reserved IP are not reported
+	if ips := subnet.AllocatedIPs(); len(ips) != 0 {
+		t.Fatalf("expected no allocated ip but got %v", ips)
+	}
+
+	subnet.AllocateNext("", "")
+	subnet.AllocateNext("", "")
+	subnet.Release("10.0.0.3")
+	if ips := subnet.AllocatedIPs(); len(ips) != 1 || ips[0] != "10.0.0.4" {
+		t.Fatalf("expected [10.0.0.4] but got %v", ips)
+	}
+}
+
+func TestSubnet_AllocationTime(t *testing.T) {
+	ip, cidr, _ := net.ParseCIDR("10.0.0.1/24")
+	subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+	if err := subnet.Canonicalize(); err != nil {
+		t.Fatalf("fail to canonicalize: %v", err)
+	}
+	if err := subnet.Sync(nil, NewIPSet()); err != nil {
+		t.Fatalf("fail to sync: %v", err)
+	}
+
+	if usage := subnet.Usage(); !usage.LastAllocationTime.IsZero() || !usage.LastReleaseTime.IsZero() {
+		t.Fatalf("allocation and release time should be zero before any allocation")
+	}
+
+	beforeAllocation := time.Now()
+	allocatedIP := subnet.AllocateNext("", "")
+	if allocatedIP == nil {
+		t.Fatalf("fail to allocate")
+	}
+	if usage := subnet.Usage(); usage.LastAllocationTime.Before(beforeAllocation) || !usage.LastReleaseTime.IsZero() {
+		t.Fatalf("unexpected allocation time %v and release time %v", usage.LastAllocationTime, usage.LastReleaseTime)
+	}
+
+	beforeRelease := time.Now()
+	subnet.Release(allocatedIP.Address.IP.String())
+	if usage := subnet.Usage(); usage.LastReleaseTime.Before(beforeRelease) {
+		t.Fatalf("unexpected release time %v", usage.LastReleaseTime)
+	}
+
+	// releasing an IP not in use should not change the release time
+	lastReleaseTime := subnet.Usage().LastReleaseTime
+	subnet.Release("10.0.0.100")
+	if usage := subnet.Usage(); !usage.LastReleaseTime.Equal(lastReleaseTime) {
+		t.Fatalf("release time should not change for ip not in use")
+	}
+}
+
+func BenchmarkSubnet_AllocateFirstFit(b *testing.B) {
+	benchmarkSubnetAllocateWithChurn(b, FirstFit)
+}
+
+func BenchmarkSubnet_AllocateBestFit(b *testing.B) {
+	benchmarkSubnetAllocateWithChurn(b, BestFit)
+}
+
+// BenchmarkSubnet_Memory reports the memory of subnets of different sizes after syncing,
+// and with 50% utilization of the bitmap
+func BenchmarkSubnet_Memory(b *testing.B) {
+	for _, maskSize := range []int{8, 16, 20, 24} {
+		ip, cidr, _ := net.ParseCIDR(fmt.Sprintf("10.0.0.1/%d", maskSize))
+
+		b.Run(fmt.Sprintf("sync/%d", maskSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+				if err := subnet.Canonicalize(); err != nil {
+					b.Fatalf("fail to canonicalize: %v", err)
+				}
+				if err := subnet.Sync(nil, NewIPSet()); err != nil {
+					b.Fatalf("fail to sync: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("half-used/%d", maskSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+				if err := subnet.Canonicalize(); err != nil {
+					b.Fatalf("fail to canonicalize: %v", err)
+				}
+				if err := subnet.Sync(nil, NewIPSet()); err != nil {
+					b.Fatalf("fail to sync: %v", err)
+				}
+				subnet.ensureBitmap()
+				for index := 0; index < subnet.rangeSize/2; index++ {
+					subnet.bitmap.Set(index)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkSubnetAllocateWithChurn allocates and releases IPs randomly to fragment the
+// subnet, and reports how many contiguous free blocks are left finally
+func benchmarkSubnetAllocateWithChurn(b *testing.B, fitStrategy FitStrategy) {
+	ip, cidr, _ := net.ParseCIDR("10.0.0.1/22")
+
+	var freeBlocks int
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+		if err := subnet.Canonicalize(); err != nil {
+			b.Fatalf("fail to canonicalize: %v", err)
+		}
+		if err := subnet.Sync(nil, NewIPSet()); err != nil {
+			b.Fatalf("fail to sync: %v", err)
+		}
+		random := rand.New(rand.NewSource(1))
+		var allocatedIPs []string
+		b.StartTimer()
+
+		for i := 0; i < 2000; i++ {
+			// release one of allocated IPs by a chance of 40%
+			if len(allocatedIPs) > 0 && random.Intn(10) < 4 {
+				index := random.Intn(len(allocatedIPs))
+				subnet.Release(allocatedIPs[index])
+				allocatedIPs = append(allocatedIPs[:index], allocatedIPs[index+1:]...)
+				continue
+			}
+
+			allocatedIP := subnet.Allocate(fitStrategy, "", "")
+			if allocatedIP == nil {
+				continue
+			}
+			allocatedIPs = append(allocatedIPs, allocatedIP.Address.IP.String())
+		}
+
+		freeBlocks = len(subnet.freeBlocks())
+	}
+
+	b.ReportMetric(float64(freeBlocks), "free-blocks")
+}
+
+func TestSubnet_BlackRanges(t *testing.T) {
+	ip, cidr, _ := net.ParseCIDR("10.0.0.1/24")
+	subnet := NewSubnet("test", "fake", nil, nil, nil, ip, cidr, nil, nil, nil, false, false)
+	subnet.BlackRanges = []*IPRange{
+		{Start: net.ParseIP("10.0.0.2"), End: net.ParseIP("10.0.0.4")},
+		// clipped by range of subnet
+		{Start: net.ParseIP("10.0.0.250"), End: net.ParseIP("10.0.1.10")},
+		// ranges of the other family are ignored
+		{Start: net.ParseIP("fd00::1"), End: net.ParseIP("fd00::10")},
+	}
+	if err := subnet.Canonicalize(); err != nil {
+		t.Fatalf("fail to canonicalize: %v", err)
+	}
+	if err := subnet.Sync(nil, NewIPSet()); err != nil {
+		t.Fatalf("fail to sync: %v", err)
+	}
+
+	for _, blackIP := range []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.250", "10.0.0.254"} {
+		if !subnet.IsBlackIP(blackIP) {
+			t.Errorf("expected %s to be black ip", blackIP)
+		}
+	}
+	if subnet.IsBlackIP("10.0.0.5") || subnet.IsBlackIP("fd00::2:1") {
+		t.Errorf("expected ips out of black ranges not to be black ip")
+	}
+
+	// network address, broadcast address, gateway and black ranges are excluded
+	if expected := 256 - 3 - 3 - 5; subnet.Usage().Total != uint32(expected) {
+		t.Fatalf("expected total %d but got %d", expected, subnet.Usage().Total)
+	}
+
+	allocatedIP := subnet.AllocateNext("", "")
+	if allocatedIP == nil || allocatedIP.Address.IP.String() != "10.0.0.5" {
+		t.Fatalf("expected 10.0.0.5 but got %v", allocatedIP)
+	}
+}
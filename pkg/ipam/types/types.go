@@ -16,7 +16,10 @@
 
 package types
 
-import "net"
+import (
+	"net"
+	"time"
+)
 
 const (
 	IPStatusAllocated = "Allocated"
@@ -46,15 +49,35 @@ type Subnet struct {
 	Gateway         net.IP
 	ReservedList    map[string]struct{}
 	BlackList       map[string]struct{}
+	BlackRanges     []*IPRange
 	LastAllocatedIP net.IP
 	Private         bool
 	IPv6            bool
 
 	// Status fields
 	// `Sync` method will initialize these
-	AvailableIPs    *IPSlice
-	UsingIPs        IPSet
-	ReservedIPCount int
+	UsingIPs         IPSet
+	ReservedIPCount  int
+	AvailableIPCount int
+
+	// IPs in range [Start, End] are indexed by their offsets from Start, the offsets
+	// of unavailable IPs (gateway, black list and reserved ones) and using IPs are set
+	// in bitmap, which will be initialized lazily on the first allocation request
+	rangeSize          int
+	lastAllocatedIndex int
+	bitmap             *SparseBitmap
+
+	// the time of last allocation and release, which are zero before any IP of subnet
+	// is allocated or released by this process
+	lastAllocationTime time.Time
+	lastReleaseTime    time.Time
+}
+
+// IPRange is a block of contiguous IPs in range [Start, End], which is excluded
+// from allocation like black list, e.g., reserved IP blocks
+type IPRange struct {
+	Start net.IP
+	End   net.IP
 }
 
 type SubnetSlice struct {
@@ -16,11 +16,16 @@
 
 package types
 
+import "time"
+
 type Usage struct {
 	Total          uint32
 	Used           uint32
 	Available      uint32
 	LastAllocation string
+
+	LastAllocationTime time.Time
+	LastReleaseTime    time.Time
 }
 
 func (u *Usage) Add(in *Usage) {
@@ -34,6 +39,12 @@ func (u *Usage) Add(in *Usage) {
 	if len(u.LastAllocation) == 0 {
 		u.LastAllocation = in.LastAllocation
 	}
+	if in.LastAllocationTime.After(u.LastAllocationTime) {
+		u.LastAllocationTime = in.LastAllocationTime
+	}
+	if in.LastReleaseTime.After(u.LastReleaseTime) {
+		u.LastReleaseTime = in.LastReleaseTime
+	}
 }
 
 type NetworkUsage struct {
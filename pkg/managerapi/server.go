@@ -0,0 +1,182 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package managerapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/utils/transform"
+)
+
+const (
+	IPInstancesPath = "/api/v1/ipinstances"
+
+	bearerPrefix = "Bearer "
+)
+
+// Server serves a read-only http api of IPInstances for external admission controllers,
+// requests are authenticated by a bearer token and limited by qps. The bearer token must
+// not be sent in plain text through network, so the api is served over https unless it
+// only listens on loopback address.
+type Server struct {
+	Client      client.Reader
+	BindAddress string
+	Port        int
+	TLSCertFile string
+	TLSKeyFile  string
+	BearerToken string
+	QPSLimit    float64
+	Logger      logr.Logger
+}
+
+// Validate checks if the api is served over https or only on loopback address
+func (s *Server) Validate() error {
+	if (len(s.TLSCertFile) == 0) != (len(s.TLSKeyFile) == 0) {
+		return fmt.Errorf("tls cert file and key file must be specified together")
+	}
+	if len(s.TLSCertFile) > 0 {
+		return nil
+	}
+
+	if ip := net.ParseIP(s.BindAddress); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("api must be served over https if bind address %q is not a loopback address", s.BindAddress)
+	}
+	return nil
+}
+
+// Start serves the api until context is done, it implements manager.Runnable
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(s.BindAddress, fmt.Sprint(s.Port)),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	s.Logger.Info("starting api server", "address", server.Addr, "tls", len(s.TLSCertFile) > 0, "qps-limit", s.QPSLimit)
+
+	var err error
+	if len(s.TLSCertFile) > 0 {
+		err = server.ListenAndServeTLS(s.TLSCertFile, s.TLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("api server exit unexpectedly: %v", err)
+	}
+	return nil
+}
+
+// NeedLeaderElection makes the read-only api served by all the replicas
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler assembles the http handler of api with authentication and rate limit, requests
+// are authenticated first so that unauthenticated clients can not exhaust the qps limit
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(IPInstancesPath, s.listIPInstances)
+
+	return s.authHandler(s.rateLimitHandler(mux))
+}
+
+func (s *Server) authHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if len(s.BearerToken) == 0 || !strings.HasPrefix(auth, bearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, bearerPrefix)), []byte(s.BearerToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) rateLimitHandler(next http.Handler) http.Handler {
+	limiter := rate.NewLimiter(rate.Limit(s.QPSLimit), int(math.Max(1, math.Ceil(s.QPSLimit))))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !limiter.Allow() {
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listIPInstances lists IPInstances in namespace, filtered by pod if specified
+func (s *Server) listIPInstances(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, podName := r.URL.Query().Get("namespace"), r.URL.Query().Get("pod")
+	if len(namespace) == 0 {
+		http.Error(w, "namespace must be specified", http.StatusBadRequest)
+		return
+	}
+
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if len(podName) > 0 {
+		opts = append(opts, client.MatchingLabels{
+			constants.LabelPod: transform.TransferPodNameForLabelValue(podName),
+		})
+	}
+
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err := s.Client.List(r.Context(), ipInstanceList, opts...); err != nil {
+		s.Logger.Error(err, "failed to list IPInstances", "namespace", namespace, "pod", podName)
+		http.Error(w, "failed to list IPInstances", http.StatusInternalServerError)
+		return
+	}
+
+	// label value of a long pod name is hashed, pod name should be checked again
+	result := &networkingv1.IPInstanceList{Items: []networkingv1.IPInstance{}}
+	for i := range ipInstanceList.Items {
+		if len(podName) == 0 || ipInstanceList.Items[i].Spec.Binding.PodName == podName {
+			result.Items = append(result.Items, ipInstanceList.Items[i])
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.Logger.Error(err, "failed to encode IPInstances")
+	}
+}
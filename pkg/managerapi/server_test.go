@@ -0,0 +1,255 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package managerapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+const testToken = "test-token"
+
+func newTestServer(t *testing.T, qpsLimit float64) *httptest.Server {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	newIPInstance := func(name, namespace, podName string) *networkingv1.IPInstance {
+		return &networkingv1.IPInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					constants.LabelPod: podName,
+				},
+			},
+			Spec: networkingv1.IPInstanceSpec{
+				Binding: networkingv1.Binding{
+					PodName: podName,
+				},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newIPInstance("192-168-0-1", "ns1", "pod1"),
+		newIPInstance("192-168-0-2", "ns1", "pod2"),
+		newIPInstance("192-168-0-3", "ns2", "pod1"),
+	).Build()
+
+	s := &Server{
+		Client:      c,
+		BearerToken: testToken,
+		QPSLimit:    qpsLimit,
+		Logger:      logr.Discard(),
+	}
+	return httptest.NewServer(s.Handler())
+}
+
+func doRequest(t *testing.T, url, token string) (*http.Response, *networkingv1.IPInstanceList) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("fail to build request: %v", err)
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("fail to do request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	ipInstanceList := &networkingv1.IPInstanceList{}
+	if err = json.NewDecoder(resp.Body).Decode(ipInstanceList); err != nil {
+		t.Fatalf("fail to decode response: %v", err)
+	}
+	return resp, ipInstanceList
+}
+
+func TestListIPInstances(t *testing.T) {
+	server := newTestServer(t, 100)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		token      string
+		statusCode int
+		ipNames    []string
+	}{
+		{
+			"without token",
+			"?namespace=ns1&pod=pod1",
+			"",
+			http.StatusUnauthorized,
+			nil,
+		},
+		{
+			"with wrong token",
+			"?namespace=ns1&pod=pod1",
+			"wrong-token",
+			http.StatusUnauthorized,
+			nil,
+		},
+		{
+			"without namespace",
+			"?pod=pod1",
+			testToken,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"by pod",
+			"?namespace=ns1&pod=pod1",
+			testToken,
+			http.StatusOK,
+			[]string{"192-168-0-1"},
+		},
+		{
+			"by namespace",
+			"?namespace=ns1",
+			testToken,
+			http.StatusOK,
+			[]string{"192-168-0-1", "192-168-0-2"},
+		},
+		{
+			"no matching",
+			"?namespace=ns2&pod=pod2",
+			testToken,
+			http.StatusOK,
+			[]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, ipInstanceList := doRequest(t, server.URL+IPInstancesPath+test.query, test.token)
+			if resp.StatusCode != test.statusCode {
+				t.Fatalf("expect status code %d but got %d", test.statusCode, resp.StatusCode)
+			}
+			if ipInstanceList == nil {
+				return
+			}
+
+			var ipNames = []string{}
+			for _, ipInstance := range ipInstanceList.Items {
+				ipNames = append(ipNames, ipInstance.Name)
+			}
+			if len(ipNames) != len(test.ipNames) {
+				t.Fatalf("expect IPInstances %v but got %v", test.ipNames, ipNames)
+			}
+			for i := range ipNames {
+				if ipNames[i] != test.ipNames[i] {
+					t.Fatalf("expect IPInstances %v but got %v", test.ipNames, ipNames)
+				}
+			}
+		})
+	}
+}
+
+func TestQPSLimit(t *testing.T) {
+	server := newTestServer(t, 1)
+	defer server.Close()
+
+	url := server.URL + IPInstancesPath + "?namespace=ns1"
+	if resp, _ := doRequest(t, url, testToken); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect status code %d but got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp, _ := doRequest(t, url, testToken); resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expect status code %d but got %d", http.StatusTooManyRequests, resp.StatusCode)
+	}
+}
+
+func TestQPSLimitAfterAuthentication(t *testing.T) {
+	server := newTestServer(t, 1)
+	defer server.Close()
+
+	// unauthenticated requests must not consume the qps limit
+	url := server.URL + IPInstancesPath + "?namespace=ns1"
+	for i := 0; i < 3; i++ {
+		if resp, _ := doRequest(t, url, "wrong-token"); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expect status code %d but got %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+	}
+	if resp, _ := doRequest(t, url, testToken); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expect status code %d but got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestServerValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *Server
+		valid  bool
+	}{
+		{
+			"plain http on ipv4 loopback",
+			&Server{BindAddress: "127.0.0.1"},
+			true,
+		},
+		{
+			"plain http on ipv6 loopback",
+			&Server{BindAddress: "::1"},
+			true,
+		},
+		{
+			"plain http on all addresses",
+			&Server{BindAddress: "0.0.0.0"},
+			false,
+		},
+		{
+			"plain http without bind address",
+			&Server{},
+			false,
+		},
+		{
+			"https on all addresses",
+			&Server{BindAddress: "0.0.0.0", TLSCertFile: "tls.crt", TLSKeyFile: "tls.key"},
+			true,
+		},
+		{
+			"https without key file",
+			&Server{BindAddress: "0.0.0.0", TLSCertFile: "tls.crt"},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.server.Validate(); (err == nil) != test.valid {
+				t.Fatalf("expect valid %v but got %v", test.valid, err)
+			}
+		})
+	}
+}
@@ -25,6 +25,38 @@ func init() {
 	metrics.Registry.MustRegister(IPUsageGauge,
 		IPAllocationPeriodSummary,
 		RemoteClusterStatusCheckDuration,
+		RemoteRoutesCompressedCounter,
+		VtepInterfaceResetsCounter,
+		ARPSuppressedCounter,
+		SubnetLastAllocationGauge,
+		SubnetIPUsageGauge,
+		VxlanChecksumOffloadFallbackGauge,
+		IPAMDriftDetectedCounter,
+		IPAMLockWaitSeconds,
+		IPAMLockContentionCounter,
+		SRIOVVFUsageGauge,
+		IPAMRebuildTimeoutCounter,
+		BFDSessionStateGauge,
+		MultiClusterClientRebuildsCounter,
+		IPInstanceCompliancePurgeCounter,
+		VtepMTUBlackholeDetectedGauge,
+		VtepRxBytesCounter,
+		VtepTxBytesCounter,
+		VtepRxErrorsCounter,
+		VtepTxDropsCounter,
+		WebhookAdmissionRequestsCounter,
+		WebhookAdmissionDuration,
+		IPAllocationE2ESeconds,
+		RemoteVtepStaleCacheCounter,
+		ARPEAGAINRetriesCounter,
+		OverlayRTTSeconds,
+		SubnetAllocationHistoryGauge,
+		CNICommandDuration,
+		IPInstanceSpecStatusSkewCounter,
+		PodEventsCoalescedCounter,
+		LACPFailoverARPSentCounter,
+		SubnetDaysToExhaustionGauge,
+		IPAMExcludeListReservedIPsGauge,
 	)
 }
 
@@ -79,3 +111,315 @@ var RemoteClusterStatusCheckDuration = prometheus.NewHistogramVec(
 		"clusterName",
 	},
 )
+
+var RemoteRoutesCompressedCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_remote_routes_compressed_total",
+		Help: "the number of remote subnet routes saved by route compression",
+	},
+)
+
+var VtepInterfaceResetsCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_vtep_interface_resets_total",
+		Help: "the number of times vtep interface comes up after being down or recreated",
+	},
+)
+
+var ARPSuppressedCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_arp_suppressed_total",
+		Help: "the number of overlay neigh requests answered from local caches instead of being flooded",
+	},
+)
+
+var SubnetLastAllocationGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_subnet_last_allocation_seconds",
+		Help: "the unix timestamp of the last allocation of IPs in different subnets",
+	},
+	[]string{
+		"subnetName",
+		"ipFamily",
+	},
+)
+
+var SubnetIPUsageGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_subnet_ip_usage",
+		Help: "the usage of IPs in different subnets",
+	},
+	[]string{
+		"subnetName",
+		"ipFamily",
+		"usageType",
+	},
+)
+
+var VxlanChecksumOffloadFallbackGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_vxlan_checksum_offload_fallback",
+		Help: "whether udp tunnel checksum of vxlan traffic falls back to software on different interfaces, 1 means fallback",
+	},
+	[]string{
+		"interfaceName",
+	},
+)
+
+var IPAMDriftDetectedCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_ipam_drift_detected_total",
+		Help: "the number of times in-memory ipam state drifts from apiserver in different subnets",
+	},
+	[]string{
+		"subnetName",
+	},
+)
+
+const (
+	IPAMLockTypeRead  = "read"
+	IPAMLockTypeWrite = "write"
+)
+
+var IPAMLockWaitSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hybridnet_ipam_lock_wait_seconds",
+		Help:    "time taken for waiting the lock of ipam manager",
+		Buckets: []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1.0, 5.0, 10.0},
+	},
+	[]string{
+		"lockType",
+	},
+)
+
+var IPAMLockContentionCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_ipam_lock_contention_total",
+		Help: "the number of times the lock of ipam manager can not be acquired immediately",
+	},
+	[]string{
+		"lockType",
+	},
+)
+
+var SRIOVVFUsageGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_sriov_vf_usage",
+		Help: "the usage of SR-IOV virtual functions of different physical functions on node",
+	},
+	[]string{
+		"pfName",
+		"usageType",
+	},
+)
+
+var IPAMRebuildTimeoutCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_ipam_rebuild_timeout_total",
+		Help: "the number of times daemon exits because ipam state is not rebuilt within timeout",
+	},
+)
+
+var BFDSessionStateGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_bfd_session_state",
+		Help: "the state of bfd sessions from node to peers, 0 for AdminDown, 1 for Down, 2 for Init and 3 for Up",
+	},
+	[]string{
+		"peerAddress",
+		"peerType",
+	},
+)
+
+var MultiClusterClientRebuildsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_multicluster_client_rebuilds_total",
+		Help: "the number of times clients of remote clusters are rebuilt because kubeconfig files change",
+	},
+	[]string{
+		"clusterName",
+	},
+)
+
+var IPInstanceCompliancePurgeCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_ipinstance_compliance_purge_total",
+		Help: "the number of IPInstances purged because they are older than the max age of networking policies",
+	},
+	[]string{
+		"networkName",
+	},
+)
+
+var VtepMTUBlackholeDetectedGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_vtep_mtu_blackhole_detected",
+		Help: "whether the effective mtu to remote vtep is more than 100 bytes below the configured vxlan mtu",
+	},
+	[]string{
+		"remoteVtep",
+	},
+)
+
+var VtepRxBytesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_vtep_rx_bytes_total",
+		Help: "the number of bytes received by vtep interfaces",
+	},
+	[]string{
+		"interface",
+	},
+)
+
+var VtepTxBytesCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_vtep_tx_bytes_total",
+		Help: "the number of bytes transmitted by vtep interfaces",
+	},
+	[]string{
+		"interface",
+	},
+)
+
+var VtepRxErrorsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_vtep_rx_errors_total",
+		Help: "the number of receive errors of vtep interfaces",
+	},
+	[]string{
+		"interface",
+	},
+)
+
+var VtepTxDropsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_vtep_tx_drops_total",
+		Help: "the number of packets dropped on transmission by vtep interfaces",
+	},
+	[]string{
+		"interface",
+	},
+)
+
+var WebhookAdmissionRequestsCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_webhook_admission_requests_total",
+		Help: "the number of admission requests handled by validating webhook",
+	},
+	[]string{
+		"resource",
+		"operation",
+		"allowed",
+	},
+)
+
+var WebhookAdmissionDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hybridnet_webhook_admission_duration_seconds",
+		Help:    "time taken for handling admission requests by validating webhook",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0},
+	},
+	[]string{
+		"resource",
+		"operation",
+	},
+)
+
+var IPAllocationE2ESeconds = prometheus.NewHistogram(
+	prometheus.HistogramOpts{
+		Name:    "hybridnet_ip_allocation_e2e_seconds",
+		Help:    "time taken from pod being queued for IP allocation to its interface being configured",
+		Buckets: []float64{0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0, 120.0, 300.0},
+	},
+)
+
+var RemoteVtepStaleCacheCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_remote_vtep_stale_cache_total",
+		Help: "the number of RemoteVteps whose fdb entries are installed from stale informer cache of daemon",
+	},
+)
+
+var ARPEAGAINRetriesCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_arp_eagain_retries_total",
+		Help: "the number of arp checks retried because raw socket returns EAGAIN on busy interfaces",
+	},
+)
+
+var OverlayRTTSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hybridnet_overlay_rtt_seconds",
+		Help:    "the exponentially smoothed round-trip time of icmp probes from local vtep to vteps of remote nodes",
+		Buckets: []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0},
+	},
+	[]string{
+		"remote_node",
+		"local_node",
+	},
+)
+
+var SubnetAllocationHistoryGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_subnet_allocation_history",
+		Help: "the number of used IPs of different subnets in the latest snapshot of allocation history",
+	},
+	[]string{
+		"subnet",
+	},
+)
+
+var CNICommandDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hybridnet_cni_command_duration_seconds",
+		Help:    "time taken for serving cni commands by daemon, including the time waiting for a free worker",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0, 120.0},
+	},
+	[]string{
+		"command",
+	},
+)
+
+var IPInstanceSpecStatusSkewCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_ipinstance_spec_status_skew_total",
+		Help: "the number of IPInstances whose status skews from their bindings and is patched to match",
+	},
+	[]string{
+		"networkName",
+	},
+)
+
+var PodEventsCoalescedCounter = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "hybridnet_pod_events_coalesced_total",
+		Help: "the number of pod update events merged into a pending reconciliation by debouncing",
+	},
+)
+
+var LACPFailoverARPSentCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "hybridnet_lacp_failover_arp_sent_total",
+		Help: "the number of gratuitous arps sent for underlay pods after the active links of bonds change",
+	},
+	[]string{
+		"interface",
+	},
+)
+
+var SubnetDaysToExhaustionGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_subnet_days_to_exhaustion",
+		Help: "the days until IPs of different subnets are exhausted, forecasted by the trend of allocation history",
+	},
+	[]string{
+		"subnet",
+	},
+)
+
+var IPAMExcludeListReservedIPsGauge = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "hybridnet_ipam_exclude_list_reserved_ips",
+		Help: "the number of IPs loaded from the exclude list file of node and reserved in underlay subnets",
+	},
+)
@@ -0,0 +1,137 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ipv4HeaderLength = 20
+	icmpHeaderLength = 8
+)
+
+// probePathMTU finds the largest ipv4 packet size in range [low, high] which reaches target
+// over interface with DF bit set, by binary searching with icmp echo requests. An error is
+// returned if even the packets of low size can not reach target.
+func probePathMTU(ifName string, target net.IP, low, high int, timeout time.Duration) (int, error) {
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to listen icmp: %v", err)
+	}
+	defer conn.Close()
+
+	rawConn, err := conn.(*net.IPConn).SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var sockErr error
+	if err = rawConn.Control(func(fd uintptr) {
+		// probes must go through the underlay interface rather than the one chosen by host
+		// routes, e.g., the interface of default route
+		if sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, ifName); sockErr != nil {
+			sockErr = fmt.Errorf("failed to bind to interface %v: %v", ifName, sockErr)
+			return
+		}
+		// always set DF bit and ignore the path mtu cached by kernel
+		if sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_MTU_DISCOVER, unix.IP_PMTUDISC_PROBE); sockErr != nil {
+			sockErr = fmt.Errorf("failed to set DF bit: %v", sockErr)
+		}
+	}); err != nil {
+		return 0, err
+	}
+	if sockErr != nil {
+		return 0, sockErr
+	}
+
+	if high < low {
+		high = low
+	}
+
+	seq := 0
+	probe := func(size int) bool {
+		seq++
+		return echo(conn, target, size, seq, timeout) == nil
+	}
+
+	if !probe(low) {
+		return 0, fmt.Errorf("packets of %v bytes do not reach %v", low, target)
+	}
+
+	for low < high {
+		mid := (low + high + 1) / 2
+		if probe(mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, nil
+}
+
+// echo sends an icmp echo request of ipv4 packet size and waits for the reply.
+func echo(conn net.PacketConn, target net.IP, size, seq int, timeout time.Duration) error {
+	id := os.Getpid() & 0xffff
+	message := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{
+			ID:   id,
+			Seq:  seq,
+			Data: make([]byte, size-ipv4HeaderLength-icmpHeaderLength),
+		},
+	}
+	b, err := message.Marshal(nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = conn.WriteTo(b, &net.IPAddr{IP: target}); err != nil {
+		return err
+	}
+
+	if err = conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, size+ipv4HeaderLength)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		if !from.(*net.IPAddr).IP.Equal(target) {
+			continue
+		}
+
+		reply, err := icmp.ParseMessage(ipv4.ICMPTypeEcho.Protocol(), buf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+
+		if body, ok := reply.Body.(*icmp.Echo); ok && body.ID == id && body.Seq == seq {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package preflight
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProbePathMTU(t *testing.T) {
+	if conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0"); err != nil {
+		t.Skipf("icmp is not available: %v", err)
+	} else {
+		_ = conn.Close()
+	}
+
+	loopback := net.ParseIP("127.0.0.1")
+	pathMTU, err := probePathMTU("lo", loopback, minimumIPv4MTU, 1500, time.Second)
+	if err != nil {
+		t.Fatalf("fail to probe path mtu over lo: %v", err)
+	}
+	if pathMTU != 1500 {
+		t.Errorf("expect path mtu 1500 but got %d", pathMTU)
+	}
+
+	if _, err = probePathMTU("not-exist", loopback, minimumIPv4MTU, 1500, time.Second); err == nil {
+		t.Errorf("expect error when probing over a nonexistent interface")
+	}
+}
@@ -0,0 +1,229 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vishvananda/netlink"
+
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/daemon/arp"
+	daemonutils "github.com/alibaba/hybridnet/pkg/daemon/utils"
+)
+
+const (
+	CheckIPForwarding = "IPForwarding"
+	CheckVlanTagging  = "VlanTagging"
+	CheckGatewayARP   = "GatewayARP"
+	CheckPathMTU      = "PathMTU"
+)
+
+const (
+	DefaultTimeout = 3 * time.Second
+
+	minimumIPv4MTU        = 576
+	preflightVlanIfSuffix = "pf"
+)
+
+// Options are the underlay network environment to be checked.
+type Options struct {
+	// NodeInterface is the host interface which underlay vlan interfaces are created on
+	NodeInterface string
+	// Gateway is the IPv4 gateway of underlay subnet
+	Gateway net.IP
+	// VlanID is the vlan id of underlay network, 0 means untagged
+	VlanID int
+	// MTU is the expected path mtu to gateway, 0 means the mtu of the interface which gateway
+	// is reached over
+	MTU     int
+	Timeout time.Duration
+}
+
+// Result is the result of a single preflight check, Hint tells how to fix a failed check.
+type Result struct {
+	Name    string
+	Passed  bool
+	Message string
+	Hint    string
+}
+
+func passed(name, format string, args ...interface{}) Result {
+	return Result{Name: name, Passed: true, Message: fmt.Sprintf(format, args...)}
+}
+
+func failed(name, hint, format string, args ...interface{}) Result {
+	return Result{Name: name, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+// Run performs all the preflight checks in order. A vlan interface will be created on node
+// interface temporarily if vlan id is specified and the interface does not exist, gateway arp
+// and vlan checks are done over it.
+func Run(options Options) []Result {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	results := []Result{checkIPForwarding(options.NodeInterface)}
+
+	forwardIfName := options.NodeInterface
+	if options.VlanID > 0 {
+		vlanIfName, cleanup, result := checkVlanTagging(options.NodeInterface, options.VlanID)
+		results = append(results, result)
+		if !result.Passed {
+			return results
+		}
+		defer cleanup()
+		forwardIfName = vlanIfName
+	}
+
+	arpResult := checkGatewayARP(forwardIfName, options.Gateway, options.VlanID, options.Timeout)
+	results = append(results, arpResult)
+	if !arpResult.Passed {
+		return results
+	}
+
+	return append(results, checkPathMTU(forwardIfName, options.Gateway, options.MTU, options.Timeout))
+}
+
+func checkIPForwarding(nodeInterface string) Result {
+	for _, ifName := range []string{"all", nodeInterface} {
+		sysctlPath := fmt.Sprintf(constants.IPv4ForwardingSysctl, ifName)
+		value, err := daemonutils.GetSysctl(sysctlPath)
+		if err != nil {
+			return failed(CheckIPForwarding, "make sure the node interface exists and /proc/sys is readable",
+				"failed to read %v: %v", sysctlPath, err)
+		}
+
+		if value != 1 {
+			return failed(CheckIPForwarding,
+				fmt.Sprintf("run \"sysctl -w net.ipv4.conf.%s.forwarding=1\" and persist it in /etc/sysctl.conf", ifName),
+				"ipv4 forwarding of %v is disabled", ifName)
+		}
+	}
+	return passed(CheckIPForwarding, "ipv4 forwarding is enabled on all interfaces and %v", nodeInterface)
+}
+
+// checkVlanTagging makes sure a vlan interface of vlan id can be created on node interface and
+// brought up, the returned cleanup function removes it if it is created by preflight.
+func checkVlanTagging(nodeInterface string, vlanID int) (string, func(), Result) {
+	noop := func() {}
+
+	if vlanID > 4094 {
+		return "", noop, failed(CheckVlanTagging, "use a vlan id in range 1 to 4094", "invalid vlan id %v", vlanID)
+	}
+
+	parent, err := netlink.LinkByName(nodeInterface)
+	if err != nil {
+		return "", noop, failed(CheckVlanTagging, "check the --node-interface flag",
+			"failed to get node interface %v: %v", nodeInterface, err)
+	}
+
+	if parent.Attrs().OperState != netlink.OperUp && parent.Attrs().OperState != netlink.OperUnknown {
+		return "", noop, failed(CheckVlanTagging, fmt.Sprintf("run \"ip link set %s up\" and check the cable", nodeInterface),
+			"node interface %v is %v", nodeInterface, parent.Attrs().OperState)
+	}
+
+	// reuse the existing vlan interface of the same vlan id, e.g., it is created by daemon
+	links, err := netlink.LinkList()
+	if err != nil {
+		return "", noop, failed(CheckVlanTagging, "", "failed to list links: %v", err)
+	}
+	for _, link := range links {
+		if vlan, ok := link.(*netlink.Vlan); ok && vlan.ParentIndex == parent.Attrs().Index && vlan.VlanId == vlanID {
+			if vlan.Attrs().Flags&net.FlagUp == 0 {
+				return "", noop, failed(CheckVlanTagging, fmt.Sprintf("run \"ip link set %s up\"", vlan.Name),
+					"vlan interface %v of vlan %v is down", vlan.Name, vlanID)
+			}
+			return vlan.Name, noop, passed(CheckVlanTagging, "vlan interface %v of vlan %v exists and is up", vlan.Name, vlanID)
+		}
+	}
+
+	vlanIfName := fmt.Sprintf("%s.%d.%s", nodeInterface, vlanID, preflightVlanIfSuffix)
+	if len(vlanIfName) > 15 {
+		vlanIfName = fmt.Sprintf("hpf.%d", vlanID)
+	}
+
+	vlan := &netlink.Vlan{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:        vlanIfName,
+			ParentIndex: parent.Attrs().Index,
+		},
+		VlanId: vlanID,
+	}
+	if err = netlink.LinkAdd(vlan); err != nil {
+		return "", noop, failed(CheckVlanTagging, "make sure 8021q kernel module is loaded by \"modprobe 8021q\"",
+			"failed to create vlan interface %v on %v: %v", vlanIfName, nodeInterface, err)
+	}
+
+	cleanup := func() {
+		_ = netlink.LinkDel(vlan)
+	}
+
+	if err = netlink.LinkSetUp(vlan); err != nil {
+		cleanup()
+		return "", noop, failed(CheckVlanTagging, "", "failed to set vlan interface %v up: %v", vlanIfName, err)
+	}
+
+	return vlanIfName, cleanup, passed(CheckVlanTagging, "vlan interface of vlan %v can be created on %v", vlanID, nodeInterface)
+}
+
+func checkGatewayARP(ifName string, gateway net.IP, vlanID int, timeout time.Duration) Result {
+	ifi, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return failed(CheckGatewayARP, "", "failed to get interface %v: %v", ifName, err)
+	}
+
+	hw, err := arp.ResolveWithTimeout(ifi, gateway, timeout)
+	if err != nil {
+		hint := "check the gateway address and the upstream switch port"
+		if vlanID > 0 {
+			hint = fmt.Sprintf("make sure the upstream switch port allows vlan %d as a trunk, and the gateway is configured in vlan %d", vlanID, vlanID)
+		}
+		return failed(CheckGatewayARP, hint, "gateway %v does not answer arp over %v: %v", gateway, ifName, err)
+	}
+
+	return passed(CheckGatewayARP, "gateway %v is resolved to %v over %v", gateway, hw, ifName)
+}
+
+// checkPathMTU probes the path mtu to gateway over the interface which underlay packets are
+// forwarded through, i.e., the vlan interface or node interface itself if untagged.
+func checkPathMTU(ifName string, gateway net.IP, expectedMTU int, timeout time.Duration) Result {
+	if expectedMTU == 0 {
+		link, err := netlink.LinkByName(ifName)
+		if err != nil {
+			return failed(CheckPathMTU, "", "failed to get interface %v: %v", ifName, err)
+		}
+		expectedMTU = link.Attrs().MTU
+	}
+
+	pathMTU, err := probePathMTU(ifName, gateway, minimumIPv4MTU, expectedMTU, timeout)
+	if err != nil {
+		return failed(CheckPathMTU, "make sure icmp echo is allowed from node to gateway",
+			"failed to probe path mtu to %v over %v: %v", gateway, ifName, err)
+	}
+
+	if pathMTU < expectedMTU {
+		return failed(CheckPathMTU,
+			fmt.Sprintf("increase the mtu of switches and gateway to %d, or set a smaller mtu for hybridnet", expectedMTU),
+			"path mtu to %v is %v, less than %v", gateway, pathMTU, expectedMTU)
+	}
+
+	return passed(CheckPathMTU, "packets of %v bytes with DF bit reach gateway %v over %v", pathMTU, gateway, ifName)
+}
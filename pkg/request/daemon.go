@@ -89,3 +89,15 @@ func (cdc CniDaemonClient) Del(podRequest PodRequest) error {
 	}
 	return nil
 }
+
+// Check pod request
+func (cdc CniDaemonClient) Check(podRequest PodRequest) error {
+	res, body, errors := cdc.Post("http://dummy/api/v1/check").Send(podRequest).End()
+	if len(errors) != 0 {
+		return errors[0]
+	}
+	if res.StatusCode != 204 {
+		return fmt.Errorf("check pod return %d %s", res.StatusCode, body)
+	}
+	return nil
+}
@@ -43,6 +43,17 @@ func PrevIP(ip net.IP) net.IP {
 	return intToIP(i.Sub(i, big.NewInt(1)), len(normalizedIP) == net.IPv6len)
 }
 
+// OffsetIP returns IP incremented by offset, if IP is invalid, return nil
+func OffsetIP(ip net.IP, offset int64) net.IP {
+	normalizedIP := normalizeIP(ip)
+	if normalizedIP == nil {
+		return nil
+	}
+
+	i := ipToInt(normalizedIP)
+	return intToIP(i.Add(i, big.NewInt(offset)), len(normalizedIP) == net.IPv6len)
+}
+
 // Cmp compares two IPs, returning the usual ordering:
 // a < b : -1
 // a == b : 0
@@ -109,6 +109,47 @@ func TestCIDR_PrevIP(t *testing.T) {
 	}
 }
 
+func TestCIDR_OffsetIP(t *testing.T) {
+	testCases := []struct {
+		ip       net.IP
+		offset   int64
+		offsetIP net.IP
+	}{
+		{
+			[]byte{192, 0, 2},
+			1,
+			nil,
+		},
+		{
+			net.ParseIP("192.168.0.1"),
+			0,
+			net.IPv4(192, 168, 0, 1).To4(),
+		},
+		{
+			net.ParseIP("192.168.0.255"),
+			257,
+			net.IPv4(192, 168, 2, 0).To4(),
+		},
+		{
+			net.ParseIP("0.0.0.1"),
+			65536,
+			net.IPv4(0, 1, 0, 1).To4(),
+		},
+		{
+			net.ParseIP("AB12::FFFF"),
+			2,
+			net.ParseIP("AB12::1:1"),
+		},
+	}
+
+	for _, test := range testCases {
+		ip := OffsetIP(test.ip, test.offset)
+		if !ip.Equal(test.offsetIP) {
+			t.Errorf("expect IP %s with offset %d but got %s", test.offsetIP, test.offset, ip)
+		}
+	}
+}
+
 func TestCmpIP(t *testing.T) {
 	testCases := []struct {
 		a      net.IP
@@ -72,6 +72,26 @@ func ToDNSFormat(ip net.IP) string {
 	return strings.ReplaceAll(ip.String(), ".", "-")
 }
 
+// ReverseDNSName returns the name of PTR record of ip, e.g., 1.0.168.192.in-addr.arpa for
+// 192.168.0.1, and the nibble format under ip6.arpa for IPv6
+func ReverseDNSName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return ""
+	}
+
+	const hexDigits = "0123456789abcdef"
+	name := make([]byte, 0, len(ip16)*4+len("ip6.arpa"))
+	for i := len(ip16) - 1; i >= 0; i-- {
+		name = append(name, hexDigits[ip16[i]&0x0f], '.', hexDigits[ip16[i]>>4], '.')
+	}
+	return string(append(name, "ip6.arpa"...))
+}
+
 // LastIP Determine the last IP of a subnet, excluding the broadcast if IPv4
 func LastIP(subnet *net.IPNet) net.IP {
 	var end net.IP
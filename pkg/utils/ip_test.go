@@ -166,3 +166,34 @@ func TestValidateIPv6(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseDNSName(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       net.IP
+		expected string
+	}{
+		{
+			"ipv4",
+			net.ParseIP("192.168.0.1"),
+			"1.0.168.192.in-addr.arpa",
+		},
+		{
+			"ipv6",
+			net.ParseIP("2001:db8::567:89ab"),
+			"b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+		},
+		{
+			"invalid ip",
+			nil,
+			"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if out := ReverseDNSName(test.ip); out != test.expected {
+				t.Errorf("test %s fails: expected %s but got %s", test.name, test.expected, out)
+			}
+		})
+	}
+}
@@ -44,6 +44,22 @@ func TransferSubnetForIPAM(in *v1.Subnet) *ipamtypes.Subnet {
 	)
 }
 
+// TransferIPBlockReservationForIPAM returns the range of reserved block, which will be excluded from
+// allocation of the parent subnet
+func TransferIPBlockReservationForIPAM(in *v1.IPBlockReservation) (*ipamtypes.IPRange, error) {
+	start, end := net.ParseIP(in.Spec.Start), net.ParseIP(in.Spec.End)
+	if start == nil || end == nil || (start.To4() == nil) != (end.To4() == nil) || utils.Cmp(start, end) > 0 {
+		return nil, fmt.Errorf("invalid ip block [%s, %s]", in.Spec.Start, in.Spec.End)
+	}
+
+	capacity := utils.Capacity(start, end)
+	if !capacity.IsInt64() || capacity.Int64() > ipamtypes.MaxSubnetRangeSize {
+		return nil, fmt.Errorf("ip block [%s, %s] contains more than %d IPs", in.Spec.Start, in.Spec.End,
+			ipamtypes.MaxSubnetRangeSize)
+	}
+	return &ipamtypes.IPRange{Start: start, End: end}, nil
+}
+
 func TransferNetworkForIPAM(in *v1.Network) *ipamtypes.Network {
 	return ipamtypes.NewNetwork(in.Name,
 		int32pToUint32p(in.Spec.NetID),
@@ -0,0 +1,67 @@
+/*
+ Copyright 2021 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package transform
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestTransferIPBlockReservationForIPAM(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		expectErr bool
+	}{
+		{"ipv4 block", "10.0.0.2", "10.0.0.10", false},
+		{"ipv6 block", "fd00::2", "fd00::10", false},
+		{"single ip", "10.0.0.2", "10.0.0.2", false},
+		{"invalid start", "10.0.0", "10.0.0.10", true},
+		{"reversed", "10.0.0.10", "10.0.0.2", true},
+		{"mixed families", "10.0.0.2", "fd00::10", true},
+		{"oversized block", "fd00::", "fd00::ffff:ffff", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ipRange, err := TransferIPBlockReservationForIPAM(&v1.IPBlockReservation{
+				ObjectMeta: metav1.ObjectMeta{Name: "reservation"},
+				Spec: v1.IPBlockReservationSpec{
+					Subnet: "subnet",
+					Start:  test.start,
+					End:    test.end,
+				},
+			})
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expect error but got range %v", ipRange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fail to transfer: %v", err)
+			}
+			if ipRange.Start.String() != test.start || ipRange.End.String() != test.end {
+				t.Errorf("expect range [%s, %s] but got [%s, %s]", test.start, test.end, ipRange.Start, ipRange.End)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vtepbackup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// Version is the version of backup format
+const Version = "v1"
+
+// FieldManager is the field manager of objects applied by import
+const FieldManager = "hybridnetctl"
+
+// Backup is the portable format of VTEP configuration of remote clusters, only
+// names, labels, annotations and specs of objects are kept
+type Backup struct {
+	Version string `json:"version"`
+	// ClusterName is the name of remote cluster exported, empty means all clusters
+	ClusterName   string              `json:"clusterName,omitempty"`
+	RemoteVteps   []RemoteVtepEntry   `json:"remoteVteps"`
+	RemoteSubnets []RemoteSubnetEntry `json:"remoteSubnets"`
+}
+
+// ObjectMeta is the portable part of object meta
+type ObjectMeta struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type RemoteVtepEntry struct {
+	ObjectMeta `json:"metadata"`
+	Spec       multiclusterv1.RemoteVtepSpec `json:"spec"`
+}
+
+type RemoteSubnetEntry struct {
+	ObjectMeta `json:"metadata"`
+	Spec       multiclusterv1.RemoteSubnetSpec `json:"spec"`
+}
+
+// ImportResult shows how many objects are applied or skipped by import
+type ImportResult struct {
+	Applied []string
+	Skipped []string
+}
+
+// Export serializes RemoteVteps and RemoteSubnets of a remote cluster, status and
+// server-populated fields are dropped, owner references are dropped as well because
+// UIDs of RemoteCluster objects will change after rebuilding, and they will be adopted
+// again by the controllers of RemoteCluster
+func Export(ctx context.Context, c client.Reader, clusterName string) (*Backup, error) {
+	var listOptions []client.ListOption
+	if len(clusterName) > 0 {
+		listOptions = append(listOptions, client.MatchingLabels{constants.LabelCluster: clusterName})
+	}
+
+	backup := &Backup{
+		Version:       Version,
+		ClusterName:   clusterName,
+		RemoteVteps:   []RemoteVtepEntry{},
+		RemoteSubnets: []RemoteSubnetEntry{},
+	}
+
+	remoteVtepList := &multiclusterv1.RemoteVtepList{}
+	if err := c.List(ctx, remoteVtepList, listOptions...); err != nil {
+		return nil, fmt.Errorf("unable to list remote vteps: %v", err)
+	}
+	for i := range remoteVtepList.Items {
+		remoteVtep := &remoteVtepList.Items[i]
+		backup.RemoteVteps = append(backup.RemoteVteps, RemoteVtepEntry{
+			ObjectMeta: ObjectMeta{
+				Name:        remoteVtep.Name,
+				Labels:      remoteVtep.Labels,
+				Annotations: remoteVtep.Annotations,
+			},
+			Spec: remoteVtep.Spec,
+		})
+	}
+
+	remoteSubnetList := &multiclusterv1.RemoteSubnetList{}
+	if err := c.List(ctx, remoteSubnetList, listOptions...); err != nil {
+		return nil, fmt.Errorf("unable to list remote subnets: %v", err)
+	}
+	for i := range remoteSubnetList.Items {
+		remoteSubnet := &remoteSubnetList.Items[i]
+		backup.RemoteSubnets = append(backup.RemoteSubnets, RemoteSubnetEntry{
+			ObjectMeta: ObjectMeta{
+				Name:        remoteSubnet.Name,
+				Labels:      remoteSubnet.Labels,
+				Annotations: remoteSubnet.Annotations,
+			},
+			Spec: remoteSubnet.Spec,
+		})
+	}
+
+	// keep the output stable for diffing
+	sort.Slice(backup.RemoteVteps, func(i, j int) bool {
+		return backup.RemoteVteps[i].Name < backup.RemoteVteps[j].Name
+	})
+	sort.Slice(backup.RemoteSubnets, func(i, j int) bool {
+		return backup.RemoteSubnets[i].Name < backup.RemoteSubnets[j].Name
+	})
+	return backup, nil
+}
+
+// Import recreates RemoteVteps and RemoteSubnets of backup by server-side apply,
+// objects already existing with the same spec are skipped
+func Import(ctx context.Context, c client.Client, backup *Backup) (*ImportResult, error) {
+	if backup.Version != Version {
+		return nil, fmt.Errorf("unsupported backup version %q, expected %q", backup.Version, Version)
+	}
+
+	result := &ImportResult{}
+	for i := range backup.RemoteVteps {
+		entry := &backup.RemoteVteps[i]
+		remoteVtep := &multiclusterv1.RemoteVtep{}
+		applied, err := applyIfChanged(ctx, c, entry.ObjectMeta, remoteVtep, func() bool {
+			return equality.Semantic.DeepEqual(remoteVtep.Spec, entry.Spec)
+		}, &multiclusterv1.RemoteVtep{Spec: entry.Spec})
+		if err != nil {
+			return result, fmt.Errorf("unable to import remote vtep %s: %v", entry.Name, err)
+		}
+		result.record("RemoteVtep/"+entry.Name, applied)
+	}
+
+	for i := range backup.RemoteSubnets {
+		entry := &backup.RemoteSubnets[i]
+		remoteSubnet := &multiclusterv1.RemoteSubnet{}
+		applied, err := applyIfChanged(ctx, c, entry.ObjectMeta, remoteSubnet, func() bool {
+			return equality.Semantic.DeepEqual(remoteSubnet.Spec, entry.Spec)
+		}, &multiclusterv1.RemoteSubnet{Spec: entry.Spec})
+		if err != nil {
+			return result, fmt.Errorf("unable to import remote subnet %s: %v", entry.Name, err)
+		}
+		result.record("RemoteSubnet/"+entry.Name, applied)
+	}
+
+	return result, nil
+}
+
+func (r *ImportResult) record(name string, applied bool) {
+	if applied {
+		r.Applied = append(r.Applied, name)
+	} else {
+		r.Skipped = append(r.Skipped, name)
+	}
+}
+
+// applyIfChanged fetches the existing object into current, and applies desired with meta of
+// entry unless the existing one has the same spec
+func applyIfChanged(ctx context.Context, c client.Client, meta ObjectMeta, current client.Object,
+	sameSpec func() bool, desired client.Object) (bool, error) {
+	err := c.Get(ctx, client.ObjectKey{Name: meta.Name}, current)
+	switch {
+	case err == nil:
+		if sameSpec() {
+			return false, nil
+		}
+	case !apierrors.IsNotFound(err):
+		return false, err
+	}
+
+	gvk, err := apiutil.GVKForObject(desired, c.Scheme())
+	if err != nil {
+		return false, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return false, err
+	}
+	// status and server-populated fields are never applied
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(meta.Name)
+	obj.SetLabels(meta.Labels)
+	obj.SetAnnotations(meta.Annotations)
+
+	return true, c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+}
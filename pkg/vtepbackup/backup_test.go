@@ -0,0 +1,217 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package vtepbackup
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	multiclusterv1 "github.com/alibaba/hybridnet/pkg/apis/multicluster/v1"
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// applyClient emulates server-side apply which is not supported by fake client
+type applyClient struct {
+	client.Client
+	applied int
+}
+
+func (a *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return a.Client.Patch(ctx, obj, patch, opts...)
+	}
+	a.applied++
+
+	desired, err := a.Scheme().New(obj.GetObjectKind().GroupVersionKind())
+	if err != nil {
+		return err
+	}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, desired); err != nil {
+		return err
+	}
+	desiredObj := desired.(client.Object)
+
+	current := desiredObj.DeepCopyObject().(client.Object)
+	if err = a.Get(ctx, client.ObjectKeyFromObject(desiredObj), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return a.Create(ctx, desiredObj)
+		}
+		return err
+	}
+	desiredObj.SetResourceVersion(current.GetResourceVersion())
+	return a.Update(ctx, desiredObj)
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := multiclusterv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+	return scheme
+}
+
+func TestExportAndImportIdempotently(t *testing.T) {
+	owner := []metav1.OwnerReference{{
+		APIVersion: multiclusterv1.GroupVersion.String(),
+		Kind:       "RemoteCluster",
+		Name:       "cluster-a",
+		UID:        "6f0c4e1a",
+	}}
+	objects := []client.Object{
+		&multiclusterv1.RemoteVtep{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "cluster-a.node1",
+				Labels:          map[string]string{constants.LabelCluster: "cluster-a", constants.LabelNode: "node1"},
+				OwnerReferences: owner,
+			},
+			Spec: multiclusterv1.RemoteVtepSpec{
+				ClusterName: "cluster-a",
+				NodeName:    "node1",
+				VTEPInfo: networkingv1.VTEPInfo{
+					IP:  "192.168.0.1",
+					MAC: "00:00:5e:00:53:01",
+				},
+				EndpointIPList: []string{"10.0.0.2"},
+			},
+			Status: multiclusterv1.RemoteVtepStatus{
+				LastModifyTime: metav1.Now(),
+			},
+		},
+		&multiclusterv1.RemoteVtep{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "cluster-b.node1",
+				Labels: map[string]string{constants.LabelCluster: "cluster-b", constants.LabelNode: "node1"},
+			},
+			Spec: multiclusterv1.RemoteVtepSpec{
+				ClusterName: "cluster-b",
+				NodeName:    "node1",
+			},
+		},
+		&multiclusterv1.RemoteSubnet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "cluster-a.subnet1",
+				Labels:          map[string]string{constants.LabelCluster: "cluster-a"},
+				OwnerReferences: owner,
+			},
+			Spec: multiclusterv1.RemoteSubnetSpec{
+				Range: networkingv1.AddressRange{
+					Version: networkingv1.IPv4,
+					CIDR:    "10.0.0.0/24",
+				},
+				Type:        networkingv1.NetworkTypeOverlay,
+				ClusterName: "cluster-a",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	source := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(objects...).Build()
+
+	backup, err := Export(ctx, source, "cluster-a")
+	if err != nil {
+		t.Fatalf("fail to export: %v", err)
+	}
+	if len(backup.RemoteVteps) != 1 || len(backup.RemoteSubnets) != 1 {
+		t.Fatalf("expected only objects of cluster-a exported but got %+v", backup)
+	}
+
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatalf("fail to marshal backup: %v", err)
+	}
+	for _, field := range []string{"status", "ownerReferences", "resourceVersion", "lastModifyTime"} {
+		if strings.Contains(string(data), field) {
+			t.Fatalf("expected %s dropped from backup: %s", field, data)
+		}
+	}
+
+	restored := &Backup{}
+	if err = json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("fail to unmarshal backup: %v", err)
+	}
+
+	target := &applyClient{Client: fake.NewClientBuilder().WithScheme(newScheme(t)).Build()}
+
+	result, err := Import(ctx, target, restored)
+	if err != nil {
+		t.Fatalf("fail to import: %v", err)
+	}
+	if len(result.Applied) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("expected all objects applied but got %+v", result)
+	}
+
+	// importing the same backup again changes nothing
+	result, err = Import(ctx, target, restored)
+	if err != nil {
+		t.Fatalf("fail to import again: %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.Skipped) != 2 || target.applied != 2 {
+		t.Fatalf("expected all objects skipped but got %+v, %d applies", result, target.applied)
+	}
+
+	// exporting the restored cluster gets the same backup
+	roundTrip, err := Export(ctx, target, "cluster-a")
+	if err != nil {
+		t.Fatalf("fail to export restored cluster: %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip, restored) {
+		t.Fatalf("expected round trip backup %+v but got %+v", restored, roundTrip)
+	}
+
+	// only the object with drifted spec is applied
+	remoteVtep := &multiclusterv1.RemoteVtep{}
+	if err = target.Get(ctx, client.ObjectKey{Name: "cluster-a.node1"}, remoteVtep); err != nil {
+		t.Fatalf("fail to get remote vtep: %v", err)
+	}
+	remoteVtep.Spec.EndpointIPList = nil
+	if err = target.Update(ctx, remoteVtep); err != nil {
+		t.Fatalf("fail to update remote vtep: %v", err)
+	}
+
+	result, err = Import(ctx, target, restored)
+	if err != nil {
+		t.Fatalf("fail to import drifted objects: %v", err)
+	}
+	if !reflect.DeepEqual(result.Applied, []string{"RemoteVtep/cluster-a.node1"}) || len(result.Skipped) != 1 {
+		t.Fatalf("expected only drifted remote vtep applied but got %+v", result)
+	}
+	if err = target.Get(ctx, client.ObjectKey{Name: "cluster-a.node1"}, remoteVtep); err != nil {
+		t.Fatalf("fail to get remote vtep: %v", err)
+	}
+	if !reflect.DeepEqual(remoteVtep.Spec, restored.RemoteVteps[0].Spec) {
+		t.Fatalf("expected spec of remote vtep restored but got %+v", remoteVtep.Spec)
+	}
+}
+
+func TestImportUnsupportedVersion(t *testing.T) {
+	c := &applyClient{Client: fake.NewClientBuilder().WithScheme(newScheme(t)).Build()}
+	if _, err := Import(context.Background(), c, &Backup{Version: "v0"}); err == nil {
+		t.Fatalf("expected backup of unsupported version rejected")
+	}
+}
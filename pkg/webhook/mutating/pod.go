@@ -139,6 +139,11 @@ func PodCreateMutation(ctx context.Context, req *admission.Request, handler *Han
 		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, fmt.Errorf("unknown network type %s", networkType), logger)
 	}
 
+	// dns domains of subnets are searched by pod, based on the nodes selected above
+	if err = patchDNSSearchesToPod(ctx, handler.Cache, pod, networkName, subnetNameStr, networkType); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, fmt.Errorf("unable to patch dns searches for pod: %v", err), logger)
+	}
+
 	return generatePatchResponseFromPod(req.Object.Raw, pod, logger)
 }
 
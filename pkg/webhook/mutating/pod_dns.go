@@ -0,0 +1,176 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+	webhookutils "github.com/alibaba/hybridnet/pkg/webhook/utils"
+)
+
+const (
+	// limits of dns search list which are accepted by apiserver and resolvers of libc
+	maxDNSSearchPaths     = 6
+	maxDNSSearchListChars = 256
+)
+
+// patchDNSSearchesToPod injects dns domains of subnets which pod may get IPs from into the dns
+// search list of pod. Subnets are the specified ones if exist, otherwise all the subnets of
+// specified network, or of networks in the network type of pod if no network is specified. Only
+// the subnets available on the nodes which pod can be scheduled to are taken, i.e., the node
+// of pod if specified, or the nodes matching node selector of pod. Domains which exceed the
+// limits of dns search list are dropped.
+func patchDNSSearchesToPod(ctx context.Context, c client.Reader, pod *corev1.Pod, networkName, subnetNameStr string,
+	networkType ipamtypes.NetworkType) error {
+	subnetList := &networkingv1.SubnetList{}
+	if err := c.List(ctx, subnetList); err != nil {
+		return fmt.Errorf("unable to list subnets: %v", err)
+	}
+
+	networkList := &networkingv1.NetworkList{}
+	if err := c.List(ctx, networkList); err != nil {
+		return fmt.Errorf("unable to list networks: %v", err)
+	}
+
+	nodes, err := schedulableNodesOfPod(ctx, c, pod)
+	if err != nil {
+		return err
+	}
+
+	var networks = map[string]*networkingv1.Network{}
+	for i := range networkList.Items {
+		network := &networkList.Items[i]
+		switch {
+		case len(subnetNameStr) > 0:
+		case len(networkName) > 0:
+			if network.Name != networkName {
+				continue
+			}
+		default:
+			if string(networkingv1.GetNetworkType(network)) != string(networkType) {
+				continue
+			}
+		}
+
+		for j := range nodes {
+			if networkAvailableOnNode(network, &nodes[j]) {
+				networks[network.Name] = network
+				break
+			}
+		}
+	}
+
+	var domains []string
+	for i := range subnetList.Items {
+		subnet := &subnetList.Items[i]
+		if len(subnet.Spec.DNSDomain) == 0 || networks[subnet.Spec.Network] == nil {
+			continue
+		}
+
+		if len(subnetNameStr) > 0 && !webhookutils.SubnetNameBelongsToSpecifiedSubnets(subnet.Name, subnetNameStr) {
+			continue
+		}
+		domains = append(domains, subnet.Spec.DNSDomain)
+	}
+
+	if len(domains) == 0 {
+		return nil
+	}
+	sort.Strings(domains)
+
+	if pod.Spec.DNSConfig == nil {
+		pod.Spec.DNSConfig = &corev1.PodDNSConfig{}
+	}
+	pod.Spec.DNSConfig.Searches = appendDNSSearches(pod.Spec.DNSConfig.Searches, domains)
+
+	return nil
+}
+
+// schedulableNodesOfPod returns the node of pod if specified, otherwise the nodes matching node
+// selector of pod.
+func schedulableNodesOfPod(ctx context.Context, c client.Reader, pod *corev1.Pod) ([]corev1.Node, error) {
+	if len(pod.Spec.NodeName) > 0 {
+		node := &corev1.Node{}
+		if err := c.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			return nil, fmt.Errorf("unable to get node %s: %v", pod.Spec.NodeName, err)
+		}
+		return []corev1.Node{*node}, nil
+	}
+
+	nodeList := &corev1.NodeList{}
+	if err := c.List(ctx, nodeList, client.MatchingLabels(pod.Spec.NodeSelector)); err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %v", err)
+	}
+	return nodeList.Items, nil
+}
+
+// networkAvailableOnNode checks if pods on node can use the network, underlay networks are
+// selected by node selector, global bgp networks need to be attached to node and overlay
+// network is available on all nodes
+func networkAvailableOnNode(network *networkingv1.Network, node *corev1.Node) bool {
+	switch networkingv1.GetNetworkType(network) {
+	case networkingv1.NetworkTypeUnderlay:
+		return len(network.Spec.NodeSelector) > 0 &&
+			labels.SelectorFromSet(network.Spec.NodeSelector).Matches(labels.Set(node.Labels))
+	case networkingv1.NetworkTypeGlobalBGP:
+		_, attached := node.Labels[constants.LabelBGPNetworkAttachment]
+		return attached
+	default:
+		return true
+	}
+}
+
+// appendDNSSearches appends domains which do not exist yet to dns searches, as long as the
+// search list is within the limits.
+func appendDNSSearches(searches, domains []string) []string {
+	var existing = map[string]bool{}
+	for _, search := range searches {
+		existing[search] = true
+	}
+
+	listChars := len(strings.Join(searches, " "))
+	for _, domain := range domains {
+		if existing[domain] {
+			continue
+		}
+
+		chars := listChars + len(domain)
+		if len(searches) > 0 {
+			// separator between domains
+			chars++
+		}
+		if len(searches) >= maxDNSSearchPaths || chars > maxDNSSearchListChars {
+			continue
+		}
+
+		searches = append(searches, domain)
+		existing[domain] = true
+		listChars = chars
+	}
+	return searches
+}
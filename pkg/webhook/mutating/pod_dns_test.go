@@ -0,0 +1,164 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	ipamtypes "github.com/alibaba/hybridnet/pkg/ipam/types"
+)
+
+func TestPatchDNSSearchesToPod(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": zone}}}
+	}
+	underlayNetwork := func(name, zone string) *networkingv1.Network {
+		return &networkingv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: networkingv1.NetworkSpec{
+				Type:         networkingv1.NetworkTypeUnderlay,
+				NodeSelector: map[string]string{"zone": zone},
+			},
+		}
+	}
+	subnet := func(name, network, domain string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: networkingv1.SubnetSpec{
+				Network:   network,
+				DNSDomain: domain,
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		node("node1", "a"),
+		node("node2", "b"),
+		underlayNetwork("underlay-a", "a"),
+		underlayNetwork("underlay-b", "b"),
+		&networkingv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+			Spec:       networkingv1.NetworkSpec{Type: networkingv1.NetworkTypeOverlay},
+		},
+		subnet("subnet-a", "underlay-a", "a.example.com"),
+		subnet("subnet-b", "underlay-b", "b.example.com"),
+		subnet("subnet-overlay", "overlay", "overlay.example.com"),
+	).Build()
+
+	tests := []struct {
+		name          string
+		nodeName      string
+		nodeSelector  map[string]string
+		networkName   string
+		subnetNameStr string
+		networkType   ipamtypes.NetworkType
+		expected      []string
+	}{
+		{
+			name:        "underlay pods on all nodes",
+			networkType: ipamtypes.Underlay,
+			expected:    []string{"a.example.com", "b.example.com"},
+		},
+		{
+			name:        "underlay pod on specified node",
+			nodeName:    "node2",
+			networkType: ipamtypes.Underlay,
+			expected:    []string{"b.example.com"},
+		},
+		{
+			name:         "underlay pod on selected nodes",
+			nodeSelector: map[string]string{"zone": "a"},
+			networkType:  ipamtypes.Underlay,
+			expected:     []string{"a.example.com"},
+		},
+		{
+			name:        "specified network unavailable on node",
+			nodeName:    "node1",
+			networkName: "underlay-b",
+			networkType: ipamtypes.Underlay,
+		},
+		{
+			name:          "specified subnet",
+			nodeName:      "node1",
+			subnetNameStr: "subnet-overlay",
+			networkType:   ipamtypes.Overlay,
+			expected:      []string{"overlay.example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				Spec: corev1.PodSpec{
+					NodeName:     test.nodeName,
+					NodeSelector: test.nodeSelector,
+				},
+			}
+			if err := patchDNSSearchesToPod(context.Background(), c, pod, test.networkName, test.subnetNameStr,
+				test.networkType); err != nil {
+				t.Fatalf("fail to patch dns searches: %v", err)
+			}
+
+			var searches []string
+			if pod.Spec.DNSConfig != nil {
+				searches = pod.Spec.DNSConfig.Searches
+			}
+			if !reflect.DeepEqual(searches, test.expected) {
+				t.Errorf("expect searches %v but got %v", test.expected, searches)
+			}
+		})
+	}
+}
+
+func TestAppendDNSSearches(t *testing.T) {
+	var domains []string
+	for i := 0; i < 8; i++ {
+		domains = append(domains, fmt.Sprintf("subnet%d.example.com", i))
+	}
+
+	searches := appendDNSSearches([]string{"existing.example.com", "subnet0.example.com"}, domains)
+	if len(searches) != maxDNSSearchPaths {
+		t.Fatalf("expect %d searches but got %v", maxDNSSearchPaths, searches)
+	}
+	if searches[0] != "existing.example.com" || searches[1] != "subnet0.example.com" || searches[2] != "subnet1.example.com" {
+		t.Errorf("expect existing searches to be kept in order but got %v", searches)
+	}
+
+	long := strings.Repeat("a", 63) + "." + strings.Repeat("b", 63) + "." + strings.Repeat("c", 63)
+	searches = appendDNSSearches([]string{long}, []string{long + ".d", "short.example.com"})
+	if !reflect.DeepEqual(searches, []string{long, "short.example.com"}) {
+		t.Errorf("expect domains exceeding the chars limit to be dropped but got %v", searches)
+	}
+	if chars := len(strings.Join(searches, " ")); chars > maxDNSSearchListChars {
+		t.Errorf("expect at most %d chars but got %d", maxDNSSearchListChars, chars)
+	}
+}
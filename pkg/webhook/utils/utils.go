@@ -30,7 +30,7 @@ func SelectNetworkAndSubnetFromObject(ctx context.Context, c client.Reader, obj
 	subnetNameStr = utils.PickFirstNonEmptyString(obj.GetAnnotations()[constants.AnnotationSpecifiedSubnet],
 		obj.GetLabels()[constants.LabelSpecifiedSubnet])
 
-	subnetNames := specifiedSubnetStrToSubnetNames(subnetNameStr)
+	subnetNames := SpecifiedSubnetStrToSubnetNames(subnetNameStr)
 	if len(subnetNames) > 2 {
 		return "", "", fmt.Errorf("cannot have more than two specified subnet in dualstack")
 	}
@@ -81,7 +81,7 @@ func SelectNetworkAndSubnetFromObject(ctx context.Context, c client.Reader, obj
 	return
 }
 
-func specifiedSubnetStrToSubnetNames(specifiedSubnetString string) (subnetNames []string) {
+func SpecifiedSubnetStrToSubnetNames(specifiedSubnetString string) (subnetNames []string) {
 	if len(specifiedSubnetString) > 0 {
 		subnetNames = strings.Split(specifiedSubnetString, "/")
 	}
@@ -89,7 +89,7 @@ func specifiedSubnetStrToSubnetNames(specifiedSubnetString string) (subnetNames
 }
 
 func SubnetNameBelongsToSpecifiedSubnets(subnetName, specifiedSubnetString string) bool {
-	subnetNames := specifiedSubnetStrToSubnetNames(specifiedSubnetString)
+	subnetNames := SpecifiedSubnetStrToSubnetNames(specifiedSubnetString)
 	for _, subnet := range subnetNames {
 		if subnetName == subnet {
 			return true
@@ -18,13 +18,18 @@ package validating
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/alibaba/hybridnet/pkg/metrics"
 )
 
 var (
@@ -35,17 +40,41 @@ var (
 
 type handlerFunc func(ctx context.Context, req *admission.Request, handler *Handler) admission.Response
 
+const (
+	accessReviewCacheSize = 256
+	accessReviewCacheTTL  = time.Minute
+)
+
 type Handler struct {
 	Decoder *admission.Decoder
 	Cache   cache.Cache
 	Client  client.Client
+
+	Options Options
+
+	// accessReviews caches the results of cluster-admin reviews by user
+	accessReviews *utilcache.LRUExpireCache
 }
 
-func NewHandler() *Handler {
-	return &Handler{}
+func NewHandler(options Options) *Handler {
+	return &Handler{
+		Options:       options,
+		accessReviews: utilcache.NewLRUExpireCache(accessReviewCacheSize),
+	}
 }
 
 func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	start := time.Now()
+	resp := h.handle(ctx, req)
+
+	resource, operation := req.Resource.Resource, string(req.Operation)
+	metrics.WebhookAdmissionDuration.WithLabelValues(resource, operation).Observe(time.Since(start).Seconds())
+	metrics.WebhookAdmissionRequestsCounter.WithLabelValues(resource, operation, strconv.FormatBool(resp.Allowed)).Inc()
+
+	return resp
+}
+
+func (h *Handler) handle(ctx context.Context, req admission.Request) admission.Response {
 	switch req.Operation {
 	case admissionv1.Create:
 		if handling, exist := createHandlers[req.Kind]; exist {
@@ -0,0 +1,150 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+	"github.com/alibaba/hybridnet/pkg/utils"
+	"github.com/alibaba/hybridnet/pkg/utils/transform"
+	webhookutils "github.com/alibaba/hybridnet/pkg/webhook/utils"
+)
+
+var ipBlockReservationGVK = gvkConverter(networkingv1.GroupVersion.WithKind("IPBlockReservation"))
+
+func init() {
+	createHandlers[ipBlockReservationGVK] = IPBlockReservationCreateValidation
+	updateHandlers[ipBlockReservationGVK] = IPBlockReservationUpdateValidation
+	deleteHandlers[ipBlockReservationGVK] = IPBlockReservationDeleteValidation
+}
+
+func IPBlockReservationCreateValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	logger := log.FromContext(ctx)
+
+	reservation := &networkingv1.IPBlockReservation{}
+	err := handler.Decoder.Decode(*req, reservation)
+	if err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+
+	start, end := net.ParseIP(reservation.Spec.Start), net.ParseIP(reservation.Spec.End)
+	switch {
+	case start == nil:
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("invalid start ip %s", reservation.Spec.Start), logger)
+	case end == nil:
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("invalid end ip %s", reservation.Spec.End), logger)
+	case (start.To4() == nil) != (end.To4() == nil):
+		return webhookutils.AdmissionDeniedWithLog("start and end ip must be of the same family", logger)
+	case utils.Cmp(start, end) > 0:
+		return webhookutils.AdmissionDeniedWithLog("start ip must not be greater than end ip", logger)
+	}
+
+	if _, err = transform.TransferIPBlockReservationForIPAM(reservation); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	// Parent Subnet Validation
+	subnet := &networkingv1.Subnet{}
+	if err = handler.Client.Get(ctx, types.NamespacedName{Name: reservation.Spec.Subnet}, subnet); err != nil {
+		if errors.IsNotFound(err) {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("subnet %s does not exist", reservation.Spec.Subnet), logger)
+		}
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+
+	ipamSubnet := transform.TransferSubnetForIPAM(subnet)
+	if err = ipamSubnet.Canonicalize(); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+	if !ipamSubnet.CIDR.Contains(start) || !ipamSubnet.CIDR.Contains(end) ||
+		utils.Cmp(start, ipamSubnet.Start) < 0 || utils.Cmp(end, ipamSubnet.End) > 0 {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("ip block must be in the range [%s, %s] of subnet %s",
+			ipamSubnet.Start, ipamSubnet.End, subnet.Name), logger)
+	}
+	if ipamSubnet.Gateway != nil && ipInBlock(ipamSubnet.Gateway, start, end) {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("ip block must not contain gateway %s", ipamSubnet.Gateway), logger)
+	}
+
+	// Overlap Validation
+	reservationList := &networkingv1.IPBlockReservationList{}
+	if err = handler.Client.List(ctx, reservationList); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+	for i := range reservationList.Items {
+		other := &reservationList.Items[i]
+		if other.Spec.Subnet != reservation.Spec.Subnet {
+			continue
+		}
+		if utils.Cmp(start, net.ParseIP(other.Spec.End)) <= 0 && utils.Cmp(net.ParseIP(other.Spec.Start), end) <= 0 {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("overlap with ip block reservation %s", other.Name), logger)
+		}
+	}
+
+	// IP blocks can not be reserved if any IP in it is being used by pods
+	ipList := &networkingv1.IPInstanceList{}
+	if err = handler.Client.List(ctx, ipList, client.MatchingLabels{constants.LabelSubnet: subnet.Name}); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+	for i := range ipList.Items {
+		ipInstance := &ipList.Items[i]
+		if ip := net.ParseIP(ipInstance.Spec.Address.IP); ip != nil && ipInBlock(ip, start, end) {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("ip %s is being used by IPInstance %s/%s",
+				ipInstance.Spec.Address.IP, ipInstance.Namespace, ipInstance.Name), logger)
+		}
+	}
+
+	return admission.Allowed("validation pass")
+}
+
+func IPBlockReservationUpdateValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	logger := log.FromContext(ctx)
+
+	var err error
+	oldR, newR := &networkingv1.IPBlockReservation{}, &networkingv1.IPBlockReservation{}
+	if err = handler.Decoder.DecodeRaw(req.Object, newR); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+	if err = handler.Decoder.DecodeRaw(req.OldObject, oldR); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+
+	if !reflect.DeepEqual(oldR.Spec, newR.Spec) {
+		return webhookutils.AdmissionDeniedWithLog("spec of ip block reservation must not be changed", logger)
+	}
+
+	return admission.Allowed("validation pass")
+}
+
+func IPBlockReservationDeleteValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	return admission.Allowed("no validation")
+}
+
+func ipInBlock(ip, start, end net.IP) bool {
+	return utils.Cmp(ip, start) >= 0 && utils.Cmp(ip, end) <= 0
+}
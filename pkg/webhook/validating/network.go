@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 
 	controllerutils "github.com/alibaba/hybridnet/pkg/controllers/utils"
 	"github.com/alibaba/hybridnet/pkg/utils"
@@ -38,6 +40,7 @@ import (
 	"github.com/alibaba/hybridnet/pkg/feature"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
@@ -127,8 +130,8 @@ func NetworkCreateValidation(ctx context.Context, req *admission.Request, handle
 		}
 
 		for _, peer := range network.Spec.Config.BGPPeers {
-			if net.ParseIP(peer.Address) == nil {
-				return admission.Denied(fmt.Sprintf("invalid bgp peer ip address %v", peer.Address))
+			if err = validateBGPPeerAddress(&peer); err != nil {
+				return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
 			}
 
 			if peer.ASN == 0 {
@@ -139,6 +142,10 @@ func NetworkCreateValidation(ctx context.Context, req *admission.Request, handle
 		if networkType != networkingv1.NetworkTypeUnderlay {
 			return admission.Denied("VLAN mode can only be used for underlay network")
 		}
+
+		if err = validateVlanID(network.Spec.NetID); err != nil {
+			return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+		}
 	case networkingv1.NetworkModeVxlan:
 		if networkType != networkingv1.NetworkTypeOverlay {
 			return admission.Denied("VXLAN mode can only be used for overlay network")
@@ -151,6 +158,46 @@ func NetworkCreateValidation(ctx context.Context, req *admission.Request, handle
 		return admission.Denied(fmt.Sprintf("unknown network mode %s", networkingv1.GetNetworkMode(network)))
 	}
 
+	if err = validateSubnetMaskSizeRange(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateMulticastGroup(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateEncapsulation(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateChecksumOffloadMode(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateNodeMobilityGraceTimeout(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateNoSubnetPolicy(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateBGPPodIPAnnouncement(ctx, handler.Client, network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateSRIOVMode(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateInheritNamespaceLabels(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateIPFamilyPreference(network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	return admission.Allowed("validation pass")
 }
 
@@ -198,8 +245,8 @@ func NetworkUpdateValidation(ctx context.Context, req *admission.Request, handle
 		}
 
 		for _, peer := range newN.Spec.Config.BGPPeers {
-			if net.ParseIP(peer.Address) == nil {
-				return admission.Denied(fmt.Sprintf("invalid bgp peer ip address %v", peer.Address))
+			if err = validateBGPPeerAddress(&peer); err != nil {
+				return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
 			}
 		}
 	case networkingv1.NetworkModeVlan, networkingv1.NetworkModeVxlan, networkingv1.NetworkModeGlobalBGP:
@@ -211,6 +258,49 @@ func NetworkUpdateValidation(ctx context.Context, req *admission.Request, handle
 		return webhookutils.AdmissionDeniedWithLog("net ID must not be changed", logger)
 	}
 
+	if err = validateSubnetMaskSizeRange(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateMulticastGroup(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if oldN.Spec.Encapsulation != newN.Spec.Encapsulation || !reflect.DeepEqual(oldN.Spec.ServiceVlanID, newN.Spec.ServiceVlanID) {
+		return webhookutils.AdmissionDeniedWithLog("encapsulation and service vlan ID must not be changed", logger)
+	}
+
+	if err = validateChecksumOffloadMode(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateNodeMobilityGraceTimeout(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateNoSubnetPolicy(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	// bgp networks are only required while enabling, networks already enabled are not affected by them
+	if !networkingv1.IsBGPPodIPAnnouncementNetwork(oldN) {
+		if err = validateBGPPodIPAnnouncement(ctx, handler.Client, newN); err != nil {
+			return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+		}
+	}
+
+	if oldN.Spec.SRIOVMode != newN.Spec.SRIOVMode {
+		return webhookutils.AdmissionDeniedWithLog("sriov mode must not be changed", logger)
+	}
+
+	if err = validateInheritNamespaceLabels(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateIPFamilyPreference(newN); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	return admission.Allowed("validation pass")
 }
 
@@ -318,3 +408,188 @@ func checkUnderlayNetworkOverlapped(ctx context.Context, c client.Reader, networ
 	}
 	return false, "", nil
 }
+
+// validateSubnetMaskSizeRange checks if the restriction on subnet mask size of network is valid
+func validateSubnetMaskSizeRange(network *networkingv1.Network) error {
+	if network.Spec.MinSubnetMaskSize != nil && network.Spec.MaxSubnetMaskSize != nil &&
+		*network.Spec.MinSubnetMaskSize > *network.Spec.MaxSubnetMaskSize {
+		return fmt.Errorf("min subnet mask size %d must not be larger than max subnet mask size %d",
+			*network.Spec.MinSubnetMaskSize, *network.Spec.MaxSubnetMaskSize)
+	}
+	return nil
+}
+
+// validateMulticastGroup checks if the multicast group of network is a valid multicast address,
+// which should be in 224.0.0.0/4 for IPv4 or ff00::/8 for IPv6
+func validateMulticastGroup(network *networkingv1.Network) error {
+	if len(network.Spec.MulticastGroup) == 0 {
+		return nil
+	}
+
+	if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVxlan {
+		return fmt.Errorf("multicast group can only be assigned for vxlan network")
+	}
+
+	if ip := net.ParseIP(network.Spec.MulticastGroup); ip == nil || !ip.IsMulticast() {
+		return fmt.Errorf("multicast group %s must be in 224.0.0.0/4 or ff00::/8", network.Spec.MulticastGroup)
+	}
+	return nil
+}
+
+// validateEncapsulation checks if the vlan encapsulation of network is valid, QinQ encapsulation
+// can only be used for underlay VLAN network with a service vlan ID as outer tag
+func validateEncapsulation(network *networkingv1.Network) error {
+	switch network.Spec.Encapsulation {
+	case "":
+		if network.Spec.ServiceVlanID != nil {
+			return fmt.Errorf("service vlan ID can only be assigned for QinQ encapsulation")
+		}
+	case networkingv1.NetworkEncapsulationQinQ:
+		if networkingv1.GetNetworkType(network) != networkingv1.NetworkTypeUnderlay ||
+			networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan {
+			return fmt.Errorf("QinQ encapsulation can only be used for underlay VLAN network")
+		}
+
+		if network.Spec.ServiceVlanID == nil || *network.Spec.ServiceVlanID <= 0 || *network.Spec.ServiceVlanID > 4094 {
+			return fmt.Errorf("must assign service vlan ID in range [1, 4094] for QinQ encapsulation")
+		}
+	default:
+		return fmt.Errorf("unknown encapsulation %s", network.Spec.Encapsulation)
+	}
+	return nil
+}
+
+// validateChecksumOffloadMode checks if the checksum offload mode of network is valid,
+// which can only be assigned for vxlan network
+func validateChecksumOffloadMode(network *networkingv1.Network) error {
+	if len(network.Spec.ChecksumOffloadMode) == 0 {
+		return nil
+	}
+
+	if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVxlan {
+		return fmt.Errorf("checksum offload mode can only be assigned for vxlan network")
+	}
+
+	switch network.Spec.ChecksumOffloadMode {
+	case networkingv1.ChecksumOffloadModeAuto, networkingv1.ChecksumOffloadModeEnabled, networkingv1.ChecksumOffloadModeDisabled:
+		return nil
+	default:
+		return fmt.Errorf("unknown checksum offload mode %s", network.Spec.ChecksumOffloadMode)
+	}
+}
+
+// validateNodeMobilityGraceTimeout checks if the node mobility grace timeout of network is non-negative
+func validateNodeMobilityGraceTimeout(network *networkingv1.Network) error {
+	if network.Spec.NodeMobilityGraceTimeout != nil && network.Spec.NodeMobilityGraceTimeout.Duration < 0 {
+		return fmt.Errorf("node mobility grace timeout must not be negative")
+	}
+	return nil
+}
+
+// validateNoSubnetPolicy checks if the no-subnet policy of network is valid, and a valid
+// http(s) url must be assigned for Webhook policy
+func validateNoSubnetPolicy(network *networkingv1.Network) error {
+	switch networkingv1.GetNoSubnetPolicy(network) {
+	case networkingv1.NoSubnetPolicyRetry, networkingv1.NoSubnetPolicyFail:
+		if len(network.Spec.NoSubnetWebhookURL) > 0 {
+			return fmt.Errorf("no-subnet webhook url can only be assigned for %s policy", networkingv1.NoSubnetPolicyWebhook)
+		}
+		return nil
+	case networkingv1.NoSubnetPolicyWebhook:
+		if len(network.Spec.NoSubnetWebhookURL) == 0 {
+			return fmt.Errorf("no-subnet webhook url must be assigned for %s policy", networkingv1.NoSubnetPolicyWebhook)
+		}
+		webhookURL, err := url.Parse(network.Spec.NoSubnetWebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid no-subnet webhook url %s: %v", network.Spec.NoSubnetWebhookURL, err)
+		}
+		if (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || len(webhookURL.Host) == 0 {
+			return fmt.Errorf("no-subnet webhook url %s must be an absolute http or https url", network.Spec.NoSubnetWebhookURL)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown no-subnet policy %s", network.Spec.NoSubnetPolicy)
+	}
+}
+
+// validateSRIOVMode checks if the network is able to assign SR-IOV virtual functions to pods,
+// which is only supported by underlay VLAN networks without QinQ encapsulation
+func validateSRIOVMode(network *networkingv1.Network) error {
+	if !networkingv1.IsSRIOVNetwork(network) {
+		return nil
+	}
+
+	if networkingv1.GetNetworkType(network) != networkingv1.NetworkTypeUnderlay ||
+		networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan {
+		return fmt.Errorf("sriov mode can only be enabled for underlay network in %s mode", networkingv1.NetworkModeVlan)
+	}
+
+	if networkingv1.IsQinQNetwork(network) {
+		return fmt.Errorf("sriov mode can not be enabled for network with %s encapsulation", networkingv1.NetworkEncapsulationQinQ)
+	}
+	return nil
+}
+
+// validateBGPPodIPAnnouncement checks if pod ip announcement is only enabled for vxlan network, since pods
+// of bgp networks are announced anyway, and if there is an underlay bgp network whose peers ips are announced to
+func validateBGPPodIPAnnouncement(ctx context.Context, c client.Reader, network *networkingv1.Network) error {
+	if !networkingv1.IsBGPPodIPAnnouncementNetwork(network) {
+		return nil
+	}
+
+	if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVxlan {
+		return fmt.Errorf("bgp pod ip announcement can only be enabled for vxlan network")
+	}
+
+	networks := &networkingv1.NetworkList{}
+	if err := c.List(ctx, networks); err != nil {
+		return fmt.Errorf("failed to list networks: %v", err)
+	}
+	for i := range networks.Items {
+		if networkingv1.GetNetworkMode(&networks.Items[i]) == networkingv1.NetworkModeBGP {
+			return nil
+		}
+	}
+	return fmt.Errorf("bgp pod ip announcement can not be enabled without any underlay network in %s mode",
+		networkingv1.NetworkModeBGP)
+}
+
+// validateInheritNamespaceLabels checks if the inherited namespace label keys are legal,
+// built-in labels are not allowed because they are managed by hybridnet itself
+func validateInheritNamespaceLabels(network *networkingv1.Network) error {
+	for _, key := range network.Spec.InheritNamespaceLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid inherited namespace label key %s: %s", key, strings.Join(errs, "; "))
+		}
+		if strings.HasPrefix(key, constants.LabelDomainPrefix) {
+			return fmt.Errorf("built-in label %s can not be inherited from namespace", key)
+		}
+	}
+	return nil
+}
+
+// validateIPFamilyPreference checks if the ip family preference of network is valid
+func validateIPFamilyPreference(network *networkingv1.Network) error {
+	switch networkingv1.GetIPFamilyPreference(network) {
+	case networkingv1.IPFamilyPreferenceIPv4First, networkingv1.IPFamilyPreferenceIPv6First:
+		return nil
+	default:
+		return fmt.Errorf("unknown ip family preference %s", network.Spec.IPFamilyPreference)
+	}
+}
+
+// validateBGPPeerAddress checks the address of bgp peer, which is discovered through IPv6
+// link-local address for unnumbered peer
+func validateBGPPeerAddress(peer *networkingv1.BGPPeer) error {
+	if peer.BGPUnnumbered {
+		if len(peer.Address) != 0 {
+			return fmt.Errorf("must not assign address %v for bgp unnumbered peer", peer.Address)
+		}
+		return nil
+	}
+
+	if net.ParseIP(peer.Address) == nil {
+		return fmt.Errorf("invalid bgp peer ip address %v", peer.Address)
+	}
+	return nil
+}
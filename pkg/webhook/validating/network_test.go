@@ -0,0 +1,150 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func newCreateRequest(t *testing.T, obj runtime.Object) *admission.Request {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("fail to marshal object: %v", err)
+	}
+
+	return &admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func TestBGPPodIPAnnouncementValidation(t *testing.T) {
+	netID := int32(4)
+	overlayNetwork := func(announced bool) *networkingv1.Network {
+		return &networkingv1.Network{
+			TypeMeta:   metav1.TypeMeta{APIVersion: networkingv1.GroupVersion.String(), Kind: "Network"},
+			ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+			Spec: networkingv1.NetworkSpec{
+				Type:                 networkingv1.NetworkTypeOverlay,
+				Mode:                 networkingv1.NetworkModeVxlan,
+				NetID:                &netID,
+				BGPPodIPAnnouncement: announced,
+			},
+		}
+	}
+	bgpNetwork := &networkingv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "bgp"},
+		Spec: networkingv1.NetworkSpec{
+			Type: networkingv1.NetworkTypeUnderlay,
+			Mode: networkingv1.NetworkModeBGP,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+		oldN     *networkingv1.Network
+		newN     *networkingv1.Network
+		allowed  bool
+	}{
+		{
+			name:    "create without announcement",
+			newN:    overlayNetwork(false),
+			allowed: true,
+		},
+		{
+			name:    "create without bgp network",
+			newN:    overlayNetwork(true),
+			allowed: false,
+		},
+		{
+			name:     "create with bgp network",
+			existing: []client.Object{bgpNetwork},
+			newN:     overlayNetwork(true),
+			allowed:  true,
+		},
+		{
+			name:     "enable without bgp network",
+			existing: []client.Object{overlayNetwork(false)},
+			oldN:     overlayNetwork(false),
+			newN:     overlayNetwork(true),
+			allowed:  false,
+		},
+		{
+			name:     "enable with bgp network",
+			existing: []client.Object{overlayNetwork(false), bgpNetwork},
+			oldN:     overlayNetwork(false),
+			newN:     overlayNetwork(true),
+			allowed:  true,
+		},
+		{
+			// bgp networks are only required while enabling
+			name:     "keep enabled after bgp network removed",
+			existing: []client.Object{overlayNetwork(true)},
+			oldN:     overlayNetwork(true),
+			newN:     overlayNetwork(true),
+			allowed:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler, _ := newTestHandler(t, test.existing...)
+
+			var resp admission.Response
+			if test.oldN == nil {
+				resp = NetworkCreateValidation(context.Background(), newCreateRequest(t, test.newN), handler)
+			} else {
+				resp = NetworkUpdateValidation(context.Background(), newUpdateRequest(t, "admin", test.oldN, test.newN), handler)
+			}
+
+			if resp.Allowed != test.allowed {
+				t.Errorf("expect allowed %v but got %v: %v", test.allowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestValidateBGPPodIPAnnouncementOfNonVxlanNetwork(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	for _, mode := range []networkingv1.NetworkMode{networkingv1.NetworkModeBGP, networkingv1.NetworkModeVlan,
+		networkingv1.NetworkModeGlobalBGP} {
+		network := &networkingv1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: "network"},
+			Spec: networkingv1.NetworkSpec{
+				Mode:                 mode,
+				BGPPodIPAnnouncement: true,
+			},
+		}
+		if err := validateBGPPodIPAnnouncement(context.Background(), handler.Client, network); err == nil {
+			t.Errorf("expect announcement rejected for network in %s mode", mode)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"fmt"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+// Options are the options of validating webhook
+type Options struct {
+	// ControllerServiceAccount is the user name of hybridnet components, whose
+	// requests are never reviewed for cluster-admin privileges
+	ControllerServiceAccount string
+
+	// SubnetMaskSizes are the cluster-wide restrictions on mask size of subnets by IP version,
+	// which are used if the parent network does not specify them
+	SubnetMaskSizes map[networkingv1.IPVersion]MaskSizeRange
+}
+
+// MaskSizeRange restricts the mask size of subnets, 0 means no restriction
+type MaskSizeRange struct {
+	Min int
+	Max int
+}
+
+// Validate checks if the mask size ranges are valid for their IP versions
+func (o *Options) Validate() error {
+	for version, maskSizes := range o.SubnetMaskSizes {
+		bits := 32
+		if version == networkingv1.IPv6 {
+			bits = 128
+		}
+
+		if maskSizes.Min < 0 || maskSizes.Min > bits {
+			return fmt.Errorf("min mask size %d of ipv%s subnets must be in range [0, %d]", maskSizes.Min, version, bits)
+		}
+		if maskSizes.Max < 0 || maskSizes.Max > bits {
+			return fmt.Errorf("max mask size %d of ipv%s subnets must be in range [0, %d]", maskSizes.Max, version, bits)
+		}
+		if maskSizes.Min > 0 && maskSizes.Max > 0 && maskSizes.Min > maskSizes.Max {
+			return fmt.Errorf("min mask size %d of ipv%s subnets must not be larger than max mask size %d",
+				maskSizes.Min, version, maskSizes.Max)
+		}
+	}
+	return nil
+}
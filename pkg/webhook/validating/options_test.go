@@ -0,0 +1,140 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"testing"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		maskSizes map[networkingv1.IPVersion]MaskSizeRange
+		valid     bool
+	}{
+		{
+			name:  "no restriction",
+			valid: true,
+		},
+		{
+			name: "restrictions of both versions",
+			maskSizes: map[networkingv1.IPVersion]MaskSizeRange{
+				networkingv1.IPv4: {Min: 16, Max: 28},
+				networkingv1.IPv6: {Min: 48, Max: 120},
+			},
+			valid: true,
+		},
+		{
+			name: "only min restriction",
+			maskSizes: map[networkingv1.IPVersion]MaskSizeRange{
+				networkingv1.IPv4: {Min: 24},
+			},
+			valid: true,
+		},
+		{
+			name: "min larger than max",
+			maskSizes: map[networkingv1.IPVersion]MaskSizeRange{
+				networkingv1.IPv4: {Min: 28, Max: 24},
+			},
+			valid: false,
+		},
+		{
+			name: "ipv4 mask size out of range",
+			maskSizes: map[networkingv1.IPVersion]MaskSizeRange{
+				networkingv1.IPv4: {Max: 64},
+			},
+			valid: false,
+		},
+		{
+			name: "negative mask size",
+			maskSizes: map[networkingv1.IPVersion]MaskSizeRange{
+				networkingv1.IPv6: {Min: -1},
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := &Options{SubnetMaskSizes: test.maskSizes}
+			if err := options.Validate(); (err == nil) != test.valid {
+				t.Errorf("expect valid %v but got %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateSubnetMaskSize(t *testing.T) {
+	globalMaskSizes := map[networkingv1.IPVersion]MaskSizeRange{
+		networkingv1.IPv4: {Min: 16, Max: 28},
+		networkingv1.IPv6: {Min: 48, Max: 120},
+	}
+	maskSize := func(size int32) *int32 { return &size }
+	subnet := func(cidr string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{Spec: networkingv1.SubnetSpec{Range: networkingv1.AddressRange{CIDR: cidr}}}
+	}
+
+	tests := []struct {
+		name    string
+		subnet  *networkingv1.Subnet
+		network *networkingv1.Network
+		valid   bool
+	}{
+		{
+			name:    "ipv4 subnet in global range",
+			subnet:  subnet("10.0.0.0/24"),
+			network: &networkingv1.Network{},
+			valid:   true,
+		},
+		{
+			name:    "ipv4 subnet larger than global range",
+			subnet:  subnet("10.0.0.0/8"),
+			network: &networkingv1.Network{},
+			valid:   false,
+		},
+		{
+			name:    "ipv6 subnet in global range",
+			subnet:  subnet("fd00::/64"),
+			network: &networkingv1.Network{},
+			valid:   true,
+		},
+		{
+			name:    "ipv6 subnet smaller than global range",
+			subnet:  subnet("fd00::/124"),
+			network: &networkingv1.Network{},
+			valid:   false,
+		},
+		{
+			name:   "restriction of network overrides global one",
+			subnet: subnet("10.0.0.0/8"),
+			network: &networkingv1.Network{Spec: networkingv1.NetworkSpec{
+				MinSubnetMaskSize: maskSize(8),
+			}},
+			valid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateSubnetMaskSize(test.subnet, test.network, globalMaskSizes); (err == nil) != test.valid {
+				t.Errorf("expect valid %v but got %v", test.valid, err)
+			}
+		})
+	}
+}
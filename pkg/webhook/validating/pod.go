@@ -26,6 +26,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -165,12 +166,47 @@ func PodCreateValidation(ctx context.Context, req *admission.Request, handler *H
 		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("unrecognized network type %s", networkType), logger)
 	}
 
+	// Node network type validation
+	// pods bound to nodes on creation will never be scheduled again, so the explicitly requested network
+	// type must be available on the node, pods are bound to nodes by the binding subresource afterwards,
+	// which never goes through pod update
+	if len(pod.Spec.NodeName) > 0 && len(networkTypeFromPod) > 0 {
+		availableTypes, err := availableNetworkTypesOfNode(ctx, handler.Cache, pod.Spec.NodeName)
+		if err != nil {
+			return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+		}
+
+		var available bool
+		var availableTypeStrs []string
+		for _, availableType := range availableTypes {
+			available = available || stringEqualCaseInsensitive(string(availableType), string(networkType))
+			availableTypeStrs = append(availableTypeStrs, string(availableType))
+		}
+		if !available {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("network type %s is not available on node %s, "+
+				"available network types: [%s]", networkType, pod.Spec.NodeName, strings.Join(availableTypeStrs, ", ")), logger)
+		}
+	}
+
 	// IP family validation
 	var ipFamily = ipamtypes.ParseIPFamilyFromString(pod.Annotations[constants.AnnotationIPFamily])
 	if !ipamtypes.IsValidFamilyMode(ipFamily) {
 		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("unrecognized ip family %s", ipFamily), logger)
 	}
 
+	// IP family combination validation
+	// pod will never get IPs if there is no subnet of the requested ip family, deny it here rather than
+	// failing in the reconciling of pod controller
+	missingVersions, scope, err := missingSubnetVersions(ctx, handler.Cache, networkType, specifiedNetwork, specifiedSubnetStr, ipFamily)
+	if err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+	if len(missingVersions) > 0 {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("no ipv%s subnet found in %s for ip family %s, "+
+			"please create one or change the annotation %s", strings.Join(missingVersions, "/ipv"), scope, ipFamily,
+			constants.AnnotationIPFamily), logger)
+	}
+
 	// Network availability validation
 	// For underlay network type, pod will be patched some quota labels when mutating to be scheduled on nodes which
 	// have available underlay network
@@ -220,3 +256,115 @@ func PodCreateValidation(ctx context.Context, req *admission.Request, handler *H
 func stringEqualCaseInsensitive(a, b string) bool {
 	return strings.EqualFold(a, b)
 }
+
+// missingSubnetVersions returns the ip versions required by ip family but not provided by any subnet in scope,
+// the scope is decided by the specified subnets, specified network or network type in order
+func missingSubnetVersions(ctx context.Context, c client.Reader, networkType ipamtypes.NetworkType, specifiedNetwork,
+	specifiedSubnetStr string, ipFamily ipamtypes.IPFamilyMode) (missingVersions []string, scope string, err error) {
+	var subnets []*networkingv1.Subnet
+	switch {
+	case len(specifiedSubnetStr) > 0:
+		scope = fmt.Sprintf("specified subnets %s", specifiedSubnetStr)
+		for _, subnetName := range strings.Split(specifiedSubnetStr, "/") {
+			subnet := &networkingv1.Subnet{}
+			if err = c.Get(ctx, types.NamespacedName{Name: subnetName}, subnet); err != nil {
+				return nil, "", err
+			}
+			subnets = append(subnets, subnet)
+		}
+	default:
+		subnetList := &networkingv1.SubnetList{}
+		if err = c.List(ctx, subnetList); err != nil {
+			return nil, "", err
+		}
+
+		var inScope func(subnet *networkingv1.Subnet) bool
+		if len(specifiedNetwork) > 0 {
+			scope = fmt.Sprintf("network %s", specifiedNetwork)
+			inScope = func(subnet *networkingv1.Subnet) bool {
+				return subnet.Spec.Network == specifiedNetwork
+			}
+		} else {
+			networkList := &networkingv1.NetworkList{}
+			if err = c.List(ctx, networkList); err != nil {
+				return nil, "", err
+			}
+
+			var networkNames = make(map[string]struct{})
+			for i := range networkList.Items {
+				if stringEqualCaseInsensitive(string(networkingv1.GetNetworkType(&networkList.Items[i])), string(networkType)) {
+					networkNames[networkList.Items[i].Name] = struct{}{}
+				}
+			}
+
+			scope = fmt.Sprintf("%s networks", networkType)
+			inScope = func(subnet *networkingv1.Subnet) bool {
+				_, exist := networkNames[subnet.Spec.Network]
+				return exist && !networkingv1.IsPrivateSubnet(subnet)
+			}
+		}
+
+		for i := range subnetList.Items {
+			if inScope(&subnetList.Items[i]) {
+				subnets = append(subnets, &subnetList.Items[i])
+			}
+		}
+	}
+
+	var hasIPv4, hasIPv6 bool
+	for _, subnet := range subnets {
+		if networkingv1.IsIPv6Subnet(subnet) {
+			hasIPv6 = true
+		} else {
+			hasIPv4 = true
+		}
+	}
+
+	if (ipFamily == ipamtypes.IPv4 || ipFamily == ipamtypes.DualStack) && !hasIPv4 {
+		missingVersions = append(missingVersions, "4")
+	}
+	if (ipFamily == ipamtypes.IPv6 || ipFamily == ipamtypes.DualStack) && !hasIPv6 {
+		missingVersions = append(missingVersions, "6")
+	}
+	return missingVersions, scope, nil
+}
+
+// availableNetworkTypesOfNode returns the types of networks which pods on node can use, underlay
+// networks are selected by node selector, bgp networks need to be attached to node and overlay
+// network is available on all nodes
+func availableNetworkTypesOfNode(ctx context.Context, c client.Reader, nodeName string) ([]networkingv1.NetworkType, error) {
+	node := &corev1.Node{}
+	if err := c.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		return nil, fmt.Errorf("unable to get node %s: %v", nodeName, err)
+	}
+
+	networkList := &networkingv1.NetworkList{}
+	if err := c.List(ctx, networkList); err != nil {
+		return nil, err
+	}
+
+	var networkTypes []networkingv1.NetworkType
+	var found = make(map[networkingv1.NetworkType]struct{})
+	for i := range networkList.Items {
+		network := &networkList.Items[i]
+		networkType := networkingv1.GetNetworkType(network)
+
+		switch networkType {
+		case networkingv1.NetworkTypeUnderlay:
+			if len(network.Spec.NodeSelector) == 0 ||
+				!labels.SelectorFromSet(network.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+				continue
+			}
+		case networkingv1.NetworkTypeGlobalBGP:
+			if _, attached := node.Labels[constants.LabelBGPNetworkAttachment]; !attached {
+				continue
+			}
+		}
+
+		if _, exist := found[networkType]; !exist {
+			found[networkType] = struct{}{}
+			networkTypes = append(networkTypes, networkType)
+		}
+	}
+	return networkTypes, nil
+}
@@ -19,6 +19,7 @@ package validating
 import (
 	"context"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"sync"
 
@@ -76,9 +77,13 @@ func validate(ctx context.Context, rc *multiclusterv1.RemoteCluster, handler *Ha
 	if rc.Spec.APIEndpoint == "" {
 		return webhookutils.AdmissionDeniedWithLog("invalid empty endpoint", logger)
 	}
-	if len(rc.Spec.CAData) == 0 || len(rc.Spec.CertData) == 0 || len(rc.Spec.KeyData) == 0 {
+	if len(rc.Spec.KubeConfigFile) == 0 &&
+		(len(rc.Spec.CAData) == 0 || len(rc.Spec.CertData) == 0 || len(rc.Spec.KeyData) == 0) {
 		return webhookutils.AdmissionDeniedWithLog("invalid empty certificate info", logger)
 	}
+	if len(rc.Spec.KubeConfigFile) > 0 && !filepath.IsAbs(rc.Spec.KubeConfigFile) {
+		return webhookutils.AdmissionDeniedWithLog("kubeconfig file must be an absolute path", logger)
+	}
 
 	// validate endpoint format
 	if !validEndpoint.Match([]byte(rc.Spec.APIEndpoint)) {
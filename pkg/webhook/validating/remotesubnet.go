@@ -55,6 +55,10 @@ func RemoteSubnetCreateValidation(ctx context.Context, req *admission.Request, h
 		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
 	}
 
+	if err = validateRemoteSubnetRouteMetric(remoteSubnet); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	var localSubnetList = &networkingv1.SubnetList{}
 	if err = handler.Client.List(ctx, localSubnetList); err != nil {
 		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
@@ -81,9 +85,28 @@ func RemoteSubnetCreateValidation(ctx context.Context, req *admission.Request, h
 }
 
 func RemoteSubnetUpdateValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	logger := log.FromContext(ctx)
+
+	var err error
+	var remoteSubnet = &multiclusterv1.RemoteSubnet{}
+	if err = handler.Decoder.Decode(*req, remoteSubnet); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+
+	if err = validateRemoteSubnetRouteMetric(remoteSubnet); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	return admission.Allowed("validation pass")
 }
 
 func RemoteSubnetDeleteValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
 	return admission.Allowed("validation pass")
 }
+
+func validateRemoteSubnetRouteMetric(remoteSubnet *multiclusterv1.RemoteSubnet) error {
+	if remoteSubnet.Spec.RouteMetric != nil && *remoteSubnet.Spec.RouteMetric <= 0 {
+		return fmt.Errorf("route metric must be a positive integer, got %d", *remoteSubnet.Spec.RouteMetric)
+	}
+	return nil
+}
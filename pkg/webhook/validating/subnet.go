@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -35,8 +37,10 @@ import (
 	"github.com/alibaba/hybridnet/pkg/utils"
 	"github.com/alibaba/hybridnet/pkg/utils/transform"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -87,6 +91,11 @@ func SubnetCreateValidation(ctx context.Context, req *admission.Request, handler
 			if network.Spec.NetID != nil && *subnet.Spec.NetID != *network.Spec.NetID {
 				return webhookutils.AdmissionDeniedWithLog("have inconsistent Net ID with network", logger)
 			}
+
+			// subnets of different vlans can share the same nic through vlan sub-interfaces
+			if err = validateVlanID(subnet.Spec.NetID); err != nil {
+				return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+			}
 		}
 
 		if subnet.Spec.Config != nil && subnet.Spec.Config.AutoNatOutgoing != nil {
@@ -120,6 +129,20 @@ func SubnetCreateValidation(ctx context.Context, req *admission.Request, handler
 		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("subnet contains more than %d IPs", MaxSubnetCapacity), logger)
 	}
 
+	// Mask size validation
+	if err = validateSubnetMaskSize(subnet, network, handler.Options.SubnetMaskSizes); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	// Pod routes validation
+	if err = validatePodRoutes(subnet, network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateDNSDomain(subnet); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	// Subnet overlap validation
 	ipamSubnet := transform.TransferSubnetForIPAM(subnet)
 	if err = ipamSubnet.Canonicalize(); err != nil {
@@ -178,8 +201,24 @@ func SubnetUpdateValidation(ctx context.Context, req *admission.Request, handler
 		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
 	}
 
+	// Immutable fields can only be changed by cluster-admins in emergency, the review is only
+	// required when immutable fields are really changed, so that metadata-only updates and
+	// requests from hybridnet components on annotated subnets are not blocked
+	allowImmutableUpdate := false
+	if utils.ParseBoolOrDefault(newS.Annotations[constants.AnnotationAllowImmutableUpdate], false) &&
+		req.UserInfo.Username != handler.Options.ControllerServiceAccount && immutableFieldsChanged(oldS, newS) {
+		if allowImmutableUpdate, err = isClusterAdmin(ctx, req, handler); err != nil {
+			return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+		}
+		if !allowImmutableUpdate {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("only cluster-admins can set annotation %s",
+				constants.AnnotationAllowImmutableUpdate), logger)
+		}
+		logger.Info("immutable fields of subnet are allowed to be updated", "user", req.UserInfo.Username)
+	}
+
 	// Parent Network validation
-	if oldS.Spec.Network != newS.Spec.Network {
+	if oldS.Spec.Network != newS.Spec.Network && !allowImmutableUpdate {
 		return webhookutils.AdmissionDeniedWithLog("must not change parent network", logger)
 	}
 
@@ -222,22 +261,98 @@ func SubnetUpdateValidation(ctx context.Context, req *admission.Request, handler
 	if oldS.Spec.Range.Start != newS.Spec.Range.Start {
 		return webhookutils.AdmissionDeniedWithLog("must not change range start", logger)
 	}
-	if oldS.Spec.Range.End != newS.Spec.Range.End {
+	if oldS.Spec.Range.End != newS.Spec.Range.End && oldS.Spec.Range.CIDR == newS.Spec.Range.CIDR {
+		// range end can only be moved together with an expansion of CIDR
 		return webhookutils.AdmissionDeniedWithLog("must not change range end", logger)
 	}
-	if oldS.Spec.Range.Gateway != newS.Spec.Range.Gateway {
+	if oldS.Spec.Range.Gateway != newS.Spec.Range.Gateway && !allowImmutableUpdate {
 		return webhookutils.AdmissionDeniedWithLog("must not change range gateway", logger)
 	}
-	if oldS.Spec.Range.CIDR != newS.Spec.Range.CIDR {
-		return webhookutils.AdmissionDeniedWithLog("must not change range CIDR", logger)
+	if oldS.Spec.Range.CIDR != newS.Spec.Range.CIDR && !allowImmutableUpdate {
+		// CIDR is allowed to be expanded in place, IPs of existing pods keep unchanged
+		if err = validateCIDRExpansion(ctx, handler, oldS, newS, network); err != nil {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("must not change range CIDR except expansion: %v", err), logger)
+		}
+		logger.Info("range CIDR of subnet is expanded", "subnet", newS.Name,
+			"from", oldS.Spec.Range.CIDR, "to", newS.Spec.Range.CIDR)
 	}
 	if !utils.DeepEqualStringSlice(oldS.Spec.Range.ExcludeIPs, newS.Spec.Range.ExcludeIPs) {
 		return webhookutils.AdmissionDeniedWithLog("must not change excluded IPs", logger)
 	}
 
+	// Pod routes validation
+	if err = validatePodRoutes(newS, network); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if err = validateDNSDomain(newS); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
 	return admission.Allowed("validation pass")
 }
 
+// immutableFieldsChanged checks whether fields which can only be changed by cluster-admins are changed
+func immutableFieldsChanged(oldS, newS *networkingv1.Subnet) bool {
+	return oldS.Spec.Network != newS.Spec.Network ||
+		oldS.Spec.Range.Gateway != newS.Spec.Range.Gateway ||
+		oldS.Spec.Range.CIDR != newS.Spec.Range.CIDR
+}
+
+// isClusterAdmin checks whether the requesting user is able to do anything on any resource,
+// results are cached for a while to avoid creating a review for every request
+func isClusterAdmin(ctx context.Context, req *admission.Request, handler *Handler) (bool, error) {
+	key := accessReviewKey(req)
+	if handler.accessReviews != nil {
+		if allowed, exist := handler.accessReviews.Get(key); exist {
+			return allowed.(bool), nil
+		}
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, v := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     "*",
+				Group:    "*",
+				Resource: "*",
+			},
+			User:   req.UserInfo.Username,
+			Groups: req.UserInfo.Groups,
+			UID:    req.UserInfo.UID,
+			Extra:  extra,
+		},
+	}
+	if err := handler.Client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("unable to review access of user %s: %v", req.UserInfo.Username, err)
+	}
+
+	if handler.accessReviews != nil {
+		handler.accessReviews.Add(key, review.Status.Allowed, accessReviewCacheTTL)
+	}
+	return review.Status.Allowed, nil
+}
+
+// accessReviewKey identifies the requesting user with all attributes used by access reviews
+func accessReviewKey(req *admission.Request) string {
+	extraKeys := make([]string, 0, len(req.UserInfo.Extra))
+	for k := range req.UserInfo.Extra {
+		extraKeys = append(extraKeys, k)
+	}
+	sort.Strings(extraKeys)
+
+	var builder strings.Builder
+	builder.WriteString(req.UserInfo.Username + "/" + req.UserInfo.UID + "/" + strings.Join(req.UserInfo.Groups, ","))
+	for _, k := range extraKeys {
+		builder.WriteString("/" + k + "=" + strings.Join(req.UserInfo.Extra[k], ","))
+	}
+	return builder.String()
+}
+
 func SubnetDeleteValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
 	logger := log.FromContext(ctx)
 
@@ -247,6 +362,10 @@ func SubnetDeleteValidation(ctx context.Context, req *admission.Request, handler
 		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
 	}
 
+	if utils.ParseBoolOrDefault(subnet.Annotations[constants.AnnotationForceDelete], false) {
+		return admission.Allowed("force delete")
+	}
+
 	ipList := &networkingv1.IPInstanceList{}
 	if err = handler.Client.List(ctx, ipList, client.MatchingLabels{constants.LabelSubnet: subnet.Name}); err != nil {
 		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
@@ -262,3 +381,165 @@ func SubnetDeleteValidation(ctx context.Context, req *admission.Request, handler
 
 	return admission.Allowed("validation pass")
 }
+
+// validateSubnetMaskSize checks the mask size of subnet with the restriction of parent network,
+// global restriction of the IP version will be used if network does not specify it
+func validateSubnetMaskSize(subnet *networkingv1.Subnet, network *networkingv1.Network,
+	globalMaskSizes map[networkingv1.IPVersion]MaskSizeRange) error {
+	_, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid cidr %s: %v", subnet.Spec.Range.CIDR, err)
+	}
+	maskSize, bits := cidr.Mask.Size()
+
+	version := networkingv1.IPv4
+	if bits == 128 {
+		version = networkingv1.IPv6
+	}
+
+	minMaskSize, maxMaskSize := globalMaskSizes[version].Min, globalMaskSizes[version].Max
+	if network.Spec.MinSubnetMaskSize != nil {
+		minMaskSize = int(*network.Spec.MinSubnetMaskSize)
+	}
+	if network.Spec.MaxSubnetMaskSize != nil {
+		maxMaskSize = int(*network.Spec.MaxSubnetMaskSize)
+	}
+
+	if minMaskSize > 0 && maskSize < minMaskSize {
+		return fmt.Errorf("mask size %d of subnet must not be smaller than %d", maskSize, minMaskSize)
+	}
+	if maxMaskSize > 0 && maskSize > maxMaskSize {
+		return fmt.Errorf("mask size %d of subnet must not be larger than %d", maskSize, maxMaskSize)
+	}
+	return nil
+}
+
+// validateVlanID checks if the net ID of vlan network or subnet is a valid 802.1Q vlan id,
+// 0 means untagged
+func validateVlanID(netID *int32) error {
+	if netID != nil && (*netID < 0 || *netID > 4094) {
+		return fmt.Errorf("net ID %d of vlan is out of range, must be from 0 to 4094", *netID)
+	}
+	return nil
+}
+
+// validatePodRoutes checks if pod routes are valid destination CIDRs with next hops inside
+// subnet, pod routes are only supported by vlan subnets whose next hops are on link of nodes
+func validatePodRoutes(subnet *networkingv1.Subnet, network *networkingv1.Network) error {
+	if len(subnet.Spec.PodRoutes) == 0 {
+		return nil
+	}
+
+	if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan {
+		return fmt.Errorf("pod routes are only supported by vlan subnets")
+	}
+
+	_, cidr, err := net.ParseCIDR(subnet.Spec.Range.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid cidr %s: %v", subnet.Spec.Range.CIDR, err)
+	}
+
+	for _, route := range subnet.Spec.PodRoutes {
+		_, dst, err := net.ParseCIDR(route.Destination)
+		if err != nil {
+			return fmt.Errorf("invalid destination %s of pod route: %v", route.Destination, err)
+		}
+		if (dst.IP.To4() == nil) != (cidr.IP.To4() == nil) {
+			return fmt.Errorf("destination %s of pod route has a different ip family from subnet", route.Destination)
+		}
+
+		nextHop := net.ParseIP(route.NextHop)
+		if nextHop == nil {
+			return fmt.Errorf("invalid next hop %s of pod route", route.NextHop)
+		}
+		if !cidr.Contains(nextHop) {
+			return fmt.Errorf("next hop %s of pod route is not in subnet cidr %s", route.NextHop, subnet.Spec.Range.CIDR)
+		}
+	}
+	return nil
+}
+
+// validateDNSDomain checks if the dns domain of subnet is a valid dns subdomain
+func validateDNSDomain(subnet *networkingv1.Subnet) error {
+	if len(subnet.Spec.DNSDomain) == 0 {
+		return nil
+	}
+
+	if errs := validation.IsDNS1123Subdomain(subnet.Spec.DNSDomain); len(errs) > 0 {
+		return fmt.Errorf("invalid dns domain %s: %s", subnet.Spec.DNSDomain, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateCIDRExpansion checks if the new CIDR of subnet is a proper superset of the old one
+// with the same network address, e.g., from /25 to /24, and the expanded CIDR must not overlap
+// with any other subnet
+func validateCIDRExpansion(ctx context.Context, handler *Handler, oldS, newS *networkingv1.Subnet,
+	network *networkingv1.Network) error {
+	oldIP, oldCIDR, err := net.ParseCIDR(oldS.Spec.Range.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid old CIDR %s: %v", oldS.Spec.Range.CIDR, err)
+	}
+	newIP, newCIDR, err := net.ParseCIDR(newS.Spec.Range.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid new CIDR %s: %v", newS.Spec.Range.CIDR, err)
+	}
+
+	oldOnes, oldBits := oldCIDR.Mask.Size()
+	newOnes, newBits := newCIDR.Mask.Size()
+	switch {
+	case (oldIP.To4() == nil) != (newIP.To4() == nil) || oldBits != newBits:
+		return fmt.Errorf("ip family of CIDR must not be changed")
+	case !oldCIDR.IP.Equal(newCIDR.IP):
+		return fmt.Errorf("network address of CIDR must not be changed")
+	case newOnes >= oldOnes:
+		return fmt.Errorf("new CIDR %s is not a proper superset of %s", newCIDR.String(), oldCIDR.String())
+	}
+
+	// an explicit range end keeps expanded addresses out of use, it must be moved forward
+	// or removed along with the expansion and must not leave IPs in use out of range
+	oldEnd := utils.LastIP(oldCIDR)
+	if len(oldS.Spec.Range.End) > 0 {
+		oldEnd = net.ParseIP(oldS.Spec.Range.End)
+	}
+	if newEnd := net.ParseIP(newS.Spec.Range.End); newEnd != nil && utils.Cmp(newEnd, oldEnd) <= 0 {
+		return fmt.Errorf("range end %s must be moved beyond %s or removed to use addresses of expanded CIDR",
+			newS.Spec.Range.End, oldEnd.String())
+	}
+
+	if capacity := networkingv1.CalculateCapacity(&newS.Spec.Range); capacity.Cmp(big.NewInt(MaxSubnetCapacity)) == 1 {
+		return fmt.Errorf("subnet contains more than %d IPs", MaxSubnetCapacity)
+	}
+
+	if err = validateSubnetMaskSize(newS, network, handler.Options.SubnetMaskSizes); err != nil {
+		return err
+	}
+
+	subnetList := &networkingv1.SubnetList{}
+	if err = handler.Client.List(ctx, subnetList); err != nil {
+		return fmt.Errorf("unable to list subnets: %v", err)
+	}
+	for i := range subnetList.Items {
+		if subnetList.Items[i].Name == newS.Name {
+			continue
+		}
+		if networkingv1.Intersect(&networkingv1.AddressRange{CIDR: newS.Spec.Range.CIDR},
+			&networkingv1.AddressRange{CIDR: subnetList.Items[i].Spec.Range.CIDR}) {
+			return fmt.Errorf("expanded CIDR overlaps with existing subnet %s", subnetList.Items[i].Name)
+		}
+	}
+
+	if feature.MultiClusterEnabled() {
+		rcSubnetList := &multiclusterv1.RemoteSubnetList{}
+		if err = handler.Client.List(ctx, rcSubnetList); err != nil {
+			return fmt.Errorf("unable to list remote subnets: %v", err)
+		}
+		for _, rcSubnet := range rcSubnetList.Items {
+			if networkingv1.Intersect(&newS.Spec.Range, &rcSubnet.Spec.Range) {
+				return fmt.Errorf("expanded CIDR overlaps with existing RemoteSubnet %s", rcSubnet.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,345 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	"github.com/alibaba/hybridnet/pkg/constants"
+)
+
+// reviewingClient answers access reviews with a fixed set of cluster-admins
+type reviewingClient struct {
+	client.Client
+	admins  map[string]bool
+	reviews int
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		c.reviews++
+		review.Status.Allowed = c.admins[review.Spec.User]
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func newTestHandler(t *testing.T, objs ...client.Object) (*Handler, *reviewingClient) {
+	scheme := runtime.NewScheme()
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to build scheme: %v", err)
+	}
+
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("fail to build decoder: %v", err)
+	}
+
+	c := &reviewingClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		admins: map[string]bool{"admin": true},
+	}
+
+	handler := NewHandler(Options{ControllerServiceAccount: "system:serviceaccount:kube-system:hybridnet"})
+	handler.Decoder = decoder
+	handler.Client = c
+	return handler, c
+}
+
+func newUpdateRequest(t *testing.T, user string, oldObj, newObj runtime.Object) *admission.Request {
+	oldRaw, err := json.Marshal(oldObj)
+	if err != nil {
+		t.Fatalf("fail to marshal old object: %v", err)
+	}
+	newRaw, err := json.Marshal(newObj)
+	if err != nil {
+		t.Fatalf("fail to marshal new object: %v", err)
+	}
+
+	return &admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: user},
+			Object:    runtime.RawExtension{Raw: newRaw},
+			OldObject: runtime.RawExtension{Raw: oldRaw},
+		},
+	}
+}
+
+func TestSubnetUpdateValidationReviewsImmutableUpdate(t *testing.T) {
+	network := &networkingv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "network1"},
+		Spec:       networkingv1.NetworkSpec{Type: networkingv1.NetworkTypeOverlay},
+	}
+
+	subnet := func(gateway string, annotations map[string]string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{
+			TypeMeta: metav1.TypeMeta{APIVersion: networkingv1.GroupVersion.String(), Kind: "Subnet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "subnet1",
+				Annotations: annotations,
+			},
+			Spec: networkingv1.SubnetSpec{
+				Network: "network1",
+				Range: networkingv1.AddressRange{
+					Version: networkingv1.IPv4,
+					CIDR:    "10.0.0.0/24",
+					Gateway: gateway,
+				},
+			},
+		}
+	}
+	allowed := map[string]string{constants.AnnotationAllowImmutableUpdate: "true"}
+
+	tests := []struct {
+		name    string
+		user    string
+		oldS    *networkingv1.Subnet
+		newS    *networkingv1.Subnet
+		allowed bool
+		reviews int
+	}{
+		{
+			name:    "metadata-only update is not reviewed",
+			user:    "developer",
+			oldS:    subnet("10.0.0.1", nil),
+			newS:    subnet("10.0.0.1", allowed),
+			allowed: true,
+			reviews: 0,
+		},
+		{
+			name:    "controller is not reviewed",
+			user:    "system:serviceaccount:kube-system:hybridnet",
+			oldS:    subnet("10.0.0.1", allowed),
+			newS:    subnet("10.0.0.254", allowed),
+			allowed: false,
+			reviews: 0,
+		},
+		{
+			name:    "non-admin is denied",
+			user:    "developer",
+			oldS:    subnet("10.0.0.1", allowed),
+			newS:    subnet("10.0.0.254", allowed),
+			allowed: false,
+			reviews: 1,
+		},
+		{
+			name:    "non-admin is denied with cached review",
+			user:    "developer",
+			oldS:    subnet("10.0.0.1", allowed),
+			newS:    subnet("10.0.0.254", allowed),
+			allowed: false,
+			reviews: 1,
+		},
+		{
+			name:    "admin is allowed",
+			user:    "admin",
+			oldS:    subnet("10.0.0.1", allowed),
+			newS:    subnet("10.0.0.254", allowed),
+			allowed: true,
+			reviews: 2,
+		},
+		{
+			name:    "admin is allowed with cached review",
+			user:    "admin",
+			oldS:    subnet("10.0.0.1", allowed),
+			newS:    subnet("10.0.0.254", allowed),
+			allowed: true,
+			reviews: 2,
+		},
+	}
+
+	handler, c := newTestHandler(t, network)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := SubnetUpdateValidation(context.Background(), newUpdateRequest(t, test.user, test.oldS, test.newS), handler)
+			if resp.Allowed != test.allowed {
+				t.Errorf("expect allowed %v but got %v: %v", test.allowed, resp.Allowed, resp.Result)
+			}
+			if c.reviews != test.reviews {
+				t.Errorf("expect %d access reviews but got %d", test.reviews, c.reviews)
+			}
+		})
+	}
+}
+
+func TestSubnetUpdateValidationCIDRExpansion(t *testing.T) {
+	minMaskSize := int32(20)
+	network := &networkingv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "network1"},
+		Spec: networkingv1.NetworkSpec{
+			Type:              networkingv1.NetworkTypeOverlay,
+			MinSubnetMaskSize: &minMaskSize,
+		},
+	}
+
+	subnet := func(name, cidr string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: networkingv1.GroupVersion.String(), Kind: "Subnet"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: networkingv1.SubnetSpec{
+				Network: "network1",
+				Range: networkingv1.AddressRange{
+					Version: networkingv1.IPv4,
+					CIDR:    cidr,
+					Gateway: "10.0.0.1",
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cidr    string
+		allowed bool
+		reason  string
+	}{
+		{
+			name:    "expansion",
+			cidr:    "10.0.0.0/23",
+			allowed: true,
+		},
+		{
+			name:   "shrink",
+			cidr:   "10.0.0.0/25",
+			reason: "not a proper superset",
+		},
+		{
+			name:   "gateway out of expanded CIDR",
+			cidr:   "10.0.2.0/23",
+			reason: "gateway 10.0.0.1 is not in CIDR",
+		},
+		{
+			name:   "overlapping with other subnet",
+			cidr:   "10.0.0.0/21",
+			reason: "overlaps with existing subnet subnet2",
+		},
+		{
+			name:   "mask size out of range",
+			cidr:   "10.0.0.0/16",
+			reason: "must not be smaller than 20",
+		},
+	}
+
+	handler, _ := newTestHandler(t, network, subnet("subnet1", "10.0.0.0/24"), subnet("subnet2", "10.0.4.0/24"))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := SubnetUpdateValidation(context.Background(),
+				newUpdateRequest(t, "admin", subnet("subnet1", "10.0.0.0/24"), subnet("subnet1", test.cidr)), handler)
+			if resp.Allowed != test.allowed {
+				t.Fatalf("expect allowed %v but got %v: %v", test.allowed, resp.Allowed, resp.Result)
+			}
+			if !test.allowed && !strings.Contains(string(resp.Result.Reason), test.reason) {
+				t.Errorf("expect denied for %q but got %q", test.reason, resp.Result.Reason)
+			}
+		})
+	}
+
+	withEnd := func(s *networkingv1.Subnet, end string) *networkingv1.Subnet {
+		s.Spec.Range.End = end
+		return s
+	}
+
+	endTests := []struct {
+		name    string
+		oldEnd  string
+		cidr    string
+		newEnd  string
+		allowed bool
+		reason  string
+	}{
+		{
+			name:    "end moved into expanded CIDR",
+			oldEnd:  "10.0.0.200",
+			cidr:    "10.0.0.0/23",
+			newEnd:  "10.0.1.200",
+			allowed: true,
+		},
+		{
+			name:    "end removed with expansion",
+			oldEnd:  "10.0.0.200",
+			cidr:    "10.0.0.0/23",
+			allowed: true,
+		},
+		{
+			name:   "end kept with expansion",
+			oldEnd: "10.0.0.200",
+			cidr:   "10.0.0.0/23",
+			newEnd: "10.0.0.200",
+			reason: "range end 10.0.0.200 must be moved beyond 10.0.0.200 or removed",
+		},
+		{
+			name:   "end moved backward with expansion",
+			oldEnd: "10.0.0.200",
+			cidr:   "10.0.0.0/23",
+			newEnd: "10.0.0.100",
+			reason: "range end 10.0.0.100 must be moved beyond 10.0.0.200 or removed",
+		},
+		{
+			name:   "end added inside old CIDR with expansion",
+			cidr:   "10.0.0.0/23",
+			newEnd: "10.0.0.250",
+			reason: "range end 10.0.0.250 must be moved beyond 10.0.0.254 or removed",
+		},
+		{
+			name:   "end changed without expansion",
+			oldEnd: "10.0.0.200",
+			cidr:   "10.0.0.0/24",
+			newEnd: "10.0.0.250",
+			reason: "must not change range end",
+		},
+	}
+
+	for _, test := range endTests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := SubnetUpdateValidation(context.Background(),
+				newUpdateRequest(t, "admin", withEnd(subnet("subnet1", "10.0.0.0/24"), test.oldEnd),
+					withEnd(subnet("subnet1", test.cidr), test.newEnd)), handler)
+			if resp.Allowed != test.allowed {
+				t.Fatalf("expect allowed %v but got %v: %v", test.allowed, resp.Allowed, resp.Result)
+			}
+			if !test.allowed && !strings.Contains(string(resp.Result.Reason), test.reason) {
+				t.Errorf("expect denied for %q but got %q", test.reason, resp.Result.Reason)
+			}
+		})
+	}
+
+	// network address and ip family are checked even if gateway is in new CIDR
+	for cidr, reason := range map[string]string{
+		"10.0.2.0/23": "network address of CIDR must not be changed",
+		"fd00::/64":   "ip family of CIDR must not be changed",
+	} {
+		err := validateCIDRExpansion(context.Background(), handler, subnet("subnet1", "10.0.0.0/24"),
+			subnet("subnet1", cidr), network)
+		if err == nil || !strings.Contains(err.Error(), reason) {
+			t.Errorf("expect error %q for CIDR %s but got %v", reason, cidr, err)
+		}
+	}
+}
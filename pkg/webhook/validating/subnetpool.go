@@ -0,0 +1,126 @@
+/*
+ Copyright 2022 The Hybridnet Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	networkingv1 "github.com/alibaba/hybridnet/pkg/apis/networking/v1"
+	webhookutils "github.com/alibaba/hybridnet/pkg/webhook/utils"
+)
+
+var subnetPoolGVK = gvkConverter(networkingv1.GroupVersion.WithKind("SubnetPool"))
+
+func init() {
+	createHandlers[subnetPoolGVK] = SubnetPoolCreateValidation
+	updateHandlers[subnetPoolGVK] = SubnetPoolUpdateValidation
+	deleteHandlers[subnetPoolGVK] = SubnetPoolDeleteValidation
+}
+
+func SubnetPoolCreateValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	logger := log.FromContext(ctx)
+
+	pool := &networkingv1.SubnetPool{}
+	err := handler.Decoder.Decode(*req, pool)
+	if err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+
+	// Parent Network validation
+	network := &networkingv1.Network{}
+	if err = handler.Client.Get(ctx, types.NamespacedName{Name: pool.Spec.Network}, network); err != nil {
+		if errors.IsNotFound(err) {
+			return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("parent network %s does not exist", pool.Spec.Network), logger)
+		}
+		return webhookutils.AdmissionErroredWithLog(http.StatusInternalServerError, err, logger)
+	}
+
+	_, cidr, err := net.ParseCIDR(pool.Spec.CIDR)
+	if err != nil {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("invalid cidr %s: %v", pool.Spec.CIDR, err), logger)
+	}
+
+	// Mask size validation, carved subnets must also pass the subnet validation
+	ones, bits := cidr.Mask.Size()
+	if int(pool.Spec.SubnetMaskSize) < ones || int(pool.Spec.SubnetMaskSize) > bits {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("mask size %d of subnets must be in range [%d, %d]",
+			pool.Spec.SubnetMaskSize, ones, bits), logger)
+	}
+	if err = validateSubnetMaskSize(&networkingv1.Subnet{
+		Spec: networkingv1.SubnetSpec{
+			Range: networkingv1.AddressRange{
+				CIDR: (&net.IPNet{IP: cidr.IP, Mask: net.CIDRMask(int(pool.Spec.SubnetMaskSize), bits)}).String(),
+			},
+		},
+	}, network, handler.Options.SubnetMaskSizes); err != nil {
+		return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+	}
+
+	if pool.Spec.MaxSubnets > 0 && pool.Spec.MinSubnets > pool.Spec.MaxSubnets {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("min subnets %d must not be larger than max subnets %d",
+			pool.Spec.MinSubnets, pool.Spec.MaxSubnets), logger)
+	}
+
+	if pool.Spec.NetID != nil {
+		if networkingv1.GetNetworkMode(network) != networkingv1.NetworkModeVlan {
+			return webhookutils.AdmissionDeniedWithLog("must not assign net ID for non-vlan network", logger)
+		}
+		if err = validateVlanID(pool.Spec.NetID); err != nil {
+			return webhookutils.AdmissionDeniedWithLog(err.Error(), logger)
+		}
+	}
+
+	return admission.Allowed("validation pass")
+}
+
+func SubnetPoolUpdateValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	logger := log.FromContext(ctx)
+
+	var err error
+	oldP, newP := &networkingv1.SubnetPool{}, &networkingv1.SubnetPool{}
+	if err = handler.Decoder.DecodeRaw(req.Object, newP); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+	if err = handler.Decoder.DecodeRaw(req.OldObject, oldP); err != nil {
+		return webhookutils.AdmissionErroredWithLog(http.StatusBadRequest, err, logger)
+	}
+
+	// only the scale of pool can be changed
+	if oldP.Spec.Network != newP.Spec.Network || oldP.Spec.CIDR != newP.Spec.CIDR ||
+		oldP.Spec.SubnetMaskSize != newP.Spec.SubnetMaskSize {
+		return webhookutils.AdmissionDeniedWithLog("network, cidr and subnet mask size of subnet pool must not be changed", logger)
+	}
+
+	if newP.Spec.MaxSubnets > 0 && newP.Spec.MinSubnets > newP.Spec.MaxSubnets {
+		return webhookutils.AdmissionDeniedWithLog(fmt.Sprintf("min subnets %d must not be larger than max subnets %d",
+			newP.Spec.MinSubnets, newP.Spec.MaxSubnets), logger)
+	}
+
+	return admission.Allowed("validation pass")
+}
+
+func SubnetPoolDeleteValidation(ctx context.Context, req *admission.Request, handler *Handler) admission.Response {
+	return admission.Allowed("no validation")
+}